@@ -0,0 +1,79 @@
+//go:build js && wasm
+
+// Command reminty-wasm is the converter built for the browser. Loaded with
+// Go's wasm_exec.js, it defines a global `reminty` object:
+//
+//	reminty.convert(jsx)  → {code} or {error}
+//	reminty.analyze(jsx)  → {components, patterns, suggestions, warnings, findings} or {error}
+//	reminty.version       → the converter version
+//
+// Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o reminty.wasm ./cmd/reminty-wasm
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/patterns"
+	"github.com/ha1tch/reminty/internal/pipeline"
+	"github.com/ha1tch/reminty/pkg/reminty"
+)
+
+const version = "0.1.0"
+
+func main() {
+	js.Global().Set("reminty", js.ValueOf(map[string]interface{}{
+		"convert": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			result, err := reminty.Convert([]byte(argText(args)), reminty.Options{})
+			if err != nil {
+				return toJS(map[string]string{"error": err.Error()})
+			}
+			return toJS(map[string]string{"code": result.Code})
+		}),
+		"analyze": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			result, err := analyze(context.Background(), argText(args))
+			if err != nil {
+				return toJS(map[string]string{"error": err.Error()})
+			}
+			return toJS(result)
+		}),
+		"version": version,
+	}))
+
+	// Keep the functions callable for the lifetime of the page
+	select {}
+}
+
+func argText(args []js.Value) string {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return ""
+	}
+	return args[0].String()
+}
+
+// toJS hands v to JavaScript as a plain object
+func toJS(v interface{}) js.Value {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+// analyze answers as the analyze-buffer method of `reminty rpc` does, so
+// editor and browser clients read the same analysis
+func analyze(ctx context.Context, text string) (*pipeline.Analysis, error) {
+	detector, err := new(pipeline.Settings).NewDetector()
+	if err != nil {
+		return nil, err
+	}
+	result, detected, _, err := pipeline.Analyze(ctx, text, detector)
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.NewAnalysis(result, patterns.Rank(detected, 0), audit.Run(result.File, text)), nil
+}
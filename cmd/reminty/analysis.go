@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// analysisReport is the document written by -analyze -format json. A
+// single file and a directory produce the same shape.
+type analysisReport struct {
+	Files []fileAnalysis `json:"files"`
+}
+
+type fileAnalysis struct {
+	File        string               `json:"file"`
+	Components  []componentAnalysis  `json:"components"`
+	Patterns    []patternAnalysis    `json:"patterns"`
+	Suggestions []suggestionAnalysis `json:"suggestions"`
+	Warnings    []warningAnalysis    `json:"warnings"`
+	Findings    []audit.Finding      `json:"findings"`
+}
+
+type componentAnalysis struct {
+	Name        string                   `json:"name"`
+	Line        int                      `json:"line"`
+	Props       []string                 `json:"props"`
+	Hooks       []hookAnalysis           `json:"hooks"`
+	StateVars   []parser.StateVariable   `json:"stateVars"`
+	DerivedVars []parser.DerivedVariable `json:"derivedVars"`
+}
+
+type hookAnalysis struct {
+	Type string   `json:"type"`
+	Name string   `json:"name,omitempty"`
+	Deps []string `json:"deps,omitempty"`
+	Line int      `json:"line"`
+}
+
+type patternAnalysis struct {
+	Type        string   `json:"type"`
+	Rule        string   `json:"rule"`
+	Line        int      `json:"line"`
+	Confidence  float64  `json:"confidence"`
+	Description string   `json:"description"`
+	StateVars   []string `json:"stateVars,omitempty"`
+	DerivedVars []string `json:"derivedVars,omitempty"`
+}
+
+type suggestionAnalysis struct {
+	Rule      string `json:"rule"`
+	Line      int    `json:"line"`
+	Kind      string `json:"kind"`
+	ReactCode string `json:"reactCode"`
+	MintyHint string `json:"mintyHint"`
+}
+
+type warningAnalysis struct {
+	Rule    string          `json:"rule"`
+	Code    string          `json:"code,omitempty"`
+	Line    int             `json:"line"`
+	Column  int             `json:"column"`
+	Message string          `json:"message"`
+	Skipped *parser.Skipped `json:"skipped,omitempty"`
+}
+
+// fileAnalysisOf collects the analysis of one converted file; slices are
+// never nil, so scripts can iterate without checking for null
+func fileAnalysisOf(file string, c *conversion) fileAnalysis {
+	fa := fileAnalysis{
+		File:        file,
+		Components:  []componentAnalysis{},
+		Patterns:    []patternAnalysis{},
+		Suggestions: []suggestionAnalysis{},
+		Warnings:    []warningAnalysis{},
+		Findings:    append([]audit.Finding{}, c.findings...),
+	}
+	for _, comp := range c.result.File.Components {
+		ca := componentAnalysis{
+			Name:        comp.Name,
+			Line:        comp.LineNumber,
+			Props:       []string{},
+			Hooks:       []hookAnalysis{},
+			StateVars:   append([]parser.StateVariable{}, comp.StateVars...),
+			DerivedVars: append([]parser.DerivedVariable{}, comp.DerivedVars...),
+		}
+		for _, p := range comp.Props {
+			ca.Props = append(ca.Props, p.Name)
+		}
+		for _, h := range comp.Hooks {
+			ca.Hooks = append(ca.Hooks, hookAnalysis{Type: h.Type, Name: h.Name, Deps: h.Deps, Line: h.LineNumber})
+		}
+		fa.Components = append(fa.Components, ca)
+	}
+	for _, p := range c.patterns {
+		fa.Patterns = append(fa.Patterns, patternAnalysis{
+			Type:        string(p.Type),
+			Rule:        p.Type.Rule(),
+			Line:        p.Line,
+			Confidence:  p.Confidence,
+			Description: p.Description,
+			StateVars:   p.StateVars,
+			DerivedVars: p.DerivedVars,
+		})
+	}
+	for _, s := range c.result.Suggestions {
+		fa.Suggestions = append(fa.Suggestions, suggestionAnalysis{
+			Rule:      s.Rule(),
+			Line:      s.Line,
+			Kind:      s.PatternType,
+			ReactCode: s.ReactCode,
+			MintyHint: s.MintyHint,
+		})
+	}
+	for _, w := range c.result.Warnings {
+		fa.Warnings = append(fa.Warnings, warningAnalysis{
+			Rule:    w.Rule(),
+			Code:    w.Code,
+			Line:    w.Line,
+			Column:  w.Column,
+			Message: w.Message,
+			Skipped: w.Skipped,
+		})
+	}
+	return fa
+}
+
+// writeAnalysisJSON writes the analysis of one or more files
+func writeAnalysisJSON(w io.Writer, files []fileAnalysis) error {
+	if files == nil {
+		files = []fileAnalysis{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(analysisReport{Files: files})
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ha1tch/reminty/internal/pipeline"
+)
+
+// apiConversion is the response to POST /convert: the generated code
+// along with the same analysis POST /analyze returns
+type apiConversion struct {
+	Code string `json:"code"`
+	*pipeline.Analysis
+}
+
+// apiServer serves conversions over HTTP with bounded request sizes,
+// concurrency and time per request
+type apiServer struct {
+	maxBody int64
+	slots   chan struct{} // one token per conversion allowed to run
+	timeout time.Duration
+}
+
+// runAPI implements `reminty api`: a headless HTTP service offering
+// conversion and analysis to other tools
+func runAPI(args []string) {
+	fs := flag.NewFlagSet("api", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "Address to listen on")
+	maxBody := fs.Int64("max-body", 1<<20, "Largest accepted request body, in bytes")
+	maxConcurrent := fs.Int("max-concurrent", runtime.NumCPU(), "Conversions allowed to run at once")
+	timeout := fs.Duration("timeout", 10*time.Second, "Longest time a request may wait and run")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: reminty api [options]
+
+Serves JSX conversion over HTTP. Both endpoints take the JSX source as the
+request body and answer with JSON:
+  POST /convert   {code, components, patterns, suggestions, warnings, findings}
+  POST /analyze   {components, patterns, suggestions, warnings, findings}
+
+Query parameters: tailwind=true, mappings=<packs>, framework=<name> (as the
+CLI flags).
+
+Options:
+  -listen <addr>          Address to listen on (default :8080)
+  -max-body <bytes>       Largest accepted request body (default 1048576)
+  -max-concurrent <n>     Conversions allowed to run at once (default: CPUs)
+  -timeout <duration>     Longest time a request may wait and run (default 10s)
+`)
+	}
+	fs.Parse(args)
+	if *maxConcurrent < 1 {
+		fatalf("Error: -max-concurrent must be at least 1\n")
+	}
+
+	s := &apiServer{
+		maxBody: *maxBody,
+		slots:   make(chan struct{}, *maxConcurrent),
+		timeout: *timeout,
+	}
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("reminty %s API listening on %s", version, *listen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatalf("Error: %v\n", err)
+	}
+}
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", s.handle(func(ctx context.Context, conv *conversion, opts *options) (interface{}, error) {
+		if err := conv.generate(ctx, opts); err != nil {
+			return nil, err
+		}
+		code, _ := gofmt(conv.output)
+		return apiConversion{Code: code, Analysis: analysisOf(conv)}, nil
+	}))
+	mux.HandleFunc("/analyze", s.handle(func(ctx context.Context, conv *conversion, opts *options) (interface{}, error) {
+		return analysisOf(conv), nil
+	}))
+	return mux
+}
+
+// handle wraps an endpoint with the method check, body limit,
+// concurrency cap and timeout, and analyzes the posted JSX for it
+func (s *apiServer) handle(endpoint func(context.Context, *conversion, *options) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, http.StatusMethodNotAllowed, "use POST with the JSX source as the body")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", s.maxBody))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.TrimSpace(string(body)) == "" {
+			writeAPIError(w, http.StatusBadRequest, "empty request body")
+			return
+		}
+
+		opts := &options{tailwind: r.URL.Query().Get("tailwind") == "true", framework: r.URL.Query().Get("framework")}
+		if mappings := r.URL.Query().Get("mappings"); mappings != "" {
+			opts.mappings = strings.Split(mappings, ",")
+		}
+		if _, err := opts.newGenerator(); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+		defer cancel()
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		case <-ctx.Done():
+			writeAPIError(w, http.StatusServiceUnavailable, "server busy, try again later")
+			return
+		}
+
+		// The conversion runs on its own goroutine so that a hung one
+		// cannot hold the slot past the timeout: the handler answers and
+		// releases the slot when the context ends, and the worker's result
+		// is discarded.
+		type outcome struct {
+			result interface{}
+			err    error
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			conv, err := analyze(ctx, string(body), opts)
+			if err != nil {
+				done <- outcome{err: err}
+				return
+			}
+			conv.file = "request.jsx"
+			result, err := endpoint(ctx, conv, opts)
+			done <- outcome{result, err}
+		}()
+		select {
+		case out := <-done:
+			err = out.err
+			if err == nil {
+				writeAPIJSON(w, http.StatusOK, out.result)
+				return
+			}
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			writeAPIError(w, http.StatusServiceUnavailable, "conversion timed out")
+			return
+		}
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+	}
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": message})
+}
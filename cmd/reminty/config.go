@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ha1tch/reminty/internal/config"
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/patterns"
+	"github.com/ha1tch/reminty/internal/pipeline"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// loadConfig reads the config file at path or, when path is empty, the
+// .reminty.yaml found from the working directory up to the repository
+// root. It returns nil when there is none.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		found, err := config.Find(".")
+		if err != nil || found == "" {
+			return nil, err
+		}
+		path = found
+	}
+	return config.Load(path)
+}
+
+// applyConfig fills the options from cfg. Settings given on the command
+// line (those in set) win over the config file.
+func (o *options) applyConfig(cfg *config.Config, set map[string]bool) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Tailwind != nil && !set["tailwind"] {
+		o.tailwind = *cfg.Tailwind
+	}
+	if cfg.KeyIDs != nil && !set["key-ids"] {
+		o.keyIDs = *cfg.KeyIDs
+	}
+	if cfg.PreserveWhitespace != nil && !set["preserve-whitespace"] {
+		o.preserve = *cfg.PreserveWhitespace
+	}
+	if cfg.StripTestAttrs != nil && !set["strip-test-attrs"] {
+		o.noTests = *cfg.StripTestAttrs
+	}
+	if cfg.Split != nil && !set["split"] {
+		o.split = *cfg.Split
+	}
+	if cfg.Package != "" && !set["package"] {
+		o.pkg = cfg.Package
+	}
+	if cfg.MintyImport != "" && !set["minty-import"] {
+		o.mintyImport = cfg.MintyImport
+	}
+	if cfg.MintydynImport != "" && !set["mintydyn-import"] {
+		o.dynImport = cfg.MintydynImport
+	}
+	if len(cfg.Mappings) > 0 && !set["mappings"] {
+		o.mappings = cfg.Mappings
+	}
+	if cfg.StaticDir != "" && !set["static-dir"] {
+		o.staticDir = cfg.StaticDir
+	}
+	if cfg.Framework != "" && !set["framework"] {
+		o.framework = cfg.Framework
+	}
+	if cfg.Interactivity != "" && !set["interactivity"] {
+		o.interactivity = cfg.Interactivity
+	}
+	if cfg.Templates != "" && !set["templates"] {
+		o.templates = cfg.Templates
+	}
+	o.tagMethods = cfg.Tags
+	o.attributes = cfg.Attributes
+	for _, file := range cfg.Patterns.Rules {
+		rules, err := patterns.LoadRules(file)
+		if err != nil {
+			return err
+		}
+		o.rules = append(o.rules, rules...)
+	}
+	o.detectors = append(cfg.Patterns.Detectors, o.detectors...)
+	o.disabledPatterns = cfg.Patterns.Disable
+	if !set["min-confidence"] {
+		o.minConfidence = cfg.Patterns.MinConfidence
+	}
+	o.ignore = cfg
+	if err := patterns.NewDetector().Disable(o.disabledPatterns...); err != nil {
+		return fmt.Errorf("%s: patterns.disable: %w", cfg.Path, err)
+	}
+	o.alpinePatterns = cfg.Patterns.Alpine
+	if err := patterns.CheckTypes(o.alpinePatterns...); err != nil {
+		return fmt.Errorf("%s: patterns.alpine: %w", cfg.Path, err)
+	}
+	if _, err := o.newGenerator(); err != nil {
+		return fmt.Errorf("%s: %w", cfg.Path, err)
+	}
+	return nil
+}
+
+// setFlags returns the names of the flags given on the command line
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// fingerprint hashes every setting that shapes the generated files, the
+// templates by their content, so that a source converted with other
+// settings is not taken for up to date
+func (o *options) fingerprint() string {
+	var t generator.Templates
+	if o.templates != "" {
+		t, _ = generator.LoadTemplates(o.templates) // checked by newGenerator
+	}
+	data, _ := json.Marshal(struct {
+		*pipeline.Settings
+		TemplateText    generator.Templates
+		Plugins         []string
+		Split, NoFormat bool
+		StaticDir       string
+		MinConfidence   float64
+	}{o.settings(), t, o.plugins, o.split, o.noFormat, o.staticDir, o.minConfidence})
+	return project.Hash(data)
+}
+
+// packageName is the package of the generated files
+func (o *options) packageName() string {
+	if o.pkg == "" {
+		return "main"
+	}
+	return o.pkg
+}
+
+// aliases returns the minty and mintydyn imports of the generated files
+func (o *options) aliases() (generator.Aliases, error) {
+	return o.settings().Aliases()
+}
+
+// rewriteImports applies the configured imports to a generated file
+func (o *options) rewriteImports(src string) string {
+	aliases, _ := o.aliases() // checked by newGenerator
+	return aliases.Rewrite(src)
+}
+
+// handlersSource returns the handlers file of the htmx endpoints, its
+// stubs shaped by the handler template if there is one, keeping
+// session-backed state in the session package at sessionImport if any
+func (o *options) handlersSource(handlers []generator.Handler, sessionImport string) (string, error) {
+	var t generator.Templates
+	if o.templates != "" {
+		var err error
+		if t, err = generator.LoadTemplates(o.templates); err != nil {
+			return "", err
+		}
+	}
+	src, err := generator.HandlersSourceTemplates(o.packageName(), handlers, t, sessionImport)
+	if err != nil {
+		return "", err
+	}
+	return o.rewriteImports(src), nil
+}
+
+// sources lists the JSX sources below dir, leaving out those matching the
+// config file's ignore globs
+func (o *options) sources(dir string) ([]string, error) {
+	sources, err := findSources(dir)
+	if err != nil || o.ignore == nil {
+		return sources, err
+	}
+	kept := sources[:0]
+	for _, source := range sources {
+		if o.ignore.Ignored(filepath.ToSlash(source)) {
+			continue
+		}
+		kept = append(kept, source)
+	}
+	return kept, nil
+}
+
+// configNote describes where the settings of a run came from, for -verbose
+func configNote(cfg *config.Config) string {
+	if cfg == nil {
+		return "No " + config.FileName + " found"
+	}
+	return "Using " + cfg.Path
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/assets"
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// CSSModulesReportFile is written to the output directory in project mode
+const CSSModulesReportFile = "css-modules.json"
+
+// loadCSSModules resolves the CSS Modules imported by the converted
+// source, relative to the directory the source lives in
+func (c *conversion) loadCSSModules(dir string) error {
+	for _, imp := range c.result.File.Imports {
+		source := strings.Trim(imp.Source, "'\"`")
+		if imp.Default == "" || !assets.IsCSSModule(source) {
+			continue
+		}
+		module, err := assets.LoadCSSModule(filepath.Join(dir, filepath.FromSlash(source)))
+		if err != nil {
+			return fmt.Errorf("loading CSS module %s: %w", source, err)
+		}
+		if c.cssModules == nil {
+			c.cssModules = map[string]*assets.CSSModule{}
+		}
+		c.cssModules[imp.Default] = module
+	}
+	return nil
+}
+
+// writeCSSModules copies the rewritten stylesheets into staticDir/css
+func writeCSSModules(staticDir string, modules map[string]*assets.CSSModule) error {
+	if len(modules) == 0 {
+		return nil
+	}
+	cssDir := filepath.Join(staticDir, "css")
+	for _, module := range modules {
+		path := filepath.Join(cssDir, module.OutputName())
+		if err := project.WriteFile(path, []byte(module.Rewrite())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStylesheet writes CSS compiled from styled components to
+// staticDir/css/name, creating parent directories as needed
+func writeStylesheet(staticDir, name, css string) error {
+	if css == "" {
+		return nil
+	}
+	return project.WriteFile(filepath.Join(staticDir, "css", name), []byte(css))
+}
+
+// printCSSModuleIssues lists unresolved CSS Modules accesses
+func printCSSModuleIssues(w io.Writer, file string, issues []generator.CSSModuleIssue) {
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s:%d: unresolved CSS module access %s: %s\n", file, issue.Line, issue.Expr, issue.Reason)
+	}
+}
+
+// cssModulesReport maps every converted stylesheet to its class names and
+// lists the accesses that still need a manual decision
+type cssModulesReport struct {
+	Modules    map[string]*cssModuleReport `json:"modules"`
+	Unresolved []cssModuleAccess           `json:"unresolved,omitempty"`
+}
+
+type cssModuleReport struct {
+	Output  string            `json:"output"`
+	Classes map[string]string `json:"classes"`
+}
+
+type cssModuleAccess struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Expr   string `json:"expr"`
+	Reason string `json:"reason"`
+}
+
+func newCSSModulesReport() *cssModulesReport {
+	return &cssModulesReport{Modules: map[string]*cssModuleReport{}}
+}
+
+// add records the modules and unresolved accesses of one conversion
+func (r *cssModulesReport) add(file, srcDir, cssDir string, c *conversion) {
+	for _, module := range c.cssModules {
+		key := module.Path
+		if rel, err := filepath.Rel(srcDir, module.Path); err == nil {
+			key = filepath.ToSlash(rel)
+		}
+		r.Modules[key] = &cssModuleReport{
+			Output:  path.Join(cssDir, module.OutputName()),
+			Classes: module.Classes,
+		}
+	}
+	for _, issue := range c.cssIssues {
+		r.Unresolved = append(r.Unresolved, cssModuleAccess{
+			File:   file,
+			Line:   issue.Line,
+			Expr:   issue.Expr,
+			Reason: issue.Reason,
+		})
+	}
+}
+
+// save writes the report to dir, or nothing if no CSS Modules were seen
+func (r *cssModulesReport) save(dir string) error {
+	if len(r.Modules) == 0 && len(r.Unresolved) == 0 {
+		return nil
+	}
+	sort.Slice(r.Unresolved, func(i, j int) bool {
+		if r.Unresolved[i].File != r.Unresolved[j].File {
+			return r.Unresolved[i].File < r.Unresolved[j].File
+		}
+		return r.Unresolved[i].Line < r.Unresolved[j].Line
+	})
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return project.WriteFile(filepath.Join(dir, CSSModulesReportFile), append(data, '\n'))
+}
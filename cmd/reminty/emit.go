@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/plugin"
+)
+
+// astDocument is the JSON written by -emit=ast. It has the shape of a
+// plugin request without the source text, so the dump can be fed to a
+// plugin under development.
+type astDocument struct {
+	Version int                 `json:"version"` // parser.ASTVersion
+	File    string              `json:"file"`
+	AST     *parser.ParseResult `json:"ast"`
+}
+
+// emitAST returns the parsed AST of a conversion as indented JSON. Any
+// plugins run first, so the dump shows what the generator would see.
+func emitAST(ctx context.Context, c *conversion, opts *options) (string, error) {
+	result := c.result
+	if len(opts.plugins) > 0 {
+		var err error
+		result, err = plugin.Apply(ctx, opts.plugins, c.file, c.source, result)
+		if err != nil {
+			return "", err
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep JSX and arrow functions readable
+	enc.SetIndent("", "  ")
+	err := enc.Encode(astDocument{
+		Version: parser.ASTVersion,
+		File:    c.file,
+		AST:     result,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding AST: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+)
+
+// formatGo runs a generated Go file through gofmt unless -no-format was
+// given. A file that does not parse (a TODO left where an expression
+// belongs can cause that) is kept as generated, with a note naming it.
+func (o *options) formatGo(name, src string) string {
+	if o.noFormat {
+		return src
+	}
+	out, err := gofmt(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Note: %s left unformatted: %v\n", name, err)
+	}
+	return out
+}
+
+// gofmt formats src, returning it unchanged if it does not parse
+func gofmt(src string) (string, error) {
+	out, err := format.Source([]byte(src))
+	if err != nil {
+		return src, err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/patterns"
+)
+
+// printGitHubAnnotations writes analysis findings as GitHub Actions
+// workflow commands so CI runs annotate the offending JSX lines.
+func printGitHubAnnotations(w io.Writer, file string, detected []patterns.DetectedPattern, result *parser.ParseResult, findings []audit.Finding) {
+	for _, warn := range result.Warnings {
+		writeWorkflowCommand(w, "warning", file, warn.Line, "reminty: parse warning", warn.Message)
+	}
+
+	for _, s := range result.Suggestions {
+		writeWorkflowCommand(w, "warning", file, s.Line, "reminty: "+s.ReactCode, s.MintyHint)
+	}
+
+	for _, p := range detected {
+		msg := fmt.Sprintf("%s (confidence %.0f%%) - consider the minty/mintydyn equivalent", p.Description, p.Confidence*100)
+		writeWorkflowCommand(w, "warning", file, p.Line, "reminty: "+string(p.Type)+" pattern", msg)
+	}
+
+	for _, f := range findings {
+		writeWorkflowCommand(w, "warning", file, f.Line, "reminty: "+f.Rule, f.Message)
+	}
+}
+
+func writeWorkflowCommand(w io.Writer, level, file string, line int, title, message string) {
+	props := []string{"file=" + escapeWorkflowProperty(file)}
+	if line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", line))
+	}
+	props = append(props, "title="+escapeWorkflowProperty(title))
+	fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), escapeWorkflowData(message))
+}
+
+// escapeWorkflowData escapes a workflow command message as the runner expects
+func escapeWorkflowData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeWorkflowProperty additionally escapes the property separators
+func escapeWorkflowProperty(s string) string {
+	s = escapeWorkflowData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
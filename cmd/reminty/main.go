@@ -1,28 +1,75 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/ha1tch/reminty/internal/assets"
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/config"
+	"github.com/ha1tch/reminty/internal/diagnostics"
 	"github.com/ha1tch/reminty/internal/generator"
 	"github.com/ha1tch/reminty/internal/parser"
 	"github.com/ha1tch/reminty/internal/patterns"
+	"github.com/ha1tch/reminty/internal/pipeline"
+	"github.com/ha1tch/reminty/internal/plugin"
+	"github.com/ha1tch/reminty/internal/project"
 )
 
 const version = "0.1.0"
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "rpc":
+			runRPC(os.Args[2:])
+			return
+		case "review":
+			runReview(os.Args[2:])
+			return
+		case "api":
+			runAPI(os.Args[2:])
+			return
+		}
+	}
+
 	// Flags
 	var (
-		outputFile   string
-		analyzeOnly  bool
-		showVersion  bool
-		showHelp     bool
-		verbose      bool
+		outputFile  string
+		analyzeOnly bool
+		showVersion bool
+		showHelp    bool
+		verbose     bool
+		timeout     time.Duration
+		format      string
+		mappings    string
+		tailwind    bool
+		keyIDs      bool
+		preserveWS  bool
+		stripTests  bool
+		split       bool
+		noFormat    bool
+		pkg         string
+		mintyImport string
+		dynImport   string
+		staticDir   string
+		framework   string
+		interactive string
+		templates   string
+		previewOnly bool
+		plugins     stringList
+		configFile  string
+		emit        string
+		minConf     float64
+		ruleFiles   stringList
+		detectors   stringList
 	)
 
 	flag.StringVar(&outputFile, "o", "", "Output file (default: stdout)")
@@ -33,26 +80,112 @@ func main() {
 	flag.BoolVar(&showHelp, "help", false, "Show help")
 	flag.BoolVar(&showHelp, "h", false, "Show help")
 	flag.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flag.DurationVar(&timeout, "timeout", 0, "Abort conversion after this duration (e.g. 5s)")
+	flag.StringVar(&format, "format", "text", "Analysis output format: text, github, sarif, json")
+	flag.BoolVar(&tailwind, "tailwind", false, "Translate static inline styles into Tailwind classes")
+	flag.BoolVar(&keyIDs, "key-ids", false, "Keep the keys of list items htmx swaps as their ids")
+	flag.BoolVar(&preserveWS, "preserve-whitespace", false, "Keep the text of <pre> and <textarea> as written")
+	flag.BoolVar(&stripTests, "strip-test-attrs", false, "Leave data-testid and data-cy attributes out of the output")
+	flag.BoolVar(&split, "split", false, "Write a Go file per component plus a shared types file")
+	flag.BoolVar(&noFormat, "no-format", false, "Write the generated Go as is, without running it through gofmt")
+	flag.StringVar(&pkg, "package", "", "Package clause of the generated files (default: main)")
+	flag.StringVar(&mintyImport, "minty-import", "", "Import path of minty, optionally name=path (default: mi="+generator.MintyImport+")")
+	flag.StringVar(&dynImport, "mintydyn-import", "", "Import path of mintydyn, optionally name=path (default: mdy="+generator.MintydynImport+")")
+	flag.StringVar(&staticDir, "static-dir", "", "Directory for copied stylesheets (default: <output dir>/static)")
+	flag.StringVar(&framework, "framework", "react", "App conventions: "+strings.Join(generator.Frameworks, ", "))
+	flag.StringVar(&interactive, "interactivity", "htmx", "How state changes are made interactive: "+strings.Join(generator.Interactivities, ", "))
+	flag.StringVar(&templates, "templates", "", "Directory of header.tmpl, component.tmpl and handler.tmpl shaping the generated code")
+	flag.StringVar(&configFile, "config", "", "Project config file (default: "+config.FileName+" from the working directory up to the repository root)")
+	flag.Var(&plugins, "plugin", "Transform the parsed AST with an external executable (repeatable)")
+	flag.StringVar(&emit, "emit", "go", "What to output: go, ast (the parsed AST as JSON)")
+	flag.BoolVar(&previewOnly, "preview", false, "Print unified diffs of the files that would be written instead of writing them")
+	flag.Var(&ruleFiles, "rules", "YAML file of pattern rules to detect as well as the built-in ones (repeatable)")
+	flag.Var(&detectors, "detector", "Detect patterns with an external executable as well (repeatable)")
+	flag.Float64Var(&minConf, "min-confidence", 0, "Only report patterns detected with at least this confidence (0 to 1)")
+	flag.StringVar(&mappings, "mappings", "", "Design-system mapping packs to apply (comma-separated: "+strings.Join(generator.MappingPackNames(), ", ")+")")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, `reminty - Convert React/JSX to Go + minty
 
 Usage:
   reminty [options] <input.jsx>
+  reminty -o <outdir> [options] <srcdir>
   reminty [options] < input.jsx
   cat input.jsx | reminty [options]
+  reminty rpc                     # JSON-over-stdin mode for editors
+  reminty review -o <outdir> <src> # Accept components one by one
+  reminty api -listen :8080       # HTTP conversion service
 
 Options:
-  -o, --output <file>   Write output to file (default: stdout)
-  -analyze              Only analyze patterns, don't generate code
+  -o, --output <file>   Write output to file (default: stdout), or the
+                        output directory when converting a directory
+  -analyze              Only analyze patterns, don't generate code; for a
+                        directory, print a project-wide summary
+  -format <fmt>         Analysis output format: text (default), github,
+                        sarif (for code-scanning uploads), json
+  -emit <what>          Output go (default), or ast: the parsed components,
+                        props, state, hooks and element tree as JSON
+  -config <file>        Project config file (default: .reminty.yaml found
+                        from the working directory up to the repository root)
+  -plugin <exe>         Transform the parsed AST with an external program
+                        before generating code (repeatable, run in order)
+  -preview              Print unified diffs of the files that would be
+                        written instead of writing them (requires -o)
+  -tailwind             Translate static inline styles into Tailwind classes
+  -key-ids              Keep the key of a list item that htmx swaps as its
+                        id (user-<key>), instead of dropping it
+  -preserve-whitespace  Keep the text of <pre> and <textarea> as written,
+                        instead of collapsing its whitespace like JSX
+  -strip-test-attrs     Leave the test attributes (data-testid, data-cy...)
+                        out of the generated code
+  -split                Write one Go file per component, plus a shared
+                        <name>_types.go for everything else (requires -o)
+  -no-format            Skip running the generated Go through gofmt
+  -package <name>       Package clause of the generated files (default: main)
+  -minty-import <spec>  Import path of minty in the generated code, or
+                        name=path to change the mi alias as well
+  -mintydyn-import <spec>
+                        The same for mintydyn (mdy), which the pattern
+                        suggestions refer to
+  -mappings <packs>     Render design-system components as HTML + Tailwind
+                        (comma-separated: antd, chakra, mui, shadcn)
+  -static-dir <dir>     Where stylesheets and imported images, fonts and
+                        media are written
+                        (default: static/ next to the output)
+  -rules <file>         Detect the patterns defined in a YAML rules file as
+                        well as the built-in ones (repeatable)
+  -detector <exe>       Detect patterns with an external program as well,
+                        given each parsed file as JSON (repeatable)
+  -min-confidence <c>   Only report patterns detected with at least this
+                        confidence, 0 to 1 (e.g. 0.8); patterns are listed
+                        most confident first
+  -framework <name>     App conventions: react (default), nextjs to turn
+                        getServerSideProps/getStaticProps into Go loaders
+                        and API routes into handler stubs, or solid to read
+                        SolidJS signals, memos and effects
+  -interactivity <mode> htmx (default) to post state changes to endpoints,
+                        or alpine to keep boolean state that only shows
+                        and hides markup in the browser with Alpine.js
+  -templates <dir>      Shape the generated code with the text/template
+                        fragments header.tmpl, component.tmpl and
+                        handler.tmpl found in dir
   -verbose              Show detailed analysis
+  -timeout <duration>   Abort if conversion takes longer (e.g. 5s)
   -v, --version         Show version
   -h, --help            Show this help
 
 Examples:
   reminty Component.jsx                    # Convert and print to stdout
   reminty -o component.go Component.jsx    # Convert to file
+  reminty -o out/ src/components/          # Convert a directory tree
+  reminty -preview -o out/ src/ | git apply  # Review, then apply the changes
   reminty -analyze Component.jsx           # Show pattern analysis only
+  reminty -emit ast Component.jsx          # Dump the parsed AST as JSON
+  reminty -analyze src/                    # Summarize a whole project
+  reminty -mappings mui Form.jsx           # Translate MUI components
+  reminty -analyze -format github App.jsx  # Annotate lines in GitHub Actions
+  reminty -analyze -format sarif src/ > reminty.sarif  # Code-scanning results
+  reminty -analyze -format json src/ | jq .  # Analysis for scripts
   cat Component.jsx | reminty              # Read from stdin
 
 The tool will:
@@ -63,9 +196,9 @@ The tool will:
 
 Not supported (flagged as TODO):
   - Complex hooks (useReducer, useContext with complex state)
-  - Third-party component libraries (Material UI, etc.)
-  - CSS-in-JS (styled-components, emotion)
-  - Dynamic imports
+  - Third-party component libraries without a -mappings pack
+  - CSS-in-JS with prop interpolation (static styled-components compile to CSS)
+  - Dynamic imports other than React.lazy
 
 `)
 	}
@@ -82,13 +215,108 @@ Not supported (flagged as TODO):
 		os.Exit(0)
 	}
 
+	switch format {
+	case "text", "github", "sarif", "json":
+	default:
+		fatalf("Error: unknown format %q (want text, github, sarif or json)\n", format)
+	}
+	switch emit {
+	case "go", "ast":
+	default:
+		fatalf("Error: unknown -emit %q (want go or ast)\n", emit)
+	}
+	if emit == "ast" && analyzeOnly {
+		fatalf("Error: -emit ast and -analyze cannot be combined\n")
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if minConf < 0 || minConf > 1 {
+		fatalf("Error: -min-confidence must be between 0 and 1\n")
+	}
+
+	opts := &options{tailwind: tailwind, keyIDs: keyIDs, preserve: preserveWS, noTests: stripTests, split: split, noFormat: noFormat, pkg: pkg, mintyImport: mintyImport, dynImport: dynImport, staticDir: staticDir, framework: framework, interactivity: interactive, templates: templates, plugins: plugins, detectors: detectors, minConfidence: minConf}
+	if mappings != "" {
+		opts.mappings = strings.Split(mappings, ",")
+	}
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		fatalf("Error reading config: %v\n", err)
+	}
+	if err := opts.applyConfig(cfg, setFlags(flag.CommandLine)); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	for _, file := range ruleFiles {
+		rules, err := patterns.LoadRules(file)
+		if err != nil {
+			fatalf("Error reading rules: %v\n", err)
+		}
+		opts.rules = append(opts.rules, rules...)
+	}
+	if _, err := opts.newGenerator(); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if verbose {
+		fmt.Fprintln(os.Stderr, configNote(cfg))
+	}
+
+	inputDir := false
+	if flag.NArg() > 0 {
+		if info, err := os.Stat(flag.Arg(0)); err == nil && info.IsDir() {
+			inputDir = true
+		}
+	}
+	if inputDir && outputFile == "" && cfg != nil {
+		outputFile = cfg.Output
+	}
+
+	if inputDir && emit == "ast" {
+		fatalf("Error: -emit ast works on a single file\n")
+	}
+
+	if previewOnly {
+		if outputFile == "" {
+			fatalf("Error: -preview requires -o\n")
+		}
+		defer func() {
+			if err := pending.print(os.Stdout); err != nil {
+				fatalf("Error: %v\n", err)
+			}
+		}()
+		startPreview()
+	}
+
+	// Directory input: convert the whole tree into the output directory
+	if inputDir {
+		if analyzeOnly {
+			if err := analyzeProject(ctx, flag.Arg(0), opts, format); err != nil {
+				fatalf("Error: %v\n", err)
+			}
+			return
+		}
+		if outputFile == "" {
+			fatalf("Error: converting a directory requires -o <output dir> (or output: in %s)\n", config.FileName)
+		}
+		if err := convertProject(ctx, flag.Arg(0), outputFile, opts, verbose); err != nil {
+			fatalf("Error: %v\n", err)
+		}
+		return
+	}
+
 	// Get input
 	var input string
 	var inputName string
+	var inputPath string
 
 	if flag.NArg() > 0 {
 		// Read from file
 		inputFile := flag.Arg(0)
+		inputPath = inputFile
 		inputName = filepath.Base(inputFile)
 		data, err := os.ReadFile(inputFile)
 		if err != nil {
@@ -99,6 +327,7 @@ Not supported (flagged as TODO):
 	} else {
 		// Read from stdin
 		inputName = "stdin"
+		inputPath = inputName
 		data, err := io.ReadAll(os.Stdin)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
@@ -113,71 +342,396 @@ Not supported (flagged as TODO):
 		os.Exit(1)
 	}
 
-	// Parse
-	lexer := parser.NewLexer(input)
-	tokens := lexer.Tokenize()
-
-	if verbose {
-		fmt.Fprintf(os.Stderr, "Parsed %d tokens from %s\n", len(tokens), inputName)
+	conv, err := analyze(ctx, input, opts)
+	if err != nil {
+		fatalf("Error processing %s: %v\n", inputName, err)
 	}
+	conv.file = inputName
+	result := conv.result
+	detectedPatterns := conv.patterns
 
-	p := parser.NewParserWithSource(tokens, input)
-	result := p.Parse()
+	if flag.NArg() > 0 {
+		if err := conv.loadCSSModules(filepath.Dir(inputPath)); err != nil {
+			fatalf("Error: %v\n", err)
+		}
+		conv.loadAssets(filepath.Dir(inputPath), filepath.Dir(inputPath))
+	}
 
 	if verbose {
+		fmt.Fprintf(os.Stderr, "Parsed %d tokens from %s\n", conv.tokens, inputName)
 		fmt.Fprintf(os.Stderr, "Found %d components, %d imports\n",
 			len(result.File.Components), len(result.File.Imports))
 	}
 
-	// Detect patterns
-	detector := patterns.NewDetector()
-	detectedPatterns := detector.AnalyzeSource(input)
-
-	// Also analyze the parsed result
-	parsedPatterns := detector.Analyze(result)
-	detectedPatterns = append(detectedPatterns, parsedPatterns...)
-
-	if verbose || analyzeOnly {
-		printPatternAnalysis(detectedPatterns, result)
+	if format == "github" && analyzeOnly {
+		printGitHubAnnotations(os.Stdout, filepath.ToSlash(inputPath), detectedPatterns, result, conv.findings)
+	} else if format == "json" && analyzeOnly {
+		analysis := fileAnalysisOf(filepath.ToSlash(inputPath), conv)
+		if err := writeAnalysisJSON(os.Stdout, []fileAnalysis{analysis}); err != nil {
+			fatalf("Error: %v\n", err)
+		}
+	} else if format == "sarif" && analyzeOnly {
+		diags := diagnostics.Collect(filepath.ToSlash(inputPath), result, detectedPatterns, conv.findings)
+		if err := writeSARIF(os.Stdout, diags); err != nil {
+			fatalf("Error: %v\n", err)
+		}
+	} else if verbose || analyzeOnly {
+		printPatternAnalysis(detectedPatterns, result, conv.findings)
 	}
 
 	if analyzeOnly {
 		os.Exit(0)
 	}
 
+	if emit == "ast" {
+		output, err := emitAST(ctx, conv, opts)
+		if err != nil {
+			fatalf("Error processing %s: %v\n", inputName, err)
+		}
+		writeOutput(outputFile, output)
+		return
+	}
+
+	if opts.split && outputFile == "" {
+		fatalf("Error: -split requires -o\n")
+	}
+
 	// Generate code
-	gen := generator.NewGenerator()
-	output := gen.Generate(result)
-
-	// Add pattern suggestions as comments
-	if len(detectedPatterns) > 0 {
-		output += "\n// =============================================================================\n"
-		output += "// DETECTED PATTERNS - CONSIDER USING MINTYDYN\n"
-		output += "// =============================================================================\n"
-		for _, p := range detectedPatterns {
-			output += fmt.Sprintf("//\n// %s (line %d, confidence: %.0f%%)\n", p.Description, p.Line, p.Confidence*100)
-			output += fmt.Sprintf("// React: %s\n", p.ReactCode)
-			output += "// Minty equivalent:\n"
-			for _, line := range strings.Split(p.MintyCode, "\n") {
-				output += fmt.Sprintf("//   %s\n", line)
+	if err := conv.generate(ctx, opts); err != nil {
+		fatalf("Error generating code for %s: %v\n", inputName, err)
+	}
+	output := conv.output
+	if conv.result.File.Native != nil {
+		fmt.Fprintf(os.Stderr, "Note: %s imports react-native; writing a report of its web equivalents instead of code\n", inputName)
+	}
+	if len(conv.streams) > 0 {
+		if outputFile == "" {
+			fmt.Fprintf(os.Stderr, "Note: %d realtime stream(s) detected; use -o to generate %s\n", len(conv.streams), generator.SSEFile)
+		} else {
+			path := filepath.Join(filepath.Dir(outputFile), generator.SSEFile)
+			if err := project.WriteFile(path, []byte(generator.SSESource(opts.packageName(), conv.streams))); err != nil {
+				fatalf("Error writing %s: %v\n", path, err)
+			}
+		}
+	}
+	if len(conv.handlers) > 0 {
+		if outputFile == "" {
+			fmt.Fprintf(os.Stderr, "Note: %d event handler endpoint(s) generated; use -o to generate %s\n", len(conv.handlers), generator.HandlersFile)
+		} else {
+			path := filepath.Join(filepath.Dir(outputFile), generator.HandlersFile)
+			src, err := opts.handlersSource(conv.handlers, "")
+			if err != nil {
+				fatalf("Error generating %s: %v\n", path, err)
+			}
+			if err := project.WriteFile(path, []byte(src)); err != nil {
+				fatalf("Error writing %s: %v\n", path, err)
+			}
+		}
+	}
+	if conv.result.File.Stories != nil {
+		if outputFile == "" {
+			fmt.Fprintf(os.Stderr, "Note: Storybook stories converted into a test; use -o to generate %s\n", generator.StoriesFile)
+		} else {
+			path := filepath.Join(filepath.Dir(outputFile), generator.StoriesFile)
+			if err := project.WriteFile(path, []byte(opts.rewriteImports(generator.StoriesSource(opts.packageName())))); err != nil {
+				fatalf("Error writing %s: %v\n", path, err)
+			}
+		}
+	}
+	if len(conv.messages) > 0 {
+		if outputFile == "" {
+			fmt.Fprintf(os.Stderr, "Note: %d translatable string(s) found; use -o to generate the go-i18n catalog and %s\n", len(conv.messages), generator.I18nFile)
+		} else if err := writeI18n(filepath.Dir(outputFile), opts.packageName(), conv.messages, nil); err != nil {
+			fatalf("Error writing message catalog: %v\n", err)
+		}
+	}
+	if verbose && len(conv.unmapped) > 0 {
+		m := project.NewManifest()
+		m.Record(&project.FileEntry{Source: inputName, Unmapped: conv.unmapped})
+		printUnmapped(os.Stderr, m.Unmapped())
+	}
+	printCSSModuleIssues(os.Stderr, inputName, conv.cssIssues)
+	printAssetIssues(os.Stderr, inputName, conv)
+
+	if len(conv.cssModules) > 0 || conv.stylesheet != "" || len(conv.assets) > 0 {
+		dir := opts.staticDir
+		if dir == "" && outputFile != "" {
+			dir = filepath.Join(filepath.Dir(outputFile), "static")
+		}
+		if dir == "" {
+			fmt.Fprintln(os.Stderr, "Note: static files not written; use -o or -static-dir to write them")
+		} else {
+			if err := writeCSSModules(dir, conv.cssModules); err != nil {
+				fatalf("Error writing stylesheets: %v\n", err)
+			}
+			name := strings.TrimSuffix(outputName(filepath.Base(inputName)), ".go") + ".css"
+			if err := writeStylesheet(dir, name, conv.stylesheet); err != nil {
+				fatalf("Error writing stylesheets: %v\n", err)
+			}
+			if err := copyAssets(dir, conv.assets); err != nil {
+				fatalf("Error: %v\n", err)
+			}
+			if outputFile != "" {
+				if err := writeEmbedScaffold(filepath.Dir(outputFile), dir, opts.packageName()); err != nil {
+					fatalf("Error writing %s: %v\n", assets.EmbedFile, err)
+				}
 			}
 		}
 	}
 
-	// Write output
+	if opts.split {
+		if err := opts.writeGo(outputFile, output, conv.stats); err != nil {
+			fatalf("Error writing output: %v\n", err)
+		}
+		if pending == nil {
+			fmt.Fprintf(os.Stderr, "Written %d component file(s) and %s\n", len(conv.stats), opts.goOutput(outputFile))
+		}
+		return
+	}
+	name := outputFile
+	if name == "" {
+		name = "output"
+	}
+	writeOutput(outputFile, opts.formatGo(name, output))
+}
+
+// writeOutput writes the result for a single input to outputFile, or to
+// stdout when no file was given
+func writeOutput(outputFile, output string) {
 	if outputFile != "" {
-		err := os.WriteFile(outputFile, []byte(output), 0644)
+		err := project.WriteFile(outputFile, []byte(output))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Written to %s\n", outputFile)
+		if pending == nil {
+			fmt.Fprintf(os.Stderr, "Written to %s\n", outputFile)
+		}
 	} else {
 		fmt.Print(output)
 	}
 }
 
-func printPatternAnalysis(patterns []patterns.DetectedPattern, result *parser.ParseResult) {
+// conversion holds the intermediate and final results for one input
+type conversion struct {
+	file     string // source path shown in generated comments
+	source   string
+	tokens   int
+	result   *parser.ParseResult
+	patterns []patterns.DetectedPattern
+	findings []audit.Finding // accessibility and code-quality audit results
+	output   string
+	stats    []generator.ComponentStat
+
+	cssModules map[string]*assets.CSSModule // imported CSS Modules by local name
+	cssIssues  []generator.CSSModuleIssue   // unresolved CSS Modules accesses
+	stylesheet string                       // CSS compiled from styled components
+
+	assets        []staticAsset          // imported static files to copy
+	assetPaths    map[string]string      // import name or require path → public path
+	missingAssets []string               // imported assets not found on disk
+	assetIssues   []generator.AssetIssue // dynamic asset paths left as-is
+
+	streams  []generator.SSEStream                  // realtime state served over SSE
+	handlers []generator.Handler                    // htmx endpoints replacing event handlers
+	loaders  []generator.PageLoader                 // Go loaders replacing Next.js data loaders
+	messages []generator.Message                    // translatable strings for the go-i18n catalogs
+	calls    []generator.CallSite                   // component calls made by the generated code
+	imports  map[string]generator.ImportedComponent // components imported from other converted files
+	reserved map[string]bool                        // names the other converted files declare
+	types    []generator.ItemStruct                 // item structs generated for mapped collections
+	decls    []generator.Declaration                // package-level vars and helpers it declares
+	unmapped []generator.Unmapped                   // tags and attributes without a minty mapping
+}
+
+// analyze lexes, parses and pattern-checks a JSX source
+func analyze(ctx context.Context, input string, opts *options) (*conversion, error) {
+	solid := opts != nil && opts.framework == "solid"
+	source := input
+	if solid {
+		input = parser.DesugarSolid(input)
+	}
+	// Detect patterns in the raw source, the parsed result and with any
+	// external detectors
+	detector, err := opts.newDetector()
+	if err != nil {
+		return nil, err
+	}
+	result, detected, tokens, err := pipeline.Analyze(ctx, input, detector)
+	if err != nil {
+		return nil, err
+	}
+	if solid {
+		pipeline.SolidNames(source, result, detected)
+	}
+	min := 0.0
+	if opts != nil {
+		min = opts.minConfidence
+	}
+
+	return &conversion{
+		source:   input,
+		tokens:   tokens,
+		result:   result,
+		patterns: patterns.Rank(detected, min),
+		findings: audit.Run(result.File, input),
+	}, nil
+}
+
+// options carries the command-line settings that shape code generation
+type options struct {
+	mappings []string // design-system mapping packs
+	tailwind bool     // translate inline styles to Tailwind classes
+	keyIDs   bool     // keep the keys of swapped list items as ids
+	preserve bool     // keep the whitespace of <pre> and <textarea> text
+	noTests  bool     // leave data-testid and data-cy out
+	split    bool     // write a Go file per component
+	noFormat bool     // skip gofmt on the generated files
+
+	staticDir     string   // where copied stylesheets go
+	framework     string   // app conventions: react, nextjs or solid
+	interactivity string   // htmx or alpine
+	templates     string   // directory of the code templates
+	plugins       []string // external AST transforms, run in order
+	detectors     []string // external pattern detectors
+
+	pkg         string // package of the generated files
+	mintyImport string // [name=]path of minty
+	dynImport   string // [name=]path of mintydyn
+
+	// Set from the project config file
+	tagMethods       map[string]string // tag → builder method overrides
+	attributes       map[string]string // attribute → option function overrides
+	disabledPatterns []string          // pattern types not to report
+	alpinePatterns   []string          // pattern types whose state Alpine keeps
+	rules            []patterns.Rule   // pattern rules of the project's own
+	minConfidence    float64           // patterns detected with less confidence are not reported
+	ignore           *config.Config    // ignore globs for directory mode
+}
+
+// stringList is a flag that may be given more than once
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// settings returns the options that shape generation and detection
+func (o *options) settings() *pipeline.Settings {
+	return &pipeline.Settings{
+		Mappings:           o.mappings,
+		Tailwind:           o.tailwind,
+		KeyIDs:             o.keyIDs,
+		PreserveWhitespace: o.preserve,
+		StripTestAttrs:     o.noTests,
+		Framework:          o.framework,
+		Interactivity:      o.interactivity,
+		Package:            o.pkg,
+		Templates:          o.templates,
+		MintyImport:        o.mintyImport,
+		MintydynImport:     o.dynImport,
+		Tags:               o.tagMethods,
+		Attributes:         o.attributes,
+		Rules:              o.rules,
+		Detectors:          o.detectors,
+		DisablePatterns:    o.disabledPatterns,
+		AlpinePatterns:     o.alpinePatterns,
+	}
+}
+
+// newGenerator returns a generator configured from the options
+func (o *options) newGenerator() (*generator.Generator, error) {
+	return o.settings().NewGenerator()
+}
+
+// newDetector returns a pattern detector configured from the options;
+// nil options give the defaults
+func (o *options) newDetector() (*patterns.Detector, error) {
+	if o == nil {
+		return patterns.NewDetector(), nil
+	}
+	return o.settings().NewDetector()
+}
+
+// generate produces the Go output, including pattern suggestions as comments
+func (c *conversion) generate(ctx context.Context, opts *options) error {
+	gen, err := opts.newGenerator()
+	if err != nil {
+		return err
+	}
+	if len(opts.plugins) > 0 {
+		result, err := plugin.Apply(ctx, opts.plugins, c.file, c.source, c.result)
+		if err != nil {
+			return err
+		}
+		c.result = result
+	}
+	gen.UseCSSModules(c.cssModules)
+	gen.UseAssets(c.assetPaths)
+	c.streams = c.realtimeStreams()
+	gen.UseRealtime(c.streams)
+	gen.UseAlpineStates(patterns.StatesOf(c.patterns, opts.alpinePatterns))
+	gen.UseImports(c.imports)
+	gen.UseReservedNames(c.reserved)
+	output, err := gen.GenerateContext(ctx, c.result)
+	if err != nil {
+		return err
+	}
+	aliases, _ := opts.aliases() // checked by newGenerator
+	c.output = aliases.Rewrite(output + patterns.Notes(c.patterns))
+	c.stats = gen.Stats()
+	for i := range c.stats {
+		c.stats[i].Code = aliases.Rewrite(c.stats[i].Code)
+	}
+	c.cssIssues = gen.CSSModuleIssues()
+	c.stylesheet = gen.Stylesheet()
+	c.assetIssues = gen.AssetIssues()
+	c.calls = gen.Calls()
+	c.types = gen.ItemStructs()
+	c.decls = gen.Declarations()
+	c.unmapped = gen.Unmapped()
+	c.loaders = gen.PageLoaders()
+	c.handlers = gen.Handlers()
+	for i := range c.handlers {
+		c.handlers[i].File = c.file
+	}
+	c.messages = gen.Messages()
+	for i := range c.messages {
+		c.messages[i].File = c.file
+	}
+	return nil
+}
+
+// realtimeStreams turns detected realtime patterns into SSE streams
+func (c *conversion) realtimeStreams() []generator.SSEStream {
+	var streams []generator.SSEStream
+	for _, p := range c.patterns {
+		if p.Type != patterns.PatternRealtime {
+			continue
+		}
+		for _, state := range p.StateVars {
+			streams = append(streams, generator.SSEStream{
+				State:    state,
+				Endpoint: patterns.SSEEndpoint(state),
+				Source:   strings.TrimPrefix(p.Description, "Realtime updates via "),
+				File:     c.file,
+				Line:     p.Line,
+			})
+		}
+	}
+	return streams
+}
+
+// fatalf prints an error message to stderr and exits with status 1
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+	os.Exit(1)
+}
+
+func printPatternAnalysis(patterns []patterns.DetectedPattern, result *parser.ParseResult, findings []audit.Finding) {
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "=== PATTERN ANALYSIS ===")
 	fmt.Fprintln(os.Stderr, "")
@@ -194,6 +748,56 @@ func printPatternAnalysis(patterns []patterns.DetectedPattern, result *parser.Pa
 		}
 	}
 
+	// Server and client components
+	if result.File.Directive != "" {
+		fmt.Fprintf(os.Stderr, "Directive: '%s'\n", result.File.Directive)
+	}
+	rsc := false
+	for _, comp := range result.File.Components {
+		switch comp.Rendering {
+		case parser.RenderServer:
+			fmt.Fprintf(os.Stderr, "Server component: %s (line %d), converts directly\n", comp.Name, comp.LineNumber)
+		case parser.RenderClient:
+			fmt.Fprintf(os.Stderr, "Client component: %s (line %d), needs HTMX or mintydyn for %s\n", comp.Name, comp.LineNumber, strings.Join(comp.Interactive, ", "))
+		default:
+			continue
+		}
+		rsc = true
+	}
+	for _, action := range result.File.ServerActions {
+		fmt.Fprintf(os.Stderr, "Server action: %s (line %d) → POST %s\n", action.Name, action.LineNumber, generator.ServerActionEndpoint(action.Name))
+		rsc = true
+	}
+	if rsc || result.File.Directive != "" {
+		fmt.Fprintln(os.Stderr, "")
+	}
+
+	// Portals, served as partials swapped into a layout container
+	portals := false
+	for _, comp := range result.File.Components {
+		if comp.Portal != nil {
+			fmt.Fprintf(os.Stderr, "Portal: %s (line %d) renders into %s → partial for #%s\n", comp.Name, comp.Portal.LineNumber, comp.Portal.Container, generator.PortalContainer(comp.Portal))
+			portals = true
+		}
+	}
+	if portals {
+		fmt.Fprintln(os.Stderr, "")
+	}
+
+	// React Native primitives, which are reported rather than converted
+	if result.File.Native != nil {
+		fmt.Fprintln(os.Stderr, "React Native (report only, no code is generated):")
+		for _, use := range result.File.Native {
+			text, ok := generator.NativeEquivalent(use)
+			if ok {
+				fmt.Fprintf(os.Stderr, "  %s → %s\n", use.Name, text)
+			} else {
+				fmt.Fprintf(os.Stderr, "  %s (%s): cannot be converted, %s\n", use.Name, use.Module, text)
+			}
+		}
+		fmt.Fprintln(os.Stderr, "")
+	}
+
 	// Suggestions from parsing
 	if len(result.Suggestions) > 0 {
 		fmt.Fprintln(os.Stderr, "Hook migration suggestions:")
@@ -228,11 +832,23 @@ func printPatternAnalysis(patterns []patterns.DetectedPattern, result *parser.Pa
 		}
 	}
 
+	// Audit findings
+	if len(findings) > 0 {
+		fmt.Fprintln(os.Stderr, "Audit findings:")
+		for _, f := range findings {
+			fmt.Fprintf(os.Stderr, "  Line %d [%s] %s: %s\n", f.Line, f.Rule, f.Component, f.Message)
+		}
+		fmt.Fprintln(os.Stderr, "")
+	}
+
 	// Warnings
 	if len(result.Warnings) > 0 {
 		fmt.Fprintln(os.Stderr, "Warnings:")
 		for _, w := range result.Warnings {
 			fmt.Fprintf(os.Stderr, "  Line %d: %s\n", w.Line, w.Message)
+			if w.Skipped != nil && w.Skipped.EndLine > w.Skipped.Line {
+				fmt.Fprintf(os.Stderr, "    skipped lines %d-%d: %s\n", w.Skipped.Line, w.Skipped.EndLine, w.Skipped.Text)
+			}
 		}
 		fmt.Fprintln(os.Stderr, "")
 	}
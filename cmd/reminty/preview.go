@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/diff"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// preview collects the files a run would write, so -preview can print
+// them as unified diffs against what is on disk instead of writing them
+type preview struct {
+	paths []string
+	files map[string][]byte
+}
+
+// pending is the active preview, or nil when files are written normally
+var pending *preview
+
+// startPreview redirects every generated file into a new preview
+func startPreview() *preview {
+	pending = &preview{files: map[string][]byte{}}
+	project.WriteFile = pending.write
+	return pending
+}
+
+func (p *preview) write(path string, data []byte) error {
+	path = filepath.Clean(path)
+	if _, ok := p.files[path]; !ok {
+		p.paths = append(p.paths, path)
+	}
+	p.files[path] = append([]byte(nil), data...)
+	return nil
+}
+
+// hasFilesIn reports whether the preview holds a file below dir
+func (p *preview) hasFilesIn(dir string) bool {
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	for _, path := range p.paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// print writes a git-style diff for every collected file that differs
+// from the file on disk; the result applies with `git apply`
+func (p *preview) print(w io.Writer) error {
+	for _, path := range p.paths {
+		old, err := os.ReadFile(path)
+		created := os.IsNotExist(err)
+		if err != nil && !created {
+			return err
+		}
+		data := p.files[path]
+		if !created && bytes.Equal(old, data) {
+			continue
+		}
+
+		name := diffPath(path)
+		fmt.Fprintf(w, "diff --git a/%s b/%s\n", name, name)
+		oldName := "a/" + name
+		if created {
+			fmt.Fprintln(w, "new file mode 100644")
+			oldName = "/dev/null"
+		}
+		if isBinary(old) || isBinary(data) {
+			fmt.Fprintf(w, "Binary files %s and b/%s differ\n", oldName, name)
+			continue
+		}
+		fmt.Fprint(w, diff.Unified(oldName, "b/"+name, string(old), string(data)))
+	}
+	return nil
+}
+
+// diffPath names path relative to the working directory when it is
+// below it, with forward slashes as diffs expect
+func diffPath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ha1tch/reminty/internal/assets"
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// sourceExtensions lists the file types picked up in directory mode
+var sourceExtensions = map[string]bool{
+	".jsx": true,
+	".tsx": true,
+	".js":  true,
+}
+
+// findSources returns the JSX sources below dir, relative to dir
+func findSources(dir string) ([]string, error) {
+	var sources []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "node_modules" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !sourceExtensions[filepath.Ext(path)] {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, rel)
+		return nil
+	})
+	return sources, err
+}
+
+// outputName maps a source path such as components/TaskBoard.jsx to the
+// Go file it is converted into (components_task_board.go). The output is
+// one package, so subdirectories are folded into the file name. A
+// Storybook file becomes a test file (Button.stories.jsx →
+// button_stories_test.go).
+func outputName(source string) string {
+	dir, base := filepath.Split(source)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	var parts []string
+	for _, d := range strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/") {
+		if d != "." && d != "" {
+			parts = append(parts, toSnakeCase(d))
+		}
+	}
+	name := strings.Join(append(parts, toSnakeCase(base)), "_")
+	if isStories(source) {
+		return name + "_test.go"
+	}
+	return name + ".go"
+}
+
+// isStories reports whether source is a Storybook file, such as
+// Button.stories.jsx
+func isStories(source string) bool {
+	base := filepath.Base(source)
+	return strings.HasSuffix(strings.TrimSuffix(base, filepath.Ext(base)), ".stories")
+}
+
+// toSnakeCase converts PascalCase or kebab-case to snake_case
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '-' || r == '.' || r == ' ':
+			b.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// convertProject converts every JSX source under srcDir into outDir,
+// skipping files the manifest shows are already up to date, then
+// refreshes the manifest and the cumulative metrics export.
+func convertProject(ctx context.Context, srcDir, outDir string, opts *options, verbose bool) error {
+	sources, err := opts.sources(srcDir)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := project.LoadManifest(outDir)
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+
+	// Sources deleted or excluded since the last run leave no output
+	// behind, so the metrics and the generated package cover the current
+	// tree only
+	current := map[string]bool{}
+	for _, source := range sources {
+		current[filepath.ToSlash(source)] = true
+	}
+	for _, entry := range manifest.Prune(current) {
+		if err := removeOutputs(outDir, entry); err != nil {
+			return fmt.Errorf("removing the output of %s: %w", entry.Source, err)
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Removed %s, whose source %s is gone\n", entry.Output, entry.Source)
+		}
+	}
+
+	staticDir := opts.staticDir
+	if staticDir == "" {
+		staticDir = filepath.Join(outDir, "static")
+	}
+	cssDir := filepath.Join(staticDir, "css")
+	if rel, err := filepath.Rel(outDir, cssDir); err == nil {
+		cssDir = rel
+	}
+	report := newCSSModulesReport()
+	assetManifest, err := loadAssetManifest(outDir)
+	if err != nil {
+		return fmt.Errorf("reading asset manifest: %w", err)
+	}
+
+	// Collect the components of every source first, so that a file calls
+	// the components it imports by their generated signatures
+	symbols := project.NewSymbolTable()
+	contents := map[string][]byte{}
+	changed := map[string]bool{}
+	outputs := map[string]string{} // Go file → the source converted into it
+	dropped := map[string]error{}  // sources left out, and why
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(source)
+		if other, ok := outputs[outputName(source)]; ok {
+			dropped[key] = fmt.Errorf("%s converts into %s already", other, outputName(source))
+			continue
+		}
+		outputs[outputName(source)] = key
+		data, err := os.ReadFile(filepath.Join(srcDir, source))
+		if err != nil {
+			return err
+		}
+		contents[key] = data
+
+		if manifest.Unchanged(key, data, version, opts.fingerprint()) {
+			if _, err := os.Stat(filepath.Join(outDir, opts.goOutput(outputName(source)))); err == nil {
+				symbols.Add(manifest.Files[key])
+				continue
+			}
+		}
+		changed[key] = true
+		conv, err := analyze(ctx, string(data), opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if err := conv.generate(ctx, opts); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		symbols.Add(conv.manifestEntry(key, filepath.ToSlash(outputName(source)), data, opts))
+	}
+
+	// A source that would redeclare a component or overwrite the output of
+	// another is left out, and its output of an earlier run removed, so
+	// that the rest of the package still builds
+	for source, err := range symbols.Collisions() {
+		dropped[source] = err
+	}
+	for _, source := range sources {
+		key := filepath.ToSlash(source)
+		err, ok := dropped[key]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Skipped %s: %v; rename it to convert the file\n", key, err)
+		if entry := manifest.Files[key]; entry != nil {
+			delete(manifest.Files, key)
+			if outputs[outputName(source)] == key {
+				if err := removeOutputs(outDir, entry); err != nil {
+					return fmt.Errorf("removing the output of %s: %w", key, err)
+				}
+			}
+		}
+	}
+	sources = slices.DeleteFunc(sources, func(source string) bool {
+		return dropped[filepath.ToSlash(source)] != nil
+	})
+	// An item struct, var or helper another file declares too is named
+	// after its component, and calls of the file's components take the
+	// new name
+	for _, source := range sources {
+		key := filepath.ToSlash(source)
+		if !symbols.Renamed(key) {
+			continue
+		}
+		changed[key] = true
+		conv, err := analyze(ctx, string(contents[key]), opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		conv.reserved = symbols.Reserved(key)
+		if err := conv.generate(ctx, opts); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		symbols.Add(conv.manifestEntry(key, filepath.ToSlash(outputName(source)), contents[key], opts))
+	}
+
+	converted, skipped := 0, 0
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(source)
+		data := contents[key]
+		outPath := filepath.Join(outDir, outputName(source))
+		if !changed[key] && !importsChanged(manifest.Files[key], changed) {
+			skipped++
+			continue
+		}
+
+		conv, err := analyze(ctx, string(data), opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		conv.imports = symbols.Resolve(key, conv.result.File.Imports)
+		conv.reserved = symbols.Reserved(key)
+		if err := conv.loadCSSModules(filepath.Dir(filepath.Join(srcDir, source))); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		conv.file = key
+		conv.loadAssets(filepath.Dir(filepath.Join(srcDir, source)), srcDir)
+		if err := conv.generate(ctx, opts); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if err := copyAssets(staticDir, conv.assets); err != nil {
+			return err
+		}
+		assetManifest.update(key, conv)
+		if err := writeCSSModules(staticDir, conv.cssModules); err != nil {
+			return err
+		}
+		stylesheet := strings.TrimSuffix(outputName(source), ".go") + ".css"
+		if err := writeStylesheet(staticDir, stylesheet, conv.stylesheet); err != nil {
+			return err
+		}
+		report.add(key, srcDir, filepath.ToSlash(cssDir), conv)
+
+		if err := opts.writeGo(outPath, conv.output, conv.stats); err != nil {
+			return err
+		}
+
+		entry := conv.manifestEntry(key, filepath.ToSlash(opts.goOutput(outputName(source))), data, opts)
+		if _, ok := project.APIRoute(key); ok && opts.framework == "nextjs" {
+			file := conv.result.File
+			entry.API = project.APIMethods(key, file.Exports, file.DefaultExport)
+		}
+		manifest.Record(entry)
+		converted++
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Converted %s → %s\n", source, outPath)
+		}
+	}
+
+	if err := manifest.Save(outDir); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	if err := manifest.SaveMetrics(outDir); err != nil {
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	sessionImport := ""
+	if vars := manifest.SessionVars(); len(vars) > 0 {
+		sessionImport = packageImportPath(filepath.Join(outDir, project.SessionDir))
+		dir := filepath.Join(outDir, project.SessionDir)
+		if err := project.WriteFile(filepath.Join(dir, "session.go"), []byte(project.SessionSource(vars))); err != nil {
+			return fmt.Errorf("writing session package: %w", err)
+		}
+	}
+	if routes := manifest.Routes(); len(routes) > 0 {
+		source := opts.rewriteImports(project.RoutesSource(opts.packageName(), routes, manifest.Layout(), sessionImport))
+		if err := project.WriteFile(filepath.Join(outDir, project.RoutesFile), []byte(source)); err != nil {
+			return fmt.Errorf("writing %s: %w", project.RoutesFile, err)
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Generated %d route(s) in %s\n", len(routes), project.RoutesFile)
+		}
+	}
+	if streams := manifest.Streams(); len(streams) > 0 {
+		if err := project.WriteFile(filepath.Join(outDir, generator.SSEFile), []byte(generator.SSESource(opts.packageName(), streams))); err != nil {
+			return fmt.Errorf("writing %s: %w", generator.SSEFile, err)
+		}
+	}
+	if slices.ContainsFunc(sources, isStories) {
+		if err := project.WriteFile(filepath.Join(outDir, generator.StoriesFile), []byte(opts.rewriteImports(generator.StoriesSource(opts.packageName())))); err != nil {
+			return fmt.Errorf("writing %s: %w", generator.StoriesFile, err)
+		}
+	}
+	if handlers := manifest.Handlers(); len(handlers) > 0 {
+		src, err := opts.handlersSource(handlers, sessionImport)
+		if err != nil {
+			return fmt.Errorf("generating %s: %w", generator.HandlersFile, err)
+		}
+		if err := project.WriteFile(filepath.Join(outDir, generator.HandlersFile), []byte(src)); err != nil {
+			return fmt.Errorf("writing %s: %w", generator.HandlersFile, err)
+		}
+	}
+	locales, err := project.LoadI18nextLocales(srcDir)
+	if err != nil {
+		return fmt.Errorf("reading i18next locales: %w", err)
+	}
+	if messages := manifest.Messages(); len(messages) > 0 {
+		if err := writeI18n(outDir, opts.packageName(), messages, locales); err != nil {
+			return fmt.Errorf("writing message catalogs: %w", err)
+		}
+	}
+	if err := writeEmbedScaffold(outDir, staticDir, opts.packageName()); err != nil {
+		return fmt.Errorf("writing %s: %w", assets.EmbedFile, err)
+	}
+	if err := assetManifest.save(outDir); err != nil {
+		return fmt.Errorf("writing asset manifest: %w", err)
+	}
+	if err := report.save(outDir); err != nil {
+		return fmt.Errorf("writing CSS modules report: %w", err)
+	}
+
+	if err := manifest.SaveUnmapped(outDir); err != nil {
+		return fmt.Errorf("writing %s: %w", project.UnmappedFile, err)
+	}
+	printUnmapped(os.Stderr, manifest.Unmapped())
+
+	if issues := manifest.CallSiteIssues(); len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "Component call mismatches (%d):\n", len(issues))
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "  %s\n", issue)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Converted %d file(s), %d unchanged, output in %s\n", converted, skipped, outDir)
+	return nil
+}
+
+// removeOutputs deletes the Go files converted from a source: its output
+// and, when it was split, a file per component next to it
+func removeOutputs(outDir string, entry *project.FileEntry) error {
+	files := []string{entry.Output}
+	if strings.HasSuffix(entry.Output, "_types.go") {
+		for _, comp := range entry.Components {
+			files = append(files, path.Join(path.Dir(entry.Output), toSnakeCase(comp.Name)+".go"))
+		}
+	}
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(outDir, filepath.FromSlash(file))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// importsChanged reports whether a file calls components of a source that
+// is converted again, whose signatures may have changed
+func importsChanged(entry *project.FileEntry, changed map[string]bool) bool {
+	for _, source := range entry.Imports {
+		if changed[source] {
+			return true
+		}
+	}
+	return false
+}
+
+// printUnmapped lists the most frequent tags and attributes that fell
+// back to El() or mi.Attr, with an example location for each
+func printUnmapped(w io.Writer, report project.UnmappedReport) {
+	sections := []struct {
+		title   string
+		entries []project.UnmappedEntry
+	}{
+		{"Unmapped tags, rendered with El()", report.Tags},
+		{"Unmapped attributes, rendered with mi.Attr", report.Attributes},
+	}
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s (%d):\n", section.title, len(section.entries))
+		for i, e := range section.entries {
+			if i == summaryTop {
+				fmt.Fprintf(w, "  ... and %d more in %s\n", len(section.entries)-summaryTop, project.UnmappedFile)
+				break
+			}
+			fmt.Fprintf(w, "  %4d  %-20s %s\n", e.Count, e.Name, e.Examples[0])
+		}
+	}
+}
+
+// writeI18n writes the go-i18n catalogs into dir/locales, seeded from
+// any existing i18next resources, and the i18n.go file (in package pkg)
+// that loads them
+func writeI18n(dir, pkg string, messages []generator.Message, locales map[string]project.Catalog) error {
+	if err := project.WriteCatalogs(dir, project.Catalogs(messages, locales)); err != nil {
+		return err
+	}
+	return project.WriteFile(filepath.Join(dir, generator.I18nFile), []byte(generator.I18nSource(pkg)))
+}
+
+// packageImportPath returns the import path of the package in dir, using
+// the nearest go.mod above it. Without one, the directory's own path
+// relative to its parent is used and has to be fixed up by hand.
+func packageImportPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	for root := filepath.Dir(abs); ; root = filepath.Dir(root) {
+		if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					rel, _ := filepath.Rel(root, abs)
+					return path.Join(strings.Trim(strings.TrimSpace(module), `"`), filepath.ToSlash(rel))
+				}
+			}
+		}
+		if filepath.Dir(root) == root {
+			break
+		}
+	}
+	return filepath.Base(filepath.Dir(abs)) + "/" + filepath.Base(abs)
+}
+
+// manifestEntry summarises a generated conversion for the manifest
+func (c *conversion) manifestEntry(source, output string, content []byte, opts *options) *project.FileEntry {
+	entry := &project.FileEntry{
+		Source:   source,
+		Output:   output,
+		Hash:     project.Hash(content),
+		Tool:     version,
+		Options:  opts.fingerprint(),
+		Default:  c.result.File.DefaultExport,
+		Routes:   project.RouterConfig(c.source),
+		Streams:  c.streams,
+		Handlers: c.handlers,
+		Loaders:  c.loaders,
+		Messages: c.messages,
+		Calls:    c.calls,
+		Imports:  project.ImportedSources(c.imports),
+		Types:    c.types,
+		Decls:    c.decls,
+		Unmapped: c.unmapped,
+		Patterns: map[string]int{},
+		Warnings: len(c.result.Warnings),
+	}
+	for _, stat := range c.stats {
+		entry.Components = append(entry.Components, project.ComponentRef{
+			Name:   stat.Name,
+			TODOs:  stat.TODOs,
+			Params: stat.Params,
+		})
+	}
+	for _, p := range c.patterns {
+		entry.Patterns[string(p.Type)]++
+	}
+	for _, f := range c.findings {
+		if entry.Findings == nil {
+			entry.Findings = map[string]int{}
+		}
+		entry.Findings[f.Rule]++
+	}
+	return entry
+}
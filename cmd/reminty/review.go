@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// Review decisions recorded per component in the manifest
+const (
+	reviewAccepted = "accepted"
+	reviewSkipped  = "skipped"
+	reviewManual   = "manual"
+)
+
+// runReview implements `reminty review`: step through every component of
+// a file or directory, show its JSX next to the generated Go, and let the
+// user accept, skip or mark it for manual migration. Accepted components
+// are written to the output directory; every decision is recorded in the
+// manifest so an interrupted review resumes where it stopped.
+func runReview(args []string) {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	outDir := fs.String("o", "", "Output directory")
+	mappings := fs.String("mappings", "", "Design-system mapping packs (comma-separated)")
+	tailwind := fs.Bool("tailwind", false, "Translate static inline styles into Tailwind classes")
+	configFile := fs.String("config", "", "Project config file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: reminty review -o <outdir> [options] <srcdir|file.jsx>
+
+For each component, shows the JSX and the proposed Go side by side and asks:
+  a  accept   write the component to the output file
+  s  skip     leave it out for now (asked again next time)
+  m  manual   leave it out and record that it needs a manual migration
+  q  quit     stop; decisions so far are kept
+
+Options:
+  -o <dir>              Output directory (default: output: in .reminty.yaml)
+  -config <file>        Project config file (default: .reminty.yaml found
+                        from the working directory up to the repository root)
+  -mappings <packs>     Design-system mapping packs (comma-separated)
+  -tailwind             Translate static inline styles into Tailwind classes
+`)
+	}
+	fs.Parse(args)
+
+	opts := &options{tailwind: *tailwind}
+	if *mappings != "" {
+		opts.mappings = strings.Split(*mappings, ",")
+	}
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		fatalf("Error reading config: %v\n", err)
+	}
+	if err := opts.applyConfig(cfg, setFlags(fs)); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if *outDir == "" && cfg != nil {
+		*outDir = cfg.Output
+	}
+	if fs.NArg() != 1 || *outDir == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if _, err := opts.newGenerator(); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+
+	srcDir, sources := fs.Arg(0), []string(nil)
+	if info, err := os.Stat(srcDir); err != nil {
+		fatalf("Error: %v\n", err)
+	} else if info.IsDir() {
+		if sources, err = opts.sources(srcDir); err != nil {
+			fatalf("Error: %v\n", err)
+		}
+	} else {
+		srcDir, sources = filepath.Dir(srcDir), []string{filepath.Base(srcDir)}
+	}
+
+	r := &reviewer{
+		in:    bufio.NewReader(os.Stdin),
+		out:   os.Stdout,
+		width: terminalWidth(),
+		clear: isTerminal(os.Stdout),
+	}
+	if err := r.review(context.Background(), srcDir, sources, *outDir, opts); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+}
+
+// reviewer holds the state of an interactive review session
+type reviewer struct {
+	in    *bufio.Reader
+	out   io.Writer
+	width int
+	clear bool // clear the screen between components
+
+	accepted, skipped, manual int
+}
+
+func (r *reviewer) review(ctx context.Context, srcDir string, sources []string, outDir string, opts *options) error {
+	manifest, err := project.LoadManifest(outDir)
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+
+	total := 0
+	quit := false
+	for _, source := range sources {
+		if quit {
+			break
+		}
+		key := filepath.ToSlash(source)
+		data, err := os.ReadFile(filepath.Join(srcDir, source))
+		if err != nil {
+			return err
+		}
+
+		// Decisions stand as long as the source is unchanged
+		previous := map[string]string{}
+		if prev, ok := manifest.Files[key]; ok && manifest.Unchanged(key, data, version, opts.fingerprint()) {
+			for _, comp := range prev.Components {
+				if comp.Review != "" && comp.Review != reviewSkipped {
+					previous[comp.Name] = comp.Review
+				}
+			}
+		}
+
+		conv, err := analyze(ctx, string(data), opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		conv.file = key
+		if err := conv.loadCSSModules(filepath.Dir(filepath.Join(srcDir, source))); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		conv.loadAssets(filepath.Dir(filepath.Join(srcDir, source)), srcDir)
+		if err := conv.generate(ctx, opts); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if len(conv.stats) == 0 {
+			continue
+		}
+
+		lines := strings.Split(conv.source, "\n")
+		comps := conv.result.File.Components
+		decisions := map[string]string{}
+		for i, stat := range conv.stats {
+			total++
+			if d, ok := previous[stat.Name]; ok {
+				decisions[stat.Name] = d
+				continue
+			}
+			// The stats follow rendering order, the components source
+			// order; a component's source runs up to the next one's
+			start, end := len(lines)+1, len(lines)+1
+			for j, comp := range comps {
+				if comp.Name == stat.Name {
+					start = comp.LineNumber
+					if j+1 < len(comps) {
+						end = comps[j+1].LineNumber
+					}
+					break
+				}
+			}
+			jsx := lines[min(start-1, len(lines)):min(end-1, len(lines))]
+
+			r.show(key, i+1, len(conv.stats), stat, jsx, conv)
+			decision, ok := r.ask()
+			if !ok {
+				quit = true
+				break
+			}
+			decisions[stat.Name] = decision
+		}
+
+		if err := r.write(outDir, key, data, conv, decisions, manifest, opts); err != nil {
+			return err
+		}
+	}
+
+	if err := manifest.Save(outDir); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	fmt.Fprintf(r.out, "\nReviewed %d of %d component(s): %d accepted, %d skipped, %d marked manual\n",
+		r.accepted+r.skipped+r.manual, total, r.accepted, r.skipped, r.manual)
+	return nil
+}
+
+// show prints one component: a title bar, the JSX and Go side by side,
+// and the TODOs and audit findings left in it
+func (r *reviewer) show(file string, n, of int, stat generator.ComponentStat, jsx []string, conv *conversion) {
+	if r.clear {
+		fmt.Fprint(r.out, "\033[H\033[2J")
+	}
+	title := fmt.Sprintf("── %s · %s (%d/%d) ", file, stat.Name, n, of)
+	fmt.Fprintln(r.out, title+strings.Repeat("─", max(r.width-utf8.RuneCountInString(title), 0)))
+	sideBySide(r.out, append([]string{"JSX", ""}, jsx...), append([]string{"Go", ""}, strings.Split(stat.Code, "\n")...), r.width)
+	fmt.Fprintln(r.out, strings.Repeat("─", r.width))
+
+	fmt.Fprintf(r.out, "TODOs: %d", stat.TODOs)
+	for _, f := range conv.findings {
+		if f.Component == stat.Name {
+			fmt.Fprintf(r.out, " · line %d %s", f.Line, f.Rule)
+		}
+	}
+	fmt.Fprintln(r.out)
+}
+
+// ask reads a decision; it returns false on quit or end of input
+func (r *reviewer) ask() (string, bool) {
+	for {
+		fmt.Fprint(r.out, "[a]ccept  [s]kip  [m]anual  [q]uit > ")
+		line, err := r.in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "a", "accept":
+			r.accepted++
+			return reviewAccepted, true
+		case "s", "skip":
+			r.skipped++
+			return reviewSkipped, true
+		case "m", "manual":
+			r.manual++
+			return reviewManual, true
+		case "q", "quit":
+			return "", false
+		}
+		if err != nil {
+			fmt.Fprintln(r.out)
+			return "", false
+		}
+	}
+}
+
+// write saves the accepted components of a file and records every
+// decision in the manifest. Components not yet decided are left out.
+func (r *reviewer) write(outDir, key string, data []byte, conv *conversion, decisions map[string]string, manifest *project.Manifest, opts *options) error {
+	entry := conv.manifestEntry(key, filepath.ToSlash(opts.goOutput(outputName(key))), data, opts)
+	for i := range entry.Components {
+		entry.Components[i].Review = decisions[entry.Components[i].Name]
+	}
+	manifest.Record(entry)
+
+	accepted := map[string]bool{}
+	var stats []generator.ComponentStat
+	for _, stat := range conv.stats {
+		if decisions[stat.Name] == reviewAccepted {
+			accepted[stat.Name] = true
+			stats = append(stats, stat)
+		}
+	}
+	code, ok := acceptedOutput(conv.output, conv.stats, accepted)
+	if !ok {
+		return nil
+	}
+	return opts.writeGo(filepath.Join(outDir, outputName(key)), code, stats)
+}
+
+// acceptedOutput rebuilds a generated file with only the accepted
+// components, keeping the header and the trailing notes. It returns false
+// if no component was accepted.
+func acceptedOutput(output string, stats []generator.ComponentStat, accepted map[string]bool) (string, bool) {
+	first := strings.Index(output, stats[0].Code)
+	lastStat := stats[len(stats)-1]
+	last := strings.LastIndex(output, lastStat.Code)
+	if first < 0 || last < 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString(output[:first])
+	any := false
+	for _, stat := range stats {
+		if accepted[stat.Name] {
+			b.WriteString(stat.Code)
+			b.WriteString("\n")
+			any = true
+		}
+	}
+	b.WriteString(output[last+len(lastStat.Code):])
+	return b.String(), any
+}
+
+// sideBySide prints two columns of text separated by a rule
+func sideBySide(w io.Writer, left, right []string, width int) {
+	col := max((width-3)/2, 20)
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		fmt.Fprintf(w, "%s │ %s\n", fitColumn(l, col), strings.TrimRight(fitColumn(r, col), " "))
+	}
+}
+
+// fitColumn expands tabs and pads or truncates s to exactly width runes
+func fitColumn(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// terminalWidth returns the width to render for, from $COLUMNS
+func terminalWidth() int {
+	var cols int
+	if _, err := fmt.Sscanf(os.Getenv("COLUMNS"), "%d", &cols); err == nil && cols >= 60 {
+		return cols
+	}
+	return 160
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
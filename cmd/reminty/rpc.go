@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/pipeline"
+)
+
+// rpcRequest is a single line of input in rpc mode
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params rpcParams       `json:"params"`
+}
+
+// rpcParams carries the arguments for every rpc method. Editors send the
+// buffer (or selection) text along with an optional position.
+type rpcParams struct {
+	Text string `json:"text"`
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// rpcResponse is written as a single line of output per request
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// runRPC serves newline-delimited JSON requests from stdin until EOF.
+// Each request gets exactly one response line on stdout, so an editor
+// extension can keep a single process alive for the whole session.
+func runRPC(args []string) {
+	fs := flag.NewFlagSet("rpc", flag.ExitOnError)
+	mappings := fs.String("mappings", "", "Design-system mapping packs (comma-separated)")
+	tailwind := fs.Bool("tailwind", false, "Translate static inline styles into Tailwind classes")
+	pkg := fs.String("package", "", "Package clause of the generated code (default: main)")
+	mintyImport := fs.String("minty-import", "", "Import path of minty, optionally name=path")
+	configFile := fs.String("config", "", "Project config file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: reminty rpc [options]
+
+Serves newline-delimited JSON requests from stdin, one response per line.
+
+Options:
+  -config <file>        Project config file (default: .reminty.yaml found
+                        from the working directory up to the repository root)
+  -mappings <packs>     Design-system mapping packs (comma-separated)
+  -tailwind             Translate static inline styles into Tailwind classes
+  -package <name>       Package clause of the generated code (default: main)
+  -minty-import <path>  Import path of minty, optionally name=path
+`)
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts := &options{tailwind: *tailwind, pkg: *pkg, mintyImport: *mintyImport}
+	if *mappings != "" {
+		opts.mappings = strings.Split(*mappings, ",")
+	}
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		fatalf("Error reading config: %v\n", err)
+	}
+	if err := opts.applyConfig(cfg, setFlags(fs)); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if _, err := opts.newGenerator(); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	serveRPC(os.Stdin, os.Stdout, opts)
+}
+
+func serveRPC(r io.Reader, w io.Writer, opts *options) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			enc.Encode(rpcResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+
+		result, err := handleRPC(context.Background(), req, opts)
+		resp := rpcResponse{ID: req.ID, Result: result}
+		if err != nil {
+			resp.Result = nil
+			resp.Error = err.Error()
+		}
+		enc.Encode(resp)
+	}
+	// A request over the buffer limit stops the scanner; say why rather
+	// than exiting as if stdin had closed
+	if err := scanner.Err(); err != nil {
+		enc.Encode(rpcResponse{Error: fmt.Sprintf("reading request: %v", err)})
+	}
+}
+
+func handleRPC(ctx context.Context, req rpcRequest, opts *options) (interface{}, error) {
+	switch req.Method {
+	case "convert-selection":
+		code, err := convertSelection(ctx, req.Params.Text, opts)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"code": code}, nil
+	case "analyze-buffer":
+		return analyzeBuffer(ctx, req.Params.Text, opts)
+	case "get-suggestion-at-position":
+		analysis, err := analyzeBuffer(ctx, req.Params.Text, opts)
+		if err != nil {
+			return nil, err
+		}
+		return analysis.At(req.Params.Line), nil
+	default:
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+}
+
+// convertSelection converts either a whole component or a bare JSX
+// fragment such as a pasted <div>...</div>, with the configured package,
+// minty import and mappings.
+func convertSelection(ctx context.Context, text string, opts *options) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("empty selection")
+	}
+
+	tokens, err := parser.NewLexer(text).TokenizeContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	gen, err := opts.newGenerator()
+	if err != nil {
+		return "", err
+	}
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "<") {
+		node := parser.NewParser(tokens).ParseJSX()
+		if node == nil {
+			return "", fmt.Errorf("selection is not a JSX element")
+		}
+		return opts.rewriteImports(gen.GenerateNode(node)), nil
+	}
+
+	result, err := parser.NewParserWithSource(tokens, text).ParseContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(result.File.Components) == 0 {
+		return "", fmt.Errorf("no component or JSX element found in selection")
+	}
+	output, err := gen.GenerateContext(ctx, result)
+	if err != nil {
+		return "", err
+	}
+	return opts.rewriteImports(output), nil
+}
+
+func analyzeBuffer(ctx context.Context, text string, opts *options) (*pipeline.Analysis, error) {
+	conv, err := analyze(ctx, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	return analysisOf(conv), nil
+}
+
+// analysisOf collects the analysis results of a conversion
+func analysisOf(conv *conversion) *pipeline.Analysis {
+	return pipeline.NewAnalysis(conv.result, conv.patterns, conv.findings)
+}
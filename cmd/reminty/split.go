@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// goOutput returns the file the conversion into path is recorded as:
+// path itself or, with -split, the shared types file next to it
+// (task_board.go → task_board_types.go). Test files are not split.
+func (o *options) goOutput(path string) string {
+	if !o.split || strings.HasSuffix(path, "_test.go") {
+		return path
+	}
+	return strings.TrimSuffix(path, ".go") + "_types.go"
+}
+
+// writeGo writes the Go output of a conversion into path or, with -split,
+// a file per component next to it (TaskList → task_list.go) plus the
+// shared types file. Each file is gofmt-formatted on the way.
+func (o *options) writeGo(path, output string, stats []generator.ComponentStat) error {
+	if !o.split {
+		return project.WriteFile(path, []byte(o.formatGo(path, output)))
+	}
+	for _, f := range generator.SplitOutput(output, stats) {
+		name := o.goOutput(path)
+		if f.Component != "" {
+			name = filepath.Join(filepath.Dir(path), toSnakeCase(f.Component)+".go")
+		}
+		if err := project.WriteFile(name, []byte(o.formatGo(name, f.Source))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/assets"
+	"github.com/ha1tch/reminty/internal/project"
+)
+
+// AssetManifestFile lists the copied static assets in project mode
+const AssetManifestFile = "assets.json"
+
+// staticAsset is an imported file copied into the static tree
+type staticAsset struct {
+	path   string // file on disk
+	rel    string // path relative to the source root
+	public string // URL it is served under
+}
+
+var requireAsset = regexp.MustCompile(`require\(\s*['"](\.[^'"]+)['"]\s*\)`)
+
+// loadAssets resolves the static assets imported (or required) by the
+// converted source. dir is the source file's directory, root the source
+// tree the public paths are computed against.
+func (c *conversion) loadAssets(dir, root string) {
+	c.assetPaths = map[string]string{}
+
+	add := func(key, source string) {
+		if !strings.HasPrefix(source, ".") || !assets.IsStaticAsset(source) {
+			return
+		}
+		path := filepath.Join(dir, filepath.FromSlash(source))
+		if _, err := os.Stat(path); err != nil {
+			c.missingAssets = append(c.missingAssets, source)
+			return
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = filepath.Base(path)
+		}
+		asset := staticAsset{path: path, rel: filepath.ToSlash(rel), public: assets.PublicPath(rel)}
+		c.assetPaths[key] = asset.public
+		c.assets = append(c.assets, asset)
+	}
+
+	for _, imp := range c.result.File.Imports {
+		if imp.Default != "" {
+			add(imp.Default, strings.Trim(imp.Source, "'\"`"))
+		}
+	}
+	for _, m := range requireAsset.FindAllStringSubmatch(c.source, -1) {
+		add(m[1], m[1])
+	}
+}
+
+// copyAssets copies the conversion's assets below staticDir
+func copyAssets(staticDir string, list []staticAsset) error {
+	for _, asset := range list {
+		dst := filepath.Join(staticDir, filepath.FromSlash(strings.TrimPrefix(asset.public, "/"+assets.StaticDir+"/")))
+		if pending != nil {
+			data, err := os.ReadFile(asset.path)
+			if err != nil {
+				return fmt.Errorf("copying %s: %w", asset.rel, err)
+			}
+			pending.write(dst, data)
+			continue
+		}
+		if err := assets.CopyFile(asset.path, dst); err != nil {
+			return fmt.Errorf("copying %s: %w", asset.rel, err)
+		}
+	}
+	return nil
+}
+
+// printAssetIssues lists asset paths that could not be rewritten
+func printAssetIssues(w io.Writer, file string, c *conversion) {
+	for _, source := range c.missingAssets {
+		fmt.Fprintf(w, "%s: imported asset %s not found\n", file, source)
+	}
+	for _, issue := range c.assetIssues {
+		fmt.Fprintf(w, "%s:%d: dynamic %s path not rewritten: %s\n", file, issue.Line, issue.Attr, issue.Expr)
+	}
+}
+
+// assetManifest records every copied asset with the files referencing it,
+// and the dynamic paths that still point at the original locations
+type assetManifest struct {
+	Assets     []*assetRecord `json:"assets"`
+	Unresolved []assetAccess  `json:"unresolved,omitempty"`
+}
+
+type assetRecord struct {
+	Source string   `json:"source"`
+	Public string   `json:"public"`
+	Files  []string `json:"files"`
+}
+
+type assetAccess struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	Attr string `json:"attr,omitempty"`
+	Expr string `json:"expr"`
+}
+
+// loadAssetManifest reads the asset manifest from a previous run, so files
+// skipped as unchanged keep their entries
+func loadAssetManifest(dir string) (*assetManifest, error) {
+	m := &assetManifest{}
+	data, err := os.ReadFile(filepath.Join(dir, AssetManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", AssetManifestFile, err)
+	}
+	return m, nil
+}
+
+// update replaces everything recorded for file with the conversion's assets
+func (m *assetManifest) update(file string, c *conversion) {
+	for _, record := range m.Assets {
+		record.Files = removeString(record.Files, file)
+	}
+	unresolved := m.Unresolved[:0]
+	for _, access := range m.Unresolved {
+		if access.File != file {
+			unresolved = append(unresolved, access)
+		}
+	}
+	m.Unresolved = unresolved
+
+	for _, asset := range c.assets {
+		var record *assetRecord
+		for _, r := range m.Assets {
+			if r.Source == asset.rel {
+				record = r
+				break
+			}
+		}
+		if record == nil {
+			record = &assetRecord{Source: asset.rel, Public: asset.public}
+			m.Assets = append(m.Assets, record)
+		}
+		if !containsString(record.Files, file) {
+			record.Files = append(record.Files, file)
+		}
+	}
+	for _, source := range c.missingAssets {
+		m.Unresolved = append(m.Unresolved, assetAccess{File: file, Expr: source})
+	}
+	for _, issue := range c.assetIssues {
+		m.Unresolved = append(m.Unresolved, assetAccess{
+			File: file,
+			Line: issue.Line,
+			Attr: issue.Attr,
+			Expr: issue.Expr,
+		})
+	}
+}
+
+// save writes the manifest, dropping assets no file references any more
+func (m *assetManifest) save(dir string) error {
+	records := m.Assets[:0]
+	for _, record := range m.Assets {
+		if len(record.Files) > 0 {
+			sort.Strings(record.Files)
+			records = append(records, record)
+		}
+	}
+	m.Assets = records
+	if len(m.Assets) == 0 && len(m.Unresolved) == 0 {
+		return nil
+	}
+	sort.Slice(m.Assets, func(i, j int) bool { return m.Assets[i].Source < m.Assets[j].Source })
+	sort.SliceStable(m.Unresolved, func(i, j int) bool {
+		if m.Unresolved[i].File != m.Unresolved[j].File {
+			return m.Unresolved[i].File < m.Unresolved[j].File
+		}
+		return m.Unresolved[i].Line < m.Unresolved[j].Line
+	})
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return project.WriteFile(filepath.Join(dir, AssetManifestFile), append(data, '\n'))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// writeEmbedScaffold generates assets.go in dir, embedding staticDir and
+// registering a file server for it. Nothing is written when there are no
+// static files, when staticDir is outside dir (go:embed cannot reach it),
+// or when an assets.go not generated by reminty is already present. The
+// file is generated in package pkg.
+func writeEmbedScaffold(dir, staticDir, pkg string) error {
+	entries, _ := os.ReadDir(staticDir)
+	if len(entries) == 0 && (pending == nil || !pending.hasFilesIn(staticDir)) {
+		return nil
+	}
+	rel, err := filepath.Rel(dir, staticDir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		fmt.Fprintf(os.Stderr, "Note: %s is outside %s; %s not generated\n", staticDir, dir, assets.EmbedFile)
+		return nil
+	}
+
+	path := filepath.Join(dir, assets.EmbedFile)
+	if existing, err := os.ReadFile(path); err == nil && !assets.IsGeneratedEmbed(existing) {
+		fmt.Fprintf(os.Stderr, "Note: %s exists and was not generated by reminty; left unchanged\n", path)
+		return nil
+	}
+	return project.WriteFile(path, []byte(assets.EmbedSource(pkg, rel)))
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/diagnostics"
+)
+
+// summaryTop is how many entries each summary ranking shows
+const summaryTop = 10
+
+// projectSummary aggregates the analysis of every file in a directory
+type projectSummary struct {
+	files      int
+	components int
+	hooks      int
+	lines      int
+
+	patterns   map[string]int // pattern type → occurrences
+	hookKinds  map[string]int // hook → occurrences needing manual migration
+	constructs map[string]int // unsupported construct → TODO markers
+	findings   map[string]int // audit rule → findings
+	hotspots   []hookHotspot
+	defined    map[string]string         // component → file defining it
+	references map[string]map[string]int // component → referencing file → calls
+}
+
+// hookHotspot is a file's hook usage relative to its size
+type hookHotspot struct {
+	file  string
+	hooks int
+	lines int
+}
+
+func (h hookHotspot) density() float64 {
+	return float64(h.hooks) * 100 / float64(max(h.lines, 1))
+}
+
+func newProjectSummary() *projectSummary {
+	return &projectSummary{
+		patterns:   map[string]int{},
+		hookKinds:  map[string]int{},
+		constructs: map[string]int{},
+		findings:   map[string]int{},
+		defined:    map[string]string{},
+		references: map[string]map[string]int{},
+	}
+}
+
+// analyzeProject analyzes every JSX source under srcDir and prints a
+// consolidated summary instead of per-file reports. With the github
+// format each file's findings are printed as annotations as well; with
+// sarif or json, the results of all files are written as one document.
+func analyzeProject(ctx context.Context, srcDir string, opts *options, format string) error {
+	sources, err := opts.sources(srcDir)
+	if err != nil {
+		return err
+	}
+
+	summary := newProjectSummary()
+	var diags []diagnostics.Diagnostic
+	var files []fileAnalysis
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filepath.Join(srcDir, source))
+		if err != nil {
+			return err
+		}
+		conv, err := analyze(ctx, string(data), opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		conv.file = filepath.ToSlash(source)
+		// Generate too: TODO markers show what is left unsupported
+		if err := conv.generate(ctx, opts); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if format == "github" {
+			printGitHubAnnotations(os.Stdout, filepath.ToSlash(filepath.Join(srcDir, source)), conv.patterns, conv.result, conv.findings)
+		}
+		if format == "json" {
+			files = append(files, fileAnalysisOf(filepath.ToSlash(filepath.Join(srcDir, source)), conv))
+		}
+		if format == "sarif" {
+			diags = append(diags, diagnostics.Collect(filepath.ToSlash(filepath.Join(srcDir, source)), conv.result, conv.patterns, conv.findings)...)
+		}
+		summary.add(conv)
+	}
+
+	summary.print(os.Stderr)
+	switch format {
+	case "sarif":
+		return writeSARIF(os.Stdout, diags)
+	case "json":
+		return writeAnalysisJSON(os.Stdout, files)
+	}
+	return nil
+}
+
+var todoText = regexp.MustCompile(`TODO: ([^\n]*?)(?: \*/|\n|$)`)
+
+// add records one analyzed file
+func (s *projectSummary) add(c *conversion) {
+	s.files++
+	lines := strings.Count(c.source, "\n") + 1
+	s.lines += lines
+
+	hooks := 0
+	for _, comp := range c.result.File.Components {
+		s.components++
+		hooks += len(comp.Hooks)
+		if _, ok := s.defined[comp.Name]; !ok {
+			s.defined[comp.Name] = c.file
+		}
+	}
+	s.hooks += hooks
+	if hooks > 0 {
+		s.hotspots = append(s.hotspots, hookHotspot{file: c.file, hooks: hooks, lines: lines})
+	}
+
+	for _, p := range c.patterns {
+		s.patterns[string(p.Type)]++
+	}
+	for _, sug := range c.result.Suggestions {
+		s.hookKinds[sug.ReactCode]++
+	}
+	for _, f := range c.findings {
+		s.findings[f.Rule]++
+	}
+	for _, call := range c.calls {
+		if s.references[call.Component] == nil {
+			s.references[call.Component] = map[string]int{}
+		}
+		s.references[call.Component][c.file]++
+	}
+	for _, m := range todoText.FindAllStringSubmatch(c.output, -1) {
+		s.constructs[todoConstruct(m[1])]++
+	}
+	for _, w := range c.result.Warnings {
+		s.constructs["parse warning: "+w.Message]++
+	}
+}
+
+// print writes the summary as text
+func (s *projectSummary) print(w io.Writer) {
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "=== PROJECT ANALYSIS ===")
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "%d file(s), %d component(s), %d hook call(s), %d line(s)\n", s.files, s.components, s.hooks, s.lines)
+
+	printRanking(w, "Most frequent patterns:", s.patterns)
+
+	if len(s.hotspots) > 0 {
+		sort.SliceStable(s.hotspots, func(i, j int) bool {
+			return s.hotspots[i].density() > s.hotspots[j].density()
+		})
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Hook density hotspots (hooks per 100 lines):")
+		for i, h := range s.hotspots {
+			if i == summaryTop {
+				break
+			}
+			fmt.Fprintf(w, "  %6.1f  %s (%d hooks, %d lines)\n", h.density(), h.file, h.hooks, h.lines)
+		}
+	}
+
+	// Components used from many places are worth migrating first
+	type referenced struct {
+		name        string
+		calls, from int
+	}
+	var refs []referenced
+	for name, files := range s.references {
+		if _, ok := s.defined[name]; !ok {
+			continue
+		}
+		r := referenced{name: name, from: len(files)}
+		for _, n := range files {
+			r.calls += n
+		}
+		refs = append(refs, r)
+	}
+	if len(refs) > 0 {
+		sort.Slice(refs, func(i, j int) bool {
+			if refs[i].calls != refs[j].calls {
+				return refs[i].calls > refs[j].calls
+			}
+			return refs[i].name < refs[j].name
+		})
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Most referenced components (migrate first):")
+		for i, r := range refs {
+			if i == summaryTop {
+				break
+			}
+			fmt.Fprintf(w, "  %4d  %s (%s; used from %d file(s))\n", r.calls, r.name, s.defined[r.name], r.from)
+		}
+	}
+
+	printRanking(w, "Unsupported constructs (TODO markers):", s.constructs)
+	printRanking(w, "Hooks needing manual migration:", s.hookKinds)
+	printRanking(w, "Audit findings:", s.findings)
+	fmt.Fprintln(w, "")
+}
+
+// printRanking prints the most frequent entries of counts
+func printRanking(w io.Writer, title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, title)
+	for i, k := range keys {
+		if i == summaryTop {
+			fmt.Fprintf(w, "  ... and %d more\n", len(keys)-summaryTop)
+			break
+		}
+		fmt.Fprintf(w, "  %4d  %s\n", counts[k], k)
+	}
+}
+
+// todoConstruct names the kind of construct a TODO marker was left for,
+// so markers for different expressions of the same kind are counted
+// together
+func todoConstruct(todo string) string {
+	todo = strings.TrimSpace(todo)
+	switch {
+	case strings.HasPrefix(todo, "implement "):
+		return "derived value: " + strings.Fields(todo)[1]
+	case strings.HasPrefix(todo, "{..."):
+		return "spread props"
+	case strings.Contains(todo, "<Trans>"):
+		return "i18n markup"
+	case strings.HasSuffix(todo, "not translated"):
+		return "validation rule"
+	case todo == "true" || todo == "false" || todo == "null" || todo == "undefined":
+		return "literal"
+	case strings.Contains(todo, "not in stylesheet"):
+		return "unknown CSS module class"
+	case strings.HasPrefix(todo, "complex handler"):
+		return "complex event handler"
+	case strings.Contains(todo, "mi.HtmxTrigger"), strings.HasSuffix(todo, "handler"):
+		return "event handler"
+	case strings.Contains(todo, "not parsed"), strings.Contains(todo, "unhandled"):
+		return "unparsed JSX"
+	case strings.Contains(todo, "=>"):
+		return "inline function"
+	case regexp.MustCompile(`\.(map|filter|find|some|every|reduce|sort|slice)\(`).MatchString(todo):
+		return "array method"
+	case strings.Contains(todo, "`") || strings.Contains(todo, "${"):
+		return "template literal"
+	case strings.Contains(todo, "?") && strings.Contains(todo, ":"):
+		return "ternary expression"
+	case strings.Contains(todo, "&&") || strings.Contains(todo, "||"):
+		return "logical expression"
+	case strings.Contains(todo, "("):
+		return "function call"
+	case strings.Contains(todo, "+"):
+		return "string concatenation"
+	case regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)+$`).MatchString(todo):
+		return "property access"
+	case regexp.MustCompile(`^[A-Za-z_$][\w$]*$`).MatchString(todo):
+		return "unresolved identifier"
+	}
+	// Reminders to finish generated scaffolding rather than constructs
+	return "manual follow-up"
+}
@@ -0,0 +1,146 @@
+// Package assets handles the non-JSX files a component depends on:
+// stylesheets, images and other static files that have to be carried
+// over into the converted Go project.
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CSSModule is a parsed CSS Modules stylesheet (e.g. Card.module.css).
+// Local class names are given deterministic global names so generated
+// Go code can reference them as plain strings.
+type CSSModule struct {
+	Path    string            // path of the source stylesheet
+	Name    string            // module name used as class prefix (e.g. "Card")
+	Classes map[string]string // local class name → global class name
+	source  string
+}
+
+// IsCSSModule reports whether an import path refers to a CSS Modules file
+func IsCSSModule(importPath string) bool {
+	return strings.HasSuffix(importPath, ".module.css") || strings.HasSuffix(importPath, ".module.scss")
+}
+
+// LoadCSSModule reads and parses a CSS Modules stylesheet
+func LoadCSSModule(path string) (*CSSModule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := ParseCSSModule(moduleName(path), string(data))
+	m.Path = path
+	return m, nil
+}
+
+// ParseCSSModule extracts the class names defined in a stylesheet
+func ParseCSSModule(name, css string) *CSSModule {
+	m := &CSSModule{
+		Name:    name,
+		Classes: map[string]string{},
+		source:  css,
+	}
+	forEachSelector(css, func(selector string) string {
+		for _, match := range classSelector.FindAllStringSubmatch(selector, -1) {
+			m.Classes[match[1]] = m.globalName(match[1])
+		}
+		return selector
+	})
+	return m
+}
+
+// Resolve returns the global class name for a local class
+func (m *CSSModule) Resolve(class string) (string, bool) {
+	global, ok := m.Classes[class]
+	return global, ok
+}
+
+// ClassNames returns the local class names in sorted order
+func (m *CSSModule) ClassNames() []string {
+	names := make([]string, 0, len(m.Classes))
+	for name := range m.Classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rewrite returns the stylesheet with every local class renamed to its
+// global name, ready to be served as a regular stylesheet.
+func (m *CSSModule) Rewrite() string {
+	return forEachSelector(m.source, func(selector string) string {
+		return classSelector.ReplaceAllStringFunc(selector, func(match string) string {
+			local := strings.TrimPrefix(match, ".")
+			if global, ok := m.Classes[local]; ok {
+				return "." + global
+			}
+			return match
+		})
+	})
+}
+
+// OutputName is the file name the rewritten stylesheet is published as
+func (m *CSSModule) OutputName() string {
+	return m.Name + ".module.css"
+}
+
+func (m *CSSModule) globalName(local string) string {
+	return m.Name + "_" + local
+}
+
+// moduleName derives the class prefix from a stylesheet path:
+// components/Card.module.css → "Card"
+func moduleName(path string) string {
+	base := filepath.Base(path)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	base = strings.TrimSuffix(base, ".module")
+	return nonClassChars.ReplaceAllString(base, "_")
+}
+
+var (
+	classSelector = regexp.MustCompile(`\.(-?[_a-zA-Z][_a-zA-Z0-9-]*)`)
+	nonClassChars = regexp.MustCompile(`[^_a-zA-Z0-9-]`)
+	cssComment    = regexp.MustCompile(`(?s)/\*.*?\*/`)
+)
+
+// forEachSelector calls fn for the selector preceding every rule block
+// and returns the stylesheet with the selectors replaced by fn's result.
+// At-rule preludes (@media ...) and declaration blocks are left alone.
+func forEachSelector(css string, fn func(string) string) string {
+	css = cssComment.ReplaceAllString(css, "")
+
+	var out strings.Builder
+	start := 0 // start of the pending selector text
+	inDecls := false
+	for i := 0; i < len(css); i++ {
+		switch css[i] {
+		case '{':
+			prelude := css[start:i]
+			if !inDecls && !strings.HasPrefix(strings.TrimSpace(prelude), "@") {
+				out.WriteString(fn(prelude))
+				// A rule block: everything up to the matching } is declarations
+				inDecls = true
+			} else {
+				out.WriteString(prelude)
+			}
+			out.WriteByte('{')
+			start = i + 1
+		case '}':
+			out.WriteString(css[start : i+1])
+			start = i + 1
+			inDecls = false
+		case ';':
+			if !inDecls {
+				// Statement at-rule such as @import "x.css";
+				out.WriteString(css[start : i+1])
+				start = i + 1
+			}
+		}
+	}
+	out.WriteString(css[start:])
+	return out.String()
+}
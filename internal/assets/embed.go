@@ -0,0 +1,41 @@
+package assets
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// EmbedFile is the Go file generated next to the static tree
+const EmbedFile = "assets.go"
+
+// embedHeader marks EmbedFile as generated, so reruns may replace it
+const embedHeader = "// Generated by reminty - serves the copied static files"
+
+// EmbedSource returns a Go file that embeds the static tree at staticRel
+// (relative to the file's directory) and registers a file server for it
+// under the same URL prefix used by PublicPath.
+func EmbedSource(pkg, staticRel string) string {
+	staticRel = filepath.ToSlash(staticRel)
+	prefix := "/" + StaticDir + "/"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString(embedHeader + "\n\n")
+	b.WriteString("import (\n\t\"embed\"\n\t\"io/fs\"\n\t\"net/http\"\n)\n\n")
+	fmt.Fprintf(&b, "//go:embed %s\n", staticRel)
+	b.WriteString("var staticFiles embed.FS\n\n")
+	fmt.Fprintf(&b, "// RegisterStatic serves the embedded static files under %s on mux\n", prefix)
+	b.WriteString("func RegisterStatic(mux *http.ServeMux) {\n")
+	fmt.Fprintf(&b, "\tsub, err := fs.Sub(staticFiles, %q)\n", staticRel)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	fmt.Fprintf(&b, "\tmux.Handle(%q, http.StripPrefix(%q, http.FileServer(http.FS(sub))))\n", prefix, prefix)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// IsGeneratedEmbed reports whether an existing file was written by
+// EmbedSource and may be overwritten
+func IsGeneratedEmbed(content []byte) bool {
+	return strings.Contains(string(content), embedHeader)
+}
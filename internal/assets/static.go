@@ -0,0 +1,60 @@
+package assets
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// StaticDir is the directory, relative to the output root, that copied
+// assets are published from, and the URL prefix they are served under
+const StaticDir = "static"
+
+// staticExtensions lists the file types copied as static assets
+var staticExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+	".avif": true, ".svg": true, ".ico": true, ".bmp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp4": true, ".webm": true, ".mp3": true, ".wav": true, ".ogg": true,
+}
+
+// IsStaticAsset reports whether an import path refers to a file that is
+// copied verbatim (images, fonts, media) rather than converted
+func IsStaticAsset(importPath string) bool {
+	return staticExtensions[strings.ToLower(path.Ext(importPath))]
+}
+
+// PublicPath returns the URL a copied asset is served under, given its
+// path relative to the source root: img/logo.png → /static/img/logo.png.
+// Files outside the source root are published by base name.
+func PublicPath(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		rel = path.Base(rel)
+	}
+	return "/" + StaticDir + "/" + strings.TrimPrefix(rel, "/")
+}
+
+// CopyFile copies src to dst, creating dst's directory as needed
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
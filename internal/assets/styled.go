@@ -0,0 +1,156 @@
+package assets
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// StyledClassName returns the deterministic class name for a styled
+// component: its name plus a short hash of its template, so the name only
+// changes when the styles do.
+func StyledClassName(name, css string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(css))
+	return fmt.Sprintf("%s-%06x", name, h.Sum32()&0xffffff)
+}
+
+// CompileStyled turns a styled-components template body into plain CSS
+// scoped to class. Nested rules (&:hover, & > span, .child) and at-rules
+// are flattened. Declarations and rules containing ${...} interpolations
+// cannot be compiled statically; they are left out of the CSS and
+// returned as dynamic parts.
+func CompileStyled(class, body string) (css string, dynamic []string) {
+	block := parseStyledBlock(cssComment.ReplaceAllString(body, ""))
+	var out strings.Builder
+	writeStyledBlock(&out, "."+class, block, "", &dynamic)
+	return out.String(), dynamic
+}
+
+// styledBlock is the content of a { } block: declarations and nested rules
+type styledBlock struct {
+	decls []string
+	rules []styledRule
+}
+
+type styledRule struct {
+	prelude string
+	body    styledBlock
+}
+
+// parseStyledBlock splits a template body into declarations and nested
+// rules, treating ${...} interpolations as opaque text
+func parseStyledBlock(s string) styledBlock {
+	var block styledBlock
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			i = skipInterpolation(s, i)
+		case s[i] == ';':
+			if decl := strings.TrimSpace(s[start:i]); decl != "" {
+				block.decls = append(block.decls, decl)
+			}
+			start = i + 1
+		case s[i] == '{':
+			end := matchingBrace(s, i)
+			block.rules = append(block.rules, styledRule{
+				prelude: strings.TrimSpace(s[start:i]),
+				body:    parseStyledBlock(s[i+1 : end]),
+			})
+			i = end
+			start = i + 1
+		}
+	}
+	if decl := strings.TrimSpace(s[start:]); decl != "" && decl != "}" {
+		block.decls = append(block.decls, decl)
+	}
+	return block
+}
+
+// skipInterpolation returns the index of the } closing the ${ at i
+func skipInterpolation(s string, i int) int {
+	depth := 0
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(s) - 1
+}
+
+// matchingBrace returns the index of the } matching the { at i
+func matchingBrace(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch {
+		case s[j] == '$' && j+1 < len(s) && s[j+1] == '{':
+			j = skipInterpolation(s, j)
+		case s[j] == '{':
+			depth++
+		case s[j] == '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(s)
+}
+
+func writeStyledBlock(out *strings.Builder, selector string, block styledBlock, indent string, dynamic *[]string) {
+	var decls []string
+	for _, decl := range block.decls {
+		if strings.Contains(decl, "${") {
+			*dynamic = append(*dynamic, decl)
+			continue
+		}
+		decls = append(decls, decl)
+	}
+	if len(decls) > 0 {
+		fmt.Fprintf(out, "%s%s {\n", indent, selector)
+		for _, decl := range decls {
+			fmt.Fprintf(out, "%s  %s;\n", indent, decl)
+		}
+		fmt.Fprintf(out, "%s}\n", indent)
+	}
+
+	for _, rule := range block.rules {
+		if strings.Contains(rule.prelude, "${") {
+			*dynamic = append(*dynamic, rule.prelude+" { ... }")
+			continue
+		}
+		if strings.HasPrefix(rule.prelude, "@") {
+			var inner strings.Builder
+			writeStyledBlock(&inner, selector, rule.body, indent+"  ", dynamic)
+			if inner.Len() > 0 {
+				fmt.Fprintf(out, "%s%s {\n%s%s}\n", indent, rule.prelude, inner.String(), indent)
+			}
+			continue
+		}
+		writeStyledBlock(out, nestSelector(selector, rule.prelude), rule.body, indent, dynamic)
+	}
+}
+
+// nestSelector resolves a nested selector against its parent:
+// "&:hover" → ".x:hover", "span" → ".x span", "a, b" → ".x a, .x b"
+func nestSelector(parent, selector string) string {
+	parts := strings.Split(selector, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if strings.Contains(part, "&") {
+			parts[i] = strings.ReplaceAll(part, "&", parent)
+		} else {
+			parts[i] = parent + " " + part
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Accessibility rule IDs
+const (
+	RuleImgAlt      = "a11y/img-alt"
+	RuleInputLabel  = "a11y/input-label"
+	RuleClickEvents = "a11y/click-non-interactive"
+	RuleButtonType  = "a11y/button-type"
+)
+
+// interactiveTags are elements that are focusable and operable from the
+// keyboard without extra attributes
+var interactiveTags = map[string]bool{
+	"a": true, "button": true, "input": true, "select": true, "textarea": true,
+	"summary": true, "option": true, "label": true, "details": true,
+}
+
+// unlabelledInputTypes need no label: hidden fields and buttons that carry
+// their own text
+var unlabelledInputTypes = map[string]bool{
+	"hidden": true, "submit": true, "reset": true, "button": true, "image": true,
+}
+
+// Accessibility reports images without alt text, form controls without a
+// label, click handlers on elements that cannot be reached from the
+// keyboard, and buttons without an explicit type
+func Accessibility(file *parser.File) []Finding {
+	var findings []Finding
+	for _, comp := range file.Components {
+		if comp.Body == nil {
+			continue
+		}
+		labelled := labelTargets(comp.Body)
+		report := func(rule string, line int, format string, args ...interface{}) {
+			findings = append(findings, Finding{
+				Rule:      rule,
+				Line:      line,
+				Component: comp.Name,
+				Message:   fmt.Sprintf(format, args...),
+			})
+		}
+
+		walk(comp.Body, nil, func(elem *parser.Element, parents []*parser.Element) {
+			if hasSpread(elem) {
+				return
+			}
+			switch elem.Tag {
+			case "img":
+				if _, ok := attr(elem, "alt"); !ok {
+					report(RuleImgAlt, elem.LineNumber, "<img> has no alt text; use alt=\"\" if it is decorative")
+				}
+			case "input", "select", "textarea":
+				if !hasLabel(elem, parents, labelled) {
+					report(RuleInputLabel, elem.LineNumber, "<%s> has no label; wrap it in <label>, point a <label htmlFor> at its id, or add aria-label", elem.Tag)
+				}
+			case "button":
+				if _, ok := attr(elem, "type"); !ok {
+					report(RuleButtonType, elem.LineNumber, "<button> has no type and submits its form by default; add type=\"button\" or type=\"submit\"")
+				}
+			}
+
+			if _, ok := attr(elem, "onClick"); ok && isNonInteractive(elem) {
+				report(RuleClickEvents, elem.LineNumber, "onClick on <%s>, which keyboard users cannot reach; use a <button> (b.Button) or add role and tabIndex", elem.Tag)
+			}
+		})
+	}
+	return findings
+}
+
+// labelTargets collects the ids named by <label htmlFor="..."> elements
+func labelTargets(body parser.Node) map[string]bool {
+	targets := map[string]bool{}
+	walk(body, nil, func(elem *parser.Element, _ []*parser.Element) {
+		if elem.Tag != "label" {
+			return
+		}
+		for _, name := range []string{"htmlFor", "for"} {
+			if a, ok := attr(elem, name); ok && a.Value != "" {
+				targets[a.Value] = true
+			}
+		}
+	})
+	return targets
+}
+
+// hasLabel reports whether a form control has an accessible name
+func hasLabel(elem *parser.Element, parents []*parser.Element, labelled map[string]bool) bool {
+	if a, ok := attr(elem, "type"); ok && unlabelledInputTypes[strings.ToLower(a.Value)] {
+		return true
+	}
+	for _, name := range []string{"aria-label", "aria-labelledby", "title"} {
+		if _, ok := attr(elem, name); ok {
+			return true
+		}
+	}
+	if a, ok := attr(elem, "id"); ok && (labelled[a.Value] || a.Value == "" && a.Expression.Raw != "") {
+		// A computed id may well match a computed htmlFor
+		return true
+	}
+	for _, parent := range parents {
+		if parent.Tag == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+// isNonInteractive reports whether a native element with a click handler
+// is missing the role and tabIndex that would make it keyboard operable
+func isNonInteractive(elem *parser.Element) bool {
+	if interactiveTags[elem.Tag] || !isNativeTag(elem.Tag) {
+		return false
+	}
+	_, role := attr(elem, "role")
+	_, tab := attr(elem, "tabIndex")
+	return !(role && tab)
+}
+
+// isNativeTag reports whether a JSX tag is an HTML element rather than a
+// component
+func isNativeTag(tag string) bool {
+	return tag != "" && tag[0] >= 'a' && tag[0] <= 'z' && !strings.Contains(tag, ".")
+}
@@ -0,0 +1,79 @@
+// Package audit checks parsed JSX for problems worth fixing while a
+// component is being migrated anyway: accessibility gaps, dead code and
+// similar issues that would otherwise be carried over into Go.
+package audit
+
+import (
+	"sort"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Finding is a single audit result
+type Finding struct {
+	Rule      string `json:"rule"` // rule ID, e.g. "a11y/img-alt"
+	Line      int    `json:"line"`
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
+// Run applies every audit to a parsed file and its source and returns
+// the findings ordered by line
+func Run(file *parser.File, source string) []Finding {
+	findings := append(Accessibility(file), DeadCode(file, source)...)
+	findings = append(findings, EffectDeps(file, source)...)
+	findings = append(findings, Security(file)...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// walk calls fn for every element below node, passing the chain of
+// enclosing elements (innermost last)
+func walk(node parser.Node, parents []*parser.Element, fn func(elem *parser.Element, parents []*parser.Element)) {
+	switch n := node.(type) {
+	case *parser.Element:
+		fn(n, parents)
+		inner := append(parents[:len(parents):len(parents)], n)
+		for _, child := range n.Children {
+			walk(child, inner, fn)
+		}
+	case *parser.Fragment:
+		for _, child := range n.Children {
+			walk(child, parents, fn)
+		}
+	case *parser.MapExpr:
+		walk(n.Body, parents, fn)
+	case *parser.Conditional:
+		walk(n.Consequent, parents, fn)
+	case *parser.Ternary:
+		walk(n.Consequent, parents, fn)
+		walk(n.Alternate, parents, fn)
+	case *parser.Expression:
+		if n.Parsed != nil {
+			walk(n.Parsed, parents, fn)
+		}
+	}
+}
+
+// attr returns the named attribute of an element
+func attr(elem *parser.Element, name string) (*parser.Attribute, bool) {
+	for i := range elem.Attributes {
+		if elem.Attributes[i].Name == name {
+			return &elem.Attributes[i], true
+		}
+	}
+	return nil, false
+}
+
+// hasSpread reports whether an element spreads props, which may supply
+// any attribute the audit looks for
+func hasSpread(elem *parser.Element) bool {
+	for _, a := range elem.Attributes {
+		if a.IsSpread {
+			return true
+		}
+	}
+	return false
+}
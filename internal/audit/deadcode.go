@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Dead code rule IDs
+const (
+	RuleUnusedProp     = "dead-code/unused-prop"
+	RuleStateNeverSet  = "dead-code/state-never-set"
+	RuleStateNeverRead = "dead-code/state-never-read"
+)
+
+// DeadCode reports props that are declared but never referenced, state
+// whose setter is never called (it is a constant), and state whose value
+// is never read (only its setter is used)
+func DeadCode(file *parser.File, source string) []Finding {
+	lines := strings.Split(source, "\n")
+	var findings []Finding
+	for _, sc := range scopes(file, len(lines)) {
+		comp := sc.comp
+		body := componentSource(lines, comp, sc.end)
+		if body == "" {
+			continue
+		}
+		report := func(rule string, line int, format string, args ...interface{}) {
+			findings = append(findings, Finding{
+				Rule:      rule,
+				Line:      line,
+				Component: comp.Name,
+				Message:   fmt.Sprintf(format, args...),
+			})
+		}
+
+		for _, prop := range comp.Props {
+			// { label: heading } is used as heading
+			local := prop.Name
+			if prop.Alias != "" {
+				local = prop.Alias
+			}
+			if !references(body, local) {
+				report(RuleUnusedProp, comp.LineNumber, "prop %q is never used; drop it from the component and its callers", prop.Name)
+			}
+		}
+
+		for _, sv := range comp.StateVars {
+			rest := withoutDeclaration(body, sv.LineNumber-comp.LineNumber, sv)
+			setterUsed := references(rest, sv.Setter)
+			valueUsed := references(rest, sv.Name)
+			switch {
+			case !setterUsed && !valueUsed:
+				report(RuleStateNeverRead, sv.LineNumber, "state %q is never read or set; remove it", sv.Name)
+			case !setterUsed:
+				report(RuleStateNeverSet, sv.LineNumber, "%s is never called, so %q is a constant; replace the useState with a value or prop", sv.Setter, sv.Name)
+			case !valueUsed:
+				report(RuleStateNeverRead, sv.LineNumber, "state %q is set but never read; remove it along with its %s calls", sv.Name, sv.Setter)
+			}
+		}
+	}
+	return findings
+}
+
+// scope is a component or custom hook to audit, ending before line end
+type scope struct {
+	comp parser.Component
+	end  int
+}
+
+// scopes returns the components and custom hooks of a file in source
+// order. Hooks are audited like components: their state and effects
+// follow the same rules.
+func scopes(file *parser.File, lines int) []scope {
+	var all []scope
+	for _, comp := range file.Components {
+		all = append(all, scope{comp: comp})
+	}
+	for _, h := range file.CustomHooks {
+		all = append(all, scope{comp: parser.Component{
+			Name:        h.Name,
+			Props:       h.Params,
+			StateVars:   h.StateVars,
+			DerivedVars: h.DerivedVars,
+			LineNumber:  h.LineNumber,
+		}})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].comp.LineNumber < all[j].comp.LineNumber })
+	for i := range all {
+		all[i].end = lines + 1
+		if i+1 < len(all) {
+			all[i].end = all[i+1].comp.LineNumber
+		}
+	}
+	return all
+}
+
+// componentSource returns the text of a component from its first line up
+// to (not including) line end, with the parameter list blanked out so
+// only uses in the body count
+func componentSource(lines []string, comp parser.Component, end int) string {
+	start := comp.LineNumber - 1
+	if start < 0 || start >= len(lines) {
+		return ""
+	}
+	body := strings.Join(lines[start:min(end-1, len(lines))], "\n")
+
+	open := strings.Index(body, comp.Name)
+	if open >= 0 {
+		open = strings.IndexByte(body[open:], '(') + open
+	}
+	if open < 0 || open >= len(body) {
+		return body
+	}
+	depth := 0
+	for i := open; i < len(body); i++ {
+		switch body[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				blank := strings.Map(func(r rune) rune {
+					if r == '\n' {
+						return r
+					}
+					return ' '
+				}, body[open:i+1])
+				return body[:open] + blank + body[i+1:]
+			}
+		}
+	}
+	return body
+}
+
+// withoutDeclaration blanks out the declaration of a state variable,
+// const [open, setOpen] = useState(false), found from the given 0-based
+// line of text on. Only the declaration goes: on a component written on
+// one line the rest of the line is its body. The whole line is blanked
+// when the declaration is not found.
+func withoutDeclaration(text string, line int, sv parser.StateVariable) string {
+	start := 0
+	for i := 0; i < line; i++ {
+		next := strings.IndexByte(text[start:], '\n')
+		if next < 0 {
+			return text
+		}
+		start += next + 1
+	}
+	decl := regexp.MustCompile(`\[\s*` + regexp.QuoteMeta(sv.Name) + `\s*,\s*` + regexp.QuoteMeta(sv.Setter) + `\s*\]\s*=\s*[\w$.]+\s*(?:<[^>()]*>\s*)?\(`)
+	loc := decl.FindStringIndex(text[start:])
+	if loc == nil {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			end = len(text) - start
+		}
+		return text[:start] + text[start+end:]
+	}
+	from, end := start+loc[0], start+loc[1]
+	for depth := 1; depth > 0 && end < len(text); end++ {
+		switch text[end] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	blank := strings.Map(func(r rune) rune {
+		if r == '\n' {
+			return r
+		}
+		return ' '
+	}, text[from:end])
+	return text[:from] + blank + text[end:]
+}
+
+// references reports whether name is used as an identifier in text, other
+// than as a property name (obj.name)
+func references(text, name string) bool {
+	ref := regexp.MustCompile(`(^|[^.\w$])` + regexp.QuoteMeta(name) + `\b`)
+	return ref.MatchString(text)
+}
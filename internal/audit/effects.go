@@ -0,0 +1,202 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Hook dependency rule IDs
+const (
+	RuleMissingDeps     = "hooks/missing-deps"
+	RuleUnnecessaryDeps = "hooks/unnecessary-deps"
+	RuleNoDeps          = "hooks/no-deps"
+)
+
+// depHookCall matches hooks that take a callback and a dependency array
+var depHookCall = regexp.MustCompile(`\b(useEffect|useLayoutEffect|useCallback|useMemo)\s*\(`)
+
+// memoDecl matches values computed by hooks, which are reactive themselves
+var memoDecl = regexp.MustCompile(`\b(?:const|let)\s+(\w+)\s*=\s*(?:React\.)?(?:useMemo|useCallback)\s*\(`)
+
+// reducerDecl matches const [state, dispatch] = useReducer(...); dispatch is stable
+var reducerDecl = regexp.MustCompile(`\b(?:const|let)\s+\[\s*(\w+)\s*(?:,\s*\w+\s*)?\]\s*=\s*(?:React\.)?useReducer\s*\(`)
+
+// EffectDeps compares the dependency arrays of useEffect, useLayoutEffect,
+// useCallback and useMemo with the props, state and memoized values their
+// callbacks read. A missing dependency means the callback sees a stale
+// value, and that behaviour silently changes once the logic runs
+// server-side on every request.
+func EffectDeps(file *parser.File, source string) []Finding {
+	lines := strings.Split(source, "\n")
+	var findings []Finding
+	for _, sc := range scopes(file, len(lines)) {
+		comp := sc.comp
+		body := componentSource(lines, comp, sc.end)
+		if body == "" {
+			continue
+		}
+		reactive := reactiveNames(comp, body)
+
+		for _, loc := range depHookCall.FindAllStringSubmatchIndex(body, -1) {
+			hook := body[loc[2]:loc[3]]
+			line := comp.LineNumber + strings.Count(body[:loc[0]], "\n")
+			args, ok := callArgs(body, loc[1]-1)
+			if !ok || len(args) == 0 {
+				continue
+			}
+			report := func(rule, format string, a ...interface{}) {
+				findings = append(findings, Finding{
+					Rule:      rule,
+					Line:      line,
+					Component: comp.Name,
+					Message:   fmt.Sprintf(format, a...),
+				})
+			}
+
+			callback := args[0]
+			var used []string
+			for _, name := range reactive {
+				if references(callback, name) {
+					used = append(used, name)
+				}
+			}
+
+			if len(args) < 2 {
+				if hook == "useEffect" || hook == "useLayoutEffect" {
+					if len(used) > 0 {
+						report(RuleNoDeps, "%s has no dependency array and runs after every render; list %s to run it only when they change", hook, strings.Join(quoted(used), ", "))
+					}
+				} else {
+					report(RuleNoDeps, "%s has no dependency array, so nothing is memoized", hook)
+				}
+				continue
+			}
+
+			deps := depRoots(args[1])
+			var missing, unnecessary []string
+			for _, name := range used {
+				if !deps[name] {
+					missing = append(missing, name)
+				}
+			}
+			for dep := range deps {
+				if !references(callback, dep) {
+					unnecessary = append(unnecessary, dep)
+				}
+			}
+			sort.Strings(unnecessary)
+
+			if len(missing) > 0 {
+				report(RuleMissingDeps, "%s reads %s without listing it in its dependencies, so it sees a stale value; the server-side version will see the current one", hook, strings.Join(quoted(missing), ", "))
+			}
+			// Extra effect dependencies are a legitimate way to re-run an
+			// effect; for memoization they only cause recomputation
+			if len(unnecessary) > 0 && (hook == "useCallback" || hook == "useMemo") {
+				report(RuleUnnecessaryDeps, "%s lists %s but never reads it", hook, strings.Join(quoted(unnecessary), ", "))
+			}
+		}
+	}
+	return findings
+}
+
+// reactiveNames returns the values of a component that can change between
+// renders: props, state, derived values and memoized values. Setters,
+// dispatch functions and refs are stable and left out.
+func reactiveNames(comp parser.Component, body string) []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if name != "" && name != "props" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, prop := range comp.Props {
+		if prop.Alias != "" {
+			add(prop.Alias)
+		} else {
+			add(prop.Name)
+		}
+	}
+	for _, sv := range comp.StateVars {
+		add(sv.Name)
+	}
+	for _, dv := range comp.DerivedVars {
+		add(dv.Name)
+	}
+	for _, m := range memoDecl.FindAllStringSubmatch(body, -1) {
+		add(m[1])
+	}
+	for _, m := range reducerDecl.FindAllStringSubmatch(body, -1) {
+		add(m[1])
+	}
+	return names
+}
+
+// depRoots returns the root identifiers of a dependency array literal:
+// [user.id, filter] → user, filter
+func depRoots(array string) map[string]bool {
+	roots := map[string]bool{}
+	array = strings.TrimSpace(array)
+	if !strings.HasPrefix(array, "[") || !strings.HasSuffix(array, "]") {
+		return roots
+	}
+	for _, dep := range strings.Split(array[1:len(array)-1], ",") {
+		dep = strings.TrimSpace(dep)
+		if i := strings.IndexAny(dep, ".?["); i >= 0 {
+			dep = dep[:i]
+		}
+		if dep != "" {
+			roots[dep] = true
+		}
+	}
+	return roots
+}
+
+// callArgs splits the arguments of the call whose opening parenthesis is
+// at open, ignoring commas nested in brackets, strings and templates
+func callArgs(s string, open int) ([]string, bool) {
+	var args []string
+	depth := 0
+	var quote byte
+	start := open + 1
+	for i := open; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+			if depth == 0 {
+				if arg := strings.TrimSpace(s[start:i]); arg != "" {
+					args = append(args, arg)
+				}
+				return args, true
+			}
+		case ch == ',' && depth == 1:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return nil, false
+}
+
+func quoted(names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = fmt.Sprintf("%q", name)
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package audit
+
+import "github.com/ha1tch/reminty/internal/parser"
+
+// Security rule IDs
+const (
+	RuleDangerousHTML = "security/dangerous-html"
+)
+
+// Security reports dangerouslySetInnerHTML, which the generator turns
+// into mi.Raw: the HTML is written without escaping, so anything derived
+// from user input is an XSS hole unless it is sanitized first
+func Security(file *parser.File) []Finding {
+	var findings []Finding
+	for _, comp := range file.Components {
+		if comp.Body == nil {
+			continue
+		}
+		walk(comp.Body, nil, func(elem *parser.Element, _ []*parser.Element) {
+			if _, ok := attr(elem, "dangerouslySetInnerHTML"); !ok {
+				return
+			}
+			findings = append(findings, Finding{
+				Rule:      RuleDangerousHTML,
+				Line:      elem.LineNumber,
+				Component: comp.Name,
+				Message:   "dangerouslySetInnerHTML on <" + elem.Tag + "> becomes mi.Raw, which writes the HTML unescaped; sanitize it or make sure it never contains user input",
+			})
+		})
+	}
+	return findings
+}
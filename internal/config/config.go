@@ -0,0 +1,154 @@
+// Package config loads per-project settings from .reminty.yaml.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the config file looked up from the working directory
+const FileName = ".reminty.yaml"
+
+// Config holds project defaults. Command-line flags override them.
+type Config struct {
+	Output             string            `json:"output"`          // output directory for directory conversions
+	Package            string            `json:"package"`         // package name of the generated files
+	MintyImport        string            `json:"minty-import"`    // [name=]path of minty
+	MintydynImport     string            `json:"mintydyn-import"` // [name=]path of mintydyn
+	StaticDir          string            `json:"static-dir"`      // where stylesheets and assets are copied
+	Tailwind           *bool             `json:"tailwind"`
+	KeyIDs             *bool             `json:"key-ids"`             // keep the keys of swapped list items as ids
+	PreserveWhitespace *bool             `json:"preserve-whitespace"` // keep <pre> and <textarea> text as written
+	StripTestAttrs     *bool             `json:"strip-test-attrs"`    // leave data-testid and data-cy out
+	Split              *bool             `json:"split"`               // a Go file per component
+	Framework          string            `json:"framework"`           // react, nextjs or solid
+	Interactivity      string            `json:"interactivity"`       // htmx or alpine
+	Templates          string            `json:"templates"`           // directory of header.tmpl, component.tmpl and handler.tmpl
+	Mappings           []string          `json:"mappings"`            // design-system mapping packs
+	Tags               map[string]string `json:"tags"`                // tag → builder method, e.g. dialog: Dialog
+	Attributes         map[string]string `json:"attributes"`          // attribute → minty option, e.g. inputMode: mi.InputMode
+	Patterns           Patterns          `json:"patterns"`
+	Ignore             []string          `json:"ignore"` // globs of sources to skip, relative to the converted directory
+
+	// Path is the file the config was read from
+	Path string `json:"-"`
+}
+
+// Patterns configures pattern detection
+type Patterns struct {
+	Disable       []string `json:"disable"`        // pattern types not to report
+	MinConfidence float64  `json:"min-confidence"` // drop patterns detected with less confidence
+	Rules         []string `json:"rules"`          // YAML files of pattern rules of the project's own
+	Detectors     []string `json:"detectors"`      // executables detecting patterns of the project's own
+	Alpine        []string `json:"alpine"`         // pattern types whose show/hide state Alpine.js keeps
+}
+
+// Load reads the config file at path. Relative output, static-dir and
+// templates paths are resolved against the file's directory.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{Path: path}
+	if err := UnmarshalYAML(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.Patterns.MinConfidence < 0 || c.Patterns.MinConfidence > 1 {
+		return nil, fmt.Errorf("%s: patterns.min-confidence must be between 0 and 1", path)
+	}
+	for _, glob := range c.Ignore {
+		if _, err := matchGlob(glob, ""); err != nil {
+			return nil, fmt.Errorf("%s: ignore pattern %q: %w", path, glob, err)
+		}
+	}
+	dir := filepath.Dir(path)
+	c.Output = resolve(dir, c.Output)
+	c.StaticDir = resolve(dir, c.StaticDir)
+	c.Templates = resolve(dir, c.Templates)
+	for i, rules := range c.Patterns.Rules {
+		c.Patterns.Rules[i] = resolve(dir, rules)
+	}
+	for i, detector := range c.Patterns.Detectors {
+		c.Patterns.Detectors[i] = resolve(dir, detector)
+	}
+	return c, nil
+}
+
+// Find looks for FileName in dir and its parents, stopping at the root of
+// the repository (a directory containing .git). It returns "" when there
+// is no config file.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, FileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Ignored reports whether the source at rel (slash-separated, relative to
+// the converted directory) matches one of the ignore globs. "**" matches
+// any number of directories.
+func (c *Config) Ignored(rel string) bool {
+	for _, glob := range c.Ignore {
+		if ok, _ := matchGlob(glob, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob is path.Match extended with "**" segments
+func matchGlob(pattern, name string) (bool, error) {
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return false, err
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/")), nil
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func resolve(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, filepath.FromSlash(p))
+}
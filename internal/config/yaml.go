@@ -0,0 +1,466 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalYAML decodes the YAML subset reminty's config files use into v,
+// which is filled as encoding/json would fill it from the equivalent JSON
+// document (so v's fields carry json tags). Unknown keys are errors.
+//
+// Supported: block mappings and sequences, flow [..] and {..}
+// collections, plain, single- and double-quoted scalars, literal (|) and
+// folded (>) block scalars, and # comments. Anchors, tags and multiple
+// documents are not.
+func UnmarshalYAML(data []byte, v interface{}) error {
+	tree, err := parseYAML(string(data))
+	if err != nil {
+		return err
+	}
+	if tree == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(encoded)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string // content after the indentation, comments included
+	raw    string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func parseYAML(src string) (interface{}, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		if strings.TrimSpace(text) == "---" && len(p.lines) == 0 {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(raw) - len(text), text: strings.TrimRight(text, " "), raw: raw})
+	}
+	if !p.skipBlank() {
+		return nil, nil
+	}
+	value, err := p.parseBlock(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipBlank() {
+		return nil, p.errorf("unexpected content")
+	}
+	return value, nil
+}
+
+// skipBlank moves past empty and comment-only lines and reports whether
+// any content is left
+func (p *yamlParser) skipBlank() bool {
+	for p.pos < len(p.lines) {
+		if text := stripComment(p.lines[p.pos].text); text != "" {
+			return true
+		}
+		p.pos++
+	}
+	return false
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	num := 0
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	} else if len(p.lines) > 0 {
+		num = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+// parseBlock parses the mapping or sequence starting at the current line
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if isSeqItem(stripComment(p.lines[p.pos].text)) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseMap(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.skipBlank() {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		text := stripComment(line.text)
+		if isSeqItem(text) {
+			break
+		}
+		key, rest, ok := splitKey(text)
+		if !ok {
+			return nil, p.errorf("expected \"key: value\", found %q", text)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+		value, err := p.parseValue(indent, rest)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+func (p *yamlParser) parseSeq(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for p.skipBlank() {
+		line := p.lines[p.pos]
+		text := stripComment(line.text)
+		if line.indent != indent || !isSeqItem(text) {
+			if line.indent > indent {
+				return nil, p.errorf("unexpected indentation")
+			}
+			break
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+		if _, _, ok := splitKey(rest); ok && !strings.HasPrefix(rest, "[") && !strings.HasPrefix(rest, "{") {
+			// "- key: value" starts a mapping indented past the dash
+			inner := line.indent + len(line.text) - len(strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " "))
+			p.lines[p.pos] = yamlLine{num: line.num, indent: inner, text: strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " "), raw: line.raw}
+			value, err := p.parseMap(inner)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			continue
+		}
+		p.pos++
+		value, err := p.parseValue(indent, rest)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+// parseValue parses what follows "key:" or "-": an inline value, a block
+// scalar, or a nested block on the following lines
+func (p *yamlParser) parseValue(indent int, rest string) (interface{}, error) {
+	switch {
+	case rest == "|" || rest == "|-" || rest == ">" || rest == ">-":
+		return p.blockScalar(indent, rest), nil
+	case rest != "":
+		return parseFlow(rest, p.lines[p.pos-1].num)
+	}
+	if !p.skipBlank() {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || (next.indent == indent && isSeqItem(stripComment(next.text))) {
+		return p.parseBlock(next.indent)
+	}
+	return nil, nil
+}
+
+// blockScalar collects the lines of a | or > scalar
+func (p *yamlParser) blockScalar(indent int, style string) string {
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if line.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = line.indent
+		}
+		lines = append(lines, line.raw[min(blockIndent, line.indent):])
+		p.pos++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var s string
+	if strings.HasPrefix(style, ">") {
+		var b strings.Builder
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "" || lines[i-1] == "":
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line)
+		}
+		s = b.String()
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+	if !strings.HasSuffix(style, "-") && s != "" {
+		s += "\n"
+	}
+	return s
+}
+
+// stripComment removes a trailing # comment outside quotes
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return strings.TrimRight(text[:i], " ")
+		}
+	}
+	return text
+}
+
+// splitKey splits "key: value" at the first ": " (or final ':') outside
+// quotes and flow collections
+func splitKey(text string) (key, rest string, ok bool) {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ':' && depth == 0 && (i == len(text)-1 || text[i+1] == ' '):
+			key = strings.TrimSpace(text[:i])
+			if unquoted, err := unquote(key); err == nil {
+				key = unquoted
+			}
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+// parseFlow parses an inline value: a flow collection or a scalar
+func parseFlow(text string, num int) (interface{}, error) {
+	f := &flowParser{s: text, num: num}
+	value, err := f.value()
+	if err != nil {
+		return nil, err
+	}
+	f.space()
+	if f.i < len(f.s) {
+		return nil, fmt.Errorf("line %d: unexpected %q", num, f.s[f.i:])
+	}
+	return value, nil
+}
+
+type flowParser struct {
+	s   string
+	i   int
+	num int
+}
+
+func (f *flowParser) space() {
+	for f.i < len(f.s) && f.s[f.i] == ' ' {
+		f.i++
+	}
+}
+
+func (f *flowParser) value() (interface{}, error) {
+	f.space()
+	if f.i >= len(f.s) {
+		return nil, nil
+	}
+	switch f.s[f.i] {
+	case '[':
+		f.i++
+		list := []interface{}{}
+		for {
+			f.space()
+			if f.i < len(f.s) && f.s[f.i] == ']' {
+				f.i++
+				return list, nil
+			}
+			item, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.i++
+		m := map[string]interface{}{}
+		for {
+			f.space()
+			if f.i < len(f.s) && f.s[f.i] == '}' {
+				f.i++
+				return m, nil
+			}
+			key, err := f.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			f.space()
+			if f.i >= len(f.s) || f.s[f.i] != ':' {
+				return nil, fmt.Errorf("line %d: expected ':' after %q", f.num, key)
+			}
+			f.i++
+			value, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(key)] = value
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f.scalar(false)
+}
+
+// separator consumes the ',' between flow items, leaving a closing
+// bracket for the caller
+func (f *flowParser) separator(end byte) error {
+	f.space()
+	if f.i < len(f.s) && f.s[f.i] == ',' {
+		f.i++
+		return nil
+	}
+	if f.i < len(f.s) && f.s[f.i] == end {
+		return nil
+	}
+	return fmt.Errorf("line %d: expected ',' or '%c'", f.num, end)
+}
+
+// scalar reads a quoted or plain scalar; inside a collection plain
+// scalars end at ',', ']', '}' (and ':' for keys)
+func (f *flowParser) scalar(key bool) (interface{}, error) {
+	start := f.i
+	if c := f.s[f.i]; c == '"' || c == '\'' {
+		for f.i++; f.i < len(f.s); f.i++ {
+			if f.s[f.i] == '\\' && c == '"' {
+				f.i++
+				continue
+			}
+			if f.s[f.i] == c {
+				if c == '\'' && f.i+1 < len(f.s) && f.s[f.i+1] == '\'' {
+					f.i++
+					continue
+				}
+				f.i++
+				s, err := unquote(f.s[start:f.i])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", f.num, err)
+				}
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("line %d: unterminated string", f.num)
+	}
+
+	nested := strings.ContainsAny(f.s[:start], "[{")
+	for f.i < len(f.s) {
+		c := f.s[f.i]
+		if nested && (c == ',' || c == ']' || c == '}') || key && c == ':' {
+			break
+		}
+		f.i++
+	}
+	text := strings.TrimSpace(f.s[start:f.i])
+	if key {
+		return text, nil
+	}
+	return plainScalar(text), nil
+}
+
+var (
+	yamlInt   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?([0-9]+\.[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+)
+
+// plainScalar types an unquoted scalar as YAML's core schema does
+func plainScalar(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlInt.MatchString(text) {
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	}
+	if yamlFloat.MatchString(text) {
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+// unquote decodes a single- or double-quoted scalar
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '"' && s[0] != '\'') {
+		return s, fmt.Errorf("not quoted")
+	}
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return strconv.Unquote(s)
+}
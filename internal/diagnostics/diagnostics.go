@@ -0,0 +1,126 @@
+// Package diagnostics merges parser warnings, hook suggestions, detected
+// patterns and audit findings into one list of results, each under a
+// stable rule ID, for machine-readable reports.
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/patterns"
+)
+
+// Severity levels, named as in SARIF
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Rule describes one kind of result
+type Rule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"` // default severity of its results
+}
+
+// Diagnostic is a single result at a source location
+type Diagnostic struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+}
+
+var rules = []Rule{
+	{parser.RuleSyntax, "JSX the parser could not read; the output may be incomplete", SeverityWarning},
+	{parser.RuleUseState, "useState has no direct Go equivalent; move the state to the server, mintydyn State or HTMX", SeverityNote},
+	{parser.RuleUseEffect, "useEffect has no direct Go equivalent; move the effect to server-side logic or an HTMX trigger", SeverityNote},
+	{parser.RuleMemo, "Memoization is unnecessary in server-rendered Go", SeverityNote},
+	{parser.RuleUseContext, "useContext becomes function parameters or a context.Context", SeverityNote},
+	{parser.RuleUseRef, "useRef becomes mi.ID() references in mintydyn hooks", SeverityNote},
+	{parser.RuleUseReducer, "useReducer maps to mintydyn Rules", SeverityNote},
+	{parser.RuleCloneElement, "cloneElement injects props that rendered Go children cannot take; pass them at the call sites", SeverityWarning},
+	{audit.RuleImgAlt, "Image without alt text", SeverityWarning},
+	{audit.RuleInputLabel, "Form control without an accessible label", SeverityWarning},
+	{audit.RuleClickEvents, "Click handler on an element that is not keyboard accessible", SeverityWarning},
+	{audit.RuleButtonType, "Button without a type submits its form by default", SeverityWarning},
+	{audit.RuleUnusedProp, "Prop that the component never reads", SeverityWarning},
+	{audit.RuleStateNeverSet, "State that is never updated and can be a constant", SeverityWarning},
+	{audit.RuleStateNeverRead, "State that is never read", SeverityWarning},
+	{audit.RuleMissingDeps, "Effect reads values missing from its dependency array", SeverityWarning},
+	{audit.RuleUnnecessaryDeps, "Effect lists dependencies it never reads", SeverityWarning},
+	{audit.RuleNoDeps, "Effect without a dependency array runs after every render", SeverityWarning},
+	{audit.RuleDangerousHTML, "dangerouslySetInnerHTML becomes mi.Raw, which writes HTML unescaped", SeverityWarning},
+}
+
+// Rules returns every rule that can be reported, in a fixed order
+func Rules() []Rule {
+	all := append([]Rule{}, rules...)
+	for _, t := range patterns.Types() {
+		all = append(all, Rule{
+			ID:          t.Rule(),
+			Description: fmt.Sprintf("%s pattern with a minty/mintydyn equivalent", t),
+			Severity:    SeverityNote,
+		})
+	}
+	return all
+}
+
+// Lookup returns the rule with the given ID
+func Lookup(id string) (Rule, bool) {
+	for _, r := range Rules() {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Collect returns the diagnostics for one analyzed file, ordered by line
+func Collect(file string, result *parser.ParseResult, detected []patterns.DetectedPattern, findings []audit.Finding) []Diagnostic {
+	var diags []Diagnostic
+	add := func(rule string, line, column int, message string) {
+		severity := SeverityWarning
+		if strings.HasPrefix(rule, "pattern/") {
+			// Including those of pattern rules loaded from a file
+			severity = SeverityNote
+		}
+		if r, ok := Lookup(rule); ok {
+			severity = r.Severity
+		}
+		diags = append(diags, Diagnostic{
+			Rule:     rule,
+			Severity: severity,
+			File:     file,
+			Line:     line,
+			Column:   column,
+			Message:  message,
+		})
+	}
+
+	for _, w := range result.Warnings {
+		add(w.Rule(), w.Line, w.Column, w.Message)
+	}
+	for _, s := range result.Suggestions {
+		add(s.Rule(), s.Line, 0, fmt.Sprintf("%s: %s", s.ReactCode, s.MintyHint))
+	}
+	for _, p := range detected {
+		add(p.Type.Rule(), p.Line, 0, fmt.Sprintf("%s (confidence %.0f%%) - consider the minty/mintydyn equivalent", p.Description, p.Confidence*100))
+	}
+	for _, f := range findings {
+		add(f.Rule, f.Line, 0, f.Message)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Line < diags[j].Line
+	})
+	return diags
+}
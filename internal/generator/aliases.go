@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Import paths of the packages generated code uses
+const (
+	MintyImport    = "github.com/ha1tch/minty"
+	MintydynImport = "github.com/ha1tch/mintydyn"
+)
+
+// importPath matches a Go import path
+var importPath = regexp.MustCompile(`^[\w.~-]+(/[\w.~-]+)*$`)
+
+// Import is a package generated code refers to by Name
+type Import struct {
+	Name string
+	Path string
+}
+
+// ParseImport reads an import setting: "path" keeps the name of def,
+// "name=path" renames it too (ui=github.com/acme/minty). An empty spec
+// gives def.
+func ParseImport(spec string, def Import) (Import, error) {
+	if spec == "" {
+		return def, nil
+	}
+	imp := def
+	if name, path, ok := strings.Cut(spec, "="); ok {
+		if !packageName.MatchString(name) {
+			return Import{}, fmt.Errorf("invalid import name %q", name)
+		}
+		imp.Name, spec = name, path
+	}
+	if !importPath.MatchString(spec) {
+		return Import{}, fmt.Errorf("invalid import path %q", spec)
+	}
+	imp.Path = spec
+	return imp, nil
+}
+
+// Aliases are the imports generated code refers to as mi and mdy
+type Aliases struct {
+	Minty    Import
+	Mintydyn Import
+}
+
+// DefaultAliases are the imports reminty generates
+var DefaultAliases = Aliases{
+	Minty:    Import{Name: "mi", Path: MintyImport},
+	Mintydyn: Import{Name: "mdy", Path: MintydynImport},
+}
+
+// Rewrite renames the minty and mintydyn imports of generated source:
+// the import specs, every mi.X and mdy.X selector, and the examples in
+// comments. Strings other than the import paths are left alone.
+func (a Aliases) Rewrite(src string) string {
+	if a == DefaultAliases || a == (Aliases{}) {
+		return src
+	}
+	names := map[string]string{"mi": a.Minty.Name, "mdy": a.Mintydyn.Name}
+	paths := map[string]string{MintyImport: a.Minty.Path, MintydynImport: a.Mintydyn.Path}
+	comment := regexp.MustCompile(`\b(mi|mdy)\.|` + regexp.QuoteMeta(MintyImport) + `(dyn)?\b`)
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		off := file.Offset(pos)
+		var repl string
+		switch tok {
+		case token.IDENT:
+			// A selector (mi.Div) or the name of an import spec
+			next := strings.TrimLeft(src[off+len(lit):], " \t")
+			if name, ok := names[lit]; ok && (strings.HasPrefix(next, ".") || strings.HasPrefix(next, `"`)) {
+				repl = name
+			}
+		case token.STRING:
+			if path, err := strconv.Unquote(lit); err == nil && paths[path] != "" {
+				repl = strconv.Quote(paths[path])
+			}
+		case token.COMMENT:
+			repl = comment.ReplaceAllStringFunc(lit, func(m string) string {
+				if path, ok := paths[m]; ok {
+					return path
+				}
+				return names[strings.TrimSuffix(m, ".")] + "."
+			})
+		}
+		if repl == "" || repl == lit {
+			continue
+		}
+		b.WriteString(src[last:off])
+		b.WriteString(repl)
+		last = off + len(lit)
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
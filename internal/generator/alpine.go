@@ -0,0 +1,375 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Interactivities are the ways the generated code can make state
+// interactive
+var Interactivities = []string{"htmx", "alpine"}
+
+// UseInteractivity selects how state changes are made interactive: htmx
+// (the default) posts every change to an endpoint, alpine keeps the
+// boolean state that only shows and hides markup in the browser, with
+// Alpine.js
+func (g *Generator) UseInteractivity(name string) error {
+	switch name {
+	case "", "htmx":
+		g.alpine = false
+	case "alpine":
+		g.alpine = true
+	default:
+		return fmt.Errorf("unknown interactivity %q (available: %s)", name, strings.Join(Interactivities, ", "))
+	}
+	return nil
+}
+
+// UseAlpineStates keeps the named state variables in the browser with
+// Alpine.js in htmx mode too, where they qualify: the states of the
+// detected patterns the config selects, such as toggles and dropdowns
+func (g *Generator) UseAlpineStates(states []string) {
+	g.alpineFor = map[string]bool{}
+	for _, state := range states {
+		g.alpineFor[state] = true
+	}
+}
+
+// isAlpineAttr reports whether name is an Alpine.js attribute: x-data,
+// x-on:click, or the shorthands @click and :class
+func isAlpineAttr(name string) bool {
+	return strings.HasPrefix(name, "x-") || strings.HasPrefix(name, "@") || strings.HasPrefix(name, ":")
+}
+
+// generateAlpineAttr generates an Alpine.js attribute as it is written.
+// It reports false for other attributes.
+func (g *Generator) generateAlpineAttr(name string, attr *parser.Attribute) bool {
+	if !isAlpineAttr(name) {
+		return false
+	}
+	raw := strings.TrimSpace(attr.Expression.Raw)
+	switch {
+	case attr.Value != "":
+		g.writef("mi.Attr(%q, %q)", name, attr.Value)
+	case raw != "":
+		g.writef("mi.Attr(%q, %s)", name, g.dataValue(raw))
+	default:
+		g.writef("mi.Attr(%q, \"\")", name)
+	}
+	return true
+}
+
+// alpineStates returns the state of comp kept in the browser, in alpine
+// mode or when UseAlpineStates names it: booleans that event handlers
+// only toggle or set, const [open, setOpen] with onClick={() =>
+// setOpen(!open)}, and that the markup only reads to show and hide parts
+// of it, {open && ...}. The component's root must be an HTML element to
+// carry the x-data.
+func (g *Generator) alpineStates(comp *parser.Component) map[string]*parser.StateVariable {
+	if !g.alpine && len(g.alpineFor) == 0 {
+		return nil
+	}
+	root, ok := comp.Body.(*parser.Element)
+	if !ok || isComponentRef(root.Tag) || attrExprRaw(root, "x-data") != "" || attrString(root, "x-data") != "" {
+		return nil
+	}
+	fetched := fetchedState(comp)
+	states := map[string]*parser.StateVariable{}
+	for i, sv := range comp.StateVars {
+		// Pure presentation: a literal start, and no effect, fetch or
+		// derived value depending on it
+		literal := sv.InitValue == "true" || sv.InitValue == "false"
+		if (g.alpine || g.alpineFor[sv.Name]) && literal && sv.Setter != "" && !sv.Persistent && !fetched[sv.Name] && !usedOutsideJSX(comp, &comp.StateVars[i]) {
+			states[sv.Name] = &comp.StateVars[i]
+		}
+	}
+	// Drop the states read or set some other way until the rest only
+	// depend on each other
+	for changed := len(states) > 0; changed; {
+		changed = false
+		for name, sv := range states {
+			if !alpineOnly(comp.Body, sv, states) {
+				delete(states, name)
+				changed = true
+			}
+		}
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	return states
+}
+
+// usedOutsideJSX reports whether a state is read or set outside the
+// markup: in effects, memos and callbacks, or derived variables
+func usedOutsideJSX(comp *parser.Component, sv *parser.StateVariable) bool {
+	uses := mentions(sv)
+	for _, hook := range comp.Hooks {
+		if hook.Type != "useState" && uses.MatchString(hook.Body+" "+strings.Join(hook.Deps, " ")) {
+			return true
+		}
+	}
+	for _, dv := range comp.DerivedVars {
+		if uses.MatchString(dv.Expression) || uses.MatchString(strings.Join(dv.DependsOn, " ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentions matches a state variable or its setter
+func mentions(sv *parser.StateVariable) *regexp.Regexp {
+	return regexp.MustCompile(`\b(?:` + regexp.QuoteMeta(sv.Name) + `|` + regexp.QuoteMeta(sv.Setter) + `)\b`)
+}
+
+// alpineOnly reports whether node reads sv only in conditions showing
+// and hiding markup, and sets it only in handlers Alpine can run given
+// the other states kept in the browser
+func alpineOnly(node parser.Node, sv *parser.StateVariable, states map[string]*parser.StateVariable) bool {
+	uses := mentions(sv)
+	ok := true
+	var walk func(n parser.Node)
+	walk = func(n parser.Node) {
+		switch n := n.(type) {
+		case *parser.Expression:
+			ok = ok && !uses.MatchString(n.Raw)
+		case *parser.Element:
+			for _, attr := range n.Attributes {
+				if attr.EventHandler != nil && uses.MatchString(attr.EventHandler.HandlerBody) {
+					_, fits := alpineAction(attr.EventHandler, states)
+					ok = ok && fits && !isComponentRef(n.Tag)
+					continue
+				}
+				ok = ok && !uses.MatchString(attr.Expression.Raw) && !uses.MatchString(attr.SpreadExpr)
+			}
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.Fragment:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.MapExpr:
+			ok = ok && !uses.MatchString(n.Source())
+			walk(n.Body)
+		case *parser.Conditional:
+			if name, _ := alpineCondition(n.Condition); name != sv.Name {
+				ok = ok && !uses.MatchString(n.Condition)
+			}
+			walk(n.Consequent)
+		case *parser.Ternary:
+			if name, _ := alpineCondition(n.Condition); name != sv.Name {
+				ok = ok && !uses.MatchString(n.Condition)
+			}
+			walk(n.Consequent)
+			walk(n.Alternate)
+		}
+	}
+	walk(node)
+	return ok
+}
+
+// alpineCondition returns the state a condition tests, open or !open,
+// and its Alpine expression
+func alpineCondition(cond string) (string, string) {
+	e, err := parser.ParseJSExpr(strings.TrimSpace(cond))
+	if err != nil {
+		return "", ""
+	}
+	e = parser.Unparen(e)
+	if not, ok := e.(*parser.JSUnary); ok && not.Op == "!" {
+		if id, ok := parser.Unparen(not.X).(*parser.JSIdent); ok {
+			return id.Name, "!" + id.Name
+		}
+		return "", ""
+	}
+	if id, ok := e.(*parser.JSIdent); ok {
+		return id.Name, id.Name
+	}
+	return "", ""
+}
+
+// alpineAction returns the Alpine code of a handler that only toggles or
+// sets states kept in the browser: () => setOpen(!open) → open = !open.
+// It reports false for any other handler.
+func alpineAction(handler *parser.EventHandler, states map[string]*parser.StateVariable) (string, bool) {
+	body := strings.TrimSpace(handler.HandlerBody)
+	e, err := parser.ParseJSExpr(body)
+	if err != nil {
+		return "", false
+	}
+	fn, ok := parser.Unparen(e).(*parser.JSArrow)
+	if !ok {
+		return "", false
+	}
+	var statements []string
+	if fn.Body != nil {
+		statements = []string{fn.Body.Span().Text(body)}
+	} else {
+		statements = strings.Split(fn.Block, ";")
+	}
+	setters := map[string]*parser.StateVariable{}
+	for _, sv := range states {
+		setters[sv.Setter] = sv
+	}
+	var code []string
+	for _, stmt := range statements {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		s, err := parser.ParseJSExpr(stmt)
+		if err != nil {
+			return "", false
+		}
+		call, ok := parser.Unparen(s).(*parser.JSCall)
+		if !ok || len(call.Args) != 1 {
+			return "", false
+		}
+		setter, ok := call.Callee.(*parser.JSIdent)
+		if !ok || setters[setter.Name] == nil {
+			return "", false
+		}
+		name := setters[setter.Name].Name
+		value, ok := alpineValue(call.Args[0], name)
+		if !ok {
+			return "", false
+		}
+		code = append(code, name+" = "+value)
+	}
+	if len(code) == 0 {
+		return "", false
+	}
+	return strings.Join(code, "; "), true
+}
+
+// alpineValue returns the Alpine value a setter of name is called with:
+// true, false, !open, or prev => !prev
+func alpineValue(arg parser.JSExpr, name string) (string, bool) {
+	arg = parser.Unparen(arg)
+	if fn, ok := arg.(*parser.JSArrow); ok && len(fn.Params) == 1 && fn.Body != nil {
+		if not, ok := parser.Unparen(fn.Body).(*parser.JSUnary); ok && not.Op == "!" {
+			if id, ok := parser.Unparen(not.X).(*parser.JSIdent); ok && id.Name == fn.Params[0] {
+				return "!" + name, true
+			}
+		}
+		return "", false
+	}
+	switch v := arg.(type) {
+	case *parser.JSLiteral:
+		if v.Kind == "bool" {
+			return v.Value, true
+		}
+	case *parser.JSUnary:
+		if id, ok := parser.Unparen(v.X).(*parser.JSIdent); ok && v.Op == "!" && id.Name == name {
+			return "!" + name, true
+		}
+	}
+	return "", false
+}
+
+// alpineData returns the x-data attribute the root element of the
+// current component carries, once
+func (g *Generator) alpineData(elem *parser.Element) string {
+	if len(g.alpineState) == 0 || parser.Node(elem) != g.alpineRoot {
+		return ""
+	}
+	g.alpineRoot = nil
+	var states []*parser.StateVariable
+	for _, sv := range g.alpineState {
+		states = append(states, sv)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].LineNumber < states[j].LineNumber })
+	var fields []string
+	for _, sv := range states {
+		fields = append(fields, sv.Name+": "+sv.InitValue)
+	}
+	return fmt.Sprintf("mi.Attr(\"x-data\", %q)", "{ "+strings.Join(fields, ", ")+" }")
+}
+
+// generateAlpineAction generates a handler of states kept in the
+// browser as Alpine code: mi.Attr("x-on:click", "open = !open"). It
+// reports false for other handlers.
+func (g *Generator) generateAlpineAction(handler *parser.EventHandler) bool {
+	if len(g.alpineState) == 0 {
+		return false
+	}
+	code, ok := alpineAction(handler, g.alpineState)
+	if !ok {
+		return false
+	}
+	g.writef("mi.Attr(%q, %q)", "x-on:"+strings.ToLower(strings.TrimPrefix(handler.EventType, "on")), code)
+	return true
+}
+
+// generateAlpineShow generates {open && <p/>} and open ? <A/> : <B/> on
+// a state kept in the browser as markup Alpine shows and hides. It
+// reports false for conditions on other values.
+func (g *Generator) generateAlpineShow(cond string, then, otherwise parser.Node, builder string) bool {
+	name, show := alpineCondition(cond)
+	if g.alpineState[name] == nil {
+		return false
+	}
+	hide := "!" + name
+	if show == hide {
+		hide = name
+	}
+	if otherwise == nil || isNullNode(otherwise) {
+		g.generateNode(alpineShown(then, show), builder)
+		return true
+	}
+	if isNullNode(then) {
+		g.generateNode(alpineShown(otherwise, hide), builder)
+		return true
+	}
+	g.generateFragment(&parser.Fragment{Children: []parser.Node{alpineShown(then, show), alpineShown(otherwise, hide)}}, builder)
+	return true
+}
+
+// isNullNode reports whether a branch renders nothing: {cond ? <A/> : null}
+func isNullNode(n parser.Node) bool {
+	expr, ok := n.(*parser.Expression)
+	if !ok {
+		return false
+	}
+	raw := strings.TrimSpace(expr.Raw)
+	return raw == "null" || raw == "undefined" || raw == "false"
+}
+
+// alpineShown returns node shown only when the Alpine expression show
+// holds. HTML elements get x-show; components are wrapped in a <template
+// x-if>, text in a <span x-show> and anything else in a <div x-show>.
+func alpineShown(node parser.Node, show string) parser.Node {
+	wrapper := "div"
+	switch n := node.(type) {
+	case *parser.Element:
+		if isComponentRef(n.Tag) {
+			return &parser.Element{
+				Tag:        "template",
+				Attributes: []parser.Attribute{{Name: "x-if", Value: show}},
+				Children:   []parser.Node{n},
+				LineNumber: n.LineNumber,
+			}
+		}
+		shown := *n
+		shown.Attributes = append([]parser.Attribute{{Name: "x-show", Value: show}}, n.Attributes...)
+		return &shown
+	case *parser.Fragment:
+		if children := significant(n.Children); len(children) == 1 {
+			return alpineShown(children[0], show)
+		}
+		return &parser.Element{Tag: "div", Attributes: []parser.Attribute{{Name: "x-show", Value: show}}, Children: n.Children, LineNumber: n.LineNumber}
+	case *parser.Text, *parser.Expression:
+		wrapper = "span"
+	}
+	return &parser.Element{
+		Tag:        wrapper,
+		Attributes: []parser.Attribute{{Name: "x-show", Value: show}},
+		Children:   []parser.Node{node},
+		LineNumber: node.Line(),
+	}
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AssetIssue records a src/href whose path is computed at runtime and so
+// could not be rewritten to a copied static asset
+type AssetIssue struct {
+	Line int
+	Attr string
+	Expr string
+}
+
+// UseAssets binds imported static assets to their public paths. Keys are
+// local import names (import logo from './logo.png') or the module path
+// passed to require().
+func (g *Generator) UseAssets(paths map[string]string) {
+	g.assets = paths
+}
+
+// AssetIssues returns the unresolved dynamic asset paths from the last run
+func (g *Generator) AssetIssues() []AssetIssue {
+	return g.assetIssues
+}
+
+var requireCall = regexp.MustCompile(`^require\(\s*['"]([^'"]+)['"]\s*\)$`)
+
+// pathAttrs are the attributes that reference a static file
+var pathAttrs = map[string]bool{
+	"src": true, "href": true, "srcSet": true, "poster": true, "xlinkHref": true,
+}
+
+// translateAssetRef resolves an imported asset identifier or require()
+// call to its public path as a Go string literal
+func (g *Generator) translateAssetRef(expr string) (string, bool) {
+	if len(g.assets) == 0 {
+		return "", false
+	}
+	expr = strings.TrimSpace(expr)
+	if public, ok := g.assets[expr]; ok && isSimpleIdent(expr) {
+		return fmt.Sprintf("%q", public), true
+	}
+	if m := requireCall.FindStringSubmatch(expr); m != nil {
+		if public, ok := g.assets[m[1]]; ok {
+			return fmt.Sprintf("%q", public), true
+		}
+	}
+	return "", false
+}
+
+// checkAssetPath records a path attribute built at runtime from a template
+// literal, concatenation or require() that could not be resolved
+func (g *Generator) checkAssetPath(attr, expr string) {
+	if g.assets == nil || !pathAttrs[attr] {
+		return
+	}
+	if _, ok := g.translateAssetRef(expr); ok {
+		return
+	}
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "`") || strings.Contains(expr, "require(") || strings.Contains(expr, "+") {
+		g.assetIssues = append(g.assetIssues, AssetIssue{Line: g.currentLine, Attr: attr, Expr: expr})
+	}
+}
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// CallSite is a use of a component (<Card title="x" />) as generated: the
+// props in attribute order, each with the kind of value passed
+type CallSite struct {
+	Component string    `json:"component"`
+	File      string    `json:"file,omitempty"`
+	Line      int       `json:"line"`
+	Args      []CallArg `json:"args"`
+	Spread    bool      `json:"spread,omitempty"`   // {...props} hides which props are passed
+	Resolved  bool      `json:"resolved,omitempty"` // imported from another converted file and called by its signature
+}
+
+// CallArg is one prop passed at a call site
+type CallArg struct {
+	Prop   string `json:"prop"`
+	Kind   string `json:"kind"`   // string, number, bool, array, object, func, expr or children
+	Passed bool   `json:"passed"` // whether the generated call passes it as an argument
+}
+
+// ImportedComponent is a component a file imports from another converted
+// file, under its local name
+type ImportedComponent struct {
+	Name   string  // name of the generated function
+	Source string  // file it is converted from
+	Params []Param // parameters of the generated function
+}
+
+// UseImports resolves the components a file imports from other converted
+// files, keyed by the local name the file renders them with. Their calls
+// use the generated function's name and parameter order; other
+// PascalCase tags are called as written.
+func (g *Generator) UseImports(imports map[string]ImportedComponent) {
+	g.imported = imports
+}
+
+// Calls returns the component calls generated in the last run
+func (g *Generator) Calls() []CallSite {
+	return g.calls
+}
+
+var numberLiteral = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// recordCall notes a component call for the cross-file signature check.
+// Passed mirrors generateComponentArgs, which emits attributes in source
+// order and drops key, ref, spreads and valueless attributes, followed by
+// the context values and generateChildArgs.
+func (g *Generator) recordCall(elem *parser.Element) {
+	call := CallSite{Component: elem.Tag, Line: elem.LineNumber}
+	if imp, ok := g.imported[elem.Tag]; ok {
+		call.Component, call.Resolved = imp.Name, true
+	}
+	for _, attr := range elem.Attributes {
+		if attr.IsSpread {
+			call.Spread = true
+			continue
+		}
+		if attr.Name == "key" || attr.Name == "ref" {
+			continue
+		}
+		arg := CallArg{Prop: attr.Name, Kind: "expr"}
+		switch {
+		case attr.Value != "":
+			arg.Kind, arg.Passed = "string", true
+		case attr.Expression.Raw == "":
+			// <Modal open /> is a true boolean prop
+			arg.Kind = "bool"
+		default:
+			arg.Kind, arg.Passed = exprKind(attr.Expression.Raw), true
+		}
+		call.Args = append(call.Args, arg)
+	}
+	// Context values are passed after the props
+	for _, p := range g.contextParams(elem.Tag) {
+		call.Args = append(call.Args, CallArg{Prop: p.Prop, Kind: "expr", Passed: true})
+	}
+	for _, child := range elem.Children {
+		if text, ok := child.(*parser.Text); !ok || strings.TrimSpace(text.Content) != "" {
+			// Nested content is passed as the trailing children arguments
+			call.Args = append(call.Args, CallArg{Prop: "children", Kind: "children", Passed: true})
+			break
+		}
+	}
+	g.calls = append(g.calls, call)
+}
+
+// exprKind classifies a literal JavaScript expression; anything computed
+// is "expr"
+func exprKind(raw string) string {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "true" || raw == "false":
+		return "bool"
+	case numberLiteral.MatchString(raw):
+		return "number"
+	case strings.HasPrefix(raw, "["):
+		return "array"
+	case strings.HasPrefix(raw, "{"):
+		return "object"
+	case strings.Contains(raw, "=>") || strings.HasPrefix(raw, "function"):
+		return "func"
+	}
+	if _, ok := stringLiteral(raw); ok {
+		return "string"
+	}
+	return "expr"
+}
+
+// KindFits reports whether a value of the given kind can be passed to a
+// parameter of a generated Go type
+func KindFits(kind, goType string) bool {
+	switch kind {
+	case "expr":
+		return true
+	case "string":
+		return goType == "string" || goType == "interface{}"
+	case "number":
+		return goType == "int" || goType == "float64" || goType == "interface{}"
+	case "bool":
+		return goType == "bool" || goType == "interface{}"
+	case "array":
+		return strings.HasPrefix(goType, "[]") || goType == "interface{}"
+	case "object":
+		return strings.HasPrefix(goType, "map[") || goType == "interface{}"
+	case "func":
+		// Event handlers become htmx requests, not parameters
+		return false
+	}
+	return true
+}
+
+// generateImportedCall calls a component imported from another converted
+// file with an argument per parameter of its generated function, in
+// order. Props it is not passed get the zero value of their type.
+func (g *Generator) generateImportedCall(elem *parser.Element, imp ImportedComponent) {
+	attrs := map[string]parser.Attribute{}
+	for _, attr := range elem.Attributes {
+		if !attr.IsSpread {
+			attrs[attr.Name] = attr
+		}
+	}
+
+	var args []string
+	children := false
+	for _, p := range imp.Params {
+		if p.Type == childrenType {
+			children = true
+			continue
+		}
+		if attr, ok := attrs[p.Prop]; ok && p.Prop != "" {
+			if lit, ok := typedLiteral(attr.Expression.Raw, p.Type); ok {
+				args = append(args, lit)
+			} else if lit, ok := g.compositeArg(attr.Expression.Raw, p.Type); ok {
+				args = append(args, lit)
+			} else if arg, ok := g.componentArg(attr); ok {
+				args = append(args, arg)
+			} else {
+				// <Modal open /> is a true boolean prop
+				args = append(args, "true")
+			}
+			continue
+		}
+		switch {
+		case p.Name == formErrorsParam:
+			args = append(args, "nil")
+		case p.Prop != "":
+			args = append(args, zeroValue(p.Type)+" /* "+p.Prop+" not passed */")
+		default:
+			args = append(args, zeroValue(p.Type)+" /* TODO: "+p.Name+" */")
+		}
+	}
+
+	g.writef("%s(%s", imp.Name, strings.Join(args, ", "))
+	if children {
+		g.generateChildArgs(elem, len(args) > 0)
+	}
+	g.write(")")
+}
+
+// typedLiteral passes a number or boolean literal to a parameter of a
+// matching type as it is: count={3} → 3
+func typedLiteral(raw, goType string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	switch kind := exprKind(raw); {
+	case kind == "number" && (goType == "float64" || goType == "int" && !strings.Contains(raw, ".")):
+		return raw, true
+	case kind == "bool" && goType == "bool":
+		return raw, true
+	}
+	return "", false
+}
@@ -0,0 +1,352 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// chainedCollection returns the collection of m after the operations
+// chained on it, as a function literal called in place: the filters
+// become loops, the sorts sort.Slice over a copy, the slices bounds
+// checked reslicing. item is the struct of the elements, or nil; elem
+// their scalar type, or "". Steps that do not translate are left as
+// TODO comments, passing the elements through.
+func (g *Generator) chainedCollection(m *parser.MapExpr, collection string, item *itemType, elem string) string {
+	steps, _ := g.chainSteps(m.Chain, item, elem)
+	lines := append([]string{"list := " + collection}, steps...)
+	return g.calledInPlace("[]"+itemGoType(item, elem), append(lines, "return list"))
+}
+
+// itemGoType returns the Go type of the elements of a collection: the
+// struct item, the scalar elem, or interface{}
+func itemGoType(item *itemType, elem string) string {
+	switch {
+	case item != nil:
+		return item.name
+	case elem != "":
+		return elem
+	}
+	return "interface{}"
+}
+
+// chainSteps translates the filter, sort, slice and reverse operations
+// of a chain into statements updating list, counting those left as TODOs
+func (g *Generator) chainSteps(ops []parser.ArrayOp, item *itemType, elem string) (lines []string, todos int) {
+	typ := itemGoType(item, elem)
+	names := map[string]int{}
+	fresh := func(name string) string {
+		names[name]++
+		if names[name] == 1 {
+			return name
+		}
+		return name + strconv.Itoa(names[name])
+	}
+	for _, op := range ops {
+		var step []string
+		switch op.Method {
+		case "filter":
+			step = g.filterStep(op, item, elem, typ, fresh("filtered"))
+		case "sort":
+			step = sortStep(op, item, elem, typ, fresh("sorted"))
+		case "slice":
+			step = g.sliceStep(op)
+		case "reverse":
+			v := fresh("reversed")
+			step = []string{
+				fmt.Sprintf("%s := make([]%s, len(list))", v, typ),
+				"for i, x := range list {",
+				fmt.Sprintf("\t%s[len(list)-1-i] = x", v),
+				"}",
+				"list = " + v,
+			}
+		}
+		if step == nil {
+			step = []string{"// TODO: ." + truncateExpr(op.Raw, 60)}
+			todos++
+		}
+		lines = append(lines, step...)
+	}
+	return lines, todos
+}
+
+// calledInPlace returns lines as the body of a function returning
+// result, called where it is defined
+func (g *Generator) calledInPlace(result string, lines []string) string {
+	indent := "\n" + strings.Repeat("\t", g.indent)
+	var b strings.Builder
+	b.WriteString("func() " + result + " {")
+	for _, line := range lines {
+		b.WriteString(indent + "\t" + line)
+	}
+	b.WriteString(indent + "}()")
+	return b.String()
+}
+
+// rangeList returns the head of a loop over list binding x, with the
+// type assertion an untyped element needs when uses reads x as a map,
+// and the variable holding the element as it is in list
+func rangeList(x, uses string, untyped bool) ([]string, string) {
+	if !untyped {
+		return []string{fmt.Sprintf("for _, %s := range list {", x)}, x
+	}
+	// An untyped element is read as a map, as in a map body
+	kept := x + "Val"
+	loop := []string{fmt.Sprintf("for _, %s := range list {", kept)}
+	if regexp.MustCompile(`\b` + regexp.QuoteMeta(x) + `\b`).MatchString(uses) {
+		loop = append(loop, fmt.Sprintf("\t%s := %s.(map[string]interface{})", x, kept))
+	}
+	return loop, kept
+}
+
+// filterStep translates .filter(x => cond), keeping the elements of list
+// the condition holds for in v
+func (g *Generator) filterStep(op parser.ArrayOp, item *itemType, elem, typ, v string) []string {
+	x, cond, ok := g.callbackCondition(item, elem, op.Params, op.Body)
+	if !ok {
+		return nil
+	}
+	loop, kept := rangeList(x, cond, item == nil && elem == "")
+	return append([]string{fmt.Sprintf("var %s []%s", v, typ)}, append(loop,
+		fmt.Sprintf("\tif %s {", cond),
+		fmt.Sprintf("\t\t%s = append(%s, %s)", v, v, kept),
+		"\t}",
+		"}",
+		"list = "+v,
+	)...)
+}
+
+// sortStep translates .sort(comparator) into sort.Slice over a copy of
+// list; a sort with no comparator orders strings
+func sortStep(op parser.ArrayOp, item *itemType, elem, typ, v string) []string {
+	sorting := fmt.Sprintf("sort.Strings(%s)", v)
+	switch {
+	case op.Params == nil && len(op.Args) == 0 && elem == "string":
+	case op.Params != nil:
+		less, ok := comparatorLess(item, elem, op.Params, op.Body, v)
+		if !ok {
+			return nil
+		}
+		sorting = fmt.Sprintf("sort.Slice(%s, func(i, j int) bool { return %s })", v, less)
+	default:
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("%s := make([]%s, len(list))", v, typ),
+		fmt.Sprintf("copy(%s, list)", v),
+		sorting,
+		"list = " + v,
+	}
+}
+
+// sliceStep translates .slice(start, end) into reslicing list within its
+// bounds, as JavaScript clamps them. A negative start counts from the
+// end; a negative end is not translated.
+func (g *Generator) sliceStep(op parser.ArrayOp) []string {
+	if len(op.Args) == 0 || len(op.Args) > 2 {
+		return nil
+	}
+	start, ok := g.sliceBound(op.Args[0])
+	if !ok {
+		return nil
+	}
+	var step []string
+	if len(op.Args) == 2 {
+		end, ok := g.sliceBound(op.Args[1])
+		if !ok || strings.HasPrefix(end, "-") {
+			return nil
+		}
+		step = append(step, fmt.Sprintf("if len(list) > %s {", end), fmt.Sprintf("\tlist = list[:%s]", end), "}")
+	}
+	switch {
+	case start == "0":
+	case strings.HasPrefix(start, "-"):
+		n := start[1:]
+		step = append(step, fmt.Sprintf("if len(list) > %s {", n), fmt.Sprintf("\tlist = list[len(list)-%s:]", n), "}")
+	default:
+		step = append(step,
+			fmt.Sprintf("if len(list) > %s {", start), fmt.Sprintf("\tlist = list[%s:]", start),
+			"} else {", "\tlist = nil", "}")
+	}
+	if step == nil {
+		// slice(0) copies the array, which makes no difference here
+		step = []string{}
+	}
+	return step
+}
+
+// sliceBound translates a bound of .slice(): an integer literal, or an
+// int prop or state variable
+func (g *Generator) sliceBound(arg string) (string, bool) {
+	e, err := parser.ParseJSExpr(arg)
+	if err != nil {
+		return "", false
+	}
+	if lit := literalGoType(e); lit != "" {
+		return arg, lit == "int"
+	}
+	if u, ok := parser.Unparen(e).(*parser.JSUnary); ok && u.Op == "-" && literalGoType(u.X) == "int" {
+		return "-" + strings.TrimSpace(u.X.Span().Text(arg)), true
+	}
+	x, ok := g.jsToGo(e, arg, g.markupScope())
+	if !ok || x.typ != "int" {
+		return "", false
+	}
+	return x.code, true
+}
+
+// derivedInPlace are the operations generateDerivedChain translates even
+// on their own; generateDerivedVar writes loops for the others
+var derivedInPlace = map[string]bool{"slice": true, "reverse": true, "map": true, "reduce": true}
+
+// generateDerivedChain writes a derived variable chaining operations, or
+// slicing, reversing, mapping or reducing its source, as a function
+// called in place: the filter, sort, slice and reverse steps, then the
+// map, find, some, every, reduce or .length ending the chain. It reports
+// false, writing nothing, for the variables generateDerivedVar handles
+// and any part of the chain that does not translate.
+func (g *Generator) generateDerivedChain(dv parser.DerivedVariable, goName string, sourceKnown bool) bool {
+	ops, length, ok := parser.DerivedChain(dv)
+	if !ok || len(ops) == 1 && !length && !derivedInPlace[ops[0].Method] {
+		return false
+	}
+	source, item, elem := toCamelCase(dv.SourceVar), g.itemTypeOf(dv.SourceVar), ""
+	if c := g.constantRef(dv.SourceVar); c != nil && strings.HasPrefix(c.value.typ, "[]") {
+		source, sourceKnown, item = c.value.code, true, c.item
+		if elem = strings.TrimPrefix(c.value.typ, "[]"); elem == "interface{}" || item != nil {
+			elem = ""
+		}
+	}
+	if !sourceKnown {
+		return false
+	}
+	typ := itemGoType(item, elem)
+	untyped := item == nil && elem == ""
+
+	last, steps := ops[len(ops)-1], ops
+	if !length && !typedOps[last.Method] {
+		steps = ops[:len(ops)-1]
+	}
+	for _, op := range steps {
+		if !typedOps[op.Method] {
+			return false
+		}
+	}
+	translated, todos := g.chainSteps(steps, item, elem)
+	lines := append([]string{"list := " + source}, translated...)
+	result := "[]" + typ
+	switch {
+	case length:
+		lines, result = append(lines, "return len(list)"), "int"
+	case len(steps) > 0 && todos == len(steps):
+		// Nothing translates: the TODO loop shows the original
+		return false
+	case typedOps[last.Method]:
+		lines = append(lines, "return list")
+	case last.Method == "map":
+		x, value, mapped, ok := g.mapValue(last, item, elem)
+		if !ok {
+			return false
+		}
+		loop, _ := rangeList(x, value, untyped)
+		if len(steps) == 0 {
+			// Mapping alone is a loop over the source
+			g.setDerived(dv.Name, goName, "[]"+mapped)
+			g.writef("var %s []%s\n", goName, mapped)
+			loop[0] = strings.Replace(loop[0], "range list", "range "+source, 1)
+			for _, line := range append(loop, fmt.Sprintf("\t%s = append(%s, %s)", goName, goName, value), "}") {
+				g.writeIndent()
+				g.writeln(line)
+			}
+			return true
+		}
+		lines = append(lines, "var mapped []"+mapped)
+		lines = append(lines, append(loop, "\tmapped = append(mapped, "+value+")", "}", "return mapped")...)
+		result = "[]" + mapped
+	case last.Method == "find" || last.Method == "some" || last.Method == "every":
+		x, cond, ok := g.callbackCondition(item, elem, last.Params, last.Body)
+		if !ok {
+			return false
+		}
+		loop, kept := rangeList(x, cond, untyped)
+		match, found, missing := cond, "return "+kept, "return "+zeroValue(typ)
+		switch last.Method {
+		case "find":
+			result = typ
+		case "some":
+			result, found, missing = "bool", "return true", "return false"
+		case "every":
+			result, match, found, missing = "bool", negateCondition(cond), "return false", "return true"
+		}
+		lines = append(lines, append(loop, "\tif "+match+" {", "\t\t"+found, "\t}", "}", missing)...)
+	case last.Method == "reduce":
+		acc := ""
+		if len(steps) == 0 {
+			acc = goName
+		}
+		r, ok := g.reduceLoop(last, acc, item, elem)
+		if !ok {
+			return false
+		}
+		loop, _ := rangeList(r.x, strings.Join(r.body, "\n"), untyped)
+		for _, line := range r.body {
+			loop = append(loop, "\t"+line)
+		}
+		loop = append(loop, "}")
+		if len(steps) == 0 {
+			// Reducing alone is a loop over the source
+			g.setDerived(dv.Name, goName, r.typ)
+			g.writef("%s := %s\n", goName, r.init)
+			loop[0] = strings.Replace(loop[0], "range list", "range "+source, 1)
+			for _, line := range loop {
+				g.writeIndent()
+				g.writeln(line)
+			}
+			return true
+		}
+		lines = append(lines, r.acc+" := "+r.init)
+		lines = append(lines, append(loop, "return "+r.acc)...)
+		result = r.typ
+	default:
+		return false
+	}
+	g.setDerived(dv.Name, goName, result)
+	g.writef("%s := %s\n", goName, g.calledInPlace(result, lines))
+	if last.Method == "find" && !length {
+		g.writeIndent()
+		g.writef("_ = %s\n", goName)
+	}
+	return true
+}
+
+// setDerived records the Go type of a derived variable computed in
+// place, for the markup mapping over it
+func (g *Generator) setDerived(name, goName, typ string) {
+	if g.derived == nil {
+		g.derived = map[string]goExpr{}
+	}
+	g.derived[name] = goExpr{goName, typ}
+}
+
+// mapValue translates the callback of .map(x => value) over items of
+// type item or elem, or maps, returning the Go value and its type
+func (g *Generator) mapValue(op parser.ArrayOp, item *itemType, elem string) (x, value, typ string, ok bool) {
+	if len(op.Params) != 1 || !isSimpleIdent(op.Params[0]) {
+		return "", "", "", false
+	}
+	e, err := parser.ParseJSExpr(op.Body)
+	if err != nil {
+		return "", "", "", false
+	}
+	outerIn, outerVar, outerItem, outerElem := g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem
+	g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem = true, op.Params[0], item, elem
+	v, ok := g.jsToGo(e, op.Body, g.markupScope())
+	g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem = outerIn, outerVar, outerItem, outerElem
+	if !ok || v.typ == "nil" {
+		return "", "", "", false
+	}
+	return op.Params[0], v.code, concreteType(v.typ, v), true
+}
@@ -0,0 +1,336 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// childrenType is the Go type of a component's children parameter. It is
+// variadic, so it always comes last and callers may pass no children.
+const childrenType = "...mi.H"
+
+// childrenParam returns the children parameter of a component: for a
+// destructured children prop, or a props object whose children are
+// rendered with {props.children}
+func childrenParam(comp *parser.Component) (Param, bool) {
+	for _, prop := range comp.Props {
+		if prop.Name == "children" {
+			name := "children"
+			if prop.Alias != "" {
+				name = toCamelCase(prop.Alias)
+			}
+			return Param{Name: name, Type: childrenType, Prop: "children"}, true
+		}
+	}
+	if len(comp.Props) == 1 && (rendersExpr(comp.Body, comp.Props[0].Name+".children") || utilizesChildren(comp.Body, comp.Props[0].Name+".children")) {
+		return Param{Name: "children", Type: childrenType, Prop: "children"}, true
+	}
+	return Param{}, false
+}
+
+// rendersExpr reports whether node renders the expression {raw} anywhere
+func rendersExpr(node parser.Node, raw string) bool {
+	switch n := node.(type) {
+	case *parser.Expression:
+		return strings.TrimSpace(n.Raw) == raw || n.Parsed != nil && rendersExpr(n.Parsed, raw)
+	case *parser.Element:
+		for _, child := range n.Children {
+			if rendersExpr(child, raw) {
+				return true
+			}
+		}
+	case *parser.Fragment:
+		for _, child := range n.Children {
+			if rendersExpr(child, raw) {
+				return true
+			}
+		}
+	case *parser.MapExpr:
+		return rendersExpr(n.Body, raw)
+	case *parser.Conditional:
+		return rendersExpr(n.Consequent, raw)
+	case *parser.Ternary:
+		return rendersExpr(n.Consequent, raw) || rendersExpr(n.Alternate, raw)
+	}
+	return false
+}
+
+// utilizesChildren reports whether node passes the expression raw to a
+// React.Children utility: {Children.map(props.children, ...)}
+func utilizesChildren(node parser.Node, raw string) bool {
+	util := regexp.MustCompile(`\bChildren\.\w+\(\s*` + regexp.QuoteMeta(raw) + `\s*[,)]`)
+	found := false
+	var walk func(n parser.Node)
+	walk = func(n parser.Node) {
+		switch n := n.(type) {
+		case *parser.Expression:
+			found = found || util.MatchString(n.Raw)
+		case *parser.Element:
+			for _, attr := range n.Attributes {
+				found = found || util.MatchString(attr.Expression.Raw)
+			}
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.Fragment:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.MapExpr:
+			walk(n.Body)
+		case *parser.Conditional:
+			found = found || util.MatchString(n.Condition)
+			walk(n.Consequent)
+		case *parser.Ternary:
+			walk(n.Consequent)
+			walk(n.Alternate)
+		}
+	}
+	walk(node)
+	return found
+}
+
+// isChildrenRef reports whether raw renders the current component's
+// children parameter: {children} or {props.children}
+func (g *Generator) isChildrenRef(raw string) bool {
+	if g.children == "" {
+		return false
+	}
+	raw = strings.TrimSpace(raw)
+	// props.children also when the props object was replaced by the props
+	// its propTypes declare
+	return raw == g.children || raw == "props.children" || strings.HasSuffix(raw, ".children") && g.currentParams[strings.TrimSuffix(raw, ".children")]
+}
+
+// generateChildrenRef renders the children parameter as one node
+func (g *Generator) generateChildrenRef() {
+	g.writef("func(b *mi.Builder) mi.Node { nodes := make([]interface{}, len(%[1]s)); for i, c := range %[1]s { nodes[i] = c }; return mi.NewFragment(nodes...) }", g.children)
+}
+
+// generateChildArgs passes the children of a component element as the
+// trailing variadic arguments of its call. Components are passed as they
+// are; elements and text are wrapped into an mi.H.
+func (g *Generator) generateChildArgs(elem *parser.Element, hasArgs bool) {
+	for _, child := range elem.Children {
+		if text, ok := child.(*parser.Text); ok && strings.TrimSpace(text.Content) == "" {
+			continue
+		}
+		if hasArgs {
+			g.write(",\n")
+			g.writeIndent()
+			g.write("\t")
+		}
+		hasArgs = true
+
+		switch n := child.(type) {
+		case *parser.Element:
+			if _, mapped := g.mapped[n.Tag]; isComponentRef(n.Tag) && !mapped {
+				g.generateNode(n, "b")
+				continue
+			}
+			g.write("func(b *mi.Builder) mi.Node { return ")
+			g.generateNode(n, "b")
+			g.write(" }")
+		default:
+			g.write("func(b *mi.Builder) mi.Node { return mi.NewFragment(")
+			g.generateNode(n, "b")
+			g.write(") }")
+		}
+	}
+}
+
+// childrenUtil returns the method and call of a React.Children utility
+// applied to the current component's children: React.Children.count(children),
+// or Children.map(children, ...) with Children imported
+func (g *Generator) childrenUtil(raw string) (string, *parser.JSCall, bool) {
+	if g.children == "" {
+		return "", nil, false
+	}
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return "", nil, false
+	}
+	call, ok := parser.Unparen(e).(*parser.JSCall)
+	if !ok || call.New || len(call.Args) == 0 {
+		return "", nil, false
+	}
+	method, ok := call.Callee.(*parser.JSMember)
+	if !ok || method.Property == "" {
+		return "", nil, false
+	}
+	switch obj := method.Object.(type) {
+	case *parser.JSIdent:
+		ok = obj.Name == "Children"
+	case *parser.JSMember:
+		react, isIdent := obj.Object.(*parser.JSIdent)
+		ok = isIdent && react.Name == "React" && obj.Property == "Children"
+	default:
+		ok = false
+	}
+	if !ok || !g.isChildrenRef(call.Args[0].Span().Text(raw)) {
+		return "", nil, false
+	}
+	return method.Property, call, true
+}
+
+// translateChildrenUtil translates the React.Children utilities that
+// are values over the children parameter: count is its length, toArray
+// the slice itself and only its single child
+func (g *Generator) translateChildrenUtil(raw string) (string, bool) {
+	method, call, ok := g.childrenUtil(raw)
+	if !ok || len(call.Args) != 1 {
+		return "", false
+	}
+	switch method {
+	case "count":
+		return "len(" + g.children + ")", true
+	case "toArray":
+		return g.children, true
+	case "only":
+		return g.children + "[0]", true
+	}
+	return "", false
+}
+
+// generateChildrenMap generates React.Children.map(children, (child, i) =>
+// <li>{child}</li>) as mi.Each over the children parameter, each child
+// rendered where the callback renders it. It reports false for other
+// expressions and callbacks not returning markup.
+func (g *Generator) generateChildrenMap(raw string, line int) bool {
+	method, call, ok := g.childrenUtil(raw)
+	if !ok || method != "map" || len(call.Args) != 2 {
+		return false
+	}
+	fnRaw := strings.TrimSpace(call.Args[1].Span().Text(raw))
+	fn, ok := parser.Unparen(call.Args[1]).(*parser.JSArrow)
+	if !ok || len(fn.Params) == 0 || len(fn.Params) > 2 {
+		return false
+	}
+	for _, p := range fn.Params {
+		if !isSimpleIdent(p) {
+			return false
+		}
+	}
+	// (child) => cloneElement(child, { active }) renders the child as it is
+	if element, props, ok := parser.CloneElement(fn.Body, raw); ok && element == fn.Params[0] {
+		if len(fn.Params) == 2 {
+			g.writef("mi.EachWithIndex(%s, func(%s int, %s mi.H) mi.H {\n", g.children, fn.Params[1], element)
+		} else {
+			g.writef("mi.Each(%s, func(%s mi.H) mi.H {\n", g.children, element)
+		}
+		g.indent++
+		g.writeIndent()
+		g.writef("return %s%s\n", element, cloneTODO(props, true))
+		g.indent--
+		g.writeIndent()
+		g.write("})")
+		return true
+	}
+	body := renderFunction(fnRaw, line)
+	if body == nil {
+		return false
+	}
+
+	// The child and its index read like parameters in the callback
+	outer := g.currentParams
+	g.currentParams = map[string]bool{}
+	for name, ok := range outer {
+		g.currentParams[name] = ok
+	}
+	for _, p := range fn.Params {
+		g.currentParams[p] = true
+	}
+	defer func() { g.currentParams = outer }()
+
+	child := fn.Params[0]
+	if len(fn.Params) == 2 {
+		g.writef("mi.EachWithIndex(%s, func(%s int, %s mi.H) mi.H {\n", g.children, fn.Params[1], child)
+	} else {
+		g.writef("mi.Each(%s, func(%s mi.H) mi.H {\n", g.children, child)
+	}
+	g.indent++
+	g.writeIndent()
+	if elem, ok := body.(*parser.Element); ok && isComponentName(elem.Tag) {
+		if _, mapped := g.mapped[elem.Tag]; !mapped {
+			g.write("return ")
+			g.generateNode(body, "b")
+			g.write("\n")
+			g.indent--
+			g.writeIndent()
+			g.write("})")
+			return true
+		}
+	}
+	g.write("return func(b *mi.Builder) mi.Node {\n")
+	g.indent++
+	g.writeIndent()
+	g.write("return ")
+	g.generateNode(body, "b")
+	g.write("\n")
+	g.indent--
+	g.writeIndent()
+	g.write("}\n")
+	g.indent--
+	g.writeIndent()
+	g.write("})")
+	return true
+}
+
+// translateChildrenCount translates a comparison of the number of
+// children: React.Children.count(children) > 1 → len(children) > 1
+func (g *Generator) translateChildrenCount(cond string) (string, bool) {
+	e, err := parser.ParseJSExpr(cond)
+	if err != nil {
+		return "", false
+	}
+	cmp, ok := parser.Unparen(e).(*parser.JSBinary)
+	if !ok {
+		return "", false
+	}
+	op, ok := map[string]string{">": ">", ">=": ">=", "<": "<", "<=": "<=", "===": "==", "!==": "!=", "==": "==", "!=": "!="}[cmp.Op]
+	if !ok {
+		return "", false
+	}
+	left, ok := g.translateChildrenUtil(strings.TrimSpace(cmp.Left.Span().Text(cond)))
+	if !ok {
+		return "", false
+	}
+	right := g.translateComparisonOperand(strings.TrimSpace(cmp.Right.Span().Text(cond)))
+	if right == "" {
+		return "", false
+	}
+	return left + " " + op + " " + right, true
+}
+
+// generateCloneElement generates React.cloneElement(element, props) as
+// the element itself, which is rendered before it is passed and cannot
+// take more props, with a TODO naming the props it was given. It reports
+// false for other expressions.
+func (g *Generator) generateCloneElement(raw string, line int) bool {
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return false
+	}
+	element, props, ok := parser.CloneElement(e, raw)
+	if !ok {
+		return false
+	}
+	g.generateExpression(&parser.Expression{Raw: element, LineNumber: line})
+	g.write(cloneTODO(props, false))
+	return true
+}
+
+// cloneTODO returns the TODO comment naming the props cloneElement
+// injected, as a line comment or an inline one
+func cloneTODO(props []string, lineComment bool) string {
+	if len(props) == 0 {
+		return ""
+	}
+	todo := "TODO: cloneElement injected " + commentText(strings.Join(props, ", ")) + "; pass them where the element is created"
+	if lineComment {
+		return " // " + todo
+	}
+	return " /* " + todo + " */"
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// classNameFuncs are the class composition helpers translated inline:
+// clsx, the classnames package (and its bind variant cx), and the cn
+// wrapper that shadcn/ui projects define around clsx and tailwind-merge
+var classNameFuncs = map[string]bool{
+	"clsx":       true,
+	"classnames": true,
+	"classNames": true,
+	"cn":         true,
+	"cx":         true,
+}
+
+// classPart is one dynamic part of a composed class list: a Go string
+// expression, included only when cond holds if cond is set
+type classPart struct {
+	cond  string
+	value string
+}
+
+// translateClassNames converts clsx('btn', isActive && 'active', size)
+// to a Go string expression. Static classes are folded into one literal;
+// conditional ones become closures returning the class, after a space
+// separator, or "". ok is false
+// if expr is not a call to a class composition helper.
+func (g *Generator) translateClassNames(expr string) (string, bool) {
+	e, err := parser.ParseJSExpr(expr)
+	if err != nil {
+		return "", false
+	}
+	call, ok := parser.Unparen(e).(*parser.JSCall)
+	if !ok || call.New {
+		return "", false
+	}
+	callee, ok := call.Callee.(*parser.JSIdent)
+	if !ok || !classNameFuncs[callee.Name] {
+		return "", false
+	}
+
+	var static []string
+	var dynamic []classPart
+	for _, arg := range call.Args {
+		g.collectClasses(arg, expr, "", &static, &dynamic)
+	}
+
+	literals := []string{strings.Join(static, " ")}
+	var args []string
+	nonEmpty := len(static) > 0
+	for _, part := range dynamic {
+		sep := ""
+		if nonEmpty {
+			sep = " "
+		}
+		if part.cond == "" {
+			literals[len(literals)-1] += sep
+			args = append(args, part.value)
+			literals = append(literals, "")
+			nonEmpty = true
+			continue
+		}
+		value := fmt.Sprintf("%q + %s", sep, part.value)
+		if text, err := strconv.Unquote(part.value); err == nil {
+			value = fmt.Sprintf("%q", sep+text)
+		} else if sep == "" {
+			value = part.value
+		}
+		args = append(args, fmt.Sprintf("func() string { if %s { return %s }; return \"\" }()", part.cond, value))
+		literals = append(literals, "")
+		nonEmpty = true
+	}
+	return formatParts(literals, args), true
+}
+
+// collectClasses adds the classes of one argument, recursing into
+// arrays, objects and && chains. cond is the Go condition guarding the
+// argument, if any.
+func (g *Generator) collectClasses(x parser.JSExpr, src, cond string, static *[]string, dynamic *[]classPart) {
+	and := func(test parser.JSExpr) string {
+		c := g.translateCondition(test.Span().Text(src))
+		if bin, ok := test.(*parser.JSBinary); ok && bin.Op == "||" {
+			c = "(" + c + ")"
+		}
+		if cond == "" {
+			return c
+		}
+		return cond + " && " + c
+	}
+
+	switch n := parser.Unparen(x).(type) {
+	case *parser.JSLiteral:
+		switch n.Kind {
+		case "string":
+			if n.Value == "" {
+				return
+			}
+			if cond == "" {
+				*static = append(*static, strings.Fields(n.Value)...)
+				return
+			}
+			*dynamic = append(*dynamic, classPart{cond: cond, value: fmt.Sprintf("%q", n.Value)})
+			return
+		case "null", "undefined", "bool", "number":
+			// false, null and undefined add nothing; numbers are rare
+			// enough to leave out rather than stringify
+			return
+		}
+	case *parser.JSArray:
+		for _, item := range n.Elements {
+			if item != nil {
+				g.collectClasses(item, src, cond, static, dynamic)
+			}
+		}
+		return
+	case *parser.JSObject:
+		// { 'btn-active': isActive, [styles.wide]: wide }
+		for _, prop := range n.Props {
+			if prop.Spread {
+				continue
+			}
+			var class parser.JSExpr = &parser.JSLiteral{Kind: "string", Value: prop.Key}
+			if prop.Computed != nil {
+				class = prop.Computed
+			}
+			if lit, ok := prop.Value.(*parser.JSLiteral); ok && lit.Kind == "bool" {
+				// { always: true } is unconditional, { never: false } is dropped
+				if lit.Value == "true" {
+					g.collectClasses(class, src, cond, static, dynamic)
+				}
+				continue
+			}
+			keyCond := and(prop.Value)
+			*dynamic = append(*dynamic, classPart{cond: keyCond, value: g.translateStringOperand(class, src)})
+		}
+		return
+	case *parser.JSBinary:
+		if n.Op == "&&" {
+			g.collectClasses(n.Right, src, and(n.Left), static, dynamic)
+			return
+		}
+	}
+
+	value := g.translateStringOperand(x, src)
+	if cond == "" {
+		*dynamic = append(*dynamic, classPart{value: value})
+		return
+	}
+	*dynamic = append(*dynamic, classPart{cond: cond, value: value})
+}
@@ -0,0 +1,279 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// compoundTags maps the members of the compound component families
+// reminty knows to the HTML elements that play their part: <Select> with
+// <Select.Option>s is a <select> with <option>s. Tabs are generated as
+// mintydyn states instead.
+var compoundTags = map[string]map[string]string{
+	"Select": {
+		"":         "select",
+		"Option":   "option",
+		"Item":     "option",
+		"Group":    "optgroup",
+		"OptGroup": "optgroup",
+	},
+	"Accordion": {
+		"":        "div",
+		"Item":    "details",
+		"Header":  "summary",
+		"Title":   "summary",
+		"Trigger": "summary",
+		"Summary": "summary",
+		"Panel":   "div",
+		"Content": "div",
+		"Body":    "div",
+	},
+}
+
+// Members of the Tabs family
+var (
+	tabsLists  = map[string]bool{"List": true, "TabList": true}
+	tabsTabs   = map[string]bool{"Tab": true, "Trigger": true}
+	tabsPanels = map[string]bool{"Panel": true, "Content": true, "Pane": true, "TabPane": true}
+)
+
+// compoundFamily returns the family and member of a compound component
+// tag, Tabs.Panel → Tabs, Panel, unless the family is a component of
+// this file or of another converted one
+func (g *Generator) compoundFamily(tag string) (family, member string, ok bool) {
+	family, member, _ = strings.Cut(tag, ".")
+	if _, known := compoundTags[family]; !known && family != "Tabs" {
+		return "", "", false
+	}
+	if g.local[family] {
+		return "", "", false
+	}
+	if _, imported := g.imported[family]; imported {
+		return "", "", false
+	}
+	return family, member, true
+}
+
+// generateCompound generates a compound component of a known family:
+// Tabs with their panels as mintydyn states, the others as the HTML
+// elements they stand for. It reports false for other elements.
+func (g *Generator) generateCompound(elem *parser.Element, builder string) bool {
+	family, member, ok := g.compoundFamily(elem.Tag)
+	if !ok || member != "" {
+		return false
+	}
+	if family == "Tabs" {
+		return g.generateTabs(elem, builder)
+	}
+	g.generateElement(compoundElement(elem, family), builder)
+	return true
+}
+
+// compoundElement returns elem with the members of family replaced by
+// their HTML elements
+func compoundElement(elem *parser.Element, family string) *parser.Element {
+	out := *elem
+	member := strings.TrimPrefix(strings.TrimPrefix(elem.Tag, family), ".")
+	out.Tag = compoundTags[family][member]
+	if family != "Select" {
+		// Behaviour props (type="single", collapsible, value) have no
+		// meaning on the HTML elements
+		out.Attributes = nil
+		for _, attr := range elem.Attributes {
+			if presentational(attr.Name) {
+				out.Attributes = append(out.Attributes, attr)
+			}
+		}
+	}
+	out.Children = make([]parser.Node, len(elem.Children))
+	for i, child := range elem.Children {
+		if e, ok := child.(*parser.Element); ok && strings.HasPrefix(e.Tag, family+".") {
+			if _, known := compoundTags[family][strings.TrimPrefix(e.Tag, family+".")]; known {
+				child = compoundElement(e, family)
+			}
+		}
+		out.Children[i] = child
+	}
+	return &out
+}
+
+// presentational reports whether an attribute styles or labels an
+// element rather than configuring a component
+func presentational(name string) bool {
+	switch name {
+	case "className", "class", "id", "style":
+		return true
+	}
+	return strings.HasPrefix(name, "data-") || strings.HasPrefix(name, "aria-")
+}
+
+// tabState is one tab of a <Tabs>: its value, label and panel
+type tabState struct {
+	value string
+	label string
+	panel *parser.Element
+}
+
+// generateTabs generates <Tabs> with <Tabs.Tab>s and <Tabs.Panel>s as
+// the mintydyn states the tabs pattern suggests. Panels are matched to
+// tabs by value, or else by position. It reports false if the tabs have
+// no panels.
+func (g *Generator) generateTabs(elem *parser.Element, builder string) bool {
+	var tabs []*parser.Element
+	var panels []*parser.Element
+	var collect func(nodes []parser.Node)
+	collect = func(nodes []parser.Node) {
+		for _, child := range nodes {
+			e, ok := child.(*parser.Element)
+			if !ok {
+				continue
+			}
+			member := strings.TrimPrefix(e.Tag, "Tabs.")
+			switch {
+			case !strings.HasPrefix(e.Tag, "Tabs."):
+			case tabsLists[member]:
+				collect(e.Children)
+			case tabsTabs[member]:
+				tabs = append(tabs, e)
+			case tabsPanels[member]:
+				panels = append(panels, e)
+			}
+		}
+	}
+	collect(elem.Children)
+	if len(panels) == 0 {
+		return false
+	}
+
+	var states []tabState
+	for i, panel := range panels {
+		value := attrString(panel, "value")
+		if value == "" {
+			value = attrString(panel, "tab")
+		}
+		var tab *parser.Element
+		for _, t := range tabs {
+			if value != "" && attrString(t, "value") == value {
+				tab = t
+			}
+		}
+		if tab == nil && i < len(tabs) {
+			tab = tabs[i]
+		}
+		label := attrString(panel, "label")
+		if tab != nil {
+			if text := plainText(tab.Children); text != "" {
+				label = text
+			}
+			if value == "" {
+				value = attrString(tab, "value")
+			}
+		}
+		if value == "" {
+			value = toKebabCase(strings.ReplaceAll(label, " ", ""))
+		}
+		if value == "" {
+			value = "tab" + string(rune('1'+i))
+		}
+		if label == "" {
+			label = value
+		}
+		states = append(states, tabState{value: value, label: label, panel: panel})
+	}
+
+	// The active tab: value or defaultValue, when it is a literal
+	active := attrString(elem, "value")
+	if active == "" {
+		active = attrString(elem, "defaultValue")
+	}
+	found := false
+	for _, s := range states {
+		found = found || s.value == active
+	}
+	if !found {
+		active = states[0].value
+	}
+
+	g.writef("mdy.Dyn(%q).\n", "tabs")
+	g.indent++
+	g.writeIndent()
+	g.write("States([]mdy.ComponentState{\n")
+	g.indent++
+	for _, s := range states {
+		g.writeIndent()
+		state := "mdy.NewState"
+		if s.value == active {
+			state = "mdy.ActiveState"
+		}
+		g.writef("%s(%q, %q, func(b *mi.Builder) mi.Node {\n", state, s.value, s.label)
+		g.indent++
+		g.writeIndent()
+		g.write("return ")
+		if children := significant(s.panel.Children); len(children) == 1 {
+			g.generateNode(children[0], builder)
+		} else {
+			g.generateFragment(&parser.Fragment{Children: children, LineNumber: s.panel.LineNumber}, builder)
+		}
+		g.write("\n")
+		g.indent--
+		g.writeIndent()
+		g.write("}),\n")
+	}
+	g.indent--
+	g.writeIndent()
+	g.write("}).\n")
+	if raw := attrExprRaw(elem, "value"); raw != "" {
+		g.writeIndent()
+		g.writef("// TODO: the active tab follows %s\n", commentText(raw))
+	}
+	g.writeIndent()
+	g.write("Build()")
+	g.indent--
+	return true
+}
+
+// attrString returns the string value of an attribute: value="profile"
+func attrString(elem *parser.Element, name string) string {
+	for _, attr := range elem.Attributes {
+		if attr.Name == name {
+			return jsxText(attr.Value)
+		}
+	}
+	return ""
+}
+
+// attrExprRaw returns the expression an attribute passes: value={tab}
+func attrExprRaw(elem *parser.Element, name string) string {
+	for _, attr := range elem.Attributes {
+		if attr.Name == name {
+			return strings.TrimSpace(attr.Expression.Raw)
+		}
+	}
+	return ""
+}
+
+// plainText returns the text of nodes, or "" unless they are all text
+func plainText(nodes []parser.Node) string {
+	var parts []string
+	for _, n := range nodes {
+		text, ok := n.(*parser.Text)
+		if !ok {
+			return ""
+		}
+		parts = append(parts, strings.TrimSpace(jsxText(text.Content)))
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// significant drops the whitespace-only text between elements
+func significant(nodes []parser.Node) []parser.Node {
+	var out []parser.Node
+	for _, n := range nodes {
+		if text, ok := n.(*parser.Text); ok && strings.TrimSpace(text.Content) == "" {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
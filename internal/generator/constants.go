@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// constant is a top-level const of the file, declared as a Go var
+type constant struct {
+	src   *parser.Constant
+	base  string    // the Go name it has unless another file of the package declares it
+	value goExpr    // the Go name and type of the var
+	init  string    // the Go literal it is initialized with
+	item  *itemType // struct of the elements of an array of objects
+}
+
+// resolveConstants translates the file's constants, naming them the Go
+// way: STATUSES becomes statuses, MAX_ITEMS maxItems
+func (g *Generator) resolveConstants(file *parser.File) {
+	g.constants = map[string]*constant{}
+	for i := range file.Constants {
+		c := &file.Constants[i]
+		e, err := parser.ParseJSExpr(c.Value)
+		if err != nil {
+			continue
+		}
+		name := constName(c.Name)
+		if g.local[name] || g.helpers[name] != nil || g.hooks[name] != nil {
+			name += "Value"
+		}
+		k := &constant{src: c, base: name, item: g.constantStruct(e, name)}
+		if len(file.Components) > 0 {
+			name = g.declare(name, file.Components[0].Name)
+		}
+		typ := constantType(e)
+		if k.item != nil {
+			typ = "[]" + k.item.name
+		}
+		g.constants[c.Name] = k
+		x, ok := g.literalAs(e, c.Value, typ)
+		if !ok {
+			delete(g.constants, c.Name)
+			continue
+		}
+		k.value, k.init = goExpr{name, typ}, x.code
+	}
+}
+
+// screamingCase matches SCREAMING_SNAKE_CASE names
+var screamingCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+
+// constName returns the Go name of a constant, unexported
+func constName(name string) string {
+	if !screamingCase.MatchString(name) {
+		return goLocal(strings.ToLower(name[:1]) + name[1:])
+	}
+	words := strings.Split(strings.ToLower(name), "_")
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return goLocal(strings.Join(words, ""))
+}
+
+// constantType returns the Go type of a constant: that of a scalar, a
+// slice of the type the elements of an array share, a map of the type
+// the values of an object share; interface{} where they differ. Objects
+// inside arrays and objects are map[string]interface{}.
+func constantType(e parser.JSExpr) string {
+	switch n := unfrozen(e).(type) {
+	case *parser.JSArray:
+		types := make([]string, len(n.Elements))
+		for i, el := range n.Elements {
+			types[i] = elementType(el)
+		}
+		return "[]" + sharedType(types)
+	case *parser.JSObject:
+		types := make([]string, len(n.Props))
+		for i, prop := range n.Props {
+			types[i] = elementType(prop.Value)
+		}
+		return "map[string]" + sharedType(types)
+	}
+	if t := scalarType(e); t != "" {
+		return t
+	}
+	return "interface{}"
+}
+
+// elementType returns the type of an element of a constant array or
+// object
+func elementType(e parser.JSExpr) string {
+	if _, ok := unfrozen(e).(*parser.JSObject); ok {
+		return "map[string]interface{}"
+	}
+	return constantType(e)
+}
+
+// sharedType returns the type all of types are, float64 for a mix of int
+// and float64, or interface{}
+func sharedType(types []string) string {
+	if len(types) == 0 {
+		return "interface{}"
+	}
+	typ := types[0]
+	for _, t := range types[1:] {
+		switch {
+		case t == typ:
+		case isNumeric(t) && isNumeric(typ):
+			typ = "float64"
+		default:
+			return "interface{}"
+		}
+	}
+	return typ
+}
+
+// constantStruct returns a struct for the elements of a constant array
+// of objects whose keys hold values of one type each, named after the
+// constant in the singular: NAV_LINKS gives NavLink. It returns nil for
+// anything else.
+func (g *Generator) constantStruct(e parser.JSExpr, name string) *itemType {
+	arr, ok := unfrozen(e).(*parser.JSArray)
+	if !ok || len(arr.Elements) == 0 {
+		return nil
+	}
+	var keys []string
+	types := map[string][]string{}
+	for _, el := range arr.Elements {
+		obj, ok := unfrozen(el).(*parser.JSObject)
+		if !ok {
+			return nil
+		}
+		for _, prop := range obj.Props {
+			if prop.Key == "" || prop.Shorthand || prop.Spread || !isGoField(prop.Key) {
+				return nil
+			}
+			if types[prop.Key] == nil {
+				keys = append(keys, prop.Key)
+			}
+			types[prop.Key] = append(types[prop.Key], elementType(prop.Value))
+		}
+	}
+	t := &itemType{name: itemTypeName("", name), collection: name}
+	if g.local[t.name] || g.structType(t.name) != nil {
+		t.name += "Item"
+	}
+	for _, k := range keys {
+		typ := sharedType(types[k])
+		if typ == "interface{}" || typ == "[]interface{}" {
+			return nil
+		}
+		t.fields = append(t.fields, Param{Name: exportName(toCamelCase(k)), Type: typ, Prop: k})
+	}
+	return t
+}
+
+// isGoField reports whether an object key makes a Go field name
+func isGoField(key string) bool {
+	return isSimpleIdent(key) && !strings.Contains(key, "$")
+}
+
+// writeConstants declares the file's constants as package-level vars. A
+// slice or map long enough to be hard to read on one line gets one
+// element per line.
+func (g *Generator) writeConstants(file *parser.File) {
+	for _, c := range file.Constants {
+		k := g.constants[c.Name]
+		if k == nil {
+			continue
+		}
+		if k.item != nil {
+			g.writef("// %s is an element of %s\n", k.item.name, c.Name)
+			g.writeStruct(k.item)
+			g.writeln("")
+		}
+		g.writef("// %s is the constant %s (line %d)\n", k.value.code, c.Name, c.LineNumber)
+		g.writef("var %s = %s\n", k.value.code, multiline(k.init, k.value.typ))
+		g.writeln("")
+	}
+}
+
+// multiline breaks a composite literal of type typ longer than a line
+// into one element per line
+func multiline(code, typ string) string {
+	open := len(typ)
+	if len(code) <= 80 || !strings.HasPrefix(code, typ+"{") || !strings.HasSuffix(code, "}") {
+		return code
+	}
+	elems := splitTopLevel(code[open+1:len(code)-1], ',')
+	var b strings.Builder
+	b.WriteString(code[:open+1] + "\n")
+	for _, el := range elems {
+		b.WriteString("\t" + strings.TrimSpace(el) + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// constantRef returns the constant an expression of the markup names, if
+// it is one and no parameter shadows it
+func (g *Generator) constantRef(name string) *constant {
+	if g.currentParams[name] {
+		return nil
+	}
+	return g.constants[name]
+}
+
+// translateConstantRef translates an expression of the markup reading a
+// constant, an element of one being mapped or a key of an object being
+// iterated: STATUSES.length, LABELS[status]
+func (g *Generator) translateConstantRef(raw string) (goExpr, bool) {
+	e, err := parser.ParseJSExpr(strings.TrimSpace(raw))
+	if err != nil {
+		return goExpr{}, false
+	}
+	refs := false
+	for _, name := range parser.JSIdentifiers(e) {
+		if _, local := g.mapLocals[name]; local || g.constantRef(name) != nil || g.inMapBody && g.currentElem != "" && name == g.currentItemVar {
+			refs = true
+		}
+	}
+	if !refs {
+		return goExpr{}, false
+	}
+	return g.jsToGo(e, raw, g.markupScope())
+}
+
+// translateFileRef translates an expression of the markup calling a
+// helper function of the file or reading one of its constants
+func (g *Generator) translateFileRef(raw string) (goExpr, bool) {
+	if x, ok := g.translateHelperCall(raw); ok {
+		return x, true
+	}
+	return g.translateConstantRef(raw)
+}
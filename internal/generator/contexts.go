@@ -0,0 +1,438 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// contextInfo is a React context of the file. Its value is passed down as
+// a parameter from the provider to the components that read it, and to
+// every component rendered in between.
+type contextInfo struct {
+	decl   *parser.ContextDecl
+	typ    string  // Go type of the value
+	fields []Param // fields of the value struct; Prop is the JavaScript key
+}
+
+// setterName matches a state setter passed in a context value
+var setterName = regexp.MustCompile(`^set[A-Z]\w*$`)
+
+// contextValueType returns the name of the struct type generated for a
+// context value: UserContext → UserContextValue
+func contextValueType(name string) string {
+	return exportName(name) + "Value"
+}
+
+// resolveContexts types the contexts declared in the file and works out
+// which components need each one: those reading it with useContext, and
+// those rendering such a component outside a provider of the context
+func (g *Generator) resolveContexts(file *parser.File) {
+	g.contexts = map[string]*contextInfo{}
+	g.contextNeeds = map[string][]string{}
+	g.provided = nil
+	if len(file.Contexts) == 0 {
+		return
+	}
+
+	// Fields: the keys of the default value, of the provided values and
+	// those read by consumers. Functions only run in the browser.
+	keys := map[string][]parser.Prop{}
+	funcs := map[string]map[string]bool{}
+	addKeys := func(ctx, src string) {
+		e, err := parser.ParseJSExpr(src)
+		if err != nil {
+			return
+		}
+		obj, ok := parser.Unparen(e).(*parser.JSObject)
+		if !ok {
+			return
+		}
+		for _, prop := range obj.Props {
+			if prop.Key == "" {
+				continue
+			}
+			value := prop.Key
+			if !prop.Shorthand {
+				value = strings.TrimSpace(prop.Value.Span().Text(src))
+			}
+			if _, fn := parser.Unparen(prop.Value).(*parser.JSArrow); fn || setterName.MatchString(value) {
+				if funcs[ctx] == nil {
+					funcs[ctx] = map[string]bool{}
+				}
+				funcs[ctx][prop.Key] = true
+				continue
+			}
+			if prop.Shorthand {
+				value = ""
+			}
+			keys[ctx] = append(keys[ctx], parser.Prop{Name: prop.Key, DefaultValue: value})
+		}
+	}
+	for _, decl := range file.Contexts {
+		addKeys(decl.Name, decl.Default)
+	}
+	for _, comp := range file.Components {
+		providedValues(comp.Body, func(ctx, value string) {
+			addKeys(ctx, value)
+		})
+	}
+	for _, comp := range file.Components {
+		for _, use := range comp.Contexts {
+			for _, key := range append(use.Keys, use.Fields...) {
+				keys[use.Context] = append(keys[use.Context], parser.Prop{Name: key})
+			}
+		}
+	}
+
+	for i := range file.Contexts {
+		decl := &file.Contexts[i]
+		info := &contextInfo{decl: decl, typ: "interface{}"}
+
+		var props []parser.Prop
+		seen := map[string]bool{}
+		for _, prop := range keys[decl.Name] {
+			if !seen[prop.Name] && !funcs[decl.Name][prop.Name] {
+				seen[prop.Name] = true
+				props = append(props, prop)
+			}
+		}
+		defaults := map[string]string{}
+		for _, prop := range props {
+			defaults[prop.Name] = prop.DefaultValue
+		}
+		info.fields = g.generateParams(props)
+		for j, f := range info.fields {
+			info.fields[j].Name = exportName(f.Name)
+			// A string default types the field where the name does not
+			if _, ok := stringLiteral(defaults[f.Prop]); ok {
+				info.fields[j].Type = "string"
+			}
+		}
+
+		switch {
+		case len(info.fields) > 0:
+			info.typ = contextValueType(decl.Name)
+		case decl.Default != "":
+			if lit, ok := goLiteral(decl.Default); ok {
+				info.typ = literalType(lit)
+			}
+		}
+		g.contexts[decl.Name] = info
+	}
+
+	// What each component reads and renders
+	needs := map[string]map[string]bool{}
+	renders := map[string][]renderedComponent{}
+	for _, comp := range file.Components {
+		needs[comp.Name] = map[string]bool{}
+		for _, use := range comp.Contexts {
+			if g.contexts[use.Context] != nil {
+				needs[comp.Name][use.Context] = true
+			}
+		}
+		renders[comp.Name] = g.renderedComponents(comp.Body, nil)
+	}
+	for changed := true; changed; {
+		changed = false
+		for comp, rendered := range renders {
+			for _, r := range rendered {
+				for ctx := range needs[r.tag] {
+					if !r.provided[ctx] && !needs[comp][ctx] {
+						needs[comp][ctx] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	for comp, ctxs := range needs {
+		for _, decl := range file.Contexts {
+			if ctxs[decl.Name] {
+				g.contextNeeds[comp] = append(g.contextNeeds[comp], decl.Name)
+			}
+		}
+	}
+	g.contextUses = map[string][]parser.ContextUse{}
+	for _, comp := range file.Components {
+		g.contextUses[comp.Name] = comp.Contexts
+	}
+}
+
+// providedValues calls fn with the value source of each
+// <X.Provider value={...}> under node
+func providedValues(node parser.Node, fn func(ctx, value string)) {
+	switch n := node.(type) {
+	case *parser.Element:
+		if ctx, ok := strings.CutSuffix(n.Tag, ".Provider"); ok {
+			for _, attr := range n.Attributes {
+				if attr.Name == "value" && attr.Expression.Raw != "" {
+					fn(ctx, attr.Expression.Raw)
+				}
+			}
+		}
+		for _, child := range n.Children {
+			providedValues(child, fn)
+		}
+	case *parser.Fragment:
+		for _, child := range n.Children {
+			providedValues(child, fn)
+		}
+	case *parser.MapExpr:
+		providedValues(n.Body, fn)
+	case *parser.Conditional:
+		providedValues(n.Consequent, fn)
+	case *parser.Ternary:
+		providedValues(n.Consequent, fn)
+		providedValues(n.Alternate, fn)
+	}
+}
+
+// renderedComponent is a component rendered by another, with the contexts
+// provided around it
+type renderedComponent struct {
+	tag      string
+	provided map[string]bool
+}
+
+// renderedComponents lists the components rendered under node
+func (g *Generator) renderedComponents(node parser.Node, provided map[string]bool) []renderedComponent {
+	var out []renderedComponent
+	walk := func(nodes ...parser.Node) {
+		for _, n := range nodes {
+			out = append(out, g.renderedComponents(n, provided)...)
+		}
+	}
+	switch n := node.(type) {
+	case *parser.Element:
+		if ctx, ok := g.providerOf(n.Tag); ok {
+			inner := map[string]bool{ctx: true}
+			for c := range provided {
+				inner[c] = true
+			}
+			for _, child := range n.Children {
+				out = append(out, g.renderedComponents(child, inner)...)
+			}
+			return out
+		}
+		if isComponentRef(n.Tag) {
+			out = append(out, renderedComponent{tag: n.Tag, provided: provided})
+		}
+		walk(n.Children...)
+	case *parser.Fragment:
+		walk(n.Children...)
+	case *parser.MapExpr:
+		walk(n.Body)
+	case *parser.Conditional:
+		walk(n.Consequent)
+	case *parser.Ternary:
+		walk(n.Consequent, n.Alternate)
+	}
+	return out
+}
+
+// providerOf returns the context a <X.Provider> element provides
+func (g *Generator) providerOf(tag string) (string, bool) {
+	ctx, ok := strings.CutSuffix(tag, ".Provider")
+	return ctx, ok && g.contexts[ctx] != nil
+}
+
+// contextParamName returns the parameter a component receives a context
+// in: the name it binds the value to, or themeContext for ThemeContext
+func (g *Generator) contextParamName(comp, ctx string) string {
+	for _, use := range g.contextUses[comp] {
+		if use.Context == ctx && len(use.Keys) == 0 && len(use.Names) == 1 {
+			return toCamelCase(use.Names[0])
+		}
+	}
+	return strings.ToLower(ctx[:1]) + ctx[1:]
+}
+
+// contextParams returns the context parameters of a component
+func (g *Generator) contextParams(comp string) []Param {
+	var params []Param
+	for _, ctx := range g.contextNeeds[comp] {
+		params = append(params, Param{Name: g.contextParamName(comp, ctx), Type: g.contexts[ctx].typ, Prop: ctx})
+	}
+	return params
+}
+
+// contextArgs returns the context values passed to a component call: the
+// value of the enclosing provider, or the caller's own context parameter
+func (g *Generator) contextArgs(tag string) []string {
+	var args []string
+	for _, ctx := range g.contextNeeds[tag] {
+		switch value, ok := g.provided[ctx]; {
+		case ok:
+			args = append(args, value)
+		case slices.Contains(g.contextNeeds[g.component], ctx):
+			args = append(args, g.contextParamName(g.component, ctx))
+		default:
+			args = append(args, zeroValue(g.contexts[ctx].typ)+" /* TODO: "+ctx+" */")
+		}
+	}
+	return args
+}
+
+// generateContextLocals binds the fields a component destructures from a
+// context value: const { name } = useContext(UserContext)
+func (g *Generator) generateContextLocals(comp *parser.Component) {
+	wrote := false
+	for _, use := range comp.Contexts {
+		info := g.contexts[use.Context]
+		if info == nil || len(use.Keys) == 0 {
+			continue
+		}
+		param := g.contextParamName(comp.Name, use.Context)
+		var names, values []string
+		for i, key := range use.Keys {
+			name := toCamelCase(use.Names[i])
+			if !readsState(comp, use.Names[i]) {
+				continue
+			}
+			names = append(names, name)
+			values = append(values, param+"."+exportName(toCamelCase(key)))
+			g.currentParams[use.Names[i]] = true
+			g.currentParams[name] = true
+		}
+		if len(names) == 0 {
+			continue
+		}
+		g.writeIndent()
+		g.writef("%s := %s\n", strings.Join(names, ", "), strings.Join(values, ", "))
+		wrote = true
+	}
+	if wrote {
+		g.writeln("")
+	}
+}
+
+// generateProvider renders the children of a <X.Provider value={v}>
+// element, passing v to the components below that read the context
+func (g *Generator) generateProvider(elem *parser.Element, ctx, builder string) {
+	value := zeroValue(g.contexts[ctx].typ) + " /* TODO: provider value */"
+	for _, attr := range elem.Attributes {
+		if attr.Name != "value" {
+			continue
+		}
+		if attr.Value != "" {
+			value = fmt.Sprintf("%q", attr.Value)
+		} else if raw := strings.TrimSpace(attr.Expression.Raw); raw != "" {
+			value = g.contextValue(ctx, raw)
+		}
+	}
+
+	outer := g.provided
+	g.provided = map[string]string{ctx: value}
+	for c, v := range outer {
+		if c != ctx {
+			g.provided[c] = v
+		}
+	}
+	defer func() { g.provided = outer }()
+
+	var children []parser.Node
+	for _, child := range elem.Children {
+		if text, ok := child.(*parser.Text); ok && strings.TrimSpace(text.Content) == "" {
+			continue
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		g.generateNode(children[0], builder)
+		return
+	}
+	g.generateFragment(&parser.Fragment{Children: children, LineNumber: elem.LineNumber}, builder)
+}
+
+// contextValue translates a provider's value. An object literal becomes
+// the value struct, leaving out functions, which run in the browser.
+func (g *Generator) contextValue(ctx, raw string) string {
+	info := g.contexts[ctx]
+	e, err := parser.ParseJSExpr(raw)
+	obj, ok := parser.Unparen(e).(*parser.JSObject)
+	if err != nil || !ok || len(info.fields) == 0 {
+		return g.translateExprValue(raw)
+	}
+	var fields []string
+	for _, prop := range obj.Props {
+		for _, f := range info.fields {
+			if prop.Key == "" || f.Prop != prop.Key {
+				continue
+			}
+			src := prop.Key
+			if !prop.Shorthand {
+				src = strings.TrimSpace(prop.Value.Span().Text(raw))
+			}
+			value, ok := goLiteral(src)
+			if !ok {
+				value, ok = g.compositeArg(src, f.Type)
+			}
+			if !ok {
+				value = g.translateExprValue(src)
+			}
+			fields = append(fields, f.Name+": "+value)
+		}
+	}
+	return info.typ + "{" + strings.Join(fields, ", ") + "}"
+}
+
+// writeContextTypes declares the struct types of the context values
+func (g *Generator) writeContextTypes(file *parser.File) {
+	for _, decl := range file.Contexts {
+		info := g.contexts[decl.Name]
+		if info == nil || len(info.fields) == 0 {
+			continue
+		}
+		g.writef("// %s is the value of %s (line %d), passed down from its\n", info.typ, decl.Name, decl.LineNumber)
+		g.writeln("// provider in place of useContext")
+		g.writef("type %s struct {\n", info.typ)
+		width := 0
+		for _, f := range info.fields {
+			width = max(width, len(f.Name))
+		}
+		for _, f := range info.fields {
+			g.writef("\t%-*s %s\n", width, f.Name, f.Type)
+		}
+		g.writeln("}")
+		g.writeln("")
+	}
+}
+
+// contextField translates a field of a context value a component binds
+// whole: auth.userName → auth.UserName. It also returns the field's type.
+func (g *Generator) contextField(expr string) (string, string, bool) {
+	base, field, ok := strings.Cut(strings.TrimSpace(expr), ".")
+	if !ok || !isSimpleIdent(base) || !isSimpleIdent(field) {
+		return "", "", false
+	}
+	for _, ctx := range g.contextNeeds[g.component] {
+		if g.contextParamName(g.component, ctx) != base {
+			continue
+		}
+		for _, f := range g.contexts[ctx].fields {
+			if f.Prop == field {
+				return base + "." + f.Name, f.Type, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// literalType returns the Go type of a translated literal
+func literalType(lit string) string {
+	switch {
+	case lit == "true" || lit == "false":
+		return "bool"
+	case strings.HasPrefix(lit, `"`):
+		return "string"
+	case numberLiteral.MatchString(lit) && !strings.Contains(lit, "."):
+		return "int"
+	case numberLiteral.MatchString(lit):
+		return "float64"
+	}
+	return "interface{}"
+}
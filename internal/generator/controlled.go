@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// controlledField returns the state variable behind a controlled form
+// control: value={x} (or checked={x}) paired with an onChange that calls
+// setX(e.target.value) or setX(e.target.checked)
+func controlledField(elem *parser.Element) (string, bool) {
+	switch elem.Tag {
+	case "input", "select", "textarea":
+	default:
+		return "", false
+	}
+
+	bound, setter := "", ""
+	for _, attr := range elem.Attributes {
+		switch {
+		case attr.Name == "value" || attr.Name == "checked":
+			if raw := strings.TrimSpace(attr.Expression.Raw); handlerIdent.MatchString(raw) {
+				bound = raw
+			}
+		case attr.EventHandler != nil && (attr.Name == "onChange" || attr.Name == "onInput"):
+			h := attr.EventHandler
+			if len(h.SetterCalls) == 1 && (strings.Contains(h.HandlerBody, "target.value") || strings.Contains(h.HandlerBody, "target.checked")) {
+				setter = h.SetterCalls[0]
+			}
+		}
+	}
+	if bound == "" || setter == "" || stateFromSetter(setter) != bound {
+		return "", false
+	}
+	return bound, true
+}
+
+// controlledFields lists the controlled form controls under nodes
+func controlledFields(nodes []parser.Node) []string {
+	var fields []string
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.Element:
+			if field, ok := controlledField(n); ok {
+				fields = append(fields, field)
+			}
+			fields = append(fields, controlledFields(n.Children)...)
+		case *parser.Fragment:
+			fields = append(fields, controlledFields(n.Children)...)
+		case *parser.Conditional:
+			fields = append(fields, controlledFields([]parser.Node{n.Consequent})...)
+		case *parser.Ternary:
+			fields = append(fields, controlledFields([]parser.Node{n.Consequent, n.Alternate})...)
+		}
+	}
+	return fields
+}
+
+// isCheckbox reports whether elem is an <input type="checkbox"> without a
+// value of its own
+func isCheckbox(elem *parser.Element) bool {
+	checkbox := false
+	for _, attr := range elem.Attributes {
+		switch attr.Name {
+		case "type":
+			checkbox = attr.Value == "checkbox"
+		case "value":
+			return false
+		}
+	}
+	return checkbox
+}
+
+// generateControlledField names a controlled control inside a form, so
+// the form submission carries its value, in place of its onChange. A
+// checkbox posts "true" when it is checked.
+func (g *Generator) generateControlledField(elem *parser.Element, field string) {
+	g.writef("mi.Name(%q)", field)
+	if isCheckbox(elem) {
+		g.write(", mi.Value(\"true\")")
+	}
+	g.writef(" /* %s on submit */", "set"+exportName(field))
+}
+
+// formSubmitEndpoint returns the endpoint a form with controlled fields
+// but no onSubmit posts to, and records its handler
+func (g *Generator) formSubmitEndpoint() string {
+	endpoint := "/" + toKebabCase(g.component) + "/submit"
+	g.recordHandler(Handler{Method: "POST", Endpoint: endpoint, Event: "submit", Tag: "form"}, g.controlled)
+	return endpoint
+}
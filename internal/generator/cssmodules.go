@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/assets"
+)
+
+// CSSModuleIssue records a CSS Modules access that could not be resolved
+// to a concrete class name
+type CSSModuleIssue struct {
+	Line   int
+	Expr   string
+	Reason string
+}
+
+// UseCSSModules binds imported CSS Modules (keyed by their local import
+// name, e.g. "styles") so className accesses resolve to global class names
+func (g *Generator) UseCSSModules(modules map[string]*assets.CSSModule) {
+	g.cssModules = modules
+}
+
+// CSSModuleIssues returns the unresolved CSS Modules accesses from the last run
+func (g *Generator) CSSModuleIssues() []CSSModuleIssue {
+	return g.cssIssues
+}
+
+var (
+	cssModuleBracket = regexp.MustCompile(`^(\w+)\[\s*['"]([^'"]+)['"]\s*\]$`)
+	cssModuleDynamic = regexp.MustCompile(`^(\w+)\[(.+)\]$`)
+	cssModuleInterp  = regexp.MustCompile(`\$\{\s*(\w+)(?:\.([\w-]+)|\[\s*['"]([^'"]+)['"]\s*\])\s*\}`)
+)
+
+// translateCSSModuleRef resolves styles.card or styles['card'] to the global
+// class name as a Go string literal. ok is false if expr is not a CSS
+// Modules access at all.
+func (g *Generator) translateCSSModuleRef(expr string) (string, bool) {
+	if len(g.cssModules) == 0 {
+		return "", false
+	}
+	expr = strings.TrimSpace(expr)
+
+	var base, class string
+	if isPropertyAccess(expr) {
+		parts := strings.Split(expr, ".")
+		if len(parts) != 2 {
+			return "", false
+		}
+		base, class = parts[0], parts[1]
+	} else if m := cssModuleBracket.FindStringSubmatch(expr); m != nil {
+		base, class = m[1], m[2]
+	} else if m := cssModuleDynamic.FindStringSubmatch(expr); m != nil {
+		if _, ok := g.cssModules[m[1]]; !ok {
+			return "", false
+		}
+		g.addCSSIssue(expr, "dynamic class lookup")
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(strings.ReplaceAll(expr, "\"", "'"))), true
+	} else {
+		return "", false
+	}
+
+	module, ok := g.cssModules[base]
+	if !ok {
+		return "", false
+	}
+	global, ok := module.Resolve(class)
+	if !ok {
+		g.addCSSIssue(expr, fmt.Sprintf("class %q not found in %s", class, module.OutputName()))
+		return fmt.Sprintf("%q /* TODO: %s not in stylesheet */", class, expr), true
+	}
+	return fmt.Sprintf("%q", global), true
+}
+
+// resolveCSSModuleInterpolations replaces ${styles.x} inside a template
+// literal with the literal global class name
+func (g *Generator) resolveCSSModuleInterpolations(tmpl string) string {
+	if len(g.cssModules) == 0 {
+		return tmpl
+	}
+	return cssModuleInterp.ReplaceAllStringFunc(tmpl, func(match string) string {
+		m := cssModuleInterp.FindStringSubmatch(match)
+		module, ok := g.cssModules[m[1]]
+		if !ok {
+			return match
+		}
+		class := m[2]
+		if class == "" {
+			class = m[3]
+		}
+		if global, ok := module.Resolve(class); ok {
+			return global
+		}
+		g.addCSSIssue(strings.Trim(match, "${}"), fmt.Sprintf("class %q not found in %s", class, module.OutputName()))
+		return match
+	})
+}
+
+func (g *Generator) addCSSIssue(expr, reason string) {
+	g.cssIssues = append(g.cssIssues, CSSModuleIssue{
+		Line:   g.currentLine,
+		Expr:   expr,
+		Reason: reason,
+	})
+}
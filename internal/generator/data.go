@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// testAttrs are the data-* attributes end-to-end tests find elements by
+var testAttrs = map[string]bool{
+	"data-testid": true, "data-test-id": true, "data-test": true, "data-cy": true,
+}
+
+// UseStripTestAttrs turns on leaving the test attributes, data-testid and
+// data-cy, out of the generated code
+func (g *Generator) UseStripTestAttrs(enabled bool) {
+	g.stripTests = enabled
+}
+
+// strippedTestAttr reports whether attr is a test attribute left out
+func (g *Generator) strippedTestAttr(attr *parser.Attribute) bool {
+	return g.stripTests && testAttrs[attr.Name]
+}
+
+// generateDataAttr generates a data-* attribute as mi.Data, named
+// without its prefix, with the string React renders for its value:
+// data-count={items.length} → mi.Data("count", fmt.Sprint(len(items)))
+func (g *Generator) generateDataAttr(name string, attr *parser.Attribute) {
+	dataName := strings.TrimPrefix(name, "data-")
+	switch {
+	case attr.Value != "":
+		g.writef("mi.Data(%q, %q)", dataName, attr.Value)
+	case attr.Expression.Raw != "":
+		g.writef("mi.Data(%q, %s)", dataName, g.dataValue(strings.TrimSpace(attr.Expression.Raw)))
+	default:
+		// <div data-open> is data-open={true}
+		g.writef("mi.Data(%q, \"true\")", dataName)
+	}
+}
+
+// dataValue returns the Go string of a data-* attribute expression.
+// Literals are quoted as React renders them, data-active={true} →
+// "true"; values not known to be strings are converted with fmt.Sprint.
+func (g *Generator) dataValue(raw string) string {
+	if e, err := parser.ParseJSExpr(raw); err == nil {
+		if lit, ok := parser.Unparen(e).(*parser.JSLiteral); ok {
+			switch lit.Kind {
+			case "string", "number", "bool":
+				return fmt.Sprintf("%q", lit.Value)
+			case "null", "undefined":
+				return `""`
+			}
+		}
+	}
+	value := g.translateExprValue(raw)
+	if g.stringValued(raw, value) {
+		return value
+	}
+	return fmt.Sprintf("fmt.Sprint(%s)", value)
+}
+
+// stringValued reports whether the translation value of raw is a Go
+// string: a string literal, a formatted string, a mi.Str lookup, a
+// ternary between strings or a parameter or item field typed string
+func (g *Generator) stringValued(raw, value string) bool {
+	if _, err := strconv.Unquote(value); err == nil {
+		return true
+	}
+	for _, prefix := range []string{"fmt.Sprintf(", "fmt.Sprint(", "mi.Str("} {
+		if strings.HasPrefix(value, prefix) && strings.HasSuffix(value, ")") && parenthesized(value[len(prefix)-1:]) {
+			return true
+		}
+	}
+	if strings.HasPrefix(value, "func() string {") && strings.HasSuffix(value, "}()") {
+		return true
+	}
+	if _, typ, ok := g.itemField(raw); ok {
+		return typ == "string"
+	}
+	for _, p := range g.componentArgs {
+		if p.Name == value {
+			return p.Type == "string"
+		}
+	}
+	return false
+}
+
+// parenthesized reports whether s is one parenthesized group: (a, b)
+// but not (a) + (b)
+func parenthesized(s string) bool {
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && i != len(s)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
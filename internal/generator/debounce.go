@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// inputTrigger returns the hx-trigger of an input that sets state: a
+// request per change or, when the React code debounced the state, one
+// after a pause in typing as long as its delay
+func (g *Generator) inputTrigger(state string) string {
+	if g.currentComp == nil {
+		return "input changed"
+	}
+	for _, d := range g.currentComp.Debounces {
+		if d.State == state && d.Delay > 0 {
+			return fmt.Sprintf("input changed delay:%dms", d.Delay)
+		}
+	}
+	return "input changed"
+}
+
+// debouncedSetter returns the handler as if it called the setter that
+// the debounced function it calls wraps: onChange={e => search(e.target.value)}
+// with const search = debounce(q => setQuery(q), 300)
+func (g *Generator) debouncedSetter(handler *parser.EventHandler) *parser.EventHandler {
+	if g.currentComp == nil || len(handler.SetterCalls) > 0 {
+		return handler
+	}
+	for _, d := range g.currentComp.Debounces {
+		if d.Func == "" || d.State == "" {
+			continue
+		}
+		if !regexp.MustCompile(`\b` + regexp.QuoteMeta(d.Func) + `\s*\(`).MatchString(handler.HandlerBody) {
+			continue
+		}
+		for _, sv := range g.currentComp.StateVars {
+			if sv.Name == d.State && sv.Setter != "" {
+				h := *handler
+				h.SetterCalls = []string{sv.Setter}
+				return &h
+			}
+		}
+	}
+	return handler
+}
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Declaration is a package-level var or function the file declares, as
+// recorded for the other files of the package
+type Declaration struct {
+	Name     string `json:"name"`               // the name the file alone gives it
+	Declared string `json:"declared,omitempty"` // the name declared instead, when another file has Name
+}
+
+// Declarations returns the package-level vars and helper functions
+// generated in the last run, sorted by name
+func (g *Generator) Declarations() []Declaration {
+	var decls []Declaration
+	for _, h := range g.helpers {
+		d := Declaration{Name: h.src.Name}
+		if h.name != h.src.Name {
+			d.Declared = h.name
+		}
+		decls = append(decls, d)
+	}
+	for _, k := range g.constants {
+		d := Declaration{Name: k.base}
+		if k.value.code != k.base {
+			d.Declared = k.value.code
+		}
+		decls = append(decls, d)
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].Name < decls[j].Name })
+	return decls
+}
+
+// declare returns the name a package-level declaration of the file takes:
+// name itself or, when another file of the package declares it, name
+// after the file's first component: statuses becomes todoListStatuses
+func (g *Generator) declare(name, component string) string {
+	if !g.reserved[name] {
+		return name
+	}
+	prefix := goLocal(strings.ToLower(component[:1]) + component[1:])
+	declared := prefix + exportName(name)
+	for i := 2; g.reserved[declared] || g.local[declared]; i++ {
+		declared = prefix + exportName(name) + strconv.Itoa(i)
+	}
+	return declared
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// writeComponentDoc writes the doc comment of a component's function. A
+// component with a JSDoc block keeps its text, with a list of the
+// parameters it documented; one without gets a line naming it.
+func (g *Generator) writeComponentDoc(comp *parser.Component, params []Param) {
+	for _, line := range strings.Split(docSentence(comp.Name, comp.Doc), "\n") {
+		g.writeComment(line)
+	}
+
+	var documented []string
+	for _, p := range params {
+		for _, prop := range comp.Props {
+			if prop.Name == p.Prop && prop.Doc != "" {
+				documented = append(documented, p.Name+": "+strings.Join(strings.Fields(prop.Doc), " "))
+			}
+		}
+	}
+	if len(documented) > 0 {
+		g.writeln("//")
+		for _, line := range documented {
+			g.writeComment("  - " + line)
+		}
+	}
+
+	if comp.Deprecated != "" {
+		g.writeln("//")
+		for i, line := range strings.Split(comp.Deprecated, "\n") {
+			if i == 0 {
+				line = "Deprecated: " + line
+			}
+			g.writeComment(line)
+		}
+	}
+}
+
+func (g *Generator) writeComment(line string) {
+	if line == "" {
+		g.writeln("//")
+		return
+	}
+	g.writeln("// " + line)
+}
+
+// docSentence makes a JSDoc description read as a Go doc comment, which
+// starts with the name it documents: "Renders a user card" becomes "Card
+// renders a user card". A description that cannot be turned that way
+// follows a line naming the component.
+func docSentence(name, doc string) string {
+	switch first, _, _ := strings.Cut(doc, " "); {
+	case doc == "":
+		return name + " component"
+	case first == name || strings.TrimRight(first, ".,:") == name:
+		return doc
+	case len(first) > 2 && strings.HasSuffix(first, "s") && !strings.HasSuffix(first, "ss") &&
+		unicode.IsUpper(rune(first[0])) && strings.ToLower(first[1:]) == first[1:]:
+		return name + " " + strings.ToLower(first[:1]) + doc[1:]
+	}
+	return name + " component\n\n" + doc
+}
+
+// writeWrapperNote notes the memo and forwardRef a component was
+// unwrapped from: the server renders it once per request, so there is
+// nothing to memoize, and no DOM node to forward a ref to
+func (g *Generator) writeWrapperNote(comp *parser.Component) {
+	if len(comp.Wrappers) == 0 {
+		return
+	}
+	call := comp.Name
+	memo, ref := false, false
+	for i := len(comp.Wrappers) - 1; i >= 0; i-- {
+		call = comp.Wrappers[i] + "(" + call + ")"
+		memo = memo || comp.Wrappers[i] == "memo"
+		ref = ref || comp.Wrappers[i] == "forwardRef"
+	}
+	g.writeln("//")
+	g.writef("// Unwrapped from %s.\n", call)
+	if memo {
+		g.writeln("// Memoization is unnecessary server-side, where each request renders it once.")
+	}
+	if ref {
+		g.writeln("// The forwarded ref has no server-side equivalent; give the element an id")
+		g.writeln("// to target it with htmx.")
+	}
+}
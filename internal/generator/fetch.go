@@ -0,0 +1,240 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// fetchedState returns the state variables a component's data-fetching
+// effects fill in: the data, and the loading and error flags. They become
+// locals loaded before render rather than parameters.
+func fetchedState(comp *parser.Component) map[string]bool {
+	fetched := map[string]bool{}
+	for _, f := range comp.Fetches {
+		for _, state := range []string{f.State, f.Loading, f.Error} {
+			if state != "" {
+				fetched[state] = true
+			}
+		}
+	}
+	return fetched
+}
+
+// fetchesRemote reports whether a fetch goes to another host. Requests to
+// the app's own API are a repository call once the page renders on the
+// server.
+func fetchesRemote(f parser.DataFetch) bool {
+	url := strings.TrimLeft(f.URL, "'\"`")
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+func loaderName(comp *parser.Component, f parser.DataFetch) string {
+	return "load" + exportName(comp.Name) + exportName(toCamelCase(f.State))
+}
+
+// stateType returns the Go type of a component's state variable
+func stateType(comp *parser.Component, state string) string {
+	for _, sv := range comp.StateVars {
+		if sv.Name == state && sv.InitType != "" {
+			return sv.InitType
+		}
+	}
+	return "interface{}"
+}
+
+// loaderArgs returns the component parameters a fetch URL reads
+func (g *Generator) loaderArgs(f parser.DataFetch) []Param {
+	e, err := parser.ParseJSExpr(f.URL)
+	if err != nil {
+		return nil
+	}
+	reads := map[string]bool{}
+	for _, name := range parser.JSIdentifiers(e) {
+		reads[toCamelCase(name)] = true
+	}
+	var args []Param
+	for _, p := range g.componentArgs {
+		if reads[p.Name] && p.Type != childrenType {
+			args = append(args, p)
+		}
+	}
+	return args
+}
+
+// generateFetches loads the data of the component's fetching effects
+// before render, in place of the request the effect made on mount. Loaded
+// state that read reports unused is discarded.
+func (g *Generator) generateFetches(comp *parser.Component, read func(state string) bool) {
+	if len(comp.Fetches) == 0 {
+		return
+	}
+	for _, f := range comp.Fetches {
+		args := g.loaderArgs(f)
+		names := make([]string, len(args))
+		for i, p := range args {
+			names[i] = p.Name
+		}
+
+		g.writeIndent()
+		if f.Await {
+			g.writef("// Loaded on the server - replaces the request awaited at line %d\n", f.LineNumber)
+			g.currentParams[f.State] = true
+			if g.objectLike(f.State) {
+				g.objectParams[f.State] = true
+			}
+		} else {
+			g.writef("// Loaded on the server - replaces the useEffect fetch at line %d\n", f.LineNumber)
+		}
+		g.writeIndent()
+		g.writef("%s, err := %s(%s)\n", toCamelCase(f.State), loaderName(comp, f), strings.Join(names, ", "))
+		if f.Loading != "" {
+			g.writeIndent()
+			g.writef("var %s %s // the data is in before render\n", toCamelCase(f.Loading), stateType(comp, f.Loading))
+		}
+		if f.Error != "" {
+			g.writeIndent()
+			g.writef("var %s %s\n", toCamelCase(f.Error), stateType(comp, f.Error))
+		}
+		for _, state := range []string{f.State, f.Loading, f.Error} {
+			if state != "" && !read(state) {
+				g.writeIndent()
+				g.writef("_ = %s\n", toCamelCase(state))
+			}
+		}
+		g.writeIndent()
+		g.writeln("if err != nil {")
+		g.writeIndent()
+		if f.Error != "" {
+			g.writef("\t%s = err.Error()\n", toCamelCase(f.Error))
+		} else if f.Await {
+			g.writeln("\t// TODO: render an error state; a failed request showed the error page")
+		} else {
+			g.writeln("\t// TODO: render an error state; the effect ignored failures")
+		}
+		g.writeIndent()
+		g.writeln("}")
+	}
+	g.writeln("")
+}
+
+// readsState reports whether the generated render code reads a state
+// variable: the markup outside event handlers, or a derived variable
+func readsState(comp *parser.Component, state string) bool {
+	for _, dv := range comp.DerivedVars {
+		if dv.SourceVar == state {
+			return true
+		}
+	}
+	ref := regexp.MustCompile(`\b` + regexp.QuoteMeta(state) + `\b`)
+	var reads func(node parser.Node) bool
+	reads = func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.Expression:
+			return ref.MatchString(n.Raw)
+		case *parser.Element:
+			for _, attr := range n.Attributes {
+				if attr.EventHandler == nil && (ref.MatchString(attr.Expression.Raw) || ref.MatchString(attr.SpreadExpr)) {
+					return true
+				}
+			}
+			for _, child := range n.Children {
+				if reads(child) {
+					return true
+				}
+			}
+		case *parser.Fragment:
+			for _, child := range n.Children {
+				if reads(child) {
+					return true
+				}
+			}
+		case *parser.MapExpr:
+			return ref.MatchString(n.Source()) || reads(n.Body)
+		case *parser.Conditional:
+			return ref.MatchString(n.Condition) || reads(n.Consequent)
+		case *parser.Ternary:
+			return ref.MatchString(n.Condition) || reads(n.Consequent) || reads(n.Alternate)
+		}
+		return false
+	}
+	return reads(comp.Body)
+}
+
+// writeLoaders writes a data-loading function per fetching effect of the
+// component. Requests to other hosts become http.Get calls; requests to
+// the app's own API are left as a TODO for the repository behind them.
+func (g *Generator) writeLoaders(comp *parser.Component) {
+	for _, f := range comp.Fetches {
+		args := g.loaderArgs(f)
+		typ := g.fetchType(comp, f)
+		name := loaderName(comp, f)
+		replaced := "the useEffect"
+		if f.Await {
+			replaced = "the request awaited"
+		}
+
+		g.writeln("")
+		g.writef("// %s loads %s for %s, replacing %s at line %d:\n//\n", name, f.State, comp.Name, replaced, f.LineNumber)
+		g.writef("//\t%s(%s)\n", f.Client, f.URL)
+		g.writef("func %s(%s) (%s, error) {\n", name, joinParams(args), typ)
+
+		if !fetchesRemote(f) {
+			g.writeln("\t// TODO: load the data from the repository behind this endpoint")
+			g.writef("\treturn %s, nil\n}\n", zeroValue(typ))
+			continue
+		}
+
+		url := g.translateExprValue(f.URL)
+		if e, err := parser.ParseJSExpr(f.URL); err == nil {
+			url = g.translateStringOperand(e, f.URL)
+		}
+		g.writef("\tresp, err := http.Get(%s)\n", url)
+		g.writef("\tif err != nil {\n\t\treturn %s, err\n\t}\n", zeroValue(typ))
+		g.writeln("\tdefer resp.Body.Close()")
+		g.writef("\tif resp.StatusCode != http.StatusOK {\n\t\treturn %s, fmt.Errorf(\"GET %%s: %%s\", resp.Request.URL, resp.Status)\n\t}\n", zeroValue(typ))
+
+		// The effect may store a field of the response: setUsers(data.users)
+		field, ok := responseField(f)
+		switch {
+		case ok && field == "":
+			g.writef("\tvar %s %s\n", toCamelCase(f.State), typ)
+			g.writef("\tif err := json.NewDecoder(resp.Body).Decode(&%s); err != nil {\n", toCamelCase(f.State))
+			g.writef("\t\treturn %s, err\n\t}\n", zeroValue(typ))
+			g.writef("\treturn %s, nil\n}\n", toCamelCase(f.State))
+		case ok && !strings.Contains(field, "."):
+			g.writef("\tvar body struct {\n\t\t%s %s `json:%q`\n\t}\n", exportName(field), typ, field)
+			g.writeln("\tif err := json.NewDecoder(resp.Body).Decode(&body); err != nil {")
+			g.writef("\t\treturn %s, err\n\t}\n", zeroValue(typ))
+			g.writef("\treturn body.%s, nil\n}\n", exportName(field))
+		default:
+			g.writef("\tvar %s %s // TODO: the effect stored %s\n", toCamelCase(f.State), typ, f.Value)
+			g.writef("\tif err := json.NewDecoder(resp.Body).Decode(&%s); err != nil {\n", toCamelCase(f.State))
+			g.writef("\t\treturn %s, err\n\t}\n", zeroValue(typ))
+			g.writef("\treturn %s, nil\n}\n", toCamelCase(f.State))
+		}
+	}
+}
+
+// responseField returns the path within the decoded response body that
+// the effect stores: "users" for setUsers(data.users), "" for the body
+// itself. ok is false if the stored value is computed.
+func responseField(f parser.DataFetch) (string, bool) {
+	if f.Value == "" {
+		return "", true
+	}
+	e, err := parser.ParseJSExpr(f.Value)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.Split(parser.MemberPath(e), ".")
+	if len(parts) < 2 {
+		return "", false
+	}
+	parts = parts[1:]
+	if strings.HasPrefix(f.Client, "axios") && parts[0] == "data" {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, "."), true
+}
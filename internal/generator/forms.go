@@ -0,0 +1,401 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// formComponents returns the components with a react-hook-form or Formik
+// form
+func formComponents(file *parser.File) []*parser.Component {
+	var comps []*parser.Component
+	for i := range file.Components {
+		if file.Components[i].Form != nil {
+			comps = append(comps, &file.Components[i])
+		}
+	}
+	return comps
+}
+
+// formType names the request struct of a component's form: SignupForm
+func formType(comp string) string {
+	return exportName(comp) + "Form"
+}
+
+func decodeFuncName(comp string) string {
+	return "decode" + formType(comp)
+}
+
+// formFieldName returns the struct field holding a form field:
+// email → Email, address.city → AddressCity
+func formFieldName(name string) string {
+	return exportName(toCamelCase(strings.NewReplacer(".", "-", "_", "-").Replace(name)))
+}
+
+// formSchema returns the rules the register options of a form give as a
+// schema named after the form, or nil if they give none
+func formSchema(comp *parser.Component) *parser.ValidationSchema {
+	schema := &parser.ValidationSchema{
+		Name:       strings.ToLower(comp.Name[:1]) + comp.Name[1:] + "Form",
+		Library:    comp.Form.Library,
+		LineNumber: comp.Form.LineNumber,
+	}
+	for _, f := range comp.Form.Fields {
+		if len(fieldCases(schema.Name, &f)) > 0 || len(f.Unsupported) > 0 {
+			schema.Fields = append(schema.Fields, f)
+		}
+	}
+	if len(schema.Fields) == 0 {
+		return nil
+	}
+	return schema
+}
+
+// formGoType returns the Go type of a form field
+func formGoType(f parser.SchemaField) string {
+	switch f.Type {
+	case "number":
+		for _, r := range f.Rules {
+			if strings.Contains(r.Arg, ".") {
+				return "float64"
+			}
+		}
+		return "int"
+	case "boolean":
+		return "bool"
+	}
+	return "string"
+}
+
+// fieldGoType returns the Go type of a field of form: that the form's
+// schema gives it when registering it leaves it a string, so that
+// z.number().int() makes an int
+func (g *Generator) fieldGoType(form *parser.FormSpec, f parser.SchemaField) string {
+	if schema := g.schemas[form.Schema]; schema != nil && f.Type == "string" {
+		for _, sf := range schema.Fields {
+			if sf.Name == f.Name {
+				return formGoType(sf)
+			}
+		}
+	}
+	return formGoType(f)
+}
+
+// writeForm writes the request struct of a component's form and the
+// decoder reading a submission into it. The decoder checks the rules of
+// the register options and the form's schema, if it is in the file.
+func (g *Generator) writeForm(comp *parser.Component) {
+	form := comp.Form
+	typeName := formType(comp.Name)
+	width := 0
+	for _, f := range form.Fields {
+		width = max(width, len(formFieldName(f.Name)))
+	}
+
+	g.writef("// %s holds the values of the %s form (%s, line %d)\n", typeName, comp.Name, form.Library, form.LineNumber)
+	g.writef("type %s struct {\n", typeName)
+	for _, f := range form.Fields {
+		g.writef("\t%-*s %s\n", width, formFieldName(f.Name), g.fieldGoType(form, f))
+	}
+	g.writeln("}")
+	g.writeln("")
+
+	fn := decodeFuncName(comp.Name)
+	g.writef("// %s reads a %s submission into %s. It returns an\n", fn, comp.Name, typeName)
+	g.writeln("// error message per invalid field; an empty map means valid.")
+	g.writef("func %s(r *http.Request) (%s, map[string]string) {\n", fn, typeName)
+	g.writef("\tvar form %s\n", typeName)
+	g.writeln("\tif err := r.ParseForm(); err != nil {")
+	g.writeln("\t\treturn form, map[string]string{\"\": err.Error()}")
+	g.writeln("\t}")
+
+	var checks []string
+	if schema := formSchema(comp); schema != nil {
+		checks = append(checks, validateFuncName(schema.Name))
+	}
+	if _, ok := g.schemas[form.Schema]; ok {
+		checks = append(checks, validateFuncName(form.Schema))
+	}
+	switch len(checks) {
+	case 0:
+		g.writeln("\terrs := map[string]string{}")
+	default:
+		g.writef("\terrs := %s(r.PostForm)\n", checks[0])
+		for _, check := range checks[1:] {
+			g.writef("\tfor field, msg := range %s(r.PostForm) {\n", check)
+			g.writeln("\t\terrs[field] = msg")
+			g.writeln("\t}")
+		}
+	}
+	if form.Schema != "" && g.schemas[form.Schema] == nil {
+		g.writef("\t// TODO: validate against %s, declared in another file\n", form.Schema)
+	}
+
+	g.writeln("")
+	for _, f := range form.Fields {
+		field := "form." + formFieldName(f.Name)
+		switch g.fieldGoType(form, f) {
+		case "int":
+			g.writef("\t%s, _ = strconv.Atoi(r.PostForm.Get(%q))\n", field, f.Name)
+		case "float64":
+			g.writef("\t%s, _ = strconv.ParseFloat(r.PostForm.Get(%q), 64)\n", field, f.Name)
+		case "bool":
+			g.writef("\t%s = r.PostForm.Has(%q)\n", field, f.Name)
+		default:
+			g.writef("\t%s = r.PostForm.Get(%q)\n", field, f.Name)
+		}
+	}
+	g.writeln("\treturn form, errs")
+	g.writeln("}")
+	g.writeln("")
+}
+
+// formEndpoint returns the endpoint the current component's form posts
+// to, recording its handler with the form's decoder
+func (g *Generator) formEndpoint(handler *parser.EventHandler, tag string) string {
+	endpoint := g.handlerEndpoint("POST", "submit", handler, tag)
+	for i := range g.handlers {
+		if g.handlers[i].Method == "POST" && g.handlers[i].Endpoint == endpoint {
+			g.handlers[i].Form = decodeFuncName(g.component)
+			g.handlers[i].Submit = g.form.Submit
+		}
+	}
+	return endpoint
+}
+
+// formErrorRef matches a react-hook-form field error: errors.email,
+// errors.email.message, formState.errors.email?.message
+var formErrorRef = regexp.MustCompile(`^(?:formState\.)?errors\.(\w+)(?:\??\.message)?$`)
+
+// formError translates a field error of the current react-hook-form form
+// to the message the submission left for it: formErrors["email"]
+func (g *Generator) formError(raw string) (string, bool) {
+	if g.form == nil || g.form.Library != "react-hook-form" {
+		return "", false
+	}
+	m := formErrorRef.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s[%q]", formErrorsParam, m[1]), true
+}
+
+// generateFormik renders the Formik components of the current form:
+// <Formik> renders its children, or what its render function returns,
+// <Form> posts the fields to the form's endpoint, <Field> is the control
+// it names and <ErrorMessage> shows the field's error. It reports false
+// for other elements.
+func (g *Generator) generateFormik(elem *parser.Element, builder string) bool {
+	switch elem.Tag {
+	case "Formik":
+		var children []parser.Node
+		for _, child := range elem.Children {
+			switch n := child.(type) {
+			case *parser.Text:
+				if strings.TrimSpace(n.Content) == "" {
+					continue
+				}
+			case *parser.Expression:
+				if node := renderFunction(n.Raw, n.LineNumber); node != nil {
+					child = node
+				}
+			}
+			children = append(children, child)
+		}
+		if len(children) == 1 {
+			g.generateNode(children[0], builder)
+		} else {
+			g.generateFragment(&parser.Fragment{Children: children, LineNumber: elem.LineNumber}, builder)
+		}
+	case "Form":
+		form := *elem
+		form.Tag = "form"
+		form.Attributes = append(append([]parser.Attribute(nil), elem.Attributes...), parser.Attribute{
+			Name:         "onSubmit",
+			EventHandler: &parser.EventHandler{EventType: "onSubmit", HandlerBody: "handleSubmit", LineNumber: elem.LineNumber},
+		})
+		g.generateElement(&form, builder)
+	case "Field":
+		field := *elem
+		field.Tag = "input"
+		field.Attributes = nil
+		for _, attr := range elem.Attributes {
+			switch attr.Name {
+			case "as":
+				if attr.Value != "" {
+					field.Tag = attr.Value
+				}
+			case "component":
+			default:
+				field.Attributes = append(field.Attributes, attr)
+			}
+		}
+		g.generateElement(&field, builder)
+	case "ErrorMessage":
+		name, tag := "", "p"
+		for _, attr := range elem.Attributes {
+			switch attr.Name {
+			case "name":
+				name = attr.Value
+			case "component":
+				if attr.Value != "" {
+					tag = attr.Value
+				}
+			}
+		}
+		g.writef("mi.If(%s[%q] != \"\", func(b *mi.Builder) mi.Node {\n", formErrorsParam, name)
+		g.writeIndent()
+		open, hasArg := g.elementOpen("b", tag)
+		if hasArg {
+			open += ", "
+		}
+		g.writef("\treturn %smi.Class(\"field-error\"), %s[%q])\n", open, formErrorsParam, name)
+		g.writeIndent()
+		g.write("})")
+	default:
+		return false
+	}
+	return true
+}
+
+// antdFormProps are the <Form> props the posted form has no use for
+var antdFormProps = map[string]bool{
+	"form": true, "layout": true, "initialValues": true, "onFinish": true, "onFinishFailed": true,
+	"onValuesChange": true, "labelCol": true, "wrapperCol": true, "requiredMark": true, "validateTrigger": true,
+}
+
+// generateAntdForm renders the Ant Design components of the current form:
+// <Form> posts the fields to the form's endpoint, and <Form.Item> labels
+// the control it wraps, names it after the item and shows the field's
+// error. It reports false for other elements.
+func (g *Generator) generateAntdForm(elem *parser.Element, builder string) bool {
+	switch elem.Tag {
+	case "Form":
+		form := *elem
+		form.Tag = "form"
+		form.Attributes = nil
+		for _, attr := range elem.Attributes {
+			if !antdFormProps[attr.Name] {
+				form.Attributes = append(form.Attributes, attr)
+			}
+		}
+		form.Attributes = append(form.Attributes, parser.Attribute{
+			Name:         "onSubmit",
+			EventHandler: &parser.EventHandler{EventType: "onSubmit", HandlerBody: "handleSubmit", LineNumber: elem.LineNumber},
+		})
+		g.generateElement(&form, builder)
+	case "Form.Item":
+		var name string
+		var label parser.Node
+		for _, attr := range elem.Attributes {
+			switch attr.Name {
+			case "name":
+				if v, ok := literalAttrValue(attr); ok {
+					name = v
+				} else if e, err := parser.ParseJSExpr(attr.Expression.Raw); err == nil {
+					if arr, ok := parser.Unparen(e).(*parser.JSArray); ok {
+						name = strings.Join(literalStrings(arr), ".")
+					}
+				}
+			case "label":
+				if v, ok := literalAttrValue(attr); ok {
+					label = &parser.Text{Content: v, LineNumber: elem.LineNumber}
+				} else {
+					label = &parser.Expression{Raw: attr.Expression.Raw, LineNumber: elem.LineNumber}
+				}
+			}
+		}
+
+		// The item names its control: <Form.Item name="email"><Input /></Form.Item>
+		var children []parser.Node
+		named := false
+		for _, child := range elem.Children {
+			if text, ok := child.(*parser.Text); ok && strings.TrimSpace(text.Content) == "" {
+				continue
+			}
+			if el, ok := child.(*parser.Element); ok && name != "" && !named && !hasAttribute(el, "name") {
+				control := *el
+				control.Attributes = append([]parser.Attribute{{Name: "name", Value: name}}, el.Attributes...)
+				child, named = &control, true
+			}
+			children = append(children, child)
+		}
+		if label != nil {
+			children = []parser.Node{&parser.Element{
+				Tag:        "label",
+				Attributes: []parser.Attribute{{Name: "className", Value: "block text-sm font-medium"}},
+				Children:   append([]parser.Node{label}, children...),
+				LineNumber: elem.LineNumber,
+			}}
+		}
+		item := &parser.Element{
+			Tag:        "div",
+			Attributes: []parser.Attribute{{Name: "className", Value: "form-item"}},
+			Children:   children,
+			LineNumber: elem.LineNumber,
+		}
+		if named && g.formFields[name] {
+			g.generateFieldWithError(item, builder, name)
+		} else {
+			g.generateElement(item, builder)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// literalStrings returns the string literals of arr
+func literalStrings(arr *parser.JSArray) []string {
+	var values []string
+	for _, el := range arr.Elements {
+		if lit, ok := parser.Unparen(el).(*parser.JSLiteral); ok && lit.Kind == "string" {
+			values = append(values, lit.Value)
+		}
+	}
+	return values
+}
+
+// renderFunction returns the markup a render function child on line
+// returns: {({ errors }) => (<Form>...</Form>)}
+func renderFunction(raw string, line int) parser.Node {
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return nil
+	}
+	fn, ok := parser.Unparen(e).(*parser.JSArrow)
+	if !ok || fn.Body == nil {
+		return nil
+	}
+	jsx, ok := parser.Unparen(fn.Body).(*parser.JSXNode)
+	if !ok {
+		return nil
+	}
+	// Pad the markup to its line, so that its elements keep their lines
+	pad := strings.Repeat("\n", max(line-1, 0)+strings.Count(raw[:jsx.Span().Start], "\n"))
+	return parser.NewParser(parser.NewLexer(pad + jsx.Raw).Tokenize()).ParseJSX()
+}
+
+// formBinding reports whether a form control attribute only binds the
+// control to Formik's state, which the posted field replaces:
+// value={formik.values.email}, onChange={formik.handleChange}
+func (g *Generator) formBinding(attr *parser.Attribute) bool {
+	if g.form == nil || g.form.Library != "formik" {
+		return false
+	}
+	raw := attr.Expression.Raw
+	if attr.EventHandler != nil {
+		raw = attr.EventHandler.HandlerBody
+	}
+	raw = strings.TrimSpace(raw)
+	switch attr.Name {
+	case "value", "checked":
+		return strings.Contains(raw, "values.")
+	case "onChange", "onBlur":
+		return strings.HasSuffix(raw, "handleChange") || strings.HasSuffix(raw, "handleBlur")
+	}
+	return false
+}
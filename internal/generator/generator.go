@@ -1,11 +1,13 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/ha1tch/reminty/internal/assets"
 	"github.com/ha1tch/reminty/internal/parser"
 )
 
@@ -15,52 +17,239 @@ type Generator struct {
 	output         strings.Builder
 	suggestions    []string
 	warnings       []string
-	usesFragment   bool
-	usesEach       bool
-	usesIf         bool
-	usesIfElse     bool
 	inMapBody      bool
 	inIfElseReturn bool  // true when generating content that will be returned from IfElse
 	currentItemVar string
 	currentParams  map[string]bool   // tracks current function's parameter names
 	objectParams   map[string]bool   // tracks which params are object/map types
+	stats          []ComponentStat   // per-component statistics from the last run
+	packs          []*MappingPack    // enabled design-system mapping packs
+	mapped         map[string]ComponentMapping // imported components covered by packs
+	tailwind       bool                        // translate inline styles to Tailwind classes
+	cssModules     map[string]*assets.CSSModule // CSS Modules keyed by local import name
+	cssIssues      []CSSModuleIssue             // unresolved CSS Modules accesses
+	currentLine    int                          // source line of the element being generated
+	styled         []*styledComponent           // compiled styled components
+	assets         map[string]string            // imported static assets → public paths
+	assetIssues    []AssetIssue                 // unresolved dynamic asset paths
+	realtime       []SSEStream                  // realtime state served over SSE
+	realtimeUsed   map[string]bool              // streams already attached to an element
+	tablesUsed     map[int]bool                 // table library tables already generated, by line
+	schemas        map[string]*parser.ValidationSchema // zod/yup schemas keyed by variable name
+	formFields     map[string]bool                     // schema fields of the current component's form
+	translator     string                              // i18next t function of the current component
+	children       string                              // children parameter of the current component, if any
+	component      string                              // name of the component being generated
+	componentArgs  []Param                             // parameters of the component being generated
+	handlers       []Handler                           // htmx endpoints replacing event handlers
+	controlled     map[string]bool                     // controlled fields of the form being generated
+	hooks          map[string]*parser.CustomHook       // the file's custom hooks keyed by name
+	contexts       map[string]*contextInfo             // the file's React contexts keyed by name
+	contextNeeds   map[string][]string                 // contexts each component is passed
+	contextUses    map[string][]parser.ContextUse      // useContext calls of each component
+	provided       map[string]string                   // context values provided around the current element
+	messages       []Message                           // translatable strings found in t() and <Trans>
+	calls          []CallSite                          // component calls, for cross-file signature checks
+	unmapped       []Unmapped                          // tags and attributes without a minty mapping
+	pkg            string                              // package clause of the generated file
+	tagMethods     map[string]string                   // configured tag → builder method overrides
+	attrOptions    map[string]string                   // configured attribute → option function overrides
+	nextjs         bool                                // follow the Next.js conventions
+	pageLoaders    []PageLoader                        // Go loaders replacing getServerSideProps and getStaticProps
+	slices         map[string]*parser.ReduxSlice       // the file's Redux slices keyed by name
+	stores         map[string]*parser.ZustandStore     // the file's Zustand stores keyed by hook
+	dispatches     []parser.Dispatch                   // Redux actions the current component dispatches
+	form           *parser.FormSpec                    // react-hook-form or Formik form of the current component
+	imported       map[string]ImportedComponent        // components imported from other converted files
+	items          map[string]map[string]*itemType     // element structs of mapped collections, per component and collection
+	reserved       map[string]bool                     // names the other files of the package declare
+	currentComp    *parser.Component                   // the component being generated
+	currentItem    *itemType                           // struct of the item being mapped, if typed
+	serverActions  map[string]bool                     // the file's server actions by name
+	renderProps    map[string]map[string]string        // Go types of render props, per component and prop
+	local          map[string]bool                     // the file's components by name
+	keyIDs         bool                                // keep the keys of swapped list items as ids
+	preserve       bool                                // keep the source whitespace of <pre> and <textarea> text
+	stripTests     bool                                // leave data-testid and data-cy out
+	alpine         bool                                // keep show/hide state in the browser with Alpine.js
+	alpineFor      map[string]bool                     // state kept by Alpine in htmx mode too
+	alpineState    map[string]*parser.StateVariable    // the current component's state kept by Alpine
+	alpineRoot     parser.Node                         // the element that still has to carry the x-data
+	currentMap     *parser.MapExpr                     // the innermost map being generated
+	keyedID        string                              // id prefix of the list item whose attributes are being generated
+	refs           map[string]*domRef                  // the current component's useRef refs attached to elements
+	ids            map[string]bool                     // element ids generated in the file
+	templates      parsedTemplates                     // user fragments reshaping the generated code
+	templateErr    error                               // first error executing them
+	helpers        map[string]*helperFunc              // the file's plain functions by name
+	constants      map[string]*constant                // the file's constants by JavaScript name
+	localParams    map[string][]Param                  // parameters of the file's components by name
+	currentElem    string                              // Go type of the item being mapped when it is not a struct
+	derived        map[string]goExpr                   // derived variables computed in place, with their Go types
+	mapLocals      jsScope                             // keys, values and indexes the enclosing maps over objects and counts bind
 }
 
+// ComponentStat summarises the generated output for one component
+type ComponentStat struct {
+	Name   string
+	TODOs  int     // number of TODO markers left in the generated code
+	Params []Param // parameters of the generated function
+	Code   string  // the generated function, with its doc comment
+}
+
+// Param is one parameter of a generated component function
+type Param struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Prop    string `json:"prop,omitempty"`    // JSX prop the parameter is passed as
+	Session bool   `json:"session,omitempty"` // state persisted in the visitor's session
+}
+
+// todoMarker matches TODO markers in generated code (but not "TODOs")
+var todoMarker = regexp.MustCompile(`\bTODO\b`)
+
 // NewGenerator creates a new code generator
 func NewGenerator() *Generator {
 	return &Generator{
 		indent: 0,
+		pkg:    "main",
 	}
 }
 
 // Generate produces Go code from a parse result
 func (g *Generator) Generate(result *parser.ParseResult) string {
+	out, _ := g.GenerateContext(context.Background(), result)
+	return out
+}
+
+// GenerateContext is like Generate but checks ctx before each component.
+// On cancellation it returns the partial output and the context error.
+func (g *Generator) GenerateContext(ctx context.Context, result *parser.ParseResult) (string, error) {
 	g.output.Reset()
 
 	// Write package declaration
-	g.writeln("package main")
+	g.writef("package %s\n", g.pkg)
 	g.writeln("")
 	
-	// Add warning
-	g.writeln("// Generated by reminty - review TODOs before use")
+	// Add warning, or the header the project templates
+	g.templateErr = nil
+	if g.templates.header != nil {
+		header := HeaderData{Package: g.pkg}
+		for _, comp := range result.File.Components {
+			header.Components = append(header.Components, comp.Name)
+		}
+		g.writeTemplate(g.templates.header, header)
+	} else {
+		g.writeln("// Generated by reminty - review TODOs before use")
+	}
 	g.writeln("")
 
-	// Write imports
-	g.writeln("import (")
-	g.writeln("\t\"fmt\"")
-	g.writeln("")
-	g.writeln("\tmi \"github.com/ha1tch/minty\"")
-	g.writeln(")")
-	g.writeln("")
-	g.writeln("var _ = fmt.Sprint // silence unused import")
-	g.writeln("")
+	// The imports go here once the code using them is generated
+	header := g.output.Len()
+	withImports := func() string {
+		out := g.output.String()
+		if imports := fileImports(out[header:]); imports != "" {
+			return out[:header] + imports + "\n" + out[header:]
+		}
+		return out
+	}
 
-	// Generate components
+	g.resolveContexts(result.File)
+	g.writeContextTypes(result.File)
+	g.resolveItems(result.File)
+	g.writeItemTypes()
+	g.resolveRenderProps(result.File)
+	g.local = map[string]bool{}
 	for _, comp := range result.File.Components {
+		g.local[comp.Name] = true
+	}
+
+	// Bind imported design-system components to the enabled mapping packs
+	g.resolveMappings(result.File.Imports)
+	g.resolveStyled(result.File.StyledComponents)
+	g.schemas = map[string]*parser.ValidationSchema{}
+	for i := range result.File.Schemas {
+		g.schemas[result.File.Schemas[i].Name] = &result.File.Schemas[i]
+	}
+	g.hooks = map[string]*parser.CustomHook{}
+	for i := range result.File.CustomHooks {
+		g.hooks[result.File.CustomHooks[i].Name] = &result.File.CustomHooks[i]
+	}
+	g.slices = map[string]*parser.ReduxSlice{}
+	for i := range result.File.Slices {
+		g.slices[result.File.Slices[i].Name] = &result.File.Slices[i]
+	}
+	g.stores = map[string]*parser.ZustandStore{}
+	for i := range result.File.Stores {
+		g.stores[result.File.Stores[i].Hook] = &result.File.Stores[i]
+	}
+	g.resolveHelpers(result.File)
+	g.resolveConstants(result.File)
+	g.localParams = map[string][]Param{}
+	for i := range result.File.Components {
+		g.localParams[result.File.Components[i].Name] = g.componentParams(&result.File.Components[i])
+	}
+
+	// Generate components
+	g.stats = nil
+	g.cssIssues = nil
+	g.assetIssues = nil
+	g.realtimeUsed = nil
+	g.tablesUsed = nil
+	g.messages = nil
+	g.calls = nil
+	g.unmapped = nil
+	g.handlers = nil
+
+	// A Storybook file becomes a golden file test of its stories
+	if result.File.Stories != nil {
+		g.writeStories(result.File.Stories)
+		return withImports(), nil
+	}
+
+	// A React Native file only gets a report of what it would take to
+	// bring it to the web
+	if result.File.Native != nil {
+		g.writeNativeReport(result.File)
+		return withImports(), nil
+	}
+
+	g.writeConstants(result.File)
+
+	g.ids = nil
+	g.serverActions = map[string]bool{}
+	for _, action := range result.File.ServerActions {
+		g.serverActions[action.Name] = true
+	}
+	rendering := ""
+	for _, comp := range renderingOrder(result.File.Components) {
+		if err := ctx.Err(); err != nil {
+			return withImports(), err
+		}
+		if comp.Rendering != rendering {
+			rendering = comp.Rendering
+			g.writeRenderingBanner(rendering)
+		}
+		start := g.output.Len()
 		g.generateComponent(&comp)
+		g.stats = append(g.stats, ComponentStat{
+			Name:   comp.Name,
+			Params: g.componentParams(&comp),
+			TODOs: len(todoMarker.FindAllStringIndex(g.output.String()[start:], -1)),
+			Code:   g.output.String()[start:],
+		})
 		g.writeln("")
 	}
 
+	g.writeServerActions(result.File)
+	g.writeDataLoaders(result.File)
+	g.writeSlices(result.File)
+	g.writeStores(result.File)
+	g.writeHooks(result.File)
+	g.writeHelpers(result.File)
+	g.writeStyledNotes()
+	g.writeValidation(result.File)
+
 	// Add suggestions as comments at the end
 	if len(result.Suggestions) > 0 {
 		g.writeln("// =============================================================================")
@@ -73,7 +262,12 @@ func (g *Generator) Generate(result *parser.ParseResult) string {
 		}
 	}
 
-	return g.output.String()
+	return withImports(), g.templateErr
+}
+
+// Stats returns per-component statistics from the last call to Generate
+func (g *Generator) Stats() []ComponentStat {
+	return g.stats
 }
 
 // GenerateNode generates Go code for a single node (for testing)
@@ -88,10 +282,13 @@ func (g *Generator) generateComponent(comp *parser.Component) {
 	g.currentParams = make(map[string]bool)
 	g.objectParams = make(map[string]bool)
 	for _, prop := range comp.Props {
+		if prop.Alias != "" {
+			prop.Name = prop.Alias
+		}
 		g.currentParams[prop.Name] = true
 		g.currentParams[toCamelCase(prop.Name)] = true
 		// Track object-like props
-		if isObjectLikeName(strings.ToLower(prop.Name)) {
+		if propObjectLike(prop, g.objectLike(prop.Name)) {
 			g.objectParams[prop.Name] = true
 			g.objectParams[toCamelCase(prop.Name)] = true
 		}
@@ -106,55 +303,116 @@ func (g *Generator) generateComponent(comp *parser.Component) {
 		g.currentParams[dv.Name] = true
 		g.currentParams[toCamelCase(dv.Name)] = true
 	}
-	defer func() { g.currentParams = nil; g.objectParams = nil }()
+	for _, p := range g.contextParams(comp.Name) {
+		g.currentParams[p.Name] = true
+	}
+	for _, p := range g.storeParams(comp) {
+		g.currentParams[p.Name] = true
+	}
+	g.dispatches = comp.Dispatches
+	g.currentComp = comp
+	g.form = comp.Form
+	g.formFields = g.schemaFields(comp)
+	g.translator = comp.Translator
+	if children, ok := childrenParam(comp); ok {
+		g.children = children.Name
+	}
+	g.component, g.componentArgs = comp.Name, g.componentParams(comp)
+	g.resolveRefs(comp)
+	g.alpineState, g.alpineRoot = g.alpineStates(comp), comp.Body
+	defer func() { g.alpineState = nil; g.alpineRoot = nil; g.refs = nil; g.currentParams = nil; g.objectParams = nil; g.formFields = nil; g.translator = ""; g.children = ""; g.component = ""; g.componentArgs = nil; g.dispatches = nil; g.form = nil; g.currentComp = nil; g.derived = nil }()
 
 	// Convert props to Go function parameters
-	params := g.generateParams(comp.Props)
-	
 	// Add state variables as additional parameters
-	stateParams := g.generateStateParams(comp.StateVars)
-	if params != "" && stateParams != "" {
-		params = params + ", " + stateParams
-	} else if stateParams != "" {
-		params = stateParams
-	}
+	params := joinParams(g.componentParams(comp))
 
 	// Write function signature
-	g.writef("// %s component\n", comp.Name)
+	g.writeComponentDoc(comp, g.componentArgs)
+	g.writeRenderingNote(comp)
+	g.writeWrapperNote(comp)
+	g.writePortalNote(comp)
+	g.writeRefNote()
 
 	// Add setter notes as comments (for HTMX conversion guidance)
 	if len(comp.StateVars) > 0 {
+		if comp.Doc != "" || comp.Rendering != "" || len(comp.Wrappers) > 0 || comp.Portal != nil || len(g.refs) > 0 {
+			g.writeln("//")
+		}
 		g.writeln("// State converted to parameters. Original setters:")
+		fetched := fetchedState(comp)
 		for _, sv := range comp.StateVars {
+			if fetched[sv.Name] {
+				g.writef("//   %s → loaded before render (useEffect fetch)\n", sv.Setter)
+				continue
+			}
+			if g.alpineState[sv.Name] != nil {
+				g.writef("//   %s → kept in the browser by Alpine.js (x-data)\n", sv.Setter)
+				continue
+			}
+			if sv.Persistent {
+				g.writef("//   %s → persisted in session: %s\n", sv.Setter, SessionSet(toCamelCase(sv.Name)))
+				continue
+			}
 			g.writef("//   %s → use HTMX to update %s parameter\n", sv.Setter, sv.Name)
 		}
 	}
+	if len(comp.StoreReads) > 0 {
+		g.writeln("// Store values converted to parameters:")
+		for _, read := range comp.StoreReads {
+			hook := "useSelector"
+			if read.Store != "" {
+				hook = read.Store
+			}
+			g.writef("//   %s ← %s(%s)\n", toCamelCase(read.Name), hook, truncateExpr(read.Selector, 50))
+		}
+	}
 
-	g.writef("func %s(%s) mi.H {\n", comp.Name, params)
-	g.indent++
+	prelude := func() {
+		g.generateFetches(comp, func(state string) bool { return readsState(comp, state) })
+		g.generateHookCalls(comp.HookCalls, func(name string) bool { return readsState(comp, name) })
+		g.generateContextLocals(comp)
 
-	// Generate derived variable declarations
-	if len(comp.DerivedVars) > 0 {
-		g.writeln("// Derived state - compute before render")
-		for _, dv := range comp.DerivedVars {
-			g.generateDerivedVar(dv)
+		// Generate derived variable declarations
+		if len(comp.DerivedVars) > 0 {
+			g.writeln("// Derived state - compute before render")
+			for _, dv := range comp.DerivedVars {
+				g.generateDerivedVar(dv, true)
+			}
+			g.writeln("")
 		}
-		g.writeln("")
 	}
+	node := func() {
+		if comp.Body != nil {
+			g.generateNode(comp.Body, "b")
+		} else {
+			g.write("nil // TODO: Component body not parsed")
+		}
+	}
+
+	if g.templates.component != nil {
+		data := ComponentData{Name: comp.Name, Params: params, Args: g.componentArgs}
+		g.indent++
+		data.Prelude = g.capture(prelude)
+		g.indent++
+		data.Node = g.capture(node)
+		g.indent -= 2
+		g.writeTemplate(g.templates.component, data)
+		g.writeLoaders(comp)
+		return
+	}
+
+	g.writef("func %s(%s) mi.H {\n", comp.Name, params)
+	g.indent++
+	prelude()
 
 	g.writeIndent()
 	g.write("return func(b *mi.Builder) mi.Node {\n")
 	g.indent++
 
-	if comp.Body != nil {
-		g.writeIndent()
-		g.write("return ")
-		g.generateNode(comp.Body, "b")
-		g.write("\n")
-	} else {
-		g.writeIndent()
-		g.write("return nil // TODO: Component body not parsed\n")
-	}
+	g.writeIndent()
+	g.write("return ")
+	node()
+	g.write("\n")
 
 	g.indent--
 	g.writeIndent()
@@ -162,21 +420,60 @@ func (g *Generator) generateComponent(comp *parser.Component) {
 
 	g.indent--
 	g.write("}\n")
+
+	g.writeLoaders(comp)
 }
 
-// generateDerivedVar generates Go code for a derived variable
-func (g *Generator) generateDerivedVar(dv parser.DerivedVariable) {
+// generateDerivedVar generates Go code for a derived variable. inPlace
+// lets it compute chains and maps with the types they give, which a
+// variable whose type is declared elsewhere cannot.
+func (g *Generator) generateDerivedVar(dv parser.DerivedVariable, inPlace bool) {
 	goName := toCamelCase(dv.Name)
 	sourceVar := toCamelCase(dv.SourceVar)
+	if t := g.itemTypeOf(dv.Name); t != nil {
+		dv.ResultType = "[]" + t.name
+	} else if t := g.itemTypeOf(dv.SourceVar); t != nil && dv.Operation == "find" {
+		dv.ResultType = t.name
+	}
 	
 	// Check if source is known
 	sourceKnown := g.currentParams != nil && g.currentParams[dv.SourceVar]
 	
 	g.writeIndent()
+	if inPlace && g.generateDerivedChain(dv, goName, sourceKnown) {
+		return
+	}
+	if ops, length, ok := parser.DerivedChain(dv); ok && !length && ops[len(ops)-1].Method == "reduce" {
+		// Whatever comes before it, a reduce gives one value
+		dv.Operation, dv.ResultType = "reduce", "interface{}"
+	}
 	
 	switch dv.Operation {
+	case "value":
+		// Computed in place from the parameters it reads: doubled := count * 2
+		if e, src, ok := parser.DerivedInit(dv); ok && inPlace {
+			if x, ok := g.jsToGo(e, src, g.markupScope()); ok && x.typ != untypedNumber {
+				g.setDerived(dv.Name, goName, x.typ)
+				g.writef("%s := %s\n", goName, x.code)
+				return
+			}
+		}
+		g.writef("var %s interface{} // TODO: %s\n", goName, commentText(truncateExpr(dv.Expression, 60)))
+
 	case "filter":
-		if sourceKnown {
+		if item, cond, ok := g.derivedCondition(dv); sourceKnown && ok {
+			g.writef("var %s %s\n", goName, dv.ResultType)
+			g.writeIndent()
+			g.writef("for _, %s := range %s {\n", item, sourceVar)
+			g.writeIndent()
+			g.writef("\tif %s {\n", cond)
+			g.writeIndent()
+			g.writef("\t\t%s = append(%s, %s)\n", goName, goName, item)
+			g.writeIndent()
+			g.writeln("\t}")
+			g.writeIndent()
+			g.writeln("}")
+		} else if sourceKnown {
 			g.writef("var %s %s // TODO: implement filter\n", goName, dv.ResultType)
 			g.writeIndent()
 			g.writef("for _, item := range %s {\n", sourceVar)
@@ -212,7 +509,23 @@ func (g *Generator) generateDerivedVar(dv parser.DerivedVariable) {
 		}
 		
 	case "find":
-		if sourceKnown {
+		if item, cond, ok := g.derivedCondition(dv); sourceKnown && ok {
+			g.writef("var %s %s\n", goName, dv.ResultType)
+			g.writeIndent()
+			g.writef("for _, %s := range %s {\n", item, sourceVar)
+			g.writeIndent()
+			g.writef("\tif %s {\n", cond)
+			g.writeIndent()
+			g.writef("\t\t%s = %s\n", goName, item)
+			g.writeIndent()
+			g.writeln("\t\tbreak")
+			g.writeIndent()
+			g.writeln("\t}")
+			g.writeIndent()
+			g.writeln("}")
+			g.writeIndent()
+			g.writef("_ = %s\n", goName)
+		} else if sourceKnown {
 			g.writef("var %s %s // TODO: implement find\n", goName, dv.ResultType)
 			g.writeIndent()
 			g.writef("for _, item := range %s {\n", sourceVar)
@@ -241,7 +554,9 @@ func (g *Generator) generateDerivedVar(dv parser.DerivedVariable) {
 		}
 		
 	case "some":
-		if sourceKnown {
+		if item, cond, ok := g.derivedCondition(dv); sourceKnown && ok {
+			g.writeMatchLoop(goName, item, sourceVar, cond, false)
+		} else if sourceKnown {
 			g.writef("%s := false // TODO: implement some\n", goName)
 			g.writeIndent()
 			g.writef("for _, item := range %s {\n", sourceVar)
@@ -266,7 +581,9 @@ func (g *Generator) generateDerivedVar(dv parser.DerivedVariable) {
 		}
 		
 	case "every":
-		if sourceKnown {
+		if item, cond, ok := g.derivedCondition(dv); sourceKnown && ok {
+			g.writeMatchLoop(goName, item, sourceVar, negateCondition(cond), true)
+		} else if sourceKnown {
 			g.writef("%s := true // TODO: implement every\n", goName)
 			g.writeIndent()
 			g.writef("for _, item := range %s {\n", sourceVar)
@@ -302,7 +619,10 @@ func (g *Generator) generateDerivedVar(dv parser.DerivedVariable) {
 			g.writef("%s := make(%s, len(%s))\n", goName, dv.ResultType, sourceVar)
 			g.writeIndent()
 			g.writef("copy(%s, %s)\n", goName, sourceVar)
-			if dv.Operation == "sort" {
+			if less, ok := g.sortLess(dv, goName); ok && dv.Operation == "sort" {
+				g.writeIndent()
+				g.writef("sort.Slice(%s, func(i, j int) bool { return %s })\n", goName, less)
+			} else if dv.Operation == "sort" {
 				g.writeIndent()
 				g.writef("// TODO: sort.Slice(%s, func(i, j int) bool { ... })\n", goName)
 			}
@@ -315,6 +635,12 @@ func (g *Generator) generateDerivedVar(dv parser.DerivedVariable) {
 	}
 }
 
+// commentText makes source text safe to quote in a /* */ comment: a
+// regular expression such as /.*/ would end it
+func commentText(s string) string {
+	return strings.ReplaceAll(s, "*/", "* /")
+}
+
 // truncateExpr truncates an expression for display in comments
 func truncateExpr(expr string, maxLen int) string {
 	// Remove newlines
@@ -331,31 +657,27 @@ func truncateExpr(expr string, maxLen int) string {
 }
 
 // generateStateParams converts StateVariables to Go function parameters
-func (g *Generator) generateStateParams(stateVars []parser.StateVariable) string {
-	if len(stateVars) == 0 {
-		return ""
-	}
-	
-	var params []string
+func (g *Generator) generateStateParams(stateVars []parser.StateVariable) []Param {
+	var params []Param
 	for _, sv := range stateVars {
 		name := toCamelCase(sv.Name)
 		typ := sv.InitType
 		if typ == "" {
 			typ = "interface{}"
 		}
-		params = append(params, fmt.Sprintf("%s %s", name, typ))
+		params = append(params, Param{Name: name, Type: typ, Session: sv.Persistent})
 	}
-	return strings.Join(params, ", ")
+	return params
 }
 
-func (g *Generator) generateParams(props []parser.Prop) string {
-	if len(props) == 0 {
-		return ""
-	}
-
-	var params []string
+func (g *Generator) generateParams(props []parser.Prop) []Param {
+	var params []Param
 	for _, prop := range props {
 		name := toCamelCase(prop.Name)
+		if prop.Alias != "" {
+			// The body refers to a renamed prop by its local name
+			name = toCamelCase(prop.Alias)
+		}
 		
 		// Infer type from name or default value
 		typ := "string" // default to string for most props
@@ -375,25 +697,117 @@ func (g *Generator) generateParams(props []parser.Prop) string {
 		} else if strings.Contains(lowerName, "count") || strings.Contains(lowerName, "index") || strings.Contains(lowerName, "num") || strings.Contains(lowerName, "size") {
 			typ = "int"
 		} else if lowerName == "children" {
-			typ = "mi.H"
+			// Variadic, so it is added last by componentParams
+			continue
 		} else if isObjectLikeName(lowerName) {
 			// Singular object-like names suggest struct/map types
 			typ = "map[string]interface{}"
 		}
+		if g.nextjs && nextRouteProps[prop.Name] {
+			// The route and query parameters of an app router page
+			typ = "map[string]interface{}"
+		}
 		
+		// A declared type wins over the guesses
+		if declared, ok := propGoType(prop); ok {
+			if declared == "" {
+				// PropTypes.func: an event handler, converted to HTMX
+				continue
+			}
+			params = append(params, Param{Name: name, Type: declared, Prop: prop.Name})
+			continue
+		}
+
 		// Override with default value if present
 		if prop.DefaultValue != "" {
 			if prop.DefaultValue == "true" || prop.DefaultValue == "false" {
 				typ = "bool"
 			} else if _, err := fmt.Sscanf(prop.DefaultValue, "%d", new(int)); err == nil {
 				typ = "int"
+			} else if e, err := parser.ParseJSExpr(prop.DefaultValue); err == nil && isComposite(e) {
+				typ = literalParamType(e)
 			}
 		}
 		
-		params = append(params, fmt.Sprintf("%s %s", name, typ))
+		params = append(params, Param{Name: name, Type: typ, Prop: prop.Name})
 	}
 
-	return strings.Join(params, ", ")
+	return params
+}
+
+// componentParams returns the parameters of a component's generated
+// function: its props, its state, and the field errors of a validated form
+func (g *Generator) componentParams(comp *parser.Component) []Param {
+	var state []parser.StateVariable
+	fetched := fetchedState(comp)
+	alpine := g.alpineStates(comp)
+	for _, sv := range comp.StateVars {
+		if !fetched[sv.Name] && alpine[sv.Name] == nil {
+			state = append(state, sv)
+		}
+	}
+	params := append(g.generateParams(comp.Props), g.generateStateParams(state)...)
+	// A prop whose type is guessed takes the type of the literals it is
+	// compared with: size === 'lg' makes size a string, not an int
+	for _, prop := range comp.Props {
+		if _, declared := propGoType(prop); declared || prop.DefaultValue != "" {
+			continue
+		}
+		typ := comparedType(comp.Body, firstNonEmpty(prop.Alias, prop.Name))
+		for i, p := range params {
+			if typ != "" && p.Prop == prop.Name {
+				params[i].Type = typ
+			}
+		}
+	}
+	params = append(params, g.storeParams(comp)...)
+	if len(comp.Schemas) > 0 || comp.Form != nil {
+		params = append(params, Param{Name: formErrorsParam, Type: "map[string]string"})
+	}
+	if comp.Translator != "" {
+		params = append(params, Param{Name: localizerParam, Type: "*i18n.Localizer"})
+	}
+	params = append(params, g.contextParams(comp.Name)...)
+	if children, ok := childrenParam(comp); ok {
+		params = append(params, children)
+	}
+	// Render props take the arguments they are called with
+	for i, p := range params {
+		if typ, ok := g.renderProps[comp.Name][p.Prop]; ok {
+			params[i].Type = typ
+		}
+	}
+	// Collections mapped with a typed item: tasks []Task
+	for i, p := range params {
+		for root, t := range g.items[comp.Name] {
+			if toCamelCase(root) == p.Name {
+				params[i].Type = "[]" + t.name
+			}
+		}
+	}
+	// Objects iterated with Object.entries and the like, and the lengths
+	// of counts: stats map[string]interface{}, rating int
+	walkMaps(comp.Body, func(m *parser.MapExpr) {
+		root := collectionRoot(comp, m.Collection)
+		for i, p := range params {
+			switch {
+			case m.Object != "" && root != "" && toCamelCase(root) == p.Name && !strings.HasPrefix(p.Type, "map["):
+				params[i].Type = "map[string]interface{}"
+			case m.Length != "" && toCamelCase(m.Length) == p.Name && p.Type == "string":
+				params[i].Type = "int"
+			}
+		}
+	})
+	return params
+}
+
+// joinParams renders a Go parameter list
+func joinParams(params []Param) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = p.Name + " " + p.Type
+	}
+	return strings.Join(parts, ", ")
 }
 
 // isObjectLikeName checks if the prop name suggests an object/struct type
@@ -470,22 +884,152 @@ func (g *Generator) generateNode(node parser.Node, builder string) {
 }
 
 func (g *Generator) generateElement(elem *parser.Element, builder string) {
+	xData := g.alpineData(elem)
+	if g.tailwind {
+		elem = g.applyTailwind(elem)
+	}
+	g.currentLine = elem.LineNumber
 	tag := elem.Tag
-	method := tagToMethod(tag)
+
+	// Form control validated by a schema: follow it with its error message
+	if field, ok := g.formField(elem); ok {
+		g.generateFieldWithError(elem, builder, field)
+		return
+	}
+	if g.form != nil && g.form.Library == "formik" && g.generateFormik(elem, builder) {
+		return
+	}
+	if g.form != nil && g.form.Library == "antd" && g.generateAntdForm(elem, builder) {
+		return
+	}
+	// TanStack Table markup or an AG Grid: a table of its columns
+	if t, ok := g.dataTable(elem); ok {
+		g.generateDataTable(elem, t, builder)
+		return
+	}
+	// Design-system component covered by a mapping pack
+	if m, ok := g.mapped[tag]; ok {
+		node, notes := g.mapElement(elem, m)
+		g.generateNode(node, builder)
+		if len(notes) > 0 {
+			g.writef(" /* TODO: %s %s */", tag, strings.Join(notes, ", "))
+		}
+		return
+	}
+
+	// <Tabs> with <Tabs.Panel>s, <Select> with <Select.Option>s
+	if g.generateCompound(elem, builder) {
+		return
+	}
+
+	// i18next <Trans> element → go-i18n lookup
+	if tag == "Trans" && g.translator != "" {
+		g.generateTrans(elem)
+		return
+	}
+
+	// <ThemeContext.Provider value={theme}> passes theme down
+	if ctx, ok := g.providerOf(tag); ok {
+		g.generateProvider(elem, ctx, builder)
+		return
+	}
+
+	// <Suspense fallback={...}> renders its content, with the fallback as
+	// a loading indicator
+	if isSuspense(tag) {
+		g.generateSuspense(elem, builder)
+		return
+	}
 
 	// Check if it's a component reference (PascalCase)
 	if isComponentRef(tag) {
-		g.writef("%s(%s)", tag, g.generateComponentArgs(elem))
+		g.recordCall(elem)
+		if imp, ok := g.imported[tag]; ok {
+			g.generateImportedCall(elem, imp)
+			return
+		}
+		args := g.generateComponentArgs(elem)
+		if ctxArgs := g.contextArgs(tag); len(ctxArgs) > 0 {
+			if args != "" {
+				ctxArgs = append([]string{args}, ctxArgs...)
+			}
+			args = strings.Join(ctxArgs, ", ")
+		}
+		g.writef("%s(%s", tag, args)
+		g.generateChildArgs(elem, args != "")
+		g.write(")")
 		return
 	}
 
-	g.writef("%s.%s(", builder, method)
+	g.recordUnmappedTag(tag)
+	open, hasContent := g.elementOpen(builder, tag)
+	g.write(open)
+
+	// Form with controlled fields: the fields are posted on submit
+	submits := false
+	if tag == "form" {
+		if fields := controlledFields(elem.Children); len(fields) > 0 {
+			outer := g.controlled
+			g.controlled = make(map[string]bool, len(fields))
+			for _, field := range fields {
+				g.controlled[field] = true
+			}
+			defer func() { g.controlled = outer }()
+			submits = true
+		}
+	}
+	field, controlled := controlledField(elem)
+	controlled = controlled && g.controlled[field]
 
 	// Generate attributes
-	hasContent := false
+	rawHTML := ""
+	textValue := ""
+
+	// The component's root holds the state Alpine keeps
+	if xData != "" {
+		g.write(xData)
+		hasContent = true
+	}
+
+	// A swapped list item keeps its key as its id
+	if attr, prefix := g.keyAttr(elem); attr != "" {
+		if hasContent {
+			g.write(", ")
+		}
+		g.write(attr)
+		hasContent = true
+		outer := g.keyedID
+		g.keyedID = prefix
+		defer func() { g.keyedID = outer }()
+	}
 	for _, attr := range elem.Attributes {
-		// Skip key attribute (not needed in Go)
-		if attr.Name == "key" {
+		// Skip key attribute (not needed in Go), and Formik's bindings
+		// the posted field replaces
+		if attr.Name == "key" || g.formBinding(&attr) || g.strippedTestAttr(&attr) {
+			continue
+		}
+
+		// ref={inputRef}: the element's id
+		if id, ok := g.refAttr(&attr); ok {
+			if id != "" {
+				if hasContent {
+					g.write(", ")
+				}
+				g.write(id)
+				hasContent = true
+			}
+			continue
+		}
+
+		// A textarea's value is its content
+		if tag == "textarea" && attr.Name == "value" && attr.Expression.Raw != "" {
+			textValue = g.translateExprValue(strings.TrimSpace(attr.Expression.Raw))
+			continue
+		}
+
+		// dangerouslySetInnerHTML={{ __html: html }} → mi.Raw(html) child
+		if attr.Name == "dangerouslySetInnerHTML" {
+			rawHTML = g.translateInnerHTML(attr.Expression.Raw)
 			continue
 		}
 		
@@ -496,7 +1040,14 @@ func (g *Generator) generateElement(elem *parser.Element, builder string) {
 				g.writeIndent()
 				g.write("\t")
 			}
-			g.generateEventHandler(attr.EventHandler, elem.Tag)
+			if controlled && (attr.Name == "onChange" || attr.Name == "onInput") {
+				g.generateControlledField(elem, field)
+			} else {
+				if attr.Name == "onSubmit" {
+					submits = false
+				}
+				g.generateEventHandler(attr.EventHandler, elem.Tag)
+			}
 			hasContent = true
 			continue
 		}
@@ -504,12 +1055,31 @@ func (g *Generator) generateElement(elem *parser.Element, builder string) {
 		if hasContent {
 			g.write(", ")
 		}
-		g.generateAttribute(&attr)
+		g.generateAttribute(&attr, tag)
+		hasContent = true
+	}
+
+	// Form without onSubmit: post the controlled fields
+	if submits {
+		if hasContent {
+			g.write(", ")
+		}
+		g.writef("mi.HtmxPost(%q), mi.HtmxSwap(\"outerHTML\")", g.formSubmitEndpoint())
+		hasContent = true
+	}
+
+	// Realtime state rendered here: subscribe to its SSE stream
+	if attrs := g.realtimeAttrs(elem); attrs != "" {
+		if hasContent {
+			g.write(", ")
+		}
+		g.write(attrs)
 		hasContent = true
 	}
 
 	// Generate children
-	for i, child := range elem.Children {
+	g.keyedID = ""
+	for i, child := range g.rendered(elem.Children) {
 		if hasContent || i > 0 {
 			g.write(",\n")
 			g.writeIndent()
@@ -518,12 +1088,53 @@ func (g *Generator) generateElement(elem *parser.Element, builder string) {
 		g.generateNode(child, builder)
 		hasContent = true
 	}
+	if textValue != "" {
+		if hasContent {
+			g.write(", ")
+		}
+		g.write(textValue)
+		hasContent = true
+	}
+	if rawHTML != "" {
+		if hasContent {
+			g.write(",\n")
+			g.writeIndent()
+			g.write("\t")
+		}
+		g.writef("mi.Raw(%s)", rawHTML)
+	}
 
 	g.write(")")
 }
 
+// translateInnerHTML returns the Go string for the __html value of a
+// dangerouslySetInnerHTML object. The HTML is written unescaped; the
+// security/dangerous-html audit reports every use.
+func (g *Generator) translateInnerHTML(expr string) string {
+	if e, err := parser.ParseJSExpr(expr); err == nil {
+		if obj, ok := parser.Unparen(e).(*parser.JSObject); ok {
+			for _, prop := range obj.Props {
+				if prop.Key == "__html" && !prop.Spread {
+					return g.translateStringOperand(prop.Value, expr)
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("\"\" /* TODO: %s */", commentText(strings.ReplaceAll(expr, "\"", "'")))
+}
+
 // generateEventHandler generates HTMX attributes for a React event handler
 func (g *Generator) generateEventHandler(handler *parser.EventHandler, tag string) {
+	// Focusing or scrolling a ref's element happens in the browser
+	if code, ok := g.refAction(handler); ok {
+		g.writef("mi.Attr(%q, %q)", "hx-on:"+strings.ToLower(strings.TrimPrefix(handler.EventType, "on")), code)
+		return
+	}
+	// Toggling state Alpine keeps happens in the browser too
+	if g.generateAlpineAction(handler) {
+		return
+	}
+
 	// Determine HTMX method based on event type and context
 	switch handler.EventType {
 	case "onClick":
@@ -531,11 +1142,11 @@ func (g *Generator) generateEventHandler(handler *parser.EventHandler, tag strin
 	case "onChange":
 		g.generateOnChange(handler, tag)
 	case "onSubmit":
-		g.generateOnSubmit(handler)
+		g.generateOnSubmit(handler, tag)
 	case "onInput":
-		g.generateOnInput(handler)
+		g.generateOnInput(handler, tag)
 	case "onBlur":
-		g.generateOnBlur(handler)
+		g.generateOnBlur(handler, tag)
 	case "onFocus":
 		g.write("/* TODO: onFocus - consider mi.HtmxTrigger(\"focus\") */")
 	case "onKeyDown", "onKeyUp", "onKeyPress":
@@ -560,7 +1171,7 @@ func (g *Generator) generateOnClick(handler *parser.EventHandler, tag string) {
 		if strings.Contains(handler.HandlerBody, "!"+stateName) || 
 			strings.Contains(handler.HandlerBody, "!prev") ||
 			strings.Contains(handler.HandlerBody, "=> !") {
-			g.writef("mi.HtmxPost(\"/toggle-%s\")", toKebabCase(stateName))
+			g.writef("mi.HtmxPost(%q)", g.handlerEndpoint("POST", "toggle-"+toKebabCase(stateName), handler, tag))
 			g.write(", mi.HtmxSwap(\"outerHTML\")")
 			g.writef(" /* %s toggles %s */", setter, stateName)
 			return
@@ -570,28 +1181,36 @@ func (g *Generator) generateOnClick(handler *parser.EventHandler, tag string) {
 		valuePattern := regexp.MustCompile(setter + `\s*\(\s*['"]?([^'")\s]+)['"]?\s*\)`)
 		if matches := valuePattern.FindStringSubmatch(handler.HandlerBody); matches != nil {
 			value := matches[1]
-			g.writef("mi.HtmxPost(\"/%s?%s=%s\")", toKebabCase(stateName), stateName, value)
+			g.writef("mi.HtmxPost(\"%s?%s=%s\")", g.handlerEndpoint("POST", "set-"+toKebabCase(stateName), handler, tag), stateName, value)
 			g.write(", mi.HtmxSwap(\"outerHTML\")")
 			g.writef(" /* %s(%s) */", setter, value)
 			return
 		}
 		
 		// Generic setter call
-		g.writef("mi.HtmxPost(\"/update-%s\")", toKebabCase(stateName))
+		g.writef("mi.HtmxPost(%q)", g.handlerEndpoint("POST", "update-"+toKebabCase(stateName), handler, tag))
 		g.write(", mi.HtmxSwap(\"outerHTML\")")
-		g.writef(" /* TODO: %s */", handler.HandlerBody)
+		g.writef(" /* TODO: %s */", commentText(handler.HandlerBody))
 		return
 	}
 	
 	// Multiple setters or complex logic
 	if len(handler.SetterCalls) > 1 {
-		g.write("mi.HtmxPost(\"/action\")")
+		var states []string
+		for _, setter := range handler.SetterCalls {
+			states = append(states, toKebabCase(stateFromSetter(setter)))
+		}
+		g.writef("mi.HtmxPost(%q)", g.handlerEndpoint("POST", "update-"+strings.Join(states, "-"), handler, tag))
 		g.write(", mi.HtmxSwap(\"outerHTML\")")
 		g.writef(" /* TODO: complex handler with %v */", handler.SetterCalls)
 		return
 	}
 	
-	// No setters - might be navigation or other action
+	// No setters - a Redux action, navigation or other action
+	if d, ok := g.dispatchIn(handler.HandlerBody); ok {
+		g.generateDispatch(d, handler, tag)
+		return
+	}
 	if strings.Contains(handler.HandlerBody, "navigate") || 
 		strings.Contains(handler.HandlerBody, "router") ||
 		strings.Contains(handler.HandlerBody, "history") {
@@ -599,11 +1218,12 @@ func (g *Generator) generateOnClick(handler *parser.EventHandler, tag string) {
 		return
 	}
 	
-	g.writef("mi.HtmxPost(\"/click-action\") /* TODO: %s */", truncateExpr(handler.HandlerBody, 40))
+	g.writef("mi.HtmxPost(%q) /* TODO: %s */", g.handlerEndpoint("POST", "click", handler, tag), commentText(truncateExpr(handler.HandlerBody, 40)))
 }
 
 // generateOnChange generates HTMX for onChange handlers (typically for inputs)
 func (g *Generator) generateOnChange(handler *parser.EventHandler, tag string) {
+	handler = g.debouncedSetter(handler)
 	// Check for simple setState with e.target.value
 	if len(handler.SetterCalls) == 1 && 
 		(strings.Contains(handler.HandlerBody, "target.value") ||
@@ -614,8 +1234,8 @@ func (g *Generator) generateOnChange(handler *parser.EventHandler, tag string) {
 		
 		if tag == "input" || tag == "textarea" || tag == "select" {
 			g.writef("mi.Name(%q)", stateName)
-			g.write(", mi.HtmxGet(\"/update\")")
-			g.writef(", mi.HtmxTrigger(\"input changed delay:300ms\")")
+			g.writef(", mi.HtmxGet(%q)", g.handlerEndpoint("GET", "update-"+toKebabCase(stateName), handler, tag))
+			g.writef(", mi.HtmxTrigger(%q)", g.inputTrigger(stateName))
 			g.write(", mi.HtmxInclude(\"closest form\")")
 			g.writef(" /* %s from input */", setter)
 			return
@@ -629,22 +1249,30 @@ func (g *Generator) generateOnChange(handler *parser.EventHandler, tag string) {
 			stateName := strings.TrimPrefix(setter, "set")
 			stateName = strings.ToLower(stateName[:1]) + stateName[1:]
 			g.writef("mi.Name(%q)", stateName)
-			g.write(", mi.HtmxPost(\"/toggle\")")
+			g.writef(", mi.HtmxPost(%q)", g.handlerEndpoint("POST", "toggle-"+toKebabCase(stateName), handler, tag))
 			g.write(", mi.HtmxTrigger(\"change\")")
 			g.writef(" /* %s from checkbox */", setter)
 			return
 		}
 	}
 	
-	g.writef("mi.HtmxGet(\"/update\"), mi.HtmxTrigger(\"change\") /* TODO: %s */", 
-		truncateExpr(handler.HandlerBody, 40))
+	g.writef("mi.HtmxGet(%q), mi.HtmxTrigger(\"change\") /* TODO: %s */", 
+		g.handlerEndpoint("GET", "change", handler, tag), commentText(truncateExpr(handler.HandlerBody, 40)))
 }
 
 // generateOnSubmit generates HTMX for form submissions
-func (g *Generator) generateOnSubmit(handler *parser.EventHandler) {
+func (g *Generator) generateOnSubmit(handler *parser.EventHandler, tag string) {
+	// react-hook-form handleSubmit(onSubmit) and Formik's handleSubmit:
+	// the fields are posted, decoded and validated on the server
+	if g.form != nil && strings.Contains(handler.HandlerBody, "handleSubmit") {
+		g.writef("mi.HtmxPost(%q)", g.formEndpoint(handler, tag))
+		g.write(", mi.HtmxSwap(\"outerHTML\")")
+		g.writef(" /* fields decoded by %s */", decodeFuncName(g.component))
+		return
+	}
 	// Most form submissions prevent default and do something
 	if strings.Contains(handler.HandlerBody, "preventDefault") {
-		g.write("mi.HtmxPost(\"/submit\")")
+		g.writef("mi.HtmxPost(%q)", g.handlerEndpoint("POST", "submit", handler, tag))
 		g.write(", mi.HtmxSwap(\"outerHTML\")")
 		if len(handler.SetterCalls) > 0 {
 			g.writef(" /* updates: %v */", handler.SetterCalls)
@@ -652,39 +1280,40 @@ func (g *Generator) generateOnSubmit(handler *parser.EventHandler) {
 		return
 	}
 	
-	g.writef("mi.HtmxPost(\"/submit\") /* TODO: %s */", truncateExpr(handler.HandlerBody, 40))
+	g.writef("mi.HtmxPost(%q) /* TODO: %s */", g.handlerEndpoint("POST", "submit", handler, tag), commentText(truncateExpr(handler.HandlerBody, 40)))
 }
 
 // generateOnInput generates HTMX for onInput handlers
-func (g *Generator) generateOnInput(handler *parser.EventHandler) {
+func (g *Generator) generateOnInput(handler *parser.EventHandler, tag string) {
+	handler = g.debouncedSetter(handler)
 	if len(handler.SetterCalls) == 1 {
 		setter := handler.SetterCalls[0]
 		stateName := strings.TrimPrefix(setter, "set")
 		stateName = strings.ToLower(stateName[:1]) + stateName[1:]
 		g.writef("mi.Name(%q)", stateName)
-		g.write(", mi.HtmxGet(\"/search\")")
-		g.write(", mi.HtmxTrigger(\"input changed delay:200ms\")")
+		g.writef(", mi.HtmxGet(%q)", g.handlerEndpoint("GET", "search-"+toKebabCase(stateName), handler, tag))
+		g.writef(", mi.HtmxTrigger(%q)", g.inputTrigger(stateName))
 		g.writef(" /* live %s */", setter)
 		return
 	}
 	
-	g.write("mi.HtmxGet(\"/update\"), mi.HtmxTrigger(\"input\")")
+	g.writef("mi.HtmxGet(%q), mi.HtmxTrigger(\"input\")", g.handlerEndpoint("GET", "input", handler, tag))
 }
 
 // generateOnBlur generates HTMX for onBlur handlers
-func (g *Generator) generateOnBlur(handler *parser.EventHandler) {
+func (g *Generator) generateOnBlur(handler *parser.EventHandler, tag string) {
 	if len(handler.SetterCalls) == 1 {
 		setter := handler.SetterCalls[0]
 		stateName := strings.TrimPrefix(setter, "set")
 		stateName = strings.ToLower(stateName[:1]) + stateName[1:]
 		g.writef("mi.Name(%q)", stateName)
-		g.write(", mi.HtmxPost(\"/validate\")")
+		g.writef(", mi.HtmxPost(%q)", g.handlerEndpoint("POST", "validate-"+toKebabCase(stateName), handler, tag))
 		g.write(", mi.HtmxTrigger(\"blur\")")
 		g.writef(" /* validate %s */", stateName)
 		return
 	}
 	
-	g.write("mi.HtmxPost(\"/validate\"), mi.HtmxTrigger(\"blur\")")
+	g.writef("mi.HtmxPost(%q), mi.HtmxTrigger(\"blur\")", g.handlerEndpoint("POST", "validate", handler, tag))
 }
 
 // toKebabCase converts camelCase to kebab-case
@@ -699,25 +1328,44 @@ func toKebabCase(s string) string {
 	return strings.ToLower(result.String())
 }
 
-func (g *Generator) generateAttribute(attr *parser.Attribute) {
+func (g *Generator) generateAttribute(attr *parser.Attribute, tag string) {
 	if attr.IsSpread {
+		// react-hook-form {...register('field')} binds the field name
+		if m := registerCall.FindStringSubmatch(strings.TrimSpace(attr.SpreadExpr)); m != nil {
+			g.writef("mi.Name(%q)", m[1])
+			return
+		}
 		g.writef("mi.Attr(\"spread\", \"\") /* TODO: {...%s} */", attr.SpreadExpr)
 		return
 	}
 
 	name := attr.Name
+	if strings.Contains(attr.Value, "&") {
+		// Character references in a string value are decoded as in text
+		decoded := *attr
+		decoded.Value = jsxText(attr.Value)
+		attr = &decoded
+	}
+
+	// SVG attributes keep the names SVG gives them: stroke-width, viewBox
+	svg := isSVGTag(tag)
+	if svg {
+		name = svgAttrName(name)
+	}
 	
+	// htmx attributes minty has no option function for: hx-vals, hx-on:*
+	if g.generateHtmxAttr(name, attr) {
+		return
+	}
+
+	// Alpine.js attributes: x-data, x-show, @click
+	if g.generateAlpineAttr(name, attr) {
+		return
+	}
+
 	// Handle data attributes specially
 	if strings.HasPrefix(name, "data-") {
-		dataName := strings.TrimPrefix(name, "data-")
-		if attr.Value != "" {
-			g.writef("mi.Data(%q, %q)", dataName, attr.Value)
-		} else if attr.Expression.Raw != "" {
-			value := g.translateExprValue(attr.Expression.Raw)
-			g.writef("mi.Data(%q, %s)", dataName, value)
-		} else {
-			g.writef("mi.Data(%q, \"\")", dataName)
-		}
+		g.generateDataAttr(name, attr)
 		return
 	}
 	
@@ -740,7 +1388,16 @@ func (g *Generator) generateAttribute(attr *parser.Attribute) {
 		return
 	}
 	
-	mintyAttr := attrToMinty(name)
+	// <form action={createTodo}> posts to the server action's endpoint
+	if hx := g.serverActionAttr(attr); hx != "" {
+		g.write(hx)
+		return
+	}
+
+	mintyAttr := g.attrOption(name)
+	if mintyAttr == "" && !svg {
+		g.recordUnmappedAttr(name, tag)
+	}
 
 	// String value
 	if attr.Value != "" {
@@ -754,20 +1411,18 @@ func (g *Generator) generateAttribute(attr *parser.Attribute) {
 
 	// Expression value
 	if attr.Expression.Raw != "" {
+		g.checkAssetPath(name, attr.Expression.Raw)
 		value := g.translateExprValue(attr.Expression.Raw)
 		if mintyAttr != "" {
 			// Check if this is a no-argument boolean attribute
-			boolAttrs := map[string]bool{
-				"mi.Disabled": true, "mi.Readonly": true, "mi.Required": true,
-				"mi.Checked": true, "mi.Selected": true, "mi.Multiple": true,
-				"mi.Autofocus": true, "mi.Autoplay": true, "mi.Controls": true,
-				"mi.Loop": true, "mi.Muted": true, "mi.NoValidate": true,
-				"mi.Open": true, "mi.Reversed": true, "mi.Async": true,
-				"mi.Defer": true, "mi.Hidden": true,
-			}
 			if boolAttrs[mintyAttr] {
 				// Boolean attr with condition - use conditional inclusion
-				g.writef("/* conditional: %s when %s */ ", mintyAttr+"()", value)
+				// Comments don't nest: name an untranslated condition by its source
+				cond := value
+				if strings.Contains(cond, "/*") {
+					cond = truncateExpr(attr.Expression.Raw, 40)
+				}
+				g.writef("/* conditional: %s when %s */ ", mintyAttr+"()", cond)
 				g.writef("mi.Attr(%q, fmt.Sprint(%s))", name, value)
 			} else {
 				g.writef("%s(%s)", mintyAttr, value)
@@ -792,6 +1447,36 @@ func (g *Generator) generateAttribute(attr *parser.Attribute) {
 }
 
 func (g *Generator) translateExprValue(expr string) string {
+	// Imported static asset: logo → "/static/logo.png"
+	if translated, ok := g.translateAssetRef(expr); ok {
+		return translated
+	}
+
+	// CSS Modules access: styles.card → "Card_card"
+	if translated, ok := g.translateCSSModuleRef(expr); ok {
+		return translated
+	}
+
+	// i18next lookup: t('nav.home') → localizer.MustLocalize(...)
+	if translated, ok := g.translateMessageRef(expr); ok {
+		return translated
+	}
+
+	// React.Children.count(children) → len(children)
+	if translated, ok := g.translateChildrenUtil(expr); ok {
+		return translated
+	}
+
+	// A helper function or a constant of the file: formatDate(post.date)
+	if call, ok := g.translateFileRef(expr); ok {
+		switch {
+		case call.typ == "string":
+			return call.code
+		case isNumeric(call.typ), call.typ == "bool", call.typ == "interface{}":
+			return "fmt.Sprint(" + call.code + ")"
+		}
+	}
+
 	// Ternary expression → mi.Ternary (for string results)
 	if strings.Contains(expr, "?") && strings.Contains(expr, ":") {
 		if translated := g.translateTernaryExpr(expr); translated != "" {
@@ -799,6 +1484,32 @@ func (g *Generator) translateExprValue(expr string) string {
 		}
 	}
 
+	// Class composition: clsx('btn', isActive && 'active')
+	if translated, ok := g.translateClassNames(expr); ok {
+		return translated
+	}
+
+	// Array join: [styles.card, size].join(' ')
+	if strings.Contains(expr, ".join(") {
+		if translated := g.translateJoinExpr(expr); translated != "" {
+			return translated
+		}
+	}
+
+	// Optional value: isActive && 'active'
+	if strings.Contains(expr, "&&") {
+		if translated := g.translateAndExpr(expr); translated != "" {
+			return translated
+		}
+	}
+
+	// String concatenation: 'btn ' + size
+	if strings.Contains(expr, "+") {
+		if translated := g.translateConcatExpr(expr); translated != "" {
+			return translated
+		}
+	}
+
 	// Template literal → fmt.Sprintf
 	if strings.Contains(expr, "`") || strings.Contains(expr, "${") {
 		return g.translateTemplateLiteral(expr)
@@ -811,7 +1522,7 @@ func (g *Generator) translateExprValue(expr string) string {
 			return goName
 		}
 		// Unknown variable - placeholder
-		return fmt.Sprintf("\"\" /* TODO: %s */", expr)
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(expr))
 	}
 
 	// Property access: item.name, props.value, etc.
@@ -820,11 +1531,24 @@ func (g *Generator) translateExprValue(expr string) string {
 		base := parts[0]
 		
 		// If we're in a map body and this is the item variable, use mi.Str
+		if field, _, ok := g.itemField(expr); ok {
+			return field
+		}
 		if g.inMapBody && len(parts) >= 2 && base == g.currentItemVar {
 			fieldName := parts[1]
 			return fmt.Sprintf("mi.Str(%s, %q)", base, fieldName)
 		}
 		
+		// A field of a context value: auth.userName → auth.UserName
+		if field, _, ok := g.contextField(expr); ok {
+			return field
+		}
+
+		// A field of the props object: props.label → label
+		if base == "props" && g.currentParams[parts[1]] {
+			return g.translateExprValue(strings.Join(parts[1:], "."))
+		}
+
 		// If the base is an object-like param, use mi.Str
 		if g.objectParams != nil && g.objectParams[base] && len(parts) >= 2 {
 			fieldName := parts[1]
@@ -832,7 +1556,7 @@ func (g *Generator) translateExprValue(expr string) string {
 		}
 		
 		// Otherwise wrap as placeholder
-		return fmt.Sprintf("\"\" /* TODO: %s */", expr)
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(expr))
 	}
 
 	// Comparison expression - try to translate
@@ -855,22 +1579,22 @@ func (g *Generator) translateExprValue(expr string) string {
 		if translated := g.translateLengthExpr(expr); translated != "" {
 			return translated
 		}
-		return fmt.Sprintf("\"\" /* TODO: %s */", strings.ReplaceAll(expr, "\"", "'"))
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(strings.ReplaceAll(expr, "\"", "'")))
 	}
 
 	// Arrow function - produce empty string placeholder
 	if strings.Contains(expr, "=>") {
-		return fmt.Sprintf("\"\" /* TODO: %s */", strings.ReplaceAll(expr, "\"", "'"))
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(strings.ReplaceAll(expr, "\"", "'")))
 	}
 
 	// String concatenation
 	if strings.Contains(expr, "+") {
-		return fmt.Sprintf("\"\" /* TODO: %s */", strings.ReplaceAll(expr, "\"", "'"))
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(strings.ReplaceAll(expr, "\"", "'")))
 	}
 
 	// Function call
 	if strings.Contains(expr, "(") {
-		return fmt.Sprintf("\"\" /* TODO: %s */", strings.ReplaceAll(expr, "\"", "'"))
+		return fmt.Sprintf("\"\" /* TODO: %s */", commentText(strings.ReplaceAll(expr, "\"", "'")))
 	}
 
 	return fmt.Sprintf("%q", expr)
@@ -879,49 +1603,216 @@ func (g *Generator) translateExprValue(expr string) string {
 // translateTernaryExpr translates a ternary expression to Go
 // e.g., "filter === 'all' ? 'active' : ''" → func() string { if filter == "all" { return "active" }; return "" }()
 func (g *Generator) translateTernaryExpr(expr string) string {
-	// Find the ? and : positions
-	qPos := strings.Index(expr, "?")
-	if qPos < 0 {
+	e, err := parser.ParseJSExpr(expr)
+	if err != nil {
 		return ""
 	}
-	
-	// Find the matching : (handle nested ternaries by counting)
-	colonPos := -1
-	depth := 0
-	for i := qPos + 1; i < len(expr); i++ {
-		if expr[i] == '?' {
-			depth++
-		} else if expr[i] == ':' {
-			if depth == 0 {
-				colonPos = i
-				break
+	t, ok := parser.Unparen(e).(*parser.JSConditional)
+	if !ok {
+		return ""
+	}
+
+	goCondition := g.translateCondition(t.Test.Span().Text(expr))
+	goConsequent := g.translateStringOperand(t.Then, expr)
+	goAlternate := g.translateStringOperand(t.Else, expr)
+
+	// Generate inline Go ternary equivalent
+	return fmt.Sprintf("func() string { if %s { return %s }; return %s }()", goCondition, goConsequent, goAlternate)
+}
+
+// translateAndExpr converts cond && value, as used for optional class
+// names, to the value or ""
+func (g *Generator) translateAndExpr(expr string) string {
+	e, err := parser.ParseJSExpr(expr)
+	if err != nil {
+		return ""
+	}
+	and, ok := parser.Unparen(e).(*parser.JSBinary)
+	if !ok || and.Op != "&&" {
+		return ""
+	}
+	goCondition := g.translateCondition(and.Left.Span().Text(expr))
+	goValue := g.translateStringOperand(and.Right, expr)
+	return fmt.Sprintf("func() string { if %s { return %s }; return \"\" }()", goCondition, goValue)
+}
+
+// translateConcatExpr converts string concatenation ('btn ' + size) to
+// fmt.Sprintf. At least one operand must be a string, or + is arithmetic.
+func (g *Generator) translateConcatExpr(expr string) string {
+	e, err := parser.ParseJSExpr(expr)
+	if err != nil {
+		return ""
+	}
+	var operands []parser.JSExpr
+	var flatten func(x parser.JSExpr)
+	flatten = func(x parser.JSExpr) {
+		if bin, ok := x.(*parser.JSBinary); ok && bin.Op == "+" {
+			flatten(bin.Left)
+			flatten(bin.Right)
+			return
+		}
+		operands = append(operands, x)
+	}
+	flatten(parser.Unparen(e))
+	if len(operands) < 2 {
+		// A + inside a literal: the expression is no concatenation
+		return ""
+	}
+
+	literals := []string{""}
+	var args []string
+	hasString := false
+	for _, x := range operands {
+		switch n := parser.Unparen(x).(type) {
+		case *parser.JSLiteral:
+			if n.Kind == "string" {
+				hasString = true
+				literals[len(literals)-1] += n.Value
+				continue
+			}
+		case *parser.JSTemplate:
+			if n.Tag == nil {
+				hasString = true
+				g.appendTemplateParts(n, expr, &literals, &args)
+				continue
 			}
-			depth--
 		}
+		args = append(args, g.translateStringOperand(x, expr))
+		literals = append(literals, "")
 	}
-	
-	if colonPos < 0 {
+	if !hasString {
 		return ""
 	}
-	
-	condition := strings.TrimSpace(expr[:qPos])
-	consequent := strings.TrimSpace(expr[qPos+1 : colonPos])
-	alternate := strings.TrimSpace(expr[colonPos+1:])
-	
-	// Translate the condition (=== to ==, !== to !=)
-	goCondition := g.translateComparison(condition)
-	if goCondition == "" {
-		// Try as-is but fix operators
-		goCondition = strings.ReplaceAll(condition, "===", "==")
-		goCondition = strings.ReplaceAll(goCondition, "!==", "!=")
+	return formatParts(literals, args)
+}
+
+// translateStringOperand translates one operand of a string-valued
+// expression: literals are quoted, anything else goes through
+// translateExprValue
+func (g *Generator) translateStringOperand(x parser.JSExpr, src string) string {
+	if lit, ok := parser.Unparen(x).(*parser.JSLiteral); ok {
+		switch lit.Kind {
+		case "string":
+			return fmt.Sprintf("%q", lit.Value)
+		case "null", "undefined":
+			return `""`
+		}
 	}
-	
-	// Extract string values from consequent/alternate
-	goConsequent := extractStringValue(consequent)
-	goAlternate := extractStringValue(alternate)
-	
-	// Generate inline Go ternary equivalent
-	return fmt.Sprintf("func() string { if %s { return %s }; return %s }()", goCondition, goConsequent, goAlternate)
+	return g.translateExprValue(strings.TrimSpace(x.Span().Text(src)))
+}
+
+// appendTemplateParts adds the text and interpolations of a template
+// literal to a literals/args pair as built for formatParts
+func (g *Generator) appendTemplateParts(tmpl *parser.JSTemplate, src string, literals *[]string, args *[]string) {
+	for i, quasi := range tmpl.Quasis {
+		(*literals)[len(*literals)-1] += quasi
+		if i < len(tmpl.Exprs) {
+			*args = append(*args, g.templateArg(strings.TrimSpace(tmpl.Exprs[i].Span().Text(src))))
+			*literals = append(*literals, "")
+		}
+	}
+}
+
+// templateArg translates one ${...} of a template literal
+func (g *Generator) templateArg(varName string) string {
+	// Handle property access (e.g., post.status)
+	if isPropertyAccess(varName) {
+		parts := strings.Split(varName, ".")
+		base := parts[0]
+		field := parts[1]
+		// Check if base is an object-like parameter or map item
+		if length, ok := g.paramLength(varName); ok {
+			return length
+		} else if typed, _, ok := g.itemField(varName); ok {
+			return typed
+		} else if g.objectParams != nil && g.objectParams[base] {
+			return fmt.Sprintf("mi.Str(%s, %q)", base, field)
+		} else if g.inMapBody && base == g.currentItemVar {
+			return fmt.Sprintf("mi.Str(%s, %q)", base, field)
+		} else if g.currentParams != nil && g.currentParams[base] {
+			// Known param but not object-like, try mi.Str
+			return fmt.Sprintf("mi.Str(%s, %q)", base, field)
+		}
+		return varName // keep as-is for unknown
+	}
+	if isSimpleIdent(varName) {
+		return toCamelCase(varName)
+	}
+	return g.translateExprValue(varName)
+}
+
+// paramLength translates the length of a parameter, items.length, to
+// len(items)
+func (g *Generator) paramLength(raw string) (string, bool) {
+	base, prop, ok := strings.Cut(raw, ".")
+	if !ok || prop != "length" || !g.currentParams[base] {
+		return "", false
+	}
+	return fmt.Sprintf("len(%s)", toCamelCase(base)), true
+}
+
+// formatParts joins literal text and Go expressions (len(literals) ==
+// len(args)+1) into a quoted string or a fmt.Sprintf call
+func formatParts(literals []string, args []string) string {
+	// Fold arguments that translated to plain strings ("Card_card")
+	format := []string{strings.ReplaceAll(literals[0], "%", "%%")}
+	var goArgs []string
+	for i, arg := range args {
+		if text, err := strconv.Unquote(arg); err == nil && strings.HasPrefix(arg, `"`) {
+			format[len(format)-1] += strings.ReplaceAll(text, "%", "%%") + strings.ReplaceAll(literals[i+1], "%", "%%")
+			continue
+		}
+		goArgs = append(goArgs, arg)
+		format = append(format, strings.ReplaceAll(literals[i+1], "%", "%%"))
+	}
+	if len(goArgs) == 0 {
+		return fmt.Sprintf("%q", strings.ReplaceAll(format[0], "%%", "%"))
+	}
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", strings.Join(format, "%v"), strings.Join(goArgs, ", "))
+}
+
+// translateJoinExpr converts [a, b].join(' ') to a single string
+func (g *Generator) translateJoinExpr(expr string) string {
+	e, err := parser.ParseJSExpr(expr)
+	if err != nil {
+		return ""
+	}
+	call, recv, ok := parser.MethodCall(parser.Unparen(e), "join")
+	if !ok {
+		return ""
+	}
+	arr, ok := parser.Unparen(recv).(*parser.JSArray)
+	if !ok || len(call.Args) > 1 {
+		return ""
+	}
+	sep := ","
+	if len(call.Args) == 1 {
+		lit, ok := call.Args[0].(*parser.JSLiteral)
+		if !ok || lit.Kind != "string" {
+			return ""
+		}
+		sep = lit.Value
+	}
+	literals := []string{""}
+	var args []string
+	for i, item := range arr.Elements {
+		if i > 0 {
+			literals[len(literals)-1] += sep
+		}
+		if item == nil {
+			continue
+		}
+		if _, spread := item.(*parser.JSSpread); spread {
+			return ""
+		}
+		if lit, ok := item.(*parser.JSLiteral); ok && lit.Kind == "string" {
+			literals[len(literals)-1] += lit.Value
+			continue
+		}
+		args = append(args, g.translateStringOperand(item, expr))
+		literals = append(literals, "")
+	}
+	return formatParts(literals, args)
 }
 
 // extractStringValue extracts a Go string from a JS value
@@ -953,44 +1844,31 @@ func extractStringValue(val string) string {
 func (g *Generator) translateComparison(expr string) string {
 	// Try to parse: variable === 'value' or variable === "value"
 	// Also: variable !== 'value'
-	
-	var op, goOp string
-	if strings.Contains(expr, "===") {
-		op = "==="
-		goOp = "=="
-	} else if strings.Contains(expr, "!==") {
-		op = "!=="
-		goOp = "!="
-	} else if strings.Contains(expr, "==") {
-		op = "=="
-		goOp = "=="
-	} else if strings.Contains(expr, "!=") {
-		op = "!="
-		goOp = "!="
-	} else {
+	e, err := parser.ParseJSExpr(expr)
+	if err != nil {
 		return ""
 	}
-	
-	parts := strings.SplitN(expr, op, 2)
-	if len(parts) != 2 {
+	cmp, ok := parser.Unparen(e).(*parser.JSBinary)
+	if !ok {
 		return ""
 	}
-	
-	left := strings.TrimSpace(parts[0])
-	right := strings.TrimSpace(parts[1])
-	
+	goOp, ok := map[string]string{"===": "==", "!==": "!=", "==": "==", "!=": "!="}[cmp.Op]
+	if !ok {
+		return ""
+	}
+
 	// Translate left side
-	goLeft := g.translateComparisonOperand(left)
+	goLeft := g.translateComparisonOperand(strings.TrimSpace(cmp.Left.Span().Text(expr)))
 	if goLeft == "" {
 		return ""
 	}
-	
+
 	// Translate right side
-	goRight := g.translateComparisonOperand(right)
+	goRight := g.translateComparisonOperand(strings.TrimSpace(cmp.Right.Span().Text(expr)))
 	if goRight == "" {
 		return ""
 	}
-	
+
 	return fmt.Sprintf("%s %s %s", goLeft, goOp, goRight)
 }
 
@@ -1037,9 +1915,17 @@ func (g *Generator) translateComparisonOperand(operand string) string {
 		return ""
 	}
 	
+	// React.Children.count(children) → len(children)
+	if translated, ok := g.translateChildrenUtil(operand); ok {
+		return translated
+	}
+
 	// Property access in map body
 	if isPropertyAccess(operand) && g.inMapBody {
 		parts := strings.Split(operand, ".")
+		if field, _, ok := g.itemField(operand); ok {
+			return field
+		}
 		if len(parts) >= 2 && parts[0] == g.currentItemVar {
 			return fmt.Sprintf("%s[%q]", parts[0], parts[1])
 		}
@@ -1109,6 +1995,16 @@ func (g *Generator) translateLengthExpr(expr string) string {
 
 // translateTemplateLiteral converts JS template literals to fmt.Sprintf
 func (g *Generator) translateTemplateLiteral(expr string) string {
+	expr = g.resolveCSSModuleInterpolations(expr)
+	if e, err := parser.ParseJSExpr(expr); err == nil {
+		if tmpl, ok := e.(*parser.JSTemplate); ok && tmpl.Tag == nil {
+			literals := []string{""}
+			var args []string
+			g.appendTemplateParts(tmpl, expr, &literals, &args)
+			return formatParts(literals, args)
+		}
+	}
+
 	// Remove backticks if present
 	expr = strings.Trim(expr, "`")
 	
@@ -1136,7 +2032,9 @@ func (g *Generator) translateTemplateLiteral(expr string) string {
 				base := parts[0]
 				field := parts[1]
 				// Check if base is an object-like parameter or map item
-				if g.objectParams != nil && g.objectParams[base] {
+				if typed, _, ok := g.itemField(varName); ok {
+					vars = append(vars, typed)
+				} else if g.objectParams != nil && g.objectParams[base] {
 					vars = append(vars, fmt.Sprintf("mi.Str(%s, %q)", base, field))
 				} else if g.inMapBody && base == g.currentItemVar {
 					vars = append(vars, fmt.Sprintf("mi.Str(%s, %q)", base, field))
@@ -1179,10 +2077,70 @@ func isPropertyAccess(s string) bool {
 
 func (g *Generator) generateText(text *parser.Text) {
 	// Escape the text content
-	g.writef("%q", text.Content)
+	if g.preserve && text.Raw != "" {
+		g.writef("%q", jsxText(text.Raw))
+		return
+	}
+	g.writef("%q", jsxText(text.Content))
 }
 
 func (g *Generator) generateExpression(expr *parser.Expression) {
+	// {children} of a component taking children
+	if g.isChildrenRef(expr.Raw) {
+		g.generateChildrenRef()
+		return
+	}
+
+	// i18next lookup
+	if translated, ok := g.translateMessageRef(expr.Raw); ok {
+		g.write(translated)
+		return
+	}
+
+	// A string rendered as text: {' '}, {'{'}
+	if text, ok := stringChild(expr.Raw); ok {
+		g.writef("%q", text)
+		return
+	}
+
+	// React.Children utilities over the children parameter
+	if g.generateChildrenMap(expr.Raw, expr.LineNumber) {
+		return
+	}
+	if translated, ok := g.translateChildrenUtil(expr.Raw); ok {
+		g.write(translated)
+		return
+	}
+
+	// A cloned element, rendered as it is
+	if g.generateCloneElement(expr.Raw, expr.LineNumber) {
+		return
+	}
+
+	// A render prop called: renderItem(item)
+	if call, ok := g.translateRenderCall(expr.Raw); ok {
+		g.write(call)
+		return
+	}
+
+	// A field error of the form: {errors.email.message}
+	if msg, ok := g.formError(expr.Raw); ok {
+		g.write(msg)
+		return
+	}
+
+	// A helper function or a constant of the file: {formatPrice(item.price)}
+	if call, ok := g.translateFileRef(expr.Raw); ok {
+		switch {
+		case call.typ == "string", call.typ == "mi.H":
+			g.write(call.code)
+			return
+		case isNumeric(call.typ), call.typ == "interface{}":
+			g.writef("fmt.Sprint(%s)", call.code)
+			return
+		}
+	}
+
 	// Simple variable reference
 	if isSimpleIdent(expr.Raw) {
 		goName := toCamelCase(expr.Raw)
@@ -1191,7 +2149,7 @@ func (g *Generator) generateExpression(expr *parser.Expression) {
 			return
 		}
 		// Unknown variable - placeholder
-		g.writef("\"\" /* TODO: %s */", expr.Raw)
+		g.writef("\"\" /* TODO: %s */", commentText(expr.Raw))
 		return
 	}
 
@@ -1200,24 +2158,36 @@ func (g *Generator) generateExpression(expr *parser.Expression) {
 	if isPropertyAccess(expr.Raw) {
 		parts := strings.Split(expr.Raw, ".")
 		base := parts[0]
+
+		// A field of the props object: props.label → label
+		if base == "props" && g.currentParams[parts[1]] {
+			g.generateExpression(&parser.Expression{Raw: strings.Join(parts[1:], "."), LineNumber: expr.LineNumber})
+			return
+		}
 		
 		// Special case: .length - convert to len()
-		if len(parts) == 2 && parts[1] == "length" {
-			// Check if base is a known variable
-			if g.currentParams != nil && g.currentParams[base] {
-				goBase := toCamelCase(base)
-				g.writef("len(%s)", goBase)
-				return
-			}
+		if length, ok := g.paramLength(expr.Raw); ok {
+			g.write(length)
+			return
 		}
 		
 		// If we're in a map body and this is the item variable, use mi.Str
+		if field, _, ok := g.itemField(expr.Raw); ok {
+			g.write(field)
+			return
+		}
 		if g.inMapBody && len(parts) >= 2 && base == g.currentItemVar {
 			fieldName := parts[1]
 			g.writef("mi.Str(%s, %q)", base, fieldName)
 			return
 		}
 		
+		// A field of a context value: auth.userName → auth.UserName
+		if field, _, ok := g.contextField(expr.Raw); ok {
+			g.write(field)
+			return
+		}
+
 		// If the base is an object-like param, use mi.Str
 		if g.objectParams != nil && g.objectParams[base] && len(parts) >= 2 {
 			fieldName := parts[1]
@@ -1226,17 +2196,23 @@ func (g *Generator) generateExpression(expr *parser.Expression) {
 		}
 		
 		// Otherwise placeholder
-		g.writef("\"\" /* TODO: %s */", expr.Raw)
+		g.writef("\"\" /* TODO: %s */", commentText(expr.Raw))
 		return
 	}
 
+	// Text interpolation: {`${count} items`} → fmt.Sprintf
+	if e, err := parser.ParseJSExpr(expr.Raw); err == nil {
+		if tmpl, ok := e.(*parser.JSTemplate); ok && tmpl.Tag == nil {
+			g.write(g.translateTemplateLiteral(expr.Raw))
+			return
+		}
+	}
+
 	// More complex expression - show as string placeholder
-	g.writef("\"\" /* TODO: %s */", expr.Raw)
+	g.writef("\"\" /* TODO: %s */", commentText(expr.Raw))
 }
 
 func (g *Generator) generateFragment(frag *parser.Fragment, builder string) {
-	g.usesFragment = true
-
 	if len(frag.Children) == 0 {
 		g.write("mi.NewFragment()")
 		return
@@ -1255,16 +2231,72 @@ func (g *Generator) generateFragment(frag *parser.Fragment, builder string) {
 }
 
 func (g *Generator) generateMap(m *parser.MapExpr, builder string) {
-	g.usesEach = true
-
+	if g.generateObjectMap(m) {
+		return
+	}
 	collection := toCamelCase(m.Collection)
 	itemVar := m.ItemVar
 	
 	// Check if collection is a known parameter
 	collectionKnown := g.currentParams != nil && g.currentParams[m.Collection]
-	
-	// Use mi.Each with interface{}
-	if m.IndexVar != "" {
+
+	// A collection of typed items passes them to a typed callback
+	item := g.itemTypeOf(m.Collection)
+
+	// A constant of the file is known too, with the type of its elements
+	elem := ""
+	if c := g.constantRef(m.Collection); c != nil && strings.HasPrefix(c.value.typ, "[]") {
+		collection, collectionKnown, item = c.value.code, true, c.item
+		if elem = strings.TrimPrefix(c.value.typ, "[]"); elem == "interface{}" || item != nil {
+			elem = ""
+		}
+	}
+
+	// So is a slice a map over an object binds: the groups of
+	// Object.entries(byStatus)
+	if typ, ok := g.mapLocals[m.Collection]; ok && strings.HasPrefix(typ, "[]") {
+		collection, collectionKnown, item, elem = goLocal(m.Collection), true, nil, strings.TrimPrefix(typ, "[]")
+		if t := g.structType(elem); t != nil {
+			item, elem = t, ""
+		} else if elem == "interface{}" {
+			elem = ""
+		}
+	}
+
+	// Operations chained on the collection run before it is mapped
+	if len(m.Chain) > 0 && collectionKnown {
+		typed := item
+		if elem != "" {
+			typed = nil
+		}
+		collection = g.chainedCollection(m, collection, typed, elem)
+	}
+
+	// So is a derived variable computed in place
+	if x, ok := g.derived[m.Collection]; ok && item == nil && elem == "" && strings.HasPrefix(x.typ, "[]") {
+		if elem = strings.TrimPrefix(x.typ, "[]"); g.structType(elem) != nil {
+			item, elem = g.structType(elem), ""
+		} else if elem == "interface{}" {
+			elem = ""
+		}
+	}
+
+	if elem != "" {
+		item = nil
+		if m.IndexVar != "" {
+			g.writef("mi.EachWithIndex(%s, func(%s int, %s %s) mi.H {\n", collection, m.IndexVar, itemVar, elem)
+		} else {
+			g.writef("mi.Each(%s, func(%s %s) mi.H {\n", collection, itemVar, elem)
+		}
+		g.indent++
+	} else if item != nil && collectionKnown {
+		if m.IndexVar != "" {
+			g.writef("mi.EachWithIndex(%s, func(%s int, %s %s) mi.H {\n", collection, m.IndexVar, itemVar, item.name)
+		} else {
+			g.writef("mi.Each(%s, func(%s %s) mi.H {\n", collection, itemVar, item.name)
+		}
+		g.indent++
+	} else if m.IndexVar != "" {
 		if collectionKnown {
 			g.writef("mi.EachWithIndex(%s, func(%s int, %sVal interface{}) mi.H {\n",
 				collection,
@@ -1273,7 +2305,7 @@ func (g *Generator) generateMap(m *parser.MapExpr, builder string) {
 		} else {
 			// Collection is undefined - create placeholder
 			g.writef("mi.EachWithIndex([]interface{}{} /* TODO: %s */, func(%s int, %sVal interface{}) mi.H {\n",
-				m.Collection,
+				commentText(m.Source()),
 				m.IndexVar,
 				itemVar)
 		}
@@ -1285,20 +2317,34 @@ func (g *Generator) generateMap(m *parser.MapExpr, builder string) {
 		} else {
 			// Collection is undefined - create placeholder
 			g.writef("mi.Each([]interface{}{} /* TODO: %s */, func(%sVal interface{}) mi.H {\n",
-				m.Collection,
+				commentText(m.Source()),
 				itemVar)
 		}
 	}
-	g.indent++
-	
-	// Add type assertion that produces a map for field access
-	g.writeIndent()
-	g.writef("%s := %sVal.(map[string]interface{}) // TODO: or use your struct type\n", itemVar, itemVar)
+	if elem == "" && (item == nil || !collectionKnown) {
+		item = nil
+		g.indent++
+
+		// Add type assertion that produces a map for field access
+		g.writeIndent()
+		g.writef("%s := %sVal.(map[string]interface{}) // TODO: or use your struct type\n", itemVar, itemVar)
+	}
+	g.generateMapBody(m, itemVar, item, elem)
+}
+
+// generateMapBody writes what the callback of a map returns, with the
+// item itemVar of struct item or scalar type elem in scope, and closes
+// the callback
+func (g *Generator) generateMapBody(m *parser.MapExpr, itemVar string, item *itemType, elem string) {
+	outerItem, outerIn, outerVar, outerMap, outerElem := g.currentItem, g.inMapBody, g.currentItemVar, g.currentMap, g.currentElem
+	g.currentItem, g.currentMap, g.currentElem = item, m, elem
+	defer func() { g.currentItem, g.currentMap, g.currentElem = outerItem, outerMap, outerElem }()
 	
 	// Check if body is a component call (returns mi.H) vs a builder call (returns mi.Node)
 	isComponentCall := false
 	if elem, ok := m.Body.(*parser.Element); ok {
-		isComponentCall = isComponentName(elem.Tag)
+		_, mapped := g.mapped[elem.Tag]
+		isComponentCall = isComponentName(elem.Tag) && !mapped
 	}
 	
 	if isComponentCall {
@@ -1308,8 +2354,8 @@ func (g *Generator) generateMap(m *parser.MapExpr, builder string) {
 		g.inMapBody = true
 		g.currentItemVar = itemVar
 		g.generateNode(m.Body, "b")
-		g.inMapBody = false
-		g.currentItemVar = ""
+		g.inMapBody = outerIn
+		g.currentItemVar = outerVar
 		g.write("\n")
 	} else {
 		// Builder calls need the func wrapper
@@ -1323,8 +2369,8 @@ func (g *Generator) generateMap(m *parser.MapExpr, builder string) {
 			g.inMapBody = true
 			g.currentItemVar = itemVar
 			g.generateNode(m.Body, "b")
-			g.inMapBody = false
-			g.currentItemVar = ""
+			g.inMapBody = outerIn
+			g.currentItemVar = outerVar
 		} else {
 			g.write("nil /* TODO: map body not parsed */")
 		}
@@ -1339,8 +2385,9 @@ func (g *Generator) generateMap(m *parser.MapExpr, builder string) {
 }
 
 func (g *Generator) generateConditional(c *parser.Conditional, builder string) {
-	g.usesIf = true
-
+	if g.generateAlpineShow(c.Condition, c.Consequent, nil, builder) {
+		return
+	}
 	condition := g.translateCondition(c.Condition)
 	g.writef("mi.If(%s, func(b *mi.Builder) mi.Node {\n", condition)
 	g.indent++
@@ -1354,8 +2401,9 @@ func (g *Generator) generateConditional(c *parser.Conditional, builder string) {
 }
 
 func (g *Generator) generateTernary(t *parser.Ternary, builder string) {
-	g.usesIfElse = true
-
+	if g.generateAlpineShow(t.Condition, t.Consequent, t.Alternate, builder) {
+		return
+	}
 	condition := g.translateCondition(t.Condition)
 	g.writef("mi.IfElse(%s,\n", condition)
 	g.indent++
@@ -1439,68 +2487,82 @@ func (g *Generator) generateTernary(t *parser.Ternary, builder string) {
 func (g *Generator) generateComponentArgs(elem *parser.Element) string {
 	var args []string
 	for _, attr := range elem.Attributes {
-		if attr.IsSpread {
-			continue
+		if isRenderProp(attr.Name) {
+			if arg, ok := g.renderPropArg(elem.Tag, attr); ok {
+				args = append(args, arg)
+				continue
+			}
 		}
-		// Skip React-specific attributes
-		if attr.Name == "key" || attr.Name == "ref" {
+		if arg, ok := g.compositeArg(attr.Expression.Raw, g.localParam(elem.Tag, attr.Name)); ok {
+			args = append(args, arg)
 			continue
 		}
-		if attr.Value != "" {
-			args = append(args, fmt.Sprintf("%q", attr.Value))
-		} else if attr.Expression.Raw != "" {
-			raw := attr.Expression.Raw
-			
-			// When in map body and the expression IS the item variable itself,
-			// pass it directly (not as a property access)
-			if g.inMapBody && raw == g.currentItemVar {
-				args = append(args, g.currentItemVar)
-				continue
-			}
-			
-			// When in map body and accessing item properties, 
-			// we can't know the target parameter type - infer from attr name
-			if g.inMapBody && isPropertyAccess(raw) {
-				parts := strings.Split(raw, ".")
-				if len(parts) >= 2 && parts[0] == g.currentItemVar {
-					fieldName := parts[1]
-					attrName := strings.ToLower(attr.Name)
-					
-					// Infer type from attribute name
-					if strings.HasPrefix(attrName, "is") || 
-						strings.Contains(attrName, "active") ||
-						strings.Contains(attrName, "enabled") ||
-						strings.Contains(attrName, "disabled") ||
-						strings.HasPrefix(attrName, "has") ||
-						strings.Contains(attrName, "show") ||
-						strings.Contains(attrName, "hidden") ||
-						strings.Contains(attrName, "visible") {
-						// Bool - use mi.Bool
-						args = append(args, fmt.Sprintf("mi.Bool(%s, %q)", 
-							parts[0], fieldName))
-					} else if strings.Contains(attrName, "count") ||
-						strings.Contains(attrName, "index") ||
-						strings.Contains(attrName, "num") ||
-						strings.Contains(attrName, "size") {
-						// Int - use mi.Int
-						args = append(args, fmt.Sprintf("mi.Int(%s, %q)",
-							parts[0], fieldName))
-					} else {
-						// String - use mi.Str
-						args = append(args, fmt.Sprintf("mi.Str(%s, %q)", 
-							parts[0], fieldName))
-					}
-					continue
-				}
-			}
-			args = append(args, g.translateExprValue(raw))
+		if arg, ok := g.componentArg(attr); ok {
+			args = append(args, arg)
 		}
 	}
 	return strings.Join(args, ", ")
 }
 
+// componentArg translates the value of a prop passed to a component. It
+// reports false for attributes that are not passed: spreads, key, ref and
+// valueless attributes.
+func (g *Generator) componentArg(attr parser.Attribute) (string, bool) {
+	if attr.IsSpread {
+		return "", false
+	}
+	// Skip React-specific attributes
+	if attr.Name == "key" || attr.Name == "ref" {
+		return "", false
+	}
+	if attr.Value != "" {
+		return fmt.Sprintf("%q", attr.Value), true
+	}
+	if attr.Expression.Raw == "" {
+		return "", false
+	}
+	raw := attr.Expression.Raw
+	
+	// When in map body and the expression IS the item variable itself,
+	// pass it directly (not as a property access)
+	if g.inMapBody && raw == g.currentItemVar {
+		return g.currentItemVar, true
+	}
+	
+	// When in map body and accessing item properties, 
+	// we can't know the target parameter type - infer from attr name
+	if g.inMapBody && isPropertyAccess(raw) {
+		parts := strings.Split(raw, ".")
+		if field, _, ok := g.itemField(raw); ok {
+			return field, true
+		}
+		if len(parts) >= 2 && parts[0] == g.currentItemVar {
+			fieldName := parts[1]
+			switch propNameType(attr.Name) {
+			case "bool":
+				return fmt.Sprintf("mi.Bool(%s, %q)", parts[0], fieldName), true
+			case "int":
+				return fmt.Sprintf("mi.Int(%s, %q)", parts[0], fieldName), true
+			}
+			// String - use mi.Str
+			return fmt.Sprintf("mi.Str(%s, %q)", parts[0], fieldName), true
+		}
+	}
+	return g.translateExprValue(raw), true
+}
+
 func (g *Generator) translateCondition(cond string) string {
 	cond = strings.TrimSpace(cond)
+
+	// A helper function or a constant of the file: isOverdue(task)
+	if call, ok := g.translateFileRef(cond); ok {
+		return goCond(call)
+	}
+
+	// A field error of the form: errors.email
+	if msg, ok := g.formError(cond); ok {
+		return msg + ` != ""`
+	}
 	
 	// Simple identifier - likely a boolean parameter
 	if isSimpleIdent(cond) {
@@ -1509,7 +2571,13 @@ func (g *Generator) translateCondition(cond string) string {
 			return goName
 		}
 		// Unknown boolean - return false with TODO
-		return fmt.Sprintf("false /* TODO: %s */", cond)
+		return fmt.Sprintf("false /* TODO: %s */", commentText(cond))
+	}
+
+
+	// Compound condition: a && b, a || b, (a), !(a)
+	if translated, ok := g.translateLogical(cond); ok {
+		return translated
 	}
 
 	// Property access from props
@@ -1524,15 +2592,26 @@ func (g *Generator) translateCondition(cond string) string {
 			base := parts[0]
 			field := parts[1]
 			// Check if base is known
+			if field, typ, ok := g.itemField(cond); ok {
+				return itemTruth(field, typ, false)
+			}
 			if g.inMapBody && base == g.currentItemVar {
 				return fmt.Sprintf("mi.Truthy(%s[%q])", base, field)
 			}
 			if g.objectParams != nil && g.objectParams[base] {
 				return fmt.Sprintf("mi.Truthy(%s[%q])", base, field)
 			}
+			if field, typ, ok := g.contextField(cond); ok && typ == "bool" {
+				return field
+			}
 		}
 	}
 	
+	// Counted children: React.Children.count(children) > 1
+	if translated, ok := g.translateChildrenCount(cond); ok {
+		return translated
+	}
+
 	// Numeric comparison: post.likes > 0, item.count >= 5
 	if numMatch := regexp.MustCompile(`^(\w+(?:\.\w+)?)\s*(>|>=|<|<=)\s*(\d+)$`).FindStringSubmatch(cond); numMatch != nil {
 		varExpr := numMatch[1]
@@ -1545,6 +2624,9 @@ func (g *Generator) translateCondition(cond string) string {
 				base := parts[0]
 				field := parts[1]
 				// Check if base is known
+				if field, _, ok := g.itemField(varExpr); ok {
+					return fmt.Sprintf("%s %s %s", field, op, val)
+				}
 				if (g.inMapBody && base == g.currentItemVar) || 
 					(g.objectParams != nil && g.objectParams[base]) {
 					// Use appropriate mi helper
@@ -1570,7 +2652,7 @@ func (g *Generator) translateCondition(cond string) string {
 			if strings.Contains(translated, "/* TODO") {
 				// Variable unknown - use false placeholder with original expression
 				cleanCond := strings.ReplaceAll(cond, "\"", "'")
-				return fmt.Sprintf("false /* TODO: %s */", cleanCond)
+				return fmt.Sprintf("false /* TODO: %s */", commentText(cleanCond))
 			}
 			return translated
 		}
@@ -1599,6 +2681,9 @@ func (g *Generator) translateCondition(cond string) string {
 			if len(parts) >= 2 {
 				base := parts[0]
 				field := parts[1]
+				if field, typ, ok := g.itemField(inner); ok {
+					return itemTruth(field, typ, true)
+				}
 				if (g.inMapBody && base == g.currentItemVar) || 
 					(g.objectParams != nil && g.objectParams[base]) {
 					return fmt.Sprintf("!mi.Truthy(%s[%q])", base, field)
@@ -1611,7 +2696,52 @@ func (g *Generator) translateCondition(cond string) string {
 	// This makes the code compile while indicating what needs fixing
 	cleanCond := strings.ReplaceAll(cond, "\"", "'")
 	cleanCond = strings.ReplaceAll(cleanCond, "\n", " ")
-	return fmt.Sprintf("false /* TODO: %s */", cleanCond)
+	return fmt.Sprintf("false /* TODO: %s */", commentText(cleanCond))
+}
+
+// translateLogical translates a condition built from &&, ||, ! and
+// parentheses operand by operand. It fails unless every operand
+// translates, so a partly understood condition is left as one TODO.
+func (g *Generator) translateLogical(cond string) (string, bool) {
+	e, err := parser.ParseJSExpr(cond)
+	if err != nil {
+		return "", false
+	}
+	operand := func(x parser.JSExpr) (string, bool) {
+		translated := g.translateCondition(x.Span().Text(cond))
+		return translated, !strings.Contains(translated, "/* TODO")
+	}
+	switch n := e.(type) {
+	case *parser.JSBinary:
+		if n.Op != "&&" && n.Op != "||" {
+			return "", false
+		}
+		left, ok := operand(n.Left)
+		if !ok {
+			return "", false
+		}
+		right, ok := operand(n.Right)
+		if !ok {
+			return "", false
+		}
+		return left + " " + n.Op + " " + right, true
+	case *parser.JSParen:
+		inner, ok := operand(n.X)
+		if !ok {
+			return "", false
+		}
+		return "(" + inner + ")", true
+	case *parser.JSUnary:
+		if _, paren := n.X.(*parser.JSParen); n.Op != "!" || !paren {
+			return "", false
+		}
+		inner, ok := operand(n.X)
+		if !ok {
+			return "", false
+		}
+		return "!" + inner, true
+	}
+	return "", false
 }
 
 // Helper methods
@@ -1728,8 +2858,19 @@ func tagToMethod(tag string) string {
 		return method
 	}
 
-	// Unknown tag - use El() helper
-	return fmt.Sprintf("El(%q)", tag)
+	// Unknown tag, custom elements among them: rendered with b.El
+	return ""
+}
+
+// boolAttrs are the minty attributes of HTML boolean attributes, which
+// take no argument
+var boolAttrs = map[string]bool{
+	"mi.Disabled": true, "mi.Readonly": true, "mi.Required": true,
+	"mi.Checked": true, "mi.Selected": true, "mi.Multiple": true,
+	"mi.Autofocus": true, "mi.Autoplay": true, "mi.Controls": true,
+	"mi.Loop": true, "mi.Muted": true, "mi.NoValidate": true,
+	"mi.Open": true, "mi.Reversed": true, "mi.Async": true,
+	"mi.Defer": true, "mi.Hidden": true,
 }
 
 func attrToMinty(attr string) string {
@@ -1826,6 +2967,7 @@ func attrToMinty(attr string) string {
 		"hx-select":    "mi.HtmxSelect",
 		"hx-confirm":   "mi.HtmxConfirm",
 		"hx-boost":     "mi.HtmxBoost",
+		"hx-include":   "mi.HtmxInclude",
 	}
 
 	if minty, ok := attrs[attr]; ok {
@@ -1879,6 +3021,14 @@ func isSimpleIdent(s string) bool {
 	return true
 }
 
+// exportName upper-cases the first letter: cartItems → CartItems
+func exportName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 func toCamelCase(s string) string {
 	// Convert kebab-case to camelCase
 	parts := strings.Split(s, "-")
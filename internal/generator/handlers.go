@@ -0,0 +1,346 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// HandlersFile is the Go file holding the generated htmx endpoint stubs
+const HandlersFile = "handlers.go"
+
+// Handler is an htmx endpoint that replaces a React event handler. The
+// element posts to it, and the stub re-renders the component with the
+// state the handler updates.
+type Handler struct {
+	Component string   `json:"component"`
+	Method    string   `json:"method"`   // GET or POST
+	Endpoint  string   `json:"endpoint"` // path, without the query string
+	Event     string   `json:"event"`    // onClick, onChange, ...
+	Tag       string   `json:"tag"`      // element the handler was on
+	Body      string   `json:"body"`     // the original handler
+	State     []Param  `json:"state,omitempty"`
+	Params    []Param  `json:"params,omitempty"` // the component's parameters, to re-render it
+	Form      string   `json:"form,omitempty"`   // decoder of the react-hook-form or Formik form posted
+	Submit    string   `json:"submit,omitempty"` // the form's submit function
+	Key       string   `json:"key,omitempty"`    // key of the list item the handler is in: item.id
+	KeyID     string   `json:"keyId,omitempty"`  // prefix of the item's id, when the handler is on the item and its key is its id
+	Refs      []string `json:"refs,omitempty"`   // elements of refs the handler acts on: "inputRef is #input-ref"
+	File      string   `json:"file,omitempty"`
+	Line      int      `json:"line"`
+}
+
+// Handlers returns the htmx endpoints generated in the last run, one per
+// method and path
+func (g *Generator) Handlers() []Handler {
+	return g.handlers
+}
+
+// handlerEndpoint returns the URL of the endpoint /component/action for
+// an event handler and records the endpoint for the handlers file
+func (g *Generator) handlerEndpoint(method, action string, handler *parser.EventHandler, tag string) string {
+	if name := namedHandler(handler.HandlerBody); name != "" {
+		action = toKebabCase(name)
+	}
+	endpoint := "/" + toKebabCase(g.component) + "/" + action
+	h := Handler{
+		Method:   method,
+		Endpoint: endpoint,
+		Event:    handler.EventType,
+		Tag:      tag,
+		Body:     handler.HandlerBody,
+		Line:     handler.LineNumber,
+	}
+	h.Key, h.KeyID = g.handlerKey()
+	h.Refs = g.refsIn(handler.HandlerBody)
+	states := map[string]bool{}
+	for _, setter := range handler.SetterCalls {
+		states[stateFromSetter(setter)] = true
+	}
+	if handler.EventType == "onSubmit" {
+		// The form posts its controlled fields along
+		for field := range g.controlled {
+			states[field] = true
+		}
+	}
+	g.recordHandler(h, states)
+	return endpoint
+}
+
+// recordHandler adds an endpoint of the current component to the handlers
+// file, reading the given state variables from the request. An endpoint
+// recorded before reads the new state variables too.
+func (g *Generator) recordHandler(h Handler, states map[string]bool) {
+	reads := make(map[string]bool, len(states))
+	for state := range states {
+		reads[toCamelCase(state)] = true
+	}
+
+	existing := -1
+	for i := range g.handlers {
+		if g.handlers[i].Method == h.Method && g.handlers[i].Endpoint == h.Endpoint {
+			existing = i
+			for _, p := range g.handlers[i].State {
+				reads[p.Name] = true
+			}
+		}
+	}
+
+	h.Component = g.component
+	if g.currentLine != 0 {
+		h.Line = g.currentLine
+	}
+	h.Params, h.State = nil, nil
+	for _, p := range g.componentArgs {
+		if p.Type == childrenType {
+			continue
+		}
+		h.Params = append(h.Params, p)
+		if reads[p.Name] && p.Prop == "" {
+			h.State = append(h.State, p)
+		}
+	}
+	if existing >= 0 {
+		g.handlers[existing].State = h.State
+		return
+	}
+	g.handlers = append(g.handlers, h)
+}
+
+// namedHandler returns the action of a handler passed by name, without
+// its handle or on prefix: handleClearCompleted → clearCompleted. It is ""
+// for inline handlers.
+func namedHandler(body string) string {
+	body = strings.TrimSpace(body)
+	if !handlerIdent.MatchString(body) {
+		return ""
+	}
+	for _, prefix := range []string{"handle", "on"} {
+		if rest := strings.TrimPrefix(body, prefix); rest != body && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			return strings.ToLower(rest[:1]) + rest[1:]
+		}
+	}
+	return body
+}
+
+var handlerIdent = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// stateFromSetter returns the state variable of a setter: setShowAll → showAll
+func stateFromSetter(setter string) string {
+	name := strings.TrimPrefix(setter, "set")
+	if name == "" {
+		return setter
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// HandlersSource returns a Go file with an http.HandlerFunc stub per htmx
+// endpoint and a RegisterHandlers function wiring them up, in package pkg
+func HandlersSource(pkg string, handlers []Handler) string {
+	out, _ := HandlersSourceTemplates(pkg, handlers, Templates{}, "")
+	return out
+}
+
+// HandlersSourceTemplates is like HandlersSource but shapes each stub with
+// the handler template of t, if it has one. When sessionImport names the
+// generated session package, session-backed state is read from and
+// written back to the visitor's session.
+func HandlersSourceTemplates(pkg string, handlers []Handler, t Templates, sessionImport string) (string, error) {
+	tmpls, err := t.parse()
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(handlers, func(i, j int) bool {
+		if handlers[i].Endpoint != handlers[j].Endpoint {
+			return handlers[i].Endpoint < handlers[j].Endpoint
+		}
+		return handlers[i].Method < handlers[j].Method
+	})
+	usesStrconv, usesSession := false, false
+	for _, h := range handlers {
+		for _, p := range h.State {
+			if p.Type == "int" {
+				usesStrconv = true
+			}
+		}
+		for _, p := range h.Params {
+			usesSession = usesSession || p.Session && sessionImport != ""
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// Generated by reminty - htmx endpoints for the React event handlers\n\n")
+	b.WriteString("import (\n\t\"net/http\"\n")
+	if usesStrconv {
+		b.WriteString("\t\"strconv\"\n")
+	}
+	b.WriteString("\n\tmi \"github.com/ha1tch/minty\"\n")
+	if usesSession {
+		fmt.Fprintf(&b, "\n\t%q\n", sessionImport)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// RegisterHandlers registers the htmx endpoints on mux\n")
+	b.WriteString("func RegisterHandlers(mux *http.ServeMux) {\n")
+	for _, h := range handlers {
+		fmt.Fprintf(&b, "\tmux.HandleFunc(%q, %s)\n", h.Method+" "+h.Endpoint, handlerName(h))
+	}
+	b.WriteString("}\n")
+
+	for _, h := range handlers {
+		where := fmt.Sprintf("line %d", h.Line)
+		if h.File != "" {
+			where = fmt.Sprintf("%s:%d", h.File, h.Line)
+		}
+		var doc, body strings.Builder
+		if h.Body == "" {
+			fmt.Fprintf(&doc, "// %s receives the <%s> %s at %s\n", handlerName(h), h.Tag, h.Event, where)
+		} else {
+			fmt.Fprintf(&doc, "// %s replaces the %s handler on <%s> at %s:\n//\n", handlerName(h), h.Event, h.Tag, where)
+			for _, line := range strings.Split(strings.TrimSpace(h.Body), "\n") {
+				fmt.Fprintf(&doc, "//\t%s\n", strings.TrimSpace(line))
+			}
+		}
+
+		updated := map[string]bool{}
+		var names []string
+		var saves strings.Builder
+		for _, p := range h.State {
+			updated[p.Name] = true
+			names = append(names, p.Name)
+			if p.Session && sessionImport != "" {
+				fmt.Fprintf(&saves, "\t%s\n", SessionSet(p.Name))
+			}
+			switch p.Type {
+			case "string":
+				fmt.Fprintf(&body, "\t%s := r.FormValue(%q)\n", p.Name, p.Name)
+			case "bool":
+				fmt.Fprintf(&body, "\t%s := r.FormValue(%q) == \"true\"\n", p.Name, p.Name)
+			case "int":
+				fmt.Fprintf(&body, "\t%s, _ := strconv.Atoi(r.FormValue(%q))\n", p.Name, p.Name)
+			default:
+				fmt.Fprintf(&body, "\tvar %s %s // TODO: decode %s from the request\n", p.Name, p.Type, p.Name)
+			}
+		}
+		args := func(errs string) string {
+			args := make([]string, len(h.Params))
+			for i, p := range h.Params {
+				switch {
+				case updated[p.Name]:
+					args[i] = p.Name
+				case h.Form != "" && p.Name == formErrorsParam:
+					args[i] = errs
+				case p.Session && sessionImport != "":
+					args[i] = SessionGet(p)
+				default:
+					args[i] = zeroValue(p.Type) + " /* TODO: " + p.Name + " */"
+				}
+			}
+			return strings.Join(args, ", ")
+		}
+		if h.Form != "" {
+			// A react-hook-form or Formik submission: decode and validate
+			// the fields, re-rendering the form with the errors if invalid
+			fmt.Fprintf(&body, "\tform, errs := %s(r)\n", h.Form)
+			body.WriteString("\tif len(errs) > 0 {\n\t\tw.WriteHeader(http.StatusUnprocessableEntity)\n")
+			fmt.Fprintf(&body, "\t\trenderComponent(w, %s(%s))\n\t\treturn\n\t}\n", h.Component, args("errs"))
+			if h.Submit != "" {
+				fmt.Fprintf(&body, "\t// TODO: port the submit handler (%s) with form\n", truncateExpr(h.Submit, 60))
+			} else {
+				body.WriteString("\t// TODO: port the submit handler with form\n")
+			}
+			body.WriteString("\t_ = form\n")
+			body.WriteString(saves.String())
+			fmt.Fprintf(&body, "\trenderComponent(w, %s(%s))\n", h.Component, args("nil"))
+		} else {
+			for _, ref := range h.Refs {
+				fmt.Fprintf(&body, "\t// TODO: %s in the page; act on it in the browser, e.g. with hx-on::after-request\n", ref)
+			}
+			switch {
+			case h.KeyID != "":
+				fmt.Fprintf(&body, "\t// The item keyed by %s is the triggering element: its id, in the HX-Trigger header, is %s<key>\n", h.Key, h.KeyID)
+			case h.Key != "":
+				fmt.Fprintf(&body, "\t// TODO: the handler is in the item keyed by %s; identify the item from the request\n", h.Key)
+			}
+			if len(names) > 0 {
+				fmt.Fprintf(&body, "\t// TODO: port the handler logic (state: %s)\n", strings.Join(names, ", "))
+			} else {
+				body.WriteString("\t// TODO: port the handler logic\n")
+			}
+			body.WriteString(saves.String())
+			fmt.Fprintf(&body, "\trenderComponent(w, %s(%s))\n", h.Component, args(""))
+		}
+
+		stub := HandlerData{Handler: h, Name: handlerName(h), Doc: doc.String(), Body: body.String()}
+		if tmpls.handler == nil {
+			fmt.Fprintf(&b, "\n%sfunc %s(w http.ResponseWriter, r *http.Request) {\n%s}\n", stub.Doc, stub.Name, stub.Body)
+			continue
+		}
+		b.WriteString("\n")
+		if err := tmpls.handler.Execute(&b, stub); err != nil {
+			return "", fmt.Errorf("handler template: %w", err)
+		}
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(`
+// renderComponent writes the re-rendered component for htmx to swap in
+func renderComponent(w http.ResponseWriter, h mi.H) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := mi.Render(h, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+`)
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String(), nil
+	}
+	return string(formatted), nil
+}
+
+func handlerName(h Handler) string {
+	name := "handle" + exportName(h.Component)
+	for _, part := range strings.Split(strings.Trim(h.Endpoint[len(toKebabCase(h.Component))+1:], "/"), "-") {
+		name += exportName(part)
+	}
+	return name
+}
+
+// SessionGet returns the expression reading session-backed state p in a
+// handler: session.Get[[]Item](r, "cart"). The type argument names the
+// type in the handler's package, which the session package cannot.
+func SessionGet(p Param) string {
+	return fmt.Sprintf("session.Get[%s](r, %q)", p.Type, p.Name)
+}
+
+// SessionSet returns the statement of a handler storing the session-backed
+// state variable name: session.Set(w, r, "cart", cart)
+func SessionSet(name string) string {
+	return fmt.Sprintf("session.Set(w, r, %q, %s)", name, name)
+}
+
+// zeroValue returns the zero value of a generated parameter type
+func zeroValue(typ string) string {
+	switch typ {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "float64":
+		return "0"
+	}
+	if isSimpleIdent(typ) {
+		// A generated struct type, such as a context value
+		return typ + "{}"
+	}
+	return "nil"
+}
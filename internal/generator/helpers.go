@@ -0,0 +1,586 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// helperFunc is a plain function of the file, translated to Go or, where
+// its body has no translation, stubbed with its signature
+type helperFunc struct {
+	src    *parser.Helper
+	name   string // the Go name, src.Name unless another file of the package declares it
+	params []Param
+	result string   // Go result type
+	body   []string // lines of the translated body; nil for a stub
+	state  int      // unresolved, resolving or resolved
+}
+
+const (
+	unresolved = iota
+	resolving
+	resolved
+)
+
+// resolveHelpers records the file's helper functions, translated the
+// first time they are called or written
+func (g *Generator) resolveHelpers(file *parser.File) {
+	g.helpers = map[string]*helperFunc{}
+	for i := range file.Helpers {
+		h := &helperFunc{src: &file.Helpers[i], name: file.Helpers[i].Name}
+		if len(file.Components) > 0 {
+			h.name = g.declare(h.name, file.Components[0].Name)
+		}
+		g.helpers[h.src.Name] = h
+	}
+}
+
+// helper returns the helper function name, translated, or nil if the
+// file has none by that name or it is being translated: a recursive
+// helper is stubbed
+func (g *Generator) helper(name string) *helperFunc {
+	h := g.helpers[name]
+	if h == nil || h.state == resolving {
+		return nil
+	}
+	if h.state == unresolved {
+		h.state = resolving
+		g.translateHelper(h)
+		h.state = resolved
+	}
+	return h
+}
+
+// translateHelper works out the signature of a helper and translates its
+// body, leaving body nil if any of it has no translation
+func (g *Generator) translateHelper(h *helperFunc) {
+	scope := jsScope{}
+	for i, prop := range h.src.Params {
+		p := Param{Name: goLocal(prop.Name), Type: helperParamType(h.src, prop)}
+		if !plainName.MatchString(prop.Name) {
+			// A destructured parameter: the stub takes the object
+			p = Param{Name: fmt.Sprintf("arg%d", i), Type: "map[string]interface{}", Prop: prop.Name}
+			scope = nil
+		} else if scope != nil {
+			scope[prop.Name] = p.Type
+		}
+		h.params = append(h.params, p)
+	}
+	h.result = helperResultType(h.src)
+	if scope == nil || h.src.Async {
+		return
+	}
+
+	if !h.src.Block {
+		e, err := parser.ParseJSExpr(h.src.Body)
+		if err != nil {
+			return
+		}
+		x, ok := g.jsToGo(e, h.src.Body, scope)
+		if !ok || x.typ == "nil" || x.typ == "" {
+			return
+		}
+		h.result = concreteType(x.typ, x)
+		h.body = []string{"return " + x.code}
+		return
+	}
+
+	stmts, err := parser.ParseJSStatements(h.src.Body)
+	if err != nil || !terminates(stmts) {
+		return
+	}
+	var returns []goExpr
+	body, ok := g.stmtsToGo(stmts, scope, &returns)
+	if !ok || len(returns) == 0 {
+		return
+	}
+	typ := returns[0].typ
+	for _, r := range returns[1:] {
+		if typ, ok = unifyTypes(typ, r.typ); !ok {
+			return
+		}
+	}
+	if typ == "nil" || typ == "" {
+		return
+	}
+	h.result, h.body = concreteType(typ, returns...), body
+}
+
+// plainName matches a JavaScript identifier that is a valid Go one
+var plainName = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// stmtsToGo translates the statements of a helper body, one Go line per
+// entry, collecting the values it returns
+func (g *Generator) stmtsToGo(stmts []parser.JSStmt, scope jsScope, returns *[]goExpr) ([]string, bool) {
+	var lines []string
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *parser.JSReturn:
+			if s.Value == nil {
+				return nil, false
+			}
+			x, ok := g.jsToGo(s.Value, s.Src, scope)
+			if !ok {
+				return nil, false
+			}
+			*returns = append(*returns, x)
+			lines = append(lines, "return "+x.code)
+
+		case *parser.JSDecl:
+			if s.Value == nil || !plainName.MatchString(s.Name) {
+				return nil, false
+			}
+			x, ok := g.jsToGo(s.Value, s.Src, scope)
+			if !ok || x.typ == "nil" || x.typ == "" {
+				return nil, false
+			}
+			typ := concreteType(x.typ, x)
+			if x.typ == untypedNumber && typ == "float64" || s.Kind != "const" && x.typ == untypedNumber {
+				// let total = 0 may add fractions later
+				typ = "float64"
+				lines = append(lines, fmt.Sprintf("var %s %s = %s", goLocal(s.Name), typ, x.code))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s := %s", goLocal(s.Name), x.code))
+			}
+			scope = scope.with(s.Name, typ)
+
+		case *parser.JSIf:
+			chain, ok := g.ifToGo(s, scope, returns)
+			if !ok {
+				return nil, false
+			}
+			lines = append(lines, chain...)
+
+		case *parser.JSExprStmt:
+			assign, ok := s.X.(*parser.JSAssign)
+			if !ok {
+				return nil, false
+			}
+			target, ok := assign.Target.(*parser.JSIdent)
+			if !ok {
+				return nil, false
+			}
+			typ, declared := scope[target.Name]
+			x, ok := g.jsToGo(assign.Value, s.Src, scope)
+			if !declared || !ok {
+				return nil, false
+			}
+			switch assign.Op {
+			case "=":
+				if _, ok := unifyTypes(typ, x.typ); !ok {
+					return nil, false
+				}
+			case "+=", "-=", "*=", "/=":
+				if !isNumeric(typ) && !(typ == "string" && assign.Op == "+=" && x.typ == "string") {
+					return nil, false
+				}
+				if isNumeric(typ) && !isNumeric(x.typ) {
+					return nil, false
+				}
+			default:
+				return nil, false
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s", goLocal(target.Name), assign.Op, numericAs(x, typ, x.code)))
+
+		default:
+			return nil, false
+		}
+	}
+	return lines, true
+}
+
+// ifToGo translates an if statement, an else holding a single if
+// becoming an else if
+func (g *Generator) ifToGo(s *parser.JSIf, scope jsScope, returns *[]goExpr) ([]string, bool) {
+	test, ok := g.jsToGo(s.Test, s.Src, scope)
+	if !ok {
+		return nil, false
+	}
+	then, ok := g.stmtsToGo(s.Then, scope, returns)
+	if !ok {
+		return nil, false
+	}
+	lines := []string{"if " + goCond(test) + " {"}
+	lines = append(lines, indentLines(then)...)
+	switch {
+	case len(s.Else) == 1:
+		if next, isIf := s.Else[0].(*parser.JSIf); isIf {
+			chain, ok := g.ifToGo(next, scope, returns)
+			if !ok {
+				return nil, false
+			}
+			lines = append(lines, "} else "+chain[0])
+			return append(lines, chain[1:]...), true
+		}
+		fallthrough
+	case len(s.Else) > 1:
+		els, ok := g.stmtsToGo(s.Else, scope, returns)
+		if !ok {
+			return nil, false
+		}
+		lines = append(lines, "} else {")
+		lines = append(lines, indentLines(els)...)
+	}
+	return append(lines, "}"), true
+}
+
+// indentLines indents lines by a tab
+func indentLines(lines []string) []string {
+	indented := make([]string, len(lines))
+	for i, line := range lines {
+		indented[i] = "\t" + line
+	}
+	return indented
+}
+
+// terminates reports whether statements always end in a return, as Go
+// requires of a function with a result
+func terminates(stmts []parser.JSStmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	switch s := stmts[len(stmts)-1].(type) {
+	case *parser.JSReturn:
+		return true
+	case *parser.JSIf:
+		return terminates(s.Then) && terminates(s.Else)
+	}
+	return false
+}
+
+// helperParamType returns the Go type of a helper parameter: its
+// TypeScript type or the type of its default, else what the body does
+// with it, as a string or a number, else interface{}
+func helperParamType(h *parser.Helper, prop parser.Prop) string {
+	switch prop.JSType {
+	case "string":
+		return "string"
+	case "number":
+		return "float64"
+	case "bool", "boolean":
+		return "bool"
+	case "array":
+		return "[]interface{}"
+	case "object":
+		return "map[string]interface{}"
+	}
+
+	typ := ""
+	use := func(t string) {
+		if typ != "" && typ != t {
+			typ = "interface{}"
+			return
+		}
+		typ = t
+	}
+	isParam := func(e parser.JSExpr) bool {
+		id, ok := parser.Unparen(e).(*parser.JSIdent)
+		return ok && id.Name == prop.Name
+	}
+	isNumber := func(e parser.JSExpr) bool {
+		lit, ok := parser.Unparen(e).(*parser.JSLiteral)
+		return ok && lit.Kind == "number"
+	}
+	for _, e := range helperExprs(h) {
+		parser.WalkJS(e, func(e parser.JSExpr) bool {
+			switch n := e.(type) {
+			case *parser.JSCall:
+				if m, ok := n.Callee.(*parser.JSMember); ok && m.Index == nil && isParam(m.Object) {
+					if _, ok := goStringMethods[m.Property]; ok || m.Property == "replace" {
+						use("string")
+					} else if m.Property == "toFixed" {
+						use("float64")
+					}
+				}
+				if m, ok := n.Callee.(*parser.JSMember); ok && parser.MemberPath(m.Object) == "Math" {
+					for _, arg := range n.Args {
+						if isParam(arg) {
+							use("float64")
+						}
+					}
+				}
+			case *parser.JSBinary:
+				switch n.Op {
+				case "-", "*", "/", "%":
+					if isParam(n.Left) || isParam(n.Right) {
+						use("float64")
+					}
+				case "<", ">", "<=", ">=", "===", "!==", "==", "!=":
+					if isParam(n.Left) && isNumber(n.Right) || isParam(n.Right) && isNumber(n.Left) {
+						use("float64")
+					}
+				}
+			}
+			return true
+		})
+	}
+	if typ == "" {
+		return "interface{}"
+	}
+	return typ
+}
+
+// helperExprs returns the expressions of a helper's body
+func helperExprs(h *parser.Helper) []parser.JSExpr {
+	if !h.Block {
+		if e, err := parser.ParseJSExpr(h.Body); err == nil {
+			return []parser.JSExpr{e}
+		}
+		return nil
+	}
+	stmts, err := parser.ParseJSStatements(h.Body)
+	if err != nil {
+		return nil
+	}
+	var exprs []parser.JSExpr
+	var walk func([]parser.JSStmt)
+	walk = func(stmts []parser.JSStmt) {
+		for _, stmt := range stmts {
+			switch s := stmt.(type) {
+			case *parser.JSReturn:
+				if s.Value != nil {
+					exprs = append(exprs, s.Value)
+				}
+			case *parser.JSDecl:
+				if s.Value != nil {
+					exprs = append(exprs, s.Value)
+				}
+			case *parser.JSIf:
+				exprs = append(exprs, s.Test)
+				walk(s.Then)
+				walk(s.Else)
+			case *parser.JSExprStmt:
+				exprs = append(exprs, s.X)
+			}
+		}
+	}
+	walk(stmts)
+	return exprs
+}
+
+// helperResultType guesses the result type of a helper whose body is not
+// translated: mi.H for one returning JSX, the type of a returned literal,
+// bool for a predicate named isX or hasX, else interface{}
+func helperResultType(h *parser.Helper) string {
+	if strings.Contains(h.Body, "</") || strings.Contains(h.Body, "/>") {
+		return "mi.H"
+	}
+	if !h.Block {
+		if e, err := parser.ParseJSExpr(h.Body); err == nil {
+			if typ := returnedType(e); typ != "" {
+				return typ
+			}
+		}
+	} else if stmts, err := parser.ParseJSStatements(h.Body); err == nil {
+		for _, stmt := range stmts {
+			if r, ok := stmt.(*parser.JSReturn); ok && r.Value != nil {
+				if typ := returnedType(r.Value); typ != "" {
+					return typ
+				}
+			}
+		}
+	}
+	for _, prefix := range []string{"is", "has", "can", "should"} {
+		if rest := strings.TrimPrefix(h.Name, prefix); rest != h.Name && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			return "bool"
+		}
+	}
+	return "interface{}"
+}
+
+// returnedType returns the Go type of a returned expression that makes
+// its type plain: a literal, a template, a comparison or a string method
+func returnedType(e parser.JSExpr) string {
+	switch n := parser.Unparen(e).(type) {
+	case *parser.JSLiteral:
+		switch n.Kind {
+		case "string":
+			return "string"
+		case "bool":
+			return "bool"
+		case "number":
+			return "float64"
+		}
+	case *parser.JSTemplate:
+		return "string"
+	case *parser.JSUnary:
+		if n.Op == "!" {
+			return "bool"
+		}
+	case *parser.JSBinary:
+		switch n.Op {
+		case "==", "!=", "===", "!==", "<", ">", "<=", ">=":
+			return "bool"
+		case "+":
+			if returnedType(n.Left) == "string" || returnedType(n.Right) == "string" {
+				return "string"
+			}
+		}
+	case *parser.JSCall:
+		if m, ok := n.Callee.(*parser.JSMember); ok {
+			switch m.Property {
+			case "toFixed", "join", "toLowerCase", "toUpperCase", "trim", "replace", "replaceAll", "toLocaleString", "toString":
+				return "string"
+			}
+		}
+	}
+	return ""
+}
+
+// call returns the call of the helper with translated arguments, which
+// must fit its parameters; missing ones take their defaults
+func (h *helperFunc) call(args []goExpr) (goExpr, bool) {
+	if len(args) > len(h.params) {
+		return goExpr{}, false
+	}
+	codes := make([]string, len(h.params))
+	for i, p := range h.params {
+		if i >= len(args) {
+			def := h.src.Params[i].DefaultValue
+			e, err := parser.ParseJSExpr(def)
+			if def == "" || err != nil {
+				return goExpr{}, false
+			}
+			x, ok := (&Generator{}).jsToGo(e, def, jsScope{})
+			if !ok {
+				return goExpr{}, false
+			}
+			args = append(args, x)
+		}
+		code, ok := convertArg(args[i], p.Type)
+		if !ok {
+			return goExpr{}, false
+		}
+		codes[i] = code
+	}
+	return goExpr{h.name + "(" + strings.Join(codes, ", ") + ")", h.result}, true
+}
+
+// convertArg returns the Go code passing x as a parameter of type typ
+func convertArg(x goExpr, typ string) (string, bool) {
+	switch {
+	case x.typ == typ, typ == "interface{}":
+		return x.code, true
+	case x.typ == untypedNumber && isNumeric(typ):
+		return numericAs(x, typ, x.code), true
+	case x.typ == "int" && typ == "float64":
+		return "float64(" + x.code + ")", true
+	case x.typ == "nil" && nillable(typ):
+		return "nil", true
+	}
+	return "", false
+}
+
+// translateHelperCall translates a call of one of the file's helper
+// functions in the markup: formatPrice(item.price)
+func (g *Generator) translateHelperCall(raw string) (goExpr, bool) {
+	e, err := parser.ParseJSExpr(strings.TrimSpace(raw))
+	if err != nil {
+		return goExpr{}, false
+	}
+	call, ok := parser.Unparen(e).(*parser.JSCall)
+	if !ok || call.New || call.Optional {
+		return goExpr{}, false
+	}
+	callee, ok := call.Callee.(*parser.JSIdent)
+	if !ok || g.currentParams[callee.Name] {
+		return goExpr{}, false
+	}
+	h := g.helper(callee.Name)
+	if h == nil || len(call.Args) > len(h.params) {
+		return goExpr{}, false
+	}
+	args := make([]goExpr, len(call.Args))
+	for i, arg := range call.Args {
+		x, ok := g.markupArg(arg, strings.TrimSpace(arg.Span().Text(raw)), h.params[i].Type)
+		if !ok {
+			return goExpr{}, false
+		}
+		args[i] = x
+	}
+	return h.call(args)
+}
+
+// markupArg translates an argument of a helper called in the markup to
+// a value of type typ. Fields of items held in maps are read with the
+// accessor of the type: mi.Float(item, "price").
+func (g *Generator) markupArg(e parser.JSExpr, src, typ string) (goExpr, bool) {
+	if x, ok := g.jsToGo(e, src, g.markupScope()); ok && (x.typ != "interface{}" || typ == "interface{}") {
+		return x, true
+	}
+	base, field, ok := strings.Cut(parser.MemberPath(e), ".")
+	if !ok || strings.Contains(field, ".") {
+		return goExpr{}, false
+	}
+	if !(g.inMapBody && g.currentItem == nil && base == g.currentItemVar) && !g.objectParams[base] {
+		return goExpr{}, false
+	}
+	base = toCamelCase(base)
+	switch typ {
+	case "string":
+		return goExpr{fmt.Sprintf("mi.Str(%s, %q)", base, field), typ}, true
+	case "int":
+		return goExpr{fmt.Sprintf("mi.Int(%s, %q)", base, field), typ}, true
+	case "float64":
+		return goExpr{fmt.Sprintf("mi.Float(%s, %q)", base, field), typ}, true
+	case "bool":
+		return goExpr{fmt.Sprintf("mi.Bool(%s, %q)", base, field), typ}, true
+	}
+	return goExpr{fmt.Sprintf("%s[%q]", base, field), "interface{}"}, true
+}
+
+// markupScope returns the names markup can refer to with a known type:
+// the component's parameters and the item being mapped
+func (g *Generator) markupScope() jsScope {
+	scope := jsScope{}
+	for _, p := range g.componentArgs {
+		if p.Type != "" && p.Type != childrenType {
+			scope[p.Name] = p.Type
+		}
+	}
+	for name, typ := range g.mapLocals {
+		scope[name] = typ
+	}
+	if g.inMapBody && g.currentItemVar != "" {
+		if g.currentElem != "" {
+			scope[g.currentItemVar] = g.currentElem
+		} else if g.currentItem != nil {
+			scope[g.currentItemVar] = g.currentItem.name
+		} else {
+			scope[g.currentItemVar] = "map[string]interface{}"
+		}
+	}
+	return scope
+}
+
+// writeHelpers writes the file's helper functions, translated or as
+// stubs showing the original to port
+func (g *Generator) writeHelpers(file *parser.File) {
+	for i := range file.Helpers {
+		h := g.helper(file.Helpers[i].Name)
+		if h == nil {
+			continue
+		}
+		if h.body != nil {
+			g.writef("// %s is translated from the helper function at line %d\n", h.name, h.src.LineNumber)
+		} else {
+			g.writef("// %s stands in for the helper function at line %d, to port by hand:\n//\n", h.name, h.src.LineNumber)
+			for _, line := range strings.Split(h.src.Source, "\n") {
+				g.writef("//\t%s\n", strings.TrimRight(line, " \t"))
+			}
+		}
+		g.writef("func %s(%s) %s {\n", h.name, joinParams(h.params), h.result)
+		if h.body == nil {
+			g.writef("\t// TODO: port %s\n", h.src.Name)
+			g.writef("\treturn %s\n", zeroValue(h.result))
+		}
+		for _, line := range h.body {
+			g.writef("\t%s\n", line)
+		}
+		g.writeln("}")
+		g.writeln("")
+	}
+}
@@ -0,0 +1,318 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// hookResult is a value a custom hook's helper returns. Functions the hook
+// returns, such as setters, only make sense in the browser and are left
+// out.
+type hookResult struct {
+	index int    // position in the hook's return value
+	name  string // object key, or the identifier returned
+	value string // returned expression
+	typ   string
+}
+
+// hookComponent returns a component standing for a custom hook, so that
+// its state, fetches and derived values are generated like a component's
+func hookComponent(h *parser.CustomHook) *parser.Component {
+	return &parser.Component{
+		Name:        h.Name,
+		Props:       h.Params,
+		StateVars:   h.StateVars,
+		DerivedVars: h.DerivedVars,
+		Fetches:     h.Fetches,
+		LineNumber:  h.LineNumber,
+	}
+}
+
+// hookResults returns the values the helper of a custom hook returns
+func (g *Generator) hookResults(h *parser.CustomHook) []hookResult {
+	params := g.generateParams(h.Params)
+	var results []hookResult
+	for i, v := range h.Returns {
+		if v.Func || v.Value == "" {
+			continue
+		}
+		results = append(results, hookResult{index: i, name: v.Name, value: v.Value, typ: g.hookValueType(h, params, v.Value)})
+	}
+	return results
+}
+
+// hookValueType returns the Go type of a value a custom hook returns: a
+// state variable, a derived value, a parameter of the hook or a value
+// another hook returned
+func (g *Generator) hookValueType(h *parser.CustomHook, params []Param, value string) string {
+	paramType := func(name string) string {
+		for _, p := range params {
+			if p.Name == toCamelCase(name) {
+				return p.Type
+			}
+		}
+		return ""
+	}
+	for _, sv := range h.StateVars {
+		if sv.Name != value {
+			continue
+		}
+		// useState(initial) takes the type of the parameter
+		if typ := paramType(sv.InitValue); typ != "" && (sv.InitType == "" || sv.InitType == "interface{}") {
+			return typ
+		}
+		return stateType(hookComponent(h), value)
+	}
+	for _, dv := range h.DerivedVars {
+		if dv.Name == value && dv.ResultType != "" {
+			return dv.ResultType
+		}
+	}
+	if typ := paramType(value); typ != "" {
+		return typ
+	}
+	for _, call := range h.HookCalls {
+		if callee := g.hooks[call.Hook]; callee != nil && callee != h {
+			for _, r := range g.hookResults(callee) {
+				if boundName(call, callee, r) == value {
+					return r.typ
+				}
+			}
+		}
+	}
+	return "interface{}"
+}
+
+// writeHooks writes a Go helper function per custom hook of the file. The
+// helper computes what the hook returns on the first render: its state at
+// the initial value and its fetched data loaded. Setters, other returned
+// functions and effects run in the browser and are left as TODOs.
+func (g *Generator) writeHooks(file *parser.File) {
+	for i := range file.CustomHooks {
+		h := &file.CustomHooks[i]
+		comp := hookComponent(h)
+		params := g.generateParams(h.Params)
+		results := g.hookResults(h)
+
+		returned := map[string]bool{}
+		for _, r := range results {
+			returned[r.value] = true
+		}
+		read := func(name string) bool { return returned[name] }
+
+		g.currentParams = map[string]bool{}
+		g.objectParams = map[string]bool{}
+		for _, p := range params {
+			g.currentParams[p.Name] = true
+		}
+		for _, sv := range h.StateVars {
+			g.currentParams[sv.Name] = true
+			g.currentParams[toCamelCase(sv.Name)] = true
+		}
+		g.component, g.componentArgs = h.Name, params
+
+		types := make([]string, len(results))
+		for i, r := range results {
+			types[i] = r.typ
+		}
+		signature := strings.Join(types, ", ")
+		if len(results) > 1 {
+			signature = "(" + signature + ")"
+		}
+
+		g.writef("// %s replaces the custom hook at line %d. It returns what the hook\n", h.Name, h.LineNumber)
+		g.writeln("// returns on the first render, computed on the server.")
+		g.writef("func %s(%s) %s {\n", h.Name, joinParams(params), signature)
+		g.indent++
+
+		g.generateHookCalls(h.HookCalls, read)
+
+		fetched := fetchedState(comp)
+		for _, sv := range h.StateVars {
+			if fetched[sv.Name] {
+				continue
+			}
+			g.writeIndent()
+			name := toCamelCase(sv.Name)
+			if init, ok := g.hookInitValue(sv, params); ok {
+				g.writef("%s := %s\n", name, init)
+			} else if init, ok := g.compositeArg(sv.InitValue, g.hookValueType(h, params, sv.Name)); ok {
+				g.writef("%s := %s\n", name, init)
+			} else {
+				g.writef("var %s %s // initially %s\n", name, g.hookValueType(h, params, sv.Name), truncateExpr(sv.InitValue, 40))
+			}
+			if !read(sv.Name) && !derivedFrom(h, sv.Name) {
+				g.writeIndent()
+				g.writef("_ = %s\n", name)
+			}
+		}
+
+		g.generateFetches(comp, read)
+
+		g.derived = nil
+		for _, dv := range h.DerivedVars {
+			// What the hook returns has the type its signature declares
+			g.generateDerivedVar(dv, !read(dv.Name))
+			g.currentParams[dv.Name] = true
+			if !read(dv.Name) {
+				g.writeIndent()
+				g.writef("_ = %s\n", toCamelCase(dv.Name))
+			}
+		}
+
+		for _, line := range h.Effects {
+			g.writeIndent()
+			g.writef("// TODO: the useEffect at line %d runs in the browser\n", line)
+		}
+		for _, v := range h.Returns {
+			if v.Func {
+				g.writeIndent()
+				g.writef("// TODO: %s runs in the browser; where a component calls it, it becomes an htmx endpoint\n", v.Value)
+			}
+		}
+
+		values := make([]string, len(results))
+		for i, r := range results {
+			values[i] = toCamelCase(r.value)
+			if !handlerIdent.MatchString(r.value) || !g.currentParams[r.value] {
+				values[i] = zeroValue(r.typ) + " /* TODO: " + truncateExpr(r.value, 40) + " */"
+			}
+		}
+		if len(results) > 0 {
+			g.writeIndent()
+			g.writef("return %s\n", strings.Join(values, ", "))
+		}
+
+		g.indent--
+		g.writeln("}")
+		g.writeLoaders(comp)
+		g.writeln("")
+	}
+	g.currentParams, g.objectParams, g.component, g.componentArgs = nil, nil, "", nil
+}
+
+// derivedFrom reports whether a derived value of the hook is computed
+// from a state variable
+func derivedFrom(h *parser.CustomHook, state string) bool {
+	for _, dv := range h.DerivedVars {
+		if dv.SourceVar == state {
+			return true
+		}
+	}
+	return false
+}
+
+// hookInitValue returns the initial value of a custom hook's state as a Go
+// expression: a parameter of the hook, or a number, string or boolean
+// literal. ok is false for other values, which start at the zero value.
+func (g *Generator) hookInitValue(sv parser.StateVariable, params []Param) (string, bool) {
+	for _, p := range params {
+		if p.Name == toCamelCase(sv.InitValue) {
+			return p.Name, true
+		}
+	}
+	return goLiteral(sv.InitValue)
+}
+
+// goLiteral translates a JavaScript number, string or boolean literal
+func goLiteral(src string) (string, bool) {
+	e, err := parser.ParseJSExpr(src)
+	if err != nil {
+		return "", false
+	}
+	lit, ok := parser.Unparen(e).(*parser.JSLiteral)
+	if !ok {
+		return "", false
+	}
+	switch lit.Kind {
+	case "number", "bool":
+		return lit.Value, true
+	case "string":
+		return strconv.Quote(lit.Value), true
+	}
+	return "", false
+}
+
+// generateHookCalls calls the helpers of the custom hooks a component or
+// hook uses, binding the results read to the names the hook call
+// destructured them into
+func (g *Generator) generateHookCalls(calls []parser.HookCall, read func(name string) bool) {
+	if len(calls) == 0 || g.hooks == nil {
+		return
+	}
+	for _, call := range calls {
+		h := g.hooks[call.Hook]
+		if h == nil || h.Name == g.component {
+			continue
+		}
+		results := g.hookResults(h)
+
+		lhs := make([]string, len(results))
+		bound := false
+		for i, r := range results {
+			lhs[i] = "_"
+			if name := boundName(call, h, r); name != "" && read(name) {
+				lhs[i] = toCamelCase(name)
+				bound = true
+				g.currentParams[name] = true
+				g.currentParams[toCamelCase(name)] = true
+			}
+		}
+
+		// Arguments by parameter; missing ones take the default value
+		var args []string
+		for _, p := range g.generateParams(h.Params) {
+			arg := ""
+			for i, hp := range h.Params {
+				if hp.Name != p.Prop {
+					continue
+				}
+				switch {
+				case i < len(call.Args):
+					arg = call.Args[i]
+				case hp.DefaultValue != "":
+					arg = hp.DefaultValue
+				}
+			}
+			if lit, ok := goLiteral(arg); ok {
+				args = append(args, lit)
+			} else if lit, ok := g.compositeArg(arg, p.Type); ok {
+				args = append(args, lit)
+			} else if arg == "" || arg == "null" || arg == "undefined" {
+				args = append(args, zeroValue(p.Type))
+			} else {
+				args = append(args, g.translateExprValue(arg))
+			}
+		}
+
+		g.writeIndent()
+		helper := h.Name + "(" + strings.Join(args, ", ") + ")"
+		switch {
+		case len(results) == 0:
+			g.writef("%s\n", helper)
+		case bound:
+			g.writef("%s := %s\n", strings.Join(lhs, ", "), helper)
+		default:
+			g.writef("%s = %s\n", strings.Join(lhs, ", "), helper)
+		}
+	}
+	g.writeln("")
+}
+
+// boundName returns the local name a hook call binds a result to, or ""
+func boundName(call parser.HookCall, h *parser.CustomHook, r hookResult) string {
+	if h.ReturnKind == "object" {
+		for i, key := range call.Keys {
+			if key == r.name {
+				return call.Names[i]
+			}
+		}
+		return ""
+	}
+	if r.index < len(call.Names) {
+		return call.Names[r.index]
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// htmxAttrs are the hx-* attributes written with mi.Attr, which minty
+// has no option function for. hx-vals and hx-headers take JSON.
+var htmxAttrs = map[string]bool{
+	"hx-vals": true, "hx-headers": true, "hx-ext": true, "hx-sync": true,
+	"hx-params": true, "hx-sse": true, "hx-ws": true, "hx-encoding": true,
+	"hx-disabled-elt": true, "hx-disinherit": true, "hx-history": true,
+	"hx-history-elt": true, "hx-preserve": true, "hx-prompt": true,
+	"hx-replace-url": true, "hx-request": true, "hx-select-oob": true,
+	"hx-swap-oob": true, "hx-validate": true, "hx-disable": true,
+	"hx-inherit": true,
+}
+
+// isHtmxAttr reports whether name is an htmx attribute written with
+// mi.Attr: one of htmxAttrs, an hx-on:* handler or an attribute of the
+// SSE and WebSocket extensions, sse-connect, ws-send
+func isHtmxAttr(name string) bool {
+	return htmxAttrs[name] || strings.HasPrefix(name, "hx-on:") || strings.HasPrefix(name, "hx-on-") ||
+		strings.HasPrefix(name, "sse-") || strings.HasPrefix(name, "ws-")
+}
+
+// generateHtmxAttr generates an htmx attribute as it is written, with the
+// value of hx-vals and hx-headers as JSON: hx-vals={{ kind: "row" }} →
+// mi.Attr("hx-vals", "{\"kind\":\"row\"}"). It reports false for
+// attributes that are not htmx attributes written with mi.Attr.
+func (g *Generator) generateHtmxAttr(name string, attr *parser.Attribute) bool {
+	if !isHtmxAttr(name) {
+		return false
+	}
+	raw := strings.TrimSpace(attr.Expression.Raw)
+	switch {
+	case attr.Value != "":
+		g.writef("mi.Attr(%q, %q)", name, attr.Value)
+	case raw == "":
+		g.writef("mi.Attr(%q, \"\")", name)
+	case name == "hx-vals" || name == "hx-headers":
+		g.writef("mi.Attr(%q, %s)", name, g.htmxJSON(raw))
+	default:
+		g.writef("mi.Attr(%q, %s)", name, g.dataValue(raw))
+	}
+	return true
+}
+
+// htmxJSON returns the Go string of a JSON attribute value given as an
+// object, { id: item.id, kind: "row" }, or as JSON.stringify of one. An
+// object of literals becomes a JSON string literal; one with expressions
+// is marshalled where it is rendered.
+func (g *Generator) htmxJSON(raw string) string {
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return g.dataValue(raw)
+	}
+	e = parser.Unparen(e)
+	if call, ok := e.(*parser.JSCall); ok && len(call.Args) == 1 && parser.MemberPath(call.Callee) == "JSON.stringify" {
+		e = parser.Unparen(call.Args[0])
+	}
+	obj, ok := e.(*parser.JSObject)
+	if !ok {
+		value := g.translateExprValue(strings.TrimSpace(e.Span().Text(raw)))
+		if g.stringValued(raw, value) {
+			return value
+		}
+		return fmt.Sprintf("func() string { v, _ := json.Marshal(%s); return string(v) }()", value)
+	}
+
+	var static, fields []string
+	for _, prop := range obj.Props {
+		if prop.Spread || prop.Computed != nil {
+			return fmt.Sprintf("%q /* TODO: %s */", "{}", commentText(truncateExpr(raw, 40)))
+		}
+		if lit, ok := jsonLiteral(prop.Value); ok {
+			key, _ := json.Marshal(prop.Key)
+			static = append(static, string(key)+":"+lit)
+		}
+		fields = append(fields, fmt.Sprintf("%q: %s", prop.Key, g.jsonField(prop.Value, strings.TrimSpace(prop.Value.Span().Text(raw)))))
+	}
+	if len(static) == len(obj.Props) {
+		return fmt.Sprintf("%q", "{"+strings.Join(static, ",")+"}")
+	}
+	return fmt.Sprintf("func() string { v, _ := json.Marshal(map[string]any{%s}); return string(v) }()", strings.Join(fields, ", "))
+}
+
+// jsonField translates the value of one property of a JSON attribute
+func (g *Generator) jsonField(value parser.JSExpr, src string) string {
+	if lit, ok := jsonLiteral(value); ok {
+		if s, isString := parser.Unparen(value).(*parser.JSLiteral); isString && s.Kind == "string" {
+			return fmt.Sprintf("%q", s.Value)
+		}
+		if lit == "null" {
+			return "nil"
+		}
+		return lit
+	}
+	return g.translateExprValue(src)
+}
+
+// jsonLiteral returns the JSON of a literal value: a string, number,
+// boolean or null
+func jsonLiteral(e parser.JSExpr) (string, bool) {
+	lit, ok := parser.Unparen(e).(*parser.JSLiteral)
+	if !ok {
+		return "", false
+	}
+	switch lit.Kind {
+	case "string":
+		b, _ := json.Marshal(lit.Value)
+		return string(b), true
+	case "number":
+		if json.Valid([]byte(lit.Value)) {
+			return lit.Value, true
+		}
+	case "bool":
+		return lit.Value, true
+	case "null":
+		return "null", true
+	}
+	return "", false
+}
@@ -0,0 +1,238 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// I18nFile is the Go file that loads the go-i18n message catalogs
+const I18nFile = "i18n.go"
+
+// I18nImport is the go-i18n package generated lookups use
+const I18nImport = "github.com/nicksnyder/go-i18n/v2/i18n"
+
+// localizerParam is the parameter carrying the request's localizer into
+// components that translate
+const localizerParam = "localizer"
+
+// Message is a translatable string found in a t() call or <Trans> element
+type Message struct {
+	ID    string `json:"id"`
+	Other string `json:"other"` // default text, in go-i18n template syntax
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line"`
+}
+
+// Messages returns the translatable strings found in the last run
+func (g *Generator) Messages() []Message {
+	return g.messages
+}
+
+// i18nInterpolation matches i18next {{name}} placeholders
+var i18nInterpolation = regexp.MustCompile(`\{\{\s*([\w.]+)\s*\}\}`)
+
+// GoI18nTemplate converts i18next {{name}} interpolation into the
+// text/template syntax go-i18n expects ({{.name}})
+func GoI18nTemplate(s string) string {
+	return i18nInterpolation.ReplaceAllString(s, "{{.$1}}")
+}
+
+// translateMessageRef translates a t('key', ...) call into a go-i18n lookup
+func (g *Generator) translateMessageRef(expr string) (string, bool) {
+	if g.translator == "" {
+		return "", false
+	}
+	expr = strings.TrimSpace(expr)
+	prefix := g.translator + "("
+	if !strings.HasPrefix(expr, prefix) || !strings.HasSuffix(expr, ")") {
+		return "", false
+	}
+	args := splitTopLevel(expr[len(prefix):len(expr)-1], ',')
+	id, ok := stringLiteral(args[0])
+	if !ok {
+		return "", false
+	}
+	// Namespaces are merged into one catalog: t('common:save') → "save"
+	if _, key, found := strings.Cut(id, ":"); found {
+		id = key
+	}
+
+	other, options := "", ""
+	if len(args) > 1 {
+		if s, ok := stringLiteral(args[1]); ok {
+			// t('key', 'Default text'), optionally followed by options
+			other = s
+			if len(args) > 2 {
+				options = args[2]
+			}
+		} else {
+			options = args[1]
+		}
+	}
+	var data []string
+	count := ""
+	for _, entry := range objectEntries(options) {
+		switch entry[0] {
+		case "defaultValue":
+			if s, ok := stringLiteral(entry[1]); ok {
+				other = s
+			}
+		case "count":
+			count = g.translateExprValue(entry[1])
+			data = append(data, fmt.Sprintf("%q: %s", "count", count))
+		default:
+			data = append(data, fmt.Sprintf("%q: %s", entry[0], g.translateExprValue(entry[1])))
+		}
+	}
+	return g.localize(id, other, data, count), true
+}
+
+// localize records a message and returns the go-i18n lookup for it. Keys
+// without a default text fall back to the key, as i18next does.
+func (g *Generator) localize(id, other string, data []string, count string) string {
+	if other == "" {
+		other = id
+	}
+	other = GoI18nTemplate(other)
+	g.messages = append(g.messages, Message{ID: id, Other: other, Line: g.currentLine})
+
+	config := fmt.Sprintf("DefaultMessage: &i18n.Message{ID: %q, Other: %q}", id, other)
+	if len(data) > 0 {
+		config += fmt.Sprintf(", TemplateData: map[string]any{%s}", strings.Join(data, ", "))
+	}
+	if count != "" {
+		config += ", PluralCount: " + count
+	}
+	return fmt.Sprintf("%s.MustLocalize(&i18n.LocalizeConfig{%s})", localizerParam, config)
+}
+
+// generateTrans translates <Trans i18nKey="key">Default text</Trans>.
+// Markup inside the element is not carried over.
+func (g *Generator) generateTrans(elem *parser.Element) {
+	var text strings.Builder
+	markup := false
+	for _, child := range elem.Children {
+		switch c := child.(type) {
+		case *parser.Text:
+			text.WriteString(jsxText(c.Content))
+		case *parser.Expression:
+			// {{name}} inside <Trans> reads a value from the values prop
+			text.WriteString("{" + c.Raw + "}")
+		default:
+			markup = true
+		}
+	}
+	other := strings.Join(strings.Fields(text.String()), " ")
+
+	id := other
+	var data []string
+	count := ""
+	for _, attr := range elem.Attributes {
+		value, literal := literalAttrValue(attr)
+		switch {
+		case attr.Name == "i18nKey" && literal:
+			id = value
+		case attr.Name == "defaults" && literal:
+			other = value
+		case attr.Name == "values":
+			for _, entry := range objectEntries(attr.Expression.Raw) {
+				data = append(data, fmt.Sprintf("%q: %s", entry[0], g.translateExprValue(entry[1])))
+			}
+		case attr.Name == "count":
+			count = g.translateExprValue(attr.Expression.Raw)
+			data = append(data, fmt.Sprintf("%q: %s", "count", count))
+		}
+	}
+	if id == "" {
+		g.write("\"\" /* TODO: <Trans> without i18nKey or text */")
+		return
+	}
+	g.write(g.localize(id, other, data, count))
+	if markup {
+		g.write(" /* TODO: markup inside <Trans> not translated */")
+	}
+}
+
+// objectEntries splits a static object literal ({ a: x, b }) into
+// key/value pairs; shorthand entries use the key as the value
+func objectEntries(raw string) [][2]string {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "{") || !strings.HasSuffix(raw, "}") {
+		return nil
+	}
+	var entries [][2]string
+	for _, entry := range splitTopLevel(raw[1:len(raw)-1], ',') {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, ":")
+		key = strings.Trim(strings.TrimSpace(key), `'"`)
+		if !found {
+			value = key
+		}
+		entries = append(entries, [2]string{key, strings.TrimSpace(value)})
+	}
+	return entries
+}
+
+// stringLiteral returns the value of a quoted JavaScript string
+func stringLiteral(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || (s[0] != '\'' && s[0] != '"' && s[0] != '`') || s[len(s)-1] != s[0] {
+		return "", false
+	}
+	if s[0] == '`' && strings.Contains(s, "${") {
+		return "", false
+	}
+	return s[1 : len(s)-1], true
+}
+
+// I18nSource returns a Go file that embeds the catalogs in locales/ and
+// builds a localizer per request, in package pkg
+func I18nSource(pkg string) string {
+	return `package ` + pkg + `
+
+// Generated by reminty - go-i18n message catalogs
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// bundle holds the message catalogs in locales/ (active.<lang>.json),
+// with English as the default language
+var bundle = loadBundle()
+
+func loadBundle() *i18n.Bundle {
+	b := i18n.NewBundle(language.English)
+	b.RegisterUnmarshalFunc("json", json.Unmarshal)
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		if _, err := b.LoadMessageFileFS(localeFiles, "locales/"+f.Name()); err != nil {
+			panic(err)
+		}
+	}
+	return b
+}
+
+// requestLocalizer returns a localizer for the languages a request asks
+// for: a ?lang= parameter first, then the Accept-Language header
+func requestLocalizer(r *http.Request) *i18n.Localizer {
+	return i18n.NewLocalizer(bundle, r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+}
+`
+}
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"go/scanner"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goImports are the packages generated code refers to, keyed by the name
+// it uses for them
+var goImports = map[string]string{
+	"fmt":     "fmt",
+	"strings": "strings",
+	"strconv": "strconv",
+	"regexp":  "regexp",
+	"sort":    "sort",
+	"math":    "math",
+	"slices":  "slices",
+	"utf8":    "unicode/utf8",
+	"url":     "net/url",
+	"http":    "net/http",
+	"json":    "encoding/json",
+	"testing": "testing",
+	"mi":      MintyImport,
+	"mdy":     MintydynImport,
+	"i18n":    I18nImport,
+}
+
+// fileImports returns the import declaration of the packages code uses
+func fileImports(code string) string {
+	used := usedPackages(code)
+	var lines []string
+	for name, p := range goImports {
+		if !used[name] {
+			continue
+		}
+		if path.Base(p) == name {
+			lines = append(lines, strconv.Quote(p))
+		} else {
+			lines = append(lines, name+" "+strconv.Quote(p))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return importSpecPath(lines[i]) < importSpecPath(lines[j]) })
+	return importBlock(lines, code)
+}
+
+// importBlock returns the import declaration of the import specs code
+// uses, standard library first, or "" if it uses none
+func importBlock(specs []string, code string) string {
+	used := usedPackages(code)
+	var std, other []string
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, _, aliased := strings.Cut(spec, " ")
+		if !aliased {
+			name = path.Base(importSpecPath(spec))
+		}
+		if !used[name] {
+			continue
+		}
+		if strings.Contains(strings.SplitN(importSpecPath(spec), "/", 2)[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	if len(std)+len(other) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, spec := range std {
+		b.WriteString("\t" + spec + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, spec := range other {
+		b.WriteString("\t" + spec + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// importSpecPath returns the path of an import spec (mi "github.com/ha1tch/minty")
+func importSpecPath(spec string) string {
+	if i := strings.LastIndex(spec, " "); i >= 0 {
+		spec = spec[i+1:]
+	}
+	return strings.Trim(spec, `"`)
+}
+
+// usedPackages returns the names code uses as package qualifiers: fmt in
+// fmt.Sprintf, mi in mi.Class. Comments, strings and field selections
+// (r.URL.Query) do not count.
+func usedPackages(code string) map[string]bool {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(code))
+	var s scanner.Scanner
+	s.Init(file, []byte(code), nil, 0)
+
+	used := map[string]bool{}
+	prev, ident := token.ILLEGAL, ""
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.PERIOD && ident != "" {
+			used[ident] = true
+		}
+		ident = ""
+		if tok == token.IDENT && prev != token.PERIOD {
+			ident = lit
+		}
+		prev = tok
+	}
+	return used
+}
@@ -0,0 +1,1146 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// itemType is the struct generated for the elements of a collection the
+// file maps over: tasks.map(task => ...) reads task.title and task.done,
+// so tasks becomes []Task with a field for each
+type itemType struct {
+	name       string
+	base       string  // the name it has unless another file of the package declares it
+	component  string  // the first component using it
+	collection string  // the first collection typed with it, for its doc comment
+	fields     []Param // Prop is the JavaScript key
+}
+
+// ItemStruct is a struct generated for the items of a collection, as
+// recorded for the other files of the package
+type ItemStruct struct {
+	Name      string `json:"name"`               // the name the file alone gives it
+	Component string `json:"component"`          // the first component using it
+	Declared  string `json:"declared,omitempty"` // the name declared instead, when another file has Name
+}
+
+// UseReservedNames sets the names the other files of the package declare.
+// An item struct the file would give one of them is named after its
+// component instead: Item becomes ProductCatalogItem.
+func (g *Generator) UseReservedNames(names map[string]bool) {
+	g.reserved = names
+}
+
+// ItemStructs returns the item structs generated in the last run, sorted
+// by name
+func (g *Generator) ItemStructs() []ItemStruct {
+	var structs []ItemStruct
+	seen := map[*itemType]bool{}
+	for _, collections := range g.items {
+		for _, t := range collections {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			s := ItemStruct{Name: t.base, Component: t.component}
+			if t.name != t.base {
+				s.Declared = t.name
+			}
+			structs = append(structs, s)
+		}
+	}
+	sort.Slice(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+	return structs
+}
+
+// field returns the field for a JavaScript key
+func (t *itemType) field(key string) (Param, bool) {
+	for _, f := range t.fields {
+		if f.Prop == key {
+			return f, true
+		}
+	}
+	return Param{}, false
+}
+
+// setFieldType changes the type of the field for a JavaScript key
+func (t *itemType) setFieldType(key, typ string) {
+	for i := range t.fields {
+		if t.fields[i].Prop == key {
+			t.fields[i].Type = typ
+		}
+	}
+}
+
+// fieldUse is what the expressions of a map body tell about one field of
+// the item
+type fieldUse struct {
+	typ    string // from a literal: the initial state or a comparison
+	number bool   // in arithmetic or an ordering comparison
+	truthy bool   // tested on its own: item.done && ..., !item.done
+	shown  bool   // rendered or passed on as a value
+	list   bool   // mapped over itself: item.tags.map(...)
+}
+
+// goType picks the Go type of a field from its uses; a field only ever
+// tested for truth is a bool, one only shown is a string
+func (u *fieldUse) goType() string {
+	switch {
+	case u.typ != "":
+		return u.typ
+	case u.list:
+		return "[]interface{}"
+	case u.number:
+		return "int"
+	case u.truthy && !u.shown:
+		return "bool"
+	}
+	return "string"
+}
+
+// guessed reports whether the type of the field is a guess, nothing but
+// its being shown telling it
+func (u *fieldUse) guessed() bool {
+	return u.typ == "" && !u.list && !u.number && (!u.truthy || u.shown)
+}
+
+// typedOps are the derived-variable operations whose result holds the
+// same elements as their source
+var typedOps = map[string]bool{"filter": true, "sort": true, "slice": true, "reverse": true}
+
+// keepsElements reports whether a derived variable holds elements of its
+// source: every operation it chains is one of typedOps
+func keepsElements(dv parser.DerivedVariable) bool {
+	ops, length, ok := parser.DerivedChain(dv)
+	if !ok {
+		return typedOps[dv.Operation]
+	}
+	for _, op := range ops {
+		if !typedOps[op.Method] {
+			return false
+		}
+	}
+	return !length
+}
+
+// resolveItems works out a struct for each collection mapped or filtered
+// in the file. A collection is typed when it is a prop or state variable,
+// or derived from one by filter, sort or slice, and the map bodies and
+// callbacks over it only read fields of the item. Collections passed from
+// one component to another share the type, so the call still compiles.
+func (g *Generator) resolveItems(file *parser.File) {
+	g.items = map[string]map[string]*itemType{}
+	comps := map[string]*parser.Component{}
+	for i := range file.Components {
+		comps[file.Components[i].Name] = &file.Components[i]
+	}
+
+	// Fields read in the map bodies and the callbacks of derived
+	// variables, per component and root collection
+	uses := map[string]map[string]map[string]*fieldUse{}
+	itemVars := map[string]map[string]string{}
+	var order []string // component/root in order of appearance
+	blocked := map[string]bool{}
+	add := func(comp *parser.Component, root, item string, fields map[string]*fieldUse) {
+		if uses[comp.Name] == nil {
+			uses[comp.Name] = map[string]map[string]*fieldUse{}
+			itemVars[comp.Name] = map[string]string{}
+		}
+		if uses[comp.Name][root] == nil {
+			uses[comp.Name][root] = map[string]*fieldUse{}
+			order = append(order, comp.Name+"/"+root)
+		}
+		if len(itemVars[comp.Name][root]) <= 1 {
+			itemVars[comp.Name][root] = item
+		}
+		for key, u := range fields {
+			mergeFieldUse(uses[comp.Name][root], key, u)
+		}
+	}
+	for i := range file.Components {
+		comp := &file.Components[i]
+		for _, dv := range comp.DerivedVars {
+			root := collectionRoot(comp, dv.SourceVar)
+			if root == "" {
+				continue
+			}
+			if fields, item, ok := callbackFieldUses(dv); ok && len(fields) > 0 {
+				add(comp, root, item, fields)
+			}
+		}
+		walkMaps(comp.Body, func(m *parser.MapExpr) {
+			root := collectionRoot(comp, m.Collection)
+			if root == "" || m.Object != "" || m.Length != "" {
+				return
+			}
+			for _, op := range m.Chain {
+				if fields, item, ok := opFieldUses(op.Method, op.Params, op.Body); ok && len(fields) > 0 {
+					add(comp, root, item, fields)
+				}
+			}
+			if fields, ok := itemFieldUses(m); !ok {
+				blocked[comp.Name+"/"+root] = true
+			} else if len(fields) > 0 {
+				add(comp, root, m.ItemVar, fields)
+			}
+		})
+	}
+
+	// Collections passed from one component to another share the type;
+	// one whose items some map body uses whole stays untyped at both ends
+	type edge struct{ parent, root, child, local string }
+	var edges []edge
+	for _, parent := range file.Components {
+		parent := parent
+		walkElements(parent.Body, func(el *parser.Element) {
+			child := comps[el.Tag]
+			if child == nil {
+				return
+			}
+			for _, attr := range el.Attributes {
+				root := collectionRoot(&parent, strings.TrimSpace(attr.Expression.Raw))
+				local := propLocalName(child, attr.Name)
+				if root != "" && local != "" {
+					edges = append(edges, edge{parent.Name, root, child.Name, local})
+				}
+			}
+		})
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, e := range edges {
+			from, to := e.parent+"/"+e.root, e.child+"/"+e.local
+			if blocked[from] != blocked[to] {
+				blocked[from], blocked[to] = true, true
+				changed = true
+			}
+		}
+	}
+
+	// One struct per item name; the keys of an initial state literal
+	// come first and fix the types of their values
+	byName := map[string]*itemType{}
+	taken := map[string]bool{}
+	for name := range comps {
+		taken[name] = true
+	}
+	// Fields only ever shown are strings for want of anything better;
+	// another collection of the same struct may tell their type
+	guessed := map[*itemType]map[string]bool{}
+	for _, key := range order {
+		if blocked[key] {
+			continue
+		}
+		compName, root, _ := strings.Cut(key, "/")
+		comp := comps[compName]
+		fields := uses[compName][root]
+		var keys []string
+		for _, sv := range comp.StateVars {
+			if sv.Name == root {
+				keys = literalFields(sv.InitValue, fields)
+			}
+		}
+		sorted := make([]string, 0, len(fields))
+		for k := range fields {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			if !containsString(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+
+		name := itemTypeName(itemVars[compName][root], root)
+		if taken[name] && byName[name] == nil {
+			name += "Item"
+		}
+		t := byName[name]
+		if t == nil {
+			declared := name
+			if g.reserved[name] {
+				declared = compName + name
+				for i := 2; taken[declared] || g.reserved[declared]; i++ {
+					declared = compName + name + strconv.Itoa(i)
+				}
+			}
+			t = &itemType{name: declared, base: name, component: compName, collection: root}
+			byName[name] = t
+			taken[name], taken[declared] = true, true
+		}
+		for _, k := range keys {
+			typ := fields[k].goType()
+			if f, ok := t.field(k); ok {
+				switch {
+				case f.Type == typ, fields[k].guessed():
+				case guessed[t][k]:
+					// Only shown where the struct was first used
+					t.setFieldType(k, typ)
+					guessed[t][k] = false
+				default:
+					t.setFieldType(k, "interface{}")
+				}
+				continue
+			}
+			t.fields = append(t.fields, Param{Name: exportName(toCamelCase(k)), Type: typ, Prop: k})
+			if guessed[t] == nil {
+				guessed[t] = map[string]bool{}
+			}
+			guessed[t][k] = fields[k].guessed()
+		}
+		g.setItemType(compName, root, t)
+	}
+
+	// Share the types along props: <TaskList tasks={tasks} /> gives both
+	// ends the type either one has
+	for changed := true; changed; {
+		changed = false
+		for _, e := range edges {
+			from, to := g.items[e.parent][e.root], g.items[e.child][e.local]
+			switch {
+			case from != nil && to == nil:
+				g.setItemType(e.child, e.local, from)
+				changed = true
+			case from == nil && to != nil:
+				g.setItemType(e.parent, e.root, to)
+				changed = true
+			}
+		}
+	}
+}
+
+// itemTypeName names the struct of a collection's elements after the
+// item variable, or the collection in the singular when the variable is
+// a single letter: tasks.filter(t => ...) gives Task
+func itemTypeName(item, collection string) string {
+	if len(item) > 1 {
+		return exportName(toCamelCase(item))
+	}
+	name := toCamelCase(collection)
+	switch {
+	case irregularPlurals[name] != "":
+		name = irregularPlurals[name]
+	case strings.HasSuffix(name, "ies"):
+		name = strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		name = strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = strings.TrimSuffix(name, "s")
+	default:
+		name += "Item"
+	}
+	return exportName(name)
+}
+
+var irregularPlurals = map[string]string{
+	"people": "person", "children": "child", "men": "man", "women": "woman",
+}
+
+func (g *Generator) setItemType(comp, collection string, t *itemType) {
+	if g.items[comp] == nil {
+		g.items[comp] = map[string]*itemType{}
+	}
+	g.items[comp][collection] = t
+}
+
+// itemTypeOf returns the struct of the elements of a collection of the
+// component being generated, following derived variables to their source
+func (g *Generator) itemTypeOf(collection string) *itemType {
+	comp := g.currentComp
+	if comp == nil {
+		return nil
+	}
+	root := collectionRoot(comp, collection)
+	if root == "" {
+		return nil
+	}
+	return g.items[comp.Name][root]
+}
+
+// collectionRoot returns the prop or state variable a collection of comp
+// is, or is derived from by filter, sort or slice; "" if there is none
+func collectionRoot(comp *parser.Component, name string) string {
+	fetched := fetchedState(comp)
+	for depth := 0; depth < 8 && isSimpleIdent(name); depth++ {
+		if hasProp(comp, name) {
+			return name
+		}
+		for _, sv := range comp.StateVars {
+			if sv.Name == name && sv.Setter != "" && !fetched[name] {
+				return name
+			}
+		}
+		next := ""
+		for _, dv := range comp.DerivedVars {
+			if dv.Name == name && keepsElements(dv) {
+				next = dv.SourceVar
+			}
+		}
+		name = next
+	}
+	return ""
+}
+
+// hasProp reports whether comp takes a prop under the local name name
+func hasProp(comp *parser.Component, name string) bool {
+	for _, p := range comp.Props {
+		if p.Name == name && p.Alias == "" || p.Alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// propLocalName returns the name comp's body gives the prop passed as
+// name, or "" if it takes no such prop
+func propLocalName(comp *parser.Component, name string) string {
+	for _, p := range comp.Props {
+		if p.Name == name {
+			return firstNonEmpty(p.Alias, p.Name)
+		}
+	}
+	return ""
+}
+
+// fieldScan collects the fields expressions read from an item
+type fieldScan struct {
+	item   string
+	fields map[string]*fieldUse
+	ok     bool // false once the item is used other than by its fields
+
+	// methods allows string methods on fields, a.name.localeCompare(b.name),
+	// in callbacks the generator translates on its own terms
+	methods bool
+	// whole allows the item itself, which the callback of a reduce may
+	// collect
+	whole bool
+}
+
+func newFieldScan(item string) *fieldScan {
+	return &fieldScan{item: item, fields: map[string]*fieldUse{}, ok: isSimpleIdent(item)}
+}
+
+func (s *fieldScan) use(key string) *fieldUse {
+	if s.fields[key] == nil {
+		s.fields[key] = &fieldUse{}
+	}
+	return s.fields[key]
+}
+
+// stringMethods are the methods on a field that make it a string
+var stringMethods = map[string]bool{
+	"localeCompare": true, "toLowerCase": true, "toUpperCase": true, "trim": true,
+	"includes": true, "startsWith": true, "endsWith": true,
+}
+
+// scan reads one expression: cond marks a condition tested for truth,
+// shown one whose value ends up in the markup
+func (s *fieldScan) scan(raw string, cond, shown bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || !s.ok || !regexp.MustCompile(`\b`+regexp.QuoteMeta(s.item)+`\b`).MatchString(raw) {
+		return
+	}
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		s.ok = false
+		return
+	}
+	item := s.item
+	// Fields in a test or an operand are not shown as they are
+	operand := map[parser.JSExpr]bool{}
+	test := func(x parser.JSExpr) {
+		if key, isField := itemKey(x, item); isField {
+			s.use(key).truthy = true
+			operand[parser.Unparen(x)] = true
+		}
+	}
+	if cond {
+		test(e)
+	}
+	var members []*parser.JSMember
+	parser.WalkJS(e, func(x parser.JSExpr) bool {
+		switch n := x.(type) {
+		case *parser.JSMember:
+			if _, isField := itemKey(n, item); isField {
+				members = append(members, n)
+			} else if id, isIdent := n.Object.(*parser.JSIdent); isIdent && id.Name == item {
+				s.ok = false // item[key]
+			}
+			if key, isField := itemKey(n.Object, item); isField && n.Property != "length" {
+				if s.methods && stringMethods[n.Property] {
+					s.use(key).typ = firstNonEmpty(s.use(key).typ, "string")
+					operand[parser.Unparen(n.Object)] = true
+				} else {
+					s.ok = false
+				}
+			}
+		case *parser.JSIdent:
+			if n.Name == item && !s.whole {
+				// Every item.field was matched above; this is the item whole
+				s.ok = false
+			}
+			return true
+		case *parser.JSArrow:
+			for _, p := range n.Params {
+				if strings.TrimSpace(p) == item {
+					s.ok = false // a nested function binding the same name
+				}
+			}
+		case *parser.JSUnary:
+			if n.Op == "!" {
+				test(n.X)
+			}
+		case *parser.JSConditional:
+			test(n.Test)
+		case *parser.JSBinary:
+			left, lok := itemKey(n.Left, item)
+			right, rok := itemKey(n.Right, item)
+			switch n.Op {
+			case "&&", "||":
+				test(n.Left)
+			case "===", "!==", "==", "!=":
+				if lok {
+					s.use(left).typ = firstNonEmpty(s.use(left).typ, literalGoType(n.Right))
+					operand[parser.Unparen(n.Left)] = true
+				}
+				if rok {
+					s.use(right).typ = firstNonEmpty(s.use(right).typ, literalGoType(n.Left))
+					operand[parser.Unparen(n.Right)] = true
+				}
+			case "<", "<=", ">", ">=", "-", "*", "/", "%":
+				if lok {
+					s.use(left).number = true
+					operand[parser.Unparen(n.Left)] = true
+				}
+				if rok {
+					s.use(right).number = true
+					operand[parser.Unparen(n.Right)] = true
+				}
+			}
+		}
+		if member, isMember := x.(*parser.JSMember); isMember {
+			// Do not visit the item identifier of item.field
+			if _, isField := itemKey(member, item); isField {
+				return false
+			}
+		}
+		return true
+	})
+	for _, member := range members {
+		if !operand[member] && shown {
+			s.use(member.Property).shown = true
+		} else {
+			s.use(member.Property)
+		}
+	}
+}
+
+// itemFieldUses collects the fields the body of m reads from its item. It
+// returns false if the item is used whole (passed to a component, spread,
+// or read deeper than one field), which a struct could not stand in for.
+func itemFieldUses(m *parser.MapExpr) (map[string]*fieldUse, bool) {
+	item := m.ItemVar
+	s := newFieldScan(item)
+	mentions := regexp.MustCompile(`\b` + regexp.QuoteMeta(item) + `\b`)
+
+	var walk func(node parser.Node)
+	walk = func(node parser.Node) {
+		switch n := node.(type) {
+		case *parser.Expression:
+			s.scan(n.Raw, false, true)
+		case *parser.Element:
+			for _, attr := range n.Attributes {
+				if attr.IsSpread {
+					if mentions.MatchString(attr.SpreadExpr) {
+						s.ok = false
+					}
+					continue
+				}
+				// Keys are dropped, not rendered
+				s.scan(attr.Expression.Raw, false, attr.Name != "key")
+				if e, err := parser.ParseJSExpr(attr.Expression.Raw); err == nil && isComponentName(n.Tag) {
+					// A field passed as a prop takes the prop's type
+					if key, isField := itemKey(e, item); isField && propNameType(attr.Name) != "string" {
+						s.use(key).typ = firstNonEmpty(s.use(key).typ, propNameType(attr.Name))
+					}
+				}
+			}
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.Fragment:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.MapExpr:
+			key, isField := strings.CutPrefix(n.Collection, item+".")
+			switch {
+			case n.Collection == item:
+				s.ok = false
+			case isField && isSimpleIdent(key) && n.Object != "":
+				// An object iterated: item.scores in Object.entries(item.scores)
+				s.use(key).typ = "map[string]interface{}"
+			case isField && isSimpleIdent(key):
+				s.use(key).list = true
+			}
+			if n.ItemVar == item || n.ValueVar == item || n.IndexVar == item {
+				s.ok = false
+				return
+			}
+			walk(n.Body)
+		case *parser.Conditional:
+			s.scan(n.Condition, true, false)
+			walk(n.Consequent)
+		case *parser.Ternary:
+			s.scan(n.Condition, true, false)
+			walk(n.Consequent)
+			walk(n.Alternate)
+		}
+	}
+	if s.ok {
+		walk(m.Body)
+	}
+	return s.fields, s.ok
+}
+
+// callbackFieldUses collects the fields the callbacks of a derived
+// variable read from its items: u.active in users.filter(u => u.active),
+// a.price and b.price in items.sort((a, b) => a.price - b.price). item is
+// the parameter the struct would be named after.
+func callbackFieldUses(dv parser.DerivedVariable) (fields map[string]*fieldUse, item string, ok bool) {
+	ops, _, chained := parser.DerivedChain(dv)
+	if !chained {
+		params, body, ok := parser.DerivedCallback(dv)
+		if !ok {
+			return nil, "", false
+		}
+		return opFieldUses(dv.Operation, params, body)
+	}
+	// The callbacks up to the first operation that changes what the
+	// elements are read the items of the source
+	for _, op := range ops {
+		if op.Params != nil || op.Method == "reduce" {
+			opFields, opItem, ok := opFieldUses(op.Method, op.Params, op.Body)
+			if op.Method == "reduce" {
+				opFields, opItem, ok = reduceFieldUses(op)
+			}
+			if !ok {
+				return nil, "", false
+			}
+			if fields == nil {
+				fields, item = map[string]*fieldUse{}, opItem
+			}
+			for key, u := range opFields {
+				mergeFieldUse(fields, key, u)
+			}
+		}
+		if !typedOps[op.Method] {
+			break
+		}
+	}
+	return fields, item, fields != nil
+}
+
+// opFieldUses collects the fields the callback of an array operation
+// reads from its item
+func opFieldUses(op string, params []string, body string) (fields map[string]*fieldUse, item string, ok bool) {
+	if len(params) == 0 {
+		return nil, "", false
+	}
+	items := params[:1]
+	cond, shown := false, false
+	switch op {
+	case "filter", "find", "some", "every":
+		cond = true
+	case "map":
+		shown = true
+	case "sort":
+		items = params
+	default:
+		return nil, "", false
+	}
+	fields = map[string]*fieldUse{}
+	for _, param := range items {
+		s := newFieldScan(param)
+		s.methods = true
+		s.scan(body, cond, shown)
+		if !s.ok {
+			return nil, "", false
+		}
+		for key, u := range s.fields {
+			mergeFieldUse(fields, key, u)
+		}
+	}
+	return fields, params[0], true
+}
+
+// reduceFieldUses collects the fields the callback of a reduce reads
+// from its element. Those added up, or compared by Math.max and
+// Math.min, are numbers, unless the accumulator is a string; those
+// combined with a boolean accumulator by && and || are tested for truth.
+func reduceFieldUses(op parser.ArrayOp) (fields map[string]*fieldUse, item string, ok bool) {
+	params, body, block, ok := reduceCallback(op)
+	if !ok || len(params) != 2 {
+		return nil, "", false
+	}
+	exprs := []string{body}
+	if block != "" {
+		exprs = nil
+		for _, stmt := range statements(block) {
+			if _, _, isIf := ifParts(stmt); isIf {
+				continue
+			}
+			if m := declaration.FindStringSubmatch(stmt); m != nil {
+				stmt = m[2]
+			} else if returnKeyword.MatchString(stmt) {
+				stmt, _ = returnValue(stmt)
+			}
+			exprs = append(exprs, stmt)
+		}
+	}
+	var start string
+	if len(op.Args) == 1 {
+		if init, err := parser.ParseJSExpr(op.Args[0]); err == nil {
+			start = literalGoType(init)
+		}
+	}
+	item = params[1]
+	s := newFieldScan(item)
+	s.methods, s.whole = true, true
+	for _, src := range exprs {
+		s.scan(src, false, false)
+		e, err := parser.ParseJSExpr(src)
+		if err != nil || start == "string" {
+			continue
+		}
+		parser.WalkJS(e, func(x parser.JSExpr) bool {
+			var operands []parser.JSExpr
+			switch n := x.(type) {
+			case *parser.JSBinary:
+				if (n.Op == "&&" || n.Op == "||") && start == "bool" {
+					for _, operand := range []parser.JSExpr{n.Left, n.Right} {
+						if key, isField := itemKey(operand, item); isField {
+							s.use(key).truthy = true
+						}
+					}
+				}
+				if n.Op == "+" {
+					operands = []parser.JSExpr{n.Left, n.Right}
+				}
+			case *parser.JSAssign:
+				if n.Op == "+=" {
+					operands = []parser.JSExpr{n.Value}
+				}
+			case *parser.JSCall:
+				if fn := parser.MemberPath(n.Callee); fn == "Math.max" || fn == "Math.min" {
+					operands = n.Args
+				}
+			}
+			for _, operand := range operands {
+				if key, isField := itemKey(operand, item); isField {
+					s.use(key).number = true
+				}
+			}
+			return true
+		})
+	}
+	return s.fields, item, s.ok
+}
+
+// mergeFieldUse adds what u tells about a field to fields
+func mergeFieldUse(fields map[string]*fieldUse, key string, u *fieldUse) {
+	if prev := fields[key]; prev != nil {
+		u.typ = firstNonEmpty(prev.typ, u.typ)
+		u.number = u.number || prev.number
+		u.truthy = u.truthy || prev.truthy
+		u.shown = u.shown || prev.shown
+		u.list = u.list || prev.list
+	}
+	fields[key] = u
+}
+
+// propNameType guesses the type of a component prop from its name:
+// isActive and showHeader are bool, itemCount and pageSize int
+func propNameType(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(name, "is"),
+		strings.HasPrefix(name, "has"),
+		strings.Contains(name, "active"),
+		strings.Contains(name, "enabled"),
+		strings.Contains(name, "disabled"),
+		strings.Contains(name, "show"),
+		strings.Contains(name, "hidden"),
+		strings.Contains(name, "visible"):
+		return "bool"
+	case strings.Contains(name, "count"),
+		strings.Contains(name, "index"),
+		strings.Contains(name, "num"),
+		strings.Contains(name, "size"):
+		return "int"
+	}
+	return "string"
+}
+
+// isItemField reports whether e is item.field
+func isItemField(e parser.JSExpr, item string) bool {
+	_, ok := itemKey(e, item)
+	return ok
+}
+
+// itemKey returns the field of item.field
+func itemKey(e parser.JSExpr, item string) (string, bool) {
+	m, ok := parser.Unparen(e).(*parser.JSMember)
+	if !ok || m.Index != nil {
+		return "", false
+	}
+	id, ok := m.Object.(*parser.JSIdent)
+	if !ok || id.Name != item {
+		return "", false
+	}
+	return m.Property, true
+}
+
+// literalGoType returns the Go type of a literal operand, or ""
+func literalGoType(e parser.JSExpr) string {
+	lit, ok := parser.Unparen(e).(*parser.JSLiteral)
+	if !ok {
+		return ""
+	}
+	switch lit.Kind {
+	case "string":
+		return "string"
+	case "bool":
+		return "bool"
+	case "number":
+		if strings.ContainsAny(lit.Value, ".eE") {
+			return "float64"
+		}
+		return "int"
+	}
+	return ""
+}
+
+// literalFields reads the keys of the objects in an initial state array
+// literal, in order, setting the types of their values on fields
+func literalFields(src string, fields map[string]*fieldUse) []string {
+	e, err := parser.ParseJSExpr(src)
+	if err != nil {
+		return nil
+	}
+	arr, ok := parser.Unparen(e).(*parser.JSArray)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, el := range arr.Elements {
+		obj, ok := parser.Unparen(el).(*parser.JSObject)
+		if !ok {
+			continue
+		}
+		for _, prop := range obj.Props {
+			if prop.Key == "" || prop.Shorthand {
+				continue
+			}
+			if fields[prop.Key] == nil {
+				fields[prop.Key] = &fieldUse{}
+			}
+			if typ := literalValueType(prop.Value); typ != "" && fields[prop.Key].typ == "" {
+				fields[prop.Key].typ = typ
+			}
+			if !containsString(keys, prop.Key) {
+				keys = append(keys, prop.Key)
+			}
+		}
+	}
+	return keys
+}
+
+// literalValueType returns the Go type of a value in an object literal
+func literalValueType(e parser.JSExpr) string {
+	switch parser.Unparen(e).(type) {
+	case *parser.JSArray:
+		return "[]interface{}"
+	case *parser.JSObject:
+		return "map[string]interface{}"
+	}
+	return literalGoType(e)
+}
+
+// walkMaps calls fn for every map expression under node, outer first
+func walkMaps(node parser.Node, fn func(*parser.MapExpr)) {
+	switch n := node.(type) {
+	case *parser.Element:
+		for _, child := range n.Children {
+			walkMaps(child, fn)
+		}
+	case *parser.Fragment:
+		for _, child := range n.Children {
+			walkMaps(child, fn)
+		}
+	case *parser.MapExpr:
+		fn(n)
+		walkMaps(n.Body, fn)
+	case *parser.Conditional:
+		walkMaps(n.Consequent, fn)
+	case *parser.Ternary:
+		walkMaps(n.Consequent, fn)
+		walkMaps(n.Alternate, fn)
+	}
+}
+
+// walkElements calls fn for every element under node
+func walkElements(node parser.Node, fn func(*parser.Element)) {
+	switch n := node.(type) {
+	case *parser.Element:
+		fn(n)
+		for _, child := range n.Children {
+			walkElements(child, fn)
+		}
+	case *parser.Fragment:
+		for _, child := range n.Children {
+			walkElements(child, fn)
+		}
+	case *parser.MapExpr:
+		walkElements(n.Body, fn)
+	case *parser.Conditional:
+		walkElements(n.Consequent, fn)
+	case *parser.Ternary:
+		walkElements(n.Consequent, fn)
+		walkElements(n.Alternate, fn)
+	}
+}
+
+// writeItemTypes writes the structs of the mapped collections
+func (g *Generator) writeItemTypes() {
+	var types []*itemType
+	seen := map[*itemType]bool{}
+	for _, byCollection := range g.items {
+		for _, t := range byCollection {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].name < types[j].name })
+	for _, t := range types {
+		g.writef("// %s is an element of %s, with the fields the component reads\n", t.name, t.collection)
+		g.writeStruct(t)
+		g.writeln("")
+	}
+}
+
+// writeStruct declares the struct of an item type, with a JSON tag per
+// field naming its JavaScript key
+func (g *Generator) writeStruct(t *itemType) {
+	g.writef("type %s struct {\n", t.name)
+	width, typeWidth := 0, 0
+	for _, f := range t.fields {
+		width = max(width, len(f.Name))
+		typeWidth = max(typeWidth, len(f.Type))
+	}
+	for _, f := range t.fields {
+		g.writef("\t%-*s %-*s `json:%q`\n", width, f.Name, typeWidth, f.Type, f.Prop)
+	}
+	g.writeln("}")
+}
+
+// itemField translates a field of the typed item being mapped:
+// task.title → task.Title. It also returns the field's type.
+func (g *Generator) itemField(expr string) (string, string, bool) {
+	if !g.inMapBody || g.currentItem == nil {
+		return "", "", false
+	}
+	base, key, ok := strings.Cut(strings.TrimSpace(expr), ".")
+	if !ok || base != g.currentItemVar || !isSimpleIdent(key) {
+		return "", "", false
+	}
+	f, ok := g.currentItem.field(key)
+	if !ok {
+		return "", "", false
+	}
+	return base + "." + f.Name, f.Type, true
+}
+
+// derivedCondition translates the callback of a filter, find, some or
+// every over a typed collection, t => t.done becoming t.Done, and returns
+// it with the loop variable it tests. ok is false unless all of it
+// translates.
+func (g *Generator) derivedCondition(dv parser.DerivedVariable) (item, cond string, ok bool) {
+	t := g.itemTypeOf(dv.SourceVar)
+	params, body, ok := parser.DerivedCallback(dv)
+	if t == nil || !ok {
+		return "", "", false
+	}
+	return g.callbackCondition(t, "", params, body)
+}
+
+// callbackCondition translates the body of a callback testing an item
+// of type t, or of the scalar type elem, or a map when both are unset
+func (g *Generator) callbackCondition(t *itemType, elem string, params []string, body string) (item, cond string, ok bool) {
+	if len(params) == 0 || !isSimpleIdent(params[0]) {
+		return "", "", false
+	}
+	outerIn, outerVar, outerItem, outerElem := g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem
+	g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem = true, params[0], t, elem
+	cond = g.translateCondition(body)
+	g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem = outerIn, outerVar, outerItem, outerElem
+	if strings.Contains(cond, "/* TODO") {
+		return "", "", false
+	}
+	return params[0], cond, true
+}
+
+// writeMatchLoop writes the loop of a some or every over a typed
+// collection: goName starts as initial and flips at the first item
+// matching cond
+func (g *Generator) writeMatchLoop(goName, item, collection, cond string, initial bool) {
+	g.writef("%s := %t\n", goName, initial)
+	g.writeIndent()
+	g.writef("for _, %s := range %s {\n", item, collection)
+	g.writeIndent()
+	g.writef("\tif %s {\n", cond)
+	g.writeIndent()
+	g.writef("\t\t%s = %t\n", goName, !initial)
+	g.writeIndent()
+	g.writeln("\t\tbreak")
+	g.writeIndent()
+	g.writeln("\t}")
+	g.writeIndent()
+	g.writeln("}")
+}
+
+// negateCondition negates a translated Go condition
+func negateCondition(cond string) string {
+	selector := regexp.MustCompile(`^!?[\w.]+$`)
+	switch {
+	case !selector.MatchString(cond):
+		return "!(" + cond + ")"
+	case strings.HasPrefix(cond, "!"):
+		return cond[1:]
+	}
+	return "!" + cond
+}
+
+// sortLess translates the comparator of a sort over a typed collection
+// that orders by one field, (a, b) => a.price - b.price or
+// a.name.localeCompare(b.name), into the less function of sort.Slice
+// over slice
+func (g *Generator) sortLess(dv parser.DerivedVariable, slice string) (string, bool) {
+	t := g.itemTypeOf(dv.SourceVar)
+	params, body, ok := parser.DerivedCallback(dv)
+	if t == nil || !ok {
+		return "", false
+	}
+	return comparatorLess(t, "", params, body, slice)
+}
+
+// comparatorLess translates a comparator ordering items of type t by one
+// field, or items of the scalar type elem by their value, into the less
+// function of sort.Slice over slice
+func comparatorLess(t *itemType, elem string, params []string, body, slice string) (string, bool) {
+	if len(params) != 2 {
+		return "", false
+	}
+	e, err := parser.ParseJSExpr(body)
+	if err != nil {
+		return "", false
+	}
+	var left, right parser.JSExpr
+	switch n := parser.Unparen(e).(type) {
+	case *parser.JSBinary:
+		if n.Op != "-" {
+			return "", false
+		}
+		left, right = n.Left, n.Right
+	case *parser.JSCall:
+		m, isMember := n.Callee.(*parser.JSMember)
+		if !isMember || m.Property != "localeCompare" || len(n.Args) != 1 {
+			return "", false
+		}
+		left, right = m.Object, n.Args[0]
+	default:
+		return "", false
+	}
+
+	// b before a sorts in descending order
+	i, j := "i", "j"
+	if t == nil {
+		l, lok := parser.Unparen(left).(*parser.JSIdent)
+		r, rok := parser.Unparen(right).(*parser.JSIdent)
+		if !lok || !rok || elem != "string" && elem != "int" && elem != "float64" {
+			return "", false
+		}
+		switch {
+		case l.Name == params[1] && r.Name == params[0]:
+			i, j = "j", "i"
+		case l.Name != params[0] || r.Name != params[1]:
+			return "", false
+		}
+		return fmt.Sprintf("%s[%s] < %s[%s]", slice, i, slice, j), true
+	}
+	lkey, lok := itemKey(left, params[0])
+	rkey, rok := itemKey(right, params[1])
+	if !lok || !rok {
+		lkey, lok = itemKey(left, params[1])
+		rkey, rok = itemKey(right, params[0])
+		i, j = "j", "i"
+	}
+	if !lok || !rok || lkey != rkey {
+		return "", false
+	}
+	f, ok := t.field(lkey)
+	if !ok || (f.Type != "string" && f.Type != "int" && f.Type != "float64") {
+		return "", false
+	}
+	return fmt.Sprintf("%s[%s].%s < %s[%s].%s", slice, i, f.Name, slice, j, f.Name), true
+}
+
+// itemTruth is the Go condition testing a typed field for truth, negated
+// for !item.field
+func itemTruth(field, typ string, negate bool) string {
+	switch typ {
+	case "bool":
+		if negate {
+			return "!" + field
+		}
+		return field
+	case "string":
+		if negate {
+			return field + ` == ""`
+		}
+		return field + ` != ""`
+	case "int", "float64":
+		if negate {
+			return field + " == 0"
+		}
+		return field + " != 0"
+	}
+	if negate {
+		return fmt.Sprintf("!mi.Truthy(%s)", field)
+	}
+	return fmt.Sprintf("mi.Truthy(%s)", field)
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,605 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// goExpr is a JavaScript expression translated to Go, with its Go type:
+// string, int, float64, bool, a slice or struct type, mi.H, or
+// untypedNumber for a number literal and "nil" for null
+type goExpr struct {
+	code string
+	typ  string
+}
+
+// untypedNumber is the type of a number literal, which takes the type of
+// the other operand like a Go constant
+const untypedNumber = "number"
+
+// jsScope maps the names a translated expression may refer to to their
+// Go types
+type jsScope map[string]string
+
+// with returns a copy of the scope with name added
+func (s jsScope) with(name, typ string) jsScope {
+	scope := make(jsScope, len(s)+1)
+	for k, v := range s {
+		scope[k] = v
+	}
+	scope[name] = typ
+	return scope
+}
+
+// goStringMethods are the string methods with a strings package equivalent,
+// and the number of arguments they take
+var goStringMethods = map[string]struct {
+	fn   string
+	args int
+	typ  string
+}{
+	"toLowerCase": {"strings.ToLower", 0, "string"},
+	"toUpperCase": {"strings.ToUpper", 0, "string"},
+	"trim":        {"strings.TrimSpace", 0, "string"},
+	"trimStart":   {"strings.TrimLeft", 0, "string"},
+	"trimEnd":     {"strings.TrimRight", 0, "string"},
+	"startsWith":  {"strings.HasPrefix", 1, "bool"},
+	"endsWith":    {"strings.HasSuffix", 1, "bool"},
+	"includes":    {"strings.Contains", 1, "bool"},
+	"split":       {"strings.Split", 1, "[]string"},
+	"replaceAll":  {"strings.ReplaceAll", 2, "string"},
+	"repeat":      {"strings.Repeat", 1, "string"},
+	"indexOf":     {"strings.Index", 1, "int"},
+}
+
+// mathFuncs are the Math functions of one or two numbers with a math
+// package equivalent
+var mathFuncs = map[string]string{
+	"round": "math.Round", "floor": "math.Floor", "ceil": "math.Ceil",
+	"abs": "math.Abs", "sqrt": "math.Sqrt", "trunc": "math.Trunc",
+	"pow": "math.Pow", "max": "math.Max", "min": "math.Min",
+}
+
+// jsToGo translates e, parsed from src, to Go. The names it refers to must
+// be in scope or be helper functions of the file. It reports false for
+// anything it has no faithful translation for.
+func (g *Generator) jsToGo(e parser.JSExpr, src string, scope jsScope) (goExpr, bool) {
+	switch n := e.(type) {
+	case *parser.JSParen:
+		x, ok := g.jsToGo(n.X, src, scope)
+		return goExpr{"(" + x.code + ")", x.typ}, ok
+
+	case *parser.JSLiteral:
+		switch n.Kind {
+		case "string":
+			return goExpr{strconv.Quote(n.Value), "string"}, true
+		case "number":
+			if _, err := strconv.ParseFloat(n.Value, 64); err != nil {
+				return goExpr{}, false
+			}
+			return goExpr{n.Value, untypedNumber}, true
+		case "bool":
+			return goExpr{n.Value, "bool"}, true
+		case "null", "undefined":
+			return goExpr{"nil", "nil"}, true
+		}
+
+	case *parser.JSIdent:
+		typ, ok := scope[n.Name]
+		if !ok {
+			// A constant of the file, unless a local shadows it
+			if c := g.constants[n.Name]; c != nil && c.value.code != "" {
+				return c.value, true
+			}
+		}
+		if !ok || strings.Contains(n.Name, "$") {
+			return goExpr{}, false
+		}
+		return goExpr{goLocal(n.Name), typ}, true
+
+	case *parser.JSTemplate:
+		if n.Tag != nil {
+			return goExpr{}, false
+		}
+		return g.templateToGo(n, src, scope)
+
+	case *parser.JSUnary:
+		x, ok := g.jsToGo(n.X, src, scope)
+		if !ok || n.Postfix {
+			return goExpr{}, false
+		}
+		switch n.Op {
+		case "!":
+			switch x.typ {
+			case "string":
+				return goExpr{x.code + ` == ""`, "bool"}, true
+			case "int", "float64":
+				return goExpr{x.code + " == 0", "bool"}, true
+			}
+			return goExpr{negateCondition(goCond(x)), "bool"}, true
+		case "-":
+			if isNumeric(x.typ) {
+				return goExpr{"-" + wrapOperand(x.code), x.typ}, true
+			}
+		}
+
+	case *parser.JSBinary:
+		return g.binaryToGo(n, src, scope)
+
+	case *parser.JSConditional:
+		test, ok := g.jsToGo(n.Test, src, scope)
+		if !ok {
+			return goExpr{}, false
+		}
+		then, ok1 := g.jsToGo(n.Then, src, scope)
+		els, ok2 := g.jsToGo(n.Else, src, scope)
+		typ, ok3 := unifyTypes(then.typ, els.typ)
+		if !ok1 || !ok2 || !ok3 || typ == "nil" {
+			return goExpr{}, false
+		}
+		typ = concreteType(typ, then, els)
+		return goExpr{fmt.Sprintf("func() %s { if %s { return %s }; return %s }()", typ, goCond(test), then.code, els.code), typ}, true
+
+	case *parser.JSMember:
+		return g.memberToGo(n, src, scope)
+
+	case *parser.JSCall:
+		return g.callToGo(n, src, scope)
+
+	case *parser.JSArray:
+		var elems []goExpr
+		typ := ""
+		for _, el := range n.Elements {
+			if el == nil {
+				return goExpr{}, false
+			}
+			x, ok := g.jsToGo(el, src, scope)
+			if !ok {
+				return goExpr{}, false
+			}
+			if typ == "" {
+				typ = x.typ
+			} else if typ, ok = unifyTypes(typ, x.typ); !ok {
+				return goExpr{}, false
+			}
+			elems = append(elems, x)
+		}
+		if typ == "" || typ == "nil" {
+			return goExpr{}, false
+		}
+		typ = concreteType(typ, elems...)
+		codes := make([]string, len(elems))
+		for i, x := range elems {
+			codes[i] = x.code
+		}
+		return goExpr{"[]" + typ + "{" + strings.Join(codes, ", ") + "}", "[]" + typ}, true
+	}
+	return goExpr{}, false
+}
+
+// templateToGo translates a template literal to fmt.Sprintf, or to the
+// string itself when it has no expressions
+func (g *Generator) templateToGo(t *parser.JSTemplate, src string, scope jsScope) (goExpr, bool) {
+	if len(t.Exprs) == 0 {
+		return goExpr{strconv.Quote(t.Quasis[0]), "string"}, true
+	}
+	var format strings.Builder
+	args := make([]string, len(t.Exprs))
+	for i, e := range t.Exprs {
+		x, ok := g.jsToGo(e, src, scope)
+		if !ok || x.typ == "nil" {
+			return goExpr{}, false
+		}
+		format.WriteString(strings.ReplaceAll(t.Quasis[i], "%", "%%"))
+		switch x.typ {
+		case "string":
+			format.WriteString("%s")
+		case "int":
+			format.WriteString("%d")
+		default:
+			format.WriteString("%v")
+		}
+		args[i] = x.code
+	}
+	format.WriteString(strings.ReplaceAll(t.Quasis[len(t.Quasis)-1], "%", "%%"))
+	return goExpr{fmt.Sprintf("fmt.Sprintf(%q, %s)", format.String(), strings.Join(args, ", ")), "string"}, true
+}
+
+// binaryToGo translates arithmetic, concatenation, comparisons and the
+// logical operators between booleans
+func (g *Generator) binaryToGo(b *parser.JSBinary, src string, scope jsScope) (goExpr, bool) {
+	left, ok1 := g.jsToGo(b.Left, src, scope)
+	right, ok2 := g.jsToGo(b.Right, src, scope)
+	if !ok1 || !ok2 {
+		return goExpr{}, false
+	}
+	l, r := wrapOperand(left.code), wrapOperand(right.code)
+	switch b.Op {
+	case "+":
+		if left.typ == "string" || right.typ == "string" {
+			ls, ok1 := toGoString(left)
+			rs, ok2 := toGoString(right)
+			if !ok1 || !ok2 {
+				return goExpr{}, false
+			}
+			return goExpr{ls + " + " + rs, "string"}, true
+		}
+		fallthrough
+	case "-", "*":
+		typ, ok := numericType(left, right)
+		if !ok {
+			return goExpr{}, false
+		}
+		return goExpr{numericAs(left, typ, l) + " " + b.Op + " " + numericAs(right, typ, r), typ}, true
+	case "/":
+		// JavaScript divides in floating point
+		if _, ok := numericType(left, right); !ok {
+			return goExpr{}, false
+		}
+		return goExpr{numericAs(left, "float64", l) + " / " + numericAs(right, "float64", r), "float64"}, true
+	case "%":
+		typ, ok := numericType(left, right)
+		if !ok {
+			return goExpr{}, false
+		}
+		if typ == "float64" {
+			return goExpr{fmt.Sprintf("math.Mod(%s, %s)", numericAs(left, typ, left.code), numericAs(right, typ, right.code)), typ}, true
+		}
+		return goExpr{l + " % " + r, typ}, true
+	case "===", "==", "!==", "!=":
+		op := "=="
+		if strings.HasPrefix(b.Op, "!") {
+			op = "!="
+		}
+		if typ, ok := numericType(left, right); ok {
+			return goExpr{numericAs(left, typ, l) + " " + op + " " + numericAs(right, typ, r), "bool"}, true
+		}
+		if left.typ == "nil" || right.typ == "nil" {
+			other := left
+			if other.typ == "nil" {
+				other = right
+			}
+			if !nillable(other.typ) {
+				return goExpr{}, false
+			}
+		} else if left.typ != right.typ || left.typ == "" {
+			return goExpr{}, false
+		}
+		return goExpr{l + " " + op + " " + r, "bool"}, true
+	case "<", ">", "<=", ">=":
+		if typ, ok := numericType(left, right); ok {
+			return goExpr{numericAs(left, typ, l) + " " + b.Op + " " + numericAs(right, typ, r), "bool"}, true
+		}
+		if left.typ == "string" && right.typ == "string" {
+			return goExpr{l + " " + b.Op + " " + r, "bool"}, true
+		}
+	case "&&", "||":
+		if left.typ == "bool" && right.typ == "bool" {
+			return goExpr{l + " " + b.Op + " " + r, "bool"}, true
+		}
+		// name || 'Anonymous': the fallback of an empty string
+		if b.Op == "||" && left.typ == "string" && right.typ == "string" {
+			return goExpr{fmt.Sprintf("func() string { if %s != \"\" { return %s }; return %s }()", left.code, left.code, right.code), "string"}, true
+		}
+	case "??":
+		if nillable(left.typ) && left.typ == right.typ {
+			return goExpr{fmt.Sprintf("func() %s { if %s != nil { return %s }; return %s }()", left.typ, left.code, left.code, right.code), left.typ}, true
+		}
+	}
+	return goExpr{}, false
+}
+
+// memberToGo translates .length, the fields of generated structs and
+// maps, and indexing slices and maps
+func (g *Generator) memberToGo(m *parser.JSMember, src string, scope jsScope) (goExpr, bool) {
+	if m.Optional {
+		return goExpr{}, false
+	}
+	obj, ok := g.jsToGo(m.Object, src, scope)
+	if !ok {
+		return goExpr{}, false
+	}
+	if m.Index != nil {
+		index, ok := g.jsToGo(m.Index, src, scope)
+		if !ok {
+			return goExpr{}, false
+		}
+		switch {
+		case strings.HasPrefix(obj.typ, "map[string]") && index.typ == "string":
+			return goExpr{obj.code + "[" + index.code + "]", strings.TrimPrefix(obj.typ, "map[string]")}, true
+		case strings.HasPrefix(obj.typ, "[]") && (index.typ == "int" || index.typ == untypedNumber && !strings.ContainsAny(index.code, ".eE")):
+			return goExpr{obj.code + "[" + index.code + "]", strings.TrimPrefix(obj.typ, "[]")}, true
+		}
+		return goExpr{}, false
+	}
+	if m.Property == "length" && (obj.typ == "string" || strings.HasPrefix(obj.typ, "[]")) {
+		return goExpr{"len(" + obj.code + ")", "int"}, true
+	}
+	if strings.HasPrefix(obj.typ, "map[string]") {
+		return goExpr{fmt.Sprintf("%s[%q]", obj.code, m.Property), strings.TrimPrefix(obj.typ, "map[string]")}, true
+	}
+	if t := g.structType(obj.typ); t != nil {
+		if f, ok := t.field(m.Property); ok {
+			return goExpr{obj.code + "." + f.Name, f.Type}, true
+		}
+	}
+	return goExpr{}, false
+}
+
+// callToGo translates calls of the file's helper functions, of the
+// String, Number and Math functions, and of the string methods with a
+// strings package equivalent
+func (g *Generator) callToGo(call *parser.JSCall, src string, scope jsScope) (goExpr, bool) {
+	if call.New || call.Optional {
+		return goExpr{}, false
+	}
+	args := make([]goExpr, len(call.Args))
+	for i, arg := range call.Args {
+		x, ok := g.jsToGo(arg, src, scope)
+		if !ok {
+			return goExpr{}, false
+		}
+		args[i] = x
+	}
+
+	switch callee := call.Callee.(type) {
+	case *parser.JSIdent:
+		if _, shadowed := scope[callee.Name]; shadowed {
+			return goExpr{}, false
+		}
+		if h := g.helper(callee.Name); h != nil {
+			return h.call(args)
+		}
+		switch {
+		case callee.Name == "String" && len(args) == 1:
+			if s, ok := toGoString(args[0]); ok {
+				return goExpr{s, "string"}, true
+			}
+		case (callee.Name == "Number" || callee.Name == "parseFloat") && len(args) == 1 && isNumeric(args[0].typ):
+			return goExpr{numericAs(args[0], "float64", args[0].code), "float64"}, true
+		}
+		return goExpr{}, false
+
+	case *parser.JSMember:
+		if callee.Index != nil || callee.Optional {
+			return goExpr{}, false
+		}
+		if parser.MemberPath(callee.Object) == "Math" {
+			fn, ok := mathFuncs[callee.Property]
+			if !ok || len(args) == 0 || len(args) > 2 || (len(args) == 2) != (callee.Property == "pow" || callee.Property == "max" || callee.Property == "min") {
+				return goExpr{}, false
+			}
+			codes := make([]string, len(args))
+			for i, a := range args {
+				if !isNumeric(a.typ) {
+					return goExpr{}, false
+				}
+				codes[i] = numericAs(a, "float64", a.code)
+			}
+			return goExpr{fn + "(" + strings.Join(codes, ", ") + ")", "float64"}, true
+		}
+		recv, ok := g.jsToGo(callee.Object, src, scope)
+		if !ok {
+			return goExpr{}, false
+		}
+		return recv.method(callee.Property, args)
+	}
+	return goExpr{}, false
+}
+
+// method translates a method call on a translated value
+func (x goExpr) method(name string, args []goExpr) (goExpr, bool) {
+	switch {
+	case x.typ == "string":
+		if m, ok := goStringMethods[name]; ok && len(args) == m.args {
+			codes := []string{x.code}
+			for _, a := range args {
+				if a.typ != "string" {
+					return goExpr{}, false
+				}
+				codes = append(codes, a.code)
+			}
+			switch name {
+			case "trimStart", "trimEnd":
+				codes = append(codes, `" \t\n\r"`)
+			}
+			return goExpr{m.fn + "(" + strings.Join(codes, ", ") + ")", m.typ}, true
+		}
+		if name == "replace" && len(args) == 2 && args[0].typ == "string" && args[1].typ == "string" {
+			return goExpr{fmt.Sprintf("strings.Replace(%s, %s, %s, 1)", x.code, args[0].code, args[1].code), "string"}, true
+		}
+		if name == "toString" && len(args) == 0 {
+			return x, true
+		}
+	case isNumeric(x.typ):
+		switch name {
+		case "toFixed":
+			digits := "0"
+			if len(args) == 1 {
+				if _, err := strconv.Atoi(args[0].code); err != nil || args[0].typ != untypedNumber {
+					return goExpr{}, false
+				}
+				digits = args[0].code
+			} else if len(args) > 1 {
+				return goExpr{}, false
+			}
+			return goExpr{fmt.Sprintf("fmt.Sprintf(\"%%.%sf\", %s)", digits, numericAs(x, "float64", x.code)), "string"}, true
+		case "toString":
+			if len(args) == 0 {
+				return toGoStringExpr(x)
+			}
+		}
+	case x.typ == "[]string":
+		switch {
+		case name == "join" && len(args) <= 1:
+			sep := `","`
+			if len(args) == 1 {
+				if args[0].typ != "string" {
+					return goExpr{}, false
+				}
+				sep = args[0].code
+			}
+			return goExpr{"strings.Join(" + x.code + ", " + sep + ")", "string"}, true
+		case name == "includes" && len(args) == 1 && args[0].typ == "string":
+			return goExpr{"slices.Contains(" + x.code + ", " + args[0].code + ")", "bool"}, true
+		}
+	}
+	return goExpr{}, false
+}
+
+// toGoString converts a translated value to the string JavaScript would
+// make of it in a concatenation
+func toGoString(x goExpr) (string, bool) {
+	s, ok := toGoStringExpr(x)
+	return s.code, ok
+}
+
+func toGoStringExpr(x goExpr) (goExpr, bool) {
+	switch x.typ {
+	case "string":
+		return x, true
+	case untypedNumber:
+		f, err := strconv.ParseFloat(x.code, 64)
+		if err != nil {
+			return goExpr{}, false
+		}
+		return goExpr{strconv.Quote(strconv.FormatFloat(f, 'f', -1, 64)), "string"}, true
+	case "int":
+		return goExpr{"strconv.Itoa(" + x.code + ")", "string"}, true
+	case "float64", "bool":
+		return goExpr{"fmt.Sprint(" + x.code + ")", "string"}, true
+	}
+	return goExpr{}, false
+}
+
+// goCond returns a translated value as a Go condition: booleans as they
+// are, anything else by JavaScript truthiness
+func goCond(x goExpr) string {
+	switch x.typ {
+	case "bool":
+		return x.code
+	case "string":
+		return x.code + ` != ""`
+	case "int", "float64", untypedNumber:
+		return x.code + " != 0"
+	}
+	return "mi.Truthy(" + x.code + ")"
+}
+
+// wrapOperand parenthesizes code that is not a single operand, so that it
+// can be used as one
+func wrapOperand(code string) string {
+	if isSimpleIdent(code) || parenthesized(code) {
+		return code
+	}
+	if m := goCallee.FindString(code); m != "" && parenthesized(code[len(m)-1:]) {
+		return code
+	}
+	if _, err := strconv.Unquote(code); err == nil {
+		return code
+	}
+	if _, err := strconv.ParseFloat(code, 64); err == nil {
+		return code
+	}
+	return "(" + code + ")"
+}
+
+// goCallee matches the function of a Go call: strings.ToLower(
+var goCallee = regexp.MustCompile(`^[\w.]+\(`)
+
+// isNumeric reports whether typ is a Go number type or an untyped number
+func isNumeric(typ string) bool {
+	return typ == "int" || typ == "float64" || typ == untypedNumber
+}
+
+// numericType returns the type two numbers are combined in: float64 if
+// either is, int otherwise, untyped between literals
+func numericType(a, b goExpr) (string, bool) {
+	if !isNumeric(a.typ) || !isNumeric(b.typ) {
+		return "", false
+	}
+	switch {
+	case a.typ == "float64" || b.typ == "float64":
+		return "float64", true
+	case a.typ == "int" || b.typ == "int":
+		return "int", true
+	}
+	return untypedNumber, true
+}
+
+// numericAs returns code, the operand of x, converted to typ if it is an
+// int or an integer literal where a float64 is needed
+func numericAs(x goExpr, typ, code string) string {
+	switch {
+	case typ == "float64" && x.typ == "int":
+		return "float64(" + x.code + ")"
+	case typ == "float64" && x.typ == untypedNumber && !strings.ContainsAny(code, ".eExX"):
+		// 1 / 2 is 0.5 in JavaScript
+		return code + ".0"
+	}
+	return code
+}
+
+// unifyTypes returns the type of two values that may take each other's
+// place: a number literal fits any number
+func unifyTypes(a, b string) (string, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == untypedNumber && isNumeric(b):
+		return b, true
+	case b == untypedNumber && isNumeric(a):
+		return a, true
+	}
+	return "", false
+}
+
+// concreteType turns an untyped number into int, or float64 if one of
+// the values has a fraction
+func concreteType(typ string, values ...goExpr) string {
+	if typ != untypedNumber {
+		return typ
+	}
+	for _, v := range values {
+		if strings.ContainsAny(v.code, ".eE") {
+			return "float64"
+		}
+	}
+	return "int"
+}
+
+// nillable reports whether values of typ can be nil
+func nillable(typ string) bool {
+	return typ == "interface{}" || typ == "nil" || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || strings.HasPrefix(typ, "*") || typ == "mi.H"
+}
+
+// goLocal returns the Go name of a JavaScript variable, renaming those
+// that are Go keywords
+func goLocal(name string) string {
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
+
+// structType returns the generated item struct named name, or nil
+func (g *Generator) structType(name string) *itemType {
+	for _, types := range g.items {
+		for _, t := range types {
+			if t.name == name {
+				return t
+			}
+		}
+	}
+	for _, c := range g.constants {
+		if c.item != nil && c.item.name == name {
+			return c.item
+		}
+	}
+	return nil
+}
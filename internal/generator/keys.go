@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// UseKeyIDs turns on keeping the keys of list items that htmx swaps:
+// key={user.id} becomes mi.ID(fmt.Sprintf("user-%v", user.ID)), or a
+// data-key on an item with an id of its own, so that each item has a
+// stable target. Keys are dropped otherwise, as only React's
+// reconciliation reads them.
+func (g *Generator) UseKeyIDs(enabled bool) {
+	g.keyIDs = enabled
+}
+
+// keyAttr returns the attribute the key of a mapped list item becomes,
+// with the prefix of its id when it becomes the id, or "" for other
+// elements, items that are not swapped and when keys are dropped
+func (g *Generator) keyAttr(elem *parser.Element) (attr, idPrefix string) {
+	m := g.currentMap
+	if !g.keyIDs || m == nil || m.Key == "" || m.Body != parser.Node(elem) || !swappable(elem) {
+		return "", ""
+	}
+	key := g.translateExprValue(m.Key)
+	for _, attr := range elem.Attributes {
+		if attr.Name == "id" {
+			return fmt.Sprintf("mi.Data(%q, fmt.Sprint(%s))", "key", key), ""
+		}
+	}
+	return fmt.Sprintf("mi.ID(fmt.Sprintf(%q, %s))", keyIDPrefix(m)+"%v", key), keyIDPrefix(m)
+}
+
+// keyIDPrefix returns the prefix of the ids of a list's items: user-
+func keyIDPrefix(m *parser.MapExpr) string {
+	return toKebabCase(m.ItemVar) + "-"
+}
+
+// swappable reports whether htmx requests or swaps an element: it has
+// hx-* attributes or event handlers, which become htmx requests
+func swappable(elem *parser.Element) bool {
+	for _, attr := range elem.Attributes {
+		if attr.EventHandler != nil || strings.HasPrefix(attr.Name, "hx-") || strings.HasPrefix(attr.Name, "data-hx-") {
+			return true
+		}
+	}
+	return false
+}
+
+// handlerKey returns the key expression of the list item a handler is
+// in, and the prefix of the item's id when the handler is on the item
+// itself and its key is kept as the id
+func (g *Generator) handlerKey() (string, string) {
+	if g.currentMap == nil {
+		return "", ""
+	}
+	return g.currentMap.Key, g.keyedID
+}
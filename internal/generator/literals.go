@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// literalParamType returns the type a parameter holding a JavaScript
+// literal gets, as the generated functions declare them: the scalar
+// type, []interface{} for arrays and map[string]interface{} for objects,
+// read with the mi accessors
+func literalParamType(e parser.JSExpr) string {
+	switch unfrozen(e).(type) {
+	case *parser.JSArray:
+		return "[]interface{}"
+	case *parser.JSObject:
+		return "map[string]interface{}"
+	}
+	if t := scalarType(e); t != "" {
+		return t
+	}
+	return "interface{}"
+}
+
+// scalarType returns the Go type of a string, number or boolean literal,
+// or ""
+func scalarType(e parser.JSExpr) string {
+	x, ok := (&Generator{}).jsToGo(unfrozen(e), "", jsScope{})
+	if !ok {
+		return ""
+	}
+	switch x.typ {
+	case "string", "bool", "int", "float64":
+		return x.typ
+	case untypedNumber:
+		return concreteType(x.typ, x)
+	}
+	return ""
+}
+
+// unfrozen returns e without parentheses and Object.freeze, which makes
+// no difference to a Go value
+func unfrozen(e parser.JSExpr) parser.JSExpr {
+	e = parser.Unparen(e)
+	if call, ok := e.(*parser.JSCall); ok && parser.MemberPath(call.Callee) == "Object.freeze" && len(call.Args) == 1 {
+		return unfrozen(call.Args[0])
+	}
+	return e
+}
+
+// literalAs translates a JavaScript literal, parsed from src, to a Go
+// value of type typ: arrays to slices, objects to maps or to the
+// generated structs, scalars converted as arguments are. Values the
+// literal holds may name the file's constants.
+func (g *Generator) literalAs(e parser.JSExpr, src, typ string) (goExpr, bool) {
+	e = unfrozen(e)
+	if typ == "interface{}" {
+		if lit, ok := e.(*parser.JSLiteral); ok && (lit.Kind == "null" || lit.Kind == "undefined") {
+			return goExpr{"nil", typ}, true
+		}
+		x, ok := g.jsToGo(e, src, jsScope{})
+		switch {
+		case ok && x.typ != "nil":
+			return goExpr{x.code, typ}, true
+		case isComposite(e):
+			x, ok := g.literalAs(e, src, literalParamType(e))
+			return goExpr{x.code, typ}, ok
+		}
+		return goExpr{}, false
+	}
+
+	switch n := e.(type) {
+	case *parser.JSArray:
+		elem, ok := strings.CutPrefix(typ, "[]")
+		if !ok {
+			return goExpr{}, false
+		}
+		codes := make([]string, len(n.Elements))
+		for i, el := range n.Elements {
+			if el == nil {
+				return goExpr{}, false
+			}
+			x, ok := g.literalAs(el, src, elem)
+			if !ok {
+				return goExpr{}, false
+			}
+			codes[i] = elided(x.code, elem)
+		}
+		return goExpr{typ + "{" + strings.Join(codes, ", ") + "}", typ}, true
+
+	case *parser.JSObject:
+		if elem, ok := strings.CutPrefix(typ, "map[string]"); ok {
+			codes := make([]string, len(n.Props))
+			for i, prop := range n.Props {
+				if prop.Key == "" || prop.Shorthand || prop.Spread {
+					return goExpr{}, false
+				}
+				x, ok := g.literalAs(prop.Value, src, elem)
+				if !ok {
+					return goExpr{}, false
+				}
+				codes[i] = fmt.Sprintf("%q: %s", prop.Key, elided(x.code, elem))
+			}
+			return goExpr{typ + "{" + strings.Join(codes, ", ") + "}", typ}, true
+		}
+		t := g.structType(typ)
+		if t == nil {
+			return goExpr{}, false
+		}
+		// Keys the struct has no field for are not read
+		var codes []string
+		for _, prop := range n.Props {
+			if prop.Key == "" || prop.Shorthand || prop.Spread {
+				return goExpr{}, false
+			}
+			f, ok := t.field(prop.Key)
+			if !ok {
+				continue
+			}
+			x, ok := g.literalAs(prop.Value, src, f.Type)
+			if !ok {
+				return goExpr{}, false
+			}
+			codes = append(codes, f.Name+": "+elided(x.code, f.Type))
+		}
+		return goExpr{typ + "{" + strings.Join(codes, ", ") + "}", typ}, true
+	}
+
+	x, ok := g.jsToGo(e, src, jsScope{})
+	if !ok {
+		return goExpr{}, false
+	}
+	code, ok := convertArg(x, typ)
+	return goExpr{code, typ}, ok
+}
+
+// isComposite reports whether e is an array or object literal
+func isComposite(e parser.JSExpr) bool {
+	switch unfrozen(e).(type) {
+	case *parser.JSArray, *parser.JSObject:
+		return true
+	}
+	return false
+}
+
+// elided drops the type of a composite element of a composite literal of
+// elements of type typ, as gofmt -s does
+func elided(code, typ string) string {
+	if strings.HasPrefix(code, typ+"{") && typ != "interface{}" {
+		return strings.TrimPrefix(code, typ)
+	}
+	return code
+}
+
+// goLiteralAs translates the JavaScript literal src to typ, or to the
+// type a parameter holding it gets when typ is empty or interface{}.
+// Scalars, arrays and objects translate; anything else reports false.
+func (g *Generator) goLiteralAs(src, typ string) (string, bool) {
+	e, err := parser.ParseJSExpr(strings.TrimSpace(src))
+	if err != nil {
+		return "", false
+	}
+	if typ == "" || typ == "interface{}" && isComposite(e) {
+		typ = literalParamType(e)
+	}
+	x, ok := g.literalAs(e, src, typ)
+	return x.code, ok
+}
+
+// compositeArg translates an array or object literal passed as a prop to
+// a parameter of type typ, "" if the component's parameters are unknown:
+// tags={['new', 'sale']} → []interface{}{"new", "sale"}
+func (g *Generator) compositeArg(raw, typ string) (string, bool) {
+	e, err := parser.ParseJSExpr(strings.TrimSpace(raw))
+	if err != nil || !isComposite(e) {
+		return "", false
+	}
+	return g.goLiteralAs(raw, typ)
+}
+
+// localParam returns the type of the parameter a component of the file
+// takes a prop as, or ""
+func (g *Generator) localParam(tag, prop string) string {
+	for _, p := range g.localParams[tag] {
+		if p.Prop == prop {
+			return p.Type
+		}
+	}
+	return ""
+}
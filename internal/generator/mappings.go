@@ -0,0 +1,864 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// ComponentMapping describes how a design-system component is rendered as
+// plain HTML with Tailwind classes.
+type ComponentMapping struct {
+	Tag       string                              // HTML tag rendered instead of the component
+	Class     string                              // base Tailwind classes
+	Attrs     map[string]string                   // static attributes added to the element
+	Variants  map[string]map[string]string        // prop → literal value → classes
+	Props     map[string]func(string) string      // prop → classes computed from its value
+	Drop      []string                            // props with no HTML equivalent
+	Label     string                              // prop rendered as a wrapping <label>
+	Condition string                              // boolean prop controlling whether it renders
+	Defaults  map[string]string                   // prop values the component assumes when they are not given
+	Rename    map[string]string                   // prop → the HTML attribute it sets, without a value for a boolean attribute
+	Children  func(*parser.Element) []parser.Node // children built from props, replacing the element's own
+	Note      string                              // reported as a TODO on every use
+}
+
+// MappingPack maps component names to their HTML rendering for one library
+type MappingPack struct {
+	Name       string
+	Modules    []string // import paths (or prefixes) the components come from
+	Components map[string]ComponentMapping
+}
+
+// classTemplate returns a prop class function substituting the value into format
+func classTemplate(format string) func(string) string {
+	return func(value string) string {
+		if !strings.Contains(format, "%s") {
+			return format
+		}
+		return fmt.Sprintf(format, value)
+	}
+}
+
+// scaledClass multiplies a numeric prop by factor before substituting it,
+// for libraries whose spacing unit differs from Tailwind's 0.25rem
+func scaledClass(format string, factor int) func(string) string {
+	return func(value string) string {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf(format, strconv.Itoa(n*factor))
+	}
+}
+
+// breakpointSpans maps grid breakpoint props (xs, sm, md...) to column spans
+func breakpointSpans(spans map[string]func(string) string, prefixes map[string]string) {
+	for prop, prefix := range prefixes {
+		spans[prop] = classTemplate(prefix + "col-span-%s")
+	}
+}
+
+// themeSpacing maps a spacing prop onto a Tailwind utility: theme keys
+// (p={4}, mt="0.5") share Tailwind's scale, CSS lengths are converted
+func themeSpacing(prefix string) func(string) string {
+	return func(value string) string {
+		if _, err := strconv.ParseFloat(value, 64); err == nil && !strings.HasPrefix(value, "-") {
+			return prefix + "-" + value
+		}
+		if scale := spacingScale(value); scale != "" {
+			return prefix + "-" + scale
+		}
+		return ""
+	}
+}
+
+// themeSize maps a sizing prop: spacing values, the "full" keyword and
+// named sizes such as maxW="md"
+func themeSize(prefix string) func(string) string {
+	spacing := themeSpacing(prefix)
+	return func(value string) string {
+		switch value {
+		case "full", "screen", "min", "max", "fit", "xs", "sm", "md", "lg", "xl", "2xl", "3xl", "4xl", "5xl", "6xl", "7xl", "prose":
+			return prefix + "-" + value
+		}
+		return spacing(value)
+	}
+}
+
+// themeColor maps a colour prop: theme tokens ("gray.100" → bg-gray-100),
+// keywords and literal colours
+func themeColor(prefix string) func(string) string {
+	return func(value string) string {
+		if name, shade, ok := strings.Cut(value, "."); ok {
+			if _, err := strconv.Atoi(shade); err == nil {
+				return prefix + "-" + name + "-" + shade
+			}
+			return ""
+		}
+		return declToTailwind(map[string]string{"text": "color", "bg": "background-color", "border": "border-color"}[prefix], value)
+	}
+}
+
+// themeToken maps a prop whose theme keys are Tailwind's own (rounded="md"
+// → rounded-md), renaming the keys in renamed
+func themeToken(prefix string, renamed map[string]string) func(string) string {
+	return func(value string) string {
+		if cls, ok := renamed[value]; ok {
+			return cls
+		}
+		if _, err := strconv.Atoi(value); value == "" || err == nil || strings.Trim(value, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+			return ""
+		}
+		return prefix + "-" + value
+	}
+}
+
+// keywordClass maps a prop taking the values of the CSS property prop
+func keywordClass(prop string) func(string) string {
+	return func(value string) string {
+		return keywordProps[prop][value]
+	}
+}
+
+// chakraStyleProps returns Chakra's style props, shared by every layout
+// and text component, merged with the component's own props
+func chakraStyleProps(own map[string]func(string) string) map[string]func(string) string {
+	props := map[string]func(string) string{
+		"w":              themeSize("w"),
+		"width":          themeSize("w"),
+		"h":              themeSize("h"),
+		"height":         themeSize("h"),
+		"minW":           themeSize("min-w"),
+		"maxW":           themeSize("max-w"),
+		"minH":           themeSize("min-h"),
+		"maxH":           themeSize("max-h"),
+		"gap":            themeSpacing("gap"),
+		"bg":             themeColor("bg"),
+		"bgColor":        themeColor("bg"),
+		"background":     themeColor("bg"),
+		"color":          themeColor("text"),
+		"borderColor":    themeColor("border"),
+		"fontSize":       themeToken("text", map[string]string{"md": "text-base"}),
+		"fontWeight":     keywordClass("font-weight"),
+		"textAlign":      keywordClass("text-align"),
+		"borderRadius":   themeToken("rounded", map[string]string{"base": "rounded", "none": "rounded-none"}),
+		"rounded":        themeToken("rounded", map[string]string{"base": "rounded", "none": "rounded-none"}),
+		"shadow":         themeToken("shadow", map[string]string{"base": "shadow", "none": "shadow-none"}),
+		"boxShadow":      themeToken("shadow", map[string]string{"base": "shadow", "none": "shadow-none"}),
+		"borderWidth":    themeToken("border", map[string]string{"1px": "border", "0": "border-0", "2px": "border-2", "4px": "border-4"}),
+		"display":        keywordClass("display"),
+		"position":       keywordClass("position"),
+		"overflow":       keywordClass("overflow"),
+		"cursor":         keywordClass("cursor"),
+		"align":          keywordClass("align-items"),
+		"alignItems":     keywordClass("align-items"),
+		"justify":        keywordClass("justify-content"),
+		"justifyContent": keywordClass("justify-content"),
+		"wrap":           keywordClass("flex-wrap"),
+		"flexWrap":       keywordClass("flex-wrap"),
+		"flex":           keywordClass("flex"),
+		"direction":      keywordClass("flex-direction"),
+		"flexDirection":  keywordClass("flex-direction"),
+	}
+	for _, side := range []string{"", "x", "y", "t", "r", "b", "l"} {
+		props["p"+side] = themeSpacing("p" + side)
+		props["m"+side] = themeSpacing("m" + side)
+	}
+	for prop, class := range own {
+		props[prop] = class
+	}
+	return props
+}
+
+var mappingPacks = map[string]*MappingPack{
+	"mui": {
+		Name:    "mui",
+		Modules: []string{"@mui/material", "@material-ui/core"},
+		Components: map[string]ComponentMapping{
+			"Button": {
+				Tag:   "button",
+				Class: "inline-flex items-center justify-center px-4 py-2 rounded font-medium",
+				Attrs: map[string]string{"type": "button"},
+				Variants: map[string]map[string]string{
+					"variant": {
+						"contained": "bg-blue-600 text-white hover:bg-blue-700",
+						"outlined":  "border border-blue-600 text-blue-600 hover:bg-blue-50",
+						"text":      "text-blue-600 hover:bg-blue-50",
+					},
+					"color": {
+						"secondary": "bg-purple-600 text-white",
+						"error":     "bg-red-600 text-white",
+						"success":   "bg-green-600 text-white",
+					},
+					"size": {
+						"small": "text-sm px-2 py-1",
+						"large": "text-lg px-6 py-3",
+					},
+				},
+				Props: map[string]func(string) string{
+					"fullWidth": classTemplate("w-full"),
+				},
+				Drop: []string{"startIcon", "endIcon", "disableElevation"},
+			},
+			"TextField": {
+				Tag:   "input",
+				Class: "block w-full rounded border border-gray-300 px-3 py-2",
+				Props: map[string]func(string) string{
+					"fullWidth": classTemplate("w-full"),
+				},
+				Drop:  []string{"variant", "margin", "helperText", "InputProps"},
+				Label: "label",
+			},
+			"Dialog": {
+				Tag:       "div",
+				Class:     "fixed inset-0 z-50 flex items-center justify-center bg-black/50",
+				Attrs:     map[string]string{"role": "dialog", "aria-modal": "true"},
+				Drop:      []string{"onClose", "maxWidth", "fullWidth"},
+				Condition: "open",
+			},
+			"Grid": {
+				Tag: "div",
+				Props: func() map[string]func(string) string {
+					props := map[string]func(string) string{
+						"container": classTemplate("grid grid-cols-12"),
+						"spacing":   scaledClass("gap-%s", 2),
+					}
+					breakpointSpans(props, map[string]string{"xs": "", "sm": "sm:", "md": "md:", "lg": "lg:", "xl": "xl:"})
+					return props
+				}(),
+				Drop: []string{"item"},
+			},
+		},
+	},
+	"chakra": {
+		Name:    "chakra",
+		Modules: []string{"@chakra-ui/react"},
+		Components: map[string]ComponentMapping{
+			"Button": {
+				Tag:   "button",
+				Class: "inline-flex items-center justify-center px-4 py-2 rounded-md font-semibold",
+				Attrs: map[string]string{"type": "button"},
+				Variants: map[string]map[string]string{
+					"variant": {
+						"solid":   "bg-gray-100 hover:bg-gray-200",
+						"outline": "border border-gray-300",
+						"ghost":   "hover:bg-gray-100",
+						"link":    "text-blue-600 underline",
+					},
+					"colorScheme": {
+						"blue":  "bg-blue-500 text-white",
+						"red":   "bg-red-500 text-white",
+						"green": "bg-green-500 text-white",
+						"teal":  "bg-teal-500 text-white",
+					},
+					"size": {
+						"sm": "text-sm px-3 py-1",
+						"lg": "text-lg px-6 py-3",
+					},
+				},
+				Props: chakraStyleProps(nil),
+				Drop:  []string{"leftIcon", "rightIcon", "isLoading"},
+			},
+			"Input": {
+				Tag:   "input",
+				Class: "block w-full rounded-md border border-gray-300 px-3 py-2",
+				Drop:  []string{"variant", "size"},
+			},
+			"Box": {
+				Tag:   "div",
+				Props: chakraStyleProps(nil),
+			},
+			"Flex": {
+				Tag:   "div",
+				Class: "flex",
+				Props: chakraStyleProps(nil),
+			},
+			"Stack": {
+				Tag:      "div",
+				Class:    "flex",
+				Props:    chakraStyleProps(map[string]func(string) string{"spacing": themeSpacing("gap")}),
+				Defaults: map[string]string{"direction": "column", "spacing": "2"},
+			},
+			"VStack": {
+				Tag:      "div",
+				Class:    "flex flex-col",
+				Props:    chakraStyleProps(map[string]func(string) string{"spacing": themeSpacing("gap")}),
+				Defaults: map[string]string{"align": "center", "spacing": "2"},
+			},
+			"HStack": {
+				Tag:      "div",
+				Class:    "flex flex-row",
+				Props:    chakraStyleProps(map[string]func(string) string{"spacing": themeSpacing("gap")}),
+				Defaults: map[string]string{"align": "center", "spacing": "2"},
+			},
+			"Center": {
+				Tag:   "div",
+				Class: "flex items-center justify-center",
+				Props: chakraStyleProps(nil),
+			},
+			"Spacer": {
+				Tag:   "div",
+				Class: "flex-1",
+			},
+			"Text": {
+				Tag:   "p",
+				Props: chakraStyleProps(nil),
+				Drop:  []string{"noOfLines"},
+			},
+			"Heading": {
+				Tag:   "h2",
+				Class: "font-bold",
+				Variants: map[string]map[string]string{
+					"size": {
+						"4xl": "text-7xl",
+						"3xl": "text-6xl",
+						"2xl": "text-5xl",
+						"xl":  "text-4xl",
+						"lg":  "text-3xl",
+						"md":  "text-xl",
+						"sm":  "text-base",
+						"xs":  "text-sm",
+					},
+				},
+				Props:    chakraStyleProps(nil),
+				Defaults: map[string]string{"size": "xl"},
+			},
+			"Modal": {
+				Tag:       "div",
+				Class:     "fixed inset-0 z-50 flex items-center justify-center bg-black/50",
+				Attrs:     map[string]string{"role": "dialog", "aria-modal": "true"},
+				Drop:      []string{"onClose", "size", "isCentered"},
+				Condition: "isOpen",
+			},
+			"Grid": {
+				Tag:   "div",
+				Class: "grid",
+				Props: map[string]func(string) string{
+					"templateColumns": func(v string) string {
+						if n, ok := repeatColumns(v); ok {
+							return "grid-cols-" + n
+						}
+						return ""
+					},
+					"gap": classTemplate("gap-%s"),
+				},
+			},
+			"SimpleGrid": {
+				Tag:   "div",
+				Class: "grid",
+				Props: map[string]func(string) string{
+					"columns": classTemplate("grid-cols-%s"),
+					"spacing": classTemplate("gap-%s"),
+				},
+			},
+		},
+	},
+	"antd": {
+		Name:    "antd",
+		Modules: []string{"antd"},
+		Components: map[string]ComponentMapping{
+			"Button": {
+				Tag:   "button",
+				Class: "inline-flex items-center justify-center px-4 py-1 rounded border",
+				Attrs: map[string]string{"type": "button"},
+				Variants: map[string]map[string]string{
+					"type": {
+						"primary": "bg-blue-500 text-white border-blue-500",
+						"dashed":  "border-dashed border-gray-300",
+						"link":    "border-transparent text-blue-500",
+						"text":    "border-transparent",
+					},
+					"size": {
+						"small": "text-sm px-2",
+						"large": "text-lg px-5 py-2",
+					},
+				},
+				Props: map[string]func(string) string{
+					"danger": classTemplate("text-red-600 border-red-500"),
+					"block":  classTemplate("w-full"),
+				},
+				Rename: map[string]string{"htmlType": "type"},
+				Drop:   []string{"icon", "loading", "shape"},
+			},
+			"Input": {
+				Tag:   "input",
+				Class: "block w-full rounded border border-gray-300 px-3 py-1",
+				Drop:  []string{"allowClear", "prefix", "suffix", "size"},
+			},
+			"Input.Password": {
+				Tag:   "input",
+				Class: "block w-full rounded border border-gray-300 px-3 py-1",
+				Attrs: map[string]string{"type": "password"},
+				Drop:  []string{"allowClear", "prefix", "size", "visibilityToggle", "iconRender"},
+			},
+			"Input.TextArea": {
+				Tag:   "textarea",
+				Class: "block w-full rounded border border-gray-300 px-3 py-1",
+				Drop:  []string{"allowClear", "autoSize", "showCount"},
+			},
+			"InputNumber": {
+				Tag:   "input",
+				Class: "block w-full rounded border border-gray-300 px-3 py-1",
+				Attrs: map[string]string{"type": "number"},
+				Drop:  []string{"precision", "formatter", "parser", "controls", "addonBefore", "addonAfter", "size"},
+			},
+			"Select": {
+				Tag:      "select",
+				Class:    "block w-full rounded border border-gray-300 px-3 py-1",
+				Children: selectOptions,
+				Rename:   map[string]string{"mode": "multiple"},
+				Drop:     []string{"options", "placeholder", "allowClear", "showSearch", "optionFilterProp", "filterOption", "size", "loading"},
+			},
+			"DatePicker": {
+				Tag:   "input",
+				Class: "block w-full rounded border border-gray-300 px-3 py-1",
+				Attrs: map[string]string{"type": "date"},
+				Drop:  []string{"format", "picker", "showTime", "allowClear", "size", "disabledDate"},
+			},
+			"Modal": {
+				Tag:       "div",
+				Class:     "fixed inset-0 z-50 flex items-center justify-center bg-black/50",
+				Attrs:     map[string]string{"role": "dialog", "aria-modal": "true"},
+				Children:  modalPanel,
+				Drop:      []string{"onCancel", "onOk", "title", "footer", "width", "centered", "destroyOnClose", "okText", "cancelText"},
+				Condition: "open",
+			},
+			"Row": {
+				Tag:   "div",
+				Class: "grid grid-cols-[repeat(24,minmax(0,1fr))]",
+				Props: map[string]func(string) string{
+					"gutter": classTemplate("gap-[%spx]"),
+				},
+			},
+			"Col": {
+				Tag: "div",
+				Props: func() map[string]func(string) string {
+					props := map[string]func(string) string{
+						"span": classTemplate("col-span-%s"),
+					}
+					breakpointSpans(props, map[string]string{"xs": "", "sm": "sm:", "md": "md:", "lg": "lg:", "xl": "xl:"})
+					return props
+				}(),
+			},
+		},
+	},
+	"shadcn": {
+		Name:    "shadcn",
+		Modules: []string{"@/components/ui/", "components/ui/"},
+		Components: map[string]ComponentMapping{
+			"Button": {
+				Tag:   "button",
+				Class: "inline-flex items-center justify-center rounded-md text-sm font-medium h-10 px-4 py-2 bg-primary text-primary-foreground hover:bg-primary/90",
+				Attrs: map[string]string{"type": "button"},
+				Variants: map[string]map[string]string{
+					"variant": {
+						"destructive": "bg-destructive text-destructive-foreground",
+						"outline":     "border border-input bg-background hover:bg-accent",
+						"secondary":   "bg-secondary text-secondary-foreground",
+						"ghost":       "hover:bg-accent hover:text-accent-foreground",
+						"link":        "text-primary underline-offset-4 hover:underline",
+					},
+					"size": {
+						"sm":   "h-9 rounded-md px-3",
+						"lg":   "h-11 rounded-md px-8",
+						"icon": "h-10 w-10",
+					},
+				},
+				Drop: []string{"asChild"},
+			},
+			"Input": {
+				Tag:   "input",
+				Class: "flex h-10 w-full rounded-md border border-input bg-background px-3 py-2 text-sm",
+			},
+			"Dialog": {
+				Tag:       "div",
+				Class:     "fixed inset-0 z-50 flex items-center justify-center bg-black/80",
+				Attrs:     map[string]string{"role": "dialog", "aria-modal": "true"},
+				Drop:      []string{"onOpenChange"},
+				Condition: "open",
+			},
+			"DialogContent": {
+				Tag:   "div",
+				Class: "grid w-full max-w-lg gap-4 border bg-background p-6 shadow-lg sm:rounded-lg",
+			},
+			"Card": {
+				Tag:   "div",
+				Class: "rounded-lg border bg-card text-card-foreground shadow-sm",
+			},
+		},
+	},
+}
+
+// repeatColumns extracts N from a CSS value like "repeat(3, 1fr)"
+func repeatColumns(v string) (string, bool) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "repeat(") {
+		return "", false
+	}
+	inner := strings.TrimPrefix(v, "repeat(")
+	n := strings.TrimSpace(strings.SplitN(inner, ",", 2)[0])
+	if _, err := strconv.Atoi(n); err != nil {
+		return "", false
+	}
+	return n, true
+}
+
+// MappingPackNames returns the names of the built-in mapping packs
+func MappingPackNames() []string {
+	names := make([]string, 0, len(mappingPacks))
+	for name := range mappingPacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseMappings enables the named design-system mapping packs. Components
+// imported from a pack's modules are rendered as HTML instead of being
+// emitted as calls to (non-existent) Go component functions.
+func (g *Generator) UseMappings(names ...string) error {
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		pack, ok := mappingPacks[name]
+		if !ok {
+			return fmt.Errorf("unknown mapping pack %q (available: %s)", name, strings.Join(MappingPackNames(), ", "))
+		}
+		g.packs = append(g.packs, pack)
+	}
+	return nil
+}
+
+// resolveMappings binds the component names imported in a file to the
+// mappings of the enabled packs
+func (g *Generator) resolveMappings(imports []parser.Import) {
+	g.mapped = nil
+	if len(g.packs) == 0 {
+		return
+	}
+	g.mapped = make(map[string]ComponentMapping)
+	for _, imp := range imports {
+		source := strings.Trim(imp.Source, "'\"`")
+		for _, pack := range g.packs {
+			if !pack.matches(source) {
+				continue
+			}
+			for name, alias := range imp.Named {
+				if m, ok := pack.Components[name]; ok {
+					g.mapped[alias] = m
+				}
+				// Members of an imported component: Input.Password
+				for member, m := range pack.Components {
+					if rest, ok := strings.CutPrefix(member, name+"."); ok {
+						g.mapped[alias+"."+rest] = m
+					}
+				}
+			}
+			// shadcn-style default or per-file imports: match on the local name
+			if imp.Default != "" {
+				if m, ok := pack.Components[imp.Default]; ok {
+					g.mapped[imp.Default] = m
+				}
+			}
+		}
+	}
+}
+
+func (p *MappingPack) matches(source string) bool {
+	for _, module := range p.Modules {
+		if source == module || strings.HasPrefix(source, strings.TrimSuffix(module, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// mapElement rewrites a design-system component into the plain element
+// tree described by its mapping. The returned node is generated normally.
+func (g *Generator) mapElement(elem *parser.Element, m ComponentMapping) (parser.Node, []string) {
+	var notes []string
+	if m.Note != "" {
+		notes = append(notes, m.Note)
+	}
+	classes := []string{}
+	if m.Class != "" {
+		classes = append(classes, m.Class)
+	}
+
+	drop := make(map[string]bool, len(m.Drop))
+	for _, name := range m.Drop {
+		drop[name] = true
+	}
+
+	mapped := &parser.Element{
+		Tag:        m.Tag,
+		Children:   elem.Children,
+		SelfClose:  elem.SelfClose,
+		LineNumber: elem.LineNumber,
+	}
+
+	var label *parser.Attribute
+	var condition string
+	var classExpr string
+
+	for _, attr := range elem.Attributes {
+		value, literal := literalAttrValue(attr)
+
+		switch {
+		case attr.IsSpread:
+			mapped.Attributes = append(mapped.Attributes, attr)
+		case m.Condition != "" && attr.Name == m.Condition:
+			if literal {
+				if value == "false" {
+					condition = "false"
+				}
+			} else {
+				condition = attr.Expression.Raw
+			}
+		case m.Label != "" && attr.Name == m.Label:
+			a := attr
+			label = &a
+		case attr.Name == "className" || attr.Name == "class":
+			if literal {
+				classes = append(classes, value)
+			} else {
+				classExpr = attr.Expression.Raw
+			}
+		case m.Variants[attr.Name] != nil:
+			if cls, ok := m.Variants[attr.Name][value]; ok && literal {
+				classes = append(classes, cls)
+			} else if !literal {
+				notes = append(notes, fmt.Sprintf("%s={%s}", attr.Name, attr.Expression.Raw))
+			}
+		case m.Props[attr.Name] != nil:
+			if !literal {
+				notes = append(notes, fmt.Sprintf("%s={%s}", attr.Name, attr.Expression.Raw))
+				continue
+			}
+			if value == "false" {
+				continue
+			}
+			if cls := m.Props[attr.Name](value); cls != "" {
+				classes = append(classes, cls)
+			}
+		case m.Rename[attr.Name] != "":
+			a := attr
+			a.Name = m.Rename[attr.Name]
+			if literal && boolAttrs[attrToMinty(a.Name)] {
+				// mode="multiple" → multiple
+				a.Value, a.Expression = "", parser.Expression{}
+			}
+			mapped.Attributes = append(mapped.Attributes, a)
+		case drop[attr.Name]:
+			continue
+		default:
+			mapped.Attributes = append(mapped.Attributes, attr)
+		}
+	}
+
+	if m.Children != nil {
+		if children := m.Children(elem); children != nil {
+			mapped.Children = children
+			mapped.SelfClose = false
+		}
+	}
+
+	// Prop values the component assumes when they are not given, such
+	// as the column direction of a Chakra Stack
+	defaults := make([]string, 0, len(m.Defaults))
+	for prop := range m.Defaults {
+		if !hasAttribute(elem, prop) {
+			defaults = append(defaults, prop)
+		}
+	}
+	sort.Strings(defaults)
+	for _, prop := range defaults {
+		value := m.Defaults[prop]
+		if cls, ok := m.Variants[prop][value]; ok {
+			classes = append(classes, cls)
+		} else if m.Props[prop] != nil {
+			if cls := m.Props[prop](value); cls != "" {
+				classes = append(classes, cls)
+			}
+		}
+	}
+
+	// Static attributes the component implied (type="button", role=...)
+	keys := make([]string, 0, len(m.Attrs))
+	for k := range m.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !hasAttribute(mapped, k) {
+			mapped.Attributes = append(mapped.Attributes, parser.Attribute{Name: k, Value: m.Attrs[k]})
+		}
+	}
+
+	classAttr := parser.Attribute{Name: "className", Value: strings.Join(classes, " ")}
+	if classExpr != "" {
+		classAttr = parser.Attribute{
+			Name:       "className",
+			Expression: parser.Expression{Raw: "`" + strings.Join(classes, " ") + " ${" + classExpr + "}`"},
+		}
+	}
+	if classAttr.Value != "" || classAttr.Expression.Raw != "" {
+		mapped.Attributes = append([]parser.Attribute{classAttr}, mapped.Attributes...)
+	}
+
+	var node parser.Node = mapped
+	if label != nil {
+		var text parser.Node
+		if v, ok := literalAttrValue(*label); ok {
+			text = &parser.Text{Content: v, LineNumber: elem.LineNumber}
+		} else {
+			text = &parser.Expression{Raw: label.Expression.Raw, LineNumber: elem.LineNumber}
+		}
+		node = &parser.Element{
+			Tag:        "label",
+			Attributes: []parser.Attribute{{Name: "className", Value: "block text-sm font-medium"}},
+			Children:   []parser.Node{text, mapped},
+			LineNumber: elem.LineNumber,
+		}
+	}
+	if condition != "" {
+		node = &parser.Conditional{Condition: condition, Consequent: node, LineNumber: elem.LineNumber}
+	}
+
+	return node, notes
+}
+
+// selectOptions builds the <option> children of an Ant Design Select
+// from its options prop: a literal array of { value, label } objects, or
+// a collection mapped over. A placeholder becomes an empty first option.
+func selectOptions(elem *parser.Element) []parser.Node {
+	line := elem.LineNumber
+	var children []parser.Node
+	for _, attr := range elem.Attributes {
+		if attr.Name != "placeholder" {
+			continue
+		}
+		var text parser.Node = &parser.Expression{Raw: attr.Expression.Raw, LineNumber: line}
+		if v, ok := literalAttrValue(attr); ok {
+			text = &parser.Text{Content: v, LineNumber: line}
+		}
+		children = append(children, &parser.Element{
+			Tag:        "option",
+			Attributes: []parser.Attribute{{Name: "value", Value: ""}},
+			Children:   []parser.Node{text},
+			LineNumber: line,
+		})
+	}
+	option := func(value parser.Attribute, text parser.Node) *parser.Element {
+		return &parser.Element{Tag: "option", Attributes: []parser.Attribute{value}, Children: []parser.Node{text}, LineNumber: line}
+	}
+	for _, attr := range elem.Attributes {
+		if attr.Name != "options" || attr.Expression.Raw == "" {
+			continue
+		}
+		raw := attr.Expression.Raw
+		e, err := parser.ParseJSExpr(raw)
+		if err != nil {
+			return nil
+		}
+		arr, ok := parser.Unparen(e).(*parser.JSArray)
+		if !ok {
+			// options={roles}: an option per item
+			body := option(
+				parser.Attribute{Name: "value", Expression: parser.Expression{Raw: "option.value"}},
+				&parser.Expression{Raw: "option.label", LineNumber: line},
+			)
+			return append(children, &parser.MapExpr{Collection: strings.TrimSpace(raw), ItemVar: "option", Body: body, LineNumber: line})
+		}
+		for _, el := range arr.Elements {
+			obj, ok := parser.Unparen(el).(*parser.JSObject)
+			if !ok {
+				return nil
+			}
+			var value, label string
+			for _, p := range obj.Props {
+				lit, ok := parser.Unparen(p.Value).(*parser.JSLiteral)
+				if !ok {
+					continue
+				}
+				switch p.Key {
+				case "value":
+					value = lit.Value
+				case "label":
+					label = lit.Value
+				}
+			}
+			children = append(children, option(
+				parser.Attribute{Name: "value", Value: value},
+				&parser.Text{Content: orDefault(label, value), LineNumber: line},
+			))
+		}
+		return children
+	}
+	return nil
+}
+
+// modalPanel puts the children of an Ant Design Modal in a panel under
+// its title
+func modalPanel(elem *parser.Element) []parser.Node {
+	line := elem.LineNumber
+	children := elem.Children
+	for _, attr := range elem.Attributes {
+		if attr.Name != "title" {
+			continue
+		}
+		var title parser.Node = &parser.Expression{Raw: attr.Expression.Raw, LineNumber: line}
+		if v, ok := literalAttrValue(attr); ok {
+			title = &parser.Text{Content: v, LineNumber: line}
+		}
+		children = append([]parser.Node{&parser.Element{
+			Tag:        "h2",
+			Attributes: []parser.Attribute{{Name: "className", Value: "mb-4 text-lg font-semibold"}},
+			Children:   []parser.Node{title},
+			LineNumber: line,
+		}}, children...)
+	}
+	return []parser.Node{&parser.Element{
+		Tag:        "div",
+		Attributes: []parser.Attribute{{Name: "className", Value: "w-full max-w-lg rounded-lg bg-white p-6 shadow-lg"}},
+		Children:   children,
+		LineNumber: line,
+	}}
+}
+
+// literalAttrValue returns the static value of an attribute: string values,
+// boolean shorthand (true), and simple literal expressions such as {2}
+func literalAttrValue(attr parser.Attribute) (string, bool) {
+	if attr.Value != "" {
+		return attr.Value, true
+	}
+	raw := strings.TrimSpace(attr.Expression.Raw)
+	if raw == "" {
+		return "true", true
+	}
+	if raw == "true" || raw == "false" {
+		return raw, true
+	}
+	if _, err := strconv.ParseFloat(raw, 64); err == nil {
+		return raw, true
+	}
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return raw[1 : len(raw)-1], true
+	}
+	return "", false
+}
+
+func hasAttribute(elem *parser.Element, name string) bool {
+	for _, attr := range elem.Attributes {
+		if attr.Name == name {
+			return true
+		}
+	}
+	return false
+}
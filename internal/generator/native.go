@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// nativeEquivalents maps the React Native primitives that have a web
+// counterpart to the minty code that plays their part
+var nativeEquivalents = map[string]string{
+	"View":                     "b.Div, a flex column (mi.Class(\"flex flex-col\"))",
+	"SafeAreaView":             "b.Main or b.Div; safe areas are the browser's concern",
+	"KeyboardAvoidingView":     "b.Div; the browser keeps inputs in view",
+	"ScrollView":               "b.Div with overflow-auto",
+	"Text":                     "b.Span, or b.P for a paragraph",
+	"TextInput":                "b.Input (b.Textarea when multiline); onChangeText → hx-trigger",
+	"Image":                    "b.Img, with source={{ uri }} as mi.Src",
+	"ImageBackground":          "b.Div with a background-image style",
+	"Button":                   "b.Button, with title as its text",
+	"Pressable":                "b.Button, onPress → hx-post or hx-get",
+	"TouchableOpacity":         "b.Button, onPress → hx-post or hx-get",
+	"TouchableHighlight":       "b.Button, onPress → hx-post or hx-get",
+	"TouchableWithoutFeedback": "b.Div with hx-trigger=\"click\"",
+	"FlatList":                 "mi.Each over data, calling renderItem for each item",
+	"VirtualizedList":          "mi.Each over the items, paginated with hx-get",
+	"SectionList":              "mi.Each over sections, each with its own mi.Each",
+	"Switch":                   "b.Input(mi.Type(\"checkbox\"))",
+	"ActivityIndicator":        "an htmx-indicator spinner",
+	"Modal":                    "b.Dialog",
+	"RefreshControl":           "a refresh button with hx-get",
+	"Linking":                  "b.A with mi.Href",
+	"Alert":                    "hx-confirm, or a b.Dialog",
+	"StatusBar":                "nothing; browsers draw their own",
+}
+
+// nativeLimits explains why the React Native APIs without a web
+// counterpart cannot be converted
+var nativeLimits = map[string]string{
+	"StyleSheet":          "style objects are not CSS; rewrite them as classes (see -tailwind)",
+	"Animated":            "native animations; use CSS transitions",
+	"LayoutAnimation":     "native animations; use CSS transitions",
+	"Platform":            "there is one platform, the browser",
+	"Dimensions":          "screen size is a CSS media query on the web",
+	"useWindowDimensions": "screen size is a CSS media query on the web",
+	"PixelRatio":          "device pixels are the browser's concern (srcset)",
+	"PanResponder":        "gestures run on the device",
+	"Keyboard":            "the software keyboard belongs to the device",
+	"BackHandler":         "the back button is the browser's history",
+	"AppState":            "app lifecycle has no server-side equivalent",
+	"Vibration":           "device API",
+	"NativeModules":       "calls into native code",
+	"NativeEventEmitter":  "events from native code",
+	"AppRegistry":         "the entry point is the Go server's router",
+}
+
+// NativeEquivalent returns the minty code standing in for a React Native
+// primitive, or why it cannot be converted; ok reports which
+func NativeEquivalent(use parser.NativeUse) (text string, ok bool) {
+	if use.Module == "react-native" {
+		if eq, found := nativeEquivalents[use.Name]; found {
+			return eq, true
+		}
+		if why, found := nativeLimits[use.Name]; found {
+			return why, false
+		}
+		return "no web equivalent", false
+	}
+	switch {
+	case strings.HasPrefix(use.Module, "@react-navigation/"):
+		return "screens become pages; see the route table (-o on a directory)", false
+	case strings.Contains(use.Module, "async-storage"):
+		return "device storage; keep the values in the session or a cookie", false
+	case strings.HasPrefix(use.Module, "expo") || strings.HasPrefix(use.Module, "@expo/"):
+		return "Expo device API", false
+	}
+	return "native library", false
+}
+
+// writeNativeReport writes, in place of components, what a React Native
+// file uses and what it would take to rebuild it for the web: its
+// primitives are not HTML elements, so converting them would only produce
+// El() calls for tags no browser knows
+func (g *Generator) writeNativeReport(file *parser.File) {
+	g.writeln("// =============================================================================")
+	g.writeln("// REACT NATIVE")
+	g.writeln("// =============================================================================")
+	g.writeln("// This file imports react-native, so no code was generated: its primitives")
+	g.writeln("// are not HTML elements. Rebuild its screens with the web equivalents below.")
+	if len(file.Components) > 0 {
+		var names []string
+		for _, comp := range file.Components {
+			names = append(names, comp.Name)
+		}
+		g.writef("// Components: %s\n", strings.Join(names, ", "))
+	}
+
+	var mapped, limits []parser.NativeUse
+	for _, use := range file.Native {
+		if _, ok := NativeEquivalent(use); ok {
+			mapped = append(mapped, use)
+		} else {
+			limits = append(limits, use)
+		}
+	}
+	if len(mapped) > 0 {
+		g.writeln("//")
+		g.writeln("// Web equivalents:")
+		for _, use := range mapped {
+			eq, _ := NativeEquivalent(use)
+			g.writef("//   %s%s → %s\n", use.Name, nativeLines(use.Lines), eq)
+		}
+	}
+	if len(limits) > 0 {
+		g.writeln("//")
+		g.writeln("// TODO: cannot be converted:")
+		for _, use := range limits {
+			why, _ := NativeEquivalent(use)
+			name := use.Name
+			if use.Module != "react-native" {
+				name += " (" + use.Module + ")"
+			}
+			g.writef("//   %s%s: %s\n", name, nativeLines(use.Lines), why)
+		}
+	}
+}
+
+// nativeLines formats the lines a primitive is used on
+func nativeLines(lines []int) string {
+	switch len(lines) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf(" (line %d)", lines[0])
+	}
+	var s []string
+	for _, l := range lines {
+		s = append(s, fmt.Sprint(l))
+	}
+	return " (lines " + strings.Join(s, ", ") + ")"
+}
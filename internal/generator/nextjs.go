@@ -0,0 +1,271 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Frameworks lists the app conventions UseFramework accepts
+var Frameworks = []string{"react", "nextjs", "solid"}
+
+// nextRouteProps are the props the Next.js app router passes to pages
+var nextRouteProps = map[string]bool{"params": true, "searchParams": true}
+
+// UseFramework selects the conventions of the converted app: react, the
+// default, nextjs, which turns the getServerSideProps and
+// getStaticProps of a page into a Go loading function its route calls, or
+// solid, whose sources are read with parser.DesugarSolid first
+func (g *Generator) UseFramework(name string) error {
+	switch name {
+	case "", "react", "solid":
+		g.nextjs = false
+	case "nextjs":
+		g.nextjs = true
+	default:
+		return fmt.Errorf("unknown framework %q (available: %s)", name, strings.Join(Frameworks, ", "))
+	}
+	return nil
+}
+
+// PageLoader is the Go function replacing the data loader of a Next.js
+// page. The route handler calls it and passes its fields to the page.
+type PageLoader struct {
+	Component string            `json:"component"` // page component the props are passed to
+	Func      string            `json:"func"`
+	Kind      string            `json:"kind"`   // getServerSideProps or getStaticProps
+	Fields    map[string]string `json:"fields"` // prop → field of the returned struct
+	Line      int               `json:"line"`
+}
+
+// PageLoaders returns the page loaders generated in the last run
+func (g *Generator) PageLoaders() []PageLoader {
+	return g.pageLoaders
+}
+
+// objectLike reports whether a prop holds an object whose fields the
+// markup reads
+func (g *Generator) objectLike(name string) bool {
+	return isObjectLikeName(strings.ToLower(name)) || g.nextjs && nextRouteProps[name]
+}
+
+// fetchType returns the Go type of the data a fetch loads: the type of
+// the state it is stored in, or for an awaited request the type its name
+// suggests
+func (g *Generator) fetchType(comp *parser.Component, f parser.DataFetch) string {
+	typ := stateType(comp, f.State)
+	if typ != "interface{}" || !f.Await {
+		return typ
+	}
+	if params := g.generateParams([]parser.Prop{{Name: f.State}}); len(params) > 0 {
+		return params[0].Type
+	}
+	return typ
+}
+
+// dedent splits source into lines, removing the indentation the lines
+// after the first have in common
+func dedent(src string) []string {
+	lines := strings.Split(src, "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines[1:] {
+		if len(line) >= indent && indent > 0 {
+			lines[i+1] = line[indent:]
+		}
+		lines[i+1] = strings.TrimRight(lines[i+1], " \t")
+	}
+	return lines
+}
+
+// pageComponent returns the page a file's data loaders feed: the default
+// export, or the first component
+func pageComponent(file *parser.File) *parser.Component {
+	for i := range file.Components {
+		if file.Components[i].Name == file.DefaultExport {
+			return &file.Components[i]
+		}
+	}
+	if len(file.Components) > 0 {
+		return &file.Components[0]
+	}
+	return nil
+}
+
+// writeDataLoaders writes a props struct and a loading function per
+// getServerSideProps or getStaticProps of the file's page. The loader
+// reads the route and query parameters, loads the requests it awaited
+// and fills in the props it returned; the rest of the original body is
+// kept as a comment.
+func (g *Generator) writeDataLoaders(file *parser.File) {
+	g.pageLoaders = nil
+	page := pageComponent(file)
+	if !g.nextjs || page == nil {
+		return
+	}
+	pageParams := g.componentParams(page)
+
+	for _, l := range file.DataLoaders {
+		if l.Kind == "getStaticPaths" {
+			g.writef("// getStaticPaths at line %d is not needed: the route serves every path on request\n\n", l.LineNumber)
+			continue
+		}
+
+		propType := func(prop string) string {
+			for _, p := range pageParams {
+				if p.Prop == prop {
+					return p.Type
+				}
+			}
+			return "interface{}"
+		}
+
+		// The requests load into locals typed like the props they fill
+		comp := &parser.Component{Name: page.Name, Fetches: l.Fetches, LineNumber: l.LineNumber}
+		for _, f := range l.Fetches {
+			typ := g.fetchType(comp, f)
+			for _, v := range l.Props {
+				if v.Value == f.State {
+					typ = propType(v.Name)
+				}
+			}
+			comp.StateVars = append(comp.StateVars, parser.StateVariable{Name: f.State, InitType: typ})
+		}
+
+		typeName := exportName(page.Name) + "Props"
+		loader := PageLoader{
+			Component: page.Name,
+			Func:      "load" + typeName,
+			Kind:      l.Kind,
+			Fields:    map[string]string{},
+			Line:      l.LineNumber,
+		}
+		for _, v := range l.Props {
+			loader.Fields[v.Name] = exportName(toCamelCase(v.Name))
+		}
+
+		g.writef("// %s holds the props %s passes to %s\n", typeName, l.Kind, page.Name)
+		g.writef("type %s struct {\n", typeName)
+		width := 0
+		for _, field := range loader.Fields {
+			width = max(width, len(field))
+		}
+		for _, v := range l.Props {
+			g.writef("\t%-*s %s\n", width, loader.Fields[v.Name], propType(v.Name))
+		}
+		g.writeln("}")
+		g.writeln("")
+
+		g.writef("// %s replaces %s at line %d:\n//\n", loader.Func, l.Kind, l.LineNumber)
+		for _, line := range dedent(l.Body) {
+			g.writef("//\t%s\n", line)
+		}
+		if l.Kind == "getStaticProps" {
+			g.writeln("//")
+			g.writeln("// getStaticProps ran at build time; this runs on every request, so cache")
+			g.writeln("// the result if the data rarely changes.")
+		}
+		g.writef("func %s(r *http.Request) (%s, error) {\n", loader.Func, typeName)
+		g.indent++
+
+		g.currentParams = map[string]bool{}
+		g.objectParams = map[string]bool{}
+		g.component, g.componentArgs = page.Name, nil
+		locals := map[string]bool{}
+		used := map[string]bool{}
+		for _, name := range l.Params {
+			g.writeIndent()
+			g.writef("%s := r.PathValue(%q)\n", toCamelCase(name), name)
+			g.componentArgs = append(g.componentArgs, Param{Name: toCamelCase(name), Type: "string"})
+			locals[name] = true
+		}
+		for _, name := range l.Query {
+			if locals[name] {
+				continue
+			}
+			g.writeIndent()
+			g.writef("%s := r.URL.Query().Get(%q)\n", toCamelCase(name), name)
+			g.componentArgs = append(g.componentArgs, Param{Name: toCamelCase(name), Type: "string"})
+			locals[name] = true
+		}
+		for name := range locals {
+			g.currentParams[name] = true
+		}
+
+		for i, f := range l.Fetches {
+			args := g.loaderArgs(f)
+			names := make([]string, len(args))
+			for i, p := range args {
+				names[i] = p.Name
+				used[p.Name] = true
+			}
+			if i > 0 || len(locals) > 0 {
+				g.writeln("")
+			}
+			g.writeIndent()
+			g.writef("%s, err := %s(%s)\n", toCamelCase(f.State), loaderName(comp, f), strings.Join(names, ", "))
+			g.writeIndent()
+			g.writef("if err != nil {\n")
+			g.writeIndent()
+			g.writef("\treturn %s{}, err\n", typeName)
+			g.writeIndent()
+			g.writeln("}")
+			locals[f.State] = true
+		}
+
+		values := make([]string, len(l.Props))
+		for i, v := range l.Props {
+			typ := propType(v.Name)
+			value := zeroValue(typ) + " /* TODO: " + truncateExpr(v.Value, 40) + " */"
+			if lit, ok := goLiteral(v.Value); ok {
+				value = lit
+			} else if locals[v.Value] {
+				value = toCamelCase(v.Value)
+				used[value] = true
+			} else if v.Value == "[]" || v.Value == "{}" || v.Value == "null" || v.Value == "undefined" {
+				value = zeroValue(typ)
+			}
+			values[i] = value
+		}
+		for _, name := range append(l.Params, l.Query...) {
+			if !used[toCamelCase(name)] {
+				g.writeIndent()
+				g.writef("_ = %s\n", toCamelCase(name))
+				used[toCamelCase(name)] = true
+			}
+		}
+
+		if strings.Contains(l.Body, "notFound") || strings.Contains(l.Body, "redirect") {
+			g.writeln("")
+			g.writeIndent()
+			g.writeln("// TODO: the original returns notFound or a redirect in some cases;")
+			g.writeIndent()
+			g.writeln("// return an error here and handle it in the route")
+		}
+		g.writeln("")
+		g.writeIndent()
+		g.writef("return %s{\n", typeName)
+		for i, v := range l.Props {
+			g.writeIndent()
+			g.writef("\t%-*s %s,\n", width+1, loader.Fields[v.Name]+":", values[i])
+		}
+		g.writeIndent()
+		g.writeln("}, nil")
+		g.indent--
+		g.writeln("}")
+
+		g.writeLoaders(comp)
+		g.writeln("")
+		g.pageLoaders = append(g.pageLoaders, loader)
+	}
+	g.currentParams, g.objectParams, g.component, g.componentArgs = nil, nil, "", nil
+}
@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// keySorts are the sort functions of the key types an iterated object
+// can have
+var keySorts = map[string]string{"string": "sort.Strings", "int": "sort.Ints", "float64": "sort.Float64s"}
+
+// generateObjectMap writes a map over an object or over a count rather
+// than over a collection, reporting false for any other. The keys of an
+// object are ranged over sorted, Go leaving the order of a map's keys
+// undefined: Object.entries(stats).map(([k, v]) => ...) becomes mi.Each
+// over the sorted keys of stats, reading v by k. Array.from({ length: 5
+// }, (_, i) => ...) ranges over five empty items.
+func (g *Generator) generateObjectMap(m *parser.MapExpr) bool {
+	if m.Object == "" && m.Length == "" {
+		return false
+	}
+	outer := g.mapLocals
+	g.mapLocals = jsScope{}
+	for name, typ := range outer {
+		g.mapLocals[name] = typ
+	}
+	defer func() { g.mapLocals = outer }()
+
+	if m.Length != "" {
+		n, ok := g.countBound(m.Length)
+		if !ok {
+			g.generateUnknownObjectMap(m)
+			return true
+		}
+		if m.IndexVar != "" {
+			g.writef("mi.EachWithIndex(make([]struct{}, %s), func(%s int, _ struct{}) mi.H {\n", n, goLocal(m.IndexVar))
+			g.mapLocals[m.IndexVar] = "int"
+		} else {
+			g.writef("mi.Each(make([]struct{}, %s), func(struct{}) mi.H {\n", n)
+		}
+		g.indent++
+		g.generateMapBody(m, "", nil, "")
+		return true
+	}
+
+	obj, ok := g.objectMap(m.Collection)
+	key, value, isMap := mapTypes(obj.typ)
+	sorting := keySorts[key]
+	if !ok || !isMap || sorting == "" {
+		g.generateUnknownObjectMap(m)
+		return true
+	}
+	if x, ok := g.derived[m.Collection]; ok && x.code == goLocal(m.Collection) {
+		// The body may read the object by key too: byStatus[status]
+		g.mapLocals[m.Collection] = x.typ
+	}
+	keys := "keys"
+	if obj.code == keys {
+		keys = "names"
+	}
+	list := g.calledInPlace("[]"+key, []string{
+		fmt.Sprintf("%s := make([]%s, 0, len(%s))", keys, key, obj.code),
+		fmt.Sprintf("for k := range %s {", obj.code),
+		fmt.Sprintf("\t%s = append(%s, k)", keys, keys),
+		"}",
+		fmt.Sprintf("%s(%s)", sorting, keys),
+		"return " + keys,
+	})
+	k := goLocal(m.ItemVar)
+	if m.IndexVar != "" {
+		g.writef("mi.EachWithIndex(%s, func(%s int, %s %s) mi.H {\n", list, goLocal(m.IndexVar), k, key)
+		g.mapLocals[m.IndexVar] = "int"
+	} else {
+		g.writef("mi.Each(%s, func(%s %s) mi.H {\n", list, k, key)
+	}
+	g.indent++
+	g.mapLocals[m.ItemVar] = key
+	if m.ValueVar == "" {
+		g.generateMapBody(m, m.ItemVar, nil, key)
+		return true
+	}
+
+	// The value, unless the body does without it, is read by the key and
+	// is the item the body reads fields from
+	item, elem := g.structType(value), ""
+	read := fmt.Sprintf("%s := %s[%s]", goLocal(m.ValueVar), obj.code, k)
+	switch {
+	case item != nil, value == "map[string]interface{}":
+	case value == "interface{}" && readsFields(m):
+		read = fmt.Sprintf("%s, _ := %s[%s].(map[string]interface{})", goLocal(m.ValueVar), obj.code, k)
+	default:
+		elem = value
+		g.mapLocals[m.ValueVar] = value
+	}
+	if readsVar(m.Body, m.ValueVar) {
+		g.writeIndent()
+		g.writef("%s\n", read)
+	}
+	g.generateMapBody(m, m.ValueVar, item, elem)
+	return true
+}
+
+// generateUnknownObjectMap writes the placeholder of a map over an object
+// whose type is not known, or over a count that does not translate
+func (g *Generator) generateUnknownObjectMap(m *parser.MapExpr) {
+	itemVar := firstNonEmpty(m.ValueVar, m.ItemVar)
+	if m.Length != "" {
+		itemVar = ""
+	}
+	val := itemVar + "Val"
+	if itemVar == "" {
+		val = "_"
+	}
+	if m.IndexVar != "" {
+		g.writef("mi.EachWithIndex([]interface{}{} /* TODO: %s */, func(%s int, %s interface{}) mi.H {\n", commentText(m.Source()), m.IndexVar, val)
+		g.mapLocals[m.IndexVar] = "int"
+	} else {
+		g.writef("mi.Each([]interface{}{} /* TODO: %s */, func(%s interface{}) mi.H {\n", commentText(m.Source()), val)
+	}
+	g.indent++
+	if itemVar != "" {
+		g.writeIndent()
+		g.writef("%s := %s.(map[string]interface{}) // TODO: or use your struct type\n", itemVar, val)
+	}
+	g.generateMapBody(m, itemVar, nil, "")
+}
+
+// countBound translates the length of a count to an int, truncating a
+// float as Array.from does: Math.floor(rating / 2)
+func (g *Generator) countBound(length string) (string, bool) {
+	if n, ok := g.sliceBound(length); ok {
+		return n, true
+	}
+	e, err := parser.ParseJSExpr(length)
+	if err != nil {
+		return "", false
+	}
+	x, ok := g.jsToGo(e, length, g.markupScope())
+	if !ok || x.typ != "float64" {
+		return "", false
+	}
+	return "int(" + x.code + ")", true
+}
+
+// objectMap returns the Go map an iterated object is: a derived variable
+// computed in place, a constant, a parameter or a field of the item
+func (g *Generator) objectMap(name string) (goExpr, bool) {
+	if x, ok := g.derived[name]; ok {
+		return x, true
+	}
+	e, err := parser.ParseJSExpr(name)
+	if err != nil {
+		return goExpr{}, false
+	}
+	return g.jsToGo(e, name, g.markupScope())
+}
+
+// mapTypes splits a Go map type into its key and value types:
+// map[string][]Order gives string and []Order
+func mapTypes(typ string) (key, value string, ok bool) {
+	rest, ok := strings.CutPrefix(typ, "map[")
+	if !ok {
+		return "", "", false
+	}
+	depth := 1
+	for i, c := range rest {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return rest[:i], rest[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// readsFields reports whether the body of a map over the entries of an
+// object reads fields of the values, which are then records
+func readsFields(m *parser.MapExpr) bool {
+	fields, ok := itemFieldUses(&parser.MapExpr{ItemVar: m.ValueVar, Body: m.Body})
+	return ok && len(fields) > 0
+}
+
+// readsVar reports whether any expression under node names the variable
+// name
+func readsVar(node parser.Node, name string) bool {
+	ref := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	found := false
+	walkExprText(node, func(text string) {
+		found = found || ref.MatchString(text)
+	})
+	return found
+}
+
+// comparedLiteral matches a string or number literal a value is compared
+// with
+const comparedLiteral = `('[^']*'|"[^"]*"|-?\d+(?:\.\d+)?)`
+
+// comparedType returns the type of the literals the expressions under
+// node compare the variable name with, or "" if they compare it with
+// none or with literals of different types: size === 'lg' makes size a
+// string, and page !== 1 an int
+func comparedType(node parser.Node, name string) string {
+	ref := `(?:props\.)?` + regexp.QuoteMeta(name)
+	compared := []*regexp.Regexp{
+		regexp.MustCompile(`(?:^|[^.\w$])` + ref + `\s*[!=]==?\s*` + comparedLiteral),
+		regexp.MustCompile(comparedLiteral + `\s*[!=]==?\s*` + ref + `(?:[^.\w$(\[]|$)`),
+	}
+	typ := ""
+	walkExprText(node, func(text string) {
+		for _, re := range compared {
+			for _, m := range re.FindAllStringSubmatch(text, -1) {
+				lit := m[1]
+				t := "int"
+				switch {
+				case strings.HasPrefix(lit, "'"), strings.HasPrefix(lit, `"`):
+					t = "string"
+				case strings.Contains(lit, "."):
+					t = "float64"
+				}
+				if typ != "" && typ != t {
+					typ = "-"
+				} else if typ == "" {
+					typ = t
+				}
+			}
+		}
+	})
+	if typ == "-" {
+		return ""
+	}
+	return typ
+}
+
+// walkExprText calls fn with the text of every expression under node:
+// those in braces, the values of attributes and spreads, conditions and
+// the sources of maps
+func walkExprText(node parser.Node, fn func(text string)) {
+	var walk func(n parser.Node)
+	walk = func(n parser.Node) {
+		switch n := n.(type) {
+		case *parser.Expression:
+			fn(n.Raw)
+		case *parser.Element:
+			for _, attr := range n.Attributes {
+				fn(attr.Expression.Raw)
+				fn(attr.SpreadExpr)
+			}
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.Fragment:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *parser.MapExpr:
+			fn(n.Source())
+			walk(n.Body)
+		case *parser.Conditional:
+			fn(n.Condition)
+			walk(n.Consequent)
+		case *parser.Ternary:
+			fn(n.Condition)
+			walk(n.Consequent)
+			walk(n.Alternate)
+		}
+	}
+	walk(node)
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	builderMethod = regexp.MustCompile(`^[A-Z]\w*$`)
+	mintyOption   = regexp.MustCompile(`^\w+(\.\w+)?$`)
+	packageName   = regexp.MustCompile(`^[a-z_]\w*$`)
+)
+
+// UsePackage sets the package clause of the generated file (default main)
+func (g *Generator) UsePackage(name string) error {
+	if name == "" {
+		name = "main"
+	}
+	if !packageName.MatchString(name) {
+		return fmt.Errorf("invalid package name %q", name)
+	}
+	g.pkg = name
+	return nil
+}
+
+// UseTagMethods maps tags to builder methods (dialog → Dialog renders
+// b.Dialog), taking precedence over the built-in table
+func (g *Generator) UseTagMethods(methods map[string]string) error {
+	for tag, method := range methods {
+		if !builderMethod.MatchString(method) {
+			return fmt.Errorf("tag %s: %q is not a builder method name", tag, method)
+		}
+	}
+	g.tagMethods = methods
+	return nil
+}
+
+// UseAttributes maps attributes to minty option functions (inputMode →
+// mi.InputMode), taking precedence over the built-in table
+func (g *Generator) UseAttributes(options map[string]string) error {
+	for attr, option := range options {
+		if !mintyOption.MatchString(option) {
+			return fmt.Errorf("attribute %s: %q is not a function name", attr, option)
+		}
+	}
+	g.attrOptions = options
+	return nil
+}
+
+// tagMethod returns the builder method for tag, or "" for tags rendered
+// with b.El
+func (g *Generator) tagMethod(tag string) string {
+	if method, ok := g.tagMethods[tag]; ok {
+		return method
+	}
+	if method, ok := g.tagMethods[strings.ToLower(tag)]; ok {
+		return method
+	}
+	return tagToMethod(tag)
+}
+
+// elementOpen returns the start of the builder call rendering tag, up to
+// its first argument: b.Div( or, for tags without a builder method such
+// as custom elements, b.El("my-widget". It reports whether the call
+// already has an argument, the tag, that the next one follows.
+func (g *Generator) elementOpen(builder, tag string) (string, bool) {
+	if method := g.tagMethod(tag); method != "" {
+		return builder + "." + method + "(", false
+	}
+	return fmt.Sprintf("%s.El(%q", builder, tag), true
+}
+
+// attrOption returns the minty option function for attr, or ""
+func (g *Generator) attrOption(attr string) string {
+	if option, ok := g.attrOptions[attr]; ok {
+		return option
+	}
+	return attrToMinty(attr)
+}
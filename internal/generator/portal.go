@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// PortalContainer returns the id of the layout element a portal's markup
+// is swapped into: the element it named, or modal-container for
+// document.body, as in the HTMX modal pattern
+func PortalContainer(p *parser.Portal) string {
+	if id, ok := strings.CutPrefix(p.Container, "#"); ok && isSimpleIdent(strings.ReplaceAll(id, "-", "_")) {
+		return id
+	}
+	return "modal-container"
+}
+
+// writePortalNote explains how to serve a component that rendered into a
+// portal: its function renders the markup as a partial, which htmx swaps
+// into a fixed container of the layout
+func (g *Generator) writePortalNote(comp *parser.Component) {
+	if comp.Portal == nil {
+		return
+	}
+	id := PortalContainer(comp.Portal)
+	where := comp.Portal.Container
+	if where == "body" {
+		where = "document.body"
+	}
+	g.writeln("//")
+	g.writef("// Rendered into a portal on %s. Serve it as a partial swapped into a\n", commentText(where))
+	g.writef("// fixed container of the layout, b.Div(mi.ID(%q)), from the element\n", id)
+	g.writef("// opening it: mi.HtmxGet(%q), mi.HtmxTarget(%q).\n", "/"+toKebabCase(comp.Name), "#"+id)
+}
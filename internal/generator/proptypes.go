@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// propGoType returns the Go type of a prop with a declared type, and
+// false for props whose type is guessed. Function props have no
+// parameter: it is "".
+func propGoType(prop parser.Prop) (string, bool) {
+	switch prop.JSType {
+	case "":
+		return "", false
+	case "string":
+		return "string", true
+	case "number":
+		if strings.Contains(prop.DefaultValue, ".") {
+			return "float64", true
+		}
+		return "int", true
+	case "bool", "boolean":
+		return "bool", true
+	case "array":
+		return "[]interface{}", true
+	case "object":
+		return "map[string]interface{}", true
+	case "node":
+		return "mi.H", true
+	case "func":
+		return "", true
+	}
+	return "interface{}", true
+}
+
+// propObjectLike reports whether a prop's fields are read as those of a
+// map: by its declared type, or else by its name
+func propObjectLike(prop parser.Prop, byName bool) bool {
+	if prop.JSType == "" || prop.JSType == "any" {
+		return byName
+	}
+	return prop.JSType == "object"
+}
@@ -0,0 +1,731 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// reduction is a .reduce() callback translated into a loop over the
+// elements: the accumulator acc starts as init, of type typ, and body
+// updates it for each element x
+type reduction struct {
+	acc, x    string
+	init, typ string
+	body      []string
+}
+
+// reducer translates the callback of one .reduce()
+type reducer struct {
+	g          *Generator
+	acc, x     string // the Go names of the accumulator and the element
+	kept       string // the element as the collection holds it
+	item       *itemType
+	elem       string
+	typ        string // of the accumulator, once known
+	promotable bool   // typ is int from a literal, and may become float64
+}
+
+// reduceLoop translates .reduce((acc, x) => …, init) into the loop
+// computing acc, named acc in Go or after the callback's parameter if
+// acc is empty: sums, products and concatenations, minimums and
+// maximums, updates made on a condition, and, starting from {}, maps
+// grouping, counting, summing or indexing the elements by a key. It
+// reports false for anything else, including a reduce with no initial
+// value.
+func (g *Generator) reduceLoop(op parser.ArrayOp, acc string, item *itemType, elem string) (reduction, bool) {
+	params, body, block, ok := reduceCallback(op)
+	if !ok || len(params) != 2 || len(op.Args) != 1 || !isSimpleIdent(params[0]) || !isSimpleIdent(params[1]) {
+		return reduction{}, false
+	}
+	x := params[1]
+	if acc == "" {
+		if acc = goLocal(params[0]); acc == "list" {
+			acc = "acc"
+		}
+	}
+	if acc == x || acc == x+"Val" {
+		return reduction{}, false
+	}
+	r := &reducer{g: g, acc: acc, x: x, kept: x, item: item, elem: elem}
+	if item == nil && elem == "" {
+		r.kept = x + "Val"
+	}
+	rename := map[string]string{params[0]: acc}
+
+	init, err := parser.ParseJSExpr(op.Args[0])
+	if err != nil {
+		return reduction{}, false
+	}
+	if obj, ok := parser.Unparen(init).(*parser.JSObject); ok && len(obj.Props) == 0 {
+		var lines []string
+		if block != "" {
+			lines, ok = r.keyedBlock(block, rename)
+		} else {
+			e, src, parsed := r.parse(body, rename)
+			lines, ok = r.keyedExpr(e, src)
+			ok = ok && parsed
+		}
+		if !ok {
+			return reduction{}, false
+		}
+		return reduction{acc, x, r.typ + "{}", r.typ, lines}, true
+	}
+
+	initCode, ok := r.start(init, op.Args[0])
+	if !ok {
+		return reduction{}, false
+	}
+	if block != "" {
+		if body, ok = returnedExpr(block); !ok {
+			return reduction{}, false
+		}
+	}
+	e, src, ok := r.parse(body, rename)
+	if !ok {
+		return reduction{}, false
+	}
+	lines, ok := r.update(e, src)
+	if !ok {
+		return reduction{}, false
+	}
+	if r.typ == "float64" && initCode.typ != "float64" {
+		initCode.code = numericAs(initCode, "float64", initCode.code)
+	}
+	return reduction{acc, x, initCode.code, r.typ, lines}, true
+}
+
+// reduceCallback returns the parameters of the callback of a .reduce()
+// and its expression or block body
+func reduceCallback(op parser.ArrayOp) (params []string, body, block string, ok bool) {
+	if op.Params != nil {
+		return op.Params, op.Body, "", true
+	}
+	e, err := parser.ParseJSExpr(op.Raw)
+	if err != nil {
+		return nil, "", "", false
+	}
+	call, ok := e.(*parser.JSCall)
+	if !ok || len(call.Args) == 0 {
+		return nil, "", "", false
+	}
+	fn, ok := parser.Unparen(call.Args[0]).(*parser.JSArrow)
+	if !ok || fn.Block == "" {
+		return nil, "", "", false
+	}
+	for _, p := range fn.Params {
+		params = append(params, strings.TrimSpace(p))
+	}
+	return params, "", fn.Block, true
+}
+
+// start translates the initial value of a scalar accumulator, setting
+// its type: a number, a string or a boolean, or ±Infinity
+func (r *reducer) start(init parser.JSExpr, src string) (goExpr, bool) {
+	switch strings.Join(strings.Fields(src), "") {
+	case "Infinity":
+		r.typ = "float64"
+		return goExpr{"math.Inf(1)", "float64"}, true
+	case "-Infinity":
+		r.typ = "float64"
+		return goExpr{"math.Inf(-1)", "float64"}, true
+	}
+	x, ok := r.g.jsToGo(init, src, jsScope{})
+	if !ok {
+		return goExpr{}, false
+	}
+	switch x.typ {
+	case "string", "bool", "int", "float64":
+		r.typ = x.typ
+	case untypedNumber:
+		r.typ = concreteType(x.typ, x)
+		r.promotable = r.typ == "int"
+	default:
+		return goExpr{}, false
+	}
+	return x, true
+}
+
+// parse parses a JavaScript expression of the callback, renaming the
+// variables renamed
+func (r *reducer) parse(src string, renamed map[string]string) (parser.JSExpr, string, bool) {
+	src = renameIdents(strings.TrimSpace(src), renamed)
+	e, err := parser.ParseJSExpr(src)
+	if err != nil {
+		return nil, "", false
+	}
+	return e, src, true
+}
+
+// renameIdents renames the identifiers of a JavaScript expression; src
+// is returned unchanged if it does not parse
+func renameIdents(src string, renamed map[string]string) string {
+	e, err := parser.ParseJSExpr(src)
+	if err != nil {
+		return src
+	}
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	parser.WalkJS(e, func(x parser.JSExpr) bool {
+		if id, ok := x.(*parser.JSIdent); ok && renamed[id.Name] != "" {
+			edits = append(edits, edit{id.Start, id.End, renamed[id.Name]})
+		}
+		return true
+	})
+	for i := len(edits) - 1; i >= 0; i-- {
+		src = src[:edits[i].start] + edits[i].text + src[edits[i].end:]
+	}
+	return src
+}
+
+// isAcc reports whether e is the accumulator
+func (r *reducer) isAcc(e parser.JSExpr) bool {
+	id, ok := parser.Unparen(e).(*parser.JSIdent)
+	return ok && id.Name == r.acc
+}
+
+// readsAcc reports whether e reads the accumulator
+func (r *reducer) readsAcc(e parser.JSExpr) bool {
+	for _, name := range parser.JSIdentifiers(e) {
+		if name == r.acc {
+			return true
+		}
+	}
+	return false
+}
+
+// value translates an expression of the callback to a value of type typ,
+// or of its own type if typ is empty. The element itself stays as the
+// collection holds it; fields of elements held in maps are read with the
+// accessor of the type.
+func (r *reducer) value(e parser.JSExpr, src, typ string) (goExpr, bool) {
+	e = parser.Unparen(e)
+	if id, ok := e.(*parser.JSIdent); ok && id.Name == r.x {
+		return goExpr{r.kept, itemGoType(r.item, r.elem)}, true
+	}
+	g := r.g
+	outerIn, outerVar, outerItem, outerElem := g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem
+	g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem = true, r.x, r.item, r.elem
+	defer func() {
+		g.inMapBody, g.currentItemVar, g.currentItem, g.currentElem = outerIn, outerVar, outerItem, outerElem
+	}()
+	scope := g.markupScope()
+	if r.typ != "" && !strings.HasPrefix(r.typ, "map[") {
+		scope = scope.with(r.acc, r.typ)
+	}
+	x, ok := g.jsToGo(e, src, scope)
+	if !ok || x.typ != "interface{}" || typ == "" || typ == "interface{}" {
+		return x, ok
+	}
+	return g.markupArg(e, src, typ)
+}
+
+// operand translates a value the accumulator is updated with, to the
+// type of the accumulator. An int accumulator started from a literal
+// becomes float64 for fractional values and for the fields of elements
+// held in maps, all numbers being float64 in JavaScript.
+func (r *reducer) operand(e parser.JSExpr, src string) (string, bool) {
+	typ := r.typ
+	if r.promotable {
+		if x, ok := r.value(e, src, ""); ok && (x.typ == "float64" || x.typ == "interface{}") {
+			typ = "float64"
+		}
+	}
+	x, ok := r.value(e, src, typ)
+	if !ok {
+		return "", false
+	}
+	code, ok := convertArg(x, typ)
+	if ok && typ != r.typ {
+		r.typ, r.promotable = typ, false
+	}
+	return code, ok
+}
+
+// condition translates the test of a conditional update
+func (r *reducer) condition(e parser.JSExpr, src string) (string, bool) {
+	if r.readsAcc(e) {
+		x, ok := r.value(e, src, "")
+		if !ok {
+			return "", false
+		}
+		return goCond(x), true
+	}
+	_, cond, ok := r.g.callbackCondition(r.item, r.elem, []string{r.x}, src)
+	return cond, ok
+}
+
+// update translates the value the callback returns into the statements
+// updating a scalar accumulator
+func (r *reducer) update(e parser.JSExpr, src string) ([]string, bool) {
+	e = parser.Unparen(e)
+	switch n := e.(type) {
+	case *parser.JSBinary:
+		var operand parser.JSExpr
+		operandSrc := src
+		switch {
+		case n.Op == "+":
+			// acc + a + b adds a + b, which reads the same for numbers
+			// and strings
+			first := n.Left
+			for {
+				b, ok := first.(*parser.JSBinary)
+				if !ok || b.Op != "+" {
+					break
+				}
+				first = b.Left
+			}
+			if r.isAcc(first) {
+				operandSrc = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(src[first.Span().End:n.End]), "+"))
+				rest, err := parser.ParseJSExpr(operandSrc)
+				if err != nil {
+					return nil, false
+				}
+				operand = rest
+			} else if r.isAcc(n.Right) && r.typ != "string" {
+				operand = n.Left
+			}
+		case n.Op == "-" && r.isAcc(n.Left), n.Op == "*" && r.isAcc(n.Left):
+			operand = n.Right
+		case n.Op == "*" && r.isAcc(n.Right):
+			operand = n.Left
+		}
+		if operand == nil || r.readsAcc(operand) {
+			break
+		}
+		code, ok := r.operand(operand, operandSrc)
+		if !ok {
+			return nil, false
+		}
+		if code == "1" && n.Op != "*" {
+			return []string{r.acc + strings.Repeat(n.Op[:1], 2)}, true
+		}
+		return []string{fmt.Sprintf("%s %s= %s", r.acc, n.Op, code)}, true
+
+	case *parser.JSCall:
+		fn := parser.MemberPath(n.Callee)
+		if (fn != "Math.max" && fn != "Math.min") || len(n.Args) != 2 || r.typ == "string" || r.typ == "bool" {
+			break
+		}
+		operand := n.Args[1]
+		if !r.isAcc(n.Args[0]) {
+			operand = n.Args[0]
+			if !r.isAcc(n.Args[1]) {
+				break
+			}
+		}
+		if r.readsAcc(operand) {
+			break
+		}
+		code, ok := r.operand(operand, src)
+		if !ok {
+			return nil, false
+		}
+		if r.typ == "float64" {
+			return []string{fmt.Sprintf("%s = %s(%s, %s)", r.acc, mathFuncs[strings.TrimPrefix(fn, "Math.")], r.acc, code)}, true
+		}
+		cmp := ">"
+		if fn == "Math.min" {
+			cmp = "<"
+		}
+		if isSimpleSelector(code) {
+			return []string{fmt.Sprintf("if %s %s %s {", code, cmp, r.acc), fmt.Sprintf("\t%s = %s", r.acc, code), "}"}, true
+		}
+		v := "value"
+		if v == r.acc || v == r.x {
+			v = "v"
+		}
+		return []string{fmt.Sprintf("if %s := %s; %s %s %s {", v, code, v, cmp, r.acc), fmt.Sprintf("\t%s = %s", r.acc, v), "}"}, true
+
+	case *parser.JSConditional:
+		// cond ? acc + x.n : acc updates on cond only
+		test, other := n.Test, n.Then
+		negate := r.isAcc(n.Then)
+		if negate {
+			other = n.Else
+		} else if !r.isAcc(n.Else) {
+			break
+		}
+		inner, ok := r.update(other, src)
+		if !ok {
+			return nil, false
+		}
+		cond, ok := r.condition(test, strings.TrimSpace(test.Span().Text(src)))
+		if !ok {
+			return nil, false
+		}
+		if negate {
+			cond = negateCondition(cond)
+		}
+		lines := []string{"if " + cond + " {"}
+		for _, line := range inner {
+			lines = append(lines, "\t"+line)
+		}
+		return append(lines, "}"), true
+	}
+
+	// Anything else replaces the accumulator
+	if r.isAcc(e) {
+		return nil, false
+	}
+	code, ok := r.operand(e, src)
+	if !ok {
+		return nil, false
+	}
+	return []string{r.acc + " = " + code}, true
+}
+
+// isSimpleSelector reports whether code is a name or a field of one,
+// cheap to read twice
+func isSimpleSelector(code string) bool {
+	return regexp.MustCompile(`^[\w.]+$`).MatchString(code)
+}
+
+// returnedExpr returns the expression of a block body that only returns
+// it
+func returnedExpr(block string) (string, bool) {
+	stmts := statements(block)
+	if len(stmts) != 1 || !returnKeyword.MatchString(stmts[0]) {
+		return "", false
+	}
+	return returnValue(stmts[0])
+}
+
+var (
+	returnKeyword = regexp.MustCompile(`^return\b`)
+	// declaration matches const name = value
+	declaration = regexp.MustCompile(`^(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*([\s\S]+)$`)
+)
+
+// returnValue returns the expression a return statement returns
+func returnValue(stmt string) (string, bool) {
+	value := strings.TrimSpace(returnKeyword.ReplaceAllString(stmt, ""))
+	return value, value != ""
+}
+
+// statements splits a block body into its statements, joining an if to
+// the statement it guards on the next line
+func statements(block string) []string {
+	var stmts []string
+	for _, part := range splitTopLevel(block, ';') {
+		for _, line := range splitTopLevel(part, '\n') {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if n := len(stmts); n > 0 && isBareIf(stmts[n-1]) {
+				stmts[n-1] += " " + line
+				continue
+			}
+			stmts = append(stmts, line)
+		}
+	}
+	return stmts
+}
+
+// isBareIf reports whether stmt is an if with its condition only
+func isBareIf(stmt string) bool {
+	test, rest, ok := ifParts(stmt)
+	return ok && test != "" && rest == ""
+}
+
+// ifParts splits if (test) rest into its test and the statement it
+// guards, without braces
+func ifParts(stmt string) (test, rest string, ok bool) {
+	if !strings.HasPrefix(stmt, "if") {
+		return "", "", false
+	}
+	open := strings.IndexByte(stmt, '(')
+	if open < 0 || strings.TrimSpace(stmt[2:open]) != "" {
+		return "", "", false
+	}
+	depth := 0
+	for i := open; i < len(stmt); i++ {
+		switch stmt[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				rest = strings.TrimSpace(stmt[i+1:])
+				if strings.HasPrefix(rest, "{") && strings.HasSuffix(rest, "}") {
+					rest = strings.TrimSpace(strings.TrimSuffix(rest[1:len(rest)-1], ";"))
+				}
+				return stmt[open+1 : i], rest, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// keyed is the update of a map accumulator: acc[key] gets value appended,
+// added, or set
+type keyed struct {
+	kind       string // "group", "sum" or "index"
+	key, value parser.JSExpr
+	src        string
+}
+
+// keyedExpr translates a callback returning a copy of the accumulator
+// with one key updated: ({ ...acc, [key]: [...(acc[key] || []), x] })
+// groups, ({ ...acc, [key]: (acc[key] || 0) + 1 }) counts, and
+// ({ ...acc, [key]: x }) indexes
+func (r *reducer) keyedExpr(e parser.JSExpr, src string) ([]string, bool) {
+	obj, ok := parser.Unparen(e).(*parser.JSObject)
+	if !ok || len(obj.Props) != 2 || !obj.Props[0].Spread || !r.isAcc(obj.Props[0].Value) || obj.Props[1].Computed == nil {
+		return nil, false
+	}
+	key, value := obj.Props[1].Computed, parser.Unparen(obj.Props[1].Value)
+	k := keyed{kind: "index", key: key, value: value, src: src}
+	switch v := value.(type) {
+	case *parser.JSArray:
+		if len(v.Elements) != 2 {
+			return nil, false
+		}
+		spread, ok := v.Elements[0].(*parser.JSSpread)
+		if !ok || !r.defaulted(spread.X, key, src) {
+			return nil, false
+		}
+		k.kind, k.value = "group", v.Elements[1]
+	case *parser.JSBinary:
+		if v.Op == "+" && r.defaulted(v.Left, key, src) {
+			k.kind, k.value = "sum", v.Right
+		}
+	}
+	return r.keyedUpdate(k)
+}
+
+// keyedBlock translates a block body updating the accumulator in place
+// and returning it: acc[key].push(x) after creating the array, (acc[key]
+// ||= []).push(x), acc[key] = (acc[key] || 0) + 1, acc[key] = x. Local
+// constants the key or value are read from are inlined.
+func (r *reducer) keyedBlock(block string, renamed map[string]string) ([]string, bool) {
+	stmts := statements(block)
+	if len(stmts) < 2 || !returnKeyword.MatchString(stmts[len(stmts)-1]) {
+		return nil, false
+	}
+	if value, _ := returnValue(stmts[len(stmts)-1]); renameIdents(value, renamed) != r.acc {
+		return nil, false
+	}
+	var update *keyed
+	for _, stmt := range stmts[:len(stmts)-1] {
+		if m := declaration.FindStringSubmatch(stmt); m != nil {
+			e, _, ok := r.parse(m[2], renamed)
+			if !ok {
+				return nil, false
+			}
+			if _, isArrow := e.(*parser.JSArrow); isArrow {
+				return nil, false
+			}
+			renamed[m[1]] = "(" + renameIdents(strings.TrimSpace(m[2]), renamed) + ")"
+			continue
+		}
+		if test, rest, ok := ifParts(stmt); ok {
+			// if (!acc[key]) acc[key] = [] makes no difference to a Go
+			// map, whose missing keys read as zero
+			t, src, ok := r.parse(test, renamed)
+			if !ok {
+				return nil, false
+			}
+			u, isNot := parser.Unparen(t).(*parser.JSUnary)
+			if !isNot || u.Op != "!" {
+				return nil, false
+			}
+			key, ok := r.accIndex(u.X)
+			if !ok || !r.initializes(rest, renamed, key, src) {
+				return nil, false
+			}
+			continue
+		}
+		e, src, ok := r.parse(stmt, renamed)
+		if !ok {
+			return nil, false
+		}
+		if _, ok := r.initialization(e); ok {
+			continue
+		}
+		k, ok := r.keyedStatement(e, src)
+		if !ok || update != nil {
+			return nil, false
+		}
+		update = &k
+	}
+	if update == nil {
+		return nil, false
+	}
+	return r.keyedUpdate(*update)
+}
+
+// keyedStatement recognizes the statement of a block body updating the
+// accumulator at a key
+func (r *reducer) keyedStatement(e parser.JSExpr, src string) (keyed, bool) {
+	switch n := e.(type) {
+	case *parser.JSCall:
+		m, ok := n.Callee.(*parser.JSMember)
+		if !ok || m.Index != nil || m.Property != "push" || len(n.Args) != 1 {
+			return keyed{}, false
+		}
+		list := parser.Unparen(m.Object)
+		if a, ok := list.(*parser.JSAssign); ok {
+			// (acc[key] ||= []).push(x)
+			if key, ok := r.initialization(a); ok {
+				return keyed{"group", key, n.Args[0], src}, true
+			}
+			return keyed{}, false
+		}
+		if key, ok := r.accIndex(list); ok {
+			return keyed{"group", key, n.Args[0], src}, true
+		}
+	case *parser.JSAssign:
+		key, ok := r.accIndex(n.Target)
+		if !ok {
+			return keyed{}, false
+		}
+		switch {
+		case n.Op == "+=":
+			return keyed{"sum", key, n.Value, src}, true
+		case n.Op != "=":
+		case !r.readsAcc(n.Value):
+			return keyed{"index", key, n.Value, src}, true
+		default:
+			if b, ok := parser.Unparen(n.Value).(*parser.JSBinary); ok && b.Op == "+" && r.defaulted(b.Left, key, src) {
+				return keyed{"sum", key, b.Right, src}, true
+			}
+		}
+	case *parser.JSUnary:
+		if key, ok := r.accIndex(n.X); ok && n.Op == "++" {
+			return keyed{"sum", key, &parser.JSLiteral{Kind: "number", Value: "1"}, src}, true
+		}
+	}
+	return keyed{}, false
+}
+
+// accIndex returns key for acc[key]
+func (r *reducer) accIndex(e parser.JSExpr) (parser.JSExpr, bool) {
+	m, ok := parser.Unparen(e).(*parser.JSMember)
+	if !ok || m.Index == nil || !r.isAcc(m.Object) {
+		return nil, false
+	}
+	return m.Index, true
+}
+
+// defaulted reports whether e is acc[key] || [] or acc[key] ?? 0, the
+// entry at key or an empty one
+func (r *reducer) defaulted(e parser.JSExpr, key parser.JSExpr, src string) bool {
+	b, ok := parser.Unparen(e).(*parser.JSBinary)
+	if !ok || b.Op != "||" && b.Op != "??" || !isEmptyEntry(b.Right) {
+		return false
+	}
+	k, ok := r.accIndex(b.Left)
+	return ok && sameSource(k, key, src)
+}
+
+// initialization returns the key of acc[key] ||= [], acc[key] ??= 0 or
+// acc[key] = acc[key] || []
+func (r *reducer) initialization(e parser.JSExpr) (parser.JSExpr, bool) {
+	a, ok := parser.Unparen(e).(*parser.JSAssign)
+	if !ok {
+		return nil, false
+	}
+	key, ok := r.accIndex(a.Target)
+	switch {
+	case !ok:
+		return nil, false
+	case a.Op == "||=" || a.Op == "??=":
+		return key, isEmptyEntry(a.Value)
+	case a.Op == "=":
+		return key, isEmptyEntry(a.Value) || r.defaulted(a.Value, key, "")
+	}
+	return nil, false
+}
+
+// initializes reports whether stmt gives acc[key] an empty entry
+func (r *reducer) initializes(stmt string, renamed map[string]string, key parser.JSExpr, keySrc string) bool {
+	e, src, ok := r.parse(stmt, renamed)
+	if !ok {
+		return false
+	}
+	k, ok := r.initialization(e)
+	return ok && strings.Join(strings.Fields(k.Span().Text(src)), "") == strings.Join(strings.Fields(key.Span().Text(keySrc)), "")
+}
+
+// isEmptyEntry reports whether e is [] or 0
+func isEmptyEntry(e parser.JSExpr) bool {
+	switch n := parser.Unparen(e).(type) {
+	case *parser.JSArray:
+		return len(n.Elements) == 0
+	case *parser.JSLiteral:
+		return n.Kind == "number" && n.Value == "0"
+	}
+	return false
+}
+
+// sameSource reports whether two expressions parsed from src read the
+// same, src being empty when they need not be compared
+func sameSource(a, b parser.JSExpr, src string) bool {
+	if src == "" {
+		return true
+	}
+	return strings.Join(strings.Fields(a.Span().Text(src)), "") == strings.Join(strings.Fields(b.Span().Text(src)), "")
+}
+
+// keyedUpdate translates the update of a map accumulator, setting its
+// type: the key must be a string, number or boolean
+func (r *reducer) keyedUpdate(k keyed) ([]string, bool) {
+	if r.readsAcc(k.key) || r.readsAcc(k.value) {
+		return nil, false
+	}
+	key, ok := r.value(k.key, k.src, "string")
+	switch {
+	case !ok:
+		return nil, false
+	case key.typ == untypedNumber:
+		key.typ = concreteType(key.typ, key)
+	case key.typ != "string" && key.typ != "int" && key.typ != "float64" && key.typ != "bool":
+		return nil, false
+	}
+	var lines []string
+	if !isSimpleSelector(key.code) {
+		name := "key"
+		if name == r.x || name == r.acc {
+			name = "k"
+		}
+		lines = append(lines, name+" := "+key.code)
+		key.code = name
+	}
+	entry := fmt.Sprintf("%s[%s]", r.acc, key.code)
+
+	switch k.kind {
+	case "group", "index":
+		v, ok := r.value(k.value, k.src, "")
+		if !ok || v.typ == "nil" {
+			return nil, false
+		}
+		typ := concreteType(v.typ, v)
+		if k.kind == "group" {
+			r.typ = fmt.Sprintf("map[%s][]%s", key.typ, typ)
+			return append(lines, fmt.Sprintf("%s = append(%s, %s)", entry, entry, numericAs(v, typ, v.code))), true
+		}
+		r.typ = fmt.Sprintf("map[%s]%s", key.typ, typ)
+		return append(lines, fmt.Sprintf("%s = %s", entry, numericAs(v, typ, v.code))), true
+	case "sum":
+		v, ok := r.value(k.value, k.src, "float64")
+		if !ok {
+			return nil, false
+		}
+		typ := concreteType(v.typ, v)
+		if !isNumeric(typ) {
+			return nil, false
+		}
+		r.typ = fmt.Sprintf("map[%s]%s", key.typ, typ)
+		if v.code == "1" {
+			return append(lines, entry+"++"), true
+		}
+		return append(lines, fmt.Sprintf("%s += %s", entry, numericAs(v, typ, v.code))), true
+	}
+	return nil, false
+}
@@ -0,0 +1,342 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// sliceType names the Go struct holding a Redux slice's state:
+// counter → CounterState
+func sliceType(name string) string {
+	return exportName(toCamelCase(name)) + "State"
+}
+
+// sliceField returns the state field of a slice the store read selects,
+// if the slice is declared in the file
+func (g *Generator) sliceField(read parser.StoreRead) (parser.StateVariable, bool) {
+	if read.Store != "" {
+		return parser.StateVariable{}, false
+	}
+	slice := g.slices[read.Slice]
+	if slice == nil {
+		return parser.StateVariable{}, false
+	}
+	for _, sv := range slice.State {
+		if sv.Name == read.Field {
+			return sv, true
+		}
+	}
+	return parser.StateVariable{}, false
+}
+
+// storeParams returns the parameters replacing the values a component
+// selects from the Redux store or a Zustand store. A field of a slice or
+// store declared in the file has the field's type, a whole slice or store
+// its state struct; other values are typed by name.
+func (g *Generator) storeParams(comp *parser.Component) []Param {
+	var params []Param
+	for _, read := range comp.StoreReads {
+		p := Param{Name: toCamelCase(read.Name)}
+		if sv, ok := g.sliceField(read); ok && sv.InitType != "" {
+			p.Type = sv.InitType
+		} else if sv, ok := g.storeField(read); ok && sv.InitType != "" {
+			p.Type = sv.InitType
+		} else if read.Slice != "" && read.Field == "" {
+			p.Type = "*" + sliceType(read.Slice)
+		} else if read.Store != "" && read.Field == "" {
+			p.Type = "*" + storeType(read.Store)
+		} else {
+			p.Type = g.generateParams([]parser.Prop{{Name: read.Name}})[0].Type
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// writeSlices writes a state struct per Redux slice of the file, with a
+// constructor returning the initial state and a method per case reducer.
+// Reducers that only assign, update or append to state fields are
+// translated; the others are kept as a comment to port by hand.
+func (g *Generator) writeSlices(file *parser.File) {
+	for _, slice := range file.Slices {
+		typeName := sliceType(slice.Name)
+		width := 0
+		for _, sv := range slice.State {
+			width = max(width, len(exportName(toCamelCase(sv.Name))))
+		}
+
+		g.writef("// %s is the server-side state of the %s slice (createSlice at line %d).\n", typeName, slice.Name, slice.LineNumber)
+		g.writeln("// Keep one per session or application and pass its fields to the")
+		g.writeln("// components that select them with useSelector.")
+		g.writef("type %s struct {\n", typeName)
+		for _, sv := range slice.State {
+			typ := sv.InitType
+			if typ == "" {
+				typ = "interface{}"
+			}
+			g.writef("\t%-*s %s\n", width, exportName(toCamelCase(sv.Name)), typ)
+		}
+		g.writeln("}")
+		g.writeln("")
+
+		g.writef("// New%s returns the initial state of the %s slice\n", typeName, slice.Name)
+		g.writef("func New%s() *%s {\n", typeName, typeName)
+		var values []string
+		var fields []string
+		for _, sv := range slice.State {
+			value, ok := goLiteral(sv.InitValue)
+			if !ok && sv.InitValue != "[]" && sv.InitValue != "{}" {
+				value, ok = g.compositeArg(sv.InitValue, firstNonEmpty(sv.InitType, "interface{}"))
+			}
+			if !ok {
+				if sv.InitValue != "[]" && sv.InitValue != "{}" && sv.InitValue != "null" && sv.InitValue != "undefined" {
+					fields = append(fields, exportName(toCamelCase(sv.Name)))
+					values = append(values, zeroValue(sv.InitType)+" /* TODO: "+truncateExpr(sv.InitValue, 40)+" */")
+				}
+				continue
+			}
+			fields = append(fields, exportName(toCamelCase(sv.Name)))
+			values = append(values, value)
+		}
+		if len(fields) == 0 {
+			g.writef("\treturn &%s{}\n", typeName)
+		} else {
+			g.writef("\treturn &%s{\n", typeName)
+			for i, field := range fields {
+				g.writef("\t\t%-*s %s,\n", width+1, field+":", values[i])
+			}
+			g.writeln("\t}")
+		}
+		g.writeln("}")
+
+		for _, r := range slice.Reducers {
+			g.writeln("")
+			g.writeReducer(&slice, typeName, r)
+		}
+		g.writeln("")
+	}
+}
+
+// writeReducer writes the method replacing a case reducer
+func (g *Generator) writeReducer(slice *parser.ReduxSlice, typeName string, r parser.Reducer) {
+	method := exportName(r.Name)
+	stmts, payload, ok := translateReducer(slice, r)
+
+	param := ""
+	if r.Payload {
+		param = "payload " + payload
+	}
+	if ok {
+		g.writef("// %s replaces the %s reducer of the %s slice\n", method, r.Name, slice.Name)
+	} else {
+		g.writef("// %s replaces the %s reducer at line %d:\n//\n", method, r.Name, r.LineNumber)
+		for _, line := range dedent(r.Body) {
+			g.writef("//\t%s\n", line)
+		}
+	}
+	g.writef("func (s *%s) %s(%s) {\n", typeName, method, param)
+	if !ok {
+		g.writeln("\t// TODO: port the reducer")
+	}
+	for _, stmt := range stmts {
+		g.writef("\t%s\n", stmt)
+	}
+	g.writeln("}")
+}
+
+// storeExpr translates an expression of a reducer or store action into Go:
+// the fields of the states become fields of the receiver s, and locals
+// may be read if they are plain values. It reports false for anything
+// else, such as calls, callbacks or fields of the locals.
+func storeExpr(src string, states []string, locals map[string]bool, types map[string]string) (string, bool) {
+	src = strings.TrimSpace(src)
+	if lit, ok := goLiteral(src); ok {
+		return lit, true
+	}
+	if strings.ContainsAny(src, "'`") || strings.Contains(src, "?.") {
+		return "", false
+	}
+	src = strings.NewReplacer("===", "==", "!==", "!=", "null", "nil").Replace(src)
+	valid := true
+	for _, state := range states {
+		if state == "" {
+			continue
+		}
+		field := regexp.MustCompile(`(^|[^\w$.])` + regexp.QuoteMeta(state) + `\.(\w+)`)
+		src = field.ReplaceAllStringFunc(src, func(m string) string {
+			sub := field.FindStringSubmatch(m)
+			if _, ok := types[sub[2]]; !ok {
+				valid = false
+			}
+			return sub[1] + "s." + exportName(toCamelCase(sub[2]))
+		})
+	}
+	for _, loc := range reducerWord.FindAllStringIndex(src, -1) {
+		word := src[loc[0]:loc[1]]
+		switch {
+		case word == "s" || word == "true" || word == "false" || word == "nil":
+		case strings.HasPrefix(word, "."):
+			// A field of s; the fields of locals are not known
+			if loc[0] == 0 || src[loc[0]-1] != 's' || loc[0] > 1 && isIdentByte(src[loc[0]-2]) {
+				valid = false
+			}
+		case locals[word]:
+		default:
+			valid = false
+		}
+	}
+	return src, valid
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+var (
+	// reducerAssign matches state.value = expr, and += -= *=
+	reducerAssign = regexp.MustCompile(`^(\w+)\.(\w+)\s*([-+*]?=)\s*([^=].*)$`)
+	// reducerStep matches state.value++ and state.value--
+	reducerStep = regexp.MustCompile(`^(\w+)\.(\w+)\s*(\+\+|--)$`)
+	// reducerPush matches state.items.push(expr)
+	reducerPush = regexp.MustCompile(`^(\w+)\.(\w+)\.push\((.+)\)$`)
+	// reducerWord matches the identifiers of a translated expression
+	reducerWord = regexp.MustCompile(`\.?[A-Za-z_$][\w$]*`)
+)
+
+// translateReducer translates the statements of a case reducer into Go
+// statements on the receiver s, returning the payload's type and whether
+// every statement translated
+func translateReducer(slice *parser.ReduxSlice, r parser.Reducer) (stmts []string, payload string, ok bool) {
+	if len(r.Params) == 0 {
+		return nil, "interface{}", false
+	}
+	state := strings.TrimSpace(r.Params[0])
+	action := ""
+	if len(r.Params) > 1 {
+		action = strings.TrimSpace(r.Params[1])
+	}
+	types := map[string]string{}
+	for _, sv := range slice.State {
+		types[sv.Name] = sv.InitType
+	}
+
+	payload = ""
+	expr := func(src string) (string, bool) {
+		if handlerIdent.MatchString(action) {
+			src = regexp.MustCompile(`\b`+regexp.QuoteMeta(action)+`\.payload\b`).ReplaceAllString(src, "payload")
+		}
+		return storeExpr(src, []string{state}, map[string]bool{"payload": true}, types)
+	}
+	// typePayload types the payload by the field it is stored in
+	typePayload := func(value, typ string) {
+		if strings.TrimSpace(value) == "payload" && payload == "" {
+			payload = typ
+		}
+	}
+
+	body := r.Body
+	if slice.Initial != "" && (body == slice.Initial || body == "return "+slice.Initial+";" || body == "return "+slice.Initial) {
+		// Returning the initial state resets the slice
+		return []string{"*s = *New" + sliceType(slice.Name) + "()"}, "interface{}", true
+	}
+	if strings.HasPrefix(body, "{") {
+		return nil, "interface{}", false
+	}
+	ok = true
+	for _, line := range strings.FieldsFunc(body, func(c rune) bool { return c == '\n' || c == ';' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if m := reducerStep.FindStringSubmatch(line); m != nil && m[1] == state && types[m[2]] != "" {
+			stmts = append(stmts, "s."+exportName(toCamelCase(m[2]))+m[3])
+			continue
+		}
+		if m := reducerPush.FindStringSubmatch(line); m != nil && m[1] == state && strings.HasPrefix(types[m[2]], "[]") {
+			value, valid := expr(m[3])
+			if !valid {
+				ok = false
+				break
+			}
+			typePayload(value, strings.TrimPrefix(types[m[2]], "[]"))
+			field := "s." + exportName(toCamelCase(m[2]))
+			stmts = append(stmts, field+" = append("+field+", "+value+")")
+			continue
+		}
+		if m := reducerAssign.FindStringSubmatch(line); m != nil && m[1] == state && types[m[2]] != "" {
+			value, valid := expr(m[4])
+			if !valid {
+				ok = false
+				break
+			}
+			typePayload(value, types[m[2]])
+			stmts = append(stmts, "s."+exportName(toCamelCase(m[2]))+" "+m[3]+" "+value)
+			continue
+		}
+		ok = false
+		break
+	}
+	if payload == "" {
+		payload = "interface{}"
+		for _, stmt := range stmts {
+			if strings.Contains(stmt, "payload") {
+				// Used other than stored in a field: the type is unknown
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		return nil, payload, false
+	}
+	return stmts, payload, true
+}
+
+// dispatchIn returns the Redux action an event handler dispatches, or the
+// Zustand action it calls, if the current component has one
+func (g *Generator) dispatchIn(body string) (parser.Dispatch, bool) {
+	for _, d := range g.dispatches {
+		name := d.Action
+		if d.Store != "" {
+			if strings.TrimSpace(body) == d.Local {
+				return d, true
+			}
+			name = d.Local
+		}
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\s*\(`).MatchString(body) {
+			return d, true
+		}
+	}
+	return parser.Dispatch{}, false
+}
+
+// generateDispatch posts an event that dispatches a Redux action, or
+// calls a Zustand action, to the endpoint running it on the server
+func (g *Generator) generateDispatch(d parser.Dispatch, handler *parser.EventHandler, tag string) {
+	endpoint := g.handlerEndpoint("POST", toKebabCase(d.Action), handler, tag)
+	call := fmt.Sprintf("dispatch(%s(%s))", d.Action, truncateExpr(strings.Join(d.Args, ", "), 40))
+	arg := "payload"
+	if d.Store != "" {
+		call = fmt.Sprintf("%s(%s)", d.Local, truncateExpr(strings.Join(d.Args, ", "), 40))
+		if store := g.stores[d.Store]; store != nil {
+			for _, a := range store.Actions {
+				if a.Name == d.Action && len(a.Params) == 1 {
+					arg = strings.TrimSpace(a.Params[0])
+				}
+			}
+		}
+	}
+	if len(d.Args) == 1 {
+		if lit, ok := goLiteral(d.Args[0]); ok {
+			g.writef("mi.HtmxPost(\"%s?%s=%s\")", endpoint, arg, strings.Trim(lit, `"`))
+			g.write(", mi.HtmxSwap(\"outerHTML\")")
+			g.writef(" /* %s */", call)
+			return
+		}
+	}
+	g.writef("mi.HtmxPost(%q)", endpoint)
+	g.write(", mi.HtmxSwap(\"outerHTML\")")
+	g.writef(" /* %s */", call)
+}
@@ -0,0 +1,179 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// domRef is a useRef ref attached to an element, found by its id
+type domRef struct {
+	name string // the ref variable: inputRef
+	id   string // the element's id: input-ref
+	line int    // line of the useRef call
+	own  bool   // the element has an id of its own
+}
+
+// resolveRefs gives the elements the current component attaches a
+// useRef ref to with ref={inputRef} an id, input-ref, unless they have
+// one. Ids are kept unique within the file: input-ref-2 for another.
+func (g *Generator) resolveRefs(comp *parser.Component) {
+	g.refs = nil
+	lines := map[string]int{}
+	for _, hook := range comp.Hooks {
+		if hook.Type == "useRef" && hook.Name != "" {
+			lines[hook.Name] = hook.LineNumber
+		}
+	}
+	if len(lines) == 0 || comp.Body == nil {
+		return
+	}
+	walkElements(comp.Body, func(elem *parser.Element) {
+		name := attrExprRaw(elem, "ref")
+		line, ok := lines[name]
+		if !ok || g.refs[name] != nil {
+			return
+		}
+		ref := &domRef{name: name, line: line}
+		if id := attrString(elem, "id"); id != "" {
+			ref.id, ref.own = id, true
+			g.uniqueID(id)
+		} else {
+			ref.id = g.uniqueID(toKebabCase(name))
+		}
+		if g.refs == nil {
+			g.refs = map[string]*domRef{}
+		}
+		g.refs[name] = ref
+	})
+}
+
+// uniqueID returns id, or id-2, id-3... if it is taken in the file
+func (g *Generator) uniqueID(id string) string {
+	if g.ids == nil {
+		g.ids = map[string]bool{}
+	}
+	unique := id
+	for n := 2; g.ids[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	g.ids[unique] = true
+	return unique
+}
+
+// refAttr returns the attribute a ref={inputRef} becomes: the id of the
+// element, or "" when the element's own id stands for it. It reports
+// false for refs that are not the component's useRef refs.
+func (g *Generator) refAttr(attr *parser.Attribute) (string, bool) {
+	ref := g.refs[strings.TrimSpace(attr.Expression.Raw)]
+	if attr.Name != "ref" || ref == nil {
+		return "", false
+	}
+	if ref.own {
+		return "", true
+	}
+	return fmt.Sprintf("mi.ID(%q)", ref.id), true
+}
+
+// writeRefNote lists the ids the component's refs became
+func (g *Generator) writeRefNote() {
+	if len(g.refs) == 0 {
+		return
+	}
+	var refs []*domRef
+	for _, ref := range g.refs {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].line < refs[j].line })
+	g.writeln("//")
+	g.writeln("// Refs converted to element ids (see the useRef notes):")
+	for _, ref := range refs {
+		g.writef("//   %s (useRef, line %d) → #%s\n", ref.name, ref.line, ref.id)
+	}
+}
+
+// refCurrent matches the DOM node of a ref: inputRef.current
+var refCurrent = regexp.MustCompile(`\b([A-Za-z_$][\w$]*)\.current\b`)
+
+// refAction returns the browser code of a handler that only acts on the
+// DOM nodes of refs, () => inputRef.current.focus(), with each node
+// looked up by its id. It reports false for handlers doing anything
+// else, which need an htmx endpoint.
+func (g *Generator) refAction(handler *parser.EventHandler) (string, bool) {
+	if len(g.refs) == 0 || len(handler.SetterCalls) > 0 {
+		return "", false
+	}
+	e, err := parser.ParseJSExpr(strings.TrimSpace(handler.HandlerBody))
+	if err != nil {
+		return "", false
+	}
+	fn, ok := parser.Unparen(e).(*parser.JSArrow)
+	if !ok {
+		return "", false
+	}
+	var statements []string
+	if fn.Body != nil {
+		statements = []string{fn.Body.Span().Text(strings.TrimSpace(handler.HandlerBody))}
+	} else {
+		statements = strings.Split(fn.Block, ";")
+	}
+	var code []string
+	for _, stmt := range statements {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		s, err := parser.ParseJSExpr(stmt)
+		if err != nil {
+			return "", false
+		}
+		call, ok := parser.Unparen(s).(*parser.JSCall)
+		if !ok {
+			return "", false
+		}
+		method, ok := call.Callee.(*parser.JSMember)
+		if !ok || !domMethods[method.Property] {
+			return "", false
+		}
+		node := strings.TrimSpace(method.Object.Span().Text(stmt))
+		m := refCurrent.FindStringSubmatch(node)
+		if m == nil || m[0] != strings.TrimSuffix(node, "?") || g.refs[m[1]] == nil {
+			return "", false
+		}
+		code = append(code, refCurrent.ReplaceAllStringFunc(stmt, func(s string) string {
+			if ref := g.refs[refCurrent.FindStringSubmatch(s)[1]]; ref != nil {
+				return fmt.Sprintf("document.getElementById('%s')", ref.id)
+			}
+			return s
+		}))
+	}
+	if len(code) == 0 {
+		return "", false
+	}
+	return strings.Join(code, "; "), true
+}
+
+// domMethods are the DOM methods a handler may call on a ref's node
+// in the browser
+var domMethods = map[string]bool{
+	"focus": true, "blur": true, "select": true, "click": true,
+	"scrollIntoView": true, "scrollTo": true, "scrollBy": true,
+	"showModal": true, "show": true, "close": true,
+	"play": true, "pause": true, "reset": true, "requestSubmit": true,
+}
+
+// refsIn returns the refs a handler body reads, as "inputRef is #input-ref"
+func (g *Generator) refsIn(body string) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, m := range refCurrent.FindAllStringSubmatch(body, -1) {
+		if ref := g.refs[m[1]]; ref != nil && !seen[ref.name] {
+			seen[ref.name] = true
+			refs = append(refs, ref.name+" is #"+ref.id)
+		}
+	}
+	return refs
+}
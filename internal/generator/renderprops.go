@@ -0,0 +1,275 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// isRenderProp reports whether a prop passes markup to render as a
+// function: render, renderItem, renderEmpty
+func isRenderProp(name string) bool {
+	if name == "render" {
+		return true
+	}
+	return strings.HasPrefix(name, "render") && len(name) > 6 && name[6] >= 'A' && name[6] <= 'Z'
+}
+
+// resolveRenderProps types the render props of the file's components
+// from how each calls them: renderItem(item) inside items.map() takes
+// an Item
+func (g *Generator) resolveRenderProps(file *parser.File) {
+	g.renderProps = map[string]map[string]string{}
+	for i := range file.Components {
+		comp := &file.Components[i]
+		for _, prop := range comp.Props {
+			if !isRenderProp(prop.Name) {
+				continue
+			}
+			if g.renderProps[comp.Name] == nil {
+				g.renderProps[comp.Name] = map[string]string{}
+			}
+			g.renderProps[comp.Name][prop.Name] = g.renderPropType(comp, prop.Name)
+		}
+	}
+}
+
+// renderPropType returns the Go function type of a render prop of comp,
+// func(Item) mi.H, with one parameter per argument of its first call
+func (g *Generator) renderPropType(comp *parser.Component, name string) string {
+	var types []string
+	found := false
+	walkRenderCalls(comp.Body, nil, func(raw string, m *parser.MapExpr) {
+		args, ok := renderCall(raw, name)
+		if !ok || found {
+			return
+		}
+		found = true
+		for _, arg := range args {
+			typ := "interface{}"
+			if m != nil && arg == m.ItemVar && m.Object == "" && m.Length == "" {
+				typ = "map[string]interface{}"
+				if t := g.items[comp.Name][collectionRoot(comp, m.Collection)]; t != nil {
+					typ = t.name
+				}
+			} else if m != nil && arg == m.IndexVar {
+				typ = "int"
+			}
+			types = append(types, typ)
+		}
+	})
+	return "func(" + strings.Join(types, ", ") + ") mi.H"
+}
+
+// walkRenderCalls calls fn with the source of every expression of the
+// markup node, and the innermost map it is in
+func walkRenderCalls(node parser.Node, m *parser.MapExpr, fn func(raw string, m *parser.MapExpr)) {
+	switch n := node.(type) {
+	case *parser.Element:
+		for _, child := range n.Children {
+			walkRenderCalls(child, m, fn)
+		}
+	case *parser.Fragment:
+		for _, child := range n.Children {
+			walkRenderCalls(child, m, fn)
+		}
+	case *parser.MapExpr:
+		walkRenderCalls(n.Body, n, fn)
+	case *parser.Conditional:
+		walkRenderCalls(n.Consequent, m, fn)
+	case *parser.Ternary:
+		walkRenderCalls(n.Consequent, m, fn)
+		walkRenderCalls(n.Alternate, m, fn)
+	case *parser.Expression:
+		fn(strings.TrimSpace(n.Raw), m)
+	}
+}
+
+// renderCall returns the arguments of a call of the function name:
+// renderItem(item, i)
+func renderCall(raw, name string) ([]string, bool) {
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return nil, false
+	}
+	call, ok := parser.Unparen(e).(*parser.JSCall)
+	if !ok || call.New {
+		return nil, false
+	}
+	callee, ok := call.Callee.(*parser.JSIdent)
+	if !ok || callee.Name != name {
+		return nil, false
+	}
+	var args []string
+	for _, arg := range call.Args {
+		args = append(args, strings.TrimSpace(arg.Span().Text(raw)))
+	}
+	return args, true
+}
+
+// translateRenderCall translates a call of one of the current
+// component's render props, renderItem(item), or reports false
+func (g *Generator) translateRenderCall(raw string) (string, bool) {
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return "", false
+	}
+	call, ok := parser.Unparen(e).(*parser.JSCall)
+	if !ok {
+		return "", false
+	}
+	callee, ok := call.Callee.(*parser.JSIdent)
+	if !ok || !isRenderProp(callee.Name) || !g.currentParams[callee.Name] {
+		return "", false
+	}
+	args, _ := renderCall(raw, callee.Name)
+	for i, arg := range args {
+		if isSimpleIdent(arg) && (g.currentParams[arg] || g.inMapBody) {
+			args[i] = toCamelCase(arg)
+		} else {
+			args[i] = g.translateExprValue(arg)
+		}
+	}
+	return toCamelCase(callee.Name) + "(" + strings.Join(args, ", ") + ")", true
+}
+
+// renderPropArg translates the arrow function passed as a render prop of
+// component tag into a Go closure: renderItem={(user) => <UserRow ... />}
+// becomes func(user User) mi.H { return UserRow(...) }
+func (g *Generator) renderPropArg(tag string, attr parser.Attribute) (string, bool) {
+	raw := strings.TrimSpace(attr.Expression.Raw)
+	e, err := parser.ParseJSExpr(raw)
+	if err != nil {
+		return "", false
+	}
+	fn, ok := parser.Unparen(e).(*parser.JSArrow)
+	if !ok || fn.Body == nil {
+		return "", false
+	}
+	line := attr.Expression.LineNumber
+	if line == 0 {
+		line = g.currentLine
+	}
+	body := renderFunction(raw, line)
+	if body == nil {
+		return "", false
+	}
+
+	types := paramTypes(g.renderPropTypeOf(tag, attr.Name))
+	var params []string
+	var item *itemType
+	itemVar := ""
+	for i, p := range fn.Params {
+		if !isSimpleIdent(p) {
+			return "", false
+		}
+		typ := "interface{}"
+		if i < len(types) {
+			typ = types[i]
+		}
+		if i == 0 {
+			itemVar = p
+			item = g.itemTypeNamed(typ)
+		}
+		params = append(params, p+" "+typ)
+	}
+
+	// The parameters read like the item of a map
+	outerIn, outerVar, outerItem, outerParams := g.inMapBody, g.currentItemVar, g.currentItem, g.currentParams
+	g.inMapBody, g.currentItemVar, g.currentItem = itemVar != "", itemVar, item
+	g.currentParams = map[string]bool{}
+	for name, ok := range outerParams {
+		g.currentParams[name] = ok
+	}
+	for _, p := range fn.Params {
+		g.currentParams[p] = true
+	}
+	defer func() {
+		g.inMapBody, g.currentItemVar, g.currentItem, g.currentParams = outerIn, outerVar, outerItem, outerParams
+	}()
+
+	code := g.capture(func() {
+		g.writef("func(%s) mi.H {\n", strings.Join(params, ", "))
+		g.indent++
+		g.writeIndent()
+		if elem, ok := body.(*parser.Element); ok && isComponentName(elem.Tag) {
+			if _, mapped := g.mapped[elem.Tag]; !mapped {
+				g.write("return ")
+				g.generateNode(body, "b")
+				g.write("\n")
+				g.indent--
+				g.writeIndent()
+				g.write("}")
+				return
+			}
+		}
+		g.write("return func(b *mi.Builder) mi.Node {\n")
+		g.indent++
+		g.writeIndent()
+		g.write("return ")
+		g.generateNode(body, "b")
+		g.write("\n")
+		g.indent--
+		g.writeIndent()
+		g.write("}\n")
+		g.indent--
+		g.writeIndent()
+		g.write("}")
+	})
+	return code, true
+}
+
+// renderPropTypeOf returns the type of a render prop of a component of
+// this file or one imported from a converted file
+func (g *Generator) renderPropTypeOf(tag, prop string) string {
+	if typ, ok := g.renderProps[tag][prop]; ok {
+		return typ
+	}
+	if imp, ok := g.imported[tag]; ok {
+		for _, p := range imp.Params {
+			if p.Prop == prop {
+				return p.Type
+			}
+		}
+	}
+	return ""
+}
+
+// paramTypes returns the parameter types of func(A, B) mi.H
+func paramTypes(fn string) []string {
+	inner, ok := strings.CutPrefix(fn, "func(")
+	if !ok {
+		return nil
+	}
+	inner, _, _ = strings.Cut(inner, ")")
+	if inner == "" {
+		return nil
+	}
+	types := strings.Split(inner, ",")
+	for i := range types {
+		types[i] = strings.TrimSpace(types[i])
+	}
+	return types
+}
+
+// itemTypeNamed returns the generated item struct of the given name
+func (g *Generator) itemTypeNamed(name string) *itemType {
+	for _, types := range g.items {
+		for _, t := range types {
+			if t.name == name {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// capture returns what fn writes instead of writing it
+func (g *Generator) capture(fn func()) string {
+	outer := g.output
+	g.output = strings.Builder{}
+	fn()
+	code := g.output.String()
+	g.output = outer
+	return code
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// ServerActionEndpoint returns the URL the generated forms post to in
+// place of calling a server action: createTodo → /actions/create-todo
+func ServerActionEndpoint(name string) string {
+	return "/actions/" + toKebabCase(name)
+}
+
+// renderingOrder returns the components of a server components file with
+// the server components first, which convert as they are, and the client
+// components after them. Other files keep their order.
+func renderingOrder(comps []parser.Component) []parser.Component {
+	var server, client []parser.Component
+	for _, comp := range comps {
+		if comp.Rendering == parser.RenderClient {
+			client = append(client, comp)
+		} else {
+			server = append(server, comp)
+		}
+	}
+	return append(server, client...)
+}
+
+// writeRenderingBanner opens the section of the server or the client
+// components
+func (g *Generator) writeRenderingBanner(rendering string) {
+	g.writeln("// =============================================================================")
+	switch rendering {
+	case parser.RenderServer:
+		g.writeln("// SERVER COMPONENTS: rendered on the server, converted directly")
+	case parser.RenderClient:
+		g.writeln("// CLIENT COMPONENTS: interactive, their state and events need HTMX or mintydyn")
+	}
+	g.writeln("// =============================================================================")
+	g.writeln("")
+}
+
+// writeRenderingNote says in a component's doc comment where it rendered
+// under the server components conventions
+func (g *Generator) writeRenderingNote(comp *parser.Component) {
+	switch comp.Rendering {
+	case parser.RenderServer:
+		g.writeln("//")
+		g.writeln("// Server component: renders the same on the server, nothing to port.")
+	case parser.RenderClient:
+		g.writeln("//")
+		g.writef("// Client component (%s): its interactive parts need HTMX or mintydyn.\n", strings.Join(comp.Interactive, ", "))
+	}
+}
+
+// serverActionAttr returns the hx-post of a form action={createTodo} or
+// formAction={createTodo} that calls a server action, or ""
+func (g *Generator) serverActionAttr(attr *parser.Attribute) string {
+	if attr.Name != "action" && attr.Name != "formAction" {
+		return ""
+	}
+	name := strings.TrimSpace(attr.Expression.Raw)
+	if !g.serverActions[name] {
+		return ""
+	}
+	return fmt.Sprintf("mi.HtmxPost(%q)", ServerActionEndpoint(name))
+}
+
+// writeServerActions lists the file's server actions with the endpoint
+// each becomes
+func (g *Generator) writeServerActions(file *parser.File) {
+	if len(file.ServerActions) == 0 {
+		return
+	}
+	g.writeln("// =============================================================================")
+	g.writeln("// SERVER ACTIONS")
+	g.writeln("// =============================================================================")
+	for _, action := range file.ServerActions {
+		g.writef("// Line %d: %s → POST %s\n", action.LineNumber, action.Name, ServerActionEndpoint(action.Name))
+		g.writeln("//   TODO: port its body to a handler; forms using it post there with hx-post")
+	}
+	g.writeln("")
+}
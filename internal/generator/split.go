@@ -0,0 +1,43 @@
+package generator
+
+import "strings"
+
+// SplitFile is one file of a split conversion
+type SplitFile struct {
+	Component string // the component it holds; "" for the shared file
+	Source    string
+}
+
+// SplitOutput divides a generated file into one file per component and a
+// shared file with the rest: context types, loaders, stores, validation
+// and the notes. Every file gets the package clause and the imports its
+// own code uses. The shared file comes first.
+func SplitOutput(output string, stats []ComponentStat) []SplitFile {
+	start := strings.Index(output, "import (\n")
+	end := strings.Index(output, "\n)\n")
+	if start < 0 || end < start {
+		return []SplitFile{{Source: output}}
+	}
+	preamble := output[:start]
+	imports := strings.Split(strings.TrimSpace(output[start+len("import (\n"):end]), "\n")
+	body := output[end+len("\n)\n"):]
+
+	var files []SplitFile
+	for _, stat := range stats {
+		i := strings.Index(body, stat.Code)
+		if stat.Code == "" || i < 0 {
+			continue
+		}
+		body = body[:i] + strings.TrimPrefix(body[i+len(stat.Code):], "\n")
+		files = append(files, SplitFile{
+			Component: stat.Name,
+			Source:    preamble + importBlock(imports, stat.Code) + "\n" + stat.Code,
+		})
+	}
+	body = strings.TrimLeft(body, "\n")
+	shared := SplitFile{Source: preamble + importBlock(imports, body)}
+	if body != "" {
+		shared.Source += "\n" + body
+	}
+	return append([]SplitFile{shared}, files...)
+}
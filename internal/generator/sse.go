@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// SSEFile is the Go file holding the generated Server-Sent Events handlers
+const SSEFile = "sse.go"
+
+// SSEStream is a realtime state variable served as a Server-Sent Events
+// stream instead of being polled or pushed client-side
+type SSEStream struct {
+	State    string `json:"state"`    // state variable the stream replaces
+	Endpoint string `json:"endpoint"` // URL of the stream
+	Source   string `json:"source"`   // what it replaces (EventSource, WebSocket, polling)
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line"`
+}
+
+// UseRealtime binds realtime state variables to their SSE endpoints. The
+// element rendering each variable subscribes to the stream.
+func (g *Generator) UseRealtime(streams []SSEStream) {
+	g.realtime = streams
+}
+
+// realtimeAttrs returns the htmx sse attributes for an element whose
+// children render a realtime state variable, or "" if there is none. Each
+// stream is attached to the first such element only.
+func (g *Generator) realtimeAttrs(elem *parser.Element) string {
+	for _, stream := range g.realtime {
+		if g.realtimeUsed[stream.State] || !rendersState(elem.Children, stream.State) {
+			continue
+		}
+		if g.realtimeUsed == nil {
+			g.realtimeUsed = map[string]bool{}
+		}
+		g.realtimeUsed[stream.State] = true
+		return fmt.Sprintf("mi.Attr(\"hx-ext\", \"sse\"), mi.Attr(\"sse-connect\", %q), mi.Attr(\"sse-swap\", %q)",
+			stream.Endpoint, stream.State)
+	}
+	return ""
+}
+
+// rendersState reports whether any of the nodes reads the variable directly
+func rendersState(nodes []parser.Node, state string) bool {
+	ref := regexp.MustCompile(`\b` + regexp.QuoteMeta(state) + `\b`)
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *parser.Expression:
+			if ref.MatchString(n.Raw) {
+				return true
+			}
+		case *parser.MapExpr:
+			if ref.MatchString(n.Source()) {
+				return true
+			}
+		case *parser.Conditional:
+			if ref.MatchString(n.Condition) {
+				return true
+			}
+		case *parser.Ternary:
+			if ref.MatchString(n.Condition) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SSESource returns a Go file with a streaming handler stub per realtime
+// state variable and a RegisterEvents function wiring them up, in package pkg
+func SSESource(pkg string, streams []SSEStream) string {
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Endpoint < streams[j].Endpoint })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// Generated by reminty - Server-Sent Events endpoints for realtime state\n\n")
+	b.WriteString("import (\n\t\"fmt\"\n\t\"net/http\"\n\t\"strings\"\n\t\"time\"\n)\n\n")
+
+	b.WriteString("// RegisterEvents registers the Server-Sent Events endpoints on mux\n")
+	b.WriteString("func RegisterEvents(mux *http.ServeMux) {\n")
+	for _, s := range streams {
+		fmt.Fprintf(&b, "\tmux.HandleFunc(%q, %s)\n", "GET "+s.Endpoint, streamName(s.State))
+	}
+	b.WriteString("}\n")
+
+	for _, s := range streams {
+		where := fmt.Sprintf("line %d", s.Line)
+		if s.File != "" {
+			where = fmt.Sprintf("%s:%d", s.File, s.Line)
+		}
+		fmt.Fprintf(&b, "\n// %s pushes %s updates to the page (replaces the %s at %s)\n",
+			streamName(s.State), s.State, s.Source, where)
+		fmt.Fprintf(&b, "func %s(w http.ResponseWriter, r *http.Request) {\n", streamName(s.State))
+		b.WriteString(`	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+`)
+		fmt.Fprintf(&b, "\t\t\t// TODO: load the current %s and render its fragment\n", s.State)
+		fmt.Fprintf(&b, "\t\t\twriteEvent(w, %q, %q)\n", s.State, "<!-- "+s.State+" -->")
+		b.WriteString("\t\t\tflusher.Flush()\n\t\t}\n\t}\n}\n")
+	}
+
+	b.WriteString(`
+// writeEvent writes one SSE message; multi-line data gets a data: field per line
+func writeEvent(w http.ResponseWriter, event, data string) {
+	fmt.Fprintf(w, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+`)
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
+
+func streamName(state string) string {
+	return "stream" + exportName(state)
+}
@@ -0,0 +1,282 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// StoriesFile is the file holding the golden file check the converted
+// Storybook stories share
+const StoriesFile = "stories_test.go"
+
+// storyAction matches the args Storybook passes as actions: onClick: fn(),
+// onSubmit: action('submitted'). The generated components take no
+// callbacks, so the tests leave them out.
+var storyAction = regexp.MustCompile(`^on[A-Z]`)
+
+// writeStories writes a Storybook file as a test rendering each of its
+// stories with their args as the component's arguments, and comparing
+// the HTML with a golden file
+func (g *Generator) writeStories(s *parser.Stories) {
+	name := s.Component
+	if name == "" {
+		// title: 'Forms/Sign Up' → TestSignUpStories
+		title := s.Title[strings.LastIndex(s.Title, "/")+1:]
+		name = ""
+		for _, word := range strings.Fields(title) {
+			name += exportName(word)
+		}
+	}
+	dir := storiesDir(s)
+
+	what := name
+	if s.Title != "" {
+		what = s.Title
+	}
+	g.writef("// Test%sStories renders the stories of %s and compares each\n", exportName(name), what)
+	g.writef("// with its golden file in testdata/stories/%s; go test -update\n", dir)
+	g.writeln("// records them.")
+	g.writef("func Test%sStories(t *testing.T) {\n", exportName(name))
+	g.indent++
+	g.writeln("\tstories := []struct {")
+	g.writeln("\t\tname string")
+	g.writeln("\t\th    mi.H")
+	g.writeln("\t}{")
+	g.indent++
+	for _, story := range s.Stories {
+		node := g.storyNode(s, story)
+		g.writeIndent()
+		if node == nil {
+			g.writef("// TODO: %s (line %d) renders with a function reminty could not convert\n", story.Name, story.LineNumber)
+			continue
+		}
+		g.writef("{%q, ", story.Name)
+		if elem, ok := node.(*parser.Element); ok && isComponentRef(elem.Tag) {
+			g.generateNode(node, "b")
+		} else {
+			g.write("func(b *mi.Builder) mi.Node {\n")
+			g.indent++
+			g.writeIndent()
+			g.write("return ")
+			g.generateNode(node, "b")
+			g.write("\n")
+			g.indent--
+			g.writeIndent()
+			g.write("}")
+		}
+		g.write("},\n")
+	}
+	g.indent--
+	g.writeln("\t}")
+	g.writeln("\tfor _, s := range stories {")
+	g.writeln("\t\tt.Run(s.name, func(t *testing.T) {")
+	g.writef("\t\t\tcheckGolden(t, %q+s.name, s.h)\n", dir+"/")
+	g.writeln("\t\t})")
+	g.writeln("\t}")
+	g.indent--
+	g.writeln("}")
+}
+
+// storyNode returns the markup a story renders: its render function with
+// the args spread into it, or else the component with the args as props.
+// Story args override the args of the default export.
+func (g *Generator) storyNode(s *parser.Stories, story parser.Story) parser.Node {
+	args := append([]parser.StoryArg(nil), s.Args...)
+	for _, arg := range story.Args {
+		replaced := false
+		for i := range args {
+			if args[i].Name == arg.Name {
+				args[i], replaced = arg, true
+			}
+		}
+		if !replaced {
+			args = append(args, arg)
+		}
+	}
+
+	render := story.Render
+	if render == "" {
+		render = s.Render
+	}
+	if render == "" {
+		if s.Component == "" {
+			return nil
+		}
+		elem := &parser.Element{Tag: s.Component, SelfClose: true, LineNumber: story.LineNumber}
+		spreadArgs(elem, args)
+		return elem
+	}
+	node := renderFunction(render, story.LineNumber)
+	if node == nil {
+		return nil
+	}
+	param := ""
+	if e, err := parser.ParseJSExpr(render); err == nil {
+		if fn, ok := parser.Unparen(e).(*parser.JSArrow); ok && len(fn.Params) > 0 {
+			param = fn.Params[0]
+		}
+	}
+	if param != "" {
+		applyArgs(node, param, args)
+	}
+	return node
+}
+
+// applyArgs replaces the uses of a render function's args parameter in
+// node: {...args} spreads and args.label references
+func applyArgs(node parser.Node, param string, args []parser.StoryArg) {
+	elem, ok := node.(*parser.Element)
+	if !ok {
+		if frag, ok := node.(*parser.Fragment); ok {
+			for _, child := range frag.Children {
+				applyArgs(child, param, args)
+			}
+		}
+		return
+	}
+	attrs := elem.Attributes
+	elem.Attributes = nil
+	for _, attr := range attrs {
+		switch {
+		case attr.IsSpread && attr.SpreadExpr == param:
+			spreadArgs(elem, args)
+			continue
+		case attr.Expression.Raw != "":
+			if arg, ok := argOf(strings.TrimSpace(attr.Expression.Raw), param, args); ok {
+				a := argAttribute(arg, elem.LineNumber)
+				a.Name = attr.Name
+				attr = a
+			}
+		}
+		elem.Attributes = append(elem.Attributes, attr)
+	}
+	for i, child := range elem.Children {
+		if expr, ok := child.(*parser.Expression); ok {
+			if arg, ok := argOf(strings.TrimSpace(expr.Raw), param, args); ok {
+				elem.Children[i] = argChild(arg, expr.LineNumber)
+			}
+			continue
+		}
+		applyArgs(child, param, args)
+	}
+}
+
+// argOf returns the arg an args.name reference reads
+func argOf(raw, param string, args []parser.StoryArg) (parser.StoryArg, bool) {
+	name, ok := strings.CutPrefix(raw, param+".")
+	if !ok {
+		return parser.StoryArg{}, false
+	}
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return parser.StoryArg{}, false
+}
+
+// spreadArgs adds args to elem as props, and the children arg as its
+// children unless it has some
+func spreadArgs(elem *parser.Element, args []parser.StoryArg) {
+	for _, arg := range args {
+		switch {
+		case storyAction.MatchString(arg.Name):
+		case arg.Name == "children":
+			if len(elem.Children) == 0 {
+				elem.Children = []parser.Node{argChild(arg, elem.LineNumber)}
+				elem.SelfClose = false
+			}
+		default:
+			elem.Attributes = append(elem.Attributes, argAttribute(arg, elem.LineNumber))
+		}
+	}
+}
+
+// argAttribute returns an arg as the JSX attribute passing it
+func argAttribute(arg parser.StoryArg, line int) parser.Attribute {
+	if s, ok := stringLiteral(arg.Value); ok {
+		return parser.Attribute{Name: arg.Name, Value: s}
+	}
+	return parser.Attribute{Name: arg.Name, Expression: parser.Expression{Raw: arg.Value, LineNumber: line}}
+}
+
+// argChild returns the children arg as the node it renders
+func argChild(arg parser.StoryArg, line int) parser.Node {
+	if s, ok := stringLiteral(arg.Value); ok {
+		return &parser.Text{Content: s, LineNumber: line}
+	}
+	if strings.HasPrefix(arg.Value, "<") {
+		pad := strings.Repeat("\n", max(line-1, 0))
+		if node := parser.NewParser(parser.NewLexer(pad + arg.Value).Tokenize()).ParseJSX(); node != nil {
+			return node
+		}
+	}
+	return &parser.Expression{Raw: arg.Value, LineNumber: line}
+}
+
+// storiesDir returns the directory below testdata/stories holding the
+// golden files of a Storybook file: its title in lower case, or else
+// its component's name (Example/Button → example/button)
+func storiesDir(s *parser.Stories) string {
+	if s.Title == "" {
+		return strings.ToLower(s.Component)
+	}
+	return storyPath(s.Title)
+}
+
+// storyPath returns a story title as a slash-separated path of
+// lower-case, dash-separated names
+func storyPath(title string) string {
+	parts := strings.Split(title, "/")
+	for i, p := range parts {
+		parts[i] = strings.Join(strings.Fields(strings.ToLower(p)), "-")
+	}
+	return strings.Join(parts, "/")
+}
+
+// StoriesSource returns the Go file declaring the -update flag and the
+// golden file check of the converted stories, in package pkg
+func StoriesSource(pkg string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// Generated by reminty - golden file check of the converted Storybook stories\n\n")
+	b.WriteString("import (\n\t\"bytes\"\n\t\"flag\"\n\t\"os\"\n\t\"path/filepath\"\n\t\"testing\"\n\n\tmi \"github.com/ha1tch/minty\"\n)\n\n")
+	b.WriteString(`var update = flag.Bool("update", false, "record the rendered stories as their golden files")
+
+// checkGolden renders h and compares the HTML with the golden file
+// testdata/stories/<name>.html, or records it with -update
+func checkGolden(t *testing.T, name string, h mi.H) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := mi.Render(h, &buf); err != nil {
+		t.Fatalf("rendering %s: %v", name, err)
+	}
+	golden := filepath.Join("testdata", "stories", filepath.FromSlash(name)+".html")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to record it)", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("%s renders differently from %s:\n got: %s\nwant: %s", name, golden, buf.Bytes(), want)
+	}
+}
+`)
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
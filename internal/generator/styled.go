@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/assets"
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// styledComponent is a compiled styled-components declaration
+type styledComponent struct {
+	parser.StyledComponent
+	Class   string   // generated class name
+	CSS     string   // compiled static CSS
+	Dynamic []string // parts that could not be compiled
+	Props   []string // props read by the dynamic parts
+}
+
+var (
+	styledPropsAccess = regexp.MustCompile(`props\.(\w+)`)
+	styledDestructure = regexp.MustCompile(`\(\s*\{([^}]*)\}\s*\)\s*=>`)
+)
+
+// Stylesheet returns the CSS compiled from styled components in the last
+// call to Generate, or "" if there were none
+func (g *Generator) Stylesheet() string {
+	var b strings.Builder
+	for _, sc := range g.styled {
+		if sc.CSS == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "/* %s (line %d) */\n%s\n", sc.Name, sc.LineNumber, sc.CSS)
+	}
+	return b.String()
+}
+
+// resolveStyled compiles the file's styled components and registers them
+// as mappings, so usages render as the underlying tag with the generated class
+func (g *Generator) resolveStyled(decls []parser.StyledComponent) {
+	g.styled = nil
+	if len(decls) == 0 {
+		return
+	}
+	if g.mapped == nil {
+		g.mapped = make(map[string]ComponentMapping)
+	}
+
+	helpers := make(map[string]parser.StyledComponent)
+	for _, decl := range decls {
+		if decl.Kind != "" {
+			helpers[decl.Name] = decl
+		}
+	}
+
+	byName := make(map[string]*styledComponent, len(decls))
+	for _, decl := range decls {
+		sc := &styledComponent{StyledComponent: decl}
+		body, exprs := inlineStyledHelpers(decl.CSS, decl.Interpolations, helpers, 0)
+		sc.Class = assets.StyledClassName(decl.Name, body)
+		if decl.Kind == "keyframes" {
+			sc.CSS = fmt.Sprintf("@keyframes %s {\n%s}\n", sc.Class, reindent(body, "  "))
+			if len(exprs) > 0 {
+				sc.CSS, sc.Dynamic = "", []string{body}
+			}
+		} else if decl.Kind == "" {
+			sc.CSS, sc.Dynamic = assets.CompileStyled(sc.Class, body)
+		}
+		for _, expr := range exprs {
+			if expr == "attrs(...)" {
+				sc.Dynamic = append(sc.Dynamic, ".attrs(...)")
+			}
+			sc.Props = append(sc.Props, styledProps(expr)...)
+		}
+		sc.Props = uniqueSorted(sc.Props)
+		g.styled = append(g.styled, sc)
+		byName[decl.Name] = sc
+	}
+
+	for _, sc := range g.styled {
+		if sc.Kind != "" {
+			continue
+		}
+		// styled(Other) where Other is styled too: inherit its tag and classes
+		tag, class, root := sc.Tag, sc.Class, sc.Base
+		drop := sc.Props
+		attrs := make(map[string]string, len(sc.Attrs))
+		for base, depth := byName[sc.Base], 0; base != nil && depth < len(decls); base, depth = byName[base.Base], depth+1 {
+			tag, root = base.Tag, base.Base
+			class = base.Class + " " + class
+			drop = append(drop, base.Props...)
+			for k, v := range base.Attrs {
+				attrs[k] = v
+			}
+		}
+		for k, v := range sc.Attrs {
+			attrs[k] = v
+		}
+		if tag == "" {
+			tag = root
+		}
+
+		m := ComponentMapping{Tag: tag, Class: class, Attrs: attrs, Drop: drop}
+		if len(sc.Dynamic) > 0 {
+			m.Note = "has dynamic styles (see STYLED COMPONENTS)"
+		}
+		g.mapped[sc.Name] = m
+	}
+}
+
+// inlineStyledHelpers substitutes ${fragment} with the body of a css“
+// helper and ${animation} with the generated keyframes name. It returns
+// the new body and the interpolations still left in it.
+func inlineStyledHelpers(body string, exprs []string, helpers map[string]parser.StyledComponent, depth int) (string, []string) {
+	var left []string
+	for _, expr := range exprs {
+		h, ok := helpers[expr]
+		if !ok || depth > len(helpers) {
+			left = append(left, expr)
+			continue
+		}
+		var text string
+		switch h.Kind {
+		case "css":
+			var nested []string
+			text, nested = inlineStyledHelpers(h.CSS, h.Interpolations, helpers, depth+1)
+			left = append(left, nested...)
+		case "keyframes":
+			kf, _ := inlineStyledHelpers(h.CSS, h.Interpolations, helpers, depth+1)
+			text = assets.StyledClassName(h.Name, kf)
+		}
+		body = strings.ReplaceAll(body, "${"+expr+"}", text)
+	}
+	return body, left
+}
+
+// reindent strips the common indentation of a template body's lines and
+// indents them with prefix instead
+func reindent(body, prefix string) string {
+	lines := strings.Split(strings.Trim(body, "\n"), "\n")
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if common < 0 || n < common {
+			common = n
+		}
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		b.WriteString(prefix + strings.TrimRight(line[common:], " \t") + "\n")
+	}
+	return b.String()
+}
+
+// styledProps lists the props an interpolation reads
+func styledProps(expr string) []string {
+	var props []string
+	for _, m := range styledPropsAccess.FindAllStringSubmatch(expr, -1) {
+		props = append(props, m[1])
+	}
+	if m := styledDestructure.FindStringSubmatch(expr); m != nil {
+		for _, name := range strings.Split(m[1], ",") {
+			name = strings.TrimSpace(strings.SplitN(name, ":", 2)[0])
+			name = strings.TrimSpace(strings.SplitN(name, "=", 2)[0])
+			if name != "" && name != "theme" {
+				props = append(props, name)
+			}
+		}
+	}
+	return props
+}
+
+func uniqueSorted(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// writeStyledNotes lists every styled component with its generated class
+// and, for interpolated templates, the dynamic parts to port by hand
+func (g *Generator) writeStyledNotes() {
+	if len(g.styled) == 0 {
+		return
+	}
+	g.writeln("// =============================================================================")
+	g.writeln("// STYLED COMPONENTS")
+	g.writeln("// =============================================================================")
+	for _, sc := range g.styled {
+		if sc.Kind != "" {
+			if len(sc.Dynamic) > 0 {
+				g.writef("// Line %d: %s (%s) has dynamic parts and was not compiled\n", sc.LineNumber, sc.Name, sc.Kind)
+				g.writeln("//")
+			}
+			continue
+		}
+		m := g.mapped[sc.Name]
+		g.writef("// Line %d: %s → <%s class=%q>\n", sc.LineNumber, sc.Name, m.Tag, m.Class)
+		if isComponentRef(m.Tag) {
+			g.writef("//   TODO: %s wraps component %s; it must accept className\n", sc.Name, m.Tag)
+		}
+		if len(sc.Dynamic) > 0 {
+			g.writeln("//   TODO: dynamic styles not compiled:")
+			for _, part := range sc.Dynamic {
+				g.writef("//     %s\n", strings.Join(strings.Fields(part), " "))
+			}
+			if len(sc.Props) > 0 {
+				g.writef("//   Depends on props: %s\n", strings.Join(sc.Props, ", "))
+			}
+		}
+		g.writeln("//")
+	}
+	g.writeln("")
+}
@@ -0,0 +1,41 @@
+package generator
+
+import "github.com/ha1tch/reminty/internal/parser"
+
+// isSuspense reports whether an element is React's <Suspense>
+func isSuspense(tag string) bool {
+	return tag == "Suspense" || tag == "React.Suspense"
+}
+
+// generateSuspense renders the children of a <Suspense> in place: the
+// server has their data before it renders, so nothing suspends. The
+// fallback becomes an htmx-indicator of a wrapper, the skeleton shown
+// while an htmx request inside it is reloading the content.
+func (g *Generator) generateSuspense(elem *parser.Element, builder string) {
+	var fallback parser.Node
+	for _, attr := range elem.Attributes {
+		if attr.Name == "fallback" {
+			fallback = attr.Expression.Parsed
+			if fallback == nil && attr.Expression.Raw != "" {
+				fallback = &parser.Expression{Raw: attr.Expression.Raw, LineNumber: elem.LineNumber}
+			}
+		}
+	}
+	if fallback == nil {
+		g.generateFragment(&parser.Fragment{Children: elem.Children, LineNumber: elem.LineNumber}, builder)
+		return
+	}
+	indicator := &parser.Element{
+		Tag:        "div",
+		Attributes: []parser.Attribute{{Name: "className", Value: "htmx-indicator"}},
+		Children:   []parser.Node{fallback},
+		LineNumber: elem.LineNumber,
+	}
+	wrapper := &parser.Element{
+		Tag:        "div",
+		Attributes: []parser.Attribute{{Name: "className", Value: "suspense"}},
+		Children:   append([]parser.Node{indicator}, elem.Children...),
+		LineNumber: elem.LineNumber,
+	}
+	g.generateElement(wrapper, builder)
+}
@@ -0,0 +1,72 @@
+package generator
+
+import "strings"
+
+// svgTags are the SVG elements, in the casing SVG requires. They are
+// rendered as they are written, with b.El, and are not reported as
+// unmapped.
+var svgTags = map[string]bool{
+	"svg": true, "g": true, "defs": true, "symbol": true, "use": true,
+	"path": true, "circle": true, "ellipse": true, "line": true,
+	"polyline": true, "polygon": true, "rect": true, "image": true,
+	"text": true, "tspan": true, "textPath": true, "foreignObject": true,
+	"linearGradient": true, "radialGradient": true, "stop": true,
+	"clipPath": true, "mask": true, "pattern": true, "marker": true,
+	"desc": true, "metadata": true, "switch": true, "view": true,
+	"animate": true, "animateMotion": true, "animateTransform": true,
+	"mpath": true, "set": true, "filter": true,
+	"feBlend": true, "feColorMatrix": true, "feComponentTransfer": true,
+	"feComposite": true, "feConvolveMatrix": true, "feDiffuseLighting": true,
+	"feDisplacementMap": true, "feDistantLight": true, "feDropShadow": true,
+	"feFlood": true, "feFuncA": true, "feFuncB": true, "feFuncG": true,
+	"feFuncR": true, "feGaussianBlur": true, "feImage": true, "feMerge": true,
+	"feMergeNode": true, "feMorphology": true, "feOffset": true,
+	"fePointLight": true, "feSpecularLighting": true, "feSpotLight": true,
+	"feTile": true, "feTurbulence": true,
+}
+
+// isSVGTag reports whether tag is an SVG element
+func isSVGTag(tag string) bool {
+	return svgTags[tag]
+}
+
+// svgCamelAttrs are the SVG attributes whose names are camelCase in SVG
+// itself, so they keep the casing JSX writes them in
+var svgCamelAttrs = map[string]bool{
+	"viewBox": true, "preserveAspectRatio": true, "pathLength": true,
+	"gradientUnits": true, "gradientTransform": true, "spreadMethod": true,
+	"patternUnits": true, "patternContentUnits": true, "patternTransform": true,
+	"clipPathUnits": true, "maskUnits": true, "maskContentUnits": true,
+	"markerUnits": true, "markerWidth": true, "markerHeight": true,
+	"refX": true, "refY": true, "textLength": true, "lengthAdjust": true,
+	"startOffset": true, "filterUnits": true, "primitiveUnits": true,
+	"stdDeviation": true, "baseFrequency": true, "numOctaves": true,
+	"stitchTiles": true, "tableValues": true, "kernelMatrix": true,
+	"kernelUnitLength": true, "diffuseConstant": true, "specularConstant": true,
+	"specularExponent": true, "surfaceScale": true, "edgeMode": true,
+	"xChannelSelector": true, "yChannelSelector": true, "limitingConeAngle": true,
+	"pointsAtX": true, "pointsAtY": true, "pointsAtZ": true,
+	"targetX": true, "targetY": true, "preserveAlpha": true,
+	"attributeName": true, "attributeType": true, "calcMode": true,
+	"keyTimes": true, "keySplines": true, "keyPoints": true,
+	"repeatCount": true, "repeatDur": true, "systemLanguage": true,
+}
+
+// svgAttrName returns the SVG name of an attribute React takes in
+// camelCase: strokeWidth → stroke-width, xlinkHref → xlink:href. Names
+// that are camelCase in SVG too (viewBox) and names React does not
+// rename are returned as they are.
+func svgAttrName(name string) string {
+	if svgCamelAttrs[name] || name == "className" || name == "htmlFor" || name == "tabIndex" {
+		return name
+	}
+	for _, ns := range []string{"xlink", "xmlns", "xml"} {
+		if rest, ok := strings.CutPrefix(name, ns); ok && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			return ns + ":" + strings.ToLower(rest[:1]) + rest[1:]
+		}
+	}
+	if strings.HasPrefix(name, "aria-") || strings.HasPrefix(name, "data-") || strings.ToLower(name) == name {
+		return name
+	}
+	return toKebabCase(name)
+}
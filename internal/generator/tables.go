@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// tableRow is the item variable of the generated table rows
+const tableRow = "row"
+
+// dataTable returns the table library table elem renders: an
+// <AgGridReact> or Ant Design <Table>, or the <table> TanStack Table's
+// markup fills in
+func (g *Generator) dataTable(elem *parser.Element) (parser.DataTable, bool) {
+	if g.currentComp == nil {
+		return parser.DataTable{}, false
+	}
+	for _, t := range g.currentComp.Tables {
+		if g.tablesUsed[t.LineNumber] || len(t.Columns) == 0 {
+			continue
+		}
+		switch {
+		case t.Library == "ag-grid" && elem.Tag == "AgGridReact" && elem.LineNumber == t.LineNumber,
+			t.Library == "antd" && elem.Tag == "Table" && elem.LineNumber == t.LineNumber,
+			t.Library == "tanstack" && elem.Tag == "table" && rendersTable(elem, t):
+			return t, true
+		}
+	}
+	return parser.DataTable{}, false
+}
+
+// rendersTable reports whether a <table> maps over the header groups or
+// rows of a TanStack table: table.getRowModel().rows.map(...), or with
+// react-table v7 rows.map(...)
+func rendersTable(elem *parser.Element, t parser.DataTable) bool {
+	maps := func(collection string) bool {
+		if t.Table != "" {
+			return strings.HasPrefix(collection, t.Table+".")
+		}
+		return collection == "rows" || collection == "page" || collection == "headerGroups"
+	}
+	found := false
+	walkElements(elem, func(el *parser.Element) {
+		for _, child := range el.Children {
+			switch n := child.(type) {
+			case *parser.MapExpr:
+				found = found || maps(n.Collection)
+			case *parser.Expression:
+				// Collections such as table.getRowModel().rows are kept
+				// as expressions
+				if i := strings.Index(n.Raw, ".map("); i > 0 {
+					found = found || maps(strings.TrimSpace(n.Raw[:i]))
+				}
+			}
+		}
+	})
+	return found
+}
+
+// generateDataTable generates a table of the columns of t in place of
+// elem: a header cell per column, sorting through htmx where the column
+// sorts, and a row per item of the table's data
+func (g *Generator) generateDataTable(elem *parser.Element, t parser.DataTable, builder string) {
+	if g.tablesUsed == nil {
+		g.tablesUsed = map[int]bool{}
+	}
+	g.tablesUsed[t.LineNumber] = true
+	line := elem.LineNumber
+
+	table := &parser.Element{Tag: "table", LineNumber: line}
+	if t.Library == "tanstack" {
+		for _, attr := range elem.Attributes {
+			if !attr.IsSpread && attr.EventHandler == nil && attr.Name != "key" && attr.Name != "ref" {
+				table.Attributes = append(table.Attributes, attr)
+			}
+		}
+	}
+
+	sortEndpoint := "/" + toKebabCase(g.component) + "/sort"
+	head := &parser.Element{Tag: "tr", LineNumber: line}
+	row := &parser.Element{Tag: "tr", LineNumber: line}
+	sorted := false
+	for _, col := range t.Columns {
+		th := &parser.Element{Tag: "th", LineNumber: line}
+		if col.Sortable {
+			sorted = true
+			th.Attributes = []parser.Attribute{
+				{Name: "hx-get", Value: sortEndpoint + "?by=" + col.Field},
+				{Name: "hx-target", Value: "closest table"},
+				{Name: "hx-swap", Value: "outerHTML"},
+			}
+		}
+		if header := columnHeader(col); header != "" {
+			th.Children = []parser.Node{&parser.Text{Content: header, LineNumber: line}}
+		}
+		head.Children = append(head.Children, th)
+
+		td := &parser.Element{Tag: "td", LineNumber: line}
+		if cell := columnCell(col, line); cell != nil {
+			td.Children = []parser.Node{cell}
+		}
+		row.Children = append(row.Children, td)
+	}
+	data := t.Data
+	if data == "" {
+		data = "data"
+	}
+	table.Children = []parser.Node{
+		&parser.Element{Tag: "thead", Children: []parser.Node{head}, LineNumber: line},
+		&parser.Element{Tag: "tbody", Children: []parser.Node{
+			&parser.MapExpr{Collection: data, ItemVar: tableRow, Body: row, LineNumber: line},
+		}, LineNumber: line},
+	}
+
+	if sorted {
+		body := ""
+		if t.Library == "tanstack" {
+			body = "header.column.getToggleSortingHandler()"
+		}
+		g.recordHandler(Handler{Method: "GET", Endpoint: sortEndpoint, Event: "onClick", Tag: "th", Body: body, Line: line}, nil)
+	}
+	g.generateElement(table, builder)
+}
+
+// columnHeader returns the header text of a column: its own, or the one
+// AG Grid derives from the field, firstName → First Name
+func columnHeader(col parser.TableColumn) string {
+	if col.Header != "" {
+		return col.Header
+	}
+	name := col.Field
+	if name == "" {
+		name = col.ID
+	}
+	words := strings.Fields(strings.NewReplacer("-", " ", "_", " ", ".", " ").Replace(toKebabCase(name)))
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// columnCell returns the content of a column's cells, reading the row
+// as tableRow: the field, or what its cell renderer or accessor function
+// returns for the row
+func columnCell(col parser.TableColumn, line int) parser.Node {
+	value := tableRow
+	if col.Field != "" {
+		value += "." + col.Field
+	}
+	switch {
+	case col.Cell != "":
+		body, ok := rewriteCell(col.Cell, value)
+		if !ok {
+			return &parser.Expression{Raw: col.Cell, LineNumber: line}
+		}
+		if node := renderFunction("() => "+body, line); node != nil {
+			return node
+		}
+		return &parser.Expression{Raw: body, LineNumber: line}
+	case col.Accessor != "":
+		// An accessor function receives the row itself
+		fn, src := arrowFunc(col.Accessor)
+		if fn == nil || fn.Body == nil || len(fn.Params) != 1 || !handlerIdent.MatchString(fn.Params[0]) {
+			return &parser.Expression{Raw: col.Accessor, LineNumber: line}
+		}
+		body := strings.TrimSpace(fn.Body.Span().Text(src))
+		return &parser.Expression{Raw: replaceIdent(body, fn.Params[0], tableRow), LineNumber: line}
+	case col.Field != "":
+		return &parser.Expression{Raw: value, LineNumber: line}
+	}
+	return nil
+}
+
+// rewriteCell returns the expression body of a cell renderer reading the
+// row directly: value for info.getValue(), params.value and a
+// destructured { value } or { getValue }, and the row for row.original
+// (TanStack), row.values (react-table) and params.data (AG Grid). Ant
+// Design renderers take the value and the row: (text, record) => ... It
+// reports false for a renderer with a block body.
+func rewriteCell(cell, value string) (string, bool) {
+	fn, src := arrowFunc(cell)
+	if fn == nil || fn.Body == nil {
+		return "", false
+	}
+	body := strings.TrimSpace(fn.Body.Span().Text(src))
+	if len(fn.Params) == 0 {
+		return body, true
+	}
+	param := strings.TrimSpace(fn.Params[0])
+	if len(fn.Params) > 1 {
+		record := strings.TrimSpace(fn.Params[1])
+		if !handlerIdent.MatchString(param) || !handlerIdent.MatchString(record) {
+			return "", false
+		}
+		body = replaceIdent(body, record, tableRow)
+		return replaceIdent(body, param, value), true
+	}
+	if handlerIdent.MatchString(param) {
+		p := regexp.QuoteMeta(param)
+		body = regexp.MustCompile(`\b`+p+`\.(?:getValue|renderValue)\(\)|\b`+p+`\.value\b`).ReplaceAllString(body, value)
+		body = regexp.MustCompile(`\b`+p+`\.(?:row\.original|row\.values|data)\b`).ReplaceAllString(body, tableRow)
+		return body, true
+	}
+	for _, name := range strings.Split(strings.Trim(param, "{} "), ",") {
+		switch strings.TrimSpace(name) {
+		case "getValue", "renderValue":
+			body = regexp.MustCompile(`\b(?:getValue|renderValue)\(\)`).ReplaceAllString(body, value)
+		case "value":
+			body = replaceIdent(body, "value", value)
+		case "row":
+			body = regexp.MustCompile(`\brow\.(?:original|values)\b`).ReplaceAllString(body, tableRow)
+		case "data":
+			body = replaceIdent(body, "data", tableRow)
+		}
+	}
+	return body, true
+}
+
+// replaceIdent replaces the identifier name in JavaScript source, but
+// not property names such as the value of x.value
+func replaceIdent(src, name, with string) string {
+	re := regexp.MustCompile(`(^|[^\w$.])` + regexp.QuoteMeta(name) + `\b`)
+	return re.ReplaceAllString(src, "${1}"+with)
+}
+
+// arrowFunc parses the arrow function src
+func arrowFunc(src string) (*parser.JSArrow, string) {
+	e, err := parser.ParseJSExpr(src)
+	if err != nil {
+		return nil, ""
+	}
+	fn, _ := parser.Unparen(e).(*parser.JSArrow)
+	return fn, src
+}
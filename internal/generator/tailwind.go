@@ -0,0 +1,385 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// UseTailwind turns on translation of static inline styles into Tailwind
+// utility classes. Declarations without a Tailwind equivalent stay inline.
+func (g *Generator) UseTailwind(enabled bool) {
+	g.tailwind = enabled
+}
+
+// CSSDecl is a single CSS declaration with a kebab-case property name
+type CSSDecl struct {
+	Property string
+	Value    string
+}
+
+// spacingProps maps CSS box properties to their Tailwind utility prefix
+var spacingProps = map[string]string{
+	"margin":         "m",
+	"margin-top":     "mt",
+	"margin-right":   "mr",
+	"margin-bottom":  "mb",
+	"margin-left":    "ml",
+	"padding":        "p",
+	"padding-top":    "pt",
+	"padding-right":  "pr",
+	"padding-bottom": "pb",
+	"padding-left":   "pl",
+	"gap":            "gap",
+	"row-gap":        "gap-y",
+	"column-gap":     "gap-x",
+	"width":          "w",
+	"height":         "h",
+	"min-width":      "min-w",
+	"min-height":     "min-h",
+	"max-width":      "max-w",
+	"max-height":     "max-h",
+	"top":            "top",
+	"right":          "right",
+	"bottom":         "bottom",
+	"left":           "left",
+}
+
+// keywordProps maps CSS property → value → Tailwind class for enumerated values
+var keywordProps = map[string]map[string]string{
+	"display": {
+		"flex": "flex", "inline-flex": "inline-flex", "grid": "grid", "block": "block",
+		"inline-block": "inline-block", "inline": "inline", "none": "hidden",
+	},
+	"flex-direction": {
+		"row": "flex-row", "column": "flex-col", "row-reverse": "flex-row-reverse", "column-reverse": "flex-col-reverse",
+	},
+	"flex-wrap": {
+		"wrap": "flex-wrap", "nowrap": "flex-nowrap", "wrap-reverse": "flex-wrap-reverse",
+	},
+	"flex": {
+		"1": "flex-1", "auto": "flex-auto", "none": "flex-none",
+	},
+	"justify-content": {
+		"flex-start": "justify-start", "start": "justify-start", "center": "justify-center",
+		"flex-end": "justify-end", "end": "justify-end", "space-between": "justify-between",
+		"space-around": "justify-around", "space-evenly": "justify-evenly",
+	},
+	"align-items": {
+		"flex-start": "items-start", "start": "items-start", "center": "items-center",
+		"flex-end": "items-end", "end": "items-end", "stretch": "items-stretch", "baseline": "items-baseline",
+	},
+	"text-align": {
+		"left": "text-left", "center": "text-center", "right": "text-right", "justify": "text-justify",
+	},
+	"font-weight": {
+		"300": "font-light", "400": "font-normal", "normal": "font-normal", "500": "font-medium",
+		"600": "font-semibold", "700": "font-bold", "bold": "font-bold", "800": "font-extrabold",
+	},
+	"position": {
+		"relative": "relative", "absolute": "absolute", "fixed": "fixed", "sticky": "sticky", "static": "static",
+	},
+	"overflow": {
+		"hidden": "overflow-hidden", "auto": "overflow-auto", "scroll": "overflow-scroll", "visible": "overflow-visible",
+	},
+	"cursor": {
+		"pointer": "cursor-pointer", "default": "cursor-default", "not-allowed": "cursor-not-allowed",
+	},
+	"font-style": {
+		"italic": "italic", "normal": "not-italic",
+	},
+	"text-decoration": {
+		"underline": "underline", "line-through": "line-through", "none": "no-underline",
+	},
+}
+
+// colorProps maps CSS colour properties to their Tailwind utility prefix
+var colorProps = map[string]string{
+	"color":            "text",
+	"background-color": "bg",
+	"background":       "bg",
+	"border-color":     "border",
+}
+
+// namedColors maps the CSS named colours to their hex values, which
+// Tailwind takes as arbitrary colours: color: 'red' becomes text-[#ff0000]
+var namedColors = map[string]string{
+	"aliceblue": "#f0f8ff", "antiquewhite": "#faebd7", "aqua": "#00ffff", "aquamarine": "#7fffd4",
+	"azure": "#f0ffff", "beige": "#f5f5dc", "bisque": "#ffe4c4", "black": "#000000",
+	"blanchedalmond": "#ffebcd", "blue": "#0000ff", "blueviolet": "#8a2be2", "brown": "#a52a2a",
+	"burlywood": "#deb887", "cadetblue": "#5f9ea0", "chartreuse": "#7fff00", "chocolate": "#d2691e",
+	"coral": "#ff7f50", "cornflowerblue": "#6495ed", "cornsilk": "#fff8dc", "crimson": "#dc143c",
+	"cyan": "#00ffff", "darkblue": "#00008b", "darkcyan": "#008b8b", "darkgoldenrod": "#b8860b",
+	"darkgray": "#a9a9a9", "darkgreen": "#006400", "darkgrey": "#a9a9a9", "darkkhaki": "#bdb76b",
+	"darkmagenta": "#8b008b", "darkolivegreen": "#556b2f", "darkorange": "#ff8c00",
+	"darkorchid": "#9932cc", "darkred": "#8b0000", "darksalmon": "#e9967a", "darkseagreen": "#8fbc8f",
+	"darkslateblue": "#483d8b", "darkslategray": "#2f4f4f", "darkslategrey": "#2f4f4f",
+	"darkturquoise": "#00ced1", "darkviolet": "#9400d3", "deeppink": "#ff1493",
+	"deepskyblue": "#00bfff", "dimgray": "#696969", "dimgrey": "#696969", "dodgerblue": "#1e90ff",
+	"firebrick": "#b22222", "floralwhite": "#fffaf0", "forestgreen": "#228b22", "fuchsia": "#ff00ff",
+	"gainsboro": "#dcdcdc", "ghostwhite": "#f8f8ff", "gold": "#ffd700", "goldenrod": "#daa520",
+	"gray": "#808080", "green": "#008000", "greenyellow": "#adff2f", "grey": "#808080",
+	"honeydew": "#f0fff0", "hotpink": "#ff69b4", "indianred": "#cd5c5c", "indigo": "#4b0082",
+	"ivory": "#fffff0", "khaki": "#f0e68c", "lavender": "#e6e6fa", "lavenderblush": "#fff0f5",
+	"lawngreen": "#7cfc00", "lemonchiffon": "#fffacd", "lightblue": "#add8e6",
+	"lightcoral": "#f08080", "lightcyan": "#e0ffff", "lightgoldenrodyellow": "#fafad2",
+	"lightgray": "#d3d3d3", "lightgreen": "#90ee90", "lightgrey": "#d3d3d3", "lightpink": "#ffb6c1",
+	"lightsalmon": "#ffa07a", "lightseagreen": "#20b2aa", "lightskyblue": "#87cefa",
+	"lightslategray": "#778899", "lightslategrey": "#778899", "lightsteelblue": "#b0c4de",
+	"lightyellow": "#ffffe0", "lime": "#00ff00", "limegreen": "#32cd32", "linen": "#faf0e6",
+	"magenta": "#ff00ff", "maroon": "#800000", "mediumaquamarine": "#66cdaa", "mediumblue": "#0000cd",
+	"mediumorchid": "#ba55d3", "mediumpurple": "#9370db", "mediumseagreen": "#3cb371",
+	"mediumslateblue": "#7b68ee", "mediumspringgreen": "#00fa9a", "mediumturquoise": "#48d1cc",
+	"mediumvioletred": "#c71585", "midnightblue": "#191970", "mintcream": "#f5fffa",
+	"mistyrose": "#ffe4e1", "moccasin": "#ffe4b5", "navajowhite": "#ffdead", "navy": "#000080",
+	"oldlace": "#fdf5e6", "olive": "#808000", "olivedrab": "#6b8e23", "orange": "#ffa500",
+	"orangered": "#ff4500", "orchid": "#da70d6", "palegoldenrod": "#eee8aa", "palegreen": "#98fb98",
+	"paleturquoise": "#afeeee", "palevioletred": "#db7093", "papayawhip": "#ffefd5",
+	"peachpuff": "#ffdab9", "peru": "#cd853f", "pink": "#ffc0cb", "plum": "#dda0dd",
+	"powderblue": "#b0e0e6", "purple": "#800080", "rebeccapurple": "#663399", "red": "#ff0000",
+	"rosybrown": "#bc8f8f", "royalblue": "#4169e1", "saddlebrown": "#8b4513", "salmon": "#fa8072",
+	"sandybrown": "#f4a460", "seagreen": "#2e8b57", "seashell": "#fff5ee", "sienna": "#a0522d",
+	"silver": "#c0c0c0", "skyblue": "#87ceeb", "slateblue": "#6a5acd", "slategray": "#708090",
+	"slategrey": "#708090", "snow": "#fffafa", "springgreen": "#00ff7f", "steelblue": "#4682b4",
+	"tan": "#d2b48c", "teal": "#008080", "thistle": "#d8bfd8", "tomato": "#ff6347",
+	"turquoise": "#40e0d0", "violet": "#ee82ee", "wheat": "#f5deb3", "white": "#ffffff",
+	"whitesmoke": "#f5f5f5", "yellow": "#ffff00", "yellowgreen": "#9acd32",
+}
+
+// unitlessProps are the inline-style properties React leaves without "px"
+var unitlessProps = map[string]bool{
+	"flex": true, "opacity": true, "z-index": true, "font-weight": true, "line-height": true,
+}
+
+// CSSToTailwind translates CSS declarations into Tailwind classes. It
+// returns the classes plus any declarations it could not translate.
+func CSSToTailwind(decls []CSSDecl) ([]string, []CSSDecl) {
+	var classes []string
+	var rest []CSSDecl
+	for _, d := range decls {
+		if cls := declToTailwind(d.Property, strings.TrimSpace(d.Value)); cls != "" {
+			classes = append(classes, cls)
+		} else {
+			rest = append(rest, d)
+		}
+	}
+	return classes, rest
+}
+
+func declToTailwind(prop, value string) string {
+	if prefix, ok := spacingProps[prop]; ok {
+		if scale := spacingScale(value); scale != "" {
+			return prefix + "-" + scale
+		}
+		return ""
+	}
+	if values, ok := keywordProps[prop]; ok {
+		return values[value]
+	}
+	if prefix, ok := colorProps[prop]; ok {
+		switch value {
+		case "white", "black", "transparent", "inherit", "current":
+			return prefix + "-" + value
+		case "currentColor":
+			return prefix + "-current"
+		}
+		if hex, ok := namedColors[strings.ToLower(value)]; ok {
+			return prefix + "-[" + hex + "]"
+		}
+		if strings.HasPrefix(value, "#") || strings.HasPrefix(value, "rgb") {
+			return prefix + "-[" + strings.ReplaceAll(value, " ", "") + "]"
+		}
+		return ""
+	}
+	switch prop {
+	case "opacity":
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f >= 0 && f <= 1 {
+			return fmt.Sprintf("opacity-%d", int(f*100+0.5))
+		}
+	case "z-index":
+		if _, err := strconv.Atoi(value); err == nil {
+			return "z-" + value
+		}
+	case "font-size":
+		if value != "" && !strings.ContainsAny(value, " ()") {
+			return "text-[" + value + "]"
+		}
+	case "border-radius":
+		if value == "50%" || value == "9999px" {
+			return "rounded-full"
+		}
+		if value != "" && !strings.ContainsAny(value, " ()") {
+			return "rounded-[" + value + "]"
+		}
+	}
+	return ""
+}
+
+// spacingScale converts a CSS length to a Tailwind spacing suffix, using
+// the default scale (1 unit = 0.25rem = 4px) and arbitrary values otherwise
+func spacingScale(value string) string {
+	switch value {
+	case "0", "0px":
+		return "0"
+	case "1px":
+		return "px"
+	case "auto":
+		return "auto"
+	case "100%":
+		return "full"
+	case "100vw", "100vh":
+		return "screen"
+	}
+	if px, ok := strings.CutSuffix(value, "px"); ok {
+		if n, err := strconv.ParseFloat(px, 64); err == nil && n > 0 && n == float64(int(n)) && int(n)%4 == 0 {
+			return strconv.Itoa(int(n) / 4)
+		}
+	}
+	if rem, ok := strings.CutSuffix(value, "rem"); ok {
+		if n, err := strconv.ParseFloat(rem, 64); err == nil && n*4 == float64(int(n*4)) {
+			return strconv.Itoa(int(n * 4))
+		}
+	}
+	if value != "" && !strings.ContainsAny(value, " ()") {
+		return "[" + value + "]"
+	}
+	return ""
+}
+
+// parseStyleObject parses a static React style object such as
+// { marginTop: 8, display: 'flex' } into CSS declarations. It fails if any
+// value is not a literal.
+func parseStyleObject(raw string) ([]CSSDecl, bool) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "{") || !strings.HasSuffix(raw, "}") {
+		return nil, false
+	}
+	body := strings.TrimSpace(raw[1 : len(raw)-1])
+	if body == "" {
+		return nil, false
+	}
+
+	var decls []CSSDecl
+	for _, entry := range splitTopLevel(body, ',') {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		colon := strings.Index(entry, ":")
+		if colon < 0 {
+			return nil, false
+		}
+		key := strings.Trim(strings.TrimSpace(entry[:colon]), "'\"")
+		value := strings.TrimSpace(entry[colon+1:])
+		if !isSimpleIdent(strings.ReplaceAll(key, "-", "_")) {
+			return nil, false
+		}
+
+		prop := toKebabCase(key)
+		switch {
+		case len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0]:
+			value = value[1 : len(value)-1]
+		default:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, false
+			}
+			if !unitlessProps[prop] && value != "0" {
+				value += "px"
+			}
+		}
+		decls = append(decls, CSSDecl{Property: prop, Value: value})
+	}
+	return decls, len(decls) > 0
+}
+
+// splitTopLevel splits s on sep, ignoring separators nested in brackets or strings
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		case ch == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// cssString renders declarations as an inline style attribute value
+func cssString(decls []CSSDecl) string {
+	parts := make([]string, len(decls))
+	for i, d := range decls {
+		parts[i] = d.Property + ": " + d.Value
+	}
+	return strings.Join(parts, "; ")
+}
+
+// applyTailwind returns a copy of elem whose static style object has been
+// folded into its class list. Elements without a translatable style are
+// returned unchanged.
+func (g *Generator) applyTailwind(elem *parser.Element) *parser.Element {
+	styleIdx := -1
+	var decls []CSSDecl
+	for i, attr := range elem.Attributes {
+		if attr.Name == "style" && attr.Expression.Raw != "" {
+			if d, ok := parseStyleObject(attr.Expression.Raw); ok {
+				styleIdx, decls = i, d
+			}
+			break
+		}
+	}
+	if styleIdx < 0 {
+		return elem
+	}
+
+	classes, rest := CSSToTailwind(decls)
+	if len(classes) == 0 {
+		return elem
+	}
+
+	copied := *elem
+	copied.Attributes = nil
+	merged := false
+	for i, attr := range elem.Attributes {
+		switch {
+		case i == styleIdx:
+			if len(rest) > 0 {
+				copied.Attributes = append(copied.Attributes, parser.Attribute{Name: "style", Value: cssString(rest)})
+			}
+		case (attr.Name == "className" || attr.Name == "class") && !merged:
+			merged = true
+			if attr.Value != "" {
+				attr.Value = attr.Value + " " + strings.Join(classes, " ")
+			} else if attr.Expression.Raw != "" {
+				attr.Expression.Raw = "`${" + attr.Expression.Raw + "} " + strings.Join(classes, " ") + "`"
+			} else {
+				attr.Value = strings.Join(classes, " ")
+			}
+			copied.Attributes = append(copied.Attributes, attr)
+		default:
+			copied.Attributes = append(copied.Attributes, attr)
+		}
+	}
+	if !merged {
+		copied.Attributes = append([]parser.Attribute{{Name: "className", Value: strings.Join(classes, " ")}}, copied.Attributes...)
+	}
+	return &copied
+}
@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Templates are text/template fragments reshaping the generated code to
+// a project's conventions. An empty fragment keeps the built-in shape.
+type Templates struct {
+	Header    string // comment after the package clause of a component file
+	Component string // a component function, given its body
+	Handler   string // an htmx handler stub, given its body
+}
+
+// templateFiles are the files LoadTemplates reads the fragments from
+var templateFiles = map[string]func(*Templates) *string{
+	"header.tmpl":    func(t *Templates) *string { return &t.Header },
+	"component.tmpl": func(t *Templates) *string { return &t.Component },
+	"handler.tmpl":   func(t *Templates) *string { return &t.Handler },
+}
+
+// LoadTemplates reads header.tmpl, component.tmpl and handler.tmpl from
+// dir. A missing file leaves its fragment empty; a fragment that does not
+// parse is an error.
+func LoadTemplates(dir string) (Templates, error) {
+	var t Templates
+	for name, field := range templateFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return Templates{}, err
+		}
+		*field(&t) = string(data)
+	}
+	if _, err := t.parse(); err != nil {
+		return Templates{}, fmt.Errorf("%s: %w", dir, err)
+	}
+	return t, nil
+}
+
+// HeaderData is what the header template is executed with
+type HeaderData struct {
+	Package    string   // package clause of the file
+	Components []string // the file's components, in source order
+}
+
+// ComponentData is what the component template is executed with. The
+// built-in shape is
+//
+//	func {{.Name}}({{.Params}}) mi.H {
+//	{{.Prelude}}	return func(b *mi.Builder) mi.Node {
+//			return {{.Node}}
+//		}
+//	}
+type ComponentData struct {
+	Name    string  // Go function name
+	Params  string  // parameter list: "title string, count int"
+	Args    []Param // the parameters one by one
+	Prelude string  // statements computing what the render reads, one per line
+	Node    string  // the builder expression rendering the component
+}
+
+// HandlerData is what the handler template is executed with: the
+// endpoint, its function name, its doc comment and the statements of its
+// body. The built-in shape is
+//
+//	{{.Doc}}func {{.Name}}(w http.ResponseWriter, r *http.Request) {
+//	{{.Body}}}
+type HandlerData struct {
+	Handler
+	Name string // function name, as RegisterHandlers refers to it
+	Doc  string // comment lines describing the original handler
+	Body string // statements decoding the request and re-rendering the component
+}
+
+// templateFuncs are the functions the templates may call besides the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"kebab": toKebabCase,
+}
+
+// parsedTemplates are the non-empty fragments of Templates, parsed
+type parsedTemplates struct {
+	header, component, handler *template.Template
+}
+
+// parse parses the non-empty fragments
+func (t Templates) parse() (parsedTemplates, error) {
+	var p parsedTemplates
+	for _, f := range []struct {
+		name, text string
+		dst        **template.Template
+	}{
+		{"header", t.Header, &p.header},
+		{"component", t.Component, &p.component},
+		{"handler", t.Handler, &p.handler},
+	} {
+		if strings.TrimSpace(f.text) == "" {
+			continue
+		}
+		tmpl, err := template.New(f.name).Funcs(templateFuncs).Parse(f.text)
+		if err != nil {
+			return parsedTemplates{}, fmt.Errorf("%s template: %w", f.name, err)
+		}
+		*f.dst = tmpl
+	}
+	return p, nil
+}
+
+// UseTemplates reshapes the file header and the component functions with
+// the fragments of t
+func (g *Generator) UseTemplates(t Templates) error {
+	p, err := t.parse()
+	if err != nil {
+		return err
+	}
+	g.templates = p
+	return nil
+}
+
+// writeTemplate writes tmpl executed with data, ending it with a newline.
+// The first error is kept for GenerateContext to return.
+func (g *Generator) writeTemplate(tmpl *template.Template, data any) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		if g.templateErr == nil {
+			g.templateErr = err
+		}
+		return
+	}
+	g.write(b.String())
+	if !strings.HasSuffix(b.String(), "\n") {
+		g.write("\n")
+	}
+}
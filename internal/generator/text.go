@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"html"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// UsePreserveWhitespace turns on keeping the text of <pre> and <textarea>
+// as it is written, with its line breaks and indentation, instead of
+// collapsing it the way JSX does
+func (g *Generator) UsePreserveWhitespace(enabled bool) {
+	g.preserve = enabled
+}
+
+// rendered drops the text JSX collapses to nothing, which the parser
+// keeps inside <pre> and <textarea> for UsePreserveWhitespace
+func (g *Generator) rendered(nodes []parser.Node) []parser.Node {
+	var out []parser.Node
+	for _, n := range nodes {
+		if text, ok := n.(*parser.Text); ok && text.Content == "" && !(g.preserve && text.Raw != "") {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// jsxText returns the text JSX renders for text or a string attribute
+// value: its character references decoded, &amp; → &, &nbsp; → U+00A0.
+// minty escapes the text again where HTML needs it.
+func jsxText(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+	return html.UnescapeString(s)
+}
+
+// stringChild returns the text of a string literal rendered as a child:
+// {' '}, {'{'}, {"\n"}
+func stringChild(raw string) (string, bool) {
+	e, err := parser.ParseJSExpr(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+	lit, ok := parser.Unparen(e).(*parser.JSLiteral)
+	if !ok {
+		return "", false
+	}
+	switch lit.Kind {
+	case "string":
+		return lit.Value, true
+	case "number":
+		return lit.Value, true
+	}
+	return "", false
+}
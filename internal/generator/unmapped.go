@@ -0,0 +1,34 @@
+package generator
+
+// Unmapped is a tag or attribute with no dedicated minty call, rendered
+// through the generic El() or mi.Attr fallback instead
+type Unmapped struct {
+	Kind string `json:"kind"`          // "tag" or "attribute"
+	Name string `json:"name"`          // tag or attribute name as written in JSX
+	Tag  string `json:"tag,omitempty"` // element an attribute was found on
+	Line int    `json:"line"`
+}
+
+// Kinds of Unmapped entries
+const (
+	UnmappedTag       = "tag"
+	UnmappedAttribute = "attribute"
+)
+
+// Unmapped returns the tags and attributes that fell back to El() or
+// mi.Attr in the last run
+func (g *Generator) Unmapped() []Unmapped {
+	return g.unmapped
+}
+
+// recordUnmappedTag notes an element rendered through El()
+func (g *Generator) recordUnmappedTag(tag string) {
+	if g.tagMethod(tag) == "" && !isSVGTag(tag) {
+		g.unmapped = append(g.unmapped, Unmapped{Kind: UnmappedTag, Name: tag, Line: g.currentLine})
+	}
+}
+
+// recordUnmappedAttr notes an attribute rendered through mi.Attr
+func (g *Generator) recordUnmappedAttr(name, tag string) {
+	g.unmapped = append(g.unmapped, Unmapped{Kind: UnmappedAttribute, Name: name, Tag: tag, Line: g.currentLine})
+}
@@ -0,0 +1,351 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// formErrorsParam is the parameter carrying field errors into forms
+// backed by a validation schema
+const formErrorsParam = "formErrors"
+
+// registerCall matches a react-hook-form {...register('field')} or Formik
+// {...formik.getFieldProps('field')} spread
+var registerCall = regexp.MustCompile(`^(?:register|\w+\.getFieldProps)\(\s*['"]([^'"]+)['"]`)
+
+// schemaFields returns the fields of the schemas and the form a component
+// uses. Fields whose error the markup shows itself, with a Formik
+// <ErrorMessage> or react-hook-form's errors.email, are left out.
+func (g *Generator) schemaFields(comp *parser.Component) map[string]bool {
+	if len(comp.Schemas) == 0 && comp.Form == nil {
+		return nil
+	}
+	fields := map[string]bool{}
+	if comp.Form != nil {
+		for _, f := range comp.Form.Fields {
+			fields[f.Name] = true
+		}
+	}
+	for _, name := range comp.Schemas {
+		if schema, ok := g.schemas[name]; ok {
+			for _, f := range schema.Fields {
+				fields[f.Name] = true
+			}
+		}
+	}
+	if comp.Form != nil {
+		for _, name := range comp.Form.Messages {
+			delete(fields, name)
+		}
+	}
+	return fields
+}
+
+// formField returns the schema field a form control is bound to, through
+// its name attribute or a react-hook-form {...register('field')} spread
+func (g *Generator) formField(elem *parser.Element) (string, bool) {
+	if len(g.formFields) == 0 {
+		return "", false
+	}
+	switch elem.Tag {
+	case "input", "select", "textarea":
+	default:
+		return "", false
+	}
+	for _, attr := range elem.Attributes {
+		var name string
+		if attr.IsSpread {
+			if m := registerCall.FindStringSubmatch(strings.TrimSpace(attr.SpreadExpr)); m != nil {
+				name = m[1]
+			}
+		} else if attr.Name == "name" {
+			name, _ = literalAttrValue(attr)
+		}
+		if name != "" && g.formFields[name] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// generateFieldWithError renders a form control followed by the error
+// message for its field, shown when the submitted value was invalid
+func (g *Generator) generateFieldWithError(elem *parser.Element, builder, field string) {
+	fields := g.formFields
+	g.formFields = nil
+	defer func() { g.formFields = fields }()
+
+	g.write("mi.NewFragment(")
+	g.generateElement(elem, builder)
+	g.writef(",\n")
+	g.writeIndent()
+	g.writef("\tmi.If(%s[%q] != \"\", func(b *mi.Builder) mi.Node {\n", formErrorsParam, field)
+	g.writeIndent()
+	g.writef("\t\treturn b.P(mi.Class(\"field-error\"), %s[%q])\n", formErrorsParam, field)
+	g.writeIndent()
+	g.write("\t}))")
+}
+
+// builtinPatterns are the regexps for format rules. Each schema declares
+// its own copy so converted files in one package never collide.
+var builtinPatterns = map[string]struct{ suffix, expr string }{
+	"email": {"EmailFormat", `^[^@\s]+@[^@\s]+\.[^@\s]+$`},
+	"url":   {"URLFormat", `^https?://[^\s/$.?#][^\s]*$`},
+	"uuid":  {"UUIDFormat", `^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`},
+}
+
+// writeValidation emits a validate function per schema, a submit handler
+// stub per component whose form uses one, and the request struct and
+// decoder of each react-hook-form or Formik form
+func (g *Generator) writeValidation(file *parser.File) {
+	forms := formComponents(file)
+	if len(file.Schemas) == 0 && len(forms) == 0 {
+		return
+	}
+	g.writeln("// =============================================================================")
+	g.writeln("// FORM VALIDATION")
+	g.writeln("// =============================================================================")
+	g.writeln("")
+
+	schemas := append([]parser.ValidationSchema(nil), file.Schemas...)
+	for _, comp := range forms {
+		if schema := formSchema(comp); schema != nil {
+			schemas = append(schemas, *schema)
+		}
+	}
+	g.writePatterns(schemas)
+
+	for _, schema := range schemas {
+		g.writeValidateFunc(&schema)
+	}
+
+	for _, comp := range file.Components {
+		if comp.Form != nil {
+			// The form's endpoint decodes and validates the submission
+			continue
+		}
+		for _, name := range comp.Schemas {
+			g.writeSubmitHandler(comp.Name, name)
+		}
+	}
+	for _, comp := range forms {
+		g.writeForm(comp)
+	}
+}
+
+// writePatterns declares the regexps the format and regex rules of the
+// schemas check
+func (g *Generator) writePatterns(schemas []parser.ValidationSchema) {
+	var vars [][2]string // name, pattern
+	for _, schema := range schemas {
+		builtins := map[string]bool{}
+		for _, f := range schema.Fields {
+			for _, r := range f.Rules {
+				if b, ok := builtinPatterns[r.Kind]; ok && !builtins[r.Kind] {
+					builtins[r.Kind] = true
+					vars = append(vars, [2]string{schema.Name + b.suffix, "`" + b.expr + "`"})
+				}
+				if r.Kind == "regex" {
+					vars = append(vars, [2]string{patternVar(schema.Name, f.Name), goRawString(r.Arg)})
+				}
+			}
+		}
+	}
+	if len(vars) > 0 {
+		width := 0
+		for _, v := range vars {
+			width = max(width, len(v[0]))
+		}
+		g.writeln("var (")
+		for _, v := range vars {
+			g.writef("\t%-*s = regexp.MustCompile(%s)\n", width, v[0], v[1])
+		}
+		g.writeln(")")
+		g.writeln("")
+	}
+}
+
+func (g *Generator) writeValidateFunc(schema *parser.ValidationSchema) {
+	fn := validateFuncName(schema.Name)
+	g.writef("// %s checks submitted form values against %s (%s, line %d).\n", fn, schema.Name, schema.Library, schema.LineNumber)
+	g.writeln("// It returns an error message per invalid field; an empty map means valid.")
+	g.writef("func %s(form url.Values) map[string]string {\n", fn)
+	g.writeln("\terrs := map[string]string{}")
+
+	for _, f := range schema.Fields {
+		cases := fieldCases(schema.Name, &f)
+		if len(cases) == 0 && len(f.Unsupported) == 0 {
+			continue
+		}
+		g.writeln("")
+		g.writef("\t// %s: %s\n", f.Name, f.Source)
+		for _, u := range f.Unsupported {
+			g.writef("\t// TODO: %s not translated\n", u)
+		}
+		if len(cases) == 0 {
+			continue
+		}
+		g.writeln("\t{")
+		g.writef("\t\tv := strings.TrimSpace(form.Get(%q))\n", f.Name)
+		if f.Type == "number" {
+			if usesNumber(&f) {
+				g.writeln("\t\tn, err := strconv.ParseFloat(v, 64)")
+			} else {
+				g.writeln("\t\t_, err := strconv.ParseFloat(v, 64)")
+			}
+		}
+		g.writeln("\t\tswitch {")
+		for _, c := range cases {
+			g.writef("\t\tcase %s:\n", c[0])
+			if c[1] == "" {
+				g.writeln("\t\t\t// optional")
+			} else {
+				g.writef("\t\t\terrs[%q] = %q\n", f.Name, c[1])
+			}
+		}
+		g.writeln("\t\t}")
+		g.writeln("\t}")
+	}
+
+	g.writeln("\treturn errs")
+	g.writeln("}")
+	g.writeln("")
+}
+
+// fieldCases returns the switch cases (condition, message) validating a
+// field, in order; an empty message marks an optional empty value
+func fieldCases(schemaName string, f *parser.SchemaField) [][2]string {
+	var cases [][2]string
+	custom := ""
+	for _, r := range f.Rules {
+		if r.Kind == "required" {
+			custom = r.Message
+		}
+	}
+
+	if f.Type != "boolean" {
+		if f.Required {
+			cases = append(cases, [2]string{`v == ""`, orDefault(custom, f.Name+" is required")})
+		} else {
+			cases = append(cases, [2]string{`v == ""`, ""})
+		}
+	}
+	if f.Type == "number" {
+		cases = append(cases, [2]string{"err != nil", f.Name + " must be a number"})
+	}
+
+	for _, r := range f.Rules {
+		var cond, msg string
+		switch r.Kind {
+		case "min", "max", "length":
+			cmp := sizeChecks[r.Kind]
+			op, word := cmp[0], cmp[1]
+			if f.Type == "number" {
+				cond = fmt.Sprintf("n %s %s", op, r.Arg)
+				msg = fmt.Sprintf("Must be %s %s", word, r.Arg)
+			} else {
+				cond = fmt.Sprintf("utf8.RuneCountInString(v) %s %s", op, r.Arg)
+				msg = fmt.Sprintf("Must be %s %s characters", word, r.Arg)
+			}
+		case "positive":
+			cond, msg = "n <= 0", "Must be positive"
+		case "int":
+			if f.Type != "number" {
+				continue
+			}
+			cond, msg = "n != math.Trunc(n)", "Must be a whole number"
+		case "email", "url", "uuid":
+			cond = fmt.Sprintf("!%s%s.MatchString(v)", schemaName, builtinPatterns[r.Kind].suffix)
+			msg = map[string]string{"email": "Invalid email address", "url": "Invalid URL", "uuid": "Invalid UUID"}[r.Kind]
+		case "regex":
+			cond, msg = fmt.Sprintf("!%s.MatchString(v)", patternVar(schemaName, f.Name)), "Invalid format"
+		case "enum":
+			values := r.Values
+			if f.Type == "boolean" {
+				// oneOf([true]): a checked checkbox posts "on" or its value
+				cond, msg = `v == "" || v == "false"`, "Must be accepted"
+				break
+			}
+			var parts []string
+			for _, v := range values {
+				parts = append(parts, fmt.Sprintf("v != %q", v))
+			}
+			if len(parts) == 0 {
+				continue
+			}
+			cond = strings.Join(parts, " && ")
+			msg = "Must be one of: " + strings.Join(values, ", ")
+		default:
+			continue
+		}
+		cases = append(cases, [2]string{cond, orDefault(r.Message, msg)})
+	}
+
+	// An optional field without constraints needs no checks
+	if len(cases) == 1 && cases[0][1] == "" {
+		return nil
+	}
+	return cases
+}
+
+// sizeChecks maps a size rule to the failing comparison and its wording
+var sizeChecks = map[string][2]string{
+	"min":    {"<", "at least"},
+	"max":    {">", "at most"},
+	"length": {"!=", "exactly"},
+}
+
+func usesNumber(f *parser.SchemaField) bool {
+	for _, r := range f.Rules {
+		switch r.Kind {
+		case "min", "max", "length", "positive", "int":
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) writeSubmitHandler(component, schema string) {
+	name := "handle" + component + "Submit"
+	g.writef("// %s validates a %s submission against %s.\n", name, component, schema)
+	g.writef("// Register it for the form's hx-post URL, e.g. mux.HandleFunc(\"POST /submit\", %s).\n", name)
+	g.writef("func %s(w http.ResponseWriter, r *http.Request) {\n", name)
+	g.writeln("\tif err := r.ParseForm(); err != nil {")
+	g.writeln("\t\thttp.Error(w, err.Error(), http.StatusBadRequest)")
+	g.writeln("\t\treturn")
+	g.writeln("\t}")
+	g.writef("\tif errs := %s(r.PostForm); len(errs) > 0 {\n", validateFuncName(schema))
+	g.writeln("\t\tw.WriteHeader(http.StatusUnprocessableEntity)")
+	g.writef("\t\t// TODO: re-render %s with %s set to errs\n", component, formErrorsParam)
+	g.writeln("\t\treturn")
+	g.writeln("\t}")
+	g.writeln("\t// TODO: handle the valid submission")
+	g.writeln("}")
+	g.writeln("")
+}
+
+func validateFuncName(schema string) string {
+	return "validate" + exportName(schema)
+}
+
+func patternVar(schema, field string) string {
+	return schema + exportName(toCamelCase(field)) + "Pattern"
+}
+
+// goRawString quotes a regexp as a Go raw string where possible
+func goRawString(s string) string {
+	if strings.Contains(s, "`") {
+		return fmt.Sprintf("%q", s)
+	}
+	return "`" + s + "`"
+}
+
+func orDefault(s, def string) string {
+	if s != "" {
+		return s
+	}
+	return def
+}
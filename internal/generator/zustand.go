@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// storeType names the Go struct holding a Zustand store's state:
+// useCartStore → CartStore, useCounter → CounterStore
+func storeType(hook string) string {
+	name := exportName(strings.TrimPrefix(hook, "use"))
+	if !strings.HasSuffix(name, "Store") {
+		name += "Store"
+	}
+	return name
+}
+
+// storeField returns the state field of a Zustand store the read
+// selects, if the store is declared in the file
+func (g *Generator) storeField(read parser.StoreRead) (parser.StateVariable, bool) {
+	store := g.stores[read.Store]
+	if store == nil {
+		return parser.StateVariable{}, false
+	}
+	for _, sv := range store.State {
+		if sv.Name == read.Field {
+			return sv, true
+		}
+	}
+	return parser.StateVariable{}, false
+}
+
+// writeStores writes a state struct per Zustand store of the file, with a
+// constructor returning the initial state and a method per action.
+// Actions that only set fields to values of the state, the action's
+// arguments or literals are translated; the others are kept as a comment
+// to port by hand.
+func (g *Generator) writeStores(file *parser.File) {
+	for _, store := range file.Stores {
+		typeName := storeType(store.Hook)
+		width := 0
+		types := map[string]string{}
+		for _, sv := range store.State {
+			width = max(width, len(exportName(toCamelCase(sv.Name))))
+			types[sv.Name] = sv.InitType
+		}
+
+		g.writef("// %s is the server-side state of the %s Zustand store (line %d).\n", typeName, store.Hook, store.LineNumber)
+		g.writeln("// Keep one per session or application and pass its fields to the")
+		g.writef("// components that select them with %s.\n", store.Hook)
+		g.writef("type %s struct {\n", typeName)
+		for _, sv := range store.State {
+			typ := sv.InitType
+			if typ == "" {
+				typ = "interface{}"
+			}
+			g.writef("\t%-*s %s\n", width, exportName(toCamelCase(sv.Name)), typ)
+		}
+		g.writeln("}")
+		g.writeln("")
+
+		g.writef("// New%s returns the initial state of %s\n", typeName, store.Hook)
+		g.writef("func New%s() *%s {\n", typeName, typeName)
+		var fields, values []string
+		for _, sv := range store.State {
+			value, ok := goLiteral(sv.InitValue)
+			if !ok && sv.InitValue != "[]" && sv.InitValue != "{}" {
+				value, ok = g.compositeArg(sv.InitValue, firstNonEmpty(sv.InitType, "interface{}"))
+			}
+			if !ok {
+				if sv.InitValue == "[]" || sv.InitValue == "{}" || sv.InitValue == "null" || sv.InitValue == "undefined" {
+					continue
+				}
+				value = zeroValue(sv.InitType) + " /* TODO: " + truncateExpr(sv.InitValue, 40) + " */"
+			}
+			fields = append(fields, exportName(toCamelCase(sv.Name)))
+			values = append(values, value)
+		}
+		if len(fields) == 0 {
+			g.writef("\treturn &%s{}\n", typeName)
+		} else {
+			g.writef("\treturn &%s{\n", typeName)
+			for i, field := range fields {
+				g.writef("\t\t%-*s %s,\n", width+1, field+":", values[i])
+			}
+			g.writeln("\t}")
+		}
+		g.writeln("}")
+
+		for _, a := range store.Actions {
+			g.writeln("")
+			g.writeStoreAction(&store, typeName, types, a)
+		}
+		g.writeln("")
+	}
+}
+
+// writeStoreAction writes the method replacing a store action. Its
+// parameters are typed by the fields they update, or by name.
+func (g *Generator) writeStoreAction(store *parser.ZustandStore, typeName string, types map[string]string, a parser.StoreAction) {
+	method := exportName(a.Name)
+	locals := map[string]bool{}
+	for _, p := range a.Params {
+		locals[strings.TrimSpace(p)] = true
+	}
+	paramTypes := map[string]string{}
+
+	var stmts []string
+	ok := !a.Other
+	for _, set := range a.Sets {
+		if !ok {
+			break
+		}
+		var fields, values []string
+		for _, f := range set.Fields {
+			if _, known := types[f.Name]; !known {
+				ok = false
+				break
+			}
+			states := []string{set.State}
+			if store.Get != "" {
+				states = append(states, store.Get+"()")
+			}
+			value, valid := storeExpr(f.Value, states, locals, types)
+			if !valid {
+				ok = false
+				break
+			}
+			for _, word := range reducerWord.FindAllString(value, -1) {
+				if locals[word] && paramTypes[word] == "" {
+					paramTypes[word] = types[f.Name]
+				}
+			}
+			fields = append(fields, "s."+exportName(toCamelCase(f.Name)))
+			values = append(values, value)
+		}
+		if len(fields) > 0 {
+			stmts = append(stmts, strings.Join(fields, ", ")+" = "+strings.Join(values, ", "))
+		}
+	}
+
+	params := make([]string, 0, len(a.Params))
+	for _, p := range a.Params {
+		p = strings.TrimSpace(p)
+		if !isSimpleIdent(p) {
+			// Defaults and destructured arguments are left to port by hand
+			ok = false
+			params = append(params, "args ...interface{}")
+			break
+		}
+		typ := paramTypes[p]
+		if typ == "" || typ == "interface{}" {
+			typ = g.generateParams([]parser.Prop{{Name: p}})[0].Type
+		}
+		params = append(params, toCamelCase(p)+" "+typ)
+	}
+
+	if ok {
+		g.writef("// %s replaces the %s action of %s\n", method, a.Name, store.Hook)
+	} else {
+		g.writef("// %s replaces the %s action at line %d:\n//\n", method, a.Name, a.LineNumber)
+		for _, line := range dedent(a.Body) {
+			g.writef("//\t%s\n", line)
+		}
+	}
+	g.writef("func (s *%s) %s(%s) {\n", typeName, method, strings.Join(params, ", "))
+	if !ok {
+		g.writeln("\t// TODO: port the action")
+	} else {
+		for _, stmt := range stmts {
+			g.writef("\t%s\n", stmt)
+		}
+	}
+	g.writeln("}")
+}
@@ -25,13 +25,31 @@ type Node interface {
 
 // Component represents a React component definition
 type Component struct {
-	Name       string
-	Props      []Prop
-	Body       Node
-	Hooks      []Hook
-	StateVars  []StateVariable // extracted useState variables
-	DerivedVars []DerivedVariable // const x = expr dependent on state
-	LineNumber int
+	Name        string            `json:"name"`
+	Props       []Prop            `json:"props,omitempty"`
+	Body        Node              `json:"body,omitempty"`
+	Hooks       []Hook            `json:"hooks,omitempty"`
+	StateVars   []StateVariable   `json:"stateVars,omitempty"`   // extracted useState variables
+	DerivedVars []DerivedVariable `json:"derivedVars,omitempty"` // const x = expr dependent on state
+	Schemas     []string          `json:"schemas,omitempty"`     // validation schemas the component uses
+	Translator  string            `json:"translator,omitempty"`  // i18next t function name, if the component translates
+	Fetches     []DataFetch       `json:"fetches,omitempty"`     // data loaded by useEffect on mount
+	Debounces   []Debounce        `json:"debounces,omitempty"`   // state acted on only after the user pauses
+	Tables      []DataTable       `json:"tables,omitempty"`      // TanStack Table, AG Grid and Ant Design tables
+	HookCalls   []HookCall        `json:"hookCalls,omitempty"`   // calls of the file's custom hooks
+	Contexts    []ContextUse      `json:"contexts,omitempty"`    // contexts read with useContext
+	StoreReads  []StoreRead       `json:"storeReads,omitempty"`  // values selected from a Redux or Zustand store
+	Dispatches  []Dispatch        `json:"dispatches,omitempty"`  // Redux actions dispatched and Zustand actions called
+	Form        *FormSpec         `json:"form,omitempty"`        // react-hook-form, Formik or Ant Design form
+	Async       bool              `json:"async,omitempty"`       // an async server component
+	Rendering   string            `json:"rendering,omitempty"`   // server or client, in a server components file
+	Interactive []string          `json:"interactive,omitempty"` // what makes it a client component
+	Wrappers    []string          `json:"wrappers,omitempty"`    // memo and forwardRef, outermost first
+	Ref         string            `json:"ref,omitempty"`         // the ref parameter forwardRef passes
+	Portal      *Portal           `json:"portal,omitempty"`      // set when it renders into a portal
+	Doc         string            `json:"doc,omitempty"`         // the description of the JSDoc block above it
+	Deprecated  string            `json:"deprecated,omitempty"`  // the text of its @deprecated tag
+	LineNumber  int               `json:"line"`
 }
 
 func (c *Component) Type() NodeType { return NodeComponent }
@@ -39,58 +57,280 @@ func (c *Component) Line() int      { return c.LineNumber }
 
 // StateVariable represents a useState declaration
 type StateVariable struct {
-	Name       string // variable name (e.g., "filter")
-	Setter     string // setter function name (e.g., "setFilter")
-	InitValue  string // initial value as string
-	InitType   string // inferred type: "string", "bool", "int", "[]interface{}"
-	LineNumber int
+	Name       string `json:"name"`                 // variable name (e.g., "filter")
+	Setter     string `json:"setter,omitempty"`     // setter function name (e.g., "setFilter")
+	InitValue  string `json:"initValue,omitempty"`  // initial value as string
+	InitType   string `json:"initType,omitempty"`   // inferred type: "string", "bool", "int", "[]interface{}"
+	Persistent bool   `json:"persistent,omitempty"` // must survive across requests (cart, filters, preferences)
+	LineNumber int    `json:"line"`
+}
+
+// ContextDecl is a React context created with createContext
+type ContextDecl struct {
+	Name       string `json:"name"`
+	Default    string `json:"default,omitempty"` // default value source
+	LineNumber int    `json:"line"`
+}
+
+// ContextUse binds a context's value in a component:
+// const theme = useContext(ThemeContext)
+type ContextUse struct {
+	Context    string   `json:"context"`
+	Names      []string `json:"names"`            // local names
+	Keys       []string `json:"keys,omitempty"`   // fields bound, for { a, b: c } destructuring
+	Fields     []string `json:"fields,omitempty"` // fields read through a whole binding: auth.user
+	LineNumber int      `json:"line"`
+}
+
+// CustomHook is a function named useXxx defined in the file
+type CustomHook struct {
+	Name        string            `json:"name"`
+	Params      []Prop            `json:"params,omitempty"`
+	StateVars   []StateVariable   `json:"stateVars,omitempty"`
+	DerivedVars []DerivedVariable `json:"derivedVars,omitempty"`
+	Fetches     []DataFetch       `json:"fetches,omitempty"`
+	Effects     []int             `json:"effects,omitempty"`   // lines of effects that are not data fetches
+	HookCalls   []HookCall        `json:"hookCalls,omitempty"` // other custom hooks it calls
+	Returns     []HookValue       `json:"returns,omitempty"`
+	ReturnKind  string            `json:"returnKind,omitempty"` // array, object or value
+	LineNumber  int               `json:"line"`
+	EndLine     int               `json:"endLine"`
+
+	body string // function body source
+}
+
+// Helper is a plain function declared at the top level of the file, not
+// a component or a hook: function formatPrice(n) {...} or
+// const slugify = s => ...
+type Helper struct {
+	Name       string `json:"name"`
+	Params     []Prop `json:"params,omitempty"` // with the type of a TypeScript annotation
+	Body       string `json:"body"`             // the returned expression, or the block without its braces
+	Block      bool   `json:"block,omitempty"`  // Body is a block of statements
+	Async      bool   `json:"async,omitempty"`
+	Source     string `json:"source"` // the declaration as written
+	LineNumber int    `json:"line"`
+	EndLine    int    `json:"endLine"`
+}
+
+// Constant is a top-level const holding data rather than code:
+// const STATUSES = ['open', 'closed']
+type Constant struct {
+	Name       string `json:"name"`
+	Value      string `json:"value"` // JavaScript source of the value
+	LineNumber int    `json:"line"`
+	EndLine    int    `json:"endLine"`
+}
+
+// HookValue is one value a custom hook returns
+type HookValue struct {
+	Name  string `json:"name,omitempty"` // object key, or the identifier returned
+	Value string `json:"value"`          // returned expression
+	Func  bool   `json:"func,omitempty"` // a function, such as a setter: client-side only
+}
+
+// HookCall binds the values a custom hook returns in a component:
+// const [open, toggle] = useToggle(false)
+type HookCall struct {
+	Hook       string   `json:"hook"`
+	Args       []string `json:"args,omitempty"`
+	Names      []string `json:"names"`          // local names; "" for holes
+	Keys       []string `json:"keys,omitempty"` // object keys bound, for { a, b: c } destructuring
+	LineNumber int      `json:"line"`
+}
+
+// DataFetch is a useEffect that loads data into state when the component
+// mounts: useEffect(() => { fetch(url).then(r => r.json()).then(setData) }, []).
+// In async server components and Next.js data loaders it is a request
+// awaited into a local: const posts = await fetch(url).then(r => r.json())
+type DataFetch struct {
+	State      string   `json:"state"`             // state variable the data is stored in
+	Value      string   `json:"value,omitempty"`   // expression stored, when not the response itself: data.items
+	URL        string   `json:"url"`               // fetched URL, as JavaScript source
+	Client     string   `json:"client"`            // fetch, axios or axios.get
+	Loading    string   `json:"loading,omitempty"` // state flag cleared once the data is in
+	Error      string   `json:"error,omitempty"`   // state set when the request fails
+	Deps       []string `json:"deps,omitempty"`    // dependency array
+	Await      bool     `json:"await,omitempty"`   // awaited rather than made in an effect
+	LineNumber int      `json:"line"`
+}
+
+// Debounce is state whose changes take effect after a delay: read
+// through useDebounce, set through a debounced function, or handled in
+// a setTimeout that each change restarts
+type Debounce struct {
+	State      string `json:"state"`          // the state typed into; "" if the function sets none
+	Func       string `json:"func,omitempty"` // the debounced function, called instead of the setter
+	Delay      int    `json:"delay"`          // milliseconds; 0 if not a constant
+	Via        string `json:"via"`            // useDebounce, debounce or setTimeout
+	LineNumber int    `json:"line"`
+}
+
+// DataTable is a table rendered by a table library: TanStack Table's
+// useReactTable (or react-table's useTable), an AG Grid <AgGridReact> or
+// an Ant Design <Table>
+type DataTable struct {
+	Library    string        `json:"library"`         // tanstack, ag-grid or antd
+	Table      string        `json:"table,omitempty"` // the variable useReactTable is bound to
+	Data       string        `json:"data,omitempty"`  // the rows, as JavaScript source
+	Columns    []TableColumn `json:"columns"`
+	LineNumber int           `json:"line"`
+}
+
+// TableColumn is one column definition of a DataTable
+type TableColumn struct {
+	Field    string `json:"field,omitempty"`    // row key shown: accessorKey, accessor, field or dataIndex
+	ID       string `json:"id,omitempty"`       // id of a column without one, such as actions
+	Header   string `json:"header,omitempty"`   // header text, when a string
+	Cell     string `json:"cell,omitempty"`     // cell renderer source
+	Accessor string `json:"accessor,omitempty"` // accessor function source, for computed columns
+	Sortable bool   `json:"sortable,omitempty"`
+}
+
+// DataLoader is a data-fetching function exported by a Next.js page:
+// getServerSideProps, getStaticProps or getStaticPaths
+type DataLoader struct {
+	Kind       string      `json:"kind"`
+	Props      []HookValue `json:"props,omitempty"`   // the props returned: return { props: { post } }
+	Params     []string    `json:"params,omitempty"`  // route parameters read: context.params.slug
+	Query      []string    `json:"query,omitempty"`   // query parameters read: context.query.page
+	Fetches    []DataFetch `json:"fetches,omitempty"` // requests awaited
+	Body       string      `json:"body,omitempty"`    // function body source
+	LineNumber int         `json:"line"`
+	EndLine    int         `json:"endLine"`
+}
+
+// ReduxSlice is a Redux Toolkit slice:
+// const counterSlice = createSlice({ name: 'counter', initialState, reducers })
+type ReduxSlice struct {
+	Var        string          `json:"var"`               // variable the slice is assigned to
+	Name       string          `json:"name"`              // the slice's key in the store state
+	State      []StateVariable `json:"state,omitempty"`   // fields of the initial state
+	Initial    string          `json:"initial,omitempty"` // variable holding the initial state, if any
+	Reducers   []Reducer       `json:"reducers,omitempty"`
+	LineNumber int             `json:"line"`
+}
+
+// Reducer is a case reducer of a slice; its action creator has the same
+// name
+type Reducer struct {
+	Name       string   `json:"name"`
+	Params     []string `json:"params,omitempty"`  // state and action parameters
+	Body       string   `json:"body"`              // statements, or the expression an arrow returns
+	Payload    bool     `json:"payload,omitempty"` // reads the action payload
+	LineNumber int      `json:"line"`
+}
+
+// StoreRead is a value a component selects from the Redux store,
+// const count = useSelector(state => state.counter.value), or from a
+// Zustand store, const count = useCounterStore(state => state.count)
+type StoreRead struct {
+	Name       string `json:"name"`            // local name
+	Store      string `json:"store,omitempty"` // Zustand store hook; "" for the Redux store
+	Slice      string `json:"slice,omitempty"` // slice key in the Redux store state
+	Field      string `json:"field,omitempty"` // field of the slice or store state; "" for all of it
+	Selector   string `json:"selector"`        // selector source
+	LineNumber int    `json:"line"`
+}
+
+// Dispatch is a Redux action a component dispatches, dispatch(increment()),
+// or a Zustand store action it calls
+type Dispatch struct {
+	Action     string   `json:"action"`
+	Store      string   `json:"store,omitempty"` // Zustand store hook; "" for a Redux action
+	Local      string   `json:"local,omitempty"` // name a Zustand action is bound to
+	Args       []string `json:"args,omitempty"`
+	LineNumber int      `json:"line"`
+}
+
+// FormSpec is a form a component manages with react-hook-form (useForm,
+// register, handleSubmit), Formik (<Formik>, useFormik) or Ant Design
+// (<Form onFinish>, <Form.Item name rules>)
+type FormSpec struct {
+	Library    string        `json:"library"`            // react-hook-form, formik or antd
+	Fields     []SchemaField `json:"fields,omitempty"`   // with the rules register options or Form.Item rules give
+	Schema     string        `json:"schema,omitempty"`   // zod/yup schema of a resolver or validationSchema
+	Submit     string        `json:"submit,omitempty"`   // the submit function
+	Messages   []string      `json:"messages,omitempty"` // fields the markup shows the error of: <ErrorMessage>, errors.email
+	LineNumber int           `json:"line"`
+}
+
+// ZustandStore is a store created with Zustand:
+// const useCounterStore = create((set) => ({ count: 0, inc: () => set(...) }))
+type ZustandStore struct {
+	Hook       string          `json:"hook"`            // the store hook components call
+	Get        string          `json:"get,omitempty"`   // the creator's get parameter, if any
+	State      []StateVariable `json:"state,omitempty"` // the non-function values of the initial state
+	Actions    []StoreAction   `json:"actions,omitempty"`
+	LineNumber int             `json:"line"`
+}
+
+// StoreAction is a function of a Zustand store. Its body is recorded as
+// the set calls it makes, unless it does anything else.
+type StoreAction struct {
+	Name       string     `json:"name"`
+	Params     []string   `json:"params,omitempty"`
+	Body       string     `json:"body"`
+	Sets       []StoreSet `json:"sets,omitempty"`
+	Other      bool       `json:"other,omitempty"` // the body does more than call set
+	LineNumber int        `json:"line"`
+}
+
+// StoreSet is a set call of a Zustand action: set({ count: 0 }), or
+// set((state) => ({ count: state.count + 1 }))
+type StoreSet struct {
+	State  string      `json:"state,omitempty"` // parameter of an updater function
+	Fields []HookValue `json:"fields"`          // key → new value source
 }
 
 // DerivedVariable represents a const derived from state
 type DerivedVariable struct {
-	Name       string   // variable name (e.g., "filteredUsers")
-	Expression string   // the full expression
-	SourceVar  string   // source collection (e.g., "users")
-	Operation  string   // operation type: filter, map, find, some, every, reduce, sort, slice
-	ResultType string   // inferred Go type
-	DependsOn  []string // state variables it depends on
-	LineNumber int
+	Name       string   `json:"name"`                 // variable name (e.g., "filteredUsers")
+	Expression string   `json:"expression,omitempty"` // the full expression
+	SourceVar  string   `json:"sourceVar,omitempty"`  // source collection (e.g., "users")
+	Operation  string   `json:"operation,omitempty"`  // operation type: filter, map, find, some, every, reduce, sort, slice, or value for an expression of operators
+	ResultType string   `json:"resultType,omitempty"` // inferred Go type
+	DependsOn  []string `json:"dependsOn,omitempty"`  // state variables it depends on
+	LineNumber int      `json:"line"`
 }
 
 // Prop represents a component prop
 type Prop struct {
-	Name         string
-	DefaultValue string
-	JSType       string // for TypeScript
+	Name         string `json:"name"`
+	Alias        string `json:"alias,omitempty"` // local name when renamed: { label: heading }
+	DefaultValue string `json:"defaultValue,omitempty"`
+	JSType       string `json:"jsType,omitempty"`   // declared type: for TypeScript, or from propTypes (string, number, bool, array, object, func, node, any)
+	Required     bool   `json:"required,omitempty"` // declared .isRequired in propTypes
+	Doc          string `json:"doc,omitempty"`      // the text of its JSDoc @param tag
 }
 
 // Hook represents a React hook usage
 type Hook struct {
-	Type       string // useState, useEffect, useMemo, etc.
-	Name       string // variable name
-	InitValue  string
-	Deps       []string
-	Body       string
-	LineNumber int
+	Type       string   `json:"type"` // useState, useEffect, useMemo, etc.
+	Name       string   `json:"name"` // variable name
+	InitValue  string   `json:"initValue,omitempty"`
+	Deps       []string `json:"deps,omitempty"`
+	Body       string   `json:"body,omitempty"`
+	LineNumber int      `json:"line"`
 }
 
 // EventHandler represents an event handler in JSX
 type EventHandler struct {
-	EventType   string   // onClick, onChange, onSubmit, etc.
-	HandlerBody string   // the handler expression/function body
-	SetterCalls []string // setState calls detected: ["setFilter", "setCount"]
-	StateVars   []string // state variables referenced
-	IsInline    bool     // true if inline arrow function
-	LineNumber  int
+	EventType   string   `json:"eventType,omitempty"`   // onClick, onChange, onSubmit, etc.
+	HandlerBody string   `json:"handlerBody,omitempty"` // the handler expression/function body
+	SetterCalls []string `json:"setterCalls,omitempty"` // setState calls detected: ["setFilter", "setCount"]
+	StateVars   []string `json:"stateVars,omitempty"`   // state variables referenced
+	IsInline    bool     `json:"isInline,omitempty"`    // true if inline arrow function
+	LineNumber  int      `json:"line"`
 }
 
 // Element represents a JSX element
 type Element struct {
-	Tag        string
-	Attributes []Attribute
-	Children   []Node
-	SelfClose  bool
-	LineNumber int
+	Tag        string      `json:"tag"`
+	Attributes []Attribute `json:"attributes,omitempty"`
+	Children   []Node      `json:"children,omitempty"`
+	SelfClose  bool        `json:"selfClose,omitempty"`
+	LineNumber int         `json:"line"`
 }
 
 func (e *Element) Type() NodeType { return NodeElement }
@@ -98,18 +338,19 @@ func (e *Element) Line() int      { return e.LineNumber }
 
 // Attribute represents a JSX attribute
 type Attribute struct {
-	Name         string
-	Value        string        // for string values
-	Expression   Expression    // for {expression} values
-	IsSpread     bool          // for {...props}
-	SpreadExpr   string
-	EventHandler *EventHandler // parsed event handler (if applicable)
+	Name         string        `json:"name"`
+	Value        string        `json:"value,omitempty"`      // for string values
+	Expression   Expression    `json:"expression,omitempty"` // for {expression} values
+	IsSpread     bool          `json:"isSpread,omitempty"`   // for {...props}
+	SpreadExpr   string        `json:"spreadExpr,omitempty"`
+	EventHandler *EventHandler `json:"eventHandler,omitempty"` // parsed event handler (if applicable)
 }
 
 // Text represents text content
 type Text struct {
-	Content    string
-	LineNumber int
+	Content    string `json:"content,omitempty"`
+	Raw        string `json:"raw,omitempty"` // the source text, kept inside <pre> and <textarea>
+	LineNumber int    `json:"line"`
 }
 
 func (t *Text) Type() NodeType { return NodeText }
@@ -117,9 +358,9 @@ func (t *Text) Line() int      { return t.LineNumber }
 
 // Expression represents a JS expression in JSX
 type Expression struct {
-	Raw        string
-	Parsed     Node // if we can parse it further
-	LineNumber int
+	Raw        string `json:"raw,omitempty"`
+	Parsed     Node   `json:"parsed,omitempty"` // if we can parse it further
+	LineNumber int    `json:"line"`
 }
 
 func (e *Expression) Type() NodeType { return NodeExpression }
@@ -127,8 +368,8 @@ func (e *Expression) Line() int      { return e.LineNumber }
 
 // Fragment represents a React fragment (<>...</> or <Fragment>)
 type Fragment struct {
-	Children   []Node
-	LineNumber int
+	Children   []Node `json:"children,omitempty"`
+	LineNumber int    `json:"line"`
 }
 
 func (f *Fragment) Type() NodeType { return NodeFragment }
@@ -136,21 +377,53 @@ func (f *Fragment) Line() int      { return f.LineNumber }
 
 // MapExpr represents {items.map(item => ...)}
 type MapExpr struct {
-	Collection string
-	ItemVar    string
-	IndexVar   string
-	Body       Node
-	LineNumber int
+	Collection string    `json:"collection,omitempty"`
+	Chain      []ArrayOp `json:"chain,omitempty"`  // applied to the collection before it is mapped, in order
+	Object     string    `json:"object,omitempty"` // keys, values or entries when the collection is an object: Object.entries(stats)
+	Length     string    `json:"length,omitempty"` // the count of items made up rather than mapped: Array.from({ length: 5 })
+	ItemVar    string    `json:"itemVar,omitempty"`
+	ValueVar   string    `json:"valueVar,omitempty"` // the value of an entry, v in ([k, v]) => ...
+	IndexVar   string    `json:"indexVar,omitempty"`
+	Body       Node      `json:"body,omitempty"`
+	Key        string    `json:"key,omitempty"` // key expression of the rendered element: item.id
+	LineNumber int       `json:"line"`
 }
 
 func (m *MapExpr) Type() NodeType { return NodeMap }
 func (m *MapExpr) Line() int      { return m.LineNumber }
 
+// Source returns the collection with the operations chained on it, as
+// written: items.filter(i => i.visible).slice(0, 5)
+func (m *MapExpr) Source() string {
+	src := m.Collection
+	switch {
+	case m.Object != "":
+		src = "Object." + m.Object + "(" + m.Collection + ")"
+	case m.Length != "":
+		src = "Array.from({ length: " + m.Length + " })"
+	}
+	for _, op := range m.Chain {
+		src += "." + op.Raw
+	}
+	return src
+}
+
+// ArrayOp is one step of the pipeline a collection goes through before
+// it is mapped: filter(t => !t.done), sort((a, b) => a.rank - b.rank),
+// slice(0, 5), reverse()
+type ArrayOp struct {
+	Method string   `json:"method"`
+	Params []string `json:"params,omitempty"` // of the callback, when it has an expression body
+	Body   string   `json:"body,omitempty"`   // the expression body of the callback
+	Args   []string `json:"args,omitempty"`   // the arguments after the callback, or all of them
+	Raw    string   `json:"raw"`              // the call as written
+}
+
 // Conditional represents {condition && <Element/>}
 type Conditional struct {
-	Condition  string
-	Consequent Node
-	LineNumber int
+	Condition  string `json:"condition,omitempty"`
+	Consequent Node   `json:"consequent,omitempty"`
+	LineNumber int    `json:"line"`
 }
 
 func (c *Conditional) Type() NodeType { return NodeConditional }
@@ -158,10 +431,10 @@ func (c *Conditional) Line() int      { return c.LineNumber }
 
 // Ternary represents {condition ? <A/> : <B/>}
 type Ternary struct {
-	Condition  string
-	Consequent Node
-	Alternate  Node
-	LineNumber int
+	Condition  string `json:"condition,omitempty"`
+	Consequent Node   `json:"consequent,omitempty"`
+	Alternate  Node   `json:"alternate,omitempty"`
+	LineNumber int    `json:"line"`
 }
 
 func (t *Ternary) Type() NodeType { return NodeTernary }
@@ -169,41 +442,129 @@ func (t *Ternary) Line() int      { return t.LineNumber }
 
 // Import represents an import statement
 type Import struct {
-	Default    string            // default import name
-	Named      map[string]string // { name: alias }
-	Namespace  string            // * as name
-	Source     string            // module path
-	LineNumber int
+	Default    string            `json:"default,omitempty"`   // default import name
+	Named      map[string]string `json:"named,omitempty"`     // { name: alias }
+	Namespace  string            `json:"namespace,omitempty"` // * as name
+	Source     string            `json:"source,omitempty"`    // module path
+	Lazy       bool              `json:"lazy,omitempty"`      // loaded with React.lazy(() => import(...))
+	LineNumber int               `json:"line"`
 }
 
 func (i *Import) Type() NodeType { return NodeImport }
 func (i *Import) Line() int      { return i.LineNumber }
 
+// StyledComponent represents a CSS-in-JS declaration such as
+// const Button = styled.button`...` or styled(Link)`...`, or one of the
+// css`...` and keyframes`...` helpers that templates interpolate
+type StyledComponent struct {
+	Name           string            `json:"name"`                     // component name (Button)
+	Tag            string            `json:"tag"`                      // underlying HTML tag, if styled.tag
+	Base           string            `json:"base,omitempty"`           // wrapped component, if styled(Component)
+	Kind           string            `json:"kind,omitempty"`           // "css" or "keyframes" for helpers; empty for components
+	Attrs          map[string]string `json:"attrs,omitempty"`          // static .attrs({...}) values
+	CSS            string            `json:"cSS,omitempty"`            // template body, interpolations included
+	Interpolations []string          `json:"interpolations,omitempty"` // ${...} expressions inside the template
+	LineNumber     int               `json:"line"`
+}
+
+func (s *StyledComponent) Line() int { return s.LineNumber }
+
 // File represents a complete JSX file
 type File struct {
-	Imports    []Import
-	Components []Component
-	Exports    []string
+	Imports          []Import           `json:"imports,omitempty"`
+	Components       []Component        `json:"components,omitempty"`
+	StyledComponents []StyledComponent  `json:"styledComponents,omitempty"`
+	Schemas          []ValidationSchema `json:"schemas,omitempty"`
+	CustomHooks      []CustomHook       `json:"customHooks,omitempty"`
+	Helpers          []Helper           `json:"helpers,omitempty"`
+	Constants        []Constant         `json:"constants,omitempty"`
+	Contexts         []ContextDecl      `json:"contexts,omitempty"`
+	DataLoaders      []DataLoader       `json:"dataLoaders,omitempty"`
+	Slices           []ReduxSlice       `json:"slices,omitempty"`
+	Stores           []ZustandStore     `json:"stores,omitempty"`
+	Stories          *Stories           `json:"stories,omitempty"`   // set for a Storybook file
+	Native           []NativeUse        `json:"native,omitempty"`    // set for a React Native file
+	Directive        string             `json:"directive,omitempty"` // 'use client' or 'use server' at the top
+	ServerActions    []ServerAction     `json:"serverActions,omitempty"`
+	Exports          []string           `json:"exports,omitempty"`
+	DefaultExport    string             `json:"defaultExport,omitempty"` // name of the default export, if any
+}
+
+// Portal is where a component rendering createPortal(child, container)
+// puts its markup
+type Portal struct {
+	Container  string `json:"container"` // body, a #id selector, or the expression
+	LineNumber int    `json:"line"`
+}
+
+// ServerAction is an async function marked 'use server', which forms
+// and client components call on the server
+type ServerAction struct {
+	Name       string `json:"name"`
+	LineNumber int    `json:"line"`
+}
+
+// NativeUse is a React Native primitive or API, or an import of a native
+// library, and the lines a file uses it on
+type NativeUse struct {
+	Name   string `json:"name"`   // View, StyleSheet, useNavigation
+	Module string `json:"module"` // react-native, or the library it is imported from
+	Lines  []int  `json:"lines,omitempty"`
+}
+
+// Stories is a Storybook file in Component Story Format: the default
+// export describes the component, each named export is a story
+type Stories struct {
+	Title      string     `json:"title,omitempty"`
+	Component  string     `json:"component,omitempty"` // the component the stories render
+	Args       []StoryArg `json:"args,omitempty"`      // args shared by every story
+	Render     string     `json:"render,omitempty"`    // render function shared by every story
+	Stories    []Story    `json:"stories,omitempty"`
+	LineNumber int        `json:"line"`
+}
+
+// Story is one story of a Storybook file:
+// export const Primary = { args: { primary: true } }
+type Story struct {
+	Name       string     `json:"name"`
+	Args       []StoryArg `json:"args,omitempty"`
+	Render     string     `json:"render,omitempty"` // source of the render arrow function, if any
+	LineNumber int        `json:"line"`
+}
+
+// StoryArg is an arg a story passes to its component as a prop
+type StoryArg struct {
+	Name  string `json:"name"`
+	Value string `json:"value"` // JavaScript source of the value
 }
 
 // ParseResult contains the parsed AST and any warnings/suggestions
 type ParseResult struct {
-	File        *File
-	Warnings    []Warning
-	Suggestions []Suggestion
+	File        *File        `json:"file,omitempty"`
+	Warnings    []Warning    `json:"warnings,omitempty"`
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
 }
 
 // Warning represents a parsing warning
 type Warning struct {
-	Line    int
-	Column  int
-	Message string
+	Line    int      `json:"line,omitempty"`
+	Column  int      `json:"column,omitempty"`
+	Message string   `json:"message,omitempty"`
+	Code    string   `json:"code,omitempty"`    // what went wrong, such as "unclosed-element"
+	Skipped *Skipped `json:"skipped,omitempty"` // the source dropped to recover, if any
+}
+
+// Skipped is source the parser dropped to recover from an error
+type Skipped struct {
+	Line    int    `json:"line"`
+	EndLine int    `json:"endLine"`
+	Text    string `json:"text"` // its first line
 }
 
 // Suggestion represents a translation suggestion
 type Suggestion struct {
-	Line        int
-	ReactCode   string
-	MintyHint   string
-	PatternType string // "useState", "useEffect", "map", "conditional", etc.
+	Line        int    `json:"line,omitempty"`
+	ReactCode   string `json:"reactCode,omitempty"`
+	MintyHint   string `json:"mintyHint,omitempty"`
+	PatternType string `json:"patternType,omitempty"` // "useState", "useEffect", "map", "conditional", etc.
 }
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// cloneElementCall finds calls of cloneElement and React.cloneElement
+var cloneElementCall = regexp.MustCompile(`\b(?:React\s*\.\s*)?cloneElement\s*\(`)
+
+// CloneElement reports whether e is React.cloneElement(element, props),
+// returning the source of the element and the props it injects:
+// cloneElement(child, { active, ...rest }) → child, [active ...rest]
+func CloneElement(e JSExpr, src string) (string, []string, bool) {
+	call, ok := Unparen(e).(*JSCall)
+	if !ok || call.New || len(call.Args) == 0 {
+		return "", nil, false
+	}
+	switch callee := call.Callee.(type) {
+	case *JSIdent:
+		ok = callee.Name == "cloneElement"
+	case *JSMember:
+		react, isIdent := callee.Object.(*JSIdent)
+		ok = isIdent && react.Name == "React" && callee.Property == "cloneElement"
+	default:
+		ok = false
+	}
+	if !ok {
+		return "", nil, false
+	}
+	element := strings.TrimSpace(call.Args[0].Span().Text(src))
+	var props []string
+	if len(call.Args) > 1 {
+		switch arg := Unparen(call.Args[1]).(type) {
+		case *JSObject:
+			for _, prop := range arg.Props {
+				switch {
+				case prop.Spread:
+					props = append(props, "..."+strings.TrimSpace(prop.Value.Span().Text(src)))
+				case prop.Computed != nil:
+					props = append(props, "["+strings.TrimSpace(prop.Computed.Span().Text(src))+"]")
+				default:
+					props = append(props, prop.Key)
+				}
+			}
+		default:
+			props = append(props, "..."+strings.TrimSpace(call.Args[1].Span().Text(src)))
+		}
+	}
+	if len(call.Args) > 2 {
+		props = append(props, "children")
+	}
+	return element, props, true
+}
+
+// CloneElementHint says what to do about the props a cloneElement call
+// injects, which cannot be added to children rendered before they are
+// passed
+func CloneElementHint(element string, props []string) string {
+	if len(props) == 0 {
+		return fmt.Sprintf("Clones %s unchanged: render it as it is", element)
+	}
+	hint := fmt.Sprintf("Injects %s into %s: Go children are rendered before they are passed, so pass these where the child components are called", strings.Join(props, ", "), element)
+	for _, prop := range props {
+		if isEventHandler(prop) {
+			return hint + "; the event handlers become HTMX attributes there"
+		}
+	}
+	return hint
+}
+
+// cloneElements suggests what to do about every cloneElement call of the
+// source
+func (p *Parser) cloneElements() {
+	if p.source == "" {
+		return
+	}
+	for _, m := range cloneElementCall.FindAllStringIndex(p.source, -1) {
+		src := p.source[m[0]:]
+		e, n, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		element, props, ok := CloneElement(e, src)
+		if !ok {
+			continue
+		}
+		react := fmt.Sprintf("cloneElement(%s, { %s })", element, strings.Join(props, ", "))
+		if len(props) == 0 {
+			react = strings.Join(strings.Fields(src[:n]), " ")
+		}
+		p.addSuggestion(1+strings.Count(p.source[:m[0]], "\n"), react, CloneElementHint(element, props), "cloneElement")
+	}
+}
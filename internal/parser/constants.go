@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"strings"
+)
+
+// parseConstant parses the right-hand side of a top-level const whose
+// value is data rather than code: const STATUSES = ['open', 'closed'] or
+// const LABELS = { open: 'Open' }. It returns nil, leaving the position
+// untouched, for anything else.
+func (p *Parser) parseConstant(name string, line int) *Constant {
+	if p.pos == 0 || p.source == "" {
+		return nil
+	}
+	i := skipJSSpace(p.source, p.tokens[p.pos-1].Offset)
+	if i < len(p.source) && p.source[i] == ':' {
+		// TypeScript annotation: const STATUSES: Status[] = [...]
+		jp := &jsParser{src: p.source, pos: i + 1}
+		if err := jp.skipType(); err != nil {
+			return nil
+		}
+		i = skipJSSpace(p.source, jp.pos)
+	}
+	if i >= len(p.source) || p.source[i] != '=' || strings.HasPrefix(p.source[i:], "=>") {
+		return nil
+	}
+	i = skipJSSpace(p.source, i+1)
+	e, n, err := ParseJSExprPrefix(p.source[i:])
+	if err != nil || !isConstantData(e) {
+		return nil
+	}
+	value := strings.TrimSpace(p.source[i : i+n])
+	return &Constant{
+		Name:       name,
+		Value:      value,
+		LineNumber: line,
+		EndLine:    1 + strings.Count(p.source[:i+n], "\n"),
+	}
+}
+
+// isConstantData reports whether e is made only of literals: arrays and
+// objects of strings, numbers and booleans, frozen or not
+func isConstantData(e JSExpr) bool {
+	switch n := Unparen(e).(type) {
+	case *JSLiteral:
+		return n.Kind != "regexp"
+	case *JSTemplate:
+		return n.Tag == nil && len(n.Exprs) == 0
+	case *JSUnary:
+		lit, ok := n.X.(*JSLiteral)
+		return n.Op == "-" && ok && lit.Kind == "number"
+	case *JSArray:
+		for _, el := range n.Elements {
+			if el == nil || !isConstantData(el) {
+				return false
+			}
+		}
+		return true
+	case *JSObject:
+		for _, prop := range n.Props {
+			if prop.Key == "" || prop.Shorthand || prop.Spread || !isConstantData(prop.Value) {
+				return false
+			}
+		}
+		return true
+	case *JSCall:
+		// Object.freeze({ ... })
+		return MemberPath(n.Callee) == "Object.freeze" && len(n.Args) == 1 && isConstantData(n.Args[0])
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// useContextDecl matches a component reading a context:
+// const { user } = useContext(UserContext)
+var useContextDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\{[^}]*\}|\w+)\s*=\s*(?:React\.)?useContext\(\s*(\w+)\s*\)`)
+
+// parseCreateContext parses the right-hand side of
+// const Name = createContext(default). It returns nil, leaving the
+// position untouched, if the declaration does not create a context.
+func (p *Parser) parseCreateContext(name string, line int) *ContextDecl {
+	start := p.pos
+	p.skipWhitespace()
+	if !p.match(TokenEquals) {
+		p.pos = start
+		return nil
+	}
+	p.skipWhitespace()
+	if p.checkIdent("React") && p.pos+2 < len(p.tokens) && p.tokens[p.pos+1].Type == TokenDot {
+		p.advance()
+		p.advance()
+	}
+	if !p.checkIdent("createContext") {
+		p.pos = start
+		return nil
+	}
+
+	decl := &ContextDecl{Name: name, LineNumber: line}
+	if call := p.current(); p.source != "" && call.Offset <= len(p.source) {
+		src := p.source[call.Offset-len(call.Value):]
+		if e, _, err := ParseJSExprPrefix(src); err == nil {
+			if c, ok := e.(*JSCall); ok && len(c.Args) > 0 {
+				decl.Default = strings.TrimSpace(c.Args[0].Span().Text(src))
+			}
+		}
+	}
+	return decl
+}
+
+// componentContexts finds the useContext calls on the 1-based source
+// lines [start, end) and the names their values are bound to
+func componentContexts(lines []string, start, end int) []ContextUse {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	var uses []ContextUse
+	for _, m := range useContextDecl.FindAllStringSubmatchIndex(body, -1) {
+		use := ContextUse{
+			Context:    body[m[4]:m[5]],
+			LineNumber: start + strings.Count(body[:m[0]], "\n"),
+		}
+		use.Names, use.Keys = bindingNames(body[m[2]:m[3]])
+		if len(use.Keys) == 0 && len(use.Names) == 1 {
+			seen := map[string]bool{}
+			field := regexp.MustCompile(`\b` + regexp.QuoteMeta(use.Names[0]) + `\??\.(\w+)`)
+			for _, f := range field.FindAllStringSubmatch(body[m[1]:], -1) {
+				if !seen[f[1]] {
+					seen[f[1]] = true
+					use.Fields = append(use.Fields, f[1])
+				}
+			}
+		}
+		uses = append(uses, use)
+	}
+	return uses
+}
+
+// suggestContexts replaces the generic useContext hint for contexts read
+// by a component, which are passed down as parameters instead
+func (p *Parser) suggestContexts(comps []Component) {
+	for i := range p.suggestions {
+		s := &p.suggestions[i]
+		if s.PatternType != "useContext" {
+			continue
+		}
+		for _, comp := range comps {
+			for _, use := range comp.Contexts {
+				if use.LineNumber == s.Line {
+					s.ReactCode = fmt.Sprintf("useContext(%s)", use.Context)
+					s.MintyHint = fmt.Sprintf("Passed down from <%s.Provider> as a parameter of %s and the components between them", use.Context, comp.Name)
+				}
+			}
+		}
+	}
+}
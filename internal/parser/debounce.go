@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// debounceHook matches the hooks that return a debounced copy of a
+	// value: useDebounce(term, 300), Mantine's useDebouncedValue
+	debounceHook = regexp.MustCompile(`\b(useDebounce|useDebouncedValue)\s*\(`)
+	// debounceFunc matches lodash debounce and the hooks that debounce a
+	// callback
+	debounceFunc = regexp.MustCompile(`\b(?:_\.|lodash\.)?(debounce|useDebouncedCallback|useDebounceCallback)\s*\(`)
+	// declaredName matches the start of a declaration
+	declaredName = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=`)
+	// timeoutCall matches a setTimeout call
+	timeoutCall = regexp.MustCompile(`\b(?:window\.)?setTimeout\s*\(`)
+)
+
+// componentDebounces finds the debounced state on the 1-based source
+// lines [start, end): values read through a debounce hook, setters
+// wrapped in a debounced function, and effects on string state that
+// restart a timeout whenever it changes
+func componentDebounces(source string, lines []string, start, end int, stateVars []StateVariable) []Debounce {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	states := make(map[string]StateVariable, len(stateVars))
+	setters := make(map[string]string, len(stateVars))
+	for _, sv := range stateVars {
+		states[sv.Name] = sv
+		setters[sv.Setter] = sv.Name
+	}
+	line := func(offset int) int { return start + strings.Count(body[:offset], "\n") }
+
+	var debounces []Debounce
+	for _, loc := range debounceHook.FindAllStringSubmatchIndex(body, -1) {
+		src := body[loc[0]:]
+		call, ok := parseCall(src)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		state, ok := call.Args[0].(*JSIdent)
+		if !ok || states[state.Name].Name == "" {
+			continue
+		}
+		debounces = append(debounces, Debounce{
+			State:      state.Name,
+			Delay:      debounceDelay(source, call),
+			Via:        "useDebounce",
+			LineNumber: line(loc[0]),
+		})
+	}
+
+	for _, loc := range debounceFunc.FindAllStringSubmatchIndex(body, -1) {
+		src := body[loc[0]:]
+		call, ok := parseCall(src)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		d := Debounce{
+			Func:       declaredFunc(body[:loc[0]]),
+			Delay:      debounceDelay(source, call),
+			Via:        "debounce",
+			LineNumber: line(loc[0]),
+		}
+		if m := setterCall.FindStringSubmatch(call.Args[0].Span().Text(src)); m != nil {
+			d.State = setters[m[1]]
+		}
+		if d.State == "" && d.Func == "" {
+			continue
+		}
+		debounces = append(debounces, d)
+	}
+
+	for _, loc := range effectCall.FindAllStringIndex(body, -1) {
+		src := body[loc[0]:]
+		call, ok := parseCall(src)
+		if !ok || len(call.Args) < 2 {
+			continue
+		}
+		fn, ok := call.Args[0].(*JSArrow)
+		deps, isArray := call.Args[1].(*JSArray)
+		if !ok || !isArray || !strings.Contains(fn.Block, "clearTimeout") {
+			continue
+		}
+		t := timeoutCall.FindStringIndex(fn.Block)
+		if t == nil {
+			continue
+		}
+		timeout, ok := parseCall(fn.Block[t[0]:])
+		if !ok {
+			continue
+		}
+		for _, dep := range deps.Elements {
+			ident, ok := dep.(*JSIdent)
+			if !ok || states[ident.Name].InitType != "string" {
+				continue
+			}
+			debounces = append(debounces, Debounce{
+				State:      ident.Name,
+				Delay:      debounceDelay(source, timeout),
+				Via:        "setTimeout",
+				LineNumber: line(loc[0]),
+			})
+			break
+		}
+	}
+	return debounces
+}
+
+// parseCall parses the call src starts with
+func parseCall(src string) (*JSCall, bool) {
+	e, _, err := ParseJSExprPrefix(src)
+	if err != nil {
+		return nil, false
+	}
+	call, ok := e.(*JSCall)
+	return call, ok
+}
+
+// declaredFunc returns the name declared by the statement that before
+// ends in, such as debouncedSearch in
+// const debouncedSearch = useMemo(() => debounce(
+func declaredFunc(before string) string {
+	decls := declaredName.FindAllStringSubmatchIndex(before, -1)
+	if len(decls) == 0 {
+		return ""
+	}
+	m := decls[len(decls)-1]
+	if strings.Contains(before[m[1]:], ";") {
+		return ""
+	}
+	return before[m[2]:m[3]]
+}
+
+// debounceDelay returns the delay in milliseconds a debounce call takes
+// as its last argument: a number, or a constant of the file. It is 0
+// when the delay is not known.
+func debounceDelay(source string, call *JSCall) int {
+	if len(call.Args) < 2 {
+		return 0
+	}
+	switch arg := call.Args[len(call.Args)-1].(type) {
+	case *JSLiteral:
+		if arg.Kind == "number" {
+			if n, err := strconv.Atoi(strings.ReplaceAll(arg.Value, "_", "")); err == nil {
+				return n
+			}
+		}
+	case *JSIdent:
+		decl := regexp.MustCompile(`\bconst\s+` + regexp.QuoteMeta(arg.Name) + `\s*=\s*(\d[\d_]*)\s*;?\s*\n`)
+		if m := decl.FindStringSubmatch(source); m != nil {
+			n, _ := strconv.Atoi(strings.ReplaceAll(m[1], "_", ""))
+			return n
+		}
+	}
+	return 0
+}
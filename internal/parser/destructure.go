@@ -0,0 +1,306 @@
+package parser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// destructuredFields returns the keys a simple object pattern binds, by
+// local name: "{ id, title: label }" gives id → id and label → title. ok
+// is false for anything else, including defaults, rest elements and
+// nested patterns.
+func destructuredFields(param string) (map[string]string, bool) {
+	param = strings.TrimSpace(param)
+	if !strings.HasPrefix(param, "{") || !strings.HasSuffix(param, "}") {
+		return nil, false
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(param[1:len(param)-1], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, local, renamed := strings.Cut(part, ":")
+		key, local = strings.TrimSpace(key), strings.TrimSpace(local)
+		if !renamed {
+			local = key
+		}
+		if !isSimpleIdent(key) || !isSimpleIdent(local) {
+			return nil, false
+		}
+		fields[local] = key
+	}
+	return fields, len(fields) > 0
+}
+
+// arrayPattern returns the names a simple array pattern binds, in order,
+// with "" for the elements skipped: "[key, value]" gives key and value,
+// "[, value]" "" and value. ok is false for anything else.
+func arrayPattern(param string) ([]string, bool) {
+	param = strings.TrimSpace(param)
+	if !strings.HasPrefix(param, "[") || !strings.HasSuffix(param, "]") {
+		return nil, false
+	}
+	names := strings.Split(param[1:len(param)-1], ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+		if names[i] != "" && !isSimpleIdent(names[i]) {
+			return nil, false
+		}
+	}
+	return names, true
+}
+
+// itemName names the variable standing in for a destructured parameter
+// of a callback over collection: the collection in the singular, users
+// giving user, or item, avoiding the names taken
+func itemName(collection string, taken map[string]bool) string {
+	name := collection[strings.LastIndex(collection, ".")+1:]
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		name = strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = strings.TrimSuffix(name, "s")
+	default:
+		name = "item"
+	}
+	if !isSimpleIdent(name) || jsReserved[name] || taken[name] {
+		name = "item"
+	}
+	for i := 2; taken[name]; i++ {
+		name = "item" + strconv.Itoa(i)
+	}
+	taken[name] = true
+	return name
+}
+
+// destructuredParams replaces the destructured parameters of a callback
+// over collection with named ones, rewriting body to read their fields:
+// ({ id, name }) => name becomes user => user.name. body is returned
+// unchanged when no parameter is destructured, and ok is false when one
+// is destructured in a way not supported.
+func destructuredParams(collection string, params []string, body string) ([]string, string, bool) {
+	var patterns []map[string]string
+	taken := map[string]bool{}
+	for _, param := range params {
+		fields, destructured := destructuredFields(param)
+		if !destructured && !isSimpleIdent(strings.TrimSpace(param)) {
+			return nil, "", false
+		}
+		patterns = append(patterns, fields)
+		for _, name := range paramNames(param) {
+			taken[name] = true
+		}
+	}
+	if e, err := ParseJSExpr(body); err == nil {
+		for _, name := range JSIdentifiers(e) {
+			taken[name] = true
+		}
+	}
+	named := make([]string, len(params))
+	for i, param := range params {
+		named[i] = strings.TrimSpace(param)
+		if patterns[i] != nil {
+			named[i] = itemName(collection, taken)
+			body = renameFields(body, patterns[i], named[i])
+		}
+	}
+	return named, body, true
+}
+
+// fieldEdit replaces the source between start and end
+type fieldEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits makes the edits to src
+func applyEdits(src string, edits []fieldEdit) string {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		src = src[:e.start] + e.text + src[e.end:]
+	}
+	return src
+}
+
+// renameFields rewrites the JavaScript expression src to read the
+// destructured fields from item: name becomes item.name, shorthand
+// properties { name } become { name: item.name }. Parameters of nested
+// functions shadow the fields.
+func renameFields(src string, fields map[string]string, item string) string {
+	e, err := ParseJSExpr(src)
+	if err != nil {
+		return renameWords(src, fields, item, nil)
+	}
+	var edits []fieldEdit
+	var visit func(e JSExpr, bound map[string]bool)
+	visit = func(e JSExpr, bound map[string]bool) {
+		WalkJS(e, func(x JSExpr) bool {
+			switch n := x.(type) {
+			case *JSIdent:
+				if key, ok := fields[n.Name]; ok && !bound[n.Name] {
+					edits = append(edits, fieldEdit{n.Start, n.End, item + "." + key})
+				}
+			case *JSMember:
+				visit(n.Object, bound)
+				visit(n.Index, bound)
+				return false
+			case *JSObject:
+				for _, prop := range n.Props {
+					id, ok := prop.Value.(*JSIdent)
+					if key, field := fields[prop.Key]; ok && prop.Shorthand && field && !bound[id.Name] {
+						edits = append(edits, fieldEdit{id.Start, id.End, id.Name + ": " + item + "." + key})
+						continue
+					}
+					visit(prop.Computed, bound)
+					visit(prop.Value, bound)
+				}
+				return false
+			case *JSArrow:
+				inner := map[string]bool{}
+				for name := range bound {
+					inner[name] = true
+				}
+				for _, param := range n.Params {
+					for _, name := range paramNames(param) {
+						inner[name] = true
+					}
+				}
+				visit(n.Body, inner)
+				if n.Block != "" {
+					if i := strings.Index(n.Span().Text(src), n.Block); i >= 0 {
+						start := n.Start + i
+						edits = append(edits, fieldEdit{start, start + len(n.Block), renameWords(n.Block, fields, item, inner)})
+					}
+				}
+				return false
+			case *JSXNode:
+				edits = append(edits, fieldEdit{n.Start, n.End, renameJSX(n.Span().Text(src), fields, item)})
+				return false
+			}
+			return true
+		})
+	}
+	visit(e, map[string]bool{})
+	return applyEdits(src, edits)
+}
+
+// renameJSX rewrites the {…} expressions of a JSX source, leaving its
+// text alone
+func renameJSX(raw string, fields map[string]string, item string) string {
+	var edits []fieldEdit
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '{' {
+			continue
+		}
+		end := skipBalanced(raw, i)
+		if end < 0 {
+			break
+		}
+		inner := raw[i+1 : end-1]
+		if spread := strings.TrimSpace(inner); strings.HasPrefix(spread, "...") {
+			inner = "..." + renameFields(strings.TrimPrefix(spread, "..."), fields, item)
+		} else {
+			inner = renameFields(inner, fields, item)
+		}
+		edits = append(edits, fieldEdit{i + 1, end - 1, inner})
+		i = end - 1
+	}
+	return applyEdits(raw, edits)
+}
+
+// renameWords rewrites the fields read in source the expression parser
+// keeps opaque, such as block bodies, word by word: strings, property
+// names and object keys are left alone, as are the names bound
+func renameWords(text string, fields map[string]string, item string, bound map[string]bool) string {
+	var edits []fieldEdit
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"' || c == '\'':
+			if end := skipString(text, i); end > 0 {
+				i = end - 1
+			}
+			continue
+		case c == '`':
+			if end := skipTemplate(text, i); end > 0 {
+				i = end - 1
+			}
+			continue
+		case !isIdentStart(c) || i > 0 && (isWordChar(text[i-1]) || text[i-1] == '.'):
+			continue
+		}
+		j := i
+		for j < len(text) && isWordChar(text[j]) {
+			j++
+		}
+		name := text[i:j]
+		key, ok := fields[name]
+		before := strings.TrimRight(text[:i], " \t\n")
+		after := strings.TrimLeft(text[j:], " \t\n")
+		isKey := strings.HasPrefix(after, ":") && (strings.HasSuffix(before, "{") || strings.HasSuffix(before, ","))
+		if ok && !bound[name] && !isKey && !strings.HasSuffix(before, "?.") {
+			edits = append(edits, fieldEdit{i, j, item + "." + key})
+		}
+		i = j - 1
+	}
+	return applyEdits(text, edits)
+}
+
+// bindFields rewrites the expressions of a parsed map body to read the
+// destructured fields from item
+func bindFields(node Node, fields map[string]string, item string) {
+	rename := func(src string) string {
+		if strings.TrimSpace(src) == "" {
+			return src
+		}
+		return renameFields(src, fields, item)
+	}
+	switch n := node.(type) {
+	case *Element:
+		for i := range n.Attributes {
+			attr := &n.Attributes[i]
+			if attr.IsSpread {
+				attr.SpreadExpr = rename(attr.SpreadExpr)
+				continue
+			}
+			if attr.Expression.Raw == "" {
+				continue
+			}
+			attr.Expression.Raw = rename(attr.Expression.Raw)
+			bindFields(attr.Expression.Parsed, fields, item)
+			if attr.EventHandler != nil {
+				attr.EventHandler = parseEventHandler(attr.Name, attr.Expression.Raw, attr.EventHandler.LineNumber)
+			}
+		}
+		for _, child := range n.Children {
+			bindFields(child, fields, item)
+		}
+	case *Expression:
+		n.Raw = rename(n.Raw)
+		bindFields(n.Parsed, fields, item)
+	case *Fragment:
+		for _, child := range n.Children {
+			bindFields(child, fields, item)
+		}
+	case *MapExpr:
+		n.Collection = rename(n.Collection)
+		n.Length = rename(n.Length)
+		n.Key = rename(n.Key)
+		inner := map[string]string{}
+		for local, key := range fields {
+			if local != n.ItemVar && local != n.ValueVar && local != n.IndexVar {
+				inner[local] = key
+			}
+		}
+		bindFields(n.Body, inner, item)
+	case *Conditional:
+		n.Condition = rename(n.Condition)
+		bindFields(n.Consequent, fields, item)
+	case *Ternary:
+		n.Condition = rename(n.Condition)
+		bindFields(n.Consequent, fields, item)
+		bindFields(n.Alternate, fields, item)
+	}
+}
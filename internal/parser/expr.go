@@ -0,0 +1,1431 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSExpr is a node of a parsed JavaScript expression. Every node records
+// its byte span in the parsed source, so callers can recover the exact
+// text of any subexpression with Text.
+type JSExpr interface {
+	Span() JSSpan
+}
+
+// JSSpan is the byte range of an expression in its source
+type JSSpan struct {
+	Start int
+	End   int
+}
+
+func (s JSSpan) Span() JSSpan { return s }
+
+// Text returns the source text of the span
+func (s JSSpan) Text(src string) string {
+	if s.Start < 0 || s.End > len(src) || s.Start > s.End {
+		return ""
+	}
+	return src[s.Start:s.End]
+}
+
+// JSIdent is an identifier, including this
+type JSIdent struct {
+	JSSpan
+	Name string
+}
+
+// JSLiteral is a string, number, boolean, null, undefined or regexp literal
+type JSLiteral struct {
+	JSSpan
+	Kind  string // "string", "number", "bool", "null", "undefined", "regexp"
+	Value string // unquoted for strings, source text otherwise
+}
+
+// JSTemplate is a template literal; Quasis has one more entry than Exprs
+type JSTemplate struct {
+	JSSpan
+	Tag    JSExpr // tag function, if any
+	Quasis []string
+	Exprs  []JSExpr
+}
+
+// JSMember is a property access: a.b, a?.b or a[b]
+type JSMember struct {
+	JSSpan
+	Object   JSExpr
+	Property string // for a.b
+	Index    JSExpr // for a[b]
+	Optional bool
+}
+
+// JSCall is a function call or new expression
+type JSCall struct {
+	JSSpan
+	Callee   JSExpr
+	Args     []JSExpr
+	Optional bool // a?.()
+	New      bool
+}
+
+// JSUnary is a prefix or postfix operator
+type JSUnary struct {
+	JSSpan
+	Op      string // "!", "-", "+", "~", "typeof", "void", "delete", "await", "++", "--"
+	X       JSExpr
+	Postfix bool
+}
+
+// JSBinary is a binary or logical operator
+type JSBinary struct {
+	JSSpan
+	Op    string
+	Left  JSExpr
+	Right JSExpr
+}
+
+// JSConditional is a ternary: Test ? Then : Else
+type JSConditional struct {
+	JSSpan
+	Test JSExpr
+	Then JSExpr
+	Else JSExpr
+}
+
+// JSAssign is an assignment, including compound forms like +=
+type JSAssign struct {
+	JSSpan
+	Op     string
+	Target JSExpr
+	Value  JSExpr
+}
+
+// JSArrow is an arrow function or function expression. Expression bodies
+// are parsed; block bodies are kept as source text.
+type JSArrow struct {
+	JSSpan
+	Params []string // parameter source text: "item", "{ id, name }", "n = 1"
+	Body   JSExpr   // expression body
+	Block  string   // block body, without the braces
+	Async  bool
+}
+
+// JSArray is an array literal; holes are nil
+type JSArray struct {
+	JSSpan
+	Elements []JSExpr
+}
+
+// JSObject is an object literal
+type JSObject struct {
+	JSSpan
+	Props []JSProperty
+}
+
+// JSProperty is one entry of an object literal
+type JSProperty struct {
+	Key       string // static key; empty for computed keys and spreads
+	Computed  JSExpr // [key]
+	Value     JSExpr // the spread operand for spreads
+	Shorthand bool   // { a }
+	Spread    bool   // { ...a }
+}
+
+// JSSpread is ...x in calls and array literals
+type JSSpread struct {
+	JSSpan
+	X JSExpr
+}
+
+// JSParen is a parenthesized expression
+type JSParen struct {
+	JSSpan
+	X JSExpr
+}
+
+// JSXNode is a JSX element or fragment embedded in an expression, kept
+// as source text for the JSX parser
+type JSXNode struct {
+	JSSpan
+	Raw string
+}
+
+// ParseJSExpr parses src as a single JavaScript expression
+func ParseJSExpr(src string) (JSExpr, error) {
+	e, end, err := ParseJSExprPrefix(src)
+	if err != nil {
+		return nil, err
+	}
+	if p := (&jsParser{src: src, pos: end}); p.peek().kind != jsEOF {
+		tok := p.peek()
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.start)
+	}
+	return e, nil
+}
+
+// ParseJSExprPrefix parses the longest expression at the start of src,
+// such as the initializer of a declaration followed by more statements,
+// and returns it with the offset where it ends
+func ParseJSExprPrefix(src string) (JSExpr, int, error) {
+	p := &jsParser{src: src}
+	e, err := p.parseAssign()
+	if err != nil {
+		return nil, 0, err
+	}
+	return e, p.pos, nil
+}
+
+// Unparen strips any parentheses around e
+func Unparen(e JSExpr) JSExpr {
+	for {
+		p, ok := e.(*JSParen)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// MemberPath returns the dotted path of an identifier or chain of plain
+// property accesses (user.address.city), or "" for anything else
+func MemberPath(e JSExpr) string {
+	switch n := e.(type) {
+	case *JSIdent:
+		return n.Name
+	case *JSMember:
+		if n.Index != nil || n.Optional {
+			return ""
+		}
+		if base := MemberPath(n.Object); base != "" {
+			return base + "." + n.Property
+		}
+	}
+	return ""
+}
+
+// MethodCall reports whether e calls a method by name, returning the
+// receiver: for users.filter(...) and name "filter" it returns users
+func MethodCall(e JSExpr, name string) (*JSCall, JSExpr, bool) {
+	call, ok := e.(*JSCall)
+	if !ok || call.New {
+		return nil, nil, false
+	}
+	m, ok := call.Callee.(*JSMember)
+	if !ok || m.Index != nil || m.Property != name {
+		return nil, nil, false
+	}
+	return call, m.Object, true
+}
+
+// WalkJS calls fn for e and every subexpression, depth first. Returning
+// false from fn skips the children of that node.
+func WalkJS(e JSExpr, fn func(JSExpr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	switch n := e.(type) {
+	case *JSTemplate:
+		WalkJS(n.Tag, fn)
+		for _, x := range n.Exprs {
+			WalkJS(x, fn)
+		}
+	case *JSMember:
+		WalkJS(n.Object, fn)
+		WalkJS(n.Index, fn)
+	case *JSCall:
+		WalkJS(n.Callee, fn)
+		for _, x := range n.Args {
+			WalkJS(x, fn)
+		}
+	case *JSUnary:
+		WalkJS(n.X, fn)
+	case *JSBinary:
+		WalkJS(n.Left, fn)
+		WalkJS(n.Right, fn)
+	case *JSConditional:
+		WalkJS(n.Test, fn)
+		WalkJS(n.Then, fn)
+		WalkJS(n.Else, fn)
+	case *JSAssign:
+		WalkJS(n.Target, fn)
+		WalkJS(n.Value, fn)
+	case *JSArrow:
+		WalkJS(n.Body, fn)
+	case *JSArray:
+		for _, x := range n.Elements {
+			WalkJS(x, fn)
+		}
+	case *JSObject:
+		for _, prop := range n.Props {
+			WalkJS(prop.Computed, fn)
+			WalkJS(prop.Value, fn)
+		}
+	case *JSSpread:
+		WalkJS(n.X, fn)
+	case *JSParen:
+		WalkJS(n.X, fn)
+	}
+}
+
+// wordPattern finds identifiers in source text the expression parser
+// keeps opaque (block bodies, JSX); a preceding dot marks a property
+var wordPattern = regexp.MustCompile(`(\.\s*)?\b([A-Za-z_$][\w$]*)`)
+
+// JSIdentifiers returns the free identifiers e reads, in order of first
+// appearance. Property names, object keys and the parameters of nested
+// functions are excluded. Block bodies and JSX are scanned for words, so
+// the result may include a few names they only mention.
+func JSIdentifiers(e JSExpr) []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string, bound map[string]bool) {
+		if !seen[name] && !bound[name] && !jsReserved[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	addWords := func(text string, bound map[string]bool) {
+		for _, m := range wordPattern.FindAllStringSubmatch(stripLiterals(text), -1) {
+			if m[1] == "" {
+				add(m[2], bound)
+			}
+		}
+	}
+
+	var visit func(e JSExpr, bound map[string]bool)
+	visit = func(e JSExpr, bound map[string]bool) {
+		WalkJS(e, func(x JSExpr) bool {
+			switch n := x.(type) {
+			case *JSIdent:
+				add(n.Name, bound)
+			case *JSMember:
+				visit(n.Object, bound)
+				visit(n.Index, bound)
+				return false
+			case *JSObject:
+				for _, prop := range n.Props {
+					visit(prop.Computed, bound)
+					visit(prop.Value, bound)
+				}
+				return false
+			case *JSArrow:
+				inner := map[string]bool{}
+				for name := range bound {
+					inner[name] = true
+				}
+				for _, param := range n.Params {
+					for _, name := range paramNames(param) {
+						inner[name] = true
+					}
+				}
+				visit(n.Body, inner)
+				addWords(n.Block, inner)
+				return false
+			case *JSXNode:
+				addWords(jsxExpressions(n.Raw), bound)
+			}
+			return true
+		})
+	}
+	visit(e, map[string]bool{})
+	return names
+}
+
+// paramNames returns the names a parameter binds: "item" → item,
+// "{ id, label: name }" → id, name, "n = 1" → n
+func paramNames(param string) []string {
+	if i := strings.Index(param, "="); i >= 0 && !strings.ContainsAny(param, "{[") {
+		param = param[:i]
+	}
+	param = strings.TrimPrefix(strings.TrimSpace(param), "...")
+	if isSimpleIdent(param) {
+		return []string{param}
+	}
+	var names []string
+	for _, part := range regexp.MustCompile(`[{}\[\],]`).Split(param, -1) {
+		part = strings.TrimSpace(part)
+		if i := strings.Index(part, "="); i >= 0 {
+			part = strings.TrimSpace(part[:i])
+		}
+		if i := strings.Index(part, ":"); i >= 0 {
+			part = strings.TrimSpace(part[i+1:])
+		}
+		part = strings.TrimPrefix(part, "...")
+		if isSimpleIdent(part) {
+			names = append(names, part)
+		}
+	}
+	return names
+}
+
+// jsxExpressions returns the text of the {…} expressions in a JSX source,
+// which is where it reads variables
+func jsxExpressions(raw string) string {
+	var out strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '{' {
+			continue
+		}
+		end := skipBalanced(raw, i)
+		if end < 0 {
+			break
+		}
+		out.WriteString(raw[i+1 : end-1])
+		out.WriteByte(' ')
+		i = end - 1
+	}
+	return out.String()
+}
+
+// stripLiterals blanks out the text of string and template literals,
+// keeping the expressions interpolated into templates
+func stripLiterals(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\'':
+			if end := skipString(text, i); end > 0 {
+				out.WriteByte(' ')
+				i = end - 1
+				continue
+			}
+		case '`':
+			if end := skipTemplate(text, i); end > 0 {
+				tmpl, err := (&jsParser{src: text}).parseTemplate(jsToken{jsTemplateTok, text[i:end], i, end})
+				if err == nil {
+					for _, x := range tmpl.Exprs {
+						out.WriteString(" " + x.Span().Text(text) + " ")
+					}
+					i = end - 1
+					continue
+				}
+			}
+		}
+		out.WriteByte(text[i])
+	}
+	return out.String()
+}
+
+// jsReserved are keywords and literals that are never variables
+var jsReserved = map[string]bool{
+	"true": true, "false": true, "null": true, "undefined": true, "this": true,
+	"typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"void": true, "delete": true, "await": true, "async": true, "function": true,
+	"return": true, "const": true, "let": true, "var": true, "if": true,
+	"else": true, "for": true, "while": true, "do": true, "switch": true,
+	"case": true, "break": true, "continue": true, "throw": true, "try": true,
+	"catch": true, "finally": true, "class": true, "as": true,
+}
+
+// Lexing
+
+type jsTokenKind int
+
+const (
+	jsEOF jsTokenKind = iota
+	jsIdentTok
+	jsNumberTok
+	jsStringTok
+	jsTemplateTok
+	jsPunct
+)
+
+type jsToken struct {
+	kind       jsTokenKind
+	text       string
+	start, end int
+}
+
+// jsPuncts are matched longest first
+var jsPuncts = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=",
+	"*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+	"{", "}", "(", ")", "[", "]", ";", ",", "<", ">", "+", "-", "*", "/", "%",
+	"&", "|", "^", "!", "~", "?", ":", "=", ".", "@", "#",
+}
+
+type jsParser struct {
+	src string
+	pos int // offset of the next unread byte
+}
+
+// skipJSSpace returns the offset of the first byte at or after i that
+// is not whitespace or part of a comment
+func skipJSSpace(src string, i int) int {
+	for i < len(src) {
+		switch {
+		case src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return len(src)
+			}
+			i += end + 1
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return len(src)
+			}
+			i += end + 4
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// peek scans the next token without consuming it
+func (p *jsParser) peek() jsToken {
+	i := skipJSSpace(p.src, p.pos)
+	if i >= len(p.src) {
+		return jsToken{kind: jsEOF, start: i, end: i}
+	}
+	c := p.src[i]
+	switch {
+	case isIdentStart(c) || c >= 0x80:
+		j := i + 1
+		for j < len(p.src) && (isIdentStart(p.src[j]) || p.src[j] >= '0' && p.src[j] <= '9' || p.src[j] >= 0x80) {
+			j++
+		}
+		return jsToken{jsIdentTok, p.src[i:j], i, j}
+	case c >= '0' && c <= '9' || c == '.' && i+1 < len(p.src) && p.src[i+1] >= '0' && p.src[i+1] <= '9':
+		j := i + 1
+		for j < len(p.src) {
+			d := p.src[j]
+			if d >= '0' && d <= '9' || d == '.' || d == '_' || d >= 'a' && d <= 'z' || d >= 'A' && d <= 'Z' {
+				j++
+				continue
+			}
+			if (d == '+' || d == '-') && (p.src[j-1] == 'e' || p.src[j-1] == 'E') && !strings.HasPrefix(p.src[i:], "0x") {
+				j++
+				continue
+			}
+			break
+		}
+		return jsToken{jsNumberTok, p.src[i:j], i, j}
+	case c == '"' || c == '\'':
+		j := skipString(p.src, i)
+		if j < 0 {
+			return jsToken{kind: jsEOF, text: "unterminated string", start: i, end: len(p.src)}
+		}
+		return jsToken{jsStringTok, p.src[i:j], i, j}
+	case c == '`':
+		j := skipTemplate(p.src, i)
+		if j < 0 {
+			return jsToken{kind: jsEOF, text: "unterminated template", start: i, end: len(p.src)}
+		}
+		return jsToken{jsTemplateTok, p.src[i:j], i, j}
+	}
+	for _, punct := range jsPuncts {
+		if strings.HasPrefix(p.src[i:], punct) {
+			// a?.5 is a conditional, not optional chaining
+			if punct == "?." && i+2 < len(p.src) && p.src[i+2] >= '0' && p.src[i+2] <= '9' {
+				continue
+			}
+			return jsToken{jsPunct, punct, i, i + len(punct)}
+		}
+	}
+	return jsToken{jsPunct, p.src[i : i+1], i, i + 1}
+}
+
+func (p *jsParser) next() jsToken {
+	tok := p.peek()
+	p.pos = tok.end
+	return tok
+}
+
+// is reports whether the next token is the given punctuator or keyword
+func (p *jsParser) is(text string) bool {
+	tok := p.peek()
+	return (tok.kind == jsPunct || tok.kind == jsIdentTok) && tok.text == text
+}
+
+func (p *jsParser) expect(text string) (jsToken, error) {
+	tok := p.next()
+	if (tok.kind != jsPunct && tok.kind != jsIdentTok) || tok.text != text {
+		return tok, p.errorAt(tok, "expected "+strconv.Quote(text))
+	}
+	return tok, nil
+}
+
+func (p *jsParser) errorAt(tok jsToken, msg string) error {
+	if tok.kind == jsEOF {
+		if tok.text != "" {
+			return fmt.Errorf("%s at offset %d", tok.text, tok.start)
+		}
+		return fmt.Errorf("%s, got end of expression", msg)
+	}
+	return fmt.Errorf("%s, got %q at offset %d", msg, tok.text, tok.start)
+}
+
+// skipString returns the offset after the quoted string starting at i,
+// or -1 if it is not closed
+func skipString(src string, i int) int {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// skipTemplate returns the offset after the template literal starting at
+// i, or -1 if it is not closed
+func skipTemplate(src string, i int) int {
+	for j := i + 1; j < len(src); j++ {
+		switch {
+		case src[j] == '\\':
+			j++
+		case src[j] == '`':
+			return j + 1
+		case src[j] == '$' && j+1 < len(src) && src[j+1] == '{':
+			end := skipBalanced(src, j+1)
+			if end < 0 {
+				return -1
+			}
+			j = end - 1
+		}
+	}
+	return -1
+}
+
+// skipBalanced returns the offset after the bracket that closes the one
+// at i, skipping strings, templates and comments, or -1 if it is not
+// closed. A quote with no closing quote on its line is taken to be an
+// apostrophe in JSX text.
+func skipBalanced(src string, i int) int {
+	var stack []byte
+	for j := i; j < len(src); j++ {
+		switch c := src[j]; c {
+		case '(', '[', '{':
+			stack = append(stack, c)
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != map[byte]byte{')': '(', ']': '[', '}': '{'}[c] {
+				return -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return j + 1
+			}
+		case '"', '\'':
+			if end := skipString(src, j); end > 0 {
+				j = end - 1
+			}
+		case '`':
+			end := skipTemplate(src, j)
+			if end < 0 {
+				return -1
+			}
+			j = end - 1
+		case '/':
+			if k := skipJSSpace(src, j); k > j {
+				j = k - 1
+			}
+		}
+	}
+	return -1
+}
+
+// skipJSX returns the offset after the JSX element or fragment starting
+// with the '<' at i, or -1 if it is not well formed
+func skipJSX(src string, i int) int {
+	depth := 0
+	for i < len(src) {
+		if src[i] != '<' {
+			if depth == 0 {
+				return -1
+			}
+			if src[i] == '{' {
+				end := skipBalanced(src, i)
+				if end < 0 {
+					return -1
+				}
+				i = end
+			} else {
+				i++
+			}
+			continue
+		}
+
+		// Closing tag
+		if i+1 < len(src) && src[i+1] == '/' {
+			end := strings.IndexByte(src[i:], '>')
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+			depth--
+			if depth == 0 {
+				return i
+			}
+			continue
+		}
+
+		// Opening tag, attributes included
+		i++
+		selfClose := false
+	tag:
+		for i < len(src) {
+			switch c := src[i]; {
+			case c == '{':
+				end := skipBalanced(src, i)
+				if end < 0 {
+					return -1
+				}
+				i = end
+			case c == '"' || c == '\'':
+				end := strings.IndexByte(src[i+1:], c)
+				if end < 0 {
+					return -1
+				}
+				i += end + 2
+			case c == '/' && i+1 < len(src) && src[i+1] == '>':
+				i += 2
+				selfClose = true
+				break tag
+			case c == '>':
+				i++
+				break tag
+			default:
+				i++
+			}
+		}
+		if !selfClose {
+			depth++
+		} else if depth == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Parsing
+
+// binaryPrecedence of each binary operator; higher binds tighter
+var binaryPrecedence = map[string]int{
+	"??": 1, "||": 1, "&&": 2, "|": 3, "^": 4, "&": 5,
+	"==": 6, "!=": 6, "===": 6, "!==": 6,
+	"<": 7, ">": 7, "<=": 7, ">=": 7, "instanceof": 7, "in": 7,
+	"<<": 8, ">>": 8, ">>>": 8,
+	"+": 9, "-": 9, "*": 10, "/": 10, "%": 10, "**": 11,
+}
+
+var assignOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"**=": true, "<<=": true, ">>=": true, ">>>=": true, "&=": true, "|=": true,
+	"^=": true, "&&=": true, "||=": true, "??=": true,
+}
+
+func (p *jsParser) parseAssign() (JSExpr, error) {
+	if arrow, ok, err := p.tryArrow(); ok || err != nil {
+		return arrow, err
+	}
+	start := p.peek().start
+	left, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == jsPunct && assignOps[tok.text] {
+		p.next()
+		value, err := p.parseAssign()
+		if err != nil {
+			return nil, err
+		}
+		return &JSAssign{JSSpan{start, p.pos}, tok.text, left, value}, nil
+	}
+	return left, nil
+}
+
+// tryArrow parses an arrow function if one starts here
+func (p *jsParser) tryArrow() (JSExpr, bool, error) {
+	save := p.pos
+	start := p.peek().start
+	async := false
+	if tok := p.peek(); tok.kind == jsIdentTok && tok.text == "async" {
+		p.next()
+		if next := p.peek(); next.kind == jsIdentTok || next.text == "(" {
+			async = true
+		} else {
+			p.pos = save
+		}
+	}
+
+	var params []string
+	tok := p.peek()
+	switch {
+	case tok.kind == jsIdentTok && !jsReserved[tok.text]:
+		p.next()
+		if !p.is("=>") {
+			p.pos = save
+			return nil, false, nil
+		}
+		params = []string{tok.text}
+	case tok.kind == jsPunct && tok.text == "(":
+		end := skipBalanced(p.src, tok.start)
+		if end < 0 {
+			p.pos = save
+			return nil, false, nil
+		}
+		p.pos = end
+		if p.is(":") { // TypeScript return type
+			p.next()
+			if err := p.skipType(); err != nil {
+				p.pos = save
+				return nil, false, nil
+			}
+		}
+		if !p.is("=>") {
+			p.pos = save
+			return nil, false, nil
+		}
+		params = splitParams(p.src[tok.start+1 : end-1])
+	default:
+		p.pos = save
+		return nil, false, nil
+	}
+	p.next() // =>
+
+	arrow := &JSArrow{Params: params, Async: async}
+	if err := p.parseFunctionBody(arrow); err != nil {
+		return nil, true, err
+	}
+	arrow.JSSpan = JSSpan{start, p.pos}
+	return arrow, true, nil
+}
+
+// parseFunctionBody reads a block or expression body
+func (p *jsParser) parseFunctionBody(fn *JSArrow) error {
+	if tok := p.peek(); tok.text == "{" && tok.kind == jsPunct {
+		end := skipBalanced(p.src, tok.start)
+		if end < 0 {
+			return p.errorAt(tok, "unclosed function body")
+		}
+		fn.Block = strings.TrimSpace(p.src[tok.start+1 : end-1])
+		p.pos = end
+		return nil
+	}
+	body, err := p.parseAssign()
+	if err != nil {
+		return err
+	}
+	fn.Body = body
+	return nil
+}
+
+// splitParams splits a parameter list at its top-level commas
+func splitParams(s string) []string {
+	params := splitTopLevel(s, ',')
+	for i := range params {
+		params[i] = strings.TrimSpace(params[i])
+	}
+	return params
+}
+
+func (p *jsParser) parseConditional() (JSExpr, error) {
+	start := p.peek().start
+	test, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("?") {
+		return test, nil
+	}
+	p.next()
+	then, err := p.parseAssign()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.parseAssign()
+	if err != nil {
+		return nil, err
+	}
+	return &JSConditional{JSSpan{start, p.pos}, test, then, els}, nil
+}
+
+// parseBinary parses operators of at least the given precedence
+func (p *jsParser) parseBinary(min int) (JSExpr, error) {
+	start := p.peek().start
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind == jsIdentTok && (tok.text == "as" || tok.text == "satisfies") {
+			// TypeScript assertion: keep the expression, drop the type
+			p.next()
+			if err := p.skipType(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		prec, ok := binaryPrecedence[tok.text]
+		if !ok || prec < min || (tok.kind == jsIdentTok) != (tok.text == "in" || tok.text == "instanceof") {
+			return left, nil
+		}
+		p.next()
+		next := prec + 1
+		if tok.text == "**" {
+			next = prec // right associative
+		}
+		right, err := p.parseBinary(next)
+		if err != nil {
+			return nil, err
+		}
+		left = &JSBinary{JSSpan{start, p.pos}, tok.text, left, right}
+	}
+}
+
+func (p *jsParser) parseUnary() (JSExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == jsPunct && (tok.text == "!" || tok.text == "-" || tok.text == "+" || tok.text == "~" || tok.text == "++" || tok.text == "--"),
+		tok.kind == jsIdentTok && (tok.text == "typeof" || tok.text == "void" || tok.text == "delete" || tok.text == "await"):
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &JSUnary{JSSpan{tok.start, p.pos}, tok.text, x, false}, nil
+	}
+
+	x, err := p.parseCallMember()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind == jsPunct && (next.text == "++" || next.text == "--") &&
+		!strings.Contains(p.src[p.pos:next.start], "\n") {
+		p.next()
+		return &JSUnary{JSSpan{tok.start, p.pos}, next.text, x, true}, nil
+	}
+	return x, nil
+}
+
+func (p *jsParser) parseCallMember() (JSExpr, error) {
+	start := p.peek().start
+	var x JSExpr
+	var err error
+	if p.is("new") {
+		x, err = p.parseNew()
+	} else {
+		x, err = p.parsePrimary()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == jsPunct && tok.text == ".":
+			p.next()
+			name := p.next()
+			if name.kind == jsPunct && name.text == "#" { // private field
+				name = p.next()
+			}
+			if name.kind != jsIdentTok {
+				return nil, p.errorAt(name, "expected property name")
+			}
+			x = &JSMember{JSSpan: JSSpan{start, p.pos}, Object: x, Property: name.text}
+		case tok.kind == jsPunct && tok.text == "?.":
+			p.next()
+			switch {
+			case p.is("("):
+				args, err := p.parseArgs()
+				if err != nil {
+					return nil, err
+				}
+				x = &JSCall{JSSpan: JSSpan{start, p.pos}, Callee: x, Args: args, Optional: true}
+			case p.is("["):
+				index, err := p.parseIndex()
+				if err != nil {
+					return nil, err
+				}
+				x = &JSMember{JSSpan: JSSpan{start, p.pos}, Object: x, Index: index, Optional: true}
+			default:
+				name := p.next()
+				if name.kind != jsIdentTok {
+					return nil, p.errorAt(name, "expected property name")
+				}
+				x = &JSMember{JSSpan: JSSpan{start, p.pos}, Object: x, Property: name.text, Optional: true}
+			}
+		case tok.kind == jsPunct && tok.text == "[":
+			index, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			x = &JSMember{JSSpan: JSSpan{start, p.pos}, Object: x, Index: index}
+		case tok.kind == jsPunct && tok.text == "(":
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			x = &JSCall{JSSpan: JSSpan{start, p.pos}, Callee: x, Args: args}
+		case tok.kind == jsTemplateTok:
+			p.next()
+			tmpl, err := p.parseTemplate(tok)
+			if err != nil {
+				return nil, err
+			}
+			tmpl.Tag = x
+			tmpl.JSSpan = JSSpan{start, p.pos}
+			x = tmpl
+		case tok.kind == jsPunct && tok.text == "!" && !strings.HasPrefix(p.src[tok.end:], "=") &&
+			!strings.ContainsAny(p.src[p.pos:tok.start], "\n"):
+			// TypeScript non-null assertion
+			p.next()
+		default:
+			return x, nil
+		}
+	}
+}
+
+// parseNew parses new Callee(args), where the callee has no calls
+func (p *jsParser) parseNew() (JSExpr, error) {
+	start := p.next().start // new
+	callee, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(".") {
+		p.next()
+		name := p.next()
+		if name.kind != jsIdentTok {
+			return nil, p.errorAt(name, "expected property name")
+		}
+		callee = &JSMember{JSSpan: JSSpan{start, p.pos}, Object: callee, Property: name.text}
+	}
+	call := &JSCall{Callee: callee, New: true}
+	if p.is("(") {
+		if call.Args, err = p.parseArgs(); err != nil {
+			return nil, err
+		}
+	}
+	call.JSSpan = JSSpan{start, p.pos}
+	return call, nil
+}
+
+func (p *jsParser) parseIndex() (JSExpr, error) {
+	p.next() // [
+	index, err := p.parseAssign()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// parseArgs parses a parenthesized argument list
+func (p *jsParser) parseArgs() ([]JSExpr, error) {
+	p.next() // (
+	args := []JSExpr{}
+	for !p.is(")") {
+		arg, err := p.parseElement()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// parseElement parses an argument or array element, which may be spread
+func (p *jsParser) parseElement() (JSExpr, error) {
+	if tok := p.peek(); tok.kind == jsPunct && tok.text == "..." {
+		p.next()
+		x, err := p.parseAssign()
+		if err != nil {
+			return nil, err
+		}
+		return &JSSpread{JSSpan{tok.start, p.pos}, x}, nil
+	}
+	return p.parseAssign()
+}
+
+func (p *jsParser) parsePrimary() (JSExpr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case jsEOF:
+		return nil, p.errorAt(tok, "expected expression")
+	case jsNumberTok:
+		p.next()
+		return &JSLiteral{JSSpan{tok.start, tok.end}, "number", tok.text}, nil
+	case jsStringTok:
+		p.next()
+		return &JSLiteral{JSSpan{tok.start, tok.end}, "string", unquoteJS(tok.text)}, nil
+	case jsTemplateTok:
+		p.next()
+		return p.parseTemplate(tok)
+	case jsIdentTok:
+		switch tok.text {
+		case "true", "false":
+			p.next()
+			return &JSLiteral{JSSpan{tok.start, tok.end}, "bool", tok.text}, nil
+		case "null", "undefined":
+			p.next()
+			return &JSLiteral{JSSpan{tok.start, tok.end}, tok.text, tok.text}, nil
+		case "function":
+			return p.parseFunction(tok.start, false)
+		case "async":
+			p.next()
+			if p.is("function") {
+				return p.parseFunction(tok.start, true)
+			}
+			return &JSIdent{JSSpan{tok.start, tok.end}, tok.text}, nil
+		case "class":
+			return nil, p.errorAt(tok, "class expressions are not supported")
+		}
+		p.next()
+		return &JSIdent{JSSpan{tok.start, tok.end}, tok.text}, nil
+	}
+
+	switch tok.text {
+	case "(":
+		p.next()
+		x, err := p.parseAssign()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &JSParen{JSSpan{tok.start, p.pos}, x}, nil
+	case "[":
+		p.next()
+		arr := &JSArray{Elements: []JSExpr{}}
+		for !p.is("]") {
+			if p.is(",") { // hole
+				p.next()
+				arr.Elements = append(arr.Elements, nil)
+				continue
+			}
+			el, err := p.parseElement()
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements = append(arr.Elements, el)
+			if !p.is(",") {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		arr.JSSpan = JSSpan{tok.start, p.pos}
+		return arr, nil
+	case "{":
+		return p.parseObject()
+	case "<":
+		end := skipJSX(p.src, tok.start)
+		if end < 0 {
+			return nil, p.errorAt(tok, "malformed JSX")
+		}
+		p.pos = end
+		return &JSXNode{JSSpan{tok.start, end}, p.src[tok.start:end]}, nil
+	case "/", "/=":
+		end := skipRegexp(p.src, tok.start)
+		if end < 0 {
+			return nil, p.errorAt(tok, "unterminated regular expression")
+		}
+		p.pos = end
+		return &JSLiteral{JSSpan{tok.start, end}, "regexp", p.src[tok.start:end]}, nil
+	}
+	return nil, p.errorAt(tok, "unexpected token")
+}
+
+// parseFunction parses a function expression
+func (p *jsParser) parseFunction(start int, async bool) (JSExpr, error) {
+	p.next() // function
+	if p.is("*") {
+		p.next()
+	}
+	if p.peek().kind == jsIdentTok {
+		p.next() // name
+	}
+	open := p.peek()
+	if open.text != "(" {
+		return nil, p.errorAt(open, "expected parameter list")
+	}
+	end := skipBalanced(p.src, open.start)
+	if end < 0 {
+		return nil, p.errorAt(open, "unclosed parameter list")
+	}
+	fn := &JSArrow{Params: splitParams(p.src[open.start+1 : end-1]), Async: async}
+	p.pos = end
+	if p.is(":") {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.is("{") {
+		return nil, p.errorAt(p.peek(), "expected function body")
+	}
+	if err := p.parseFunctionBody(fn); err != nil {
+		return nil, err
+	}
+	fn.JSSpan = JSSpan{start, p.pos}
+	return fn, nil
+}
+
+func (p *jsParser) parseObject() (JSExpr, error) {
+	start := p.next().start // {
+	obj := &JSObject{Props: []JSProperty{}}
+	for !p.is("}") {
+		var prop JSProperty
+		tok := p.peek()
+		switch {
+		case tok.kind == jsPunct && tok.text == "...":
+			p.next()
+			x, err := p.parseAssign()
+			if err != nil {
+				return nil, err
+			}
+			prop = JSProperty{Value: x, Spread: true}
+		case tok.kind == jsPunct && tok.text == "[":
+			key, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			prop.Computed = key
+		case tok.kind == jsIdentTok || tok.kind == jsNumberTok:
+			p.next()
+			prop.Key = tok.text
+		case tok.kind == jsStringTok:
+			p.next()
+			prop.Key = unquoteJS(tok.text)
+		default:
+			return nil, p.errorAt(tok, "expected property")
+		}
+
+		if !prop.Spread {
+			switch {
+			case p.is(":"):
+				p.next()
+				value, err := p.parseAssign()
+				if err != nil {
+					return nil, err
+				}
+				prop.Value = value
+			case p.is("("):
+				// Method shorthand: name(args) { ... }
+				open := p.peek()
+				end := skipBalanced(p.src, open.start)
+				if end < 0 {
+					return nil, p.errorAt(open, "unclosed parameter list")
+				}
+				fn := &JSArrow{Params: splitParams(p.src[open.start+1 : end-1])}
+				p.pos = end
+				if err := p.parseFunctionBody(fn); err != nil {
+					return nil, err
+				}
+				fn.JSSpan = JSSpan{open.start, p.pos}
+				prop.Value = fn
+			case tok.kind == jsIdentTok:
+				prop.Value = &JSIdent{JSSpan{tok.start, tok.end}, tok.text}
+				prop.Shorthand = true
+				if p.is("=") { // default in a destructuring pattern
+					p.next()
+					if _, err := p.parseAssign(); err != nil {
+						return nil, err
+					}
+				}
+			default:
+				return nil, p.errorAt(p.peek(), "expected \":\"")
+			}
+		}
+		obj.Props = append(obj.Props, prop)
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect("}"); err != nil {
+		return nil, err
+	}
+	obj.JSSpan = JSSpan{start, p.pos}
+	return obj, nil
+}
+
+// parseTemplate splits a template literal token into its text and
+// embedded expressions
+func (p *jsParser) parseTemplate(tok jsToken) (*JSTemplate, error) {
+	tmpl := &JSTemplate{JSSpan: JSSpan{tok.start, tok.end}}
+	src := p.src
+	var quasi strings.Builder
+	for i := tok.start + 1; i < tok.end-1; i++ {
+		switch {
+		case src[i] == '\\' && i+1 < tok.end-1:
+			quasi.WriteString(src[i : i+2])
+			i++
+		case src[i] == '$' && src[i+1] == '{':
+			end := skipBalanced(src, i+1)
+			inner := &jsParser{src: src[:end-1], pos: i + 2}
+			x, err := inner.parseAssign()
+			if err != nil {
+				return nil, err
+			}
+			if rest := inner.peek(); rest.kind != jsEOF {
+				return nil, inner.errorAt(rest, "expected \"}\"")
+			}
+			tmpl.Quasis = append(tmpl.Quasis, quasi.String())
+			tmpl.Exprs = append(tmpl.Exprs, x)
+			quasi.Reset()
+			i = end - 1
+		default:
+			quasi.WriteByte(src[i])
+		}
+	}
+	tmpl.Quasis = append(tmpl.Quasis, quasi.String())
+	return tmpl, nil
+}
+
+// skipType moves past a TypeScript type in an assertion or annotation:
+// names, dotted names, generic arguments, array suffixes and unions
+func (p *jsParser) skipType() error {
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == jsIdentTok, tok.kind == jsStringTok, tok.kind == jsNumberTok:
+		case tok.kind == jsPunct && (tok.text == "(" || tok.text == "{" || tok.text == "["):
+			end := skipBalanced(p.src, tok.start)
+			if end < 0 {
+				return p.errorAt(tok, "unclosed type")
+			}
+			p.pos = end
+		default:
+			return p.errorAt(tok, "expected type")
+		}
+		for {
+			switch {
+			case p.is("."):
+				p.next()
+				if p.peek().kind != jsIdentTok {
+					return p.errorAt(p.peek(), "expected type name")
+				}
+				p.next()
+				continue
+			case p.is("<"):
+				depth := 0
+				for {
+					t := p.next()
+					if t.kind == jsEOF {
+						return p.errorAt(t, "unclosed type arguments")
+					}
+					if t.text == "<" {
+						depth++
+					} else if t.text == ">" {
+						depth--
+					} else if t.text == ">>" {
+						depth -= 2
+					}
+					if depth <= 0 {
+						break
+					}
+				}
+				continue
+			case p.is("["):
+				tok := p.peek()
+				if end := skipBalanced(p.src, tok.start); end == tok.end+1 {
+					p.pos = end // T[]
+					continue
+				}
+			}
+			break
+		}
+		if p.is("|") || p.is("&") {
+			p.next()
+			continue
+		}
+		return nil
+	}
+}
+
+// skipRegexp returns the offset after the regular expression literal
+// starting with the '/' at i, flags included, or -1
+func skipRegexp(src string, i int) int {
+	inClass := false
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return -1
+		case '/':
+			if inClass {
+				continue
+			}
+			j++
+			for j < len(src) && isIdentStart(src[j]) {
+				j++
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+// unquoteJS returns the value of a quoted JS string literal
+func unquoteJS(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	body := s[1 : len(s)-1]
+	if !strings.Contains(body, `\`) {
+		return body
+	}
+	var out strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' || i+1 == len(body) {
+			out.WriteByte(body[i])
+			continue
+		}
+		i++
+		switch body[i] {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case 'u':
+			if i+4 < len(body) {
+				if r, err := strconv.ParseUint(body[i+1:i+5], 16, 32); err == nil {
+					out.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			out.WriteByte('u')
+		default:
+			out.WriteByte(body[i])
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// effectCall matches a useEffect call
+	effectCall = regexp.MustCompile(`\b(?:React\.)?useEffect\s*\(`)
+	// requestCall matches a request made with fetch or axios
+	requestCall = regexp.MustCompile(`\b(fetch|axios\.get|axios)\s*\(`)
+	// setterCall matches a state setter call
+	setterCall = regexp.MustCompile(`\b(set[A-Z]\w*)\s*\(`)
+	// setterThen matches a setter passed straight to a promise: .then(setUsers)
+	setterThen = regexp.MustCompile(`\.then\(\s*(set[A-Z]\w*)\s*\)`)
+	// catchClause matches the start of error handling
+	catchClause = regexp.MustCompile(`\.catch\s*\(|\bcatch\s*[({]`)
+	// realtimeEffect matches effects that push or poll; those are served
+	// over Server-Sent Events instead
+	realtimeEffect = regexp.MustCompile(`new\s+EventSource\(|new\s+WebSocket\(|setInterval\(`)
+)
+
+// componentFetches finds the effects on the 1-based source lines
+// [start, end) that fetch data into one of stateVars: the request, the state the response is stored in, and the
+// loading and error flags the effect maintains
+func componentFetches(lines []string, start, end int, stateVars []StateVariable) []DataFetch {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	setters := make(map[string]string, len(stateVars))
+	for _, sv := range stateVars {
+		setters[sv.Setter] = sv.Name
+	}
+
+	var fetches []DataFetch
+	for _, loc := range effectCall.FindAllStringIndex(body, -1) {
+		src := body[loc[0]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		call, ok := e.(*JSCall)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		fn, ok := call.Args[0].(*JSArrow)
+		if !ok {
+			continue
+		}
+		effect := fn.Block
+		if fn.Body != nil {
+			effect = fn.Body.Span().Text(src)
+		}
+		if realtimeEffect.MatchString(effect) {
+			continue
+		}
+
+		fetch, ok := effectFetch(effect, setters)
+		if !ok {
+			continue
+		}
+		if len(call.Args) > 1 {
+			if deps, ok := call.Args[1].(*JSArray); ok {
+				for _, dep := range deps.Elements {
+					if dep != nil {
+						fetch.Deps = append(fetch.Deps, strings.TrimSpace(dep.Span().Text(src)))
+					}
+				}
+			}
+		}
+		fetch.LineNumber = start + strings.Count(body[:loc[0]], "\n")
+		fetches = append(fetches, fetch)
+	}
+	return fetches
+}
+
+// suggestFetches replaces the generic useEffect hint for effects that
+// fetch data, which are loaded before render instead
+func (p *Parser) suggestFetches(comps []Component) {
+	for i := range p.suggestions {
+		s := &p.suggestions[i]
+		if s.PatternType != "useEffect" {
+			continue
+		}
+		for _, comp := range comps {
+			for _, f := range comp.Fetches {
+				if f.LineNumber == s.Line {
+					s.ReactCode = fmt.Sprintf("useEffect fetching %s into %s", f.URL, f.State)
+					s.MintyHint = "Loaded on the server before render; fill in the generated load function"
+				}
+			}
+		}
+	}
+}
+
+// effectFetch reads the request an effect body makes and the setters it
+// calls with the result. Setters called with a boolean are loading flags,
+// non-literal values set in a catch are errors, and the first other value
+// is the data; literal resets are ignored.
+func effectFetch(effect string, setters map[string]string) (DataFetch, bool) {
+	m := requestCall.FindStringSubmatchIndex(effect)
+	if m == nil {
+		return DataFetch{}, false
+	}
+	req, _, err := ParseJSExprPrefix(effect[m[0]:])
+	if err != nil {
+		return DataFetch{}, false
+	}
+	call := requestOf(req)
+	if call == nil || len(call.Args) == 0 {
+		return DataFetch{}, false
+	}
+	fetch := DataFetch{
+		URL:    strings.TrimSpace(call.Args[0].Span().Text(effect[m[0]:])),
+		Client: effect[m[2]:m[3]],
+	}
+
+	if t := setterThen.FindStringSubmatch(effect); t != nil {
+		fetch.State = setters[t[1]]
+	}
+	for _, loc := range setterCall.FindAllStringSubmatchIndex(effect, -1) {
+		state, ok := setters[effect[loc[2]:loc[3]]]
+		if !ok {
+			continue
+		}
+		e, _, err := ParseJSExprPrefix(effect[loc[0]:])
+		if err != nil {
+			continue
+		}
+		set, ok := e.(*JSCall)
+		if !ok || len(set.Args) == 0 {
+			continue
+		}
+		arg := Unparen(set.Args[0])
+		if lit, ok := arg.(*JSLiteral); ok {
+			if lit.Kind == "bool" && fetch.Loading == "" {
+				fetch.Loading = state
+			}
+			continue
+		}
+		switch {
+		case catchClause.MatchString(effect[:loc[0]]):
+			if fetch.Error == "" {
+				fetch.Error = state
+			}
+		case fetch.State == "":
+			fetch.State = state
+			fetch.Value = storedValue(arg, effect[loc[0]:], fetch.Client)
+		}
+	}
+	return fetch, fetch.State != ""
+}
+
+// requestOf returns the request call at the root of a promise chain:
+// fetch(url) in fetch(url).then(...).catch(...)
+func requestOf(e JSExpr) *JSCall {
+	for {
+		switch n := e.(type) {
+		case *JSCall:
+			switch callee := n.Callee.(type) {
+			case *JSIdent:
+				if callee.Name == "fetch" || callee.Name == "axios" {
+					return n
+				}
+			case *JSMember:
+				if MemberPath(callee) == "axios.get" {
+					return n
+				}
+			}
+			e = n.Callee
+		case *JSMember:
+			e = n.Object
+		default:
+			return nil
+		}
+	}
+}
+
+// storedValue returns the expression stored from the response, or "" if
+// the effect stores the decoded body itself (data, or res.data for axios)
+func storedValue(arg JSExpr, src, client string) string {
+	if _, ok := arg.(*JSIdent); ok {
+		return ""
+	}
+	path := MemberPath(arg)
+	if strings.HasPrefix(client, "axios") && strings.Count(path, ".") == 1 && strings.HasSuffix(path, ".data") {
+		return ""
+	}
+	return strings.TrimSpace(arg.Span().Text(src))
+}
@@ -0,0 +1,479 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// useFormDecl matches const { register, handleSubmit } = useForm(...) and
+	// const { register, formState: { errors } } = useForm(...)
+	useFormDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\{(?:[^{}]|\{[^{}]*\})*\}|\w+)\s*=\s*useForm\s*(?:<[^>]*>)?\s*\(`)
+	// useFormikDecl matches const formik = useFormik({ ... })
+	useFormikDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*useFormik\s*(?:<[^>]*>)?\s*\(`)
+	// formikElement matches the <Formik> wrapper
+	formikElement = regexp.MustCompile(`<Formik\b`)
+	// formikProp matches a <Formik> prop holding an expression
+	formikProp = regexp.MustCompile(`\b(initialValues|validationSchema|onSubmit)\s*=\s*\{`)
+	// registerUse matches a react-hook-form field registration
+	registerUse = regexp.MustCompile(`\bregister\(\s*['"]`)
+	// formikFieldUse matches the fields Formik markup names:
+	// <Field name="email" />, formik.getFieldProps('email')
+	formikFieldUse = regexp.MustCompile(`<Field\b[^>]*?\bname=["']([^"']+)["']|\.getFieldProps\(\s*['"]([^'"]+)['"]`)
+	// errorMessageUse matches <ErrorMessage name="email" />
+	errorMessageUse = regexp.MustCompile(`<ErrorMessage\b[^>]*?\bname=["']([^"']+)["']`)
+	// formErrorUse matches a react-hook-form field error the markup reads:
+	// errors.email, formState.errors.email
+	formErrorUse = regexp.MustCompile(`\berrors\.(\w+)`)
+	// submitWrap matches handleSubmit(onSubmit)
+	submitWrap = regexp.MustCompile(`\bhandleSubmit\(\s*([\w.]+)`)
+	// resolverCall matches the schema of a react-hook-form resolver
+	resolverCall = regexp.MustCompile(`\b(?:zod|yup)Resolver\(\s*(\w+)`)
+)
+
+// componentForm returns the react-hook-form or Formik form of the
+// component on the 1-based source lines [start, end), if it has one
+func componentForm(lines []string, start, end int) *FormSpec {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	line := func(offset int) int { return start + strings.Count(body[:offset], "\n") }
+
+	if m := useFormDecl.FindStringIndex(body); m != nil {
+		form := &FormSpec{Library: "react-hook-form", LineNumber: line(m[0])}
+		if config := callConfig(body, m[1]-1); config != nil {
+			form.readConfig(config.obj, config.src, "defaultValues", "")
+		}
+		if r := resolverCall.FindStringSubmatch(body); r != nil {
+			form.Schema = r[1]
+		}
+		if s := submitWrap.FindStringSubmatch(body); s != nil {
+			form.Submit = s[1]
+		}
+		for _, loc := range registerUse.FindAllStringIndex(body, -1) {
+			src := body[loc[0]:]
+			e, _, err := ParseJSExprPrefix(src)
+			if err != nil {
+				continue
+			}
+			call, ok := e.(*JSCall)
+			if !ok || len(call.Args) == 0 {
+				continue
+			}
+			name, ok := Unparen(call.Args[0]).(*JSLiteral)
+			if !ok || name.Kind != "string" {
+				continue
+			}
+			field := form.field(name.Value)
+			if len(call.Args) > 1 {
+				if opts, ok := Unparen(call.Args[1]).(*JSObject); ok {
+					registerRules(field, opts, src)
+				}
+			}
+		}
+		for _, m := range formErrorUse.FindAllStringSubmatch(body, -1) {
+			if !slices.Contains(form.Messages, m[1]) {
+				form.Messages = append(form.Messages, m[1])
+			}
+		}
+		return form
+	}
+
+	var form *FormSpec
+	if m := useFormikDecl.FindStringIndex(body); m != nil {
+		form = &FormSpec{Library: "formik", LineNumber: line(m[0])}
+		if config := callConfig(body, m[1]-1); config != nil {
+			form.readConfig(config.obj, config.src, "initialValues", "validationSchema")
+			for _, prop := range config.obj.Props {
+				if prop.Key == "onSubmit" && prop.Value != nil {
+					form.Submit = submitSource(prop.Value.Span().Text(config.src))
+				}
+			}
+		}
+	} else if m := formikElement.FindStringIndex(body); m != nil {
+		form = &FormSpec{Library: "formik", LineNumber: line(m[0])}
+		tag := body[m[0]:]
+		seen := map[string]bool{}
+		for _, p := range formikProp.FindAllStringSubmatchIndex(tag, -1) {
+			prop := tag[p[2]:p[3]]
+			src := tag[p[1]:]
+			e, _, err := ParseJSExprPrefix(src)
+			if seen[prop] || err != nil {
+				continue
+			}
+			seen[prop] = true
+			switch prop {
+			case "initialValues":
+				if obj, ok := Unparen(e).(*JSObject); ok {
+					form.readConfig(&JSObject{Props: []JSProperty{{Key: "initialValues", Value: obj}}}, src, "initialValues", "")
+				}
+			case "validationSchema":
+				if id, ok := Unparen(e).(*JSIdent); ok {
+					form.Schema = id.Name
+				}
+			case "onSubmit":
+				form.Submit = submitSource(e.Span().Text(src))
+			}
+		}
+	} else {
+		return nil
+	}
+	for _, m := range formikFieldUse.FindAllStringSubmatch(body, -1) {
+		form.field(m[1] + m[2])
+	}
+	for _, m := range errorMessageUse.FindAllStringSubmatch(body, -1) {
+		form.Messages = append(form.Messages, m[1])
+	}
+	return form
+}
+
+// antdForm returns the Ant Design form rendered by the component body,
+// if it has one: a <Form> whose <Form.Item name rules> wrap its controls
+func antdForm(body Node) *FormSpec {
+	var form *FormSpec
+	var items []*Element
+	walkJSX(body, func(el *Element) {
+		switch el.Tag {
+		case "Form":
+			if form == nil {
+				form = &FormSpec{Library: "antd", LineNumber: el.LineNumber}
+				form.readFormProps(el)
+			}
+		case "Form.Item":
+			items = append(items, el)
+		}
+	})
+	if len(items) == 0 {
+		return nil
+	}
+	if form == nil {
+		form = &FormSpec{Library: "antd", LineNumber: items[0].LineNumber}
+	}
+	for _, item := range items {
+		var name, rules string
+		for _, attr := range item.Attributes {
+			switch attr.Name {
+			case "name":
+				if attr.Value != "" {
+					name = attr.Value
+				} else if e, err := ParseJSExpr(attr.Expression.Raw); err == nil {
+					name = dataIndex(Unparen(e))
+				}
+			case "rules":
+				rules = attr.Expression.Raw
+			}
+		}
+		if name == "" {
+			continue
+		}
+		field := form.field(name)
+		switch itemControl(item) {
+		case "InputNumber":
+			field.Type = "number"
+		case "Checkbox", "Switch":
+			field.Type = "boolean"
+		}
+		if e, err := ParseJSExpr(rules); err == nil {
+			if arr, ok := Unparen(e).(*JSArray); ok {
+				antdRules(field, arr, rules)
+			}
+		}
+	}
+	return form
+}
+
+// readFormProps reads the submit function and initial values of <Form>
+func (f *FormSpec) readFormProps(el *Element) {
+	for _, attr := range el.Attributes {
+		raw := strings.TrimSpace(attr.Expression.Raw)
+		switch attr.Name {
+		case "onFinish":
+			f.Submit = submitSource(raw)
+		case "initialValues":
+			if e, err := ParseJSExpr(raw); err == nil {
+				if obj, ok := Unparen(e).(*JSObject); ok {
+					f.readConfig(&JSObject{Props: []JSProperty{{Key: "initialValues", Value: obj}}}, raw, "initialValues", "")
+				}
+			}
+		}
+	}
+}
+
+// itemControl returns the tag of the control a Form.Item wraps
+func itemControl(item *Element) string {
+	for _, child := range item.Children {
+		if el, ok := child.(*Element); ok {
+			return el.Tag
+		}
+	}
+	return ""
+}
+
+// antdRules translates the rules of a Form.Item:
+// rules={[{ required: true, message: 'Required' }, { type: 'email' }, { min: 8 }]}
+func antdRules(field *SchemaField, rules *JSArray, src string) {
+	field.Source = "rules={" + submitSource(rules.Span().Text(src)) + "}"
+	for _, el := range rules.Elements {
+		rule, ok := Unparen(el).(*JSObject)
+		if !ok {
+			field.Unsupported = append(field.Unsupported, "rule")
+			continue
+		}
+		message := ""
+		if value, ok := propValue(rule, "message"); ok {
+			if lit, ok := Unparen(value).(*JSLiteral); ok && lit.Kind == "string" {
+				message = lit.Value
+			}
+		}
+		for _, prop := range rule.Props {
+			if prop.Key == "message" || prop.Value == nil {
+				continue
+			}
+			value := Unparen(prop.Value)
+			lit, _ := value.(*JSLiteral)
+			switch {
+			case prop.Key == "required" && lit != nil && lit.Kind == "bool":
+				if lit.Value == "false" {
+					continue
+				}
+				field.Required = true
+				if message != "" {
+					field.Rules = append(field.Rules, SchemaRule{Kind: "required", Message: message})
+				}
+			case prop.Key == "type" && lit != nil && lit.Kind == "string":
+				switch lit.Value {
+				case "email", "url":
+					field.Rules = append(field.Rules, SchemaRule{Kind: lit.Value, Message: message})
+				case "number", "integer", "float":
+					field.Type = "number"
+				case "boolean":
+					field.Type = "boolean"
+				case "string":
+				default:
+					field.Unsupported = append(field.Unsupported, "type: "+lit.Value)
+				}
+			case (prop.Key == "min" || prop.Key == "max" || prop.Key == "len") && lit != nil && lit.Kind == "number":
+				kind := prop.Key
+				if kind == "len" {
+					kind = "length"
+				}
+				field.Rules = append(field.Rules, SchemaRule{Kind: kind, Arg: lit.Value, Message: message})
+			case prop.Key == "pattern":
+				re, ok := goRegexp(strings.TrimSpace(value.Span().Text(src)))
+				if !ok {
+					field.Unsupported = append(field.Unsupported, "pattern")
+					continue
+				}
+				field.Rules = append(field.Rules, SchemaRule{Kind: "regex", Arg: re, Message: message})
+			case prop.Key == "enum":
+				arr, ok := value.(*JSArray)
+				if !ok {
+					field.Unsupported = append(field.Unsupported, "enum")
+					continue
+				}
+				r := SchemaRule{Kind: "enum", Message: message}
+				for _, v := range arr.Elements {
+					if lit, ok := Unparen(v).(*JSLiteral); ok {
+						r.Values = append(r.Values, lit.Value)
+					}
+				}
+				field.Rules = append(field.Rules, r)
+			case prop.Key == "whitespace" || prop.Key == "validateTrigger":
+			default:
+				field.Unsupported = append(field.Unsupported, prop.Key)
+			}
+		}
+	}
+}
+
+// walkJSX calls fn for every element of the markup node
+func walkJSX(node Node, fn func(*Element)) {
+	switch n := node.(type) {
+	case *Element:
+		fn(n)
+		for _, child := range n.Children {
+			walkJSX(child, fn)
+		}
+	case *Fragment:
+		for _, child := range n.Children {
+			walkJSX(child, fn)
+		}
+	case *MapExpr:
+		walkJSX(n.Body, fn)
+	case *Conditional:
+		walkJSX(n.Consequent, fn)
+	case *Ternary:
+		walkJSX(n.Consequent, fn)
+		walkJSX(n.Alternate, fn)
+	}
+}
+
+// formConfig is the object literal passed to useForm or useFormik
+type formConfig struct {
+	obj *JSObject
+	src string
+}
+
+// callConfig returns the object literal argument of the call whose
+// opening parenthesis is at open
+func callConfig(body string, open int) *formConfig {
+	src := body[open:]
+	e, _, err := ParseJSExprPrefix(src)
+	if err != nil {
+		return nil
+	}
+	obj, ok := Unparen(e).(*JSObject)
+	if !ok {
+		return nil
+	}
+	return &formConfig{obj: obj, src: src}
+}
+
+// readConfig reads the initial values of a form config, typing its fields
+// by them, and the name of its validation schema
+func (f *FormSpec) readConfig(config *JSObject, src, values, schema string) {
+	for _, prop := range config.Props {
+		switch {
+		case prop.Key == values:
+			obj, ok := Unparen(prop.Value).(*JSObject)
+			if !ok {
+				continue
+			}
+			for _, v := range obj.Props {
+				if v.Key == "" || v.Spread || v.Value == nil {
+					continue
+				}
+				field := f.field(v.Key)
+				value := strings.TrimSpace(v.Value.Span().Text(src))
+				if field.Source == "" {
+					field.Source = values + "." + v.Key + ": " + value
+				}
+				switch inferTypeFromValue(value) {
+				case "int", "float64":
+					field.Type = "number"
+				case "bool":
+					field.Type = "boolean"
+				}
+			}
+		case schema != "" && prop.Key == schema:
+			if id, ok := Unparen(prop.Value).(*JSIdent); ok {
+				f.Schema = id.Name
+			}
+		}
+	}
+}
+
+// field returns the form field name, adding it as a string field the
+// first time
+func (f *FormSpec) field(name string) *SchemaField {
+	for i := range f.Fields {
+		if f.Fields[i].Name == name {
+			return &f.Fields[i]
+		}
+	}
+	f.Fields = append(f.Fields, SchemaField{Name: name, Type: "string"})
+	return &f.Fields[len(f.Fields)-1]
+}
+
+// registerRules translates the validation options of a register call:
+// register('age', { required: 'Age is required', min: 18, valueAsNumber: true })
+func registerRules(field *SchemaField, opts *JSObject, src string) {
+	var source []string
+	for _, prop := range opts.Props {
+		if prop.Key == "" || prop.Value == nil {
+			continue
+		}
+		source = append(source, prop.Key+": "+strings.TrimSpace(prop.Value.Span().Text(src)))
+
+		// A rule is a value, or { value, message }
+		value, message := Unparen(prop.Value), ""
+		if obj, ok := value.(*JSObject); ok {
+			value = nil
+			for _, p := range obj.Props {
+				switch p.Key {
+				case "value":
+					value = Unparen(p.Value)
+				case "message":
+					if lit, ok := Unparen(p.Value).(*JSLiteral); ok && lit.Kind == "string" {
+						message = lit.Value
+					}
+				}
+			}
+		}
+		lit, _ := value.(*JSLiteral)
+		if lit == nil {
+			field.Unsupported = append(field.Unsupported, prop.Key)
+			continue
+		}
+
+		switch prop.Key {
+		case "required":
+			if lit.Kind == "string" {
+				message = lit.Value
+			}
+			if lit.Kind == "bool" && lit.Value == "false" {
+				continue
+			}
+			field.Required = true
+			if message != "" {
+				field.Rules = append(field.Rules, SchemaRule{Kind: "required", Message: message})
+			}
+		case "minLength", "maxLength", "min", "max":
+			if lit.Kind != "number" {
+				field.Unsupported = append(field.Unsupported, prop.Key)
+				continue
+			}
+			kind := strings.TrimSuffix(prop.Key, "Length")
+			if prop.Key == "min" || prop.Key == "max" {
+				field.Type = "number"
+			}
+			field.Rules = append(field.Rules, SchemaRule{Kind: kind, Arg: lit.Value, Message: message})
+		case "pattern":
+			re, ok := goRegexp(strings.TrimSpace(value.Span().Text(src)))
+			if !ok {
+				field.Unsupported = append(field.Unsupported, "pattern")
+				continue
+			}
+			field.Rules = append(field.Rules, SchemaRule{Kind: "regex", Arg: re, Message: message})
+		case "valueAsNumber":
+			if lit.Value == "true" {
+				field.Type = "number"
+			}
+		default:
+			field.Unsupported = append(field.Unsupported, prop.Key)
+		}
+	}
+	field.Source = "register('" + field.Name + "', { " + strings.Join(source, ", ") + " })"
+}
+
+// submitSource returns the function a Formik onSubmit prop names, or the
+// inline function's source on one line
+func submitSource(src string) string {
+	return strings.Join(strings.Fields(src), " ")
+}
+
+// suggestForms describes where the forms of the components end up
+func (p *Parser) suggestForms(comps []Component) {
+	for _, comp := range comps {
+		if comp.Form == nil {
+			continue
+		}
+		names := make([]string, len(comp.Form.Fields))
+		for i, f := range comp.Form.Fields {
+			names[i] = f.Name
+		}
+		hint := fmt.Sprintf("%s form: fields %s post to POST /%s/submit, decoded into %sForm and validated on the server",
+			comp.Form.Library, strings.Join(names, ", "), kebabIdent(comp.Name), exportIdent(comp.Name))
+		if comp.Form.Schema != "" {
+			hint += " with " + comp.Form.Schema
+		}
+		react := "useForm()"
+		switch comp.Form.Library {
+		case "formik":
+			react = "Formik"
+		case "antd":
+			react = "<Form onFinish>"
+		}
+		p.addSuggestion(comp.Form.LineNumber, react, hint, "form")
+	}
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"strings"
+)
+
+// parseHelper parses a plain function of the file, just past its name:
+// function formatPrice(n) {...}, or const slugify = s => ... Declarations
+// that are not functions are skipped.
+func (p *Parser) parseHelper(name string, line int, isArrow, async bool) {
+	fn, src, stop, ok := p.functionAt(isArrow, async)
+	if !ok {
+		p.skipToNextStatement()
+		return
+	}
+
+	helper := Helper{
+		Name:       name,
+		Async:      fn.Async || async,
+		LineNumber: line,
+		EndLine:    1 + strings.Count(p.source[:stop], "\n"),
+	}
+	for _, param := range fn.Params {
+		helper.Params = append(helper.Params, helperParam(param))
+	}
+	if fn.Body != nil {
+		helper.Body = strings.TrimSpace(fn.Body.Span().Text(src))
+	} else {
+		helper.Body, helper.Block = fn.Block, true
+	}
+	lines := strings.Split(p.source, "\n")
+	if line >= 1 && helper.EndLine <= len(lines) {
+		helper.Source = strings.Join(lines[line-1:helper.EndLine], "\n")
+	}
+	p.helpers = append(p.helpers, helper)
+}
+
+// helperParam splits a parameter of a helper into its name, its
+// TypeScript type and its default value: "n: number = 2"
+func helperParam(param string) Prop {
+	param = strings.TrimSpace(param)
+	prop := Prop{Name: param}
+	if i := strings.Index(param, "="); i >= 0 && !strings.HasPrefix(param[i:], "=>") {
+		prop.Name, prop.DefaultValue = strings.TrimSpace(param[:i]), strings.TrimSpace(param[i+1:])
+	}
+	if i := strings.Index(prop.Name, ":"); i >= 0 && plainIdent.MatchString(strings.TrimSpace(strings.TrimSuffix(prop.Name[:i], "?"))) {
+		prop.JSType = jsdocKind(prop.Name[i+1:])
+		prop.Name = strings.TrimSpace(strings.TrimSuffix(prop.Name[:i], "?"))
+	}
+	if prop.JSType == "" && prop.DefaultValue != "" {
+		switch inferInitType(prop.DefaultValue) {
+		case "string":
+			prop.JSType = "string"
+		case "int", "float64":
+			prop.JSType = "number"
+		case "bool":
+			prop.JSType = "bool"
+		}
+	}
+	return prop
+}
+
+// withoutActions leaves out the helpers that are server actions, which are
+// generated as such
+func withoutActions(helpers []Helper, actions []ServerAction) []Helper {
+	if len(actions) == 0 {
+		return helpers
+	}
+	isAction := map[string]bool{}
+	for _, a := range actions {
+		isAction[a.Name] = true
+	}
+	var kept []Helper
+	for _, h := range helpers {
+		if !isAction[h.Name] {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// hookCallDecl matches a component binding the result of a hook call:
+	// const [open, toggle] = useToggle(false)
+	hookCallDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\[[^\]]*\]|\{[^}]*\}|\w+)\s*=\s*(use[A-Z]\w*)\s*\(`)
+	// localFunc matches a function declared in a hook body
+	localFunc = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s*)?(?:\([^)]*\)|\w+)\s*=>|\b(?:const|let|var)\s+(\w+)\s*=\s*(?:React\.)?useCallback\b|\bfunction\s+(\w+)`)
+)
+
+// isCustomHook reports whether name is a hook by React's naming rule
+func isCustomHook(name string) bool {
+	return len(name) > 3 && strings.HasPrefix(name, "use") && name[3] >= 'A' && name[3] <= 'Z'
+}
+
+// parseCustomHook parses the function of a custom hook declared as
+// function useName(...) {...} or const useName = (...) => {...}; the
+// position is just past its name. The state, effects and fetches of the
+// hook are assigned by line range once the file is parsed.
+func (p *Parser) parseCustomHook(name string, line int, isArrow bool) {
+	fn, src, stop, ok := p.functionAt(isArrow, false)
+	if !ok {
+		p.skipToNextStatement()
+		return
+	}
+
+	hook := CustomHook{
+		Name:       name,
+		LineNumber: line,
+		EndLine:    1 + strings.Count(p.source[:stop], "\n"),
+		body:       fn.Block,
+	}
+	for _, param := range fn.Params {
+		prop := Prop{Name: strings.TrimSpace(param)}
+		if i := strings.Index(param, "="); i >= 0 {
+			prop = Prop{Name: strings.TrimSpace(param[:i]), DefaultValue: strings.TrimSpace(param[i+1:])}
+		}
+		if plainIdent.MatchString(prop.Name) {
+			hook.Params = append(hook.Params, prop)
+		}
+	}
+
+	// The returned values: an expression body, or the last return
+	var ret JSExpr
+	retSrc := ""
+	if fn.Body != nil {
+		ret, retSrc = fn.Body, src
+	} else if i := lastReturn(fn.Block); i >= 0 {
+		retSrc = fn.Block[i+len("return"):]
+		ret, _, _ = ParseJSExprPrefix(retSrc)
+	}
+	value := func(e JSExpr) HookValue {
+		v := HookValue{Value: strings.TrimSpace(e.Span().Text(retSrc))}
+		if id, ok := e.(*JSIdent); ok {
+			v.Name = id.Name
+		}
+		_, v.Func = Unparen(e).(*JSArrow)
+		return v
+	}
+	switch r := Unparen(ret).(type) {
+	case nil:
+	case *JSArray:
+		hook.ReturnKind = "array"
+		for _, el := range r.Elements {
+			if el == nil {
+				hook.Returns = append(hook.Returns, HookValue{})
+				continue
+			}
+			hook.Returns = append(hook.Returns, value(el))
+		}
+	case *JSObject:
+		hook.ReturnKind = "object"
+		for _, prop := range r.Props {
+			if prop.Key == "" || prop.Spread || prop.Value == nil {
+				continue
+			}
+			v := value(prop.Value)
+			v.Name = prop.Key
+			if prop.Shorthand {
+				v.Value = prop.Key
+			}
+			hook.Returns = append(hook.Returns, v)
+		}
+	default:
+		hook.ReturnKind = "value"
+		hook.Returns = append(hook.Returns, value(r))
+	}
+
+	p.hooks = append(p.hooks, hook)
+	p.addSuggestion(line, name, "Custom hook: lifted into a Go helper function; the client-side parts are left as TODOs", "customHook")
+}
+
+// functionAt parses the function declared at the position, just past its
+// name: the rest of function name(...) {...}, or = (...) => ... for an
+// arrow function. It returns the function, the source it was parsed from
+// and the source offset where it ends, leaving the position there.
+func (p *Parser) functionAt(isArrow, async bool) (fn *JSArrow, src string, stop int, ok bool) {
+	if p.source == "" || p.pos == 0 {
+		return nil, "", 0, false
+	}
+	off := p.tokens[p.pos-1].Offset
+	if off > len(p.source) {
+		return nil, "", 0, false
+	}
+	src = p.source[off:]
+	base := off
+	if isArrow {
+		i := skipJSSpace(src, 0)
+		if i >= len(src) || src[i] != '=' {
+			return nil, "", 0, false
+		}
+		src = src[i+1:]
+		base += i + 1
+	} else {
+		keyword := "function"
+		if async {
+			keyword = "async function"
+		}
+		src = keyword + src
+		base -= len(keyword)
+	}
+
+	e, end, err := ParseJSExprPrefix(src)
+	fn, ok = e.(*JSArrow)
+	if err != nil || !ok {
+		return nil, "", 0, false
+	}
+	stop = min(base+end, len(p.source))
+	for !p.isAtEnd() && p.current().Offset <= stop && !p.cancelled() {
+		p.advance()
+	}
+	return fn, src, stop, true
+}
+
+var plainIdent = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// assignHookScopes gives each custom hook the state, derived values and
+// effects declared within it, and marks the returned values that are
+// functions, which only make sense in the browser
+func (p *Parser) assignHookScopes(lines []string, stateVars []StateVariable, derivedVars []DerivedVariable) {
+	for i := range p.hooks {
+		hook := &p.hooks[i]
+		start, end := hook.LineNumber, hook.EndLine+1
+
+		for _, sv := range stateVars {
+			if sv.LineNumber >= start && sv.LineNumber < end {
+				hook.StateVars = append(hook.StateVars, sv)
+			}
+		}
+		for _, dv := range derivedVars {
+			if dv.LineNumber >= start && dv.LineNumber < end {
+				hook.DerivedVars = append(hook.DerivedVars, dv)
+			}
+		}
+
+		if lines == nil {
+			continue
+		}
+		hook.Fetches = componentFetches(lines, start, end, hook.StateVars)
+		hook.HookCalls = componentHookCalls(lines, start, end, p.hooks)
+		fetched := map[int]bool{}
+		for _, f := range hook.Fetches {
+			fetched[f.LineNumber] = true
+		}
+		body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+		for _, loc := range effectCall.FindAllStringIndex(body, -1) {
+			if line := start + strings.Count(body[:loc[0]], "\n"); !fetched[line] {
+				hook.Effects = append(hook.Effects, line)
+			}
+		}
+
+		funcs := map[string]bool{}
+		for _, sv := range hook.StateVars {
+			funcs[sv.Setter] = true
+		}
+		for _, m := range localFunc.FindAllStringSubmatch(hook.body, -1) {
+			for _, name := range m[1:] {
+				if name != "" {
+					funcs[name] = true
+				}
+			}
+		}
+		for j := range hook.Returns {
+			v := &hook.Returns[j]
+			if funcs[v.Value] {
+				v.Func = true
+			}
+		}
+	}
+}
+
+// componentHookCalls finds the calls of the file's custom hooks on the
+// 1-based source lines [start, end) and the names their results are
+// bound to
+func componentHookCalls(lines []string, start, end int, hooks []CustomHook) []HookCall {
+	local := make(map[string]bool, len(hooks))
+	for _, h := range hooks {
+		local[h.Name] = true
+	}
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+
+	var calls []HookCall
+	for _, m := range hookCallDecl.FindAllStringSubmatchIndex(body, -1) {
+		hook := body[m[4]:m[5]]
+		if !local[hook] {
+			continue
+		}
+		call := HookCall{Hook: hook, LineNumber: start + strings.Count(body[:m[0]], "\n")}
+		if e, _, err := ParseJSExprPrefix(body[m[4]:]); err == nil {
+			if c, ok := e.(*JSCall); ok {
+				for _, arg := range c.Args {
+					call.Args = append(call.Args, strings.TrimSpace(arg.Span().Text(body[m[4]:])))
+				}
+			}
+		}
+
+		call.Names, call.Keys = bindingNames(body[m[2]:m[3]])
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// bindingNames returns the local names a declaration binds: [a, , b]
+// binds a, "" and b by position; { a, b: c } binds the keys a and b as a
+// and c; a plain identifier binds the whole value
+func bindingNames(binding string) (names, keys []string) {
+	binding = strings.TrimSpace(binding)
+	switch {
+	case strings.HasPrefix(binding, "["):
+		for _, name := range strings.Split(strings.Trim(binding, "[]"), ",") {
+			name, _, _ = strings.Cut(name, "=")
+			names = append(names, strings.TrimSpace(name))
+		}
+		// [a, b,] has no third element
+		if n := len(names); n > 0 && names[n-1] == "" {
+			names = names[:n-1]
+		}
+	case strings.HasPrefix(binding, "{"):
+		for _, entry := range strings.Split(strings.Trim(binding, "{}"), ",") {
+			entry, _, _ = strings.Cut(entry, "=")
+			key, name, renamed := strings.Cut(entry, ":")
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if !renamed {
+				name = key
+			}
+			keys = append(keys, key)
+			names = append(names, strings.TrimSpace(name))
+		}
+	default:
+		names = []string{binding}
+	}
+	return names, keys
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// i18nLibraries are the i18next bindings whose t function and <Trans>
+// component are translated into go-i18n lookups
+var i18nLibraries = map[string]bool{
+	"react-i18next": true,
+	"next-i18next":  true,
+	"i18next":       true,
+}
+
+// useTranslation matches const { t } = useTranslation(...) and captures the
+// destructured names so an alias (t: translate) can be found
+var useTranslation = regexp.MustCompile(`const\s*\{([^}]*)\}\s*=\s*useTranslation\s*\(`)
+
+var translatorBinding = regexp.MustCompile(`(?:^|,)\s*t\s*(?::\s*(\w+))?\s*(?:,|$)`)
+
+// usesI18n reports whether the file imports an i18next binding
+func usesI18n(imports []Import) bool {
+	for _, imp := range imports {
+		if i18nLibraries[strings.Trim(imp.Source, "'\"`")] {
+			return true
+		}
+	}
+	return false
+}
+
+// componentTranslator returns the name the component calls the i18next t
+// function by, looking at the 1-based source lines [start, end). A t prop
+// injected by withTranslation counts, and so does a bare <Trans> element,
+// for which "t" is returned. It returns "" when nothing is translated.
+func componentTranslator(lines []string, start, end int, comp *Component) string {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	if m := useTranslation.FindStringSubmatch(body); m != nil {
+		if b := translatorBinding.FindStringSubmatch(m[1]); b != nil {
+			if b[1] != "" {
+				return b[1]
+			}
+			return "t"
+		}
+	}
+	for _, prop := range comp.Props {
+		if prop.Name == "t" {
+			return "t"
+		}
+	}
+	if strings.Contains(body, "<Trans") {
+		return "t"
+	}
+	return ""
+}
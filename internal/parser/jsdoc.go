@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// jsdocParam matches the rest of a @param tag: {string} [props.size='md'] - text
+var jsdocParam = regexp.MustCompile(`^(?:\{([^{}]*(?:\{[^{}]*\}[^{}]*)*)\}\s*)?(\[[^\]]*\]|[\w.$]+)\s*(?:-\s*)?(.*)$`)
+
+// jsdoc is a JSDoc block: its description and the tags the converter uses
+type jsdoc struct {
+	text       string
+	params     []jsdocTag
+	deprecated string
+}
+
+// jsdocTag is a @param or @property of a JSDoc block
+type jsdocTag struct {
+	name string // as written: title, props.title
+	typ  string // the type between the braces
+	text string
+	def  string // [size='md'] gives 'md'
+}
+
+// componentDoc returns the JSDoc block ending on the line above a
+// component's declaration, or nil if there is none
+func componentDoc(lines []string, line int) *jsdoc {
+	end := line - 2
+	if end < 0 || end >= len(lines) || !strings.HasSuffix(strings.TrimSpace(lines[end]), "*/") {
+		return nil
+	}
+	start := end
+	for start >= 0 && !strings.Contains(lines[start], "/*") {
+		start--
+	}
+	if start < 0 || !strings.HasPrefix(strings.TrimSpace(lines[start]), "/**") {
+		return nil
+	}
+	block := strings.Join(lines[start:end+1], "\n")
+	block = strings.TrimSpace(block)
+	block = strings.TrimSuffix(strings.TrimPrefix(block, "/**"), "*/")
+	return parseJSDoc(block)
+}
+
+// parseJSDoc reads the body of a JSDoc block, without its delimiters
+func parseJSDoc(block string) *jsdoc {
+	doc := &jsdoc{}
+	var text []string
+	var tag, tagText string
+	flush := func() {
+		tagText = strings.TrimSpace(tagText)
+		switch tag {
+		case "param", "arg", "argument", "prop", "property":
+			if t, ok := parseJSDocParam(tagText); ok {
+				doc.params = append(doc.params, t)
+			}
+		case "deprecated":
+			doc.deprecated = tagText
+			if doc.deprecated == "" {
+				doc.deprecated = "the component is deprecated."
+			}
+		}
+		tag, tagText = "", ""
+	}
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		if strings.HasPrefix(line, " ") {
+			line = line[1:]
+		}
+		if strings.HasPrefix(line, "@") {
+			flush()
+			name, rest, _ := strings.Cut(line[1:], " ")
+			tag, tagText = name, rest
+			continue
+		}
+		if tag != "" {
+			tagText += "\n" + line
+			continue
+		}
+		text = append(text, strings.TrimRight(line, " \t"))
+	}
+	flush()
+	doc.text = strings.TrimSpace(strings.Join(text, "\n"))
+	return doc
+}
+
+// parseJSDocParam reads the rest of a @param tag
+func parseJSDocParam(src string) (jsdocTag, bool) {
+	m := jsdocParam.FindStringSubmatch(strings.Join(strings.Fields(src), " "))
+	if m == nil {
+		return jsdocTag{}, false
+	}
+	t := jsdocTag{typ: strings.TrimSpace(m[1]), text: strings.TrimSpace(m[3])}
+	name := m[2]
+	if inner, ok := strings.CutPrefix(name, "["); ok {
+		name, t.def, _ = strings.Cut(strings.TrimSuffix(inner, "]"), "=")
+	}
+	// {string=} marks an optional parameter
+	t.typ = strings.TrimSuffix(t.typ, "=")
+	t.name = strings.TrimSpace(name)
+	t.def = strings.TrimSpace(t.def)
+	return t, t.name != ""
+}
+
+// jsdocKinds maps a JSDoc type to the JS type a prop declares
+var jsdocKinds = map[string]string{
+	"string":          "string",
+	"number":          "number",
+	"boolean":         "bool",
+	"bool":            "bool",
+	"array":           "array",
+	"object":          "object",
+	"function":        "func",
+	"node":            "node",
+	"element":         "node",
+	"reactnode":       "node",
+	"react.reactnode": "node",
+	"jsx.element":     "node",
+	"reactelement":    "node",
+	"*":               "any",
+	"any":             "any",
+}
+
+// jsdocKind returns the JS type of a JSDoc type expression, or ""
+func jsdocKind(typ string) string {
+	typ = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(typ), "?"))
+	switch lower := strings.ToLower(typ); {
+	case lower == "":
+		return ""
+	case jsdocKinds[lower] != "":
+		return jsdocKinds[lower]
+	case strings.HasSuffix(typ, "[]"), strings.HasPrefix(lower, "array<"), strings.HasPrefix(lower, "array.<"):
+		return "array"
+	case strings.HasPrefix(typ, "{"), strings.HasPrefix(lower, "object<"), strings.HasPrefix(lower, "object.<"):
+		return "object"
+	case strings.Contains(typ, "=>"), strings.HasPrefix(lower, "function("):
+		return "func"
+	case strings.Contains(typ, "|"):
+		// 'sm'|'lg' takes the type of its values
+		kind := ""
+		for _, alt := range strings.Split(typ, "|") {
+			alt = strings.TrimSpace(alt)
+			k := jsdocKind(alt)
+			if strings.ContainsAny(alt[:min(1, len(alt))], `'"`) {
+				k = "string"
+			}
+			if k == "" || kind != "" && k != kind {
+				return ""
+			}
+			kind = k
+		}
+		return kind
+	}
+	return ""
+}
+
+// applyJSDoc carries the JSDoc block above each component over: its text
+// becomes the component's doc, and its @param tags type the props
+// nothing else has typed. The fields of the props object are documented
+// as props.title (or under whatever name the first tag gives it); a
+// component taking the props object whole gets them as its own.
+func (p *Parser) applyJSDoc(lines []string, comps []Component) {
+	for i := range comps {
+		comp := &comps[i]
+		doc := componentDoc(lines, comp.LineNumber)
+		if doc == nil {
+			continue
+		}
+		comp.Doc = doc.text
+		comp.Deprecated = doc.deprecated
+
+		whole := len(comp.Props) == 1 && comp.Props[0].Name == "props"
+		object := "props"
+		if len(doc.params) > 0 && !strings.Contains(doc.params[0].name, ".") {
+			object = doc.params[0].name
+		}
+		var documented []Prop
+		for _, tag := range doc.params {
+			name := tag.name
+			if rest, ok := strings.CutPrefix(name, object+"."); ok {
+				name = rest
+			} else if whole || strings.Contains(name, ".") {
+				// The props object itself, or a field of a prop
+				continue
+			}
+			found := false
+			for j := range comp.Props {
+				prop := &comp.Props[j]
+				if prop.Name != name && prop.Alias != name {
+					continue
+				}
+				found = true
+				prop.Doc = tag.text
+				if prop.JSType == "" {
+					prop.JSType = jsdocKind(tag.typ)
+				}
+				if prop.DefaultValue == "" {
+					prop.DefaultValue = tag.def
+				}
+			}
+			if !found && whole {
+				documented = append(documented, Prop{Name: name, JSType: jsdocKind(tag.typ), DefaultValue: tag.def, Doc: tag.text})
+			}
+		}
+		if whole && len(documented) > 0 {
+			comp.Props = documented
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ASTVersion is the version of the JSON form of the AST. It changes only
+// when existing fields change meaning or are removed; new fields may be
+// added without a version change.
+const ASTVersion = 1
+
+// The JSON form of a node carries its kind next to its fields, so trees
+// decode back into the same node types:
+//
+//	{"kind": "element", "tag": "div", "children": [{"kind": "text", "content": "Hi"}]}
+const (
+	kindElement     = "element"
+	kindText        = "text"
+	kindExpression  = "expression"
+	kindFragment    = "fragment"
+	kindMap         = "map"
+	kindConditional = "conditional"
+	kindTernary     = "ternary"
+)
+
+func (e Element) MarshalJSON() ([]byte, error) {
+	type plain Element
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindElement, plain(e)})
+}
+
+func (t Text) MarshalJSON() ([]byte, error) {
+	type plain Text
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindText, plain(t)})
+}
+
+func (e Expression) MarshalJSON() ([]byte, error) {
+	type plain Expression
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindExpression, plain(e)})
+}
+
+func (f Fragment) MarshalJSON() ([]byte, error) {
+	type plain Fragment
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindFragment, plain(f)})
+}
+
+func (m MapExpr) MarshalJSON() ([]byte, error) {
+	type plain MapExpr
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindMap, plain(m)})
+}
+
+func (c Conditional) MarshalJSON() ([]byte, error) {
+	type plain Conditional
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindConditional, plain(c)})
+}
+
+func (t Ternary) MarshalJSON() ([]byte, error) {
+	type plain Ternary
+	return json.Marshal(struct {
+		Kind string `json:"kind"`
+		plain
+	}{kindTernary, plain(t)})
+}
+
+// MarshalJSON leaves out the expression of attributes that have none
+func (a Attribute) MarshalJSON() ([]byte, error) {
+	type plain Attribute
+	aux := struct {
+		plain
+		Expression *Expression `json:"expression,omitempty"`
+	}{plain: plain(a)}
+	if a.Expression.Raw != "" || a.Expression.Parsed != nil {
+		aux.Expression = &a.Expression
+	}
+	return json.Marshal(aux)
+}
+
+func (c *Component) UnmarshalJSON(data []byte) error {
+	type plain Component
+	aux := struct {
+		*plain
+		Body json.RawMessage `json:"body"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	c.Body, err = decodeNode(aux.Body)
+	return err
+}
+
+func (e *Element) UnmarshalJSON(data []byte) error {
+	type plain Element
+	aux := struct {
+		*plain
+		Children []json.RawMessage `json:"children"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	e.Children, err = decodeNodes(aux.Children)
+	return err
+}
+
+func (e *Expression) UnmarshalJSON(data []byte) error {
+	type plain Expression
+	aux := struct {
+		*plain
+		Parsed json.RawMessage `json:"parsed"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	e.Parsed, err = decodeNode(aux.Parsed)
+	return err
+}
+
+func (f *Fragment) UnmarshalJSON(data []byte) error {
+	type plain Fragment
+	aux := struct {
+		*plain
+		Children []json.RawMessage `json:"children"`
+	}{plain: (*plain)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	f.Children, err = decodeNodes(aux.Children)
+	return err
+}
+
+func (m *MapExpr) UnmarshalJSON(data []byte) error {
+	type plain MapExpr
+	aux := struct {
+		*plain
+		Body json.RawMessage `json:"body"`
+	}{plain: (*plain)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	m.Body, err = decodeNode(aux.Body)
+	return err
+}
+
+func (c *Conditional) UnmarshalJSON(data []byte) error {
+	type plain Conditional
+	aux := struct {
+		*plain
+		Consequent json.RawMessage `json:"consequent"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	c.Consequent, err = decodeNode(aux.Consequent)
+	return err
+}
+
+func (t *Ternary) UnmarshalJSON(data []byte) error {
+	type plain Ternary
+	aux := struct {
+		*plain
+		Consequent json.RawMessage `json:"consequent"`
+		Alternate  json.RawMessage `json:"alternate"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	if t.Consequent, err = decodeNode(aux.Consequent); err != nil {
+		return err
+	}
+	t.Alternate, err = decodeNode(aux.Alternate)
+	return err
+}
+
+// decodeNode decodes a node by its kind; an absent or null node is nil
+func decodeNode(data json.RawMessage) (Node, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var head struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	var node Node
+	switch head.Kind {
+	case kindElement:
+		node = &Element{}
+	case kindText:
+		node = &Text{}
+	case kindExpression:
+		node = &Expression{}
+	case kindFragment:
+		node = &Fragment{}
+	case kindMap:
+		node = &MapExpr{}
+	case kindConditional:
+		node = &Conditional{}
+	case kindTernary:
+		node = &Ternary{}
+	default:
+		return nil, fmt.Errorf("unknown node kind %q", head.Kind)
+	}
+	if err := json.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func decodeNodes(list []json.RawMessage) ([]Node, error) {
+	if list == nil {
+		return nil, nil
+	}
+	nodes := make([]Node, 0, len(list))
+	for _, data := range list {
+		node, err := decodeNode(data)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
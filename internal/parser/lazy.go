@@ -0,0 +1,147 @@
+package parser
+
+import "strings"
+
+// parseLazy parses const Chart = lazy(() => import('./Chart')), or
+// React.lazy, as the import it defers: the server renders every
+// component when it is needed, so a lazy component is an ordinary one.
+// A named export picked with .then(m => ({ default: m.LineChart })) is
+// a named import. The position is just past the name, and on success
+// just past the call.
+func (p *Parser) parseLazy(name string, line int) *Import {
+	start := p.pos
+	p.skipWhitespace()
+	if !p.match(TokenEquals) {
+		p.pos = start
+		return nil
+	}
+	p.skipWhitespace()
+	call := p.current()
+	if p.source == "" || call.Offset > len(p.source) || (call.Value != "lazy" && call.Value != "React") {
+		p.pos = start
+		return nil
+	}
+	src := p.source[call.Offset-len(call.Value):]
+	e, n, err := ParseJSExprPrefix(src)
+	if err != nil {
+		p.pos = start
+		return nil
+	}
+	c, ok := e.(*JSCall)
+	if !ok || !isLazyCallee(c.Callee) || len(c.Args) != 1 {
+		p.pos = start
+		return nil
+	}
+	loader, ok := Unparen(c.Args[0]).(*JSArrow)
+	if !ok || loader.Body == nil {
+		p.pos = start
+		return nil
+	}
+
+	imp := &Import{Default: name, LineNumber: line, Lazy: true}
+	body := Unparen(loader.Body)
+	// import('./Charts').then(m => ({ default: m.LineChart }))
+	if then, ok := body.(*JSCall); ok && len(then.Args) == 1 {
+		if member, ok := then.Callee.(*JSMember); ok && member.Property == "then" {
+			export := lazyExport(then.Args[0])
+			if export == "" {
+				p.pos = start
+				return nil
+			}
+			if export != "default" {
+				imp.Default = ""
+				imp.Named = map[string]string{export: name}
+			}
+			body = member.Object
+		}
+	}
+	source, ok := dynamicImport(body)
+	if !ok {
+		p.pos = start
+		return nil
+	}
+	imp.Source = "'" + source + "'"
+
+	// Step over the call, whose import() is not a declaration
+	end := call.Offset - len(call.Value) + n
+	for !p.isAtEnd() && p.current().Offset <= end && !p.cancelled() {
+		p.advance()
+	}
+	return imp
+}
+
+// isLazyCallee reports whether a call is of lazy or React.lazy
+func isLazyCallee(e JSExpr) bool {
+	switch c := e.(type) {
+	case *JSIdent:
+		return c.Name == "lazy"
+	case *JSMember:
+		obj, ok := c.Object.(*JSIdent)
+		return ok && obj.Name == "React" && c.Property == "lazy"
+	}
+	return false
+}
+
+// dynamicImport returns the module of import('./Chart')
+func dynamicImport(e JSExpr) (string, bool) {
+	c, ok := e.(*JSCall)
+	if !ok || len(c.Args) != 1 {
+		return "", false
+	}
+	if callee, ok := c.Callee.(*JSIdent); !ok || callee.Name != "import" {
+		return "", false
+	}
+	lit, ok := c.Args[0].(*JSLiteral)
+	if !ok || lit.Kind != "string" {
+		return "", false
+	}
+	return lit.Value, true
+}
+
+// lazyExport returns the export m => ({ default: m.LineChart }) picks
+func lazyExport(e JSExpr) string {
+	fn, ok := Unparen(e).(*JSArrow)
+	if !ok || len(fn.Params) != 1 || fn.Body == nil {
+		return ""
+	}
+	obj, ok := Unparen(fn.Body).(*JSObject)
+	if !ok || len(obj.Props) != 1 || obj.Props[0].Key != "default" {
+		return ""
+	}
+	member, ok := obj.Props[0].Value.(*JSMember)
+	if !ok || member.Property == "" {
+		return ""
+	}
+	if m, ok := member.Object.(*JSIdent); !ok || m.Name != strings.TrimSpace(fn.Params[0]) {
+		return ""
+	}
+	return member.Property
+}
+
+// suspense parses the fallback of every <Suspense> into markup, kept as
+// the parsed expression of the attribute
+func (p *Parser) suspense(file *File) {
+	imported := false
+	for _, imp := range file.Imports {
+		if strings.Trim(imp.Source, "'\"`") == "react" && imp.Named["Suspense"] == "Suspense" {
+			imported = true
+		}
+	}
+	for i := range file.Components {
+		walkJSX(file.Components[i].Body, func(elem *Element) {
+			if elem.Tag != "React.Suspense" && (elem.Tag != "Suspense" || !imported) {
+				return
+			}
+			for j := range elem.Attributes {
+				attr := &elem.Attributes[j]
+				if attr.Name != "fallback" || attr.Expression.Raw == "" {
+					continue
+				}
+				raw := strings.TrimSpace(attr.Expression.Raw)
+				if e, err := ParseJSExpr(raw); err == nil {
+					attr.Expression.Parsed = p.branch(raw, e, elem.LineNumber)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"context"
+	"regexp"
 	"strings"
 	"unicode"
 )
@@ -37,6 +39,9 @@ const (
 	TokenFalse        // false
 	TokenNull         // null
 	TokenUndefined    // undefined
+	TokenComment      // // ... or /* ... */, with KeepComments
+	TokenRegexp       // /\d+/g
+	TokenTemplate     // `...${expr}...`
 )
 
 // Token represents a lexical token
@@ -46,6 +51,13 @@ type Token struct {
 	Line    int
 	Column  int
 	Offset  int
+
+	// Exprs are the ${} expressions of a TokenTemplate, in order
+	Exprs []string
+
+	// Skipped is the source a TokenError passed over to recover; the
+	// token's Value says why
+	Skipped string
 }
 
 // Lexer tokenizes JSX input
@@ -55,12 +67,31 @@ type Lexer struct {
 	line    int
 	column  int
 	tokens  []Token
+
+	// KeepComments emits JavaScript comments as TokenComment. By default
+	// a comment is scanned as the whitespace it stands for.
+	KeepComments bool
+
+	// jsx is the JSX the scanner is inside, innermost last; empty in
+	// plain JavaScript. Comments are only comments outside JSX text.
+	jsx  []jsxContext
+	prev Token // the last token that is not whitespace or a comment
 }
 
+// jsxContext is a place in JSX markup the lexer tracks
+type jsxContext int
+
+const (
+	jsxOpenTag  jsxContext = iota // <div className="a" ...
+	jsxCloseTag                   // </div
+	jsxChildren                   // between <div> and </div>: text, where // is not a comment
+	jsxExpr                       // {...} in markup: JavaScript again
+)
+
 // NewLexer creates a new lexer for the given input
 func NewLexer(input string) *Lexer {
 	return &Lexer{
-		input:  input,
+		input:  desugarPreact(input),
 		pos:    0,
 		line:   1,
 		column: 1,
@@ -69,21 +100,80 @@ func NewLexer(input string) *Lexer {
 
 // Tokenize processes the input and returns all tokens
 func (l *Lexer) Tokenize() []Token {
-	for l.pos < len(l.input) {
+	tokens, _ := l.TokenizeContext(context.Background())
+	return tokens
+}
+
+// TokenizeContext is like Tokenize but stops early when ctx is cancelled,
+// returning the tokens scanned so far and the context error.
+func (l *Lexer) TokenizeContext(ctx context.Context) ([]Token, error) {
+	for n := 0; l.pos < len(l.input); n++ {
+		if n%checkInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return l.tokens, err
+			}
+		}
 		l.scanToken()
 	}
 	l.emit(TokenEOF, "")
-	return l.tokens
+	return l.tokens, nil
 }
 
+// checkInterval is how many loop iterations pass between context checks.
+const checkInterval = 256
+
 func (l *Lexer) emit(typ TokenType, value string) {
-	l.tokens = append(l.tokens, Token{
+	tok := Token{
 		Type:   typ,
 		Value:  value,
 		Line:   l.line,
 		Column: l.column,
 		Offset: l.pos,
-	})
+	}
+	l.tokens = append(l.tokens, tok)
+	if typ != TokenWhitespace && typ != TokenComment {
+		l.prev = tok
+	}
+}
+
+// inJSXText reports whether the scanner is in the children of a JSX
+// element, where // and /* are text
+func (l *Lexer) inJSXText() bool {
+	return len(l.jsx) > 0 && l.jsx[len(l.jsx)-1] == jsxChildren
+}
+
+func (l *Lexer) pushJSX(c jsxContext) {
+	l.jsx = append(l.jsx, c)
+}
+
+func (l *Lexer) popJSX() {
+	if len(l.jsx) > 0 {
+		l.jsx = l.jsx[:len(l.jsx)-1]
+	}
+}
+
+func (l *Lexer) topJSX(c jsxContext) bool {
+	return len(l.jsx) > 0 && l.jsx[len(l.jsx)-1] == c
+}
+
+// startsJSX reports whether a < in JavaScript opens a JSX element rather
+// than comparing: it follows an operator, keyword or opening bracket,
+// and a tag name or the > of a fragment follows it
+func (l *Lexer) startsJSX() bool {
+	next := l.peekN(2)
+	if len(next) < 2 || !(isIdentStart(next[1]) || next[1] == '>') {
+		return false
+	}
+	switch l.prev.Type {
+	case TokenEOF, TokenLParen, TokenEquals, TokenArrow, TokenComma, TokenColon,
+		TokenQuestion, TokenAmpAmp, TokenPipePipe, TokenJSXExprOpen:
+		return true
+	case TokenIdent:
+		return l.prev.Value == "return" || l.prev.Value == "yield" || l.prev.Value == "default"
+	case TokenText:
+		return l.prev.Value == "[" || l.prev.Value == "!"
+	}
+	return false
 }
 
 func (l *Lexer) peek() byte {
@@ -125,14 +215,39 @@ func (l *Lexer) scanToken() {
 		return
 	}
 
+	// Comments
+	if !l.inJSXText() && (l.peekN(2) == "//" || l.peekN(2) == "/*") {
+		l.scanComment()
+		return
+	}
+
+	// Regular expression literals, where an operand can start; in a
+	// tag, / only closes it
+	if ch == '/' && !l.inJSXText() && !l.topJSX(jsxOpenTag) && !l.topJSX(jsxCloseTag) && l.regexpAllowed() {
+		if end := skipRegexp(l.input, l.pos); end > 0 {
+			start := l.pos
+			for l.pos < end {
+				l.advance()
+			}
+			l.emit(TokenRegexp, l.input[start:end])
+			return
+		}
+	}
+
 	// JSX expression
 	if ch == '{' {
 		l.advance()
+		if len(l.jsx) > 0 {
+			l.pushJSX(jsxExpr)
+		}
 		l.emit(TokenJSXExprOpen, "{")
 		return
 	}
 	if ch == '}' {
 		l.advance()
+		if l.topJSX(jsxExpr) {
+			l.popJSX()
+		}
 		l.emit(TokenJSXExprClose, "}")
 		return
 	}
@@ -140,17 +255,26 @@ func (l *Lexer) scanToken() {
 	// Tags
 	if ch == '<' {
 		if l.peekN(2) == "</" {
+			if l.inJSXText() {
+				l.pushJSX(jsxCloseTag)
+			}
 			l.advance()
 			l.advance()
 			l.emit(TokenTagEnd, "</")
 			return
 		}
+		if l.inJSXText() || l.startsJSX() {
+			l.pushJSX(jsxOpenTag)
+		}
 		l.advance()
 		l.emit(TokenTagOpen, "<")
 		return
 	}
 
 	if l.peekN(2) == "/>" {
+		if l.topJSX(jsxOpenTag) {
+			l.popJSX()
+		}
 		l.advance()
 		l.advance()
 		l.emit(TokenTagSelfClose, "/>")
@@ -158,6 +282,14 @@ func (l *Lexer) scanToken() {
 	}
 
 	if ch == '>' {
+		switch {
+		case l.topJSX(jsxOpenTag):
+			l.jsx[len(l.jsx)-1] = jsxChildren
+		case l.topJSX(jsxCloseTag):
+			// The closing tag ends the element whose children it closes
+			l.popJSX()
+			l.popJSX()
+		}
 		l.advance()
 		l.emit(TokenTagClose, ">")
 		return
@@ -228,11 +360,15 @@ func (l *Lexer) scanToken() {
 		return
 	}
 
-	// Strings
-	if ch == '"' || ch == '\'' || ch == '`' {
+	// Strings; in JSX text a quote is an apostrophe or a quotation mark
+	if (ch == '"' || ch == '\'') && !l.inJSXText() {
 		l.scanString(ch)
 		return
 	}
+	if ch == '`' && !l.inJSXText() {
+		l.scanTemplate()
+		return
+	}
 
 	// Numbers
 	if unicode.IsDigit(rune(ch)) {
@@ -251,6 +387,30 @@ func (l *Lexer) scanToken() {
 	l.emit(TokenText, string(ch))
 }
 
+// regexpKeywords are the keywords after which / starts a regular
+// expression: return /x/.test(s)
+var regexpKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true,
+	"in": true, "of": true, "new": true, "delete": true, "void": true,
+	"throw": true, "yield": true, "await": true, "instanceof": true,
+}
+
+// regexpAllowed reports whether a / starts a regular expression rather
+// than dividing: one cannot follow an operand such as a name, a literal
+// or a closing bracket
+func (l *Lexer) regexpAllowed() bool {
+	switch l.prev.Type {
+	case TokenNumber, TokenString, TokenTemplate, TokenRegexp, TokenRParen, TokenJSXExprClose,
+		TokenTrue, TokenFalse, TokenNull, TokenUndefined:
+		return false
+	case TokenIdent:
+		return regexpKeywords[l.prev.Value]
+	case TokenText:
+		return l.prev.Value != "]"
+	}
+	return true
+}
+
 func (l *Lexer) scanWhitespace() {
 	start := l.pos
 	for l.pos < len(l.input) && unicode.IsSpace(rune(l.peek())) {
@@ -259,6 +419,41 @@ func (l *Lexer) scanWhitespace() {
 	l.emit(TokenWhitespace, l.input[start:l.pos])
 }
 
+// scanComment scans a // or /* comment. Unless KeepComments is set it
+// becomes whitespace: a space, or the line breaks of a block comment, so
+// the tokens after it keep their lines.
+func (l *Lexer) scanComment() {
+	start := l.pos
+	block := l.peekN(2) == "/*"
+	l.advance()
+	l.advance()
+	for l.pos < len(l.input) {
+		if block && l.peekN(2) == "*/" {
+			l.advance()
+			l.advance()
+			break
+		}
+		if !block && l.peek() == '\n' {
+			break
+		}
+		l.advance()
+	}
+	text := l.input[start:l.pos]
+	if block && !strings.HasSuffix(text, "*/") || text == "/*/" {
+		l.fail("unterminated comment", start)
+		return
+	}
+	if l.KeepComments {
+		l.emit(TokenComment, text)
+		return
+	}
+	ws := " "
+	if n := strings.Count(text, "\n"); n > 0 {
+		ws = strings.Repeat("\n", n)
+	}
+	l.emit(TokenWhitespace, ws)
+}
+
 func (l *Lexer) scanString(quote byte) {
 	start := l.pos // includes opening quote
 	l.advance()    // consume opening quote
@@ -275,10 +470,69 @@ func (l *Lexer) scanString(quote byte) {
 			l.emit(TokenString, value)
 			return
 		}
+		if ch == '\n' {
+			// A string cannot span lines; scanning resumes on the next one
+			break
+		}
 		l.advance()
 	}
 	// Unterminated string
-	l.emit(TokenError, "unterminated string")
+	l.fail("unterminated string", start)
+}
+
+// scanTemplate scans a template literal as one token, whatever braces
+// and nested templates its ${} expressions hold
+func (l *Lexer) scanTemplate() {
+	start := l.pos
+	end := skipTemplate(l.input, start)
+	if end < 0 {
+		// Resume at the next top-level statement
+		end = len(l.input)
+		if loc := topLevelStatement.FindStringIndex(l.input[start:]); loc != nil {
+			end = start + loc[0]
+		}
+		for l.pos < end {
+			l.advance()
+		}
+		l.fail("unterminated template literal", start)
+		return
+	}
+	for l.pos < end {
+		l.advance()
+	}
+	l.emit(TokenTemplate, l.input[start:end])
+	l.tokens[len(l.tokens)-1].Exprs = templateExprs(l.input[start:end])
+	l.prev = l.tokens[len(l.tokens)-1]
+}
+
+// topLevelStatement matches the start of a line declaring something at
+// the top level of a module
+var topLevelStatement = regexp.MustCompile(`\n(?:import|export|function|const|let|var|class)\b`)
+
+// fail emits an error token for the source from start, which the lexer
+// passes over to recover from the error
+func (l *Lexer) fail(msg string, start int) {
+	l.emit(TokenError, msg)
+	l.tokens[len(l.tokens)-1].Skipped = l.input[start:l.pos]
+}
+
+// templateExprs returns the ${} expressions of a template literal
+func templateExprs(src string) []string {
+	var exprs []string
+	for j := 1; j < len(src)-1; j++ {
+		switch {
+		case src[j] == '\\':
+			j++
+		case src[j] == '$' && src[j+1] == '{':
+			end := skipBalanced(src, j+1)
+			if end < 0 {
+				return exprs
+			}
+			exprs = append(exprs, strings.TrimSpace(src[j+2:end-1]))
+			j = end - 1
+		}
+	}
+	return exprs
 }
 
 func (l *Lexer) scanNumber() {
@@ -358,6 +612,9 @@ func TokenName(t TokenType) string {
 		TokenFalse:        "False",
 		TokenNull:         "Null",
 		TokenUndefined:    "Undefined",
+		TokenComment:      "Comment",
+		TokenRegexp:       "Regexp",
+		TokenTemplate:     "Template",
 	}
 	if name, ok := names[t]; ok {
 		return name
@@ -0,0 +1,30 @@
+package parser
+
+// componentWrappers consumes the calls a component is wrapped in,
+// memo(forwardRef(... or React.memo(React.forwardRef(..., and returns
+// their names, outermost first. The position is left at the wrapped
+// function or name.
+func (p *Parser) componentWrappers() []string {
+	var wrappers []string
+	last := -1
+	for !p.halted(&last) {
+		start := p.pos
+		if p.matchIdent("React") && !p.match(TokenDot) {
+			p.pos = start
+			return wrappers
+		}
+		if !p.check(TokenIdent) || (p.current().Value != "memo" && p.current().Value != "forwardRef") {
+			p.pos = start
+			return wrappers
+		}
+		name := p.advance().Value
+		p.skipWhitespace()
+		if !p.match(TokenLParen) {
+			p.pos = start
+			return wrappers
+		}
+		p.skipWhitespace()
+		wrappers = append(wrappers, name)
+	}
+	return wrappers
+}
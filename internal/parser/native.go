@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// nativeLibrary matches the modules of the React Native ecosystem a web
+// app has no use for: react-native itself, its community packages, Expo
+// and React Navigation
+var nativeLibrary = regexp.MustCompile(`^(?:react-native(?:-[\w-]+)?(?:/.*)?|@react-native[\w-]*/.+|expo(?:-[\w-]+)?|@expo/.+|@react-navigation/.+)$`)
+
+// nativeUses lists what a file imports from React Native and its
+// libraries, and the lines it uses each on, or returns nil if it does not
+// import react-native
+func nativeUses(source string, imports []Import) []NativeUse {
+	native := false
+	for _, imp := range imports {
+		if strings.Trim(imp.Source, "'\"`") == "react-native" {
+			native = true
+		}
+	}
+	if !native {
+		return nil
+	}
+
+	lines := strings.Split(source, "\n")
+	uses := []NativeUse{}
+	for _, imp := range imports {
+		module := strings.Trim(imp.Source, "'\"`")
+		if !nativeLibrary.MatchString(module) {
+			continue
+		}
+		var names []string
+		for _, alias := range imp.Named {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+		if imp.Default != "" {
+			names = append(names, imp.Default)
+		}
+		if imp.Namespace != "" {
+			names = append(names, imp.Namespace)
+		}
+		for _, name := range names {
+			use := NativeUse{Name: name, Module: module}
+			ref := regexp.MustCompile(`(^|[^.\w$])` + regexp.QuoteMeta(name) + `\b`)
+			for i, line := range lines {
+				if i+1 != imp.LineNumber && ref.MatchString(line) && !importLine.MatchString(line) {
+					use.Lines = append(use.Lines, i+1)
+				}
+			}
+			uses = append(uses, use)
+		}
+	}
+	return uses
+}
+
+// importLine matches a line of an import declaration
+var importLine = regexp.MustCompile(`^\s*import\b|\bfrom\s*['"]`)
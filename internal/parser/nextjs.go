@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// nextLoaders are the data-fetching functions a Next.js page exports
+var nextLoaders = map[string]bool{
+	"getServerSideProps": true,
+	"getStaticProps":     true,
+	"getStaticPaths":     true,
+}
+
+// routeHandlers are the functions a Next.js route handler (app/**/route.js)
+// exports, one per HTTP method. They are not components.
+var routeHandlers = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true,
+}
+
+var (
+	// awaitRequest matches a request awaited into a local:
+	// const posts = await fetch(url).then(r => r.json())
+	awaitRequest = regexp.MustCompile(`\b(?:const|let|var)\s+(\{[^}]*\}|\w+)\s*=\s*await\s+(fetch|axios\.get|axios)\s*\(`)
+	// awaitJSON matches a response body decoded in a second step:
+	// const posts = await res.json()
+	awaitJSON = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*await\s+(\w+)\.json\(\s*\)`)
+	// contextRead matches a route or query parameter read: context.params.slug
+	contextRead = regexp.MustCompile(`\b(params|query)\??\.(\w+)`)
+	// contextBinding matches route or query parameters destructured:
+	// const { slug } = context.params, or { params: { slug } } in the signature
+	contextBinding = regexp.MustCompile(`(\{[^{}]*\})\s*=\s*(?:\w+\.)?(params|query)\b|\b(params|query)\s*:\s*(\{[^{}]*\})`)
+)
+
+// parseDataLoader parses getServerSideProps, getStaticProps or
+// getStaticPaths; the position is just past the name. The requests it
+// awaits are assigned by line range once the file is parsed.
+func (p *Parser) parseDataLoader(kind string, line int, isArrow, async bool) {
+	fn, _, stop, ok := p.functionAt(isArrow, async)
+	if !ok {
+		p.skipToNextStatement()
+		return
+	}
+	loader := DataLoader{
+		Kind:       kind,
+		Body:       strings.TrimSpace(fn.Block),
+		LineNumber: line,
+		EndLine:    1 + strings.Count(p.source[:stop], "\n"),
+	}
+
+	// The props of every return { props: {...} }; a key returned twice is
+	// recorded once
+	seen := map[string]bool{}
+	for _, loc := range returnKeyword.FindAllStringIndex(fn.Block, -1) {
+		src := fn.Block[loc[1]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		ret, ok := Unparen(e).(*JSObject)
+		if !ok {
+			continue
+		}
+		for _, prop := range ret.Props {
+			props, ok := Unparen(prop.Value).(*JSObject)
+			if prop.Key != "props" || !ok {
+				continue
+			}
+			for _, v := range props.Props {
+				if v.Key == "" || v.Spread || seen[v.Key] {
+					continue
+				}
+				seen[v.Key] = true
+				value := v.Key
+				if !v.Shorthand && v.Value != nil {
+					value = strings.TrimSpace(v.Value.Span().Text(src))
+				}
+				loader.Props = append(loader.Props, HookValue{Name: v.Key, Value: value})
+			}
+		}
+	}
+
+	loader.Params, loader.Query = contextReads(strings.Join(fn.Params, ",") + "\n" + fn.Block)
+	p.loaders = append(p.loaders, loader)
+
+	hint := "Next.js data loader: with -framework=nextjs it becomes a Go function the route handler calls before rendering the page"
+	if kind == "getStaticPaths" {
+		hint = "Next.js static paths: not needed, the route handler serves every path on request"
+	}
+	p.addSuggestion(line, kind, hint, "nextData")
+}
+
+// contextReads returns the route and query parameters a data loader reads
+// from its context argument
+func contextReads(src string) (params, query []string) {
+	seen := map[string]bool{}
+	add := func(from, name string) {
+		if seen[from+"."+name] || !plainIdent.MatchString(name) {
+			return
+		}
+		seen[from+"."+name] = true
+		if from == "params" {
+			params = append(params, name)
+		} else {
+			query = append(query, name)
+		}
+	}
+	for _, m := range contextRead.FindAllStringSubmatch(src, -1) {
+		add(m[1], m[2])
+	}
+	for _, m := range contextBinding.FindAllStringSubmatch(src, -1) {
+		binding, from := m[1], m[2]
+		if binding == "" {
+			binding, from = m[4], m[3]
+		}
+		_, keys := bindingNames(binding)
+		for _, key := range keys {
+			add(from, key)
+		}
+	}
+	return params, query
+}
+
+// awaitedFetches finds the requests awaited into locals on the 1-based
+// source lines [start, end): fetch(url) decoded with .json() in the same
+// chain or a second await, and axios requests destructured into { data }
+func awaitedFetches(lines []string, start, end int) []DataFetch {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+
+	var fetches []DataFetch
+	for _, m := range awaitRequest.FindAllStringSubmatchIndex(body, -1) {
+		src := body[m[4]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		call := requestOf(e)
+		if call == nil || len(call.Args) == 0 {
+			continue
+		}
+		fetch := DataFetch{
+			URL:        strings.TrimSpace(call.Args[0].Span().Text(src)),
+			Client:     body[m[4]:m[5]],
+			Await:      true,
+			LineNumber: start + strings.Count(body[:m[0]], "\n"),
+		}
+
+		names, keys := bindingNames(body[m[2]:m[3]])
+		switch {
+		case fetch.Client != "fetch":
+			for i, key := range keys {
+				if key == "data" {
+					fetch.State = names[i]
+				}
+			}
+		case len(keys) > 0:
+		case e != JSExpr(call):
+			// fetch(url).then(r => r.json())
+			fetch.State = names[0]
+		default:
+			// const res = await fetch(url); const data = await res.json()
+			for _, j := range awaitJSON.FindAllStringSubmatch(body[m[1]:], -1) {
+				if j[2] == names[0] {
+					fetch.State = j[1]
+					break
+				}
+			}
+		}
+		if fetch.State != "" {
+			fetches = append(fetches, fetch)
+		}
+	}
+	return fetches
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// objectIterations are the Object functions that turn an object into an
+// array to map: its keys, its values or its [key, value] entries
+var objectIterations = map[string]bool{"keys": true, "values": true, "entries": true}
+
+// iteratedSource recognizes the arrays made only to be mapped, which have
+// no collection of their own: Object.entries(stats) iterates the object
+// stats, Array.from({ length: 5 }) and [...Array(5)] count to 5, and
+// Array.from(items) is items. All are empty for anything else; counted
+// reports [...Array(5).keys()], whose items are their indexes.
+func iteratedSource(src string, e JSExpr) (collection, object, length string, counted bool) {
+	switch n := Unparen(e).(type) {
+	case *JSCall:
+		if n.New || n.Optional || len(n.Args) != 1 {
+			return "", "", "", false
+		}
+		arg := Unparen(n.Args[0])
+		switch path := MemberPath(n.Callee); {
+		case strings.HasPrefix(path, "Object.") && objectIterations[strings.TrimPrefix(path, "Object.")]:
+			if collection := MemberPath(arg); collection != "" {
+				return collection, strings.TrimPrefix(path, "Object."), "", false
+			}
+		case path == "Array.from":
+			if length := lengthProperty(src, arg); length != "" {
+				return "", "", length, false
+			}
+			if collection := MemberPath(arg); collection != "" {
+				return collection, "", "", false
+			}
+			return iteratedSource(src, arg)
+		}
+	case *JSArray:
+		if len(n.Elements) != 1 {
+			return "", "", "", false
+		}
+		spread, ok := n.Elements[0].(*JSSpread)
+		if !ok {
+			return "", "", "", false
+		}
+		x := Unparen(spread.X)
+		if call, recv, ok := MethodCall(x, "keys"); ok && len(call.Args) == 0 {
+			x, counted = Unparen(recv), true
+		}
+		if call, ok := x.(*JSCall); ok && !call.Optional && MemberPath(call.Callee) == "Array" && len(call.Args) == 1 {
+			return "", "", strings.TrimSpace(call.Args[0].Span().Text(src)), counted
+		}
+	}
+	return "", "", "", false
+}
+
+// lengthProperty returns the length of an array-like object literal,
+// { length: 5 }, or ""
+func lengthProperty(src string, e JSExpr) string {
+	obj, ok := e.(*JSObject)
+	if !ok || len(obj.Props) != 1 {
+		return ""
+	}
+	prop := obj.Props[0]
+	if prop.Key != "length" || prop.Spread || prop.Computed != nil {
+		return ""
+	}
+	if prop.Shorthand {
+		return "length"
+	}
+	return strings.TrimSpace(prop.Value.Span().Text(src))
+}
+
+// keyName names the key of an object iterated without one, as its
+// values are: key, or key2 and on when the callback has a key already
+func keyName(taken map[string]bool) string {
+	name := "key"
+	for i := 2; taken[name]; i++ {
+		name = "key" + strconv.Itoa(i)
+	}
+	taken[name] = true
+	return name
+}
@@ -1,19 +1,40 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // Parser parses JSX tokens into an AST
 type Parser struct {
-	tokens      []Token
-	source      string // original source for regex-based extraction
-	pos         int
-	warnings    []Warning
-	suggestions []Suggestion
+	tokens        []Token
+	source        string // original source for regex-based extraction
+	pos           int
+	warnings      []Warning
+	suggestions   []Suggestion
+	styled        []StyledComponent
+	hooks         []CustomHook
+	helpers       []Helper
+	constants     []Constant
+	contexts      []ContextDecl
+	loaders       []DataLoader
+	lazy          []Import            // components loaded with React.lazy
+	wrapped       map[string][]string // memo and forwardRef applied to components by name
+	exports       []string
+	defaultExport string
+	ctx           context.Context
+	steps         int
+	stalled       *Token // where a loop stopped for want of progress, if one did
+
+	// limit ends the top-level declaration being parsed: the position of
+	// the next one, or 0 between declarations
+	limit      int
+	statements []int    // positions of the top-level statements
+	open       []string // the elements being parsed, innermost last; "" for a fragment
 }
 
 // NewParser creates a new parser for the given tokens
@@ -28,19 +49,32 @@ func NewParser(tokens []Token) *Parser {
 func NewParserWithSource(tokens []Token, source string) *Parser {
 	return &Parser{
 		tokens: tokens,
-		source: source,
+		source: desugarPreact(source),
 		pos:    0,
 	}
 }
 
 // Parse parses a complete JSX file
 func (p *Parser) Parse() *ParseResult {
+	result, _ := p.ParseContext(context.Background())
+	return result
+}
+
+// ParseContext is like Parse but abandons parsing when ctx is cancelled,
+// returning whatever was parsed so far together with the context error.
+func (p *Parser) ParseContext(ctx context.Context) (*ParseResult, error) {
+	p.ctx = ctx
+	defer func() { p.ctx = nil }()
+
 	file := &File{
 		Imports:    []Import{},
 		Components: []Component{},
 		Exports:    []string{},
 	}
 
+	p.statements = statementStarts(p.tokens)
+	p.reportLexErrors()
+
 	// Pre-extract all useState variables from source
 	var allStateVars []StateVariable
 	if p.source != "" {
@@ -53,7 +87,11 @@ func (p *Parser) Parse() *ParseResult {
 		allDerivedVars = extractDerivedVars(p.source, allStateVars)
 	}
 
+	last := -1
 	for !p.isAtEnd() {
+		if p.halted(&last) {
+			break
+		}
 		p.skipWhitespace()
 		if p.isAtEnd() {
 			break
@@ -61,19 +99,21 @@ func (p *Parser) Parse() *ParseResult {
 
 		// Try to parse imports
 		if p.checkIdent("import") {
-			imp := p.parseImport()
-			if imp != nil {
-				file.Imports = append(file.Imports, *imp)
-			}
+			p.declaration(func() {
+				if imp := p.parseImport(); imp != nil {
+					file.Imports = append(file.Imports, *imp)
+				}
+			})
 			continue
 		}
 
 		// Try to parse component definitions
-		if p.checkIdent("function") || p.checkIdent("const") || p.checkIdent("export") {
-			comp := p.parseComponent()
-			if comp != nil {
-				file.Components = append(file.Components, *comp)
-			}
+		if p.checkIdent("function") || p.checkIdent("const") || p.checkIdent("export") || p.checkIdent("async") {
+			p.declaration(func() {
+				if comp := p.parseComponent(); comp != nil {
+					file.Components = append(file.Components, *comp)
+				}
+			})
 			continue
 		}
 
@@ -81,11 +121,38 @@ func (p *Parser) Parse() *ParseResult {
 		p.advance()
 	}
 
+	p.solidFlow(file)
+	for i := range file.Components {
+		comp := &file.Components[i]
+		comp.Wrappers = append(comp.Wrappers, p.wrapped[comp.Name]...)
+	}
+	file.Imports = append(file.Imports, p.lazy...)
+	p.suspense(file)
+	p.cloneElements()
+
+	// Validation schemas (zod/yup) and the source lines they are used on
+	var allSchemas []ValidationSchema
+	var lines []string
+	if p.source != "" {
+		allSchemas = extractSchemas(p.source)
+		lines = strings.Split(p.source, "\n")
+		file.Slices = extractSlices(p.source)
+		file.Stores = extractStores(p.source)
+		file.Stories = extractStories(p.source)
+		file.Native = nativeUses(p.source, file.Imports)
+		file.Directive = fileDirective(p.source)
+		file.ServerActions = serverActions(p.source, file.Directive)
+	}
+	file.Schemas = allSchemas
+	i18n := usesI18n(file.Imports) && lines != nil
+
 	// Associate state vars and derived vars with components based on line numbers
+	ends := make([]int, len(file.Components))
 	for i := range file.Components {
 		comp := &file.Components[i]
 		compStart := comp.LineNumber
 		compEnd := p.findComponentEnd(comp, file.Components, i)
+		ends[i] = compEnd
 		
 		for _, sv := range allStateVars {
 			if sv.LineNumber >= compStart && sv.LineNumber < compEnd {
@@ -98,22 +165,144 @@ func (p *Parser) Parse() *ParseResult {
 				comp.DerivedVars = append(comp.DerivedVars, dv)
 			}
 		}
+
+		for _, schema := range allSchemas {
+			if referencesIdent(lines, compStart, compEnd, schema.Name, schema.LineNumber) {
+				comp.Schemas = append(comp.Schemas, schema.Name)
+			}
+		}
+
+		if i18n {
+			comp.Translator = componentTranslator(lines, compStart, compEnd, comp)
+		}
+
+		if lines != nil {
+			comp.Fetches = componentFetches(lines, compStart, compEnd, comp.StateVars)
+			comp.Debounces = componentDebounces(p.source, lines, compStart, compEnd, comp.StateVars)
+			comp.HookCalls = componentHookCalls(lines, compStart, compEnd, p.hooks)
+			comp.Contexts = componentContexts(lines, compStart, compEnd)
+			if comp.Async {
+				comp.Fetches = append(comp.Fetches, awaitedFetches(lines, compStart, compEnd)...)
+			}
+			comp.StoreReads = componentStoreReads(p.source, lines, compStart, compEnd)
+			comp.Dispatches = componentDispatches(lines, compStart, compEnd)
+			reads, calls := componentStoreHooks(file.Stores, lines, compStart, compEnd)
+			comp.StoreReads = append(comp.StoreReads, reads...)
+			comp.Dispatches = append(comp.Dispatches, calls...)
+			comp.Form = componentForm(lines, compStart, compEnd)
+			if comp.Form == nil {
+				comp.Form = antdForm(comp.Body)
+			}
+			comp.Tables = componentTables(p.source, lines, compStart, compEnd)
+		}
+	}
+	for i := range p.loaders {
+		if lines != nil {
+			p.loaders[i].Fetches = awaitedFetches(lines, p.loaders[i].LineNumber, p.loaders[i].EndLine+1)
+		}
+	}
+
+	if lines != nil {
+		p.applyPropTypes(lines, file.Components)
+		p.applyJSDoc(lines, file.Components)
 	}
 
-	return &ParseResult{
+	p.assignHookScopes(lines, allStateVars, allDerivedVars)
+	classifyRendering(file, lines, ends)
+	file.CustomHooks = p.hooks
+	file.Helpers = withoutActions(p.helpers, file.ServerActions)
+	file.Constants = p.constants
+	file.Contexts = p.contexts
+	file.DataLoaders = p.loaders
+
+	p.suggestPersistentState(allStateVars)
+	p.suggestFetches(file.Components)
+	p.suggestContexts(file.Components)
+	p.suggestRedux(file.Slices, file.Components)
+	p.suggestStores(file.Stores, file.Components)
+	p.suggestForms(file.Components)
+
+	file.StyledComponents = p.styled
+	file.Exports = append(file.Exports, p.exports...)
+	file.DefaultExport = p.defaultExport
+
+	// Lexer errors come first; report everything in source order
+	sort.SliceStable(p.warnings, func(i, j int) bool {
+		return p.warnings[i].Line < p.warnings[j].Line
+	})
+
+	result := &ParseResult{
 		File:        file,
 		Warnings:    p.warnings,
 		Suggestions: p.suggestions,
 	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
+
+// cancelled reports whether the parse context has been cancelled. The
+// context is only consulted every checkInterval calls to keep it cheap.
+func (p *Parser) cancelled() bool {
+	if p.ctx == nil {
+		return false
+	}
+	p.steps++
+	if p.steps%checkInterval != 0 {
+		return false
+	}
+	return p.ctx.Err() != nil
+}
+
+// halted reports whether a parser loop has to stop: the parse has been
+// cancelled, or the loop has gone round without consuming a token, its
+// turn starting where the last one did. last holds the position of the
+// last turn and starts at -1. A stall is recorded and stops every loop
+// until the declaration it is in has been skipped.
+func (p *Parser) halted(last *int) bool {
+	if p.stalled != nil || p.cancelled() {
+		return true
+	}
+	if p.pos == *last {
+		tok := p.current()
+		p.stalled = &tok
+		return true
+	}
+	*last = p.pos
+	return false
 }
 
 // findComponentEnd returns the line where the next component starts, or a large number
 func (p *Parser) findComponentEnd(comp *Component, comps []Component, idx int) int {
+	// No next component, use a large number
+	end := 999999
 	if idx+1 < len(comps) {
-		return comps[idx+1].LineNumber
+		end = comps[idx+1].LineNumber
 	}
-	// No next component, use a large number
-	return 999999
+	// A custom hook, data loader or helper declared after the component ends
+	// it too
+	for _, h := range p.hooks {
+		if h.LineNumber > comp.LineNumber && h.LineNumber < end {
+			end = h.LineNumber
+		}
+	}
+	for _, l := range p.loaders {
+		if l.LineNumber > comp.LineNumber && l.LineNumber < end {
+			end = l.LineNumber
+		}
+	}
+	for _, h := range p.helpers {
+		if h.LineNumber > comp.LineNumber && h.LineNumber < end {
+			end = h.LineNumber
+		}
+	}
+	for _, c := range p.constants {
+		if c.LineNumber > comp.LineNumber && c.LineNumber < end {
+			end = c.LineNumber
+		}
+	}
+	return end
 }
 
 // ParseJSX parses just a JSX element (for testing or partial conversion)
@@ -140,9 +329,43 @@ func (p *Parser) parseNode() Node {
 	}
 
 	// Text content
+	if text := p.parseText().(*Text); text.Content != "" {
+		return text
+	}
+	return nil
+}
+
+// parseChild parses a child of an element or fragment. Unlike parseNode
+// it keeps the whitespace around text, which JSX only partly collapses.
+func (p *Parser) parseChild() Node {
+	if p.isAtEnd() {
+		return nil
+	}
+	if p.check(TokenJSXExprOpen) || p.check(TokenTagOpen) {
+		return p.parseNode()
+	}
 	return p.parseText()
 }
 
+// dropped reports whether a child is whitespace JSX does not render:
+// text that collapses to nothing, kept only inside <pre> and <textarea>
+// for -preserve-whitespace
+func (p *Parser) dropped(child Node) bool {
+	text, ok := child.(*Text)
+	return ok && text.Content == "" && (text.Raw == "" || !p.preformatted())
+}
+
+// preformatted reports whether the text being parsed is inside a <pre>
+// or <textarea>, whose whitespace HTML keeps
+func (p *Parser) preformatted() bool {
+	for _, tag := range p.open {
+		if tag == "pre" || tag == "textarea" {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) parseElement() Node {
 	if !p.match(TokenTagOpen) {
 		return nil
@@ -158,12 +381,12 @@ func (p *Parser) parseElement() Node {
 
 	// Get tag name
 	if !p.check(TokenIdent) {
-		p.addWarning("Expected tag name after <")
+		p.addWarning(CodeMissingTagName, "Expected tag name after <")
 		return nil
 	}
 
 	tagToken := p.advance()
-	tagName := tagToken.Value
+	tagName := p.memberTag(tagToken.Value)
 	line := tagToken.Line
 
 	elem := &Element{
@@ -174,15 +397,23 @@ func (p *Parser) parseElement() Node {
 	}
 
 	// Parse attributes
+	last := -1
 	for !p.isAtEnd() && !p.check(TokenTagClose) && !p.check(TokenTagSelfClose) {
+		if p.halted(&last) {
+			break
+		}
 		p.skipWhitespace()
 		if p.check(TokenTagClose) || p.check(TokenTagSelfClose) {
 			break
 		}
 
+		start := p.pos
 		attr := p.parseAttribute()
 		if attr != nil {
 			elem.Attributes = append(elem.Attributes, *attr)
+		} else if p.pos == start {
+			// Not an attribute; skip the token rather than stall on it
+			p.advance()
 		}
 	}
 
@@ -194,66 +425,102 @@ func (p *Parser) parseElement() Node {
 
 	// Opening tag close
 	if !p.match(TokenTagClose) {
-		p.addWarning("Expected > to close tag")
+		p.addWarning(CodeUnclosedTag, "Expected > to close tag")
 		return elem
 	}
 
 	// Parse children
+	p.open = append(p.open, tagName)
+	last = -1
 	for !p.isAtEnd() {
-		p.skipNonSignificantWhitespace()
-
+		if p.halted(&last) {
+			break
+		}
 		// Check for closing tag
 		if p.check(TokenTagEnd) {
 			break
 		}
 
-		child := p.parseNode()
-		if child != nil {
-			elem.Children = append(elem.Children, child)
-		} else {
+		child := p.parseChild()
+		if child == nil {
 			break
 		}
+		if !p.dropped(child) {
+			elem.Children = append(elem.Children, child)
+		}
+	}
+	p.open = p.open[:len(p.open)-1]
+
+	// The closing tag of an enclosing element closes this one too:
+	// <div><p>text</div>
+	if p.check(TokenTagEnd) && p.closesOpen(tagName) {
+		p.unclosed("<"+tagName+">", line)
+		return elem
 	}
 
 	// Parse closing tag
 	if p.match(TokenTagEnd) {
 		p.skipWhitespace()
 		if p.check(TokenIdent) {
-			closingTag := p.advance()
-			if closingTag.Value != tagName {
-				p.addWarning(fmt.Sprintf("Mismatched closing tag: expected </%s>, got </%s>", tagName, closingTag.Value))
+			closingTag := p.memberTag(p.advance().Value)
+			if closingTag != tagName {
+				p.addWarning(CodeMismatchedTag, fmt.Sprintf("Mismatched closing tag: expected </%s>, got </%s>", tagName, closingTag))
 			}
 		}
 		p.skipWhitespace()
 		p.match(TokenTagClose)
+	} else if p.isAtEnd() {
+		p.unclosed("<"+tagName+">", line)
 	}
 
 	return elem
 }
 
+// memberTag reads the rest of a member expression tag name such as
+// ThemeContext.Provider or motion.div, given its first part
+func (p *Parser) memberTag(name string) string {
+	for p.check(TokenDot) && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == TokenIdent {
+		p.advance()
+		name += "." + p.advance().Value
+	}
+	return name
+}
+
 func (p *Parser) parseFragment() Node {
 	frag := &Fragment{
 		Children:   []Node{},
 		LineNumber: p.current().Line,
 	}
 
+	p.open = append(p.open, "")
+	defer func() { p.open = p.open[:len(p.open)-1] }()
+	last := -1
 	for !p.isAtEnd() {
-		p.skipNonSignificantWhitespace()
-
+		if p.halted(&last) {
+			break
+		}
 		// Check for closing </> 
+		if p.check(TokenTagEnd) && p.closesOpen("") {
+			p.unclosed("<>", frag.LineNumber)
+			return frag
+		}
 		if p.check(TokenTagEnd) {
 			p.advance()
 			p.skipWhitespace()
 			p.match(TokenTagClose)
-			break
+			return frag
 		}
 
-		child := p.parseNode()
-		if child != nil {
-			frag.Children = append(frag.Children, child)
-		} else {
+		child := p.parseChild()
+		if child == nil {
 			break
 		}
+		if !p.dropped(child) {
+			frag.Children = append(frag.Children, child)
+		}
+	}
+	if p.isAtEnd() {
+		p.unclosed("<>", frag.LineNumber)
 	}
 
 	return frag
@@ -270,7 +537,11 @@ func (p *Parser) parseAttribute() *Attribute {
 			// Get the identifier being spread
 			var spreadExpr strings.Builder
 			depth := 1
+			last := -1
 			for !p.isAtEnd() && depth > 0 {
+				if p.halted(&last) {
+					break
+				}
 				tok := p.advance()
 				if tok.Type == TokenJSXExprOpen {
 					depth++
@@ -292,6 +563,13 @@ func (p *Parser) parseAttribute() *Attribute {
 		return nil
 	}
 
+	// Alpine.js shorthands: @click, :class
+	prefix := ""
+	if (p.check(TokenColon) || p.check(TokenText) && p.current().Value == "@") &&
+		p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == TokenIdent {
+		prefix = p.advance().Value
+	}
+
 	// Regular attribute
 	if !p.check(TokenIdent) {
 		return nil
@@ -299,7 +577,15 @@ func (p *Parser) parseAttribute() *Attribute {
 
 	nameToken := p.advance()
 	attr := &Attribute{
-		Name: nameToken.Value,
+		Name: prefix + nameToken.Value,
+	}
+	// Namespaced names and modifiers: xlink:href, hx-on::after-request,
+	// x-on:keydown.escape, x-transition.duration.500ms
+	for p.check(TokenColon) || p.check(TokenDot) {
+		attr.Name += p.advance().Value
+		for p.check(TokenIdent) || p.check(TokenNumber) {
+			attr.Name += p.advance().Value
+		}
 	}
 
 	p.skipWhitespace()
@@ -401,6 +687,10 @@ func (p *Parser) parseExpression() Node {
 	}
 
 	expr := p.parseExpressionContent()
+	if expr.Raw == "" {
+		// {/* a comment */} renders nothing; go on to the next child
+		return p.parseNode()
+	}
 
 	// Check for patterns we can translate
 	node := p.analyzeExpression(expr)
@@ -416,7 +706,11 @@ func (p *Parser) parseExpressionContent() Expression {
 	depth := 1
 	startLine := p.current().Line
 
+	last := -1
 	for !p.isAtEnd() && depth > 0 {
+		if p.halted(&last) {
+			break
+		}
 		tok := p.current()
 		if tok.Type == TokenJSXExprOpen {
 			depth++
@@ -427,7 +721,9 @@ func (p *Parser) parseExpressionContent() Expression {
 				break
 			}
 		}
-		content.WriteString(tok.Value)
+		if tok.Type != TokenComment && tok.Type != TokenError {
+			content.WriteString(tok.Value)
+		}
 		p.advance()
 	}
 
@@ -441,24 +737,52 @@ func (p *Parser) parseText() Node {
 	var content strings.Builder
 	startLine := p.current().Line
 
+	last := -1
 	for !p.isAtEnd() {
+		if p.halted(&last) {
+			break
+		}
 		tok := p.current()
 		if tok.Type == TokenTagOpen || tok.Type == TokenTagEnd || tok.Type == TokenJSXExprOpen {
 			break
 		}
-		content.WriteString(tok.Value)
+		if tok.Type != TokenError {
+			content.WriteString(tok.Value)
+		}
 		p.advance()
 	}
 
-	text := strings.TrimSpace(content.String())
-	if text == "" {
-		return nil
+	raw := content.String()
+	text := &Text{
+		Content:    JSXText(raw),
+		LineNumber: startLine + strings.Count(raw[:len(raw)-len(strings.TrimLeft(raw, " \t\r\n"))], "\n"),
+	}
+	if text.Content != raw && p.preformatted() {
+		text.Raw = raw
 	}
+	return text
+}
 
-	return &Text{
-		Content:    text,
-		LineNumber: startLine,
+// JSXText collapses the whitespace of JSX text the way JSX does: lines
+// are trimmed, except before the first and after the last, blank lines
+// are dropped and the rest are joined by single spaces. Whitespace
+// within a line is kept, "Hello " before an element keeps its space and
+// text of only spaces between elements on one line becomes " ".
+func JSXText(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\t", " "), "\n")
+	var out []string
+	for i, line := range lines {
+		if i > 0 {
+			line = strings.TrimLeft(line, " ")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " ")
+		}
+		if line != "" {
+			out = append(out, line)
+		}
 	}
+	return strings.Join(out, " ")
 }
 
 func (p *Parser) parseImport() *Import {
@@ -489,7 +813,11 @@ func (p *Parser) parseImport() *Import {
 	// Named imports { a, b, c }
 	if p.check(TokenJSXExprOpen) {
 		p.advance()
+		last := -1
 		for !p.isAtEnd() && !p.check(TokenJSXExprClose) {
+			if p.halted(&last) {
+				break
+			}
 			p.skipWhitespace()
 			if p.check(TokenIdent) {
 				name := p.advance().Value
@@ -503,6 +831,10 @@ func (p *Parser) parseImport() *Import {
 					}
 				}
 				imp.Named[name] = alias
+			} else if !p.check(TokenComma) && !p.check(TokenJSXExprClose) {
+				// import { A "x" }: skip the token so the loop always
+				// makes progress
+				p.advance()
 			}
 			p.skipWhitespace()
 			p.match(TokenComma)
@@ -520,7 +852,11 @@ func (p *Parser) parseImport() *Import {
 	}
 
 	// Skip to end of statement
+	last := -1
 	for !p.isAtEnd() {
+		if p.halted(&last) {
+			break
+		}
 		tok := p.current()
 		if tok.Type == TokenIdent && (tok.Value == "import" || tok.Value == "export" || tok.Value == "function" || tok.Value == "const") {
 			break
@@ -536,18 +872,40 @@ func (p *Parser) parseComponent() *Component {
 
 	// Handle export
 	isExport := p.matchIdent("export")
+	isDefault := false
 	if isExport {
 		p.skipWhitespace()
-		p.matchIdent("default")
+		isDefault = p.matchIdent("default")
 		p.skipWhitespace()
 	}
 
+	// Server components and data loaders are async functions
+	isAsync := p.matchIdent("async")
+	p.skipWhitespace()
+
 	// function ComponentName or const ComponentName
 	isArrow := false
+	var wrappers []string
 	if p.matchIdent("const") {
 		isArrow = true
-	} else if !p.matchIdent("function") {
-		return nil
+	} else {
+		// export default memo(function Card() {...}), or memo(Card)
+		if isDefault {
+			wrappers = p.componentWrappers()
+		}
+		if !p.matchIdent("function") {
+			// export default ComponentName;
+			if isDefault && p.check(TokenIdent) {
+				p.defaultExport = p.current().Value
+				if len(wrappers) > 0 {
+					if p.wrapped == nil {
+						p.wrapped = map[string][]string{}
+					}
+					p.wrapped[p.defaultExport] = append(p.wrapped[p.defaultExport], wrappers...)
+				}
+			}
+			return nil
+		}
 	}
 
 	p.skipWhitespace()
@@ -557,31 +915,113 @@ func (p *Parser) parseComponent() *Component {
 		return nil
 	}
 	name := p.advance().Value
+	if isExport {
+		p.exports = append(p.exports, name)
+		if isDefault {
+			p.defaultExport = name
+		}
+	}
 
-	// Skip if it doesn't look like a component (starts with lowercase and not a hook)
-	if len(name) > 0 && name[0] >= 'a' && name[0] <= 'z' && !strings.HasPrefix(name, "use") {
+	// CSS-in-JS declaration rather than a component
+	if isArrow {
+		if styled := p.parseStyled(name, startLine); styled != nil {
+			p.styled = append(p.styled, *styled)
+			p.skipToNextStatement()
+			return nil
+		}
+	}
+
+	// const Chart = lazy(() => import('./Chart')) imports Chart
+	if isArrow {
+		if imp := p.parseLazy(name, startLine); imp != nil {
+			p.lazy = append(p.lazy, *imp)
+			p.skipToNextStatement()
+			return nil
+		}
+	}
+
+	// React context: const ThemeContext = createContext('light')
+	if isArrow {
+		if ctx := p.parseCreateContext(name, startLine); ctx != nil {
+			p.contexts = append(p.contexts, *ctx)
+			p.skipToNextStatement()
+			return nil
+		}
+	}
+
+	// Module-level data: const STATUSES = ['open', 'closed']
+	if isArrow {
+		if c := p.parseConstant(name, startLine); c != nil {
+			p.constants = append(p.constants, *c)
+			p.skipToNextStatement()
+			return nil
+		}
+	}
+
+	// Next.js data loaders become Go loading functions
+	if nextLoaders[name] {
+		p.parseDataLoader(name, startLine, isArrow, isAsync)
+		return nil
+	}
+	if routeHandlers[name] {
 		p.skipToNextStatement()
 		return nil
 	}
 
+	// Custom hooks become Go helper functions
+	if isCustomHook(name) {
+		p.parseCustomHook(name, startLine, isArrow)
+		return nil
+	}
+
+	// Not a component (starts with lowercase and not a hook): a plain
+	// function becomes a Go helper function
+	if len(name) > 0 && name[0] >= 'a' && name[0] <= 'z' && !strings.HasPrefix(name, "use") {
+		p.parseHelper(name, startLine, isArrow, isAsync)
+		return nil
+	}
+
 	comp := &Component{
 		Name:       name,
 		Props:      []Prop{},
 		Hooks:      []Hook{},
+		Async:      isAsync,
 		LineNumber: startLine,
 	}
 
 	p.skipWhitespace()
 
-	// Arrow function: = (props) => or = () =>
+	// Arrow function: = (props) => or = async () =>
 	if isArrow {
 		p.match(TokenEquals)
 		p.skipWhitespace()
+		if p.matchIdent("async") {
+			comp.Async = true
+			p.skipWhitespace()
+		}
+		// memo(({ count }) => ...), forwardRef(function Input(props, ref) {...})
+		if wrappers = p.componentWrappers(); len(wrappers) > 0 && p.matchIdent("function") {
+			p.skipWhitespace()
+			if p.check(TokenIdent) {
+				p.advance()
+				p.skipWhitespace()
+			}
+		}
 	}
+	comp.Wrappers = wrappers
 
 	// Props
 	if p.match(TokenLParen) {
 		comp.Props = p.parseProps()
+		p.skipWhitespace()
+		// forwardRef passes the ref as a second parameter
+		if p.match(TokenComma) {
+			p.skipWhitespace()
+			if p.check(TokenIdent) {
+				comp.Ref = p.advance().Value
+				p.skipWhitespace()
+			}
+		}
 		p.match(TokenRParen)
 	}
 
@@ -591,6 +1031,16 @@ func (p *Parser) parseComponent() *Component {
 	if isArrow {
 		p.match(TokenArrow)
 		p.skipWhitespace()
+		// Expression body: => <span /> or => (<span />)
+		start := p.pos
+		if p.match(TokenLParen) {
+			p.skipWhitespace()
+		}
+		if p.check(TokenTagOpen) {
+			comp.Body = p.parseNode()
+			return comp
+		}
+		p.pos = start
 	}
 
 	// Body - find the JSX return
@@ -599,17 +1049,146 @@ func (p *Parser) parseComponent() *Component {
 	return comp
 }
 
+// parseStyled parses the right-hand side of const Name = styled.tag`...`
+// or styled(Base)`...`. It returns nil, leaving the position untouched,
+// if the declaration is not a styled component.
+func (p *Parser) parseStyled(name string, line int) *StyledComponent {
+	start := p.pos
+	p.skipWhitespace()
+	p.match(TokenEquals)
+	p.skipWhitespace()
+
+	// css`...` fragments and keyframes`...` animations
+	if p.checkIdent("css") || p.checkIdent("keyframes") {
+		kind := p.advance().Value
+		if !p.check(TokenTemplate) {
+			p.pos = start
+			return nil
+		}
+		tmpl := p.advance()
+		return &StyledComponent{
+			Name:           name,
+			Kind:           kind,
+			CSS:            tmpl.Value[1 : len(tmpl.Value)-1],
+			Interpolations: tmpl.Exprs,
+			LineNumber:     line,
+		}
+	}
+
+	if !p.matchIdent("styled") {
+		p.pos = start
+		return nil
+	}
+
+	styled := &StyledComponent{Name: name, LineNumber: line}
+	switch {
+	case p.match(TokenDot) && p.check(TokenIdent):
+		styled.Tag = p.advance().Value
+	case p.match(TokenLParen) && p.check(TokenIdent):
+		base := p.advance().Value
+		p.match(TokenRParen)
+		if len(base) > 0 && base[0] >= 'a' && base[0] <= 'z' {
+			styled.Tag = base // styled('div')
+		} else {
+			styled.Base = base
+		}
+	default:
+		p.pos = start
+		return nil
+	}
+
+	// Skip modifiers such as .attrs({...}) up to the template literal
+	last := -1
+	for !p.isAtEnd() {
+		if p.halted(&last) {
+			break
+		}
+		tok := p.current()
+		if tok.Type == TokenTemplate {
+			break
+		}
+		if tok.Type == TokenIdent && (tok.Value == "const" || tok.Value == "export" || tok.Value == "function") {
+			p.pos = start
+			return nil
+		}
+		if tok.Type == TokenIdent && tok.Value == "attrs" {
+			if attrs := p.staticAttrs(); attrs != nil {
+				styled.Attrs = attrs
+			} else {
+				styled.Interpolations = append(styled.Interpolations, "attrs(...)")
+			}
+		}
+		p.advance()
+	}
+	if p.isAtEnd() {
+		p.pos = start
+		return nil
+	}
+
+	tmpl := p.advance()
+	styled.CSS = tmpl.Value[1 : len(tmpl.Value)-1]
+	styled.Interpolations = append(styled.Interpolations, tmpl.Exprs...)
+	return styled
+}
+
+// staticAttrs reads the object passed to .attrs(...) at the current
+// position. It returns nil unless every value is a literal: attrs computed
+// from props (props => ({...})) cannot be applied statically.
+func (p *Parser) staticAttrs() map[string]string {
+	i := p.pos + 1
+	for i < len(p.tokens) && p.tokens[i].Type == TokenWhitespace {
+		i++
+	}
+	if p.source == "" || i >= len(p.tokens) || p.tokens[i].Type != TokenLParen {
+		return nil
+	}
+	e, _, err := ParseJSExprPrefix(p.source[p.tokens[i].Offset:])
+	if err != nil {
+		return nil
+	}
+	obj, ok := e.(*JSObject)
+	if !ok {
+		return nil
+	}
+	attrs := make(map[string]string, len(obj.Props))
+	for _, prop := range obj.Props {
+		lit, ok := prop.Value.(*JSLiteral)
+		if prop.Key == "" || prop.Shorthand || !ok {
+			return nil
+		}
+		switch lit.Kind {
+		case "string", "number", "bool":
+			attrs[prop.Key] = lit.Value
+		default:
+			return nil
+		}
+	}
+	return attrs
+}
+
 func (p *Parser) parseProps() []Prop {
 	var props []Prop
 	p.skipWhitespace()
 
 	// Destructured props: { prop1, prop2 }
 	if p.match(TokenJSXExprOpen) {
+		last := -1
 		for !p.isAtEnd() && !p.check(TokenJSXExprClose) {
+			if p.halted(&last) {
+				break
+			}
 			p.skipWhitespace()
 			if p.check(TokenIdent) {
 				prop := Prop{Name: p.advance().Value}
 				p.skipWhitespace()
+				// Renamed prop: label: heading (the body uses heading)
+				if p.match(TokenColon) {
+					p.skipWhitespace()
+					if p.check(TokenIdent) {
+						prop.Alias = p.advance().Value
+						p.skipWhitespace()
+					}
+				}
 				// Default value: prop = 'default'
 				if p.match(TokenEquals) {
 					p.skipWhitespace()
@@ -619,7 +1198,11 @@ func (p *Parser) parseProps() []Prop {
 						// Skip complex default value
 						depth := 0
 						var val strings.Builder
+						last := -1
 						for !p.isAtEnd() {
+							if p.halted(&last) {
+								break
+							}
 							tok := p.current()
 							if tok.Type == TokenJSXExprOpen || tok.Type == TokenLParen {
 								depth++
@@ -638,6 +1221,10 @@ func (p *Parser) parseProps() []Prop {
 					}
 				}
 				props = append(props, prop)
+			} else if !p.check(TokenComma) && !p.check(TokenJSXExprClose) {
+				// ...rest or a nested pattern: skip the token so the loop
+				// always makes progress
+				p.advance()
 			}
 			p.skipWhitespace()
 			p.match(TokenComma)
@@ -656,7 +1243,11 @@ func (p *Parser) parseComponentBody(comp *Component) Node {
 	depth := 0
 	foundReturn := false
 
+	last := -1
 	for !p.isAtEnd() {
+		if p.halted(&last) {
+			break
+		}
 		tok := p.current()
 
 		if tok.Type == TokenJSXExprOpen || (tok.Type == TokenIdent && tok.Value == "{") {
@@ -689,6 +1280,12 @@ func (p *Parser) parseComponentBody(comp *Component) Node {
 			if p.check(TokenTagOpen) {
 				return p.parseNode()
 			}
+
+			// return createPortal(<div>...</div>, document.body)
+			if body, portal := p.parsePortal(); portal != nil {
+				comp.Portal = portal
+				return body
+			}
 		}
 
 		p.advance()
@@ -710,6 +1307,7 @@ func (p *Parser) detectHook(name string) *Hook {
 
 	hook := &Hook{
 		Type:       name,
+		Name:       p.assignedName(),
 		LineNumber: p.current().Line,
 	}
 
@@ -732,40 +1330,183 @@ func (p *Parser) detectHook(name string) *Hook {
 	return hook
 }
 
+// referencesIdent reports whether name appears as an identifier on the
+// 1-based source lines [start, end), other than on its declaration line
+func referencesIdent(lines []string, start, end int, name string, decl int) bool {
+	ref := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for i := start - 1; i < end-1 && i < len(lines); i++ {
+		if i >= 0 && i != decl-1 && ref.MatchString(lines[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// useStateHead matches the start of a useState declaration; the
+// initial value is read with the expression parser
+var useStateHead = regexp.MustCompile(`\b(?:const|let|var)\s+\[\s*(\w+)\s*,\s*(\w+)\s*\]\s*=\s*(?:React\.)?useState\b`)
+
 // extractUseStateVars scans source for useState patterns and extracts StateVariables
 func extractUseStateVars(source string) []StateVariable {
 	var stateVars []StateVariable
-	
-	// Pattern: const [varName, setVarName] = useState(initValue)
-	// Also handles: const [varName, setVarName] = useState<Type>(initValue)
-	pattern := regexp.MustCompile(`const\s+\[(\w+),\s*(\w+)\]\s*=\s*useState(?:<[^>]+>)?\s*\(([^)]*)\)`)
-	
-	matches := pattern.FindAllStringSubmatchIndex(source, -1)
-	for _, match := range matches {
-		if len(match) >= 8 {
-			varName := source[match[2]:match[3]]
-			setterName := source[match[4]:match[5]]
-			initValue := strings.TrimSpace(source[match[6]:match[7]])
-			
-			// Infer type from initial value
-			initType := inferTypeFromValue(initValue)
-			
-			// Calculate line number
-			lineNum := 1 + strings.Count(source[:match[0]], "\n")
-			
-			stateVars = append(stateVars, StateVariable{
-				Name:       varName,
-				Setter:     setterName,
-				InitValue:  initValue,
-				InitType:   initType,
-				LineNumber: lineNum,
-			})
+
+	// const [varName, setVarName] = useState(initValue)
+	// const [varName, setVarName] = useState<Type>(initValue)
+	for _, match := range useStateHead.FindAllStringSubmatchIndex(source, -1) {
+		open := skipTypeArguments(source, match[1])
+		if open >= len(source) || source[open] != '(' {
+			continue
+		}
+		end := skipBalanced(source, open)
+		if end < 0 {
+			continue
 		}
+		initValue := strings.TrimSpace(source[open+1 : end-1])
+		varName := source[match[2]:match[3]]
+		setterName := source[match[4]:match[5]]
+
+		stateVars = append(stateVars, StateVariable{
+			Name:       varName,
+			Setter:     setterName,
+			InitValue:  initValue,
+			InitType:   inferInitType(initValue),
+			Persistent: isPersistentState(varName, setterName, source),
+			LineNumber: 1 + strings.Count(source[:match[0]], "\n"),
+		})
 	}
-	
+
 	return stateVars
 }
 
+// skipTypeArguments moves past whitespace and a TypeScript <...> type
+// argument list at i, returning the offset of what follows
+func skipTypeArguments(source string, i int) int {
+	i = skipJSSpace(source, i)
+	if i >= len(source) || source[i] != '<' {
+		return i
+	}
+	depth := 0
+	for ; i < len(source); i++ {
+		switch source[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				return skipJSSpace(source, i+1)
+			}
+		}
+	}
+	return i
+}
+
+// inferInitType guesses the Go type of a useState initial value, looking
+// through lazy initializers such as useState(() => [])
+func inferInitType(initValue string) string {
+	e, err := ParseJSExpr(initValue)
+	if err != nil {
+		return inferTypeFromValue(initValue)
+	}
+	return inferJSType(initValue, e)
+}
+
+func inferJSType(src string, e JSExpr) string {
+	switch n := Unparen(e).(type) {
+	case *JSLiteral:
+		switch n.Kind {
+		case "string":
+			return "string"
+		case "bool":
+			return "bool"
+		case "null", "undefined":
+			return "interface{}"
+		}
+	case *JSTemplate:
+		if n.Tag == nil {
+			return "string"
+		}
+	case *JSUnary:
+		switch n.Op {
+		case "!":
+			return "bool"
+		case "-", "+":
+			if lit, ok := Unparen(n.X).(*JSLiteral); ok && lit.Kind == "number" {
+				return inferTypeFromValue(lit.Value)
+			}
+		}
+	case *JSBinary:
+		switch n.Op {
+		case "==", "!=", "===", "!==", "<", ">", "<=", ">=":
+			return "bool"
+		case "||", "??":
+			// stored || 'default': the type of the fallback
+			if lit, ok := Unparen(n.Right).(*JSLiteral); ok && lit.Kind != "null" && lit.Kind != "undefined" {
+				return inferJSType(src, lit)
+			}
+		}
+	case *JSArray:
+		return "[]interface{}"
+	case *JSObject:
+		return "map[string]interface{}"
+	case *JSArrow:
+		// Lazy initializer: the type of what the function returns
+		if n.Body != nil {
+			return inferJSType(src, n.Body)
+		}
+		if i := lastReturn(n.Block); i >= 0 {
+			if ret, _, err := ParseJSExprPrefix(n.Block[i+len("return"):]); err == nil {
+				return inferJSType(n.Block[i+len("return"):], ret)
+			}
+		}
+		return "interface{}"
+	}
+	return inferTypeFromValue(e.Span().Text(src))
+}
+
+// persistentStateNames are name fragments of state that users expect to
+// survive navigation and reloads
+var persistentStateNames = []string{
+	"cart", "basket", "filter", "preference", "prefs", "theme", "darkmode",
+	"locale", "language", "currency", "sort", "favorite", "favourite",
+	"wishlist", "settings", "pagesize", "perpage",
+}
+
+// isPersistentState reports whether a state variable holds per-visitor
+// state that has to persist across requests: either its name says so, or
+// the component already mirrors it into localStorage/sessionStorage.
+func isPersistentState(name, setter, source string) bool {
+	lower := strings.ToLower(name)
+	for _, fragment := range persistentStateNames {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	for _, line := range strings.Split(source, "\n") {
+		if (strings.Contains(line, "localStorage.") || strings.Contains(line, "sessionStorage.")) &&
+			(strings.Contains(line, name) || strings.Contains(line, setter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestPersistentState replaces the generic useState hint with the
+// session accessors for state that has to persist across requests
+func (p *Parser) suggestPersistentState(stateVars []StateVariable) {
+	for i := range p.suggestions {
+		s := &p.suggestions[i]
+		if s.PatternType != "useState" {
+			continue
+		}
+		for _, sv := range stateVars {
+			if sv.LineNumber == s.Line && sv.Persistent {
+				s.ReactCode = sv.Name
+				s.MintyHint = fmt.Sprintf("Persist per visitor: session.Get[T](r, %q) to read, session.Set(w, r, %q, v) to write", sv.Name, sv.Name)
+			}
+		}
+	}
+}
+
 // inferTypeFromValue guesses Go type from JS initial value
 func inferTypeFromValue(val string) string {
 	val = strings.TrimSpace(val)
@@ -844,133 +1585,204 @@ func isSimpleIdent(s string) bool {
 	return true
 }
 
+// derivedOperations are the array methods that derive a variable from
+// a collection, with the Go type of their result
+var derivedOperations = map[string]string{
+	"filter": "[]interface{}",
+	"map":    "[]interface{}",
+	"find":   "interface{}",
+	"some":   "bool",
+	"every":  "bool",
+	"reduce": "interface{}",
+	"sort":   "[]interface{}",
+	"slice":  "[]interface{}",
+}
+
+// declarationHead matches the start of a const or let declaration
+var declarationHead = regexp.MustCompile(`\b(?:const|let)\s+(\w+)\s*=\s*`)
+
 // extractDerivedVars scans source for derived state patterns
 // e.g., const filteredUsers = users.filter(user => ...)
 func extractDerivedVars(source string, stateVars []StateVariable) []DerivedVariable {
 	var derivedVars []DerivedVariable
-	
+
 	// Build set of known state var names for dependency tracking
 	stateNames := make(map[string]bool)
 	for _, sv := range stateVars {
 		stateNames[sv.Name] = true
 	}
-	
-	// Patterns for array operations:
-	// const x = y.filter(...) | .map(...) | .find(...) | .some(...) | .every(...) | .reduce(...) | .sort(...)
-	patterns := []struct {
-		regex    *regexp.Regexp
-		opType   string
-		resultType string
-	}{
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.filter\s*\(`),
-			"filter",
-			"[]interface{}",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.map\s*\(`),
-			"map",
-			"[]interface{}",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.find\s*\(`),
-			"find",
-			"interface{}",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.some\s*\(`),
-			"some",
-			"bool",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.every\s*\(`),
-			"every",
-			"bool",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.reduce\s*\(`),
-			"reduce",
-			"interface{}",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.sort\s*\(`),
-			"sort",
-			"[]interface{}",
-		},
-		{
-			regexp.MustCompile(`const\s+(\w+)\s*=\s*(\w+)\.slice\s*\(`),
-			"slice",
-			"[]interface{}",
-		},
-	}
-	
-	for _, p := range patterns {
-		matches := p.regex.FindAllStringSubmatchIndex(source, -1)
-		for _, match := range matches {
-			if len(match) >= 6 {
-				varName := source[match[2]:match[3]]
-				sourceName := source[match[4]:match[5]]
-				
-				// Skip if this is a useState destructuring (already handled)
-				if strings.Contains(source[max(0, match[0]-20):match[0]], "[") {
-					continue
-				}
-				
-				// Find the full expression (up to the matching closing paren)
-				exprStart := match[0]
-				exprEnd := findMatchingParen(source, match[5])
-				fullExpr := ""
-				if exprEnd > match[5] {
-					fullExpr = source[exprStart:exprEnd]
-				}
-				
-				// Calculate line number
-				lineNum := 1 + strings.Count(source[:match[0]], "\n")
-				
-				// Find dependencies - which state vars are referenced in the expression
-				var deps []string
-				for stateName := range stateNames {
-					// Check if state var is used in the expression
-					if strings.Contains(fullExpr, stateName) {
-						deps = append(deps, stateName)
-					}
-				}
-				// Also add source collection if it's a state var
-				if stateNames[sourceName] {
-					deps = append(deps, sourceName)
-				}
-				
-				derivedVars = append(derivedVars, DerivedVariable{
-					Name:       varName,
-					Expression: fullExpr,
-					SourceVar:  sourceName,
-					Operation:  p.opType,
-					ResultType: p.resultType,
-					DependsOn:  deps,
-					LineNumber: lineNum,
-				})
+
+	for _, match := range declarationHead.FindAllStringSubmatchIndex(source, -1) {
+		init, n, err := ParseJSExprPrefix(source[match[1]:])
+		if err != nil {
+			continue
+		}
+		// Dependencies: the state variables the expression reads
+		var deps []string
+		for _, name := range JSIdentifiers(init) {
+			if stateNames[name] {
+				deps = append(deps, name)
+			}
+		}
+
+		call, op, sourceName := derivedBase(init)
+		if call == nil {
+			// A value computed from state: count * 2, or a Solid memo
+			if len(deps) == 0 || !derivedValue(init) {
+				continue
 			}
+			op = "value"
 		}
+		typ, ok := derivedOperations[op]
+		if !ok {
+			typ = "interface{}"
+		}
+
+		derivedVars = append(derivedVars, DerivedVariable{
+			Name:       source[match[2]:match[3]],
+			Expression: strings.TrimSpace(source[match[0] : match[1]+n]),
+			SourceVar:  sourceName,
+			Operation:  op,
+			ResultType: typ,
+			DependsOn:  deps,
+			LineNumber: 1 + strings.Count(source[:match[0]], "\n"),
+		})
 	}
-	
+
 	return derivedVars
 }
 
-// findMatchingParen finds the position after the matching closing paren
-func findMatchingParen(s string, start int) int {
-	depth := 1
-	for i := start; i < len(s); i++ {
-		switch s[i] {
-		case '(':
-			depth++
-		case ')':
-			depth--
-			if depth == 0 {
-				return i + 1
+// derivedValue reports whether an expression computes a value with
+// operators alone: count * 2, done ? 'Done' : `${left} left`
+func derivedValue(e JSExpr) bool {
+	switch Unparen(e).(type) {
+	case *JSBinary, *JSUnary, *JSConditional, *JSTemplate:
+	default:
+		return false
+	}
+	plain := true
+	WalkJS(e, func(x JSExpr) bool {
+		switch n := x.(type) {
+		case *JSIdent, *JSLiteral, *JSBinary, *JSUnary, *JSConditional, *JSParen:
+		case *JSTemplate:
+			plain = plain && n.Tag == nil
+		case *JSMember:
+			plain = plain && n.Index == nil
+		default:
+			plain = false
+		}
+		return plain
+	})
+	return plain
+}
+
+// derivedBase finds the first array operation applied to a plain
+// variable in a chain of calls and property accesses: for
+// users.filter(...).sort(...) or users.filter(...).length it returns the
+// filter call, "filter" and "users"
+func derivedBase(e JSExpr) (*JSCall, string, string) {
+	for {
+		if m, ok := e.(*JSMember); ok && m.Index == nil {
+			e = m.Object
+			continue
+		}
+		call, ok := e.(*JSCall)
+		if !ok {
+			return nil, "", ""
+		}
+		m, ok := call.Callee.(*JSMember)
+		if !ok || m.Index != nil {
+			return nil, "", ""
+		}
+		if ident, ok := m.Object.(*JSIdent); ok {
+			if _, known := derivedOperations[m.Property]; known {
+				return call, m.Property, ident.Name
+			}
+			return nil, "", ""
+		}
+		e = m.Object
+	}
+}
+
+// DerivedInit returns the expression a derived variable is initialized
+// with, and its source: count * 2 for const doubled = count * 2
+func DerivedInit(dv DerivedVariable) (JSExpr, string, bool) {
+	loc := declarationHead.FindStringIndex(dv.Expression)
+	if loc == nil {
+		return nil, "", false
+	}
+	src := strings.TrimSuffix(strings.TrimSpace(dv.Expression[loc[1]:]), ";")
+	init, err := ParseJSExpr(src)
+	if err != nil {
+		return nil, "", false
+	}
+	return init, src, true
+}
+
+// DerivedCallback returns the parameters and expression body of the
+// callback a derived variable passes to its operation: u and u.active for
+// users.filter(u => u.active). ok is false for a callback given by name
+// or with a block body.
+func DerivedCallback(dv DerivedVariable) (params []string, body string, ok bool) {
+	loc := declarationHead.FindStringIndex(dv.Expression)
+	if loc == nil {
+		return nil, "", false
+	}
+	src := dv.Expression[loc[1]:]
+	init, err := ParseJSExpr(src)
+	if err != nil {
+		return nil, "", false
+	}
+	call, _, _ := derivedBase(init)
+	if call == nil || len(call.Args) == 0 {
+		return nil, "", false
+	}
+	arrow, isArrow := call.Args[0].(*JSArrow)
+	if !isArrow || arrow.Body == nil {
+		return nil, "", false
+	}
+	return destructuredParams(dv.SourceVar, arrow.Params, arrow.Body.Span().Text(src))
+}
+
+// chainedOperations are the array methods a derived variable may chain
+var chainedOperations = map[string]bool{"reverse": true}
+
+func init() {
+	for op := range derivedOperations {
+		chainedOperations[op] = true
+	}
+}
+
+// DerivedChain returns every array operation a derived variable applies
+// to its source, in order, and whether it ends reading .length:
+// users.filter(...).sort(...).slice(0, 3) gives all three. ok is false
+// when anything else is chained.
+func DerivedChain(dv DerivedVariable) (ops []ArrayOp, length bool, ok bool) {
+	loc := declarationHead.FindStringIndex(dv.Expression)
+	if loc == nil {
+		return nil, false, false
+	}
+	src := dv.Expression[loc[1]:]
+	init, err := ParseJSExpr(src)
+	if err != nil {
+		return nil, false, false
+	}
+	e := Unparen(init)
+	if m, isMember := e.(*JSMember); isMember && m.Index == nil && m.Property == "length" {
+		length, e = true, m.Object
+	}
+	ops, base := methodChain(src, e, chainedOperations)
+	if id, isIdent := Unparen(base).(*JSIdent); !isIdent || id.Name != dv.SourceVar || len(ops) == 0 {
+		return nil, false, false
+	}
+	for i, op := range ops {
+		if op.Params != nil {
+			if ops[i].Params, ops[i].Body, ok = destructuredParams(dv.SourceVar, op.Params, op.Body); !ok {
+				return nil, false, false
 			}
 		}
 	}
-	return -1
+	return ops, length, true
 }
 
 func max(a, b int) int {
@@ -980,183 +1792,257 @@ func max(a, b int) int {
 	return b
 }
 
+// analyzeExpression recognizes the expressions the generator translates
+// into minty control flow: .map() iterations, && conditionals and
+// ternaries. Anything else stays a plain expression.
 func (p *Parser) analyzeExpression(expr Expression) Node {
-	raw := expr.Raw
+	e, err := ParseJSExpr(expr.Raw)
+	if err != nil {
+		return nil
+	}
+	return p.analyzeJS(expr.Raw, e, expr.LineNumber)
+}
 
-	// Detect .map() pattern
-	mapRegex := regexp.MustCompile(`^(\w+(?:\.\w+)*)\.map\s*\(\s*\(?\s*(\w+)(?:\s*,\s*(\w+))?\s*\)?\s*=>\s*`)
-	if matches := mapRegex.FindStringSubmatch(raw); matches != nil {
-		collection := matches[1]
-		itemVar := matches[2]
-		indexVar := ""
-		if len(matches) > 3 && matches[3] != "" {
-			indexVar = matches[3]
+func (p *Parser) analyzeJS(src string, e JSExpr, line int) Node {
+	switch n := Unparen(e).(type) {
+	case *JSCall:
+		return p.mapIteration(src, n, line)
+	case *JSBinary:
+		if n.Op != "&&" {
+			return nil
 		}
-
-		// Find the JSX body after the arrow
-		bodyStart := mapRegex.FindStringIndex(raw)[1]
-		bodyRaw := raw[bodyStart:]
-
-		// Strip leading whitespace
-		bodyRaw = strings.TrimLeft(bodyRaw, " \t\n\r")
-
-		// If body starts with '(', find matching ')' and extract content
-		if strings.HasPrefix(bodyRaw, "(") {
-			bodyRaw = bodyRaw[1:] // skip opening paren
-			// Find matching closing paren
-			depth := 1
-			for i, ch := range bodyRaw {
-				if ch == '(' {
-					depth++
-				} else if ch == ')' {
-					depth--
-					if depth == 0 {
-						bodyRaw = bodyRaw[:i]
-						break
-					}
-				}
-			}
+		return &Conditional{
+			Condition:  strings.TrimSpace(n.Left.Span().Text(src)),
+			Consequent: p.branch(src, n.Right, line),
+			LineNumber: line,
 		}
+	case *JSConditional:
+		return &Ternary{
+			Condition:  strings.TrimSpace(n.Test.Span().Text(src)),
+			Consequent: p.branch(src, n.Then, line),
+			Alternate:  p.branch(src, n.Else, line),
+			LineNumber: line,
+		}
+	}
+	return nil
+}
 
-		// Strip trailing closing parens from map call
-		bodyRaw = strings.TrimRight(bodyRaw, " \t\n\r)")
-
-		// Parse the body as JSX
-		bodyLexer := NewLexer(bodyRaw)
-		bodyTokens := bodyLexer.Tokenize()
-		bodyParser := NewParser(bodyTokens)
-		body := bodyParser.ParseJSX()
+// mapIteration turns collection.map((item, index) => <jsx/>) into a
+// MapExpr; the collection must be a variable or property path, or an
+// object or count iteratedSource recognizes
+func (p *Parser) mapIteration(src string, call *JSCall, line int) Node {
+	var chain []ArrayOp
+	var base, callback JSExpr
+	if _, recv, ok := MethodCall(call, "map"); ok && len(call.Args) > 0 {
+		chain, base = arrayChain(src, recv)
+		callback = call.Args[0]
+	} else if MemberPath(call.Callee) == "Array.from" && len(call.Args) == 2 {
+		// Array.from(source, fn) maps the items as it makes the array
+		base, callback = &JSCall{JSSpan: call.JSSpan, Callee: call.Callee, Args: call.Args[:1]}, call.Args[1]
+	} else {
+		return nil
+	}
+	m := &MapExpr{
+		Collection: MemberPath(base),
+		Chain:      chain,
+		LineNumber: line,
+	}
+	counted := false
+	if m.Collection == "" {
+		m.Collection, m.Object, m.Length, counted = iteratedSource(src, base)
+	}
+	fn, ok := callback.(*JSArrow)
+	if m.Collection == "" && m.Length == "" || !ok || len(fn.Params) == 0 {
+		return nil
+	}
+	if len(chain) > 0 && (m.Object != "" || m.Length != "") {
+		return nil
+	}
+	// A destructured item, ({ id, name }) => ..., is named and its
+	// fields read from it: item.id, item.name
+	item := strings.TrimSpace(fn.Params[0])
+	fields, destructured := destructuredFields(item)
+	entry, isEntry := arrayPattern(item)
+	switch {
+	case m.Object == "entries":
+		if !isEntry || len(entry) > 2 {
+			return nil
+		}
+		item = entry[0]
+		if len(entry) > 1 {
+			m.ValueVar = entry[1]
+		}
+	case m.Length != "":
+		// The items of a count are undefined, or the index for
+		// [...Array(5).keys()]
+		if counted {
+			m.IndexVar = item
+		}
+		item, fields, destructured = "_", nil, false
+	case !destructured && !isSimpleIdent(item):
+		return nil
+	}
+	m.ItemVar = item
+	for i, op := range m.Chain {
+		if op.Params != nil {
+			m.Chain[i].Params, m.Chain[i].Body, _ = destructuredParams(m.Collection, op.Params, op.Body)
+		}
+	}
+	if len(fn.Params) > 1 && isSimpleIdent(fn.Params[1]) && m.IndexVar == "" {
+		m.IndexVar = fn.Params[1]
+	}
+	taken := map[string]bool{m.IndexVar: true, m.ValueVar: true}
+	for _, name := range JSIdentifiers(fn) {
+		taken[name] = true
+	}
+	for local := range fields {
+		taken[local] = true
+	}
+	if destructured {
+		m.ItemVar = itemName(m.Collection, taken)
+	}
+	bound := m.ItemVar
+	// The values of an object are read by key, which the callback has
+	// no name for
+	if m.Object == "values" {
+		m.ValueVar, m.ItemVar = m.ItemVar, ""
+	}
+	if m.Object != "" && m.ItemVar == "" {
+		m.ItemVar = keyName(taken)
+	}
 
-		return &MapExpr{
-			Collection: collection,
-			ItemVar:    itemVar,
-			IndexVar:   indexVar,
-			Body:       body,
-			LineNumber: expr.LineNumber,
+	body, bodySrc, bodyLine := fn.Body, src, line
+	if body == nil {
+		// Block body: render what the last return statement returns
+		i := lastReturn(fn.Block)
+		if i < 0 {
+			return m
 		}
+		bodySrc = fn.Block[i+len("return"):]
+		if at := strings.Index(fn.Text(src), fn.Block); at >= 0 {
+			bodyLine += strings.Count(src[:fn.Start+at+i+len("return")], "\n")
+		}
+		e, _, err := ParseJSExprPrefix(bodySrc)
+		if err != nil {
+			return m
+		}
+		body = e
+	}
+	m.Body = p.branch(bodySrc, body, bodyLine)
+	if destructured {
+		bindFields(m.Body, fields, bound)
+	}
+	if elem, ok := m.Body.(*Element); ok {
+		m.Key = elementKey(elem)
 	}
+	return m
+}
 
-	// Detect && conditional pattern
-	andRegex := regexp.MustCompile(`^(.+?)\s*&&\s*`)
-	if matches := andRegex.FindStringSubmatch(raw); matches != nil {
-		condition := strings.TrimSpace(matches[1])
-		bodyStart := andRegex.FindStringIndex(raw)[1]
-		bodyRaw := strings.TrimSpace(raw[bodyStart:])
-		
-		// Strip outer parentheses if present
-		bodyRaw = stripOuterParens(bodyRaw)
+// chainMethods are the array methods a collection can go through before
+// it is mapped
+var chainMethods = map[string]bool{"filter": true, "sort": true, "slice": true, "reverse": true}
 
-		bodyLexer := NewLexer(bodyRaw)
-		bodyTokens := bodyLexer.Tokenize()
-		bodyParser := NewParser(bodyTokens)
-		body := bodyParser.ParseJSX()
+// arrayChain splits the receiver of a .map() into the collection and the
+// array methods chained on it: items.filter(f).slice(0, 5) gives items
+// and the filter and slice. A callback with a block body, or given by
+// name, is kept with no params.
+func arrayChain(src string, e JSExpr) ([]ArrayOp, JSExpr) {
+	return methodChain(src, e, chainMethods)
+}
 
-		return &Conditional{
-			Condition:  condition,
-			Consequent: body,
-			LineNumber: expr.LineNumber,
-		}
-	}
-
-	// Detect ternary pattern
-	// This is tricky because ? and : can appear in nested expressions
-	// Simplified detection for common cases
-	ternaryRegex := regexp.MustCompile(`^([^?]+)\s*\?\s*`)
-	if matches := ternaryRegex.FindStringSubmatch(raw); matches != nil {
-		condition := strings.TrimSpace(matches[1])
-		rest := raw[ternaryRegex.FindStringIndex(raw)[1]:]
-
-		// Find the : separator (accounting for nesting)
-		colonIdx := findTernaryColon(rest)
-		if colonIdx > 0 {
-			consequentRaw := strings.TrimSpace(rest[:colonIdx])
-			alternateRaw := strings.TrimSpace(rest[colonIdx+1:])
-
-			// Strip outer parentheses if present
-			consequentRaw = stripOuterParens(consequentRaw)
-			alternateRaw = stripOuterParens(alternateRaw)
-
-			// Parse consequent - check if it's a .map() expression first
-			var consequent Node
-			if isMapExpression(consequentRaw) {
-				consequent = p.analyzeExpression(Expression{Raw: consequentRaw, LineNumber: expr.LineNumber})
-			} else {
-				consequentLexer := NewLexer(consequentRaw)
-				consequentParser := NewParser(consequentLexer.Tokenize())
-				consequent = consequentParser.ParseJSX()
+// methodChain splits e into the receiver and the calls of methods
+// chained on it
+func methodChain(src string, e JSExpr, methods map[string]bool) ([]ArrayOp, JSExpr) {
+	var chain []ArrayOp
+	for {
+		call, ok := Unparen(e).(*JSCall)
+		if !ok || call.New {
+			return chain, e
+		}
+		m, ok := call.Callee.(*JSMember)
+		if !ok || m.Index != nil || !methods[m.Property] {
+			return chain, e
+		}
+		op := ArrayOp{Method: m.Property, Raw: strings.TrimLeft(src[m.Object.Span().End:call.End], "?. \t\n")}
+		fn, isArrow := Unparen(firstArg(call)).(*JSArrow)
+		switch {
+		case isArrow && fn.Body != nil && len(fn.Params) > 0:
+			for _, p := range fn.Params {
+				op.Params = append(op.Params, strings.TrimSpace(p))
 			}
-
-			// Parse alternate - check if it's a .map() expression first
-			var alternate Node
-			if isMapExpression(alternateRaw) {
-				alternate = p.analyzeExpression(Expression{Raw: alternateRaw, LineNumber: expr.LineNumber})
-			} else {
-				alternateLexer := NewLexer(alternateRaw)
-				alternateParser := NewParser(alternateLexer.Tokenize())
-				alternate = alternateParser.ParseJSX()
+			op.Body = strings.TrimSpace(fn.Body.Span().Text(src))
+			fallthrough
+		case isArrow:
+			for _, arg := range call.Args[1:] {
+				op.Args = append(op.Args, strings.TrimSpace(arg.Span().Text(src)))
 			}
-
-			return &Ternary{
-				Condition:  condition,
-				Consequent: consequent,
-				Alternate:  alternate,
-				LineNumber: expr.LineNumber,
+		default:
+			for _, arg := range call.Args {
+				op.Args = append(op.Args, strings.TrimSpace(arg.Span().Text(src)))
 			}
 		}
+		chain = append([]ArrayOp{op}, chain...)
+		e = m.Object
 	}
-
-	return nil
 }
 
-// isMapExpression checks if the string looks like a .map() expression
-func isMapExpression(s string) bool {
-	return regexp.MustCompile(`^\w+(?:\.\w+)*\.map\s*\(`).MatchString(s)
+// firstArg returns the first argument of a call, or nil
+func firstArg(call *JSCall) JSExpr {
+	if len(call.Args) == 0 {
+		return nil
+	}
+	return call.Args[0]
 }
 
-// stripOuterParens removes outer parentheses from a string if balanced
-func stripOuterParens(s string) string {
-	s = strings.TrimSpace(s)
-	if !strings.HasPrefix(s, "(") {
-		return s
-	}
-	
-	// Check if the outer parens are balanced
-	depth := 0
-	for i, ch := range s {
-		if ch == '(' {
-			depth++
-		} else if ch == ')' {
-			depth--
-			if depth == 0 {
-				// If we hit depth 0 before the end, parens aren't outer
-				if i < len(s)-1 {
-					return s
-				}
-				// Strip the outer parens
-				return strings.TrimSpace(s[1 : len(s)-1])
-			}
+// elementKey returns the key of an element as an expression: item.id,
+// or 'row' for key="row"
+func elementKey(elem *Element) string {
+	for _, attr := range elem.Attributes {
+		if attr.Name != "key" {
+			continue
+		}
+		if raw := strings.TrimSpace(attr.Expression.Raw); raw != "" {
+			return raw
 		}
+		return "'" + attr.Value + "'"
 	}
-	return s
+	return ""
 }
 
-func findTernaryColon(s string) int {
-	depth := 0
-	for i, ch := range s {
-		switch ch {
-		case '(', '[', '{':
-			depth++
-		case ')', ']', '}':
-			depth--
-		case ':':
-			if depth == 0 {
-				return i
-			}
-		case '?':
-			depth++ // nested ternary
+var returnKeyword = regexp.MustCompile(`\breturn\b`)
+
+// lastReturn returns the offset of the last return keyword in a block
+func lastReturn(block string) int {
+	locs := returnKeyword.FindAllStringIndex(block, -1)
+	if len(locs) == 0 {
+		return -1
+	}
+	return locs[len(locs)-1][0]
+}
+
+// branch converts the JSX-producing part of a conditional or iteration:
+// JSX is parsed as markup, string literals become text, null and false
+// render nothing, and nested control flow is analyzed in turn. line is
+// the line src starts on.
+func (p *Parser) branch(src string, e JSExpr, line int) Node {
+	e = Unparen(e)
+	switch n := e.(type) {
+	case *JSXNode:
+		// Pad the markup to its line, so that its elements keep their lines
+		pad := strings.Repeat("\n", max(line-1, 0)+strings.Count(src[:n.Start], "\n"))
+		return NewParser(NewLexer(pad + n.Raw).Tokenize()).ParseJSX()
+	case *JSLiteral:
+		switch {
+		case n.Kind == "string":
+			return &Text{Content: n.Value, LineNumber: line + strings.Count(src[:n.Start], "\n")}
+		case n.Kind == "null", n.Kind == "undefined", n.Kind == "bool" && n.Value == "false":
+			return nil
 		}
 	}
-	return -1
+	if node := p.analyzeJS(src, e, line); node != nil {
+		return node
+	}
+	return &Expression{Raw: strings.TrimSpace(e.Span().Text(src)), LineNumber: line}
 }
 
 // Helper methods
@@ -1177,7 +2063,7 @@ func (p *Parser) advance() Token {
 }
 
 func (p *Parser) isAtEnd() bool {
-	return p.pos >= len(p.tokens) || p.tokens[p.pos].Type == TokenEOF
+	return p.pos >= len(p.tokens) || p.limit > 0 && p.pos >= p.limit || p.tokens[p.pos].Type == TokenEOF
 }
 
 func (p *Parser) check(typ TokenType) bool {
@@ -1206,7 +2092,7 @@ func (p *Parser) matchIdent(value string) bool {
 }
 
 func (p *Parser) skipWhitespace() {
-	for p.check(TokenWhitespace) {
+	for p.check(TokenWhitespace) || p.check(TokenComment) {
 		p.advance()
 	}
 }
@@ -1226,7 +2112,11 @@ func (p *Parser) skipNonSignificantWhitespace() {
 
 func (p *Parser) skipToNextStatement() {
 	depth := 0
+	last := -1
 	for !p.isAtEnd() {
+		if p.halted(&last) {
+			break
+		}
 		tok := p.current()
 		if tok.Type == TokenJSXExprOpen {
 			depth++
@@ -1246,11 +2136,30 @@ func (p *Parser) skipToNextStatement() {
 	}
 }
 
-func (p *Parser) addWarning(msg string) {
+// assignedName returns the variable the current token's value is
+// assigned to, inputRef in const inputRef = useRef(null), or ""
+func (p *Parser) assignedName() string {
+	i := p.pos - 1
+	for i >= 0 && (p.tokens[i].Type == TokenWhitespace || p.tokens[i].Type == TokenComment) {
+		i--
+	}
+	if i < 0 || p.tokens[i].Type != TokenEquals {
+		return ""
+	}
+	for i--; i >= 0 && (p.tokens[i].Type == TokenWhitespace || p.tokens[i].Type == TokenComment); i-- {
+	}
+	if i < 0 || p.tokens[i].Type != TokenIdent || !isSimpleIdent(p.tokens[i].Value) {
+		return ""
+	}
+	return p.tokens[i].Value
+}
+
+func (p *Parser) addWarning(code, msg string) {
 	p.warnings = append(p.warnings, Warning{
 		Line:    p.current().Line,
 		Column:  p.current().Column,
 		Message: msg,
+		Code:    code,
 	})
 }
 
@@ -0,0 +1,68 @@
+package parser
+
+import "strings"
+
+// parsePortal parses createPortal(<markup>, container), or
+// ReactDOM.createPortal, at the current position: a component returning
+// one renders the markup somewhere else in the page. It returns the markup
+// and where it goes, or a nil portal, leaving the position untouched.
+func (p *Parser) parsePortal() (Node, *Portal) {
+	start := p.pos
+	call := p.current()
+	if p.matchIdent("ReactDOM") && !p.match(TokenDot) {
+		p.pos = start
+		return nil, nil
+	}
+	if !p.matchIdent("createPortal") {
+		p.pos = start
+		return nil, nil
+	}
+	p.skipWhitespace()
+	if !p.match(TokenLParen) {
+		p.pos = start
+		return nil, nil
+	}
+	p.skipWhitespace()
+	if !p.check(TokenTagOpen) {
+		p.pos = start
+		return nil, nil
+	}
+	portal := &Portal{Container: "body", LineNumber: call.Line}
+	if p.source != "" && call.Offset <= len(p.source) {
+		src := p.source[call.Offset-len(call.Value):]
+		if e, _, err := ParseJSExprPrefix(src); err == nil {
+			if c, ok := e.(*JSCall); ok && len(c.Args) == 2 {
+				portal.Container = portalContainer(c.Args[1], src)
+			}
+		}
+	}
+	return p.parseNode(), portal
+}
+
+// portalContainer names the element a portal renders into:
+// document.body → body, document.getElementById('toasts') → #toasts,
+// document.querySelector(sel) → sel
+func portalContainer(e JSExpr, src string) string {
+	switch c := Unparen(e).(type) {
+	case *JSMember:
+		if doc, ok := c.Object.(*JSIdent); ok && doc.Name == "document" && c.Property == "body" {
+			return "body"
+		}
+	case *JSCall:
+		member, ok := c.Callee.(*JSMember)
+		if !ok || len(c.Args) != 1 {
+			break
+		}
+		lit, ok := c.Args[0].(*JSLiteral)
+		if !ok || lit.Kind != "string" {
+			break
+		}
+		switch member.Property {
+		case "getElementById":
+			return "#" + lit.Value
+		case "querySelector":
+			return lit.Value
+		}
+	}
+	return strings.TrimSpace(e.Span().Text(src))
+}
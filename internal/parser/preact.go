@@ -0,0 +1,326 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// preactImport matches an import from Preact or htm
+	preactImport = regexp.MustCompile(`from\s*['"](?:preact(?:/[\w-]+)?|htm(?:/preact)?)['"]`)
+	// namedImport matches the named imports of a module:
+	// import { h, Fragment } from 'preact'
+	namedImport = regexp.MustCompile(`import\s*\{([^}]*)\}\s*from\s*['"]([^'"]+)['"]`)
+	// htmBind matches the tag htm is bound to: const html = htm.bind(h)
+	htmBind = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*htm\.bind\(`)
+	// htmTail matches the end of an htm attribute value interpolated into
+	// a quoted string: class="item ${
+	htmTail = regexp.MustCompile(`=\s*"([^"<>]*)$`)
+)
+
+// desugarPreact rewrites the h() calls and htm tagged templates of a
+// Preact source into the JSX they stand for, keeping every line where it
+// was, so that Preact components parse like React ones. Sources that
+// import neither Preact nor htm are returned unchanged.
+func desugarPreact(source string) string {
+	if !preactImport.MatchString(source) {
+		return source
+	}
+	d := &desugarer{h: map[string]bool{}, html: map[string]bool{}, fragment: map[string]bool{}}
+	for _, m := range namedImport.FindAllStringSubmatch(source, -1) {
+		for _, spec := range strings.Split(m[1], ",") {
+			name, local, ok := strings.Cut(strings.TrimSpace(spec), " as ")
+			if !ok {
+				local = name
+			}
+			name, local = strings.TrimSpace(name), strings.TrimSpace(local)
+			switch {
+			case strings.HasPrefix(m[2], "preact") && (name == "h" || name == "createElement"):
+				d.h[local] = true
+			case strings.HasPrefix(m[2], "preact") && name == "Fragment":
+				d.fragment[local] = true
+			case m[2] == "htm/preact" && name == "html":
+				d.html[local] = true
+			}
+		}
+	}
+	for _, m := range htmBind.FindAllStringSubmatch(source, -1) {
+		d.html[m[1]] = true
+	}
+	if len(d.h) == 0 && len(d.html) == 0 {
+		return source
+	}
+	return d.rewrite(source)
+}
+
+// desugarer rewrites hyperscript calls and htm templates into JSX
+type desugarer struct {
+	h        map[string]bool // local names of h and createElement
+	html     map[string]bool // local names of htm tags
+	fragment map[string]bool // local names of Fragment
+}
+
+// rewrite replaces every h() call and htm template in src
+func (d *desugarer) rewrite(src string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if !isIdentStart(c) || i > 0 && (isWordChar(src[i-1]) || src[i-1] == '.') {
+			continue
+		}
+		j := i
+		for j < len(src) && isWordChar(src[j]) {
+			j++
+		}
+		name := src[i:j]
+		k := j
+		for k < len(src) && (src[k] == ' ' || src[k] == '\t') {
+			k++
+		}
+		var jsx string
+		var end int
+		switch {
+		case d.h[name] && k < len(src) && src[k] == '(':
+			e, n, err := ParseJSExprPrefix(src[i:])
+			call, ok := e.(*JSCall)
+			if err != nil || !ok || len(call.Args) == 0 {
+				break
+			}
+			if jsx, ok = d.element(call, src[i:]); ok {
+				end = i + n
+			}
+		case d.html[name] && j < len(src) && src[j] == '`':
+			e, n, err := ParseJSExprPrefix(src[j:])
+			tmpl, ok := e.(*JSTemplate)
+			if err != nil || !ok {
+				break
+			}
+			// The parentheses keep return html`\n<div>` returning the markup
+			jsx, end = "("+d.template(tmpl, src[j:])+")", j+n
+		}
+		if jsx == "" {
+			i = j - 1
+			continue
+		}
+		b.WriteString(src[last:i])
+		b.WriteString(jsx)
+		last, i = end, end-1
+	}
+	if last == 0 {
+		return src
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// element returns the JSX of a hyperscript call in src:
+// h('a', { href: url }, 'Home') → <a href={url}>Home</a>
+func (d *desugarer) element(call *JSCall, src string) (string, bool) {
+	var tag string
+	switch t := Unparen(call.Args[0]).(type) {
+	case *JSLiteral:
+		if t.Kind != "string" {
+			return "", false
+		}
+		tag = t.Value
+	case *JSIdent, *JSMember:
+		tag = MemberPath(t)
+		if d.fragment[tag] {
+			tag = ""
+		}
+	}
+	if tag == "" && !d.fragment[MemberPath(call.Args[0])] {
+		return "", false
+	}
+
+	var b strings.Builder
+	pos := call.Args[0].Span().End
+	// gap carries over the line breaks up to offset next
+	gap := func(next int) {
+		if next > pos {
+			b.WriteString(strings.Repeat("\n", strings.Count(src[pos:next], "\n")))
+			pos = next
+		}
+	}
+	text := func(e JSExpr) string { return d.rewrite(e.Span().Text(src)) }
+
+	b.WriteString("<" + tag)
+	children := call.Args[1:]
+	if len(call.Args) > 1 {
+		children = call.Args[2:]
+		switch props := Unparen(call.Args[1]).(type) {
+		case *JSLiteral:
+			if props.Kind != "null" && props.Kind != "undefined" {
+				return "", false
+			}
+		case *JSObject:
+			for _, p := range props.Props {
+				if p.Value != nil {
+					gap(p.Value.Span().Start)
+				}
+				switch {
+				case p.Spread:
+					b.WriteString(" {..." + text(p.Value) + "}")
+				case p.Key == "" || tag == "":
+					// computed keys, and a fragment's key
+				case p.Shorthand || p.Value == nil:
+					b.WriteString(" " + p.Key + "={" + p.Key + "}")
+				default:
+					if lit, ok := Unparen(p.Value).(*JSLiteral); ok && lit.Kind == "string" && !strings.Contains(lit.Value, `"`) {
+						b.WriteString(" " + p.Key + "=" + strconv.Quote(lit.Value))
+					} else {
+						b.WriteString(" " + p.Key + "={" + text(p.Value) + "}")
+					}
+				}
+			}
+		default:
+			b.WriteString(" {..." + text(call.Args[1]) + "}")
+		}
+		pos = max(pos, call.Args[1].Span().End)
+	}
+	if len(children) == 0 && tag != "" {
+		gap(call.End)
+		b.WriteString(" />")
+		return b.String(), true
+	}
+	b.WriteString(">")
+	for _, c := range children {
+		gap(c.Span().Start)
+		switch n := Unparen(c).(type) {
+		case *JSLiteral:
+			if n.Kind == "string" && !strings.ContainsAny(n.Value, "{}<>\n") {
+				b.WriteString(n.Value)
+			} else {
+				b.WriteString("{" + text(c) + "}")
+			}
+		case *JSXNode:
+			b.WriteString(text(c))
+		case *JSSpread:
+			b.WriteString("{" + text(n.X) + "}")
+		case *JSCall:
+			if id, ok := n.Callee.(*JSIdent); ok && d.h[id.Name] {
+				if jsx, ok := d.element(n, src); ok {
+					b.WriteString(jsx)
+					break
+				}
+			}
+			b.WriteString("{" + text(c) + "}")
+		default:
+			b.WriteString("{" + text(c) + "}")
+		}
+		pos = c.Span().End
+	}
+	gap(call.End)
+	b.WriteString("</" + tag + ">")
+	return b.String(), true
+}
+
+// template returns the JSX of an htm template in src: interpolations
+// become {expressions}, <${Card}> a component tag, ...${props} a spread,
+// and an interpolation inside a quoted attribute value a template literal
+func (d *desugarer) template(tmpl *JSTemplate, src string) string {
+	var b strings.Builder
+	quoted := false // inside class="a ${b}", now class={`a ${b}
+	for i, q := range tmpl.Quasis {
+		if quoted {
+			// The value ends at the next quote
+			if k := strings.IndexByte(q, '"'); k >= 0 {
+				b.WriteString(q[:k] + "`}")
+				q, quoted = q[k+1:], false
+			}
+		}
+		b.WriteString(q)
+		if i == len(tmpl.Exprs) {
+			break
+		}
+		expr := d.rewrite(tmpl.Exprs[i].Span().Text(src))
+		out := b.String()
+		switch {
+		case quoted:
+			b.WriteString("${" + expr + "}")
+		case strings.HasSuffix(out, "<") || strings.HasSuffix(out, "</"):
+			b.WriteString(expr)
+		case strings.HasSuffix(out, "..."):
+			b.Reset()
+			b.WriteString(strings.TrimSuffix(out, "...") + "{..." + expr + "}")
+		case htmTail.MatchString(out):
+			m := htmTail.FindStringSubmatchIndex(out)
+			b.Reset()
+			b.WriteString(out[:m[2]-1] + "{`" + out[m[2]:m[3]] + "${" + expr + "}")
+			quoted = true
+		default:
+			b.WriteString("{" + expr + "}")
+		}
+	}
+	out := b.String()
+	if strings.Contains(out, "<//>") {
+		out = closeTags(out)
+	}
+	return out
+}
+
+// closeTags replaces htm's <//> closing tags with the name of the
+// element they close
+func closeTags(jsx string) string {
+	var b strings.Builder
+	var open []string
+	for i := 0; i < len(jsx); i++ {
+		switch {
+		case jsx[i] == '{':
+			end := skipBalanced(jsx, i)
+			if end < 0 {
+				b.WriteString(jsx[i:])
+				return b.String()
+			}
+			b.WriteString(jsx[i:end])
+			i = end - 1
+		case strings.HasPrefix(jsx[i:], "<//>"):
+			name := ""
+			if len(open) > 0 {
+				name, open = open[len(open)-1], open[:len(open)-1]
+			}
+			b.WriteString("</" + name + ">")
+			i += len("<//>") - 1
+		case strings.HasPrefix(jsx[i:], "</"):
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+			b.WriteByte(jsx[i])
+		case jsx[i] == '<':
+			j := i + 1
+			for j < len(jsx) && (isWordChar(jsx[j]) || jsx[j] == '.' || jsx[j] == '-') {
+				j++
+			}
+			// Find the end of the opening tag, past attribute values
+			k := j
+			for k < len(jsx) && jsx[k] != '>' {
+				switch jsx[k] {
+				case '"', '\'':
+					if end := skipString(jsx, k); end > 0 {
+						k = end - 1
+					}
+				case '{':
+					if end := skipBalanced(jsx, k); end > 0 {
+						k = end - 1
+					}
+				}
+				k++
+			}
+			if k < len(jsx) && jsx[k-1] != '/' {
+				open = append(open, jsx[i+1:j])
+			}
+			b.WriteString(jsx[i:min(k+1, len(jsx))])
+			i = k
+		default:
+			b.WriteByte(jsx[i])
+		}
+	}
+	return b.String()
+}
+
+// isWordChar reports whether ch can be part of a JavaScript identifier
+func isWordChar(ch byte) bool {
+	return isIdentStart(ch) || ch >= '0' && ch <= '9'
+}
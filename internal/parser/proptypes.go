@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// propTypesDecl matches Button.propTypes = { ... }
+	propTypesDecl = regexp.MustCompile(`\b([A-Z]\w*)\.propTypes\s*=\s*\{`)
+	// propTypeKind matches the validator of a declaration:
+	// PropTypes.string, PropTypes.arrayOf(...), oneOf([...])
+	propTypeKind = regexp.MustCompile(`^(?:PropTypes\.)?(\w+)`)
+	// restProps matches a props pattern collecting the other props:
+	// ({ label, ...rest })
+	restProps = regexp.MustCompile(`^[^(]*\(\s*\{[^}]*\.\.\.`)
+	// oneOfValue matches the first value of oneOf(['a', 'b'])
+	oneOfValue = regexp.MustCompile(`^oneOf\(\s*\[\s*(['"\x60]|-?\d)`)
+)
+
+// propTypeKinds maps a PropTypes validator to the JS type it declares
+var propTypeKinds = map[string]string{
+	"string":      "string",
+	"number":      "number",
+	"bool":        "bool",
+	"array":       "array",
+	"arrayOf":     "array",
+	"object":      "object",
+	"objectOf":    "object",
+	"shape":       "object",
+	"exact":       "object",
+	"instanceOf":  "object",
+	"func":        "func",
+	"node":        "node",
+	"element":     "node",
+	"elementType": "node",
+}
+
+// declaredProp is a prop a propTypes declaration gives
+type declaredProp struct {
+	Prop
+	line int
+}
+
+// extractPropTypes finds the propTypes declarations of a source file, by
+// component
+func extractPropTypes(source string) map[string][]declaredProp {
+	decls := map[string][]declaredProp{}
+	for _, m := range propTypesDecl.FindAllStringSubmatchIndex(source, -1) {
+		src := source[m[1]-1:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		obj, ok := e.(*JSObject)
+		if !ok {
+			continue
+		}
+		comp := source[m[2]:m[3]]
+		line := 1 + strings.Count(source[:m[1]], "\n")
+		decls[comp] = nil
+		for _, prop := range obj.Props {
+			if prop.Key == "" || prop.Spread || prop.Value == nil {
+				continue
+			}
+			span := prop.Value.Span()
+			validator := strings.Join(strings.Fields(span.Text(src)), "")
+			p := Prop{Name: prop.Key, JSType: "any"}
+			if rest, ok := strings.CutSuffix(validator, ".isRequired"); ok {
+				p.Required = true
+				validator = rest
+			}
+			validator = strings.TrimPrefix(validator, "PropTypes.")
+			if k := propTypeKind.FindStringSubmatch(validator); k != nil {
+				if kind, ok := propTypeKinds[k[1]]; ok {
+					p.JSType = kind
+				}
+			}
+			// oneOf(['sm', 'lg']) takes the type of its values
+			if v := oneOfValue.FindStringSubmatch(validator); v != nil {
+				p.JSType = "string"
+				if !strings.ContainsAny(v[1], "'\"`") {
+					p.JSType = "number"
+				}
+			}
+			decls[comp] = append(decls[comp], declaredProp{Prop: p, line: line + strings.Count(src[:span.Start], "\n")})
+		}
+	}
+	return decls
+}
+
+// applyPropTypes types the props of the components by their propTypes
+// declarations. A component taking the props object gets the declared
+// props as its own. Declared props the component does not use are
+// reported as warnings.
+func (p *Parser) applyPropTypes(lines []string, comps []Component) {
+	decls := extractPropTypes(p.source)
+	for i := range comps {
+		comp := &comps[i]
+		declared, ok := decls[comp.Name]
+		if !ok {
+			continue
+		}
+
+		end := p.findComponentEnd(comp, comps, i)
+		body := strings.Join(lines[max(comp.LineNumber-1, 0):min(end-1, len(lines))], "\n")
+		if len(comp.Props) == 1 && comp.Props[0].Name == "props" {
+			// function Button(props): used as props.label, through
+			// const { label } = props, or spread as {...props}
+			spread := strings.Contains(body, "...props")
+			comp.Props = nil
+			if strings.Contains(body, "props.children") && !declaresProp(declared, "children") {
+				comp.Props = append(comp.Props, Prop{Name: "children", JSType: "node"})
+			}
+			for _, d := range declared {
+				comp.Props = append(comp.Props, d.Prop)
+				name := regexp.QuoteMeta(d.Name)
+				used := regexp.MustCompile(`\bprops\s*\.\s*` + name + `\b|\{[^}]*\b` + name + `\b[^}]*\}\s*=\s*props\b`)
+				if !spread && !used.MatchString(body) {
+					p.unusedProp(comp.Name, d)
+				}
+			}
+			continue
+		}
+
+		// ({ label, ...rest }) passes the props it does not name on
+		rest := restProps.MatchString(body)
+		for _, d := range declared {
+			found := rest
+			for j := range comp.Props {
+				if comp.Props[j].Name == d.Name {
+					comp.Props[j].JSType = d.JSType
+					comp.Props[j].Required = d.Required
+					found = true
+				}
+			}
+			if !found {
+				p.unusedProp(comp.Name, d)
+			}
+		}
+	}
+}
+
+func declaresProp(declared []declaredProp, name string) bool {
+	for _, d := range declared {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) unusedProp(comp string, d declaredProp) {
+	p.warnings = append(p.warnings, Warning{
+		Line:    d.line,
+		Message: fmt.Sprintf("Prop %s is declared in %s.propTypes but %s does not use it", d.Name, comp, comp),
+		Code:    CodeUnusedProp,
+	})
+}
@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// declarationKeywords start a top-level statement at the beginning of a line
+var declarationKeywords = map[string]bool{
+	"import": true, "export": true, "function": true, "const": true,
+	"let": true, "var": true, "class": true,
+}
+
+// statementStarts returns the positions of the tokens that begin a line
+// with a top-level declaration
+func statementStarts(tokens []Token) []int {
+	var starts []int
+	for i, tok := range tokens {
+		if tok.Type != TokenIdent || !declarationKeywords[tok.Value] {
+			continue
+		}
+		if i == 0 || tokens[i-1].Type == TokenWhitespace && strings.HasSuffix(tokens[i-1].Value, "\n") {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// nextStatement returns the position of the first top-level statement
+// after pos, or 0 if there is none
+func (p *Parser) nextStatement(pos int) int {
+	for _, start := range p.statements {
+		if start > pos {
+			return start
+		}
+	}
+	return 0
+}
+
+// declaration runs parse on the top-level declaration at the current
+// position, which cannot read past the next one: a construct the parser
+// does not understand leaves the rest of the file alone. A declaration
+// whose parameters or value do not parse, a loop that stops making
+// progress, or a panic, drops it with a warning rather than ending or
+// hanging the parse.
+func (p *Parser) declaration(parse func()) {
+	start := p.pos
+	p.limit = p.nextStatement(start)
+	end := p.limit
+	if end == 0 {
+		end = len(p.tokens)
+	}
+	skip := func(code, reason string) {
+		line := p.tokens[start].Line
+		p.warnings = append(p.warnings, Warning{
+			Line:    line,
+			Message: fmt.Sprintf("Cannot parse this declaration (%s); it is skipped", reason),
+			Code:    code,
+			Skipped: skipped(line, p.tokenText(start, end)),
+		})
+		p.pos = end
+	}
+	if reason := p.malformed(start, end); reason != "" {
+		p.limit = 0
+		skip(CodeMalformedDeclaration, reason)
+		return
+	}
+	defer func() {
+		p.limit = 0
+		stalled := p.stalled
+		p.stalled = nil
+		if r := recover(); r != nil {
+			skip(CodeInternal, fmt.Sprint(r))
+			return
+		}
+		switch {
+		case stalled != nil:
+			skip(CodeInternal, fmt.Sprintf("the parser made no progress at line %d, at %q", stalled.Line, stalled.Value))
+		case p.pos == start && !p.isAtEnd():
+			skip(CodeInternal, "the parser made no progress")
+		}
+	}()
+	parse()
+}
+
+// tokenText returns the source of the tokens from start to end
+func (p *Parser) tokenText(start, end int) string {
+	var src strings.Builder
+	for _, tok := range p.tokens[start:end] {
+		if tok.Type != TokenEOF {
+			src.WriteString(tok.Value)
+		}
+	}
+	return src.String()
+}
+
+// malformed says why the declaration from start to end cannot be
+// converted, or returns "": the parameter list of a function must close
+// and parse, function Broken( { ... is not, and a const, let or var must
+// have a value that parses where it has an =, const x = ; has none.
+// Values with JSX in them are left to the parser.
+func (p *Parser) malformed(start, end int) string {
+	var toks []Token
+	for _, tok := range p.tokens[start:end] {
+		if tok.Type != TokenWhitespace && tok.Type != TokenComment && tok.Type != TokenEOF {
+			toks = append(toks, tok)
+		}
+	}
+	i := 0
+	for _, prefix := range []string{"export", "default", "async"} {
+		if i < len(toks) && toks[i].Type == TokenIdent && toks[i].Value == prefix {
+			i++
+		}
+	}
+	if i >= len(toks) || toks[i].Type != TokenIdent {
+		return ""
+	}
+	switch toks[i].Value {
+	case "function":
+		i++
+		name := "the function"
+		if i < len(toks) && toks[i].Type == TokenIdent {
+			name = toks[i].Value
+			i++
+		}
+		if i >= len(toks) || toks[i].Type != TokenLParen {
+			return ""
+		}
+		close := matchingParen(toks, i)
+		if close < 0 {
+			return fmt.Sprintf("the parameter list of %s is not closed", name)
+		}
+		params := tokensText(toks[i+1 : close])
+		if _, err := ParseJSExpr("(" + params + ") => 0"); err != nil {
+			return fmt.Sprintf("the parameters of %s do not parse: %v", name, err)
+		}
+	case "const", "let", "var":
+		i++
+		if i >= len(toks) || toks[i].Type != TokenIdent {
+			// Destructuring: const { a, b } = ...
+			return ""
+		}
+		name := toks[i].Value
+		i++
+		if i < len(toks) && toks[i].Type == TokenColon {
+			// A TypeScript annotation: const x: Props = ...
+			for i < len(toks) && toks[i].Type != TokenEquals && toks[i].Type != TokenTagOpen {
+				i++
+			}
+		}
+		if i >= len(toks) || toks[i].Type != TokenEquals {
+			return ""
+		}
+		value := toks[i+1:]
+		if len(value) == 0 || value[0].Type == TokenText && value[0].Value == ";" {
+			return fmt.Sprintf("%s has no value", name)
+		}
+		if err := valueError(value); err != nil {
+			return fmt.Sprintf("the value of %s does not parse: %v", name, err)
+		}
+	}
+	return ""
+}
+
+// matchingParen returns the position of the ) closing the ( at open, or
+// -1 if none does
+func matchingParen(toks []Token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Type {
+		case TokenLParen:
+			depth++
+		case TokenRParen:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// valueError parses the value of a declaration, which ends at a ; or a ,
+// outside brackets or, without one, at the end of some line. It returns
+// nil for a value with JSX, which is not checked.
+func valueError(toks []Token) error {
+	var ends []int
+	depth := 0
+	for i, tok := range toks {
+		switch {
+		case tok.Type == TokenTagOpen:
+			return nil
+		case tok.Type == TokenLParen, tok.Type == TokenJSXExprOpen, tok.Type == TokenText && tok.Value == "[":
+			depth++
+		case tok.Type == TokenRParen, tok.Type == TokenJSXExprClose, tok.Type == TokenText && tok.Value == "]":
+			depth--
+		case depth == 0 && (tok.Type == TokenComma || tok.Type == TokenText && tok.Value == ";"):
+			ends = append(ends, i)
+		}
+		if depth == 0 && i+1 < len(toks) && toks[i+1].Line > tok.Line {
+			ends = append(ends, i+1)
+		}
+	}
+	ends = append(ends, len(toks))
+	var first error
+	for _, end := range ends {
+		_, err := ParseJSExpr(tokensText(toks[:end]))
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+		if end < len(toks) && (toks[end].Type == TokenComma || toks[end].Value == ";") {
+			break
+		}
+	}
+	return first
+}
+
+// tokensText joins the source of tokens, with a space where the source
+// had whitespace
+func tokensText(toks []Token) string {
+	var b strings.Builder
+	for i, tok := range toks {
+		if i > 0 && tok.Offset > toks[i-1].Offset+len(toks[i-1].Value) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(tok.Value)
+	}
+	return b.String()
+}
+
+// lexErrors are the warnings for the errors the lexer recovers from, by
+// the Value of their TokenError
+var lexErrors = map[string]Warning{
+	"unterminated string": {
+		Code:    CodeUnterminatedString,
+		Message: "Unterminated string; the rest of its line is skipped",
+	},
+	"unterminated template literal": {
+		Code:    CodeUnterminatedTemplate,
+		Message: "Unterminated template literal; skipped up to the next top-level statement",
+	},
+	"unterminated comment": {
+		Code:    CodeUnterminatedComment,
+		Message: "Unterminated comment; the rest of the file is skipped",
+	},
+}
+
+// reportLexErrors warns about the source the lexer skipped
+func (p *Parser) reportLexErrors() {
+	for _, tok := range p.tokens {
+		if tok.Type != TokenError {
+			continue
+		}
+		w, ok := lexErrors[tok.Value]
+		if !ok {
+			w = Warning{Message: tok.Value}
+		}
+		// An error token ends where the skipped source does
+		w.Line = tok.Line - strings.Count(tok.Skipped, "\n")
+		if !strings.Contains(tok.Skipped, "\n") {
+			w.Column = tok.Column - len(tok.Skipped)
+		}
+		w.Skipped = skipped(w.Line, tok.Skipped)
+		p.warnings = append(p.warnings, w)
+	}
+}
+
+// skipped describes the source src, starting on line
+func skipped(line int, src string) *Skipped {
+	src = strings.TrimRight(src, " \t\n")
+	first, _, _ := strings.Cut(src, "\n")
+	return &Skipped{
+		Line:    line,
+		EndLine: line + strings.Count(src, "\n"),
+		Text:    strings.TrimSpace(first),
+	}
+}
+
+// unclosed warns about an element that is still open where an enclosing
+// element closes, or where its declaration or the file ends
+func (p *Parser) unclosed(tag string, line int) {
+	before := "the end of the file"
+	switch {
+	case p.check(TokenTagEnd):
+		before = fmt.Sprintf("</%s> on line %d", p.closingTag(), p.current().Line)
+	case p.limit > 0 && p.limit < len(p.tokens):
+		before = fmt.Sprintf("the declaration on line %d", p.tokens[p.limit].Line)
+	}
+	p.warnings = append(p.warnings, Warning{
+		Line:    line,
+		Message: fmt.Sprintf("%s is not closed before %s", tag, before),
+		Code:    CodeUnclosedElement,
+	})
+}
+
+// closingTag returns the name of the closing tag at the current
+// position without consuming it; "" for </>
+func (p *Parser) closingTag() string {
+	start := p.pos
+	defer func() { p.pos = start }()
+	p.advance()
+	p.skipWhitespace()
+	if !p.check(TokenIdent) {
+		return ""
+	}
+	return p.memberTag(p.advance().Value)
+}
+
+// closesOpen reports whether the closing tag at the current position
+// belongs to an element enclosing the one named tag rather than to it
+func (p *Parser) closesOpen(tag string) bool {
+	name := p.closingTag()
+	if name == tag {
+		return false
+	}
+	for _, open := range p.open {
+		if open == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// createSliceDecl matches a Redux Toolkit slice declaration:
+	// const counterSlice = createSlice({ ... })
+	createSliceDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*createSlice\s*\(`)
+	// selectorDecl matches a component selecting from the store:
+	// const count = useSelector(state => state.counter.value)
+	selectorDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\{[^}]*\}|\w+)\s*=\s*useSelector\s*\(`)
+	// dispatchDecl matches const dispatch = useDispatch()
+	dispatchDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*useDispatch\s*\(\s*\)`)
+)
+
+// extractSlices finds the createSlice calls of a source file, with the
+// fields of their initial state and their case reducers
+func extractSlices(source string) []ReduxSlice {
+	var slices []ReduxSlice
+	for _, m := range createSliceDecl.FindAllStringSubmatchIndex(source, -1) {
+		src := source[m[1]-len("createSlice("):]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		call, ok := e.(*JSCall)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		config, ok := Unparen(call.Args[0]).(*JSObject)
+		if !ok {
+			continue
+		}
+		slice := ReduxSlice{
+			Var:        source[m[2]:m[3]],
+			LineNumber: 1 + strings.Count(source[:m[0]], "\n"),
+		}
+		for _, prop := range config.Props {
+			switch prop.Key {
+			case "name":
+				if lit, ok := Unparen(prop.Value).(*JSLiteral); ok && lit.Kind == "string" {
+					slice.Name = lit.Value
+				}
+			case "initialState":
+				slice.State = initialState(source, src, prop.Value)
+				if id, ok := Unparen(prop.Value).(*JSIdent); ok {
+					slice.Initial = id.Name
+				}
+			case "reducers":
+				reducers, ok := Unparen(prop.Value).(*JSObject)
+				if !ok {
+					continue
+				}
+				for _, r := range reducers.Props {
+					fn, ok := Unparen(r.Value).(*JSArrow)
+					if r.Key == "" || !ok {
+						continue
+					}
+					body := fn.Block
+					if fn.Body != nil {
+						body = fn.Body.Span().Text(src)
+					}
+					slice.Reducers = append(slice.Reducers, Reducer{
+						Name:       r.Key,
+						Params:     fn.Params,
+						Body:       strings.TrimSpace(body),
+						Payload:    len(fn.Params) > 1 && strings.Contains(body, "payload"),
+						LineNumber: slice.LineNumber + strings.Count(src[:r.Value.Span().Start], "\n"),
+					})
+				}
+			}
+		}
+		if slice.Name == "" {
+			slice.Name = strings.TrimSuffix(slice.Var, "Slice")
+		}
+		slices = append(slices, slice)
+	}
+	return slices
+}
+
+// initialState returns the fields of a slice's initial state: an object
+// literal, or a variable declared in the same file holding one
+func initialState(source, src string, value JSExpr) []StateVariable {
+	if id, ok := Unparen(value).(*JSIdent); ok {
+		decl := regexp.MustCompile(`\b(?:const|let|var)\s+` + regexp.QuoteMeta(id.Name) + `\s*(?::\s*[\w.<>\[\]]+\s*)?=`)
+		loc := decl.FindStringIndex(source)
+		if loc == nil {
+			return nil
+		}
+		src = source[loc[1]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			return nil
+		}
+		value = e
+	}
+	obj, ok := Unparen(value).(*JSObject)
+	if !ok {
+		return nil
+	}
+	var fields []StateVariable
+	for _, prop := range obj.Props {
+		if prop.Key == "" || prop.Spread || prop.Value == nil {
+			continue
+		}
+		value := strings.TrimSpace(prop.Value.Span().Text(src))
+		fields = append(fields, StateVariable{Name: prop.Key, InitValue: value, InitType: inferTypeFromValue(value)})
+	}
+	return fields
+}
+
+// componentStoreReads finds the useSelector calls on the 1-based source
+// lines [start, end). A selector passed by name is looked up in source.
+func componentStoreReads(source string, lines []string, start, end int) []StoreRead {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	var reads []StoreRead
+	for _, m := range selectorDecl.FindAllStringSubmatchIndex(body, -1) {
+		src := body[m[1]-len("useSelector("):]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		call, ok := e.(*JSCall)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		selector := strings.TrimSpace(call.Args[0].Span().Text(src))
+		slice, field := selectedPath(source, call.Args[0])
+
+		line := start + strings.Count(body[:m[0]], "\n")
+		names, keys := bindingNames(body[m[2]:m[3]])
+		if len(keys) == 0 {
+			reads = append(reads, StoreRead{Name: names[0], Slice: slice, Field: field, Selector: selector, LineNumber: line})
+			continue
+		}
+		// const { value, status } = useSelector(state => state.counter)
+		for i, key := range keys {
+			read := StoreRead{Name: names[i], Slice: slice, Field: key, Selector: selector, LineNumber: line}
+			if field != "" {
+				read.Slice, read.Field = "", ""
+			}
+			reads = append(reads, read)
+		}
+	}
+	return reads
+}
+
+// selectedPath returns the slice and field a selector reads:
+// state => state.counter.value reads value of counter. Selector functions
+// declared in source are followed.
+func selectedPath(source string, selector JSExpr) (slice, field string) {
+	if id, ok := Unparen(selector).(*JSIdent); ok {
+		decl := regexp.MustCompile(`\b(?:const|let|var)\s+` + regexp.QuoteMeta(id.Name) + `\s*=`)
+		loc := decl.FindStringIndex(source)
+		if loc == nil {
+			return "", ""
+		}
+		e, _, err := ParseJSExprPrefix(source[loc[1]:])
+		if err != nil {
+			return "", ""
+		}
+		selector = e
+	}
+	fn, ok := Unparen(selector).(*JSArrow)
+	if !ok || fn.Body == nil || len(fn.Params) != 1 {
+		return "", ""
+	}
+	parts := strings.Split(MemberPath(fn.Body), ".")
+	if len(parts) < 2 || parts[0] != strings.TrimSpace(fn.Params[0]) {
+		return "", ""
+	}
+	if len(parts) == 2 {
+		return parts[1], ""
+	}
+	if len(parts) == 3 {
+		return parts[1], parts[2]
+	}
+	return "", ""
+}
+
+// componentDispatches finds the actions dispatched on the 1-based source
+// lines [start, end) with the dispatch function useDispatch returned
+func componentDispatches(lines []string, start, end int) []Dispatch {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	m := dispatchDecl.FindStringSubmatch(body)
+	if m == nil {
+		return nil
+	}
+	call := regexp.MustCompile(`\b` + regexp.QuoteMeta(m[1]) + `\(\s*((?:\w+\.)*\w+)\s*\(`)
+
+	var dispatches []Dispatch
+	for _, loc := range call.FindAllStringSubmatchIndex(body, -1) {
+		src := body[loc[2]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		action, ok := e.(*JSCall)
+		if !ok {
+			continue
+		}
+		path := strings.Split(body[loc[2]:loc[3]], ".")
+		d := Dispatch{
+			Action:     path[len(path)-1],
+			LineNumber: start + strings.Count(body[:loc[0]], "\n"),
+		}
+		for _, arg := range action.Args {
+			d.Args = append(d.Args, strings.TrimSpace(arg.Span().Text(src)))
+		}
+		dispatches = append(dispatches, d)
+	}
+	return dispatches
+}
+
+// suggestRedux describes where the file's slices, store reads and
+// dispatched actions end up
+func (p *Parser) suggestRedux(slices []ReduxSlice, comps []Component) {
+	reducers := map[string]string{}
+	for _, s := range slices {
+		typ := sliceTypeName(s.Name)
+		p.addSuggestion(s.LineNumber, fmt.Sprintf("createSlice({ name: '%s' })", s.Name),
+			fmt.Sprintf("Redux slice: server-side state %s with a method per reducer", typ), "reduxSlice")
+		for _, r := range s.Reducers {
+			reducers[r.Name] = typ + "." + exportIdent(r.Name)
+		}
+	}
+	for _, comp := range comps {
+		for _, read := range comp.StoreReads {
+			if read.Store != "" {
+				continue
+			}
+			p.addSuggestion(read.LineNumber, "useSelector("+read.Selector+")",
+				fmt.Sprintf("Read from the store on the server and passed to %s as the %s parameter", comp.Name, read.Name), "reduxSelector")
+		}
+		for _, d := range comp.Dispatches {
+			if d.Store != "" {
+				continue
+			}
+			endpoint := "POST /" + kebabIdent(comp.Name) + "/" + kebabIdent(d.Action)
+			hint := fmt.Sprintf("Redux action: %s runs the %s reducer on the server state and re-renders %s", endpoint, d.Action, comp.Name)
+			if method, ok := reducers[d.Action]; ok {
+				// The handler stub only re-renders: calling the method on
+				// state kept per session or application is left to port
+				with := ""
+				if len(d.Args) > 0 {
+					with = " with the payload"
+				}
+				hint = fmt.Sprintf("Redux action: %s re-renders %s; port its handler to call %s%s", endpoint, comp.Name, method, with)
+			}
+			p.addSuggestion(d.LineNumber, fmt.Sprintf("dispatch(%s(%s))", d.Action, strings.Join(d.Args, ", ")), hint, "reduxDispatch")
+		}
+	}
+}
+
+// sliceTypeName names the Go struct of a slice: counter → CounterState
+func sliceTypeName(name string) string {
+	return exportIdent(name) + "State"
+}
+
+func exportIdent(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// kebabIdent converts incrementByAmount to increment-by-amount
+func kebabIdent(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteRune('-')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
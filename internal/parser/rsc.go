@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Where a component renders under the React Server Components conventions
+const (
+	RenderServer = "server" // renders once on the server; converts directly
+	RenderClient = "client" // interactive in the browser; needs HTMX or mintydyn
+)
+
+var (
+	// leadingDirective matches a directive at the start of a file or of a
+	// function body, after any comments: 'use client';
+	leadingDirective = regexp.MustCompile(`^(?:\s+|//[^\n]*|/\*[\s\S]*?\*/)*['"](use (?:client|server))['"]`)
+	// exportedAsync matches the async functions a 'use server' file exports
+	exportedAsync = regexp.MustCompile(`(?m)^export\s+(?:default\s+)?(?:async\s+function\s+(\w+)|(?:const|let)\s+(\w+)\s*=\s*async\b)`)
+	// inlineAction matches an async function whose body opens with
+	// 'use server'
+	inlineAction = regexp.MustCompile(`(?:async\s+function\s+(\w+)\s*\([^)]*\)|(?:const|let)\s+(\w+)\s*=\s*async\s*(?:\([^)]*\)|\w+)\s*=>)\s*\{\s*['"]use server['"]`)
+	// browserGlobal matches the browser APIs a server component cannot use
+	browserGlobal = regexp.MustCompile(`\b(window|document|localStorage|sessionStorage|navigator)\.`)
+)
+
+// fileDirective returns the 'use client' or 'use server' directive a
+// file starts with, or ""
+func fileDirective(source string) string {
+	if m := leadingDirective.FindStringSubmatch(source); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// serverActions finds the server actions of a file: every exported async
+// function of a 'use server' file, and functions anywhere whose body
+// starts with 'use server'
+func serverActions(source, directive string) []ServerAction {
+	var actions []ServerAction
+	seen := map[string]bool{}
+	add := func(m []int) {
+		name := ""
+		for i := 2; i+1 < len(m); i += 2 {
+			if m[i] >= 0 {
+				name = source[m[i]:m[i+1]]
+				break
+			}
+		}
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		actions = append(actions, ServerAction{Name: name, LineNumber: 1 + strings.Count(source[:m[0]], "\n")})
+	}
+	if directive == "use server" {
+		for _, m := range exportedAsync.FindAllStringSubmatchIndex(source, -1) {
+			add(m)
+		}
+	}
+	for _, m := range inlineAction.FindAllStringSubmatchIndex(source, -1) {
+		add(m)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].LineNumber < actions[j].LineNumber })
+	return actions
+}
+
+// usesServerComponents reports whether a file follows the React Server
+// Components conventions: it has a directive or server actions, or
+// renders asynchronously
+func usesServerComponents(file *File) bool {
+	if file.Directive != "" || len(file.ServerActions) > 0 {
+		return true
+	}
+	for _, comp := range file.Components {
+		if comp.Async {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyRendering decides where each component of a server components
+// file renders. Components of a 'use client' file, and components using
+// hooks, event handlers or browser APIs, are client components; the rest
+// render on the server.
+func classifyRendering(file *File, lines []string, ends []int) {
+	if !usesServerComponents(file) {
+		return
+	}
+	for i := range file.Components {
+		comp := &file.Components[i]
+		var why []string
+		seen := map[string]bool{}
+		note := func(reason string) {
+			if !seen[reason] {
+				seen[reason] = true
+				why = append(why, reason)
+			}
+		}
+		if file.Directive == "use client" {
+			note("'use client'")
+		}
+		for _, hook := range comp.Hooks {
+			// use() reads a promise or a context, on either side
+			if hook.Type != "use" {
+				note(hook.Type)
+			}
+		}
+		walkJSX(comp.Body, func(elem *Element) {
+			for _, attr := range elem.Attributes {
+				if attr.EventHandler != nil {
+					note(attr.Name)
+				}
+			}
+		})
+		if lines != nil {
+			body := strings.Join(lines[max(comp.LineNumber-1, 0):min(ends[i]-1, len(lines))], "\n")
+			for _, m := range browserGlobal.FindAllStringSubmatch(body, -1) {
+				note(m[1])
+			}
+		}
+		comp.Rendering = RenderServer
+		if len(why) > 0 {
+			comp.Rendering = RenderClient
+			comp.Interactive = why
+		}
+	}
+}
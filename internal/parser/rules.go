@@ -0,0 +1,54 @@
+package parser
+
+// Rule IDs for parser warnings and hook suggestions. They never change
+// once released: code-scanning tools track results across runs by rule.
+const (
+	RuleSyntax     = "parse/syntax"
+	RuleUseState   = "hooks/use-state"
+	RuleUseEffect  = "hooks/use-effect"
+	RuleMemo       = "hooks/memoization"
+	RuleUseContext = "hooks/use-context"
+	RuleUseRef     = "hooks/use-ref"
+	RuleUseReducer = "hooks/use-reducer"
+
+	RuleCloneElement = "react/clone-element"
+)
+
+// Codes of parser warnings, saying what went wrong. Like rule IDs they
+// never change once released.
+const (
+	CodeUnterminatedString   = "unterminated-string"
+	CodeUnterminatedTemplate = "unterminated-template"
+	CodeUnterminatedComment  = "unterminated-comment"
+	CodeMissingTagName       = "missing-tag-name"
+	CodeUnclosedTag          = "unclosed-tag"
+	CodeUnclosedElement      = "unclosed-element"
+	CodeMismatchedTag        = "mismatched-tag"
+	CodeUnusedProp           = "unused-prop"
+	CodeMalformedDeclaration = "malformed-declaration"
+	CodeInternal             = "internal-error"
+)
+
+var suggestionRules = map[string]string{
+	"useState":    RuleUseState,
+	"useEffect":   RuleUseEffect,
+	"memoization": RuleMemo,
+	"useContext":  RuleUseContext,
+	"useRef":      RuleUseRef,
+	"useReducer":  RuleUseReducer,
+
+	"cloneElement": RuleCloneElement,
+}
+
+// Rule returns the rule ID of a warning
+func (w Warning) Rule() string {
+	return RuleSyntax
+}
+
+// Rule returns the rule ID of a suggestion
+func (s Suggestion) Rule() string {
+	if rule, ok := suggestionRules[s.PatternType]; ok {
+		return rule
+	}
+	return "hooks/" + s.PatternType
+}
@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ValidationSchema is a zod or yup object schema describing form fields
+type ValidationSchema struct {
+	Name       string        `json:"name"`              // variable the schema is assigned to
+	Library    string        `json:"library,omitempty"` // "zod" or "yup"
+	Fields     []SchemaField `json:"fields,omitempty"`
+	LineNumber int           `json:"line"`
+}
+
+// SchemaField is one field of a validation schema
+type SchemaField struct {
+	Name        string       `json:"name"`
+	Type        string       `json:"type"` // string, number, boolean, enum, other
+	Required    bool         `json:"required,omitempty"`
+	Rules       []SchemaRule `json:"rules,omitempty"`
+	Unsupported []string     `json:"unsupported,omitempty"` // chain methods that could not be translated
+	Source      string       `json:"source,omitempty"`      // original schema expression
+}
+
+// SchemaRule is a single translated constraint
+type SchemaRule struct {
+	Kind    string   `json:"kind"`              // min, max, length, int, email, url, regex, enum
+	Arg     string   `json:"arg,omitempty"`     // numeric bound or Go regexp
+	Values  []string `json:"values,omitempty"`  // enum values
+	Message string   `json:"message,omitempty"` // custom error message, if given
+}
+
+var (
+	schemaDecl  = regexp.MustCompile(`(?:const|let|var)\s+(\w+)\s*=\s*(z|yup|Yup)\.object\(\s*(?:\)\s*\.shape\(\s*)?\{`)
+	schemaCall  = regexp.MustCompile(`^\.(\w+)\(`)
+	schemaBase  = regexp.MustCompile(`^(?:z|yup|Yup)\.(\w+)\(`)
+	jsRegex     = regexp.MustCompile(`^/((?:\\.|[^/\\])+)/([a-z]*)$`)
+	messageProp = regexp.MustCompile(`message\s*:\s*(["'` + "`" + `])(.*?)["'` + "`" + `]`)
+)
+
+// extractSchemas finds zod (z.object) and yup (yup.object) schemas
+func extractSchemas(source string) []ValidationSchema {
+	var schemas []ValidationSchema
+	for _, loc := range schemaDecl.FindAllStringSubmatchIndex(source, -1) {
+		open := loc[1] - 1
+		end := matchingBracket(source, open)
+		if end < 0 {
+			continue
+		}
+		library := "zod"
+		if source[loc[4]:loc[5]] != "z" {
+			library = "yup"
+		}
+		schema := ValidationSchema{
+			Name:       source[loc[2]:loc[3]],
+			Library:    library,
+			LineNumber: 1 + strings.Count(source[:loc[0]], "\n"),
+		}
+		for _, entry := range splitTopLevel(source[open+1:end], ',') {
+			key, expr, ok := strings.Cut(entry, ":")
+			if !ok {
+				continue
+			}
+			key = strings.Trim(strings.TrimSpace(key), `"'`)
+			if field, ok := parseSchemaField(key, strings.TrimSpace(expr), library); ok {
+				schema.Fields = append(schema.Fields, field)
+			}
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+// parseSchemaField translates a chain such as z.string().min(2).email()
+func parseSchemaField(name, expr, library string) (SchemaField, bool) {
+	field := SchemaField{Name: name, Required: library == "zod", Source: strings.Join(strings.Fields(expr), " ")}
+
+	m := schemaBase.FindStringSubmatchIndex(expr)
+	if m == nil {
+		return field, false
+	}
+	base := expr[m[2]:m[3]]
+	end := matchingBracket(expr, m[1]-1)
+	if end < 0 {
+		return field, false
+	}
+	baseArgs := splitTopLevel(expr[m[1]:end], ',')
+
+	switch base {
+	case "string", "number", "boolean":
+		field.Type = base
+	case "enum":
+		field.Type = "enum"
+		if len(baseArgs) > 0 {
+			field.Rules = append(field.Rules, SchemaRule{Kind: "enum", Values: stringList(baseArgs[0])})
+		}
+	default:
+		field.Type = "other"
+	}
+
+	rest := expr[end+1:]
+	for {
+		rest = strings.TrimSpace(rest)
+		call := schemaCall.FindStringSubmatchIndex(rest)
+		if call == nil {
+			break
+		}
+		method := rest[call[2]:call[3]]
+		close := matchingBracket(rest, call[1]-1)
+		if close < 0 {
+			break
+		}
+		args := splitTopLevel(rest[call[1]:close], ',')
+		rest = rest[close+1:]
+
+		rule := SchemaRule{Kind: method}
+		msgArg := 1
+		switch method {
+		case "optional", "nullable", "nullish", "notRequired":
+			field.Required = false
+			continue
+		case "required":
+			field.Required = true
+			if len(args) > 0 {
+				field.Rules = append(field.Rules, SchemaRule{Kind: "required", Message: messageArg(args[0])})
+			}
+			continue
+		case "nonempty":
+			field.Required = true
+			rule.Kind, rule.Arg = "min", "1"
+			msgArg = 0
+		case "min", "max", "length":
+			if len(args) == 0 {
+				continue
+			}
+			rule.Arg = strings.TrimSpace(args[0])
+		case "email", "url", "uuid":
+			msgArg = 0
+		case "regex", "matches":
+			if len(args) == 0 {
+				continue
+			}
+			re, ok := goRegexp(strings.TrimSpace(args[0]))
+			if !ok {
+				field.Unsupported = append(field.Unsupported, method+"("+args[0]+")")
+				continue
+			}
+			rule.Kind, rule.Arg = "regex", re
+		case "oneOf":
+			if len(args) == 0 {
+				continue
+			}
+			rule.Kind, rule.Values = "enum", stringList(args[0])
+		case "positive":
+			msgArg = 0
+		case "int", "integer":
+			rule.Kind = "int"
+			msgArg = 0
+		case "trim", "toLowerCase", "typeError", "default", "label":
+			continue
+		default:
+			field.Unsupported = append(field.Unsupported, method+"()")
+			continue
+		}
+		if len(args) > msgArg {
+			rule.Message = messageArg(args[msgArg])
+		}
+		field.Rules = append(field.Rules, rule)
+	}
+	return field, true
+}
+
+// goRegexp converts a JS regex literal (/^a+$/i) to Go regexp syntax
+func goRegexp(literal string) (string, bool) {
+	m := jsRegex.FindStringSubmatch(literal)
+	if m == nil {
+		return "", false
+	}
+	pattern := strings.ReplaceAll(m[1], `\/`, `/`)
+	if strings.Contains(m[2], "i") {
+		pattern = "(?i)" + pattern
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", false
+	}
+	return pattern, true
+}
+
+// messageArg returns the message from 'text' or { message: 'text' }
+func messageArg(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if m := messageProp.FindStringSubmatch(arg); m != nil {
+		return m[2]
+	}
+	if len(arg) >= 2 && strings.ContainsRune(`"'`+"`", rune(arg[0])) && arg[len(arg)-1] == arg[0] {
+		return arg[1 : len(arg)-1]
+	}
+	return ""
+}
+
+// stringList parses ['a', 'b'] into its string values
+func stringList(arg string) []string {
+	arg = strings.TrimSpace(arg)
+	arg = strings.TrimSuffix(strings.TrimPrefix(arg, "["), "]")
+	var values []string
+	for _, v := range splitTopLevel(arg, ',') {
+		v = strings.Trim(strings.TrimSpace(v), `"'`+"`")
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// matchingBracket returns the index of the bracket closing the one at
+// open, skipping string and regex literals, or -1
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\'', '`':
+			i = skipQuoted(s, i)
+		case '/':
+			if i+1 < len(s) && s[i+1] != '/' && s[i+1] != '*' && regexAllowed(s, i) {
+				i = skipQuoted(s, i)
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on sep outside brackets, strings and regexes
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\'', '`':
+			i = skipQuoted(s, i)
+		case '/':
+			if i+1 < len(s) && s[i+1] != '/' && s[i+1] != '*' && regexAllowed(s, i) {
+				i = skipQuoted(s, i)
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if strings.TrimSpace(s[start:]) != "" {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// skipQuoted returns the index of the delimiter closing the literal at i
+func skipQuoted(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] == quote {
+			return j
+		}
+	}
+	return len(s) - 1
+}
+
+// regexAllowed reports whether a / at i starts a regex literal rather than
+// a division, judging by the previous non-space character
+func regexAllowed(s string, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch s[j] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '(', ',', '=', ':', '[', '!', '&', '|', '?', '{', ';':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
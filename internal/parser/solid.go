@@ -0,0 +1,367 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// signalDecl matches a signal and its setter:
+	// const [count, setCount] = createSignal(0)
+	signalDecl = regexp.MustCompile(`\b(?:const|let|var)\s+\[\s*(\w+)\s*,\s*\w+\s*\]\s*=\s*createSignal\b`)
+	// memoDecl matches a memo: const doubled = createMemo(() => count() * 2)
+	memoDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\w+)\s*=\s*createMemo\s*\(`)
+	// propsComponent matches a component taking its props undestructured:
+	// function Counter(props) and const Counter = (props: Props) =>
+	propsComponent = regexp.MustCompile(`(?:\bfunction\s+[A-Z]\w*\s*|\b(?:const|let|var)\s+[A-Z]\w*\s*(?::[^=]+)?=\s*)\(\s*(props)\s*(?::\s*[\w.<>\[\]]+\s*)?\)`)
+	// propsAccess matches a read of a prop: props.count
+	propsAccess = regexp.MustCompile(`\bprops\.(\w+)`)
+	// classList matches Solid's conditional class attribute
+	classList = regexp.MustCompile(`\bclassList\s*=\s*\{`)
+	// staticClass matches a static class attribute in an opening tag
+	staticClass = regexp.MustCompile(`\bclass(?:Name)?\s*=\s*"([^"]*)"`)
+)
+
+// DesugarSolid rewrites the reactive primitives of a SolidJS source into
+// their React equivalents, keeping every line where it was, so that the
+// parser reads them as state, derived values and effects:
+//
+//	createSignal(0)                 → useState(0)
+//	createMemo(() => count() * 2)   → count * 2
+//	createEffect(fn), onMount(fn)   → useEffect(fn), useEffect(fn, [])
+//	count()                         → count, for signals and memos
+//	function Counter(props)         → function Counter({ count }), for props.count
+//	classList={{ active: on() }}    → className={clsx({ active: on })}
+//
+// <For>, <Show> and <Switch> are mapped when the file is parsed.
+func DesugarSolid(source string) string {
+	getters := map[string]bool{}
+	for _, m := range signalDecl.FindAllStringSubmatch(source, -1) {
+		getters[m[1]] = true
+	}
+	for _, m := range memoDecl.FindAllStringSubmatch(source, -1) {
+		getters[m[1]] = true
+	}
+
+	source = unwrapMemos(source)
+	source = mountEffects(source)
+	source = strings.NewReplacer("createSignal", "useState", "createEffect", "useEffect").Replace(source)
+
+	if len(getters) > 0 {
+		names := make([]string, 0, len(getters))
+		for name := range getters {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		sort.Strings(names)
+		call := regexp.MustCompile(`(^|[^.\w$])(` + strings.Join(names, "|") + `)\(\s*\)`)
+		source = call.ReplaceAllString(source, "$1$2")
+	}
+	source = destructureProps(source)
+	return classLists(source)
+}
+
+// unwrapMemos replaces createMemo(() => expr) with expr, keeping the line
+// breaks of what is dropped. Memos with a block body are kept.
+func unwrapMemos(source string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range regexp.MustCompile(`\bcreateMemo\s*\(`).FindAllStringIndex(source, -1) {
+		if m[0] < last {
+			continue
+		}
+		e, n, err := ParseJSExprPrefix(source[m[0]:])
+		call, ok := e.(*JSCall)
+		if err != nil || !ok || len(call.Args) == 0 {
+			continue
+		}
+		fn, ok := Unparen(call.Args[0]).(*JSArrow)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		src := source[m[0]:]
+		body := fn.Body.Span()
+		dropped := src[:body.Start] + src[body.End:n]
+		b.WriteString(source[last:m[0]])
+		b.WriteString(src[body.Start:body.End])
+		b.WriteString(strings.Repeat("\n", strings.Count(dropped, "\n")))
+		last = m[0] + n
+	}
+	b.WriteString(source[last:])
+	return b.String()
+}
+
+// mountEffects replaces onMount(fn) with useEffect(fn, []), an effect
+// that runs once as onMount does
+func mountEffects(source string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range regexp.MustCompile(`\bonMount\s*\(`).FindAllStringIndex(source, -1) {
+		if m[0] < last {
+			continue
+		}
+		src := source[m[0]:]
+		e, n, err := ParseJSExprPrefix(src)
+		call, ok := e.(*JSCall)
+		if err != nil || !ok || len(call.Args) != 1 {
+			continue
+		}
+		arg := call.Args[0].Span()
+		b.WriteString(source[last:m[0]])
+		b.WriteString("useEffect(" + src[arg.Start:arg.End] + ", [])")
+		b.WriteString(strings.Repeat("\n", strings.Count(src[:arg.Start]+src[arg.End:n], "\n")))
+		last = m[0] + n
+	}
+	b.WriteString(source[last:])
+	return b.String()
+}
+
+// destructureProps rewrites a component that reads props.name into one
+// destructuring the props it reads. Components that pass props on whole,
+// as in {...props} or splitProps(props), are left alone.
+func destructureProps(source string) string {
+	for {
+		changed := false
+		for _, m := range propsComponent.FindAllStringSubmatchIndex(source, -1) {
+			end := componentEnd(source, m[1])
+			if end < 0 {
+				continue
+			}
+			body := source[m[1]:end]
+			var names []string
+			seen := map[string]bool{}
+			for _, a := range propsAccess.FindAllStringSubmatch(body, -1) {
+				if !seen[a[1]] {
+					seen[a[1]] = true
+					names = append(names, a[1])
+				}
+			}
+			rewritten := propsAccess.ReplaceAllString(body, "$1")
+			if len(names) == 0 || regexp.MustCompile(`\bprops\b`).MatchString(rewritten) {
+				continue
+			}
+			source = source[:m[2]] + "{ " + strings.Join(names, ", ") + " }" + source[m[3]:m[1]] + rewritten + source[end:]
+			changed = true
+			break
+		}
+		if !changed {
+			return source
+		}
+	}
+}
+
+// componentEnd returns the end of the body of the function whose
+// parameter list ends at i: a block, or an arrow's expression
+func componentEnd(source string, i int) int {
+	j := skipJSSpace(source, i)
+	if strings.HasPrefix(source[j:], "=>") {
+		j = skipJSSpace(source, j+2)
+		if j < len(source) && source[j] != '{' {
+			_, n, err := ParseJSExprPrefix(source[j:])
+			if err != nil {
+				return -1
+			}
+			return j + n
+		}
+	}
+	if j >= len(source) || source[j] != '{' {
+		return -1
+	}
+	return skipBalanced(source, j)
+}
+
+// classLists rewrites classList={{ active: on }} into
+// className={clsx({ active: on })}, folding in a static class attribute
+// written before it in the same tag
+func classLists(source string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range classList.FindAllStringIndex(source, -1) {
+		if m[0] < last {
+			continue
+		}
+		end := skipBalanced(source, m[1]-1)
+		if end < 0 {
+			break
+		}
+		args := source[m[1] : end-1]
+		before := source[last:m[0]]
+		if tag := strings.LastIndexByte(source[:m[0]], '<'); tag >= last {
+			if c := staticClass.FindStringSubmatchIndex(source[tag:m[0]]); c != nil {
+				// Drop the static attribute, keeping what follows it
+				before = source[last:tag+c[0]] + source[tag+c[1]:m[0]]
+				args = strconv.Quote(source[tag+c[2]:tag+c[3]]) + ", " + args
+			}
+		}
+		b.WriteString(before)
+		b.WriteString("className={clsx(" + args + ")}")
+		last = end
+	}
+	if last == 0 {
+		return source
+	}
+	b.WriteString(source[last:])
+	return b.String()
+}
+
+// solidFlow maps the control flow components of solid-js in the bodies of
+// file's components onto the nodes the generator translates: <For> and
+// <Index> onto iterations, <Show> and <Switch>/<Match> onto conditionals
+func (p *Parser) solidFlow(file *File) {
+	names := map[string]string{} // local name → solid-js component
+	for _, imp := range file.Imports {
+		if strings.Trim(imp.Source, "'\"`") != "solid-js" {
+			continue
+		}
+		for name, alias := range imp.Named {
+			switch name {
+			case "For", "Index", "Show", "Switch", "Match":
+				names[alias] = name
+			}
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	for i := range file.Components {
+		file.Components[i].Body = p.solidNode(file.Components[i].Body, names)
+	}
+}
+
+// solidNode returns node with its solid-js control flow mapped
+func (p *Parser) solidNode(node Node, names map[string]string) Node {
+	switch n := node.(type) {
+	case *Element:
+		for i, child := range n.Children {
+			n.Children[i] = p.solidNode(child, names)
+		}
+		switch names[n.Tag] {
+		case "For", "Index":
+			if m := p.solidFor(n, names[n.Tag] == "Index"); m != nil {
+				m.Body = p.solidNode(m.Body, names)
+				return m
+			}
+		case "Show":
+			when, ok := attrExpr(n, "when")
+			if !ok {
+				return n
+			}
+			consequent := solidChildren(n)
+			if fallback := p.solidFallback(n, names); fallback != nil {
+				return &Ternary{Condition: when, Consequent: consequent, Alternate: fallback, LineNumber: n.LineNumber}
+			}
+			return &Conditional{Condition: when, Consequent: consequent, LineNumber: n.LineNumber}
+		case "Switch":
+			// Each <Match> is tried in turn, and the fallback renders when
+			// none holds
+			result := p.solidFallback(n, names)
+			for i := len(n.Children) - 1; i >= 0; i-- {
+				match, ok := n.Children[i].(*Element)
+				if !ok || names[match.Tag] != "Match" {
+					continue
+				}
+				when, ok := attrExpr(match, "when")
+				if !ok {
+					return n
+				}
+				if result == nil {
+					result = &Conditional{Condition: when, Consequent: solidChildren(match), LineNumber: match.LineNumber}
+				} else {
+					result = &Ternary{Condition: when, Consequent: solidChildren(match), Alternate: result, LineNumber: match.LineNumber}
+				}
+			}
+			if result != nil {
+				return result
+			}
+		}
+	case *Fragment:
+		for i, child := range n.Children {
+			n.Children[i] = p.solidNode(child, names)
+		}
+	case *MapExpr:
+		n.Body = p.solidNode(n.Body, names)
+	case *Conditional:
+		n.Consequent = p.solidNode(n.Consequent, names)
+	case *Ternary:
+		n.Consequent = p.solidNode(n.Consequent, names)
+		n.Alternate = p.solidNode(n.Alternate, names)
+	}
+	return node
+}
+
+// solidFor maps <For each={items}>{(item, i) => <li/>}</For> onto the
+// iteration items.map((item, i) => <li/>) stands for. Solid passes the
+// index, and for <Index> the item, as an accessor: i() reads it.
+func (p *Parser) solidFor(elem *Element, index bool) *MapExpr {
+	each, ok := attrExpr(elem, "each")
+	if !ok {
+		return nil
+	}
+	var fn *Expression
+	for _, child := range elem.Children {
+		if expr, ok := child.(*Expression); ok {
+			fn = expr
+		}
+	}
+	if fn == nil {
+		return nil
+	}
+	raw := fn.Raw
+	if e, err := ParseJSExpr(raw); err == nil {
+		if arrow, ok := Unparen(e).(*JSArrow); ok {
+			accessor := 1
+			if index {
+				accessor = 0
+			}
+			if len(arrow.Params) > accessor && isSimpleIdent(arrow.Params[accessor]) {
+				name := regexp.QuoteMeta(arrow.Params[accessor])
+				raw = regexp.MustCompile(`(^|[^.\w$])(`+name+`)\(\s*\)`).ReplaceAllString(raw, "$1$2")
+			}
+		}
+	}
+	m, ok := p.analyzeExpression(Expression{Raw: each + ".map(" + raw + ")", LineNumber: elem.LineNumber}).(*MapExpr)
+	if !ok {
+		return nil
+	}
+	return m
+}
+
+// solidFallback returns the markup of a fallback prop, if any
+func (p *Parser) solidFallback(elem *Element, names map[string]string) Node {
+	raw, ok := attrExpr(elem, "fallback")
+	if !ok {
+		return nil
+	}
+	e, err := ParseJSExpr(raw)
+	if err != nil {
+		return &Expression{Raw: raw, LineNumber: elem.LineNumber}
+	}
+	return p.solidNode(p.branch(raw, e, elem.LineNumber), names)
+}
+
+// solidChildren returns the children of a control flow component as one
+// node
+func solidChildren(elem *Element) Node {
+	var children []Node
+	for _, child := range elem.Children {
+		if text, ok := child.(*Text); ok && strings.TrimSpace(text.Content) == "" {
+			continue
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &Fragment{Children: children, LineNumber: elem.LineNumber}
+}
+
+// attrExpr returns the expression an attribute passes: when={ready}
+func attrExpr(elem *Element, name string) (string, bool) {
+	for _, attr := range elem.Attributes {
+		if attr.Name == name && attr.Expression.Raw != "" {
+			return strings.TrimSpace(attr.Expression.Raw), true
+		}
+	}
+	return "", false
+}
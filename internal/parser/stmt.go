@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSStmt is a statement of a function body. Only the statements that
+// compute a value are parsed: declarations, ifs, returns and expressions.
+type JSStmt interface {
+	// Source is the text the spans of the statement's expressions are
+	// offsets into
+	Source() string
+}
+
+// JSReturn is return value; Value is nil for a bare return
+type JSReturn struct {
+	Value JSExpr
+	Src   string
+}
+
+// JSDecl is a const, let or var declaration of one name
+type JSDecl struct {
+	Kind  string // const, let or var
+	Name  string
+	Value JSExpr // nil without an initializer
+	Src   string
+}
+
+// JSIf is an if statement; Else is empty without an else branch
+type JSIf struct {
+	Test JSExpr
+	Then []JSStmt
+	Else []JSStmt
+	Src  string
+}
+
+// JSExprStmt is an expression evaluated for its effect: total += n
+type JSExprStmt struct {
+	X   JSExpr
+	Src string
+}
+
+func (s *JSReturn) Source() string   { return s.Src }
+func (s *JSDecl) Source() string     { return s.Src }
+func (s *JSIf) Source() string       { return s.Src }
+func (s *JSExprStmt) Source() string { return s.Src }
+
+// ParseJSStatements parses a function body, without its braces. Loops,
+// switches, try blocks and destructuring declarations are not supported
+// and make it fail.
+func ParseJSStatements(src string) ([]JSStmt, error) {
+	var stmts []JSStmt
+	i := 0
+	for {
+		i = skipJSSpace(src, i)
+		if i >= len(src) {
+			return stmts, nil
+		}
+		if src[i] == ';' {
+			i++
+			continue
+		}
+		stmt, end, err := parseJSStatement(src, i)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+		i = end
+	}
+}
+
+// parseJSStatement parses the statement at offset i of src, returning it
+// with the offset after it
+func parseJSStatement(src string, i int) (JSStmt, int, error) {
+	switch word := jsKeyword(src, i); word {
+	case "return":
+		j := skipJSSpace(src, i+len(word))
+		if j >= len(src) || src[j] == ';' || src[j] == '}' || strings.Contains(src[i+len(word):j], "\n") {
+			return &JSReturn{Src: src}, statementEnd(src, i+len(word)), nil
+		}
+		e, n, err := ParseJSExprPrefix(src[j:])
+		if err != nil {
+			return nil, 0, err
+		}
+		return &JSReturn{Value: e, Src: src[j:]}, statementEnd(src, j+n), nil
+
+	case "const", "let", "var":
+		j := skipJSSpace(src, i+len(word))
+		name := jsKeyword(src, j)
+		if name == "" {
+			return nil, 0, fmt.Errorf("unsupported declaration at offset %d", i)
+		}
+		j = skipJSSpace(src, j+len(name))
+		if j < len(src) && src[j] == ':' {
+			// TypeScript annotation: const n: number = 1
+			p := &jsParser{src: src, pos: j + 1}
+			if err := p.skipType(); err != nil {
+				return nil, 0, err
+			}
+			j = skipJSSpace(src, p.pos)
+		}
+		if j >= len(src) || src[j] != '=' {
+			return &JSDecl{Kind: word, Name: name, Src: src}, statementEnd(src, j), nil
+		}
+		j = skipJSSpace(src, j+1)
+		e, n, err := ParseJSExprPrefix(src[j:])
+		if err != nil {
+			return nil, 0, err
+		}
+		return &JSDecl{Kind: word, Name: name, Value: e, Src: src[j:]}, statementEnd(src, j+n), nil
+
+	case "if":
+		j := skipJSSpace(src, i+len(word))
+		if j >= len(src) || src[j] != '(' {
+			return nil, 0, fmt.Errorf("expected ( after if at offset %d", i)
+		}
+		end := skipBalanced(src, j)
+		if end < 0 {
+			return nil, 0, fmt.Errorf("unclosed if condition at offset %d", i)
+		}
+		test, err := ParseJSExpr(src[j+1 : end-1])
+		if err != nil {
+			return nil, 0, err
+		}
+		stmt := &JSIf{Test: test, Src: src[j+1 : end-1]}
+		stmt.Then, end, err = parseJSBranch(src, end)
+		if err != nil {
+			return nil, 0, err
+		}
+		if k := skipJSSpace(src, end); jsKeyword(src, k) == "else" {
+			stmt.Else, end, err = parseJSBranch(src, k+len("else"))
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return stmt, end, nil
+
+	case "for", "while", "do", "switch", "try", "throw", "function", "class", "break", "continue":
+		return nil, 0, fmt.Errorf("unsupported %s statement at offset %d", word, i)
+	}
+
+	if src[i] == '{' {
+		return nil, 0, fmt.Errorf("unsupported block at offset %d", i)
+	}
+	e, n, err := ParseJSExprPrefix(src[i:])
+	if err != nil {
+		return nil, 0, err
+	}
+	return &JSExprStmt{X: e, Src: src[i:]}, statementEnd(src, i+n), nil
+}
+
+// parseJSBranch parses the branch of an if at offset i: a block or a
+// single statement
+func parseJSBranch(src string, i int) ([]JSStmt, int, error) {
+	i = skipJSSpace(src, i)
+	if i < len(src) && src[i] == '{' {
+		end := skipBalanced(src, i)
+		if end < 0 {
+			return nil, 0, fmt.Errorf("unclosed block at offset %d", i)
+		}
+		stmts, err := ParseJSStatements(src[i+1 : end-1])
+		return stmts, end, err
+	}
+	stmt, end, err := parseJSStatement(src, i)
+	if err != nil {
+		return nil, 0, err
+	}
+	return []JSStmt{stmt}, end, nil
+}
+
+// jsKeyword returns the identifier at offset i of src, or ""
+func jsKeyword(src string, i int) string {
+	j := i
+	for j < len(src) && (src[j] == '_' || src[j] == '$' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || j > i && src[j] >= '0' && src[j] <= '9') {
+		j++
+	}
+	return src[i:j]
+}
+
+// statementEnd returns the offset after the semicolon ending a statement
+// at i, if there is one
+func statementEnd(src string, i int) int {
+	if j := skipJSSpace(src, i); j < len(src) && src[j] == ';' {
+		return j + 1
+	}
+	return i
+}
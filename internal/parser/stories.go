@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// storiesMeta matches the default export of a Storybook file
+	storiesMeta = regexp.MustCompile(`(?m)^export\s+default\s+`)
+	// storyExport matches a named export, optionally typed:
+	// export const Primary: Story = ...
+	storyExport = regexp.MustCompile(`(?m)^export\s+const\s+(\w+)\s*(?::[^=]+)?=\s*`)
+)
+
+// extractStories reads a Storybook file in Component Story Format (CSF 2
+// or 3), or returns nil if source is not one: its default export must be
+// an object naming the title or component
+func extractStories(source string) *Stories {
+	m := storiesMeta.FindStringIndex(source)
+	if m == nil {
+		return nil
+	}
+	src := source[m[1]:]
+	e, _, err := ParseJSExprPrefix(src)
+	if err != nil {
+		return nil
+	}
+	// const meta = { ... }; export default meta
+	if id, ok := Unparen(e).(*JSIdent); ok {
+		e, src = declaration(source, id.Name)
+	}
+	meta, ok := Unparen(e).(*JSObject)
+	if !ok {
+		return nil
+	}
+	line := func(offset int) int { return 1 + strings.Count(source[:offset], "\n") }
+
+	s := &Stories{LineNumber: line(m[0])}
+	for _, p := range meta.Props {
+		switch p.Key {
+		case "title":
+			if lit, ok := Unparen(p.Value).(*JSLiteral); ok && lit.Kind == "string" {
+				s.Title = lit.Value
+			}
+		case "component":
+			if p.Shorthand {
+				s.Component = p.Key
+			} else if id, ok := Unparen(p.Value).(*JSIdent); ok {
+				s.Component = id.Name
+			}
+		case "args":
+			s.Args = storyArgs(p.Value, src)
+		case "render":
+			s.Render = storyRender(p.Value, src)
+		}
+	}
+	if s.Title == "" && s.Component == "" {
+		return nil
+	}
+
+	for _, m := range storyExport.FindAllStringSubmatchIndex(source, -1) {
+		name := source[m[2]:m[3]]
+		if strings.HasPrefix(name, "__") {
+			// __namedExportsOrder and the like are not stories
+			continue
+		}
+		src := source[m[1]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		story := Story{Name: name, LineNumber: line(m[0])}
+		switch n := Unparen(e).(type) {
+		case *JSObject:
+			// CSF 3: export const Primary = { args: {...}, render: ... }
+			for _, p := range n.Props {
+				switch p.Key {
+				case "args":
+					story.Args = storyArgs(p.Value, src)
+				case "render":
+					story.Render = storyRender(p.Value, src)
+				}
+			}
+		case *JSArrow:
+			// CSF 2: export const Primary = () => <Button primary />
+			story.Render = storyRender(n, src)
+		case *JSCall:
+			// CSF 2: export const Primary = Template.bind({}), with
+			// Primary.args = {...} assigned below
+			member, ok := n.Callee.(*JSMember)
+			if !ok || member.Property != "bind" {
+				continue
+			}
+			if id, ok := member.Object.(*JSIdent); ok {
+				if tmpl, tmplSrc := declaration(source, id.Name); tmpl != nil {
+					story.Render = storyRender(tmpl, tmplSrc)
+				}
+			}
+		default:
+			continue
+		}
+		assign := regexp.MustCompile(`(?m)^\s*` + name + `\.args\s*=\s*`)
+		if a := assign.FindStringIndex(source); a != nil {
+			if e, _, err := ParseJSExprPrefix(source[a[1]:]); err == nil {
+				story.Args = storyArgs(e, source[a[1]:])
+			}
+		}
+		s.Stories = append(s.Stories, story)
+	}
+	return s
+}
+
+// storyArgs reads an args object literal; spreads are left out
+func storyArgs(e JSExpr, src string) []StoryArg {
+	obj, ok := Unparen(e).(*JSObject)
+	if !ok {
+		return nil
+	}
+	var args []StoryArg
+	for _, p := range obj.Props {
+		if p.Spread || p.Key == "" {
+			continue
+		}
+		args = append(args, StoryArg{Name: p.Key, Value: propText(p, src)})
+	}
+	return args
+}
+
+// storyRender returns the source of a render function, rewriting an
+// arrow function's block body that only returns into an expression body:
+// (args) => { return <Button {...args} /> } → (args) => <Button {...args} />
+func storyRender(e JSExpr, src string) string {
+	text := strings.TrimSpace(e.Span().Text(src))
+	fn, ok := Unparen(e).(*JSArrow)
+	if !ok || fn.Body != nil {
+		return text
+	}
+	block := strings.TrimSpace(fn.Block)
+	if !strings.HasPrefix(block, "return") {
+		return text
+	}
+	result := strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(block, "return")), ";")
+	return "(" + strings.Join(fn.Params, ", ") + ") => " + result
+}
@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// tableHook matches TanStack Table's useReactTable and react-table's
+	// useTable: const table = useReactTable({ data, columns, ... })
+	tableHook = regexp.MustCompile(`\b(?:const|let|var)\s+(\{[^}]*\}|\w+)\s*=\s*(useReactTable|useTable)\s*(?:<[^>]*>)?\s*\(`)
+	// gridElement matches an AG Grid or an Ant Design table
+	gridElement = regexp.MustCompile(`<(AgGridReact|Table)\b`)
+	// gridProp matches a grid prop holding an expression
+	gridProp = regexp.MustCompile(`\b(rowData|dataSource|columnDefs|columns|defaultColDef)\s*=\s*\{`)
+	// antdImport matches the import of Ant Design's Table
+	antdImport = regexp.MustCompile(`import\s*\{[^}]*\bTable\b[^}]*\}\s*from\s*['"]antd['"]`)
+)
+
+// componentTables finds the table library tables of the component on the
+// 1-based source lines [start, end), resolving column definitions
+// declared elsewhere in source
+func componentTables(source string, lines []string, start, end int) []DataTable {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	line := func(offset int) int { return start + strings.Count(body[:offset], "\n") }
+
+	var tables []DataTable
+	for _, m := range tableHook.FindAllStringSubmatchIndex(body, -1) {
+		src := body[m[4]:]
+		call, ok := parseCall(src)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		config, ok := Unparen(call.Args[0]).(*JSObject)
+		if !ok {
+			continue
+		}
+		t := DataTable{Library: "tanstack", LineNumber: line(m[0])}
+		if name := body[m[2]:m[3]]; !strings.HasPrefix(name, "{") {
+			t.Table = name
+		}
+		// react-table v7 sorts with the useSortBy plugin, v8 with a
+		// sorted row model
+		sortable := false
+		for _, plugin := range call.Args[1:] {
+			if id, ok := plugin.(*JSIdent); ok && id.Name == "useSortBy" {
+				sortable = true
+			}
+		}
+		var columns JSExpr
+		for _, p := range config.Props {
+			switch p.Key {
+			case "data":
+				t.Data = propText(p, src)
+			case "columns":
+				columns = p.Value
+			case "getSortedRowModel":
+				sortable = true
+			case "enableSorting":
+				sortable = !isFalse(p.Value)
+			}
+		}
+		if columns == nil {
+			continue
+		}
+		t.Columns = tableColumns(source, src, columns, sortable)
+		tables = append(tables, t)
+	}
+
+	antd := antdImport.MatchString(source)
+	for _, m := range gridElement.FindAllStringSubmatchIndex(body, -1) {
+		tag := body[m[0]:]
+		t := DataTable{Library: "ag-grid", LineNumber: line(m[0])}
+		if body[m[2]:m[3]] == "Table" {
+			if !antd {
+				continue
+			}
+			t.Library = "antd"
+		}
+		var columns JSExpr
+		var columnsSrc string
+		sortable, seen := false, map[string]bool{}
+		for _, p := range gridProp.FindAllStringSubmatchIndex(tag, -1) {
+			prop := tag[p[2]:p[3]]
+			src := tag[p[1]:]
+			e, _, err := ParseJSExprPrefix(src)
+			if seen[prop] || err != nil {
+				continue
+			}
+			seen[prop] = true
+			switch prop {
+			case "rowData", "dataSource":
+				t.Data = strings.TrimSpace(e.Span().Text(src))
+			case "columnDefs", "columns":
+				columns, columnsSrc = e, src
+			case "defaultColDef":
+				if obj, ok := declared(source, src, e).(*JSObject); ok {
+					for _, p := range obj.Props {
+						if p.Key == "sortable" {
+							sortable = !isFalse(p.Value)
+						}
+					}
+				}
+			}
+		}
+		if columns == nil {
+			continue
+		}
+		t.Columns = tableColumns(source, columnsSrc, columns, sortable)
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// tableColumns reads the column definitions e, an array literal in src
+// or the name of one in source. Column groups are flattened into their
+// columns.
+func tableColumns(source, src string, e JSExpr, sortable bool) []TableColumn {
+	e = Unparen(e)
+	if id, ok := e.(*JSIdent); ok {
+		e, src = declaration(source, id.Name)
+	}
+	e, src = unwrapDefs(e, src)
+	arr, ok := e.(*JSArray)
+	if !ok {
+		return nil
+	}
+	var columns []TableColumn
+	for _, el := range arr.Elements {
+		switch n := Unparen(el).(type) {
+		case *JSObject:
+			if group, ok := propValue(n, "columns"); ok {
+				columns = append(columns, tableColumns(source, src, group, sortable)...)
+				continue
+			}
+			columns = append(columns, readColumn(TableColumn{}, n, src, sortable))
+		case *JSCall:
+			// columnHelper.accessor('email', { header: 'Email' }) and
+			// columnHelper.display({ id: 'actions', cell: ... })
+			member, ok := n.Callee.(*JSMember)
+			if !ok || len(n.Args) == 0 {
+				continue
+			}
+			var col TableColumn
+			opts := n.Args[len(n.Args)-1]
+			switch member.Property {
+			case "accessor":
+				switch key := Unparen(n.Args[0]).(type) {
+				case *JSLiteral:
+					col.Field = key.Value
+				case *JSArrow:
+					col.Accessor = strings.TrimSpace(key.Span().Text(src))
+				}
+				if len(n.Args) < 2 {
+					opts = nil
+				}
+			case "display":
+			case "group":
+				if obj, ok := Unparen(opts).(*JSObject); ok {
+					if group, ok := propValue(obj, "columns"); ok {
+						columns = append(columns, tableColumns(source, src, group, sortable)...)
+					}
+				}
+				continue
+			default:
+				continue
+			}
+			obj, ok := Unparen(opts).(*JSObject)
+			if !ok {
+				obj = &JSObject{}
+			}
+			columns = append(columns, readColumn(col, obj, src, sortable))
+		}
+	}
+	return columns
+}
+
+// readColumn completes col with a column definition object of TanStack
+// Table, react-table, AG Grid or Ant Design
+func readColumn(col TableColumn, obj *JSObject, src string, sortable bool) TableColumn {
+	for _, p := range obj.Props {
+		value := Unparen(p.Value)
+		switch p.Key {
+		case "accessorKey", "field":
+			if lit, ok := value.(*JSLiteral); ok && lit.Kind == "string" {
+				col.Field = lit.Value
+			}
+		case "dataIndex":
+			// dataIndex: ['address', 'city'] reads a nested field
+			col.Field = dataIndex(value)
+		case "accessor":
+			if lit, ok := value.(*JSLiteral); ok && lit.Kind == "string" {
+				col.Field = lit.Value
+			} else {
+				col.Accessor = strings.TrimSpace(value.Span().Text(src))
+			}
+		case "accessorFn", "valueGetter":
+			col.Accessor = strings.TrimSpace(value.Span().Text(src))
+		case "id", "key":
+			if lit, ok := value.(*JSLiteral); ok && lit.Kind == "string" {
+				col.ID = lit.Value
+			}
+		case "header", "Header", "headerName", "title":
+			if lit, ok := value.(*JSLiteral); ok && lit.Kind == "string" {
+				col.Header = lit.Value
+			}
+		case "cell", "Cell", "cellRenderer", "valueFormatter", "render":
+			col.Cell = strings.TrimSpace(value.Span().Text(src))
+		case "sortable", "sorter":
+			sortable = !isFalse(value)
+		case "enableSorting":
+			sortable = sortable && !isFalse(value)
+		case "disableSortBy":
+			sortable = sortable && isFalse(value)
+		}
+	}
+	col.Sortable = sortable && col.Field != ""
+	return col
+}
+
+// dataIndex returns the field an Ant Design dataIndex reads: a name, or
+// a path of names joined with dots
+func dataIndex(e JSExpr) string {
+	switch n := e.(type) {
+	case *JSLiteral:
+		if n.Kind == "string" {
+			return n.Value
+		}
+	case *JSArray:
+		var path []string
+		for _, el := range n.Elements {
+			lit, ok := Unparen(el).(*JSLiteral)
+			if !ok || lit.Kind != "string" {
+				return ""
+			}
+			path = append(path, lit.Value)
+		}
+		return strings.Join(path, ".")
+	}
+	return ""
+}
+
+// declaration returns the initializer of the top-level or component
+// variable name in source, and the source it is parsed from
+func declaration(source, name string) (JSExpr, string) {
+	q := regexp.QuoteMeta(name)
+	decl := regexp.MustCompile(`\b(?:const|let|var)\s+(?:` + q + `\b\s*(?::[^=]+)?|\[\s*` + q + `\b[^\]]*\]\s*)=\s*`)
+	m := decl.FindStringIndex(source)
+	if m == nil {
+		return nil, ""
+	}
+	src := source[m[1]:]
+	e, _, err := ParseJSExprPrefix(src)
+	if err != nil {
+		return nil, ""
+	}
+	return e, src
+}
+
+// declared returns e, or the initializer of the variable it names
+func declared(source, src string, e JSExpr) JSExpr {
+	e = Unparen(e)
+	if id, ok := e.(*JSIdent); ok {
+		e, src = declaration(source, id.Name)
+	}
+	e, _ = unwrapDefs(e, src)
+	return e
+}
+
+// unwrapDefs returns the definitions that useMemo(() => [...], []) and
+// useState([...]) wrap, and the source they are parsed from
+func unwrapDefs(e JSExpr, src string) (JSExpr, string) {
+	for depth := 0; depth < 4 && e != nil; depth++ {
+		switch n := Unparen(e).(type) {
+		case *JSCall:
+			callee := MemberPath(n.Callee)
+			if len(n.Args) == 0 || callee != "useMemo" && callee != "React.useMemo" && callee != "useState" && callee != "React.useState" {
+				return n, src
+			}
+			e = n.Args[0]
+		case *JSArrow:
+			if n.Body != nil {
+				e = n.Body
+				continue
+			}
+			block := strings.TrimSpace(n.Block)
+			if !strings.HasPrefix(block, "return") {
+				return n, src
+			}
+			src = strings.TrimPrefix(block, "return")
+			var err error
+			if e, _, err = ParseJSExprPrefix(src); err != nil {
+				return nil, ""
+			}
+		default:
+			return n, src
+		}
+	}
+	return Unparen(e), src
+}
+
+// propValue returns the value of the property key of obj
+func propValue(obj *JSObject, key string) (JSExpr, bool) {
+	for _, p := range obj.Props {
+		if p.Key == key && !p.Spread {
+			return p.Value, p.Value != nil
+		}
+	}
+	return nil, false
+}
+
+// propText returns the source of a property's value; its key for { data }
+func propText(p JSProperty, src string) string {
+	if p.Shorthand || p.Value == nil {
+		return p.Key
+	}
+	return strings.TrimSpace(p.Value.Span().Text(src))
+}
+
+// isFalse reports whether e is the literal false
+func isFalse(e JSExpr) bool {
+	lit, ok := Unparen(e).(*JSLiteral)
+	return ok && lit.Kind == "bool" && lit.Value == "false"
+}
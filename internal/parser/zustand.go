@@ -0,0 +1,308 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// zustandDecl matches a Zustand store declaration, with or without the
+	// curried TypeScript form: const useStore = create<State>()(...)
+	zustandDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(use\w*)\s*=\s*create\s*(?:<[^>]*>)?\s*\(`)
+	// storeHookDecl matches a component reading a Zustand store:
+	// const count = useCounterStore(state => state.count)
+	storeHookDecl = regexp.MustCompile(`\b(?:const|let|var)\s+(\{[^}]*\}|\w+)\s*=\s*(use\w*)\s*\(`)
+	// storeHookName matches the conventional name of a store hook, for
+	// stores declared in another file
+	storeHookName = regexp.MustCompile(`^use\w*Store$`)
+)
+
+// extractStores finds the Zustand stores of a source file. Middleware
+// wrapping the creator, such as persist or devtools, is looked through.
+func extractStores(source string) []ZustandStore {
+	if !strings.Contains(source, "zustand") {
+		return nil
+	}
+	var stores []ZustandStore
+	for _, m := range zustandDecl.FindAllStringSubmatchIndex(source, -1) {
+		start := m[1] - 1
+		if rest := skipJSSpace(source, m[1]); rest < len(source) && source[rest] == ')' {
+			// create<State>()((set) => ...)
+			start = skipJSSpace(source, rest+1)
+		}
+		src := source[start:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		creator := Unparen(e)
+		for {
+			call, ok := creator.(*JSCall)
+			if !ok || len(call.Args) == 0 {
+				break
+			}
+			creator = Unparen(call.Args[0])
+		}
+		fn, ok := creator.(*JSArrow)
+		if !ok || fn.Body == nil || len(fn.Params) == 0 {
+			continue
+		}
+		obj, ok := Unparen(fn.Body).(*JSObject)
+		if !ok {
+			continue
+		}
+
+		store := ZustandStore{
+			Hook:       source[m[2]:m[3]],
+			LineNumber: 1 + strings.Count(source[:m[0]], "\n"),
+		}
+		set := strings.TrimSpace(fn.Params[0])
+		if len(fn.Params) > 1 {
+			store.Get = strings.TrimSpace(fn.Params[1])
+		}
+		for _, prop := range obj.Props {
+			if prop.Key == "" || prop.Spread || prop.Value == nil {
+				continue
+			}
+			line := store.LineNumber + strings.Count(source[m[0]:start+prop.Value.Span().Start], "\n")
+			action, ok := Unparen(prop.Value).(*JSArrow)
+			if !ok {
+				value := strings.TrimSpace(prop.Value.Span().Text(src))
+				store.State = append(store.State, StateVariable{Name: prop.Key, InitValue: value, InitType: inferTypeFromValue(value), LineNumber: line})
+				continue
+			}
+			store.Actions = append(store.Actions, storeAction(prop.Key, action, src, set, line))
+		}
+		stores = append(stores, store)
+	}
+	return stores
+}
+
+// storeAction records the set calls of a store action
+func storeAction(name string, fn *JSArrow, src, set string, line int) StoreAction {
+	action := StoreAction{Name: name, Params: fn.Params, LineNumber: line}
+	var calls []JSExpr
+	if fn.Body != nil {
+		action.Body = strings.TrimSpace(fn.Body.Span().Text(src))
+		calls = append(calls, fn.Body)
+	} else {
+		action.Body = fn.Block
+		for rest := fn.Block; ; {
+			rest = rest[skipJSSpace(rest, 0):]
+			rest = strings.TrimLeft(rest, ";")
+			rest = rest[skipJSSpace(rest, 0):]
+			if rest == "" {
+				break
+			}
+			e, end, err := ParseJSExprPrefix(rest)
+			if err != nil {
+				action.Other = true
+				break
+			}
+			calls = append(calls, e)
+			rest = rest[end:]
+		}
+	}
+	for _, e := range calls {
+		call, ok := Unparen(e).(*JSCall)
+		if !ok || MemberPath(call.Callee) != set || len(call.Args) == 0 {
+			action.Other = true
+			break
+		}
+		update := StoreSet{}
+		arg := Unparen(call.Args[0])
+		if updater, ok := arg.(*JSArrow); ok && updater.Body != nil && len(updater.Params) > 0 {
+			update.State = strings.TrimSpace(updater.Params[0])
+			arg = Unparen(updater.Body)
+		}
+		obj, ok := arg.(*JSObject)
+		if !ok {
+			action.Other = true
+			break
+		}
+		text := src
+		if fn.Body == nil {
+			text = fn.Block
+		}
+		for _, prop := range obj.Props {
+			if prop.Key == "" || prop.Spread {
+				continue
+			}
+			value := prop.Key
+			if !prop.Shorthand && prop.Value != nil {
+				value = strings.TrimSpace(prop.Value.Span().Text(text))
+			}
+			update.Fields = append(update.Fields, HookValue{Name: prop.Key, Value: value})
+		}
+		action.Sets = append(action.Sets, update)
+	}
+	return action
+}
+
+// componentStoreHooks finds the Zustand store reads of the component on
+// the 1-based source lines [start, end), and the calls of the store
+// actions it binds. Stores declared in another file are recognised by
+// their use…Store name; their functions are told from their values by
+// the component calling them.
+func componentStoreHooks(stores []ZustandStore, lines []string, start, end int) ([]StoreRead, []Dispatch) {
+	body := strings.Join(lines[max(start-1, 0):min(end-1, len(lines))], "\n")
+	known := map[string]*ZustandStore{}
+	for i := range stores {
+		known[stores[i].Hook] = &stores[i]
+	}
+	isAction := func(store *ZustandStore, field, local string) bool {
+		if store != nil {
+			for _, a := range store.Actions {
+				if a.Name == field {
+					return true
+				}
+			}
+			return false
+		}
+		return regexp.MustCompile(`\b` + regexp.QuoteMeta(local) + `\s*\(|=\{\s*` + regexp.QuoteMeta(local) + `\s*\}`).MatchString(body)
+	}
+
+	var reads []StoreRead
+	var dispatches []Dispatch
+	for _, m := range storeHookDecl.FindAllStringSubmatchIndex(body, -1) {
+		hook := body[m[4]:m[5]]
+		store := known[hook]
+		if store == nil && !storeHookName.MatchString(hook) {
+			continue
+		}
+		src := body[m[4]:]
+		e, _, err := ParseJSExprPrefix(src)
+		if err != nil {
+			continue
+		}
+		call, ok := e.(*JSCall)
+		if !ok {
+			continue
+		}
+		line := start + strings.Count(body[:m[0]], "\n")
+		selector := ""
+		fields := map[string]string{} // local or key → field
+		whole := false
+		if len(call.Args) > 0 {
+			selector = strings.TrimSpace(call.Args[0].Span().Text(src))
+			fn, ok := Unparen(call.Args[0]).(*JSArrow)
+			if !ok || fn.Body == nil || len(fn.Params) != 1 {
+				continue
+			}
+			param := strings.TrimSpace(fn.Params[0])
+			switch sel := Unparen(fn.Body).(type) {
+			case *JSObject:
+				// useStore((s) => ({ count: s.count, inc: s.inc }), shallow)
+				for _, prop := range sel.Props {
+					path := strings.Split(MemberPath(prop.Value), ".")
+					if len(path) == 2 && path[0] == param {
+						fields[prop.Key] = path[1]
+					}
+				}
+			default:
+				path := strings.Split(MemberPath(sel), ".")
+				switch {
+				case len(path) == 1 && path[0] == param:
+					whole = true
+				case len(path) == 2 && path[0] == param:
+					fields[""] = path[1]
+				default:
+					continue
+				}
+			}
+		} else {
+			whole = true
+		}
+
+		names, keys := bindingNames(body[m[2]:m[3]])
+		bind := func(local, field string) {
+			if isAction(store, field, local) {
+				dispatches = append(dispatches, actionCalls(body, start, hook, field, local)...)
+				return
+			}
+			reads = append(reads, StoreRead{Name: local, Store: hook, Field: field, Selector: selector, LineNumber: line})
+		}
+		switch {
+		case len(keys) == 0 && whole:
+			reads = append(reads, StoreRead{Name: names[0], Store: hook, Selector: selector, LineNumber: line})
+		case len(keys) == 0:
+			if field, ok := fields[""]; ok {
+				bind(names[0], field)
+			}
+		default:
+			// const { count, inc } = useStore()
+			for i, key := range keys {
+				field := key
+				if !whole {
+					if field = fields[key]; field == "" {
+						continue
+					}
+				}
+				bind(names[i], field)
+			}
+		}
+	}
+	return reads, dispatches
+}
+
+// actionCalls finds where a component calls a store action bound to
+// local, or passes it as an event handler
+func actionCalls(body string, start int, hook, action, local string) []Dispatch {
+	use := regexp.MustCompile(`\b` + regexp.QuoteMeta(local) + `\s*\(|=\{\s*` + regexp.QuoteMeta(local) + `\s*\}`)
+	var dispatches []Dispatch
+	for _, loc := range use.FindAllStringIndex(body, -1) {
+		d := Dispatch{Action: action, Store: hook, Local: local, LineNumber: start + strings.Count(body[:loc[0]], "\n")}
+		if strings.HasSuffix(body[loc[0]:loc[1]], "(") {
+			src := body[loc[0]:]
+			e, _, err := ParseJSExprPrefix(src)
+			if call, ok := e.(*JSCall); err == nil && ok {
+				for _, arg := range call.Args {
+					d.Args = append(d.Args, strings.TrimSpace(arg.Span().Text(src)))
+				}
+			}
+		}
+		dispatches = append(dispatches, d)
+	}
+	return dispatches
+}
+
+// suggestStores describes where the file's Zustand stores and the
+// components' store reads and action calls end up
+func (p *Parser) suggestStores(stores []ZustandStore, comps []Component) {
+	for _, s := range stores {
+		p.addSuggestion(s.LineNumber, s.Hook+" = create(...)",
+			fmt.Sprintf("Zustand store: server-side state %s with a method per action", storeTypeName(s.Hook)), "zustandStore")
+	}
+	for _, comp := range comps {
+		for _, read := range comp.StoreReads {
+			if read.Store == "" {
+				continue
+			}
+			p.addSuggestion(read.LineNumber, read.Store+"("+read.Selector+")",
+				fmt.Sprintf("Read from %s on the server and passed to %s as the %s parameter", storeTypeName(read.Store), comp.Name, read.Name), "zustandSelector")
+		}
+		for _, d := range comp.Dispatches {
+			if d.Store == "" {
+				continue
+			}
+			with := ""
+			if len(d.Args) > 0 {
+				with = " with the arguments"
+			}
+			p.addSuggestion(d.LineNumber, fmt.Sprintf("%s(%s)", d.Local, strings.Join(d.Args, ", ")),
+				fmt.Sprintf("Zustand action: POST /%s/%s re-renders %s; port its handler to call %s.%s%s",
+					kebabIdent(comp.Name), kebabIdent(d.Action), comp.Name, storeTypeName(d.Store), exportIdent(d.Action), with), "zustandAction")
+		}
+	}
+}
+
+// storeTypeName names the Go struct of a Zustand store: useCartStore →
+// CartStore, useCounter → CounterStore
+func storeTypeName(hook string) string {
+	name := exportIdent(strings.TrimPrefix(hook, "use"))
+	if !strings.HasSuffix(name, "Store") {
+		name += "Store"
+	}
+	return name
+}
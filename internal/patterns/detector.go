@@ -1,7 +1,11 @@
 package patterns
 
 import (
+	"context"
+	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/ha1tch/reminty/internal/parser"
@@ -23,8 +27,29 @@ const (
 	PatternDarkMode       PatternType = "dark-mode"
 	PatternToggle         PatternType = "toggle"
 	PatternSortableTable  PatternType = "sortable-table"
+	PatternRealtime       PatternType = "realtime"
+	PatternEffect         PatternType = "effect"
+	PatternAutocomplete   PatternType = "autocomplete"
+	PatternTooltip        PatternType = "tooltip"
+	PatternStepper        PatternType = "stepper"
 )
 
+// Types lists every pattern type the detector reports
+func Types() []PatternType {
+	return []PatternType{
+		PatternTabs, PatternAccordion, PatternFilter, PatternSearch,
+		PatternFormDeps, PatternModal, PatternDropdown, PatternPagination,
+		PatternInfiniteScroll, PatternDarkMode, PatternToggle,
+		PatternSortableTable, PatternRealtime, PatternEffect,
+		PatternAutocomplete, PatternTooltip, PatternStepper,
+	}
+}
+
+// Rule returns the stable rule ID reported for the pattern type
+func (t PatternType) Rule() string {
+	return "pattern/" + string(t)
+}
+
 // DetectedPattern represents a pattern found in the code
 type DetectedPattern struct {
 	Type        PatternType
@@ -40,6 +65,9 @@ type DetectedPattern struct {
 // Detector analyzes React code for patterns
 type Detector struct {
 	patterns []DetectedPattern
+	disabled map[PatternType]bool
+	rules    []*rule           // added with AddRules
+	external []PatternDetector // added with Use
 }
 
 // NewDetector creates a new pattern detector
@@ -49,49 +77,86 @@ func NewDetector() *Detector {
 	}
 }
 
+// Disable stops the detector reporting the given pattern types. Unknown
+// types are an error, unless detectors added with Use may report them.
+func (d *Detector) Disable(types ...string) error {
+	known := map[PatternType]bool{}
+	for _, t := range Types() {
+		known[t] = true
+	}
+	for _, r := range d.rules {
+		known[PatternType(r.Type)] = true
+	}
+	for _, t := range types {
+		if !known[PatternType(t)] && len(d.external) == 0 {
+			return fmt.Errorf("unknown pattern type %q", t)
+		}
+		if d.disabled == nil {
+			d.disabled = map[PatternType]bool{}
+		}
+		d.disabled[PatternType(t)] = true
+	}
+	return nil
+}
+
 // Analyze looks for patterns in a parse result
 func (d *Detector) Analyze(result *parser.ParseResult) []DetectedPattern {
+	patterns, _ := d.AnalyzeContext(context.Background(), result)
+	return patterns
+}
+
+// AnalyzeContext is like Analyze but stops between components once ctx
+// is cancelled, returning the patterns found so far.
+func (d *Detector) AnalyzeContext(ctx context.Context, result *parser.ParseResult) ([]DetectedPattern, error) {
 	d.patterns = []DetectedPattern{}
 
 	for _, comp := range result.File.Components {
+		if err := ctx.Err(); err != nil {
+			return d.patterns, err
+		}
 		d.analyzeComponent(&comp)
 	}
 
-	return d.patterns
+	return d.patterns, nil
 }
 
 // AnalyzeSource analyzes raw source code for patterns
 func (d *Detector) AnalyzeSource(source string) []DetectedPattern {
-	d.patterns = []DetectedPattern{}
-
-	// Tab patterns
-	d.detectTabsPattern(source)
-
-	// Filter/search patterns
-	d.detectFilterPattern(source)
-
-	// Form dependency patterns
-	d.detectFormDepsPattern(source)
+	patterns, _ := d.AnalyzeSourceContext(context.Background(), source)
+	return patterns
+}
 
-	// Modal patterns
-	d.detectModalPattern(source)
+// AnalyzeSourceContext is like AnalyzeSource but checks ctx between
+// detectors, returning the patterns found so far once it is cancelled.
+func (d *Detector) AnalyzeSourceContext(ctx context.Context, source string) ([]DetectedPattern, error) {
+	d.patterns = []DetectedPattern{}
 
-	// Dark mode patterns
-	d.detectDarkModePattern(source)
+	detectors := []func(string){
+		d.detectTabsPattern,
+		d.detectFilterPattern,
+		d.detectFormDepsPattern,
+		d.detectModalPattern,
+		d.detectDarkModePattern,
+		d.detectPaginationPattern,
+		d.detectAccordionPattern,
+		d.detectTogglePattern,
+		d.detectSortableTablePattern,
+		d.detectRealtimePattern,
+		d.detectAutocompletePattern,
+		d.detectTooltipPattern,
+		d.detectStepperPattern,
+		d.detectAntdPatterns,
+		d.detectRules,
+	}
 
-	// Pagination patterns
-	d.detectPaginationPattern(source)
-	
-	// Accordion patterns
-	d.detectAccordionPattern(source)
-	
-	// Toggle patterns
-	d.detectTogglePattern(source)
-	
-	// Sortable table patterns
-	d.detectSortableTablePattern(source)
+	for _, detect := range detectors {
+		if err := ctx.Err(); err != nil {
+			return d.patterns, err
+		}
+		detect(source)
+	}
 
-	return d.patterns
+	return d.patterns, nil
 }
 
 func (d *Detector) analyzeComponent(comp *parser.Component) {
@@ -110,6 +175,8 @@ func (d *Detector) analyzeComponent(comp *parser.Component) {
 			d.analyzeEffectUsage(hook, comp)
 		}
 	}
+
+	d.analyzeRuleState(comp)
 }
 
 // analyzeStatePatterns detects patterns from useState variables
@@ -118,11 +185,18 @@ func (d *Detector) analyzeStatePatterns(comp *parser.Component) {
 	for _, sv := range comp.StateVars {
 		stateNames[strings.ToLower(sv.Name)] = sv
 	}
+	// Ranged in order, so the patterns are reported the same every run
+	names := make([]string, 0, len(stateNames))
+	for name := range stateNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 	
 	// Tab pattern: activeTab/selectedTab + string type
-	for name, sv := range stateNames {
+	for _, name := range names {
+		sv := stateNames[name]
 		if (strings.Contains(name, "tab") || strings.Contains(name, "selected")) && 
-			sv.InitType == "string" {
+			!strings.Contains(name, "step") && sv.InitType == "string" {
 			d.addPattern(DetectedPattern{
 				Type:        PatternTabs,
 				Line:        sv.LineNumber,
@@ -136,7 +210,8 @@ func (d *Detector) analyzeStatePatterns(comp *parser.Component) {
 	}
 	
 	// Filter pattern: filter/search state + derived .filter()
-	for name, sv := range stateNames {
+	for _, name := range names {
+		sv := stateNames[name]
 		if (strings.Contains(name, "filter") || strings.Contains(name, "search") || 
 			strings.Contains(name, "query")) && sv.InitType == "string" {
 			// Check if there's a corresponding derived filter
@@ -153,20 +228,29 @@ func (d *Detector) analyzeStatePatterns(comp *parser.Component) {
 				confidence = 0.95
 			}
 			
+			react := "useState for filter + .filter() derived state"
+			delay := 300
+			if db, ok := stateDebounce(comp, sv.Name); ok {
+				react += fmt.Sprintf(", debounced with %s", db.Via)
+				if db.Delay > 0 {
+					delay = db.Delay
+				}
+			}
 			d.addPattern(DetectedPattern{
 				Type:        PatternFilter,
 				Line:        sv.LineNumber,
 				Confidence:  confidence,
 				Description: "Filter/search with derived filtered list",
-				ReactCode:   "useState for filter + .filter() derived state",
+				ReactCode:   react,
 				StateVars:   []string{sv.Name},
-				MintyCode:   generateFilterMinty(sv.Name),
+				MintyCode:   generateFilterMinty(sv.Name, delay),
 			})
 		}
 	}
 	
 	// Modal/toggle pattern: boolean state for visibility
-	for name, sv := range stateNames {
+	for _, name := range names {
+		sv := stateNames[name]
 		if sv.InitType == "bool" {
 			if strings.Contains(name, "modal") || strings.Contains(name, "dialog") {
 				d.addPattern(DetectedPattern{
@@ -205,7 +289,8 @@ func (d *Detector) analyzeStatePatterns(comp *parser.Component) {
 	}
 	
 	// Pagination pattern: page number state
-	for name, sv := range stateNames {
+	for _, name := range names {
+		sv := stateNames[name]
 		if (strings.Contains(name, "page") || strings.Contains(name, "offset")) &&
 			(sv.InitType == "int" || sv.InitType == "float64") {
 			d.addPattern(DetectedPattern{
@@ -220,8 +305,25 @@ func (d *Detector) analyzeStatePatterns(comp *parser.Component) {
 		}
 	}
 	
+	// Stepper pattern: index of the current step
+	for _, name := range names {
+		sv := stateNames[name]
+		if strings.Contains(name, "step") && (sv.InitType == "int" || sv.InitType == "float64") {
+			d.addPattern(DetectedPattern{
+				Type:        PatternStepper,
+				Line:        sv.LineNumber,
+				Confidence:  0.85,
+				Description: "Stepper with current step index",
+				ReactCode:   "useState(" + sv.InitValue + ") for the current step",
+				StateVars:   []string{sv.Name},
+				MintyCode:   generateStepperMinty(sv.Name),
+			})
+		}
+	}
+	
 	// Sort pattern: sort column/direction state
-	for name, sv := range stateNames {
+	for _, name := range names {
+		sv := stateNames[name]
 		if strings.Contains(name, "sort") {
 			d.addPattern(DetectedPattern{
 				Type:        PatternSortableTable,
@@ -258,7 +360,7 @@ func (d *Detector) analyzeDerivedPatterns(comp *parser.Component) {
 					Description: "Client-side filtering detected",
 					ReactCode:   dv.Name + " = " + dv.SourceVar + ".filter(...)",
 					DerivedVars: []string{dv.Name},
-					MintyCode:   generateFilterMinty("filter"),
+					MintyCode:   generateFilterMinty("filter", 300),
 				})
 			}
 		case "sort":
@@ -370,7 +472,7 @@ darkMode.Toggle(b, mi.Class("p-2 rounded"))`,
 func (d *Detector) analyzeEffectUsage(hook parser.Hook, comp *parser.Component) {
 	// Effects often indicate side effects that should be server-side
 	d.addPattern(DetectedPattern{
-		Type:        PatternType("effect"),
+		Type:        PatternEffect,
 		Line:        hook.LineNumber,
 		Confidence:  0.5,
 		Description: "useEffect detected - consider server-side alternative",
@@ -387,6 +489,7 @@ func (d *Detector) detectTabsPattern(source string) {
 		regexp.MustCompile(`(?i)aria-selected`),
 		regexp.MustCompile(`(?i)className=.*tab.*active`),
 		regexp.MustCompile(`(?i)activeTab|selectedTab|currentTab`),
+		regexp.MustCompile(`<Tabs\.(?:Panel|Tab|List|Content|Trigger)\b`),
 	}
 
 	for _, pattern := range tabPatterns {
@@ -581,6 +684,9 @@ b.Button(
 }
 
 func (d *Detector) addPattern(p DetectedPattern) {
+	if d.disabled[p.Type] {
+		return
+	}
 	// Avoid duplicates
 	for _, existing := range d.patterns {
 		if existing.Type == p.Type && existing.Line == p.Line {
@@ -594,6 +700,103 @@ func countLines(s string) int {
 	return strings.Count(s, "\n") + 1
 }
 
+// realtimeSources are the client-side push/poll mechanisms that an SSE
+// stream can replace
+var realtimeSources = []struct {
+	re         *regexp.Regexp
+	kind       string
+	confidence float64
+}{
+	{regexp.MustCompile(`new\s+EventSource\(`), "EventSource", 0.9},
+	{regexp.MustCompile(`new\s+WebSocket\(|\bio\(\s*['"]|socket\.on\(`), "WebSocket", 0.85},
+	{regexp.MustCompile(`setInterval\(`), "polling", 0.75},
+}
+
+var (
+	useEffectCall = regexp.MustCompile(`useEffect\(`)
+	setterCall    = regexp.MustCompile(`\bset([A-Z]\w*)\(`)
+	stateDecl     = regexp.MustCompile(`const\s+\[\s*(\w+)\s*,\s*(\w+)\s*\]\s*=\s*useState\b`)
+)
+
+// detectRealtimePattern finds effects that keep state up to date from a
+// push channel or a polling timer (live notifications, counters)
+func (d *Detector) detectRealtimePattern(source string) {
+	setters := map[string]string{} // setter → state
+	for _, m := range stateDecl.FindAllStringSubmatch(source, -1) {
+		setters[m[2]] = m[1]
+	}
+
+	for _, loc := range useEffectCall.FindAllStringIndex(source, -1) {
+		open := loc[1] - 1
+		end := matchingParen(source, open)
+		body := source[open:end]
+
+		for _, src := range realtimeSources {
+			if !src.re.MatchString(body) {
+				continue
+			}
+			var states []string
+			seen := map[string]bool{}
+			for _, m := range setterCall.FindAllStringSubmatch(body, -1) {
+				state, ok := setters["set"+m[1]]
+				if ok && !seen[state] {
+					seen[state] = true
+					states = append(states, state)
+				}
+			}
+			if len(states) == 0 {
+				break
+			}
+			d.addPattern(DetectedPattern{
+				Type:        PatternRealtime,
+				Line:        countLines(source[:loc[0]]),
+				Confidence:  src.confidence,
+				Description: "Realtime updates via " + src.kind,
+				ReactCode:   src.kind + " in useEffect updating " + strings.Join(states, ", "),
+				StateVars:   states,
+				MintyCode:   generateRealtimeMinty(states),
+			})
+			break
+		}
+	}
+}
+
+// SSEEndpoint is the URL of the Server-Sent Events stream for a state variable
+func SSEEndpoint(state string) string {
+	return "/events/" + toKebab(state)
+}
+
+func toKebab(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// matchingParen returns the index just past the ) closing the ( at open
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
 // detectAccordionPattern looks for accordion/collapsible patterns
 func (d *Detector) detectAccordionPattern(source string) {
 	accPatterns := []*regexp.Regexp{
@@ -699,6 +902,218 @@ func (d *Detector) detectSortableTablePattern(source string) {
 	}
 }
 
+// autocompleteLibraries are the typeahead components an HTMX active
+// search replaces
+var autocompleteLibraries = []struct {
+	re         *regexp.Regexp
+	kind       string
+	confidence float64
+}{
+	{regexp.MustCompile(`from\s+['"]downshift['"]`), "downshift", 0.9},
+	{regexp.MustCompile(`from\s+['"]react-autosuggest['"]`), "react-autosuggest", 0.9},
+	{regexp.MustCompile(`from\s+['"]react-select/async(?:-creatable)?['"]`), "react-select", 0.9},
+	{regexp.MustCompile(`from\s+['"]react-select['"]`), "react-select", 0.7},
+}
+
+var (
+	suggestionState = regexp.MustCompile(`const\s+\[\s*(\w*(?:[sS]uggestions|[mM]atches|[rR]esults))\s*,\s*\w+\s*\]\s*=\s*useState\b`)
+	debounceCall    = regexp.MustCompile(`\b(?:debounce\w*|useDebounce\w*|setTimeout)\(`)
+	debounceDelay   = regexp.MustCompile(`\b(?:debounce\w*|useDebounce\w*|setTimeout)\([^;]*?,\s*(\d+)\s*\)`)
+	inputValue      = regexp.MustCompile(`<input\b[^>]*\bvalue=\{(\w+)\}`)
+)
+
+// detectAutocompletePattern finds typeaheads: a suggestion list filled
+// as the user types, from a library or from state of its own
+func (d *Detector) detectAutocompletePattern(source string) {
+	query := "query"
+	if m := inputValue.FindStringSubmatch(source); m != nil {
+		query = m[1]
+	}
+
+	for _, lib := range autocompleteLibraries {
+		if loc := lib.re.FindStringIndex(source); loc != nil {
+			d.addPattern(DetectedPattern{
+				Type:        PatternAutocomplete,
+				Line:        countLines(source[:loc[0]]),
+				Confidence:  lib.confidence,
+				Description: "Autocomplete via " + lib.kind,
+				ReactCode:   lib.kind + " typeahead",
+				MintyCode:   generateAutocompleteMinty(query, "suggestions", sourceDelay(source)),
+			})
+			return
+		}
+	}
+
+	m := suggestionState.FindStringSubmatchIndex(source)
+	if m == nil || inputValue.FindStringIndex(source) == nil {
+		return
+	}
+	list := source[m[2]:m[3]]
+	confidence, how := 0.7, "filled as the input changes"
+	if debounceCall.MatchString(source) {
+		confidence, how = 0.85, "filled from a debounced input"
+	}
+	d.addPattern(DetectedPattern{
+		Type:        PatternAutocomplete,
+		Line:        countLines(source[:m[0]]),
+		Confidence:  confidence,
+		Description: "Autocomplete suggestions " + how,
+		ReactCode:   "useState for " + list + " + <input value={" + query + "}>",
+		StateVars:   []string{list, query},
+		MintyCode:   generateAutocompleteMinty(query, list, sourceDelay(source)),
+	})
+}
+
+// stepperComponent matches the stepper components of the design systems:
+// MUI and Mantine <Stepper activeStep={step}>, Ant Design <Steps current={step}>
+var stepperComponent = regexp.MustCompile(`<(?:Stepper|Steps)\b[^>]*?\b(?:activeStep|active|current|index)=\{\s*(\w+)\s*\}|\buseSteps\(`)
+
+// detectStepperPattern finds stepper components driven by a step index
+func (d *Detector) detectStepperPattern(source string) {
+	m := stepperComponent.FindStringSubmatchIndex(source)
+	if m == nil {
+		return
+	}
+	state := "step"
+	if m[2] >= 0 {
+		state = source[m[2]:m[3]]
+	}
+	d.addPattern(DetectedPattern{
+		Type:        PatternStepper,
+		Line:        countLines(source[:m[0]]),
+		Confidence:  0.9,
+		Description: "Stepper component",
+		ReactCode:   strings.TrimSpace(source[m[0]:m[1]]),
+		MintyCode:   generateStepperMinty(state),
+	})
+}
+
+var (
+	antdImport = regexp.MustCompile(`from\s+['"]antd['"]`)
+	antdTable  = regexp.MustCompile(`<Table\b[^>]*?\bdataSource=`)
+	antdSorter = regexp.MustCompile(`\bsorter\s*:`)
+	antdSearch = regexp.MustCompile(`<Select\b[^>]*?\b(?:showSearch\b|onSearch=)`)
+	antdModal  = regexp.MustCompile(`<Modal\b[^>]*?\b(?:open|visible)=\{\s*(\w+)\s*\}`)
+)
+
+// detectAntdPatterns finds the Ant Design components that keep state the
+// server can hold instead: tables sorted by their columns' sorters,
+// selects searched as the user types and modals opened from state
+func (d *Detector) detectAntdPatterns(source string) {
+	if !antdImport.MatchString(source) {
+		return
+	}
+	if loc := antdTable.FindStringIndex(source); loc != nil && antdSorter.MatchString(source) {
+		d.addPattern(DetectedPattern{
+			Type:        PatternSortableTable,
+			Line:        countLines(source[:loc[0]]),
+			Confidence:  0.9,
+			Description: "Ant Design Table with column sorters",
+			ReactCode:   "<Table columns={…} dataSource={…}> with sorter columns",
+			MintyCode:   generateSortableMinty("sort"),
+		})
+	}
+	if loc := antdSearch.FindStringIndex(source); loc != nil {
+		d.addPattern(DetectedPattern{
+			Type:        PatternAutocomplete,
+			Line:        countLines(source[:loc[0]]),
+			Confidence:  0.85,
+			Description: "Ant Design Select with search",
+			ReactCode:   "<Select showSearch onSearch={…}>",
+			MintyCode:   generateAutocompleteMinty("q", "options", sourceDelay(source)),
+		})
+	}
+	if m := antdModal.FindStringSubmatchIndex(source); m != nil {
+		state := source[m[2]:m[3]]
+		d.addPattern(DetectedPattern{
+			Type:        PatternModal,
+			Line:        countLines(source[:m[0]]),
+			Confidence:  0.9,
+			Description: "Ant Design Modal opened from state",
+			ReactCode:   "<Modal open={" + state + "}>",
+			StateVars:   []string{state},
+			MintyCode:   generateModalMinty(state),
+		})
+	}
+}
+
+// sourceDelay returns the delay of the first debounce in source with a
+// constant one, or 300ms
+func sourceDelay(source string) int {
+	if m := debounceDelay.FindStringSubmatch(source); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 300
+}
+
+// stateDebounce returns how a component debounces a state variable
+func stateDebounce(comp *parser.Component, state string) (parser.Debounce, bool) {
+	for _, db := range comp.Debounces {
+		if db.State == state {
+			return db, true
+		}
+	}
+	return parser.Debounce{}, false
+}
+
+// tooltipLibraries are the tooltip and popover components CSS or HTMX
+// can stand in for
+var tooltipLibraries = []struct {
+	re         *regexp.Regexp
+	kind       string
+	popover    bool
+	confidence float64
+}{
+	{regexp.MustCompile(`from\s+['"]@radix-ui/react-tooltip['"]`), "Radix Tooltip", false, 0.9},
+	{regexp.MustCompile(`from\s+['"]@radix-ui/react-(?:popover|hover-card)['"]`), "Radix Popover", true, 0.9},
+	{regexp.MustCompile(`from\s+['"](?:react-tooltip|@tippyjs/react)['"]`), "a tooltip library", false, 0.9},
+	{regexp.MustCompile(`from\s+['"](?:@popperjs/core|react-popper|@floating-ui/react(?:-dom)?)['"]`), "Popper.js", true, 0.85},
+}
+
+var (
+	hoverState = regexp.MustCompile(`const\s+\[\s*(\w*(?:[hH]over\w*|[tT]ooltip\w*|[pP]opover\w*))\s*,\s*\w+\s*\]\s*=\s*useState\(\s*(?:false|null)?\s*\)`)
+	mouseEnter = regexp.MustCompile(`\bonMouse(?:Enter|Over)=`)
+)
+
+// detectTooltipPattern finds tooltips and popovers, from a library or
+// from hover state of the component's own
+func (d *Detector) detectTooltipPattern(source string) {
+	for _, lib := range tooltipLibraries {
+		if loc := lib.re.FindStringIndex(source); loc != nil {
+			kind := "Tooltip"
+			if lib.popover {
+				kind = "Popover"
+			}
+			d.addPattern(DetectedPattern{
+				Type:        PatternTooltip,
+				Line:        countLines(source[:loc[0]]),
+				Confidence:  lib.confidence,
+				Description: kind + " via " + lib.kind,
+				ReactCode:   lib.kind + " component",
+				MintyCode:   generateTooltipMinty(lib.popover),
+			})
+			return
+		}
+	}
+
+	m := hoverState.FindStringSubmatchIndex(source)
+	if m == nil || !mouseEnter.MatchString(source) {
+		return
+	}
+	state := source[m[2]:m[3]]
+	d.addPattern(DetectedPattern{
+		Type:        PatternTooltip,
+		Line:        countLines(source[:m[0]]),
+		Confidence:  0.75,
+		Description: "Tooltip shown from hover state",
+		ReactCode:   "useState for " + state + " + onMouseEnter/onMouseLeave",
+		StateVars:   []string{state},
+		MintyCode:   generateTooltipMinty(strings.Contains(strings.ToLower(state), "popover")),
+	})
+}
+
 // Helper functions to generate mintydyn code suggestions
 
 func generateTabsMinty(stateName, initValue string) string {
@@ -715,7 +1130,7 @@ func generateTabsMinty(stateName, initValue string) string {
 // GET /tabs?` + stateName + `=<value> → returns updated component HTML`
 }
 
-func generateFilterMinty(stateName string) string {
+func generateFilterMinty(stateName string, delay int) string {
 	return `mdy.Dyn("filter").
     Data(mdy.FilterableDataset{
         Items: items,
@@ -726,7 +1141,7 @@ func generateFilterMinty(stateName string) string {
         },
         Options: mdy.FilterOptions{
             EnableSearch: true,
-            Debounce:     300, // ms
+            Debounce:     ` + strconv.Itoa(delay) + `, // ms
         },
     }).
     Build()
@@ -735,6 +1150,18 @@ func generateFilterMinty(stateName string) string {
 // GET /filter?` + stateName + `=<value> → returns filtered results HTML`
 }
 
+func generateRealtimeMinty(states []string) string {
+	return fmt.Sprintf(`// Server-Sent Events (htmx sse extension):
+b.Div(
+    mi.Attr("hx-ext", "sse"),
+    mi.Attr("sse-connect", %q),
+    mi.Attr("sse-swap", %q),
+    // initial %s rendered server-side
+)
+// The handler streams "event: %s" messages with the re-rendered fragment`,
+		SSEEndpoint(states[0]), states[0], states[0], states[0])
+}
+
 func generateModalMinty(stateName string) string {
 	return `// HTMX modal pattern (recommended):
 b.Button(
@@ -817,6 +1244,70 @@ b.Div(mi.ID("pagination"),
 )`
 }
 
+func generateAutocompleteMinty(query, list string, delay int) string {
+	id := toKebab(list)
+	return `// HTMX active search: the server returns the suggestions as the user types
+b.Input(mi.Type("search"), mi.Name("` + query + `"),
+    mi.HtmxGet("/` + id + `"),
+    mi.HtmxTrigger("keyup changed delay:` + strconv.Itoa(delay) + `ms"),
+    mi.HtmxTarget("#` + id + `"),
+    mi.HtmxSwap("innerHTML"),
+    mi.Attr("autocomplete", "off"),
+)
+b.Ul(mi.ID("` + id + `"), mi.Role("listbox"))
+
+// Handler:
+// GET /` + id + `?` + query + `=<value> → returns the matching <li> items`
+}
+
+func generateTooltipMinty(popover bool) string {
+	if popover {
+		return `// Popover content loaded from the server when first opened
+b.Div(mi.Class("relative"),
+    b.Button(
+        mi.HtmxGet("/popover"),
+        mi.HtmxTrigger("click once"),
+        mi.HtmxTarget("next .popover"),
+        "Details",
+    ),
+    b.Div(mi.Class("popover absolute z-10 mt-2")),
+)
+// Or <details>/<summary> for a popover that needs no server round trip`
+	}
+	return `// Pure CSS tooltip: shown while the trigger is hovered or focused
+b.Span(mi.Class("relative inline-block group"),
+    trigger,
+    b.Span(mi.Role("tooltip"),
+        mi.Class("invisible group-hover:visible group-focus-within:visible absolute bottom-full left-1/2 -translate-x-1/2 mb-1 rounded bg-gray-900 px-2 py-1 text-xs text-white"),
+        "Tooltip text",
+    ),
+)
+// Or the native tooltip: mi.Attr("title", "Tooltip text")
+// Or HTMX on hover: mi.HtmxGet("/tooltip"), mi.HtmxTrigger("mouseenter once")`
+}
+
+func generateStepperMinty(stateName string) string {
+	endpoint := "/" + toKebab(stateName)
+	return `// Server-driven stepper: the handler renders the current step
+b.Ol(mi.Class("stepper"),
+    mi.EachWithIndex(steps, func(i int, label string) mi.H {
+        return func(b *mi.Builder) mi.Node {
+            return b.Li(mi.Class(stepClass(i, ` + stateName + `)), label) // your stepClass: done, current or upcoming
+        }
+    }),
+)
+b.Progress(mi.Value(strconv.Itoa(` + stateName + `+1)), mi.Max(strconv.Itoa(len(steps))))
+b.Button(
+    mi.HtmxPost("` + endpoint + `?` + stateName + `=" + strconv.Itoa(` + stateName + `+1)),
+    mi.HtmxTarget("closest .wizard"),
+    mi.HtmxSwap("outerHTML"),
+    "Next",
+)
+
+// Handler:
+// POST ` + endpoint + `?` + stateName + `=<n> → checks the step, returns the wizard at step n`
+}
+
 func generateSortableMinty(stateName string) string {
 	return `mdy.Dyn("table").
     Data(mdy.FilterableDataset{
@@ -839,3 +1330,82 @@ b.Th(
     "Name ↑",
 )`
 }
+
+// Rank returns the patterns detected with at least min confidence, the
+// most confident first, then by line and by type. The detector reports
+// everything it finds; how much of it to show is up to the caller.
+func Rank(detected []DetectedPattern, min float64) []DetectedPattern {
+	ranked := []DetectedPattern{}
+	for _, p := range detected {
+		if p.Confidence >= min {
+			ranked = append(ranked, p)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		switch {
+		case a.Confidence != b.Confidence:
+			return a.Confidence > b.Confidence
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		}
+		return strings.Join(a.StateVars, ",") < strings.Join(b.StateVars, ",")
+	})
+	return ranked
+}
+
+// StatesOf returns the state variables involved in the detected patterns
+// of the given types
+func StatesOf(detected []DetectedPattern, types []string) []string {
+	wanted := map[PatternType]bool{}
+	for _, t := range types {
+		wanted[PatternType(t)] = true
+	}
+	var states []string
+	for _, p := range detected {
+		if wanted[p.Type] {
+			states = append(states, p.StateVars...)
+		}
+	}
+	return states
+}
+
+// CheckTypes reports the first of types that is not a built-in pattern
+// type
+func CheckTypes(types ...string) error {
+	known := map[PatternType]bool{}
+	for _, t := range Types() {
+		known[t] = true
+	}
+	for _, t := range types {
+		if !known[PatternType(t)] {
+			return fmt.Errorf("unknown pattern type %q", t)
+		}
+	}
+	return nil
+}
+
+// Notes renders detected patterns as a block of Go comments, suitable for
+// appending to generated code.
+func Notes(patterns []DetectedPattern) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n// =============================================================================\n")
+	b.WriteString("// DETECTED PATTERNS - CONSIDER USING MINTYDYN\n")
+	b.WriteString("// =============================================================================\n")
+	for _, p := range patterns {
+		fmt.Fprintf(&b, "//\n// %s (line %d, confidence: %.0f%%)\n", p.Description, p.Line, p.Confidence*100)
+		if p.ReactCode != "" {
+			fmt.Fprintf(&b, "// React: %s\n", p.ReactCode)
+		}
+		b.WriteString("// Minty equivalent:\n")
+		for _, line := range strings.Split(p.MintyCode, "\n") {
+			fmt.Fprintf(&b, "//   %s\n", line)
+		}
+	}
+	return b.String()
+}
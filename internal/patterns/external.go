@@ -0,0 +1,52 @@
+package patterns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// PatternDetector finds patterns the built-in detectors do not know,
+// such as those of an organization's own design-system components. It
+// receives the source and parse result of a file.
+type PatternDetector interface {
+	Detect(ctx context.Context, source string, result *parser.ParseResult) ([]DetectedPattern, error)
+}
+
+// Use adds pattern detectors, run after the built-in ones and the rules.
+// The patterns they report are disabled, and deduplicated, like any other.
+func (d *Detector) Use(detectors ...PatternDetector) {
+	d.external = append(d.external, detectors...)
+}
+
+// AnalyzeFileContext runs every detector over a file: those that read
+// its source, those that read its parse result, and those added with
+// Use. An error from an added detector is returned with the patterns
+// found so far.
+func (d *Detector) AnalyzeFileContext(ctx context.Context, source string, result *parser.ParseResult) ([]DetectedPattern, error) {
+	detected, err := d.AnalyzeSourceContext(ctx, source)
+	if err != nil {
+		return detected, err
+	}
+	parsed, err := d.AnalyzeContext(ctx, result)
+	detected = append(detected, parsed...)
+	if err != nil {
+		return detected, err
+	}
+
+	d.patterns = []DetectedPattern{}
+	for _, ext := range d.external {
+		found, err := ext.Detect(ctx, source, result)
+		if err != nil {
+			return append(detected, d.patterns...), err
+		}
+		for _, p := range found {
+			if p.Type == "" {
+				return append(detected, d.patterns...), fmt.Errorf("pattern at line %d has no type", p.Line)
+			}
+			d.addPattern(p)
+		}
+	}
+	return append(detected, d.patterns...), nil
+}
@@ -0,0 +1,201 @@
+package patterns
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/ha1tch/reminty/internal/config"
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Rule is a pattern defined outside the detector, for conventions of
+// its own such as a useOurModal hook. A rule reports its pattern where
+// one of its Match expressions matches the source, or for each state
+// variable whose name matches one of its State expressions.
+type Rule struct {
+	Type        string   `json:"type"` // a built-in type, or a new one
+	Description string   `json:"description"`
+	Confidence  float64  `json:"confidence"` // 0.7 if not given
+	Match       []string `json:"match"`      // source regular expressions; the first that matches reports the pattern
+	State       []string `json:"state"`      // state variable name regular expressions, case-insensitive
+	StateType   string   `json:"state-type"` // the initial value state variables need: string, bool, number, array, object
+	Minty       string   `json:"minty"`      // the suggested minty code, a text/template of RuleData
+}
+
+// RuleData is what the minty template of a rule can refer to
+type RuleData struct {
+	Component string // the component the state variable is declared in
+	State     string // the matching state variable
+	Setter    string
+	Init      string // the state variable's initial value
+	Match     string // the source text a Match expression found
+	Line      int
+}
+
+// defaultRuleConfidence is the confidence of a rule that gives none
+const defaultRuleConfidence = 0.7
+
+var ruleType = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// rule is a Rule ready to run
+type rule struct {
+	Rule
+	match []*regexp.Regexp
+	state []*regexp.Regexp
+	minty *template.Template
+}
+
+// LoadRules reads the rules of a YAML file:
+//
+//	rules:
+//	  - type: modal
+//	    description: In-house modal hook
+//	    confidence: 0.9
+//	    match: ['useOurModal\(']
+//	    minty: mdy.Dyn("modal")...
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Rules []Rule `json:"rules"`
+	}
+	if err := config.UnmarshalYAML(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range file.Rules {
+		if _, err := compileRule(r); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// compileRule checks a rule and compiles its expressions and template
+func compileRule(r Rule) (*rule, error) {
+	if !ruleType.MatchString(r.Type) {
+		return nil, fmt.Errorf("rule type %q: want lowercase letters, digits and dashes", r.Type)
+	}
+	if len(r.Match) == 0 && len(r.State) == 0 {
+		return nil, fmt.Errorf("rule %s: needs match or state expressions", r.Type)
+	}
+	if r.Confidence < 0 || r.Confidence > 1 {
+		return nil, fmt.Errorf("rule %s: confidence must be between 0 and 1", r.Type)
+	}
+	switch r.StateType {
+	case "", "string", "bool", "number", "array", "object":
+	default:
+		return nil, fmt.Errorf("rule %s: unknown state-type %q", r.Type, r.StateType)
+	}
+	if r.Confidence == 0 {
+		r.Confidence = defaultRuleConfidence
+	}
+	if r.Description == "" {
+		r.Description = r.Type + " pattern"
+	}
+	c := &rule{Rule: r}
+	for _, expr := range r.Match {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: match %q: %w", r.Type, expr, err)
+		}
+		c.match = append(c.match, re)
+	}
+	for _, expr := range r.State {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: state %q: %w", r.Type, expr, err)
+		}
+		c.state = append(c.state, re)
+	}
+	tmpl, err := template.New(r.Type).Parse(r.Minty)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: minty: %w", r.Type, err)
+	}
+	c.minty = tmpl
+	return c, nil
+}
+
+// AddRules adds rules to those the detector runs after its own. Their
+// types can then be disabled like the built-in ones.
+func (d *Detector) AddRules(rules ...Rule) error {
+	for _, r := range rules {
+		c, err := compileRule(r)
+		if err != nil {
+			return err
+		}
+		d.rules = append(d.rules, c)
+	}
+	return nil
+}
+
+// detectRules reports the rules whose expressions match the source
+func (d *Detector) detectRules(source string) {
+	for _, r := range d.rules {
+		for _, re := range r.match {
+			loc := re.FindStringIndex(source)
+			if loc == nil {
+				continue
+			}
+			data := RuleData{Match: source[loc[0]:loc[1]], Line: countLines(source[:loc[0]])}
+			d.addPattern(r.pattern(data, data.Match))
+			break
+		}
+	}
+}
+
+// analyzeRuleState reports the rules matching a component's state
+func (d *Detector) analyzeRuleState(comp *parser.Component) {
+	for _, r := range d.rules {
+		for _, sv := range comp.StateVars {
+			if r.StateType != "" && stateType(sv.InitType) != r.StateType {
+				continue
+			}
+			for _, re := range r.state {
+				if !re.MatchString(sv.Name) {
+					continue
+				}
+				data := RuleData{Component: comp.Name, State: sv.Name, Setter: sv.Setter, Init: sv.InitValue, Line: sv.LineNumber}
+				p := r.pattern(data, "useState("+sv.InitValue+") for "+sv.Name)
+				p.StateVars = []string{sv.Name}
+				d.addPattern(p)
+				break
+			}
+		}
+	}
+}
+
+// stateType names the type of a state variable's initial value as rules
+// give it
+func stateType(initType string) string {
+	switch {
+	case initType == "int" || initType == "float64":
+		return "number"
+	case strings.HasPrefix(initType, "[]"):
+		return "array"
+	case strings.HasPrefix(initType, "map"):
+		return "object"
+	}
+	return initType
+}
+
+// pattern is the pattern the rule reports for data
+func (r *rule) pattern(data RuleData, react string) DetectedPattern {
+	var minty strings.Builder
+	if err := r.minty.Execute(&minty, data); err != nil {
+		minty.Reset()
+		minty.WriteString(r.Minty)
+	}
+	return DetectedPattern{
+		Type:        PatternType(r.Type),
+		Line:        data.Line,
+		Confidence:  r.Confidence,
+		Description: r.Description,
+		ReactCode:   strings.TrimSpace(react),
+		MintyCode:   strings.TrimRight(minty.String(), "\n"),
+	}
+}
@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/patterns"
+)
+
+// Analysis is what editor, browser and API clients are told about a
+// source: its components, detected patterns, suggestions, warnings and
+// audit findings
+type Analysis struct {
+	Components  []string        `json:"components"`
+	Patterns    []Pattern       `json:"patterns"`
+	Suggestions []Suggestion    `json:"suggestions"`
+	Warnings    []Warning       `json:"warnings"`
+	Findings    []audit.Finding `json:"findings"`
+}
+
+type Pattern struct {
+	Type        string  `json:"type"`
+	Line        int     `json:"line"`
+	Confidence  float64 `json:"confidence"`
+	Description string  `json:"description"`
+	ReactCode   string  `json:"reactCode"`
+	MintyCode   string  `json:"mintyCode"`
+}
+
+type Suggestion struct {
+	Line      int    `json:"line"`
+	Kind      string `json:"kind"`
+	ReactCode string `json:"reactCode"`
+	MintyHint string `json:"mintyHint"`
+}
+
+type Warning struct {
+	Line    int             `json:"line"`
+	Column  int             `json:"column"`
+	Message string          `json:"message"`
+	Code    string          `json:"code,omitempty"`
+	Skipped *parser.Skipped `json:"skipped,omitempty"`
+}
+
+// Analyze lexes and parses a JSX source and detects its patterns in the
+// raw source, the parsed result and with any external detectors. It
+// returns the number of tokens read as well.
+func Analyze(ctx context.Context, input string, detector *patterns.Detector) (*parser.ParseResult, []patterns.DetectedPattern, int, error) {
+	tokens, err := parser.NewLexer(input).TokenizeContext(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	result, err := parser.NewParserWithSource(tokens, input).ParseContext(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	detected, err := detector.AnalyzeFileContext(ctx, input, result)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result, detected, len(tokens), nil
+}
+
+// NewAnalysis collects the analysis of a parsed source from its ranked
+// patterns and audit findings
+func NewAnalysis(result *parser.ParseResult, detected []patterns.DetectedPattern, findings []audit.Finding) *Analysis {
+	analysis := &Analysis{
+		Components:  []string{},
+		Patterns:    []Pattern{},
+		Suggestions: []Suggestion{},
+		Warnings:    []Warning{},
+		Findings:    append([]audit.Finding{}, findings...),
+	}
+	for _, comp := range result.File.Components {
+		analysis.Components = append(analysis.Components, comp.Name)
+	}
+	for _, p := range detected {
+		analysis.Patterns = append(analysis.Patterns, Pattern{
+			Type:        string(p.Type),
+			Line:        p.Line,
+			Confidence:  p.Confidence,
+			Description: p.Description,
+			ReactCode:   p.ReactCode,
+			MintyCode:   p.MintyCode,
+		})
+	}
+	for _, s := range result.Suggestions {
+		analysis.Suggestions = append(analysis.Suggestions, Suggestion{
+			Line:      s.Line,
+			Kind:      s.PatternType,
+			ReactCode: s.ReactCode,
+			MintyHint: s.MintyHint,
+		})
+	}
+	for _, w := range result.Warnings {
+		analysis.Warnings = append(analysis.Warnings, Warning{
+			Line:    w.Line,
+			Column:  w.Column,
+			Message: w.Message,
+			Code:    w.Code,
+			Skipped: w.Skipped,
+		})
+	}
+	return analysis
+}
+
+// At narrows an analysis down to the entries on a given line
+func (a *Analysis) At(line int) *Analysis {
+	at := &Analysis{
+		Components:  a.Components,
+		Patterns:    []Pattern{},
+		Suggestions: []Suggestion{},
+		Warnings:    []Warning{},
+		Findings:    []audit.Finding{},
+	}
+	for _, p := range a.Patterns {
+		if p.Line == line {
+			at.Patterns = append(at.Patterns, p)
+		}
+	}
+	for _, s := range a.Suggestions {
+		if s.Line == line {
+			at.Suggestions = append(at.Suggestions, s)
+		}
+	}
+	for _, w := range a.Warnings {
+		if w.Line == line {
+			at.Warnings = append(at.Warnings, w)
+		}
+	}
+	for _, f := range a.Findings {
+		if f.Line == line {
+			at.Findings = append(at.Findings, f)
+		}
+	}
+	return at
+}
@@ -0,0 +1,106 @@
+// Package pipeline sets up the generator and pattern detector a
+// conversion runs with, so that the reminty command and the library
+// configure them from the same settings in the same way.
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/patterns"
+	"github.com/ha1tch/reminty/internal/plugin"
+)
+
+// Settings are the options that shape code generation and pattern
+// detection. The zero value gives the defaults.
+type Settings struct {
+	Mappings           []string          // design-system mapping packs
+	Tailwind           bool              // translate static inline styles into Tailwind classes
+	KeyIDs             bool              // keep the keys of list items htmx swaps as their ids
+	PreserveWhitespace bool              // keep the text of <pre> and <textarea> as written
+	StripTestAttrs     bool              // leave data-testid and data-cy attributes out
+	Framework          string            // react, nextjs or solid
+	Interactivity      string            // htmx or alpine
+	Package            string            // package clause of the generated code
+	Templates          string            // directory of the templates shaping the code
+	MintyImport        string            // import path of minty, or name=path
+	MintydynImport     string            // import path of mintydyn, or name=path
+	Tags               map[string]string // tag → builder method overrides
+	Attributes         map[string]string // attribute → option function overrides
+
+	Rules           []patterns.Rule // pattern rules detected as well as the built-in ones
+	Detectors       []string        // executables detecting patterns as well
+	DisablePatterns []string        // pattern types not to report
+	AlpinePatterns  []string        // pattern types whose show/hide state Alpine.js keeps
+}
+
+// NewGenerator returns a generator configured from the settings
+func (s *Settings) NewGenerator() (*generator.Generator, error) {
+	gen := generator.NewGenerator()
+	if err := gen.UseMappings(s.Mappings...); err != nil {
+		return nil, err
+	}
+	gen.UseTailwind(s.Tailwind)
+	gen.UseKeyIDs(s.KeyIDs)
+	gen.UsePreserveWhitespace(s.PreserveWhitespace)
+	gen.UseStripTestAttrs(s.StripTestAttrs)
+	if err := gen.UseFramework(s.Framework); err != nil {
+		return nil, err
+	}
+	if err := gen.UseInteractivity(s.Interactivity); err != nil {
+		return nil, err
+	}
+	if err := gen.UsePackage(s.Package); err != nil {
+		return nil, err
+	}
+	if s.Templates != "" {
+		t, err := generator.LoadTemplates(s.Templates)
+		if err != nil {
+			return nil, err
+		}
+		if err := gen.UseTemplates(t); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.Aliases(); err != nil {
+		return nil, err
+	}
+	if err := gen.UseTagMethods(s.Tags); err != nil {
+		return nil, err
+	}
+	if err := gen.UseAttributes(s.Attributes); err != nil {
+		return nil, err
+	}
+	return gen, nil
+}
+
+// Aliases returns the minty and mintydyn imports of the generated code
+func (s *Settings) Aliases() (generator.Aliases, error) {
+	minty, err := generator.ParseImport(s.MintyImport, generator.DefaultAliases.Minty)
+	if err != nil {
+		return generator.Aliases{}, fmt.Errorf("minty import: %w", err)
+	}
+	dyn, err := generator.ParseImport(s.MintydynImport, generator.DefaultAliases.Mintydyn)
+	if err != nil {
+		return generator.Aliases{}, fmt.Errorf("mintydyn import: %w", err)
+	}
+	return generator.Aliases{Minty: minty, Mintydyn: dyn}, nil
+}
+
+// NewDetector returns a pattern detector configured from the settings
+func (s *Settings) NewDetector() (*patterns.Detector, error) {
+	detector := patterns.NewDetector()
+	if err := detector.AddRules(s.Rules...); err != nil {
+		return nil, err
+	}
+	for _, path := range s.Detectors {
+		detector.Use(plugin.Detector{Path: path})
+	}
+	if err := detector.Disable(s.DisablePatterns...); err != nil {
+		return nil, err
+	}
+	if err := patterns.CheckTypes(s.AlpinePatterns...); err != nil {
+		return nil, err
+	}
+	return detector, nil
+}
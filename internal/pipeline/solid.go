@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/patterns"
+)
+
+// solidPrimitives are the React hooks parser.DesugarSolid turns the Solid
+// primitives into, with the primitives: a line of the source calling
+// createSignal was read as calling useState
+var solidPrimitives = []struct{ react, solid string }{
+	{"useState", "createSignal"},
+	{"useEffect", "createEffect"},
+	{"useEffect", "onMount"},
+}
+
+// SolidNames names the Solid primitives of source in the notes and
+// patterns found in parser.DesugarSolid(source), which name the React
+// hooks they were read as: the useEffect of a line calling createEffect
+// is reported as createEffect
+func SolidNames(source string, result *parser.ParseResult, detected []patterns.DetectedPattern) {
+	lines := strings.Split(source, "\n")
+	rename := func(line int, texts ...*string) {
+		if line < 1 || line > len(lines) {
+			return
+		}
+		for _, p := range solidPrimitives {
+			if !strings.Contains(lines[line-1], p.solid) {
+				continue
+			}
+			for _, text := range texts {
+				*text = strings.ReplaceAll(*text, p.react, p.solid)
+			}
+		}
+	}
+	for i := range result.Suggestions {
+		s := &result.Suggestions[i]
+		rename(s.Line, &s.ReactCode, &s.MintyHint)
+	}
+	for i := range detected {
+		d := &detected[i]
+		rename(d.Line, &d.Description, &d.ReactCode, &d.MintyCode)
+	}
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/patterns"
+)
+
+// DetectRequest is sent to a detector plugin on stdin
+type DetectRequest struct {
+	Version int                 `json:"version"` // parser.ASTVersion
+	Source  string              `json:"source"`  // original JSX text
+	AST     *parser.ParseResult `json:"ast"`
+}
+
+// DetectResponse is read from a detector plugin's stdout. A non-empty
+// Error aborts the conversion of the file.
+type DetectResponse struct {
+	Patterns []Pattern `json:"patterns"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Pattern is a pattern reported by a detector plugin
+type Pattern struct {
+	Type        string   `json:"type"`
+	Line        int      `json:"line"`
+	Confidence  float64  `json:"confidence"`
+	Description string   `json:"description"`
+	ReactCode   string   `json:"reactCode,omitempty"`
+	MintyCode   string   `json:"mintyCode,omitempty"`
+	StateVars   []string `json:"stateVars,omitempty"`
+}
+
+// Detector runs an executable as a patterns.PatternDetector. Like a
+// transform plugin it is started once per file, gets one DetectRequest
+// on stdin and answers with one DetectResponse on stdout.
+type Detector struct {
+	Path string
+}
+
+// Detect implements patterns.PatternDetector
+func (d Detector) Detect(ctx context.Context, source string, result *parser.ParseResult) ([]patterns.DetectedPattern, error) {
+	found, err := d.run(ctx, DetectRequest{
+		Version: parser.ASTVersion,
+		Source:  source,
+		AST:     result,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("detector %s: %w", d.Path, err)
+	}
+	return found, nil
+}
+
+func (d Detector) run(ctx context.Context, req DetectRequest) ([]patterns.DetectedPattern, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	var resp DetectResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	var found []patterns.DetectedPattern
+	for _, p := range resp.Patterns {
+		if p.Confidence < 0 || p.Confidence > 1 {
+			return nil, fmt.Errorf("pattern %s at line %d: confidence must be between 0 and 1", p.Type, p.Line)
+		}
+		found = append(found, patterns.DetectedPattern{
+			Type:        patterns.PatternType(p.Type),
+			Line:        p.Line,
+			Confidence:  p.Confidence,
+			Description: p.Description,
+			ReactCode:   p.ReactCode,
+			MintyCode:   p.MintyCode,
+			StateVars:   p.StateVars,
+		})
+	}
+	return found, nil
+}
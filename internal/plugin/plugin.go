@@ -0,0 +1,84 @@
+// Package plugin runs external programs over the parsed AST: transforms,
+// which rewrite it, and pattern detectors (see Detector).
+//
+// A plugin is any executable. For every converted file reminty starts it,
+// writes one Request as JSON to its stdin and closes it, then reads one
+// Response as JSON from its stdout. The plugin returns the (possibly
+// modified) parse result, which replaces the original before code
+// generation. Anything the plugin writes to stderr is passed through.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// Request is sent to a plugin on stdin
+type Request struct {
+	Version int                 `json:"version"` // parser.ASTVersion
+	File    string              `json:"file"`    // source file, relative to the input
+	Source  string              `json:"source"`  // original JSX text
+	AST     *parser.ParseResult `json:"ast"`
+}
+
+// Response is read from a plugin's stdout. A non-empty Error aborts the
+// conversion of the file; a missing AST leaves it unchanged.
+type Response struct {
+	AST   *parser.ParseResult `json:"ast,omitempty"`
+	Error string              `json:"error,omitempty"`
+}
+
+// Apply runs each plugin in turn, feeding every one the result of the
+// previous, and returns the final parse result
+func Apply(ctx context.Context, plugins []string, file, source string, result *parser.ParseResult) (*parser.ParseResult, error) {
+	for _, path := range plugins {
+		next, err := run(ctx, path, Request{
+			Version: parser.ASTVersion,
+			File:    file,
+			Source:  source,
+			AST:     result,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+		if next != nil {
+			result = next
+		}
+	}
+	return result, nil
+}
+
+func run(ctx context.Context, path string, req Request) (*parser.ParseResult, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if resp.AST != nil && resp.AST.File == nil {
+		return nil, errors.New("response has no file")
+	}
+	return resp.AST, nil
+}
@@ -0,0 +1,152 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/generator"
+)
+
+// CallSiteIssue is a component call whose generated arguments do not
+// match the generated function, or disagree with other callers
+type CallSiteIssue struct {
+	Component string
+	File      string
+	Line      int
+	Message   string
+}
+
+func (i CallSiteIssue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+}
+
+// definition is where a converted component was generated
+type definition struct {
+	file   string
+	params []generator.Param
+}
+
+// CallSiteIssues checks every recorded component call against the
+// signature generated for that component in any converted file. Calls
+// to components that were not converted are skipped.
+func (m *Manifest) CallSiteIssues() []CallSiteIssue {
+	defs := map[string]definition{}
+	for _, source := range m.Sources() {
+		for _, comp := range m.Files[source].Components {
+			if _, ok := defs[comp.Name]; !ok {
+				defs[comp.Name] = definition{file: source, params: comp.Params}
+			}
+		}
+	}
+
+	var issues []CallSiteIssue
+	// component → prop → kind → first call site passing that kind
+	kinds := map[string]map[string]map[string]string{}
+
+	for _, source := range m.Sources() {
+		for _, call := range m.Files[source].Calls {
+			def, ok := defs[call.Component]
+			if !ok {
+				continue
+			}
+			report := func(format string, args ...interface{}) {
+				issues = append(issues, CallSiteIssue{
+					Component: call.Component,
+					File:      source,
+					Line:      call.Line,
+					Message:   fmt.Sprintf(format, args...),
+				})
+			}
+
+			byProp := map[string]generator.Param{}
+			for _, p := range def.params {
+				if p.Prop != "" {
+					byProp[p.Prop] = p
+				}
+			}
+
+			var passed []string
+			for _, arg := range call.Args {
+				param, known := byProp[arg.Prop]
+				if !known {
+					if arg.Passed {
+						report("<%s> passes %s, which %s (%s) does not take", call.Component, arg.Prop, call.Component, def.file)
+					}
+				} else if !generator.KindFits(arg.Kind, param.Type) {
+					report("<%s> passes a %s as %s, but the parameter %s is %s", call.Component, arg.Kind, arg.Prop, param.Name, param.Type)
+				}
+				if arg.Passed {
+					passed = append(passed, arg.Prop)
+				}
+
+				if arg.Kind != "expr" {
+					if kinds[call.Component] == nil {
+						kinds[call.Component] = map[string]map[string]string{}
+					}
+					seen := kinds[call.Component][arg.Prop]
+					if seen == nil {
+						seen = map[string]string{}
+						kinds[call.Component][arg.Prop] = seen
+					}
+					if _, ok := seen[arg.Kind]; !ok {
+						seen[arg.Kind] = fmt.Sprintf("%s:%d", source, call.Line)
+					}
+				}
+			}
+
+			if call.Spread || call.Resolved {
+				// Resolved calls pass their arguments in parameter order
+				continue
+			}
+			want := make([]string, len(def.params))
+			for i, p := range def.params {
+				want[i] = p.Name
+				if p.Prop != "" {
+					want[i] = p.Prop
+				}
+			}
+			if n := len(def.params); n > 0 && strings.HasPrefix(def.params[n-1].Type, "...") &&
+				(len(passed) == 0 || passed[len(passed)-1] != want[n-1]) {
+				// Variadic children may be left out
+				want = want[:n-1]
+			}
+			if strings.Join(passed, ",") != strings.Join(want, ",") {
+				report("generated call %s(%s) does not match %s(%s) in %s; arguments are passed in attribute order",
+					call.Component, strings.Join(passed, ", "), call.Component, strings.Join(want, ", "), def.file)
+			}
+		}
+	}
+
+	// Callers that disagree on the kind of value a prop takes
+	for _, comp := range sortedKeys(kinds) {
+		for _, prop := range sortedKeys(kinds[comp]) {
+			seen := kinds[comp][prop]
+			if len(seen) < 2 {
+				continue
+			}
+			var uses []string
+			for _, kind := range sortedKeys(seen) {
+				uses = append(uses, fmt.Sprintf("a %s at %s", kind, seen[kind]))
+			}
+			issues = append(issues, CallSiteIssue{
+				Component: comp,
+				File:      defs[comp].file,
+				Message:   fmt.Sprintf("%s's %s prop is passed %s", comp, prop, strings.Join(uses, " and ")),
+			})
+		}
+	}
+	return issues
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,210 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/generator"
+)
+
+// LocalesDir is the directory holding the go-i18n catalogs, next to the
+// generated i18n.go
+const LocalesDir = "locales"
+
+// DefaultLanguage is the language extracted default messages are filed under
+const DefaultLanguage = "en"
+
+// Catalog is a go-i18n message file (active.<lang>.json), keyed by message ID
+type Catalog map[string]*CatalogMessage
+
+// CatalogMessage is one message with its plural forms
+type CatalogMessage struct {
+	Description string `json:"description,omitempty"`
+	Zero        string `json:"zero,omitempty"`
+	One         string `json:"one,omitempty"`
+	Two         string `json:"two,omitempty"`
+	Few         string `json:"few,omitempty"`
+	Many        string `json:"many,omitempty"`
+	Other       string `json:"other"`
+}
+
+// CatalogFile returns the file name of a language's catalog
+func CatalogFile(lang string) string {
+	return "active." + lang + ".json"
+}
+
+// Messages returns the translatable strings of every converted file. A
+// message ID used in several files keeps the first default text.
+func (m *Manifest) Messages() []generator.Message {
+	var messages []generator.Message
+	seen := map[string]bool{}
+	for _, source := range m.Sources() {
+		for _, msg := range m.Files[source].Messages {
+			if !seen[msg.ID] {
+				seen[msg.ID] = true
+				messages = append(messages, msg)
+			}
+		}
+	}
+	return messages
+}
+
+// Catalogs builds a catalog per language. The default language gets every
+// extracted message; translations already present in existing (loaded
+// from i18next locale files) take precedence over extracted defaults.
+func Catalogs(messages []generator.Message, existing map[string]Catalog) map[string]Catalog {
+	catalogs := map[string]Catalog{DefaultLanguage: {}}
+	for lang, catalog := range existing {
+		catalogs[lang] = catalog
+	}
+	def := catalogs[DefaultLanguage]
+	for _, msg := range messages {
+		where := fmt.Sprintf("%s:%d", msg.File, msg.Line)
+		if entry, ok := def[msg.ID]; ok {
+			if entry.Description == "" {
+				entry.Description = where
+			}
+			continue
+		}
+		def[msg.ID] = &CatalogMessage{Description: where, Other: msg.Other}
+	}
+	return catalogs
+}
+
+// WriteCatalogs writes each catalog into dir/locales. Messages already in
+// a catalog file on disk are kept as they are, so translations edited
+// there survive later conversions; only new message IDs are added.
+func WriteCatalogs(dir string, catalogs map[string]Catalog) error {
+	localesDir := filepath.Join(dir, LocalesDir)
+	for lang, catalog := range catalogs {
+		path := filepath.Join(localesDir, CatalogFile(lang))
+		merged := Catalog{}
+		data, err := os.ReadFile(path)
+		if err == nil {
+			if err := json.Unmarshal(data, &merged); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for id, msg := range catalog {
+			if _, ok := merged[id]; !ok {
+				merged[id] = msg
+			}
+		}
+		data, err = json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := WriteFile(path, append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// i18nextLocaleDirs are where React projects usually keep i18next
+// resources, relative to the source directory
+var i18nextLocaleDirs = []string{
+	"locales",
+	"public/locales",
+	"../public/locales",
+	"i18n/locales",
+}
+
+// LoadI18nextLocales reads existing i18next resources below srcDir, laid
+// out as locales/<lang>/<namespace>.json or locales/<lang>.json, and
+// converts them into catalogs. Namespaces are merged, nested keys are
+// joined with dots, and i18next plural suffixes (_one, _other, ...) become
+// plural forms of one message.
+func LoadI18nextLocales(srcDir string) (map[string]Catalog, error) {
+	catalogs := map[string]Catalog{}
+	for _, rel := range i18nextLocaleDirs {
+		dir := filepath.Join(srcDir, filepath.FromSlash(rel))
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			var files []string
+			lang := entry.Name()
+			if entry.IsDir() {
+				matches, _ := filepath.Glob(filepath.Join(dir, lang, "*.json"))
+				sort.Strings(matches)
+				files = matches
+			} else if filepath.Ext(lang) == ".json" {
+				lang = strings.TrimSuffix(lang, ".json")
+				files = []string{filepath.Join(dir, entry.Name())}
+			}
+			for _, file := range files {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return nil, err
+				}
+				var resources map[string]any
+				if err := json.Unmarshal(data, &resources); err != nil {
+					return nil, fmt.Errorf("%s: %w", file, err)
+				}
+				if catalogs[lang] == nil {
+					catalogs[lang] = Catalog{}
+				}
+				addResources(catalogs[lang], "", resources)
+			}
+		}
+		if len(catalogs) > 0 {
+			break
+		}
+	}
+	return catalogs, nil
+}
+
+// addResources flattens an i18next resource tree into a catalog
+func addResources(catalog Catalog, prefix string, resources map[string]any) {
+	for key, value := range resources {
+		switch v := value.(type) {
+		case map[string]any:
+			addResources(catalog, prefix+key+".", v)
+		case string:
+			id, form := pluralForm(prefix + key)
+			msg := catalog[id]
+			if msg == nil {
+				msg = &CatalogMessage{}
+				catalog[id] = msg
+			}
+			text := generator.GoI18nTemplate(v)
+			switch form {
+			case "zero":
+				msg.Zero = text
+			case "one":
+				msg.One = text
+			case "two":
+				msg.Two = text
+			case "few":
+				msg.Few = text
+			case "many":
+				msg.Many = text
+			default:
+				msg.Other = text
+			}
+		}
+	}
+}
+
+// pluralForm splits an i18next plural key (items_one) into the message ID
+// and the plural form; keys without a suffix are the "other" form
+func pluralForm(key string) (string, string) {
+	for _, form := range []string{"zero", "one", "two", "few", "many", "other"} {
+		if id, ok := strings.CutSuffix(key, "_"+form); ok {
+			return id, form
+		}
+	}
+	if id, ok := strings.CutSuffix(key, "_plural"); ok {
+		return id, "other"
+	}
+	return key, "other"
+}
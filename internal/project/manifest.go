@@ -0,0 +1,175 @@
+// Package project tracks the state of a multi-file migration: which
+// sources have been converted, what was generated, and how much work
+// remains.
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ha1tch/reminty/internal/generator"
+)
+
+// ManifestFile is the name of the manifest written into the output directory
+const ManifestFile = ".reminty-manifest.json"
+
+// manifestVersion is bumped whenever the manifest layout changes
+const manifestVersion = 12
+
+// Manifest records every source file converted into an output directory.
+// It is updated incrementally: each run only touches the files it converts.
+type Manifest struct {
+	Version int                   `json:"version"`
+	Files   map[string]*FileEntry `json:"files"` // keyed by slash-separated source path
+}
+
+// FileEntry describes the conversion of a single source file
+type FileEntry struct {
+	Source     string                  `json:"source"`
+	Output     string                  `json:"output"`
+	Hash       string                  `json:"hash"`    // sha256 of the source
+	Tool       string                  `json:"tool"`    // reminty version that produced the output
+	Options    string                  `json:"options"` // hash of the settings it was generated with
+	Components []ComponentRef          `json:"components"`
+	Default    string                  `json:"default,omitempty"`  // default-exported component
+	Routes     []ConfigRoute           `json:"routes,omitempty"`   // router configuration found in the source
+	Streams    []generator.SSEStream   `json:"streams,omitempty"`  // realtime state served over SSE
+	Handlers   []generator.Handler     `json:"handlers,omitempty"` // htmx endpoints replacing event handlers
+	Loaders    []generator.PageLoader  `json:"loaders,omitempty"`  // Go loaders replacing Next.js data loaders
+	API        []string                `json:"api,omitempty"`      // HTTP methods of a Next.js API route; "*" for any
+	Messages   []generator.Message     `json:"messages,omitempty"` // translatable strings for the catalogs
+	Calls      []generator.CallSite    `json:"calls,omitempty"`    // component calls, checked against their signatures
+	Imports    []string                `json:"imports,omitempty"`  // converted sources whose components it calls by signature
+	Types      []generator.ItemStruct  `json:"types,omitempty"`    // item structs it declares
+	Decls      []generator.Declaration `json:"decls,omitempty"`    // package-level vars and helpers it declares
+	Patterns   map[string]int          `json:"patterns,omitempty"` // pattern type → occurrences
+	Findings   map[string]int          `json:"findings,omitempty"` // audit rule → occurrences
+	Unmapped   []generator.Unmapped    `json:"unmapped,omitempty"` // tags and attributes without a minty mapping
+	Warnings   int                     `json:"warnings"`
+}
+
+// ComponentRef records a converted component and the TODOs left in it
+type ComponentRef struct {
+	Name   string            `json:"name"`
+	TODOs  int               `json:"todos"`
+	Params []generator.Param `json:"params,omitempty"` // generated function parameters
+	Review string            `json:"review,omitempty"` // reminty review decision: accepted, skipped or manual
+}
+
+// NewManifest returns an empty manifest
+func NewManifest() *Manifest {
+	return &Manifest{
+		Version: manifestVersion,
+		Files:   map[string]*FileEntry{},
+	}
+}
+
+// LoadManifest reads the manifest from dir, returning an empty manifest if
+// none exists yet.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewManifest()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Files == nil {
+		m.Files = map[string]*FileEntry{}
+	}
+	return m, nil
+}
+
+// Save writes the manifest into dir
+func (m *Manifest) Save(dir string) error {
+	m.Version = manifestVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'))
+}
+
+// Unchanged reports whether source was already converted from identical
+// content by the same tool version with the same settings, into a
+// manifest of the current layout
+func (m *Manifest) Unchanged(source string, content []byte, tool, options string) bool {
+	entry, ok := m.Files[source]
+	return ok && m.Version == manifestVersion && entry.Hash == Hash(content) && entry.Tool == tool && entry.Options == options
+}
+
+// Record adds or replaces the entry for a source file
+func (m *Manifest) Record(entry *FileEntry) {
+	m.Files[entry.Source] = entry
+}
+
+// Prune drops the entries of sources no longer in the project and
+// returns them, in sorted order, so that their outputs can be removed
+func (m *Manifest) Prune(sources map[string]bool) []*FileEntry {
+	var removed []*FileEntry
+	for _, source := range m.Sources() {
+		if !sources[source] {
+			removed = append(removed, m.Files[source])
+			delete(m.Files, source)
+		}
+	}
+	return removed
+}
+
+// Streams returns the realtime SSE streams of every converted file; a
+// state variable with the same endpoint in several files is served once
+func (m *Manifest) Streams() []generator.SSEStream {
+	var streams []generator.SSEStream
+	seen := map[string]bool{}
+	for _, source := range m.Sources() {
+		for _, s := range m.Files[source].Streams {
+			if !seen[s.Endpoint] {
+				seen[s.Endpoint] = true
+				streams = append(streams, s)
+			}
+		}
+	}
+	return streams
+}
+
+// Handlers returns the htmx endpoints of every converted file; an
+// endpoint with the same method and path in several files is served once
+func (m *Manifest) Handlers() []generator.Handler {
+	var handlers []generator.Handler
+	seen := map[string]bool{}
+	for _, source := range m.Sources() {
+		for _, h := range m.Files[source].Handlers {
+			if key := h.Method + " " + h.Endpoint; !seen[key] {
+				seen[key] = true
+				handlers = append(handlers, h)
+			}
+		}
+	}
+	return handlers
+}
+
+// Sources returns the recorded source paths in sorted order
+func (m *Manifest) Sources() []string {
+	sources := make([]string, 0, len(m.Files))
+	for source := range m.Files {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// Hash returns the content hash stored in the manifest
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package project
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+)
+
+// MetricsFile is the name of the metrics export written next to the manifest
+const MetricsFile = "metrics.json"
+
+// MetricsSchemaVersion identifies the layout of metrics.json. Dashboards
+// can rely on fields staying put within a schema version.
+const MetricsSchemaVersion = 1
+
+// Metrics is the cumulative migration summary exported for dashboards
+type Metrics struct {
+	SchemaVersion       int                    `json:"schemaVersion"`
+	FilesConverted      int                    `json:"filesConverted"`
+	ComponentsConverted int                    `json:"componentsConverted"`
+	ComponentsClean     int                    `json:"componentsClean"` // components with no TODOs
+	TODOsRemaining      int                    `json:"todosRemaining"`
+	Warnings            int                    `json:"warnings"`
+	CoveragePercent     float64                `json:"coveragePercent"`
+	PatternsByType      map[string]int         `json:"patternsByType"`
+	FindingsByRule      map[string]int         `json:"findingsByRule"` // audit findings left in the sources
+	Directories         map[string]*DirMetrics `json:"directories"`
+}
+
+// DirMetrics is the per-directory breakdown of Metrics
+type DirMetrics struct {
+	FilesConverted      int     `json:"filesConverted"`
+	ComponentsConverted int     `json:"componentsConverted"`
+	ComponentsClean     int     `json:"componentsClean"`
+	TODOsRemaining      int     `json:"todosRemaining"`
+	CoveragePercent     float64 `json:"coveragePercent"`
+}
+
+// Metrics computes the cumulative metrics for every file in the manifest.
+// Coverage is the percentage of converted components that need no
+// manual follow-up (no TODO markers in their generated code).
+func (m *Manifest) Metrics() *Metrics {
+	metrics := &Metrics{
+		SchemaVersion:  MetricsSchemaVersion,
+		PatternsByType: map[string]int{},
+		FindingsByRule: map[string]int{},
+		Directories:    map[string]*DirMetrics{},
+	}
+
+	for _, source := range m.Sources() {
+		entry := m.Files[source]
+		dir := path.Dir(source)
+		dm, ok := metrics.Directories[dir]
+		if !ok {
+			dm = &DirMetrics{}
+			metrics.Directories[dir] = dm
+		}
+
+		metrics.FilesConverted++
+		dm.FilesConverted++
+		metrics.Warnings += entry.Warnings
+
+		for _, comp := range entry.Components {
+			metrics.ComponentsConverted++
+			dm.ComponentsConverted++
+			metrics.TODOsRemaining += comp.TODOs
+			dm.TODOsRemaining += comp.TODOs
+			if comp.TODOs == 0 {
+				metrics.ComponentsClean++
+				dm.ComponentsClean++
+			}
+		}
+
+		for typ, n := range entry.Patterns {
+			metrics.PatternsByType[typ] += n
+		}
+		for rule, n := range entry.Findings {
+			metrics.FindingsByRule[rule] += n
+		}
+	}
+
+	metrics.CoveragePercent = coverage(metrics.ComponentsClean, metrics.ComponentsConverted)
+	for _, dm := range metrics.Directories {
+		dm.CoveragePercent = coverage(dm.ComponentsClean, dm.ComponentsConverted)
+	}
+
+	return metrics
+}
+
+// SaveMetrics writes metrics.json into dir
+func (m *Manifest) SaveMetrics(dir string) error {
+	data, err := json.MarshalIndent(m.Metrics(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(dir, MetricsFile), append(data, '\n'))
+}
+
+func coverage(clean, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	// Round to one decimal place so the file diffs cleanly between runs
+	return float64(int(float64(clean)/float64(total)*1000+0.5)) / 10
+}
@@ -0,0 +1,532 @@
+package project
+
+import (
+	"fmt"
+	"go/format"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ha1tch/reminty/internal/generator"
+)
+
+// RoutesFile is the route table generated for a converted directory
+const RoutesFile = "routes.go"
+
+// pageDirs are the directories whose files are treated as pages
+var pageDirs = map[string]bool{
+	"pages":  true,
+	"app":    true,
+	"routes": true,
+	"views":  true,
+}
+
+// Route maps a URL pattern to the page component rendering it, or to the
+// handler stubs of a Next.js API route
+type Route struct {
+	Pattern   string                // net/http pattern path, e.g. /blog/{slug}
+	Component string                // page component function
+	Source    string                // file the route was derived from
+	Params    []generator.Param     // parameters of the component function
+	Loader    *generator.PageLoader // loads the page's props, replacing getServerSideProps
+	Methods   []string              // HTTP methods of an API route; "*" for any
+}
+
+// PageRoute derives a URL path from the path of a page file relative to
+// the source root, following the Next.js conventions:
+//
+//	pages/index.jsx          → /
+//	pages/blog/[slug].jsx    → /blog/{slug}
+//	pages/docs/[...all].jsx  → /docs/{all...}
+//	app/settings/page.jsx    → /settings
+//
+// ok is false if the file is not a page (outside a pages directory, or a
+// special file such as _app.jsx or a layout).
+func PageRoute(rel string) (pattern string, ok bool) {
+	parts := strings.Split(path.Clean(strings.ReplaceAll(rel, "\\", "/")), "/")
+	root := -1
+	for i, part := range parts[:len(parts)-1] {
+		if pageDirs[part] {
+			root = i
+			break
+		}
+	}
+	if root < 0 {
+		return "", false
+	}
+
+	base := parts[len(parts)-1]
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if strings.HasPrefix(base, "_") || strings.EqualFold(base, "layout") {
+		return "", false
+	}
+
+	segments := parts[root+1 : len(parts)-1]
+	if parts[root] == "app" {
+		// App router: only page files are routes, named by their directory
+		if base != "page" {
+			return "", false
+		}
+	} else if base != "index" {
+		segments = append(segments, base)
+	}
+
+	return routePath(segments), true
+}
+
+// APIRoute derives the URL path of a Next.js API route from the path of
+// its file relative to the source root:
+//
+//	pages/api/users/[id].js   → /api/users/{id}
+//	app/api/items/route.js    → /api/items
+//
+// ok is false if the file is not an API route.
+func APIRoute(rel string) (pattern string, ok bool) {
+	parts := strings.Split(path.Clean(strings.ReplaceAll(rel, "\\", "/")), "/")
+	base := parts[len(parts)-1]
+	base = strings.TrimSuffix(base, path.Ext(base))
+	for i, part := range parts[:len(parts)-1] {
+		switch {
+		case part == "pages" && i+1 < len(parts)-1 && parts[i+1] == "api":
+			segments := parts[i+1 : len(parts)-1]
+			if base != "index" {
+				segments = append(segments, base)
+			}
+			return routePath(segments), true
+		case part == "app" && base == "route":
+			return routePath(parts[i+1 : len(parts)-1]), true
+		}
+	}
+	return "", false
+}
+
+// APIMethods returns the HTTP methods an API route file handles: the
+// methods an app router route.js exports, or "*" for the default-exported
+// handler of a pages/api file
+func APIMethods(rel string, exports []string, defaultExport string) []string {
+	if strings.Contains(path.Base(rel), "route.") {
+		var methods []string
+		for _, name := range exports {
+			switch name {
+			case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+				methods = append(methods, name)
+			}
+		}
+		return methods
+	}
+	if defaultExport != "" {
+		return []string{"*"}
+	}
+	return nil
+}
+
+// routePath joins file-system segments into a pattern path
+func routePath(segments []string) string {
+	var out []string
+	for _, seg := range segments {
+		if strings.HasPrefix(seg, "(") && strings.HasSuffix(seg, ")") {
+			continue // route group
+		}
+		out = append(out, routeSegment(seg))
+	}
+	return "/" + strings.Join(out, "/")
+}
+
+// routeSegment converts one file-system segment to a pattern segment
+func routeSegment(seg string) string {
+	switch {
+	case strings.HasPrefix(seg, "[[...") && strings.HasSuffix(seg, "]]"):
+		return "{" + seg[5:len(seg)-2] + "...}"
+	case strings.HasPrefix(seg, "[...") && strings.HasSuffix(seg, "]"):
+		return "{" + seg[4:len(seg)-1] + "...}"
+	case strings.HasPrefix(seg, "[") && strings.HasSuffix(seg, "]"):
+		return "{" + seg[1:len(seg)-1] + "}"
+	}
+	return kebab(seg)
+}
+
+// kebab converts AboutUs or about_us to about-us
+func kebab(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || r == ' ':
+			b.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 && !strings.HasSuffix(b.String(), "-") {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ConfigRoute is a route declared in a React Router configuration
+type ConfigRoute struct {
+	Path      string `json:"path"`
+	Component string `json:"component"`
+}
+
+var (
+	routeTag       = regexp.MustCompile(`<Route\b[^>]*>`)
+	routePathAttr  = regexp.MustCompile(`\bpath=["']([^"']*)["']`)
+	routeElement   = regexp.MustCompile(`\b(?:element=\{\s*<|component=\{\s*)([A-Z]\w*)`)
+	routeObject    = regexp.MustCompile(`\{\s*path:\s*["']([^"']*)["']\s*,\s*(?:element:\s*<|component:\s*)([A-Z]\w*)`)
+	routeParamPart = regexp.MustCompile(`:(\w+)`)
+)
+
+// RouterConfig extracts routes from <Route path="..." element={<Page />} />
+// elements and { path: '...', element: <Page /> } route objects
+func RouterConfig(source string) []ConfigRoute {
+	var routes []ConfigRoute
+	for _, tag := range routeTag.FindAllString(source, -1) {
+		p := routePathAttr.FindStringSubmatch(tag)
+		c := routeElement.FindStringSubmatch(tag)
+		if p != nil && c != nil {
+			routes = append(routes, ConfigRoute{Path: routerPattern(p[1]), Component: c[1]})
+		}
+	}
+	for _, m := range routeObject.FindAllStringSubmatch(source, -1) {
+		routes = append(routes, ConfigRoute{Path: routerPattern(m[1]), Component: m[2]})
+	}
+	return routes
+}
+
+// routerPattern converts /users/:id and /files/* to net/http patterns
+func routerPattern(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	p = routeParamPart.ReplaceAllString(p, "{$1}")
+	if strings.HasSuffix(p, "/*") {
+		p = strings.TrimSuffix(p, "*") + "{rest...}"
+	} else if p == "/*" || p == "*" {
+		p = "/{rest...}"
+	}
+	return p
+}
+
+// Routes collects the routes of every converted file in the manifest:
+// routes declared in router configuration first, then file-based routes
+// for default-exported page components, then Next.js API routes. A path
+// is only routed once.
+func (m *Manifest) Routes() []Route {
+	components := map[string]ComponentRef{}
+	sources := map[string]string{}
+	loaders := map[string]*generator.PageLoader{}
+	for _, source := range m.Sources() {
+		for _, c := range m.Files[source].Components {
+			components[c.Name] = c
+			sources[c.Name] = source
+		}
+		for i, l := range m.Files[source].Loaders {
+			loaders[l.Component] = &m.Files[source].Loaders[i]
+		}
+	}
+
+	var routes []Route
+	seen := map[string]bool{}
+	add := func(pattern, component, source string) {
+		c, ok := components[component]
+		if !ok || seen[pattern] {
+			return
+		}
+		seen[pattern] = true
+		routes = append(routes, Route{Pattern: pattern, Component: component, Source: source, Params: c.Params, Loader: loaders[component]})
+	}
+
+	for _, source := range m.Sources() {
+		for _, r := range m.Files[source].Routes {
+			add(r.Path, r.Component, sources[r.Component])
+		}
+	}
+	for _, source := range m.Sources() {
+		entry := m.Files[source]
+		pattern, ok := PageRoute(source)
+		if !ok {
+			continue
+		}
+		page := entry.Default
+		if page == "" && len(entry.Components) > 0 {
+			page = entry.Components[0].Name
+		}
+		add(pattern, page, source)
+	}
+	for _, source := range m.Sources() {
+		entry := m.Files[source]
+		pattern, ok := APIRoute(source)
+		if !ok || len(entry.API) == 0 || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		routes = append(routes, Route{Pattern: pattern, Source: source, Methods: entry.API})
+	}
+	return routes
+}
+
+// Layout returns the shared layout component: a component named Layout
+// (or RootLayout, App) taking children, or nil if there is none
+func (m *Manifest) Layout() *Route {
+	for _, name := range []string{"Layout", "RootLayout", "App"} {
+		for _, source := range m.Sources() {
+			for _, c := range m.Files[source].Components {
+				if c.Name == name && hasChildren(c.Params) {
+					return &Route{Component: c.Name, Source: source, Params: c.Params}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasChildren(params []generator.Param) bool {
+	for _, p := range params {
+		if p.Name == "children" {
+			return true
+		}
+	}
+	return false
+}
+
+// RoutesSource returns a Go file in package pkg registering a handler for
+// every route. Each handler renders its page inside layout (when non-nil),
+// filling path parameters from the URL, session-backed state from the
+// session package at sessionImport, and other string parameters from the
+// query.
+func RoutesSource(pkg string, routes []Route, layout *Route, sessionImport string) string {
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Pattern < routes[j].Pattern })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// Generated by reminty - route table for the converted pages\n\n")
+	b.WriteString("import (\n\t\"net/http\"\n\n\tmi \"github.com/ha1tch/minty\"\n")
+	if usesSession(routes, layout) {
+		fmt.Fprintf(&b, "\n\t%q\n", sessionImport)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// RegisterRoutes registers a handler for every converted page on mux\n")
+	b.WriteString("func RegisterRoutes(mux *http.ServeMux) {\n")
+	for _, r := range routes {
+		pattern := r.Pattern
+		if pattern == "/" {
+			pattern = "/{$}"
+		}
+		if r.Methods != nil {
+			for _, method := range r.Methods {
+				if method == "*" {
+					fmt.Fprintf(&b, "\tmux.HandleFunc(%q, %s) // %s\n", pattern, apiHandlerName(r.Pattern, method), r.Source)
+				} else {
+					fmt.Fprintf(&b, "\tmux.HandleFunc(%q, %s) // %s\n", method+" "+pattern, apiHandlerName(r.Pattern, method), r.Source)
+				}
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "\tmux.HandleFunc(%q, %s) // %s\n", "GET "+pattern, handlerName(r.Component), r.Source)
+	}
+	b.WriteString("}\n")
+
+	for _, r := range routes {
+		if r.Methods != nil {
+			for _, method := range r.Methods {
+				name := apiHandlerName(r.Pattern, method)
+				if method == "*" {
+					fmt.Fprintf(&b, "\n// %s replaces the API route in %s\n", name, r.Source)
+				} else {
+					fmt.Fprintf(&b, "\n// %s replaces the %s export of %s\n", name, method, r.Source)
+				}
+				fmt.Fprintf(&b, "func %s(w http.ResponseWriter, r *http.Request) {\n", name)
+				b.WriteString("\t// TODO: port the route handler\n")
+				b.WriteString("\thttp.Error(w, \"not implemented\", http.StatusNotImplemented)\n")
+				b.WriteString("}\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "\n// %s renders %s for %s\n", handlerName(r.Component), r.Component, r.Pattern)
+		fmt.Fprintf(&b, "func %s(w http.ResponseWriter, r *http.Request) {\n", handlerName(r.Component))
+		b.WriteString(sessionReads(r.Params))
+		if r.Loader != nil {
+			fmt.Fprintf(&b, "\tprops, err := %s(r)\n", r.Loader.Func)
+			b.WriteString("\tif err != nil {\n\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n\t\treturn\n\t}\n")
+		}
+		fmt.Fprintf(&b, "\trenderPage(w, r, %s(%s))\n", r.Component, routeArgs(r))
+		b.WriteString("}\n")
+	}
+
+	b.WriteString("\n// renderPage writes a page")
+	if layout != nil {
+		fmt.Fprintf(&b, " wrapped in the shared %s", layout.Component)
+	}
+	b.WriteString("\nfunc renderPage(w http.ResponseWriter, r *http.Request, page mi.H) {\n")
+	if layout != nil {
+		fmt.Fprintf(&b, "\tpage = %s(%s)\n", layout.Component, layoutArgs(layout.Params))
+	}
+	b.WriteString("\tw.Header().Set(\"Content-Type\", \"text/html; charset=utf-8\")\n")
+	b.WriteString("\tif err := mi.Render(page, w); err != nil {\n")
+	b.WriteString("\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n")
+	b.WriteString("\t}\n}\n")
+
+	if usesQueryParams(routes) {
+		b.WriteString("\n// queryParams returns the query string as the searchParams of a page\n")
+		b.WriteString("func queryParams(r *http.Request) map[string]interface{} {\n")
+		b.WriteString("\tparams := map[string]interface{}{}\n")
+		b.WriteString("\tfor key := range r.URL.Query() {\n\t\tparams[key] = r.URL.Query().Get(key)\n\t}\n")
+		b.WriteString("\treturn params\n}\n")
+	}
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
+
+// sessionReads loads session-backed state for a handler. String state
+// can be changed through the query string (?filter=done), which is
+// written back to the session so the choice sticks.
+func sessionReads(params []generator.Param) string {
+	var b strings.Builder
+	for _, p := range params {
+		if !p.Session {
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s := %s\n", p.Name, generator.SessionGet(p))
+		if p.Type == "string" {
+			fmt.Fprintf(&b, "\tif r.URL.Query().Has(%q) {\n", p.Name)
+			fmt.Fprintf(&b, "\t\t%s = r.URL.Query().Get(%q)\n", p.Name, p.Name)
+			fmt.Fprintf(&b, "\t\t%s\n\t}\n", generator.SessionSet(p.Name))
+		}
+	}
+	return b.String()
+}
+
+func usesSession(routes []Route, layout *Route) bool {
+	if layout != nil && hasSession(layout.Params) {
+		return true
+	}
+	for _, r := range routes {
+		if hasSession(r.Params) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSession(params []generator.Param) bool {
+	for _, p := range params {
+		if p.Session {
+			return true
+		}
+	}
+	return false
+}
+
+func handlerName(component string) string {
+	return "handle" + component
+}
+
+// apiHandlerName names the handler of an API route method:
+// handleAPIGetItems for GET /api/items
+func apiHandlerName(pattern, method string) string {
+	name := "handleAPI"
+	if method != "*" {
+		name += method[:1] + strings.ToLower(method[1:])
+	}
+	for _, seg := range strings.Split(pattern, "/") {
+		seg = strings.Trim(seg, "{}.")
+		if seg == "" || seg == "api" {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			name += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return name
+}
+
+// routeParams builds the params of an app router page from the path
+// parameters of its pattern
+func routeParams(pattern string) string {
+	var entries []string
+	for _, m := range patternParam.FindAllStringSubmatch(pattern, -1) {
+		entries = append(entries, fmt.Sprintf("%q: r.PathValue(%q)", m[1], m[1]))
+	}
+	return "map[string]interface{}{" + strings.Join(entries, ", ") + "}"
+}
+
+var patternParam = regexp.MustCompile(`\{(\w+)(?:\.\.\.)?\}`)
+
+func usesQueryParams(routes []Route) bool {
+	for _, r := range routes {
+		for _, p := range r.Params {
+			if p.Prop == "searchParams" && p.Type == "map[string]interface{}" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routeArgs builds the call arguments for a page component
+func routeArgs(r Route) string {
+	args := make([]string, len(r.Params))
+	for i, p := range r.Params {
+		switch {
+		case p.Session:
+			args[i] = p.Name
+		case r.Loader != nil && r.Loader.Fields[p.Prop] != "":
+			args[i] = "props." + r.Loader.Fields[p.Prop]
+		case p.Prop == "params" && p.Type == "map[string]interface{}":
+			args[i] = routeParams(r.Pattern)
+		case p.Prop == "searchParams" && p.Type == "map[string]interface{}":
+			args[i] = "queryParams(r)"
+		case p.Type == "string" && strings.Contains(r.Pattern, "{"+p.Name+"}"),
+			p.Type == "string" && strings.Contains(r.Pattern, "{"+p.Name+"...}"):
+			args[i] = fmt.Sprintf("r.PathValue(%q)", p.Name)
+		case p.Type == "string":
+			args[i] = fmt.Sprintf("r.URL.Query().Get(%q)", p.Name)
+		case p.Name == "formErrors":
+			// Validation errors only exist after a failed submission
+			args[i] = "nil"
+		case p.Name == "localizer":
+			args[i] = "requestLocalizer(r)"
+		default:
+			args[i] = zeroValue(p.Type) + " /* TODO: " + p.Name + " */"
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// layoutArgs passes the page as the layout's children
+func layoutArgs(params []generator.Param) string {
+	args := make([]string, len(params))
+	for i, p := range params {
+		if p.Name == "children" {
+			args[i] = "page"
+		} else if p.Session {
+			args[i] = generator.SessionGet(p)
+		} else if p.Name == "localizer" {
+			args[i] = "requestLocalizer(r)"
+		} else {
+			args[i] = zeroValue(p.Type)
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+func zeroValue(typ string) string {
+	switch typ {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int":
+		return "0"
+	}
+	return "nil"
+}
@@ -0,0 +1,221 @@
+package project
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/generator"
+)
+
+// SessionDir is the package generated for session-backed state
+const SessionDir = "session"
+
+// sessionReserved are identifiers of the session package itself, which
+// accessor names must not collide with
+var sessionReserved = map[string]bool{
+	"Store": true, "Default": true, "NewCookieStore": true, "Get": true, "Set": true,
+}
+
+// predeclared are the identifiers a type may use and still be named in
+// the session package: builtin types and type keywords
+var predeclared = map[string]bool{
+	"string": true, "bool": true, "int": true, "int64": true, "float64": true, "byte": true,
+	"rune": true, "any": true, "interface": true, "map": true, "struct": true, "func": true,
+}
+
+var typeIdent = regexp.MustCompile(`[A-Za-z_]\w*`)
+
+// portable reports whether the session package can name typ: []string
+// can, []Item, a struct of the components' package, cannot
+func portable(typ string) bool {
+	for _, ident := range typeIdent.FindAllString(typ, -1) {
+		if !predeclared[ident] {
+			return false
+		}
+	}
+	return true
+}
+
+// SessionVars returns the session-backed state of every converted
+// component, one entry per variable name. Variables sharing a name but
+// not a type fall back to interface{}.
+func (m *Manifest) SessionVars() []generator.Param {
+	var vars []generator.Param
+	index := map[string]int{}
+	for _, source := range m.Sources() {
+		for _, c := range m.Files[source].Components {
+			for _, p := range c.Params {
+				if !p.Session {
+					continue
+				}
+				if i, ok := index[p.Name]; ok {
+					if vars[i].Type != p.Type {
+						vars[i].Type = "interface{}"
+					}
+					continue
+				}
+				index[p.Name] = len(vars)
+				vars = append(vars, p)
+			}
+		}
+	}
+	return vars
+}
+
+// SessionAccessor returns the name of the session getter for a state
+// variable; the setter is the same name prefixed with Set
+func SessionAccessor(name string) string {
+	accessor := strings.ToUpper(name[:1]) + name[1:]
+	if sessionReserved[accessor] {
+		accessor += "Value"
+	}
+	return accessor
+}
+
+// SessionSource returns the session package: a Store interface with a
+// signed-cookie implementation, generic Get and Set, and a typed getter
+// and setter per variable whose type the package can name. Handlers use
+// Get and Set, whose type argument can name the components' own types.
+func SessionSource(vars []generator.Param) string {
+	var b strings.Builder
+	b.WriteString(`// Package session keeps per-visitor state that has to survive across
+// requests: the React state that lived in useState or localStorage.
+package session
+
+// Generated by reminty - typed accessors for persistent state
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Store loads and saves the session values of a visitor
+type Store interface {
+	Load(r *http.Request) map[string]json.RawMessage
+	Save(w http.ResponseWriter, r *http.Request, values map[string]json.RawMessage)
+}
+
+// Default is the store behind the accessors. Replace it to keep sessions
+// server-side instead of in a cookie.
+var Default Store = NewCookieStore("session", secret())
+
+// secret returns the key cookies are signed with. Anybody could forge a
+// session signed with an empty key, so the program does not start
+// without one.
+func secret() []byte {
+	key := os.Getenv("SESSION_SECRET")
+	if key == "" {
+		panic("session: SESSION_SECRET is not set")
+	}
+	return []byte(key)
+}
+
+// NewCookieStore returns a Store keeping all values in one cookie, signed
+// with secret so clients cannot tamper with it
+func NewCookieStore(name string, secret []byte) Store {
+	if len(secret) == 0 {
+		panic("session: cookie store without a secret")
+	}
+	return &cookieStore{name: name, secret: secret}
+}
+
+type cookieStore struct {
+	name   string
+	secret []byte
+}
+
+func (s *cookieStore) Load(r *http.Request) map[string]json.RawMessage {
+	values := map[string]json.RawMessage{}
+	cookie, err := r.Cookie(s.name)
+	if err != nil {
+		return values
+	}
+	payload, sig, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return values
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return values
+	}
+	json.Unmarshal(data, &values)
+	return values
+}
+
+func (s *cookieStore) Save(w http.ResponseWriter, r *http.Request, values map[string]json.RawMessage) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	cookie := &http.Cookie{
+		Name:     s.name,
+		Value:    payload + "." + s.sign(payload),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, cookie)
+
+	// Later reads while handling this request see the new values
+	var kept []string
+	for _, c := range r.Cookies() {
+		if c.Name != s.name {
+			kept = append(kept, c.String())
+		}
+	}
+	r.Header.Set("Cookie", strings.Join(append(kept, cookie.Name+"="+cookie.Value), "; "))
+}
+
+func (s *cookieStore) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Get returns the visitor's value of key, or the zero value of T
+func Get[T any](r *http.Request, key string) T {
+	var v T
+	if raw, ok := Default.Load(r)[key]; ok {
+		json.Unmarshal(raw, &v)
+	}
+	return v
+}
+
+// Set stores the visitor's value of key
+func Set[T any](w http.ResponseWriter, r *http.Request, key string, v T) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	values := Default.Load(r)
+	values[key] = data
+	Default.Save(w, r, values)
+}
+`)
+
+	for _, v := range vars {
+		if !portable(v.Type) {
+			fmt.Fprintf(&b, "\n// %s, a %s of the components' package, is kept with Get[%s](r, %q) and Set\n", v.Name, v.Type, v.Type, v.Name)
+			continue
+		}
+		name := SessionAccessor(v.Name)
+		fmt.Fprintf(&b, "\n// %s returns the visitor's %s state\n", name, v.Name)
+		fmt.Fprintf(&b, "func %s(r *http.Request) %s {\n\treturn Get[%s](r, %q)\n}\n", name, v.Type, v.Type, v.Name)
+		fmt.Fprintf(&b, "\n// Set%s stores the visitor's %s state\n", name, v.Name)
+		fmt.Fprintf(&b, "func Set%s(w http.ResponseWriter, r *http.Request, v %s) {\n\tSet(w, r, %q, v)\n}\n", name, v.Type, v.Name)
+	}
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
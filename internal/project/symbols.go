@@ -0,0 +1,186 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ha1tch/reminty/internal/generator"
+	"github.com/ha1tch/reminty/internal/parser"
+)
+
+// importExtensions are tried, in order, on an import path without one
+var importExtensions = []string{".jsx", ".tsx", ".js"}
+
+// SymbolTable holds the components converted from every source of a
+// project, so that a file importing one calls the generated function by
+// its name and signature
+type SymbolTable struct {
+	files map[string]*FileEntry // keyed by slash-separated source path
+}
+
+// NewSymbolTable returns an empty symbol table
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{files: map[string]*FileEntry{}}
+}
+
+// Add records the components converted from a source
+func (t *SymbolTable) Add(entry *FileEntry) {
+	t.files[entry.Source] = entry
+}
+
+// Collisions reports the sources that define a component a source before
+// them, in path order, defines too. The output is one Go package, so the
+// function would be redeclared: the sources are dropped from the table,
+// to be skipped, and the first definition is kept.
+func (t *SymbolTable) Collisions() map[string]error {
+	sources := make([]string, 0, len(t.files))
+	for source := range t.files {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	defined := map[string]string{} // component → the source it is kept from
+	collisions := map[string]error{}
+	for _, source := range sources {
+		var clashes []string
+		for _, comp := range t.files[source].Components {
+			if other, ok := defined[comp.Name]; ok {
+				clashes = append(clashes, fmt.Sprintf("component %s is already defined in %s", comp.Name, other))
+			}
+		}
+		if len(clashes) > 0 {
+			collisions[source] = errors.New(strings.Join(clashes, "; "))
+			delete(t.files, source)
+			continue
+		}
+		for _, comp := range t.files[source].Components {
+			defined[comp.Name] = source
+		}
+	}
+	return collisions
+}
+
+// Reserved returns the names the sources other than source declare in
+// the package: their components, item structs, vars and helpers
+func (t *SymbolTable) Reserved(source string) map[string]bool {
+	reserved := map[string]bool{}
+	for other, entry := range t.files {
+		if other == source {
+			continue
+		}
+		for _, comp := range entry.Components {
+			reserved[comp.Name] = true
+		}
+		for _, s := range entry.Types {
+			reserved[s.Name] = true
+			if s.Declared != "" {
+				reserved[s.Declared] = true
+			}
+		}
+		for _, d := range entry.Decls {
+			reserved[d.Name] = true
+			if d.Declared != "" {
+				reserved[d.Declared] = true
+			}
+		}
+	}
+	return reserved
+}
+
+// Renamed reports whether the item structs, vars or helpers of source were
+// named for names the other sources no longer declare, or not for those
+// they now do, so that it has to be converted again
+func (t *SymbolTable) Renamed(source string) bool {
+	entry := t.files[source]
+	if entry == nil {
+		return false
+	}
+	reserved := t.Reserved(source)
+	for _, s := range entry.Types {
+		if reserved[s.Name] != (s.Declared != "") || reserved[s.Declared] {
+			return true
+		}
+	}
+	for _, d := range entry.Decls {
+		if reserved[d.Name] != (d.Declared != "") || reserved[d.Declared] {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the components the imports of source name, keyed by
+// the local name source renders them with. Imports of files outside the
+// project, and of names the file does not convert, are left out.
+func (t *SymbolTable) Resolve(source string, imports []parser.Import) map[string]generator.ImportedComponent {
+	resolved := map[string]generator.ImportedComponent{}
+	for _, imp := range imports {
+		entry := t.lookup(source, strings.Trim(imp.Source, "'\"`"))
+		if entry == nil {
+			continue
+		}
+		add := func(name, local string) {
+			for _, comp := range entry.Components {
+				if comp.Name == name {
+					resolved[local] = generator.ImportedComponent{Name: comp.Name, Source: entry.Source, Params: comp.Params}
+				}
+			}
+		}
+		if imp.Default != "" && entry.Default != "" {
+			add(entry.Default, imp.Default)
+		}
+		for name, alias := range imp.Named {
+			add(name, alias)
+		}
+	}
+	return resolved
+}
+
+// lookup finds the converted source an import path of source points to:
+// './Card' from components/List.jsx is components/Card.jsx,
+// components/Card/index.jsx or the .tsx or .js equivalents. '@/' and '~/'
+// are taken from the project root or its src directory.
+func (t *SymbolTable) lookup(source, spec string) *FileEntry {
+	var bases []string
+	switch {
+	case strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../"):
+		bases = []string{path.Join(path.Dir(source), spec)}
+	case strings.HasPrefix(spec, "@/") || strings.HasPrefix(spec, "~/"):
+		bases = []string{path.Clean(spec[2:]), path.Join("src", spec[2:])}
+	default:
+		// A package from node_modules
+		return nil
+	}
+	for _, base := range bases {
+		candidates := []string{base}
+		for _, ext := range importExtensions {
+			candidates = append(candidates, base+ext)
+		}
+		for _, ext := range importExtensions {
+			candidates = append(candidates, base+"/index"+ext)
+		}
+		for _, c := range candidates {
+			if entry, ok := t.files[c]; ok {
+				return entry
+			}
+		}
+	}
+	return nil
+}
+
+// ImportedSources returns the sources the resolved imports come from, in
+// sorted order
+func ImportedSources(imports map[string]generator.ImportedComponent) []string {
+	seen := map[string]bool{}
+	var sources []string
+	for _, imp := range imports {
+		if !seen[imp.Source] {
+			seen[imp.Source] = true
+			sources = append(sources, imp.Source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
@@ -0,0 +1,112 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ha1tch/reminty/internal/generator"
+)
+
+// UnmappedFile is the machine-readable coverage report written next to
+// the manifest
+const UnmappedFile = "unmapped.json"
+
+// unmappedExamples is how many example locations each entry keeps
+const unmappedExamples = 3
+
+// UnmappedReport ranks the tags and attributes that had no minty mapping
+// across all converted files. Filling in Method/Minty gives the start of
+// a custom mapping config.
+type UnmappedReport struct {
+	Tags       []UnmappedEntry `json:"tags"`
+	Attributes []UnmappedEntry `json:"attributes"`
+}
+
+// UnmappedEntry is one unmapped tag or attribute with its occurrences
+type UnmappedEntry struct {
+	Name     string   `json:"name"`
+	Count    int      `json:"count"`
+	Tags     []string `json:"tags,omitempty"` // elements an attribute appeared on
+	Examples []string `json:"examples"`       // file:line locations
+	Minty    string   `json:"minty"`          // mapping to fill in, e.g. "b.Dialog" or "mi.InputMode"
+}
+
+// Unmapped builds the coverage report from every file in the manifest
+func (m *Manifest) Unmapped() UnmappedReport {
+	tags := map[string]*UnmappedEntry{}
+	attrs := map[string]*UnmappedEntry{}
+	attrTags := map[string]map[string]bool{}
+	for _, source := range m.Sources() {
+		for _, u := range m.Files[source].Unmapped {
+			index := tags
+			if u.Kind == generator.UnmappedAttribute {
+				index = attrs
+				if attrTags[u.Name] == nil {
+					attrTags[u.Name] = map[string]bool{}
+				}
+				attrTags[u.Name][u.Tag] = true
+			}
+			entry := index[u.Name]
+			if entry == nil {
+				entry = &UnmappedEntry{Name: u.Name}
+				index[u.Name] = entry
+			}
+			entry.Count++
+			if len(entry.Examples) < unmappedExamples {
+				entry.Examples = append(entry.Examples, fmt.Sprintf("%s:%d", source, u.Line))
+			}
+		}
+	}
+	for name, entry := range attrs {
+		entry.Tags = sortedKeys(attrTags[name])
+	}
+	return UnmappedReport{Tags: rankUnmapped(tags), Attributes: rankUnmapped(attrs)}
+}
+
+// rankUnmapped orders entries by count, then name
+func rankUnmapped(index map[string]*UnmappedEntry) []UnmappedEntry {
+	entries := []UnmappedEntry{}
+	for _, name := range sortedKeys(index) {
+		entries = append(entries, *index[name])
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Count > entries[j].Count })
+	return entries
+}
+
+// SaveUnmapped writes unmapped.json into dir, keeping the mappings
+// already filled in there for entries that are still unmapped
+func (m *Manifest) SaveUnmapped(dir string) error {
+	report := m.Unmapped()
+	if previous, err := loadUnmapped(dir); err == nil {
+		keepMinty(report.Tags, previous.Tags)
+		keepMinty(report.Attributes, previous.Attributes)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(dir, UnmappedFile), append(data, '\n'))
+}
+
+func loadUnmapped(dir string) (UnmappedReport, error) {
+	var report UnmappedReport
+	data, err := os.ReadFile(filepath.Join(dir, UnmappedFile))
+	if err != nil {
+		return report, err
+	}
+	err = json.Unmarshal(data, &report)
+	return report, err
+}
+
+func keepMinty(entries, previous []UnmappedEntry) {
+	filled := map[string]string{}
+	for _, e := range previous {
+		filled[e.Name] = e.Minty
+	}
+	for i := range entries {
+		entries[i].Minty = filled[entries[i].Name]
+	}
+}
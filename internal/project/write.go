@@ -0,0 +1,16 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to path, creating parent directories as needed.
+// Every file reminty generates goes through it, so -preview can replace
+// it to collect the files instead of writing them.
+var WriteFile = func(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,224 @@
+// Package reminty exposes the JSX to Go + minty conversion pipeline for
+// use by other programs.
+//
+//	res, err := reminty.Convert(src, reminty.Options{Tailwind: true})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(res.Code)
+//
+// The types in this package are stable: fields may be added, but existing
+// ones keep their meaning.
+package reminty
+
+import (
+	"context"
+	"go/format"
+
+	"github.com/ha1tch/reminty/internal/audit"
+	"github.com/ha1tch/reminty/internal/parser"
+	"github.com/ha1tch/reminty/internal/patterns"
+	"github.com/ha1tch/reminty/internal/pipeline"
+)
+
+// Options configures a conversion. The zero value converts with the
+// defaults of the reminty command.
+type Options struct {
+	Package            string            // package clause of the generated code (default main)
+	MintyImport        string            // import path of minty, or name=path to rename mi too
+	MintydynImport     string            // import path of mintydyn, or name=path to rename mdy too
+	Mappings           []string          // design-system mapping packs (antd, chakra, mui, shadcn)
+	Tailwind           bool              // translate static inline styles into Tailwind classes
+	KeyIDs             bool              // keep the keys of list items htmx swaps as their ids
+	PreserveWhitespace bool              // keep the text of <pre> and <textarea> as written
+	StripTestAttrs     bool              // leave data-testid and data-cy attributes out
+	Framework          string            // app conventions: react (default), nextjs or solid
+	Interactivity      string            // htmx (default) or alpine to keep show/hide state in the browser
+	Templates          string            // directory of header.tmpl and component.tmpl reshaping the code
+	Tags               map[string]string // tag → builder method overrides (dialog → Dialog)
+	Attributes         map[string]string // attribute → minty option overrides (inputMode → mi.InputMode)
+
+	NoFormat bool // return the code as generated, without gofmt
+
+	DisablePatterns []string // pattern types not to report
+	AlpinePatterns  []string // pattern types whose show/hide state Alpine.js keeps
+	MinConfidence   float64  // report only patterns detected with at least this confidence (0 to 1)
+	RuleFiles       []string // YAML files of pattern rules to detect as well as the built-in ones
+	Detectors       []string // executables detecting patterns as well, as -detector runs them
+}
+
+// Result is the outcome of a conversion
+type Result struct {
+	Code        string       // generated Go source, pattern notes included
+	Components  []Component  // generated component functions
+	Warnings    []Warning    // parser warnings
+	Suggestions []Suggestion // hook and construct migration hints
+	Patterns    []Pattern    // detected UI patterns
+	Findings    []Finding    // accessibility and code-quality audit results
+}
+
+// Component is a generated component function
+type Component struct {
+	Name   string
+	Params []Param
+	TODOs  int // TODO markers left in its code
+}
+
+// Param is a parameter of a generated component function
+type Param struct {
+	Name string // Go parameter name
+	Type string // Go type
+	Prop string // JSX prop it comes from, if any
+}
+
+// Warning is a problem found while parsing
+type Warning struct {
+	Line    int
+	Column  int
+	Message string
+	Code    string // what went wrong, such as "unclosed-element"
+}
+
+// Suggestion is a hint for migrating a React construct by hand
+type Suggestion struct {
+	Line      int
+	Kind      string // useState, useEffect, map, conditional, ...
+	ReactCode string
+	MintyHint string
+}
+
+// Pattern is a UI pattern with a minty/mintydyn equivalent
+type Pattern struct {
+	Type        string // tabs, modal, filter, ...
+	Line        int
+	Confidence  float64 // 0 to 1
+	Description string
+	ReactCode   string
+	MintyCode   string
+}
+
+// Finding is an audit result, identified by a stable rule ID such as
+// a11y/img-alt
+type Finding struct {
+	Rule      string
+	Line      int
+	Component string
+	Message   string
+}
+
+// Convert converts JSX source into Go + minty code.
+func Convert(src []byte, opts Options) (Result, error) {
+	return ConvertContext(context.Background(), src, opts)
+}
+
+// ConvertContext converts JSX source into Go + minty code, abandoning the
+// work as soon as ctx is cancelled or its deadline passes. Lexing, parsing,
+// pattern detection and generation all honour the context, so callers such
+// as servers can bound the time spent on a single request.
+func ConvertContext(ctx context.Context, src []byte, opts Options) (Result, error) {
+	input := string(src)
+
+	settings := &pipeline.Settings{
+		Mappings:           opts.Mappings,
+		Tailwind:           opts.Tailwind,
+		KeyIDs:             opts.KeyIDs,
+		PreserveWhitespace: opts.PreserveWhitespace,
+		StripTestAttrs:     opts.StripTestAttrs,
+		Framework:          opts.Framework,
+		Interactivity:      opts.Interactivity,
+		Package:            opts.Package,
+		Templates:          opts.Templates,
+		MintyImport:        opts.MintyImport,
+		MintydynImport:     opts.MintydynImport,
+		Tags:               opts.Tags,
+		Attributes:         opts.Attributes,
+		Detectors:          opts.Detectors,
+		DisablePatterns:    opts.DisablePatterns,
+		AlpinePatterns:     opts.AlpinePatterns,
+	}
+	for _, file := range opts.RuleFiles {
+		rules, err := patterns.LoadRules(file)
+		if err != nil {
+			return Result{}, err
+		}
+		settings.Rules = append(settings.Rules, rules...)
+	}
+	gen, err := settings.NewGenerator()
+	if err != nil {
+		return Result{}, err
+	}
+	aliases, _ := settings.Aliases() // checked by NewGenerator
+	detector, err := settings.NewDetector()
+	if err != nil {
+		return Result{}, err
+	}
+	source := input
+	if opts.Framework == "solid" {
+		input = parser.DesugarSolid(input)
+	}
+
+	tokens, err := parser.NewLexer(input).TokenizeContext(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := parser.NewParserWithSource(tokens, input).ParseContext(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	detected, err := detector.AnalyzeFileContext(ctx, input, result)
+	if err != nil {
+		return Result{}, err
+	}
+	if opts.Framework == "solid" {
+		pipeline.SolidNames(source, result, detected)
+	}
+	detected = patterns.Rank(detected, opts.MinConfidence)
+	gen.UseAlpineStates(patterns.StatesOf(detected, opts.AlpinePatterns))
+
+	output, err := gen.GenerateContext(ctx, result)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Code: aliases.Rewrite(output + patterns.Notes(detected))}
+	if !opts.NoFormat {
+		// Code that does not parse is returned as generated
+		if formatted, err := format.Source([]byte(res.Code)); err == nil {
+			res.Code = string(formatted)
+		}
+	}
+	for _, stat := range gen.Stats() {
+		comp := Component{Name: stat.Name, TODOs: stat.TODOs}
+		for _, p := range stat.Params {
+			comp.Params = append(comp.Params, Param{Name: p.Name, Type: aliases.Rewrite(p.Type), Prop: p.Prop})
+		}
+		res.Components = append(res.Components, comp)
+	}
+	for _, w := range result.Warnings {
+		res.Warnings = append(res.Warnings, Warning{Line: w.Line, Column: w.Column, Message: w.Message, Code: w.Code})
+	}
+	for _, s := range result.Suggestions {
+		res.Suggestions = append(res.Suggestions, Suggestion{
+			Line:      s.Line,
+			Kind:      s.PatternType,
+			ReactCode: s.ReactCode,
+			MintyHint: s.MintyHint,
+		})
+	}
+	for _, p := range detected {
+		res.Patterns = append(res.Patterns, Pattern{
+			Type:        string(p.Type),
+			Line:        p.Line,
+			Confidence:  p.Confidence,
+			Description: p.Description,
+			ReactCode:   p.ReactCode,
+			MintyCode:   p.MintyCode,
+		})
+	}
+	for _, f := range audit.Run(result.File, input) {
+		res.Findings = append(res.Findings, Finding{Rule: f.Rule, Line: f.Line, Component: f.Component, Message: f.Message})
+	}
+	return res, nil
+}
@@ -62,7 +62,10 @@ function UserList({ users }) {
             ))}
           </ul>
         ) : (
-          <p className="empty">No users found</p>
+          <div className="empty">
+            <img src="/img/no-users.svg" />
+            <p>No users found</p>
+          </div>
         )}
       </div>
     </div>